@@ -17,9 +17,64 @@ integration {
     name = "Unikraft Kraftkit Building"
     slug = "unikraft"
   }
+  component {
+    type = "builder"
+    name = "Unikraft Kernel Import"
+    slug = "import"
+  }
   component {
     type = "post-processor"
     name = "Unikraft Kraftkit Packaging"
     slug = "unikraft"
   }
+  component {
+    type = "post-processor"
+    name = "Unikraft Artifact Storage"
+    slug = "storage"
+  }
+  component {
+    type = "post-processor"
+    name = "Unikraft Package Push"
+    slug = "push"
+  }
+  component {
+    type = "post-processor"
+    name = "Unikraft Package Signing"
+    slug = "sign"
+  }
+  component {
+    type = "post-processor"
+    name = "Unikraft Cloud Deploy"
+    slug = "deploy"
+  }
+  component {
+    type = "post-processor"
+    name = "Unikraft AMI Import"
+    slug = "ami"
+  }
+  component {
+    type = "post-processor"
+    name = "Unikraft Kubernetes"
+    slug = "kubernetes"
+  }
+  component {
+    type = "provisioner"
+    name = "Unikraft Run"
+    slug = "run"
+  }
+  component {
+    type = "data-source"
+    name = "Unikraft Package Catalog"
+    slug = "catalog"
+  }
+  component {
+    type = "data-source"
+    name = "Unikraft Kraftfile Targets"
+    slug = "targets"
+  }
+  component {
+    type = "data-source"
+    name = "Unikraft Version"
+    slug = "version"
+  }
 }
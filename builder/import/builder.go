@@ -0,0 +1,66 @@
+package importbuilder
+
+import (
+	"context"
+	unikraft "packer-plugin-unikraft/builder/unikraft"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	"github.com/hashicorp/packer-plugin-sdk/multistep/commonsteps"
+	"github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+// Builder packages a kernel built outside of Packer, skipping the build of a
+// project entirely.
+type Builder struct {
+	config Config
+	runner multistep.Runner
+}
+
+func (b *Builder) ConfigSpec() hcldec.ObjectSpec { return b.config.FlatMapstructure().HCL2Spec() }
+
+func (b *Builder) Prepare(raws ...interface{}) (generatedVars []string, warnings []string, err error) {
+	warnings, err = b.config.Prepare(raws...)
+	if err != nil {
+		return nil, warnings, err
+	}
+
+	// Nothing is provisioned, the builder does not generate any data.
+	return []string{}, warnings, nil
+}
+
+func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (packer.Artifact, error) {
+	driver := &unikraft.KraftDriver{
+		Ctx:            &b.config.ctx,
+		Ui:             ui,
+		CommandContext: unikraft.KraftCommandContext(ui, b.config.LogLevel, b.config.FancyOutput),
+		PkgFormat:      b.config.Format,
+		DiskFormat:     b.config.DiskFormat,
+		DiskSize:       b.config.DiskSize,
+	}
+
+	steps := []multistep.Step{
+		&StepPkgKernel{},
+	}
+
+	// Setup the state bag and initial state for the steps
+	state := new(multistep.BasicStateBag)
+	state.Put("hook", hook)
+	state.Put("ui", ui)
+
+	state.Put("config", &b.config)
+	state.Put("driver", driver)
+
+	// Run!
+	b.runner = commonsteps.NewRunner(steps, b.config.PackerConfig, ui)
+	b.runner.Run(ctx, state)
+
+	// If there was an error, return that
+	if err, ok := state.GetOk("error"); ok {
+		return nil, err.(error)
+	}
+
+	return &unikraft.Artifact{
+		StateData: state.Get("artifact").(map[string]interface{}),
+	}, nil
+}
@@ -0,0 +1,155 @@
+package importbuilder
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	unikraft "packer-plugin-unikraft/builder/unikraft"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+func TestPrepare(t *testing.T) {
+	valid := func() map[string]interface{} {
+		return map[string]interface{}{
+			"kernel":       "build/app_qemu-x86_64",
+			"architecture": "x86_64",
+			"platform":     "qemu",
+			"destination":  "unikraft.org/app:latest",
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(map[string]interface{})
+		want   string
+	}{
+		{name: "valid", modify: func(map[string]interface{}) {}},
+		{name: "no kernel", modify: func(raw map[string]interface{}) { delete(raw, "kernel") }, want: "kernel must be specified"},
+		{name: "no architecture", modify: func(raw map[string]interface{}) { delete(raw, "architecture") }, want: "architecture must be specified"},
+		{name: "no destination", modify: func(raw map[string]interface{}) { delete(raw, "destination") }, want: "destination must be specified"},
+		{name: "disk", modify: func(raw map[string]interface{}) {
+			raw["format"] = "disk"
+			raw["disk_format"] = "qcow2"
+		}},
+		{name: "disk options without disk", modify: func(raw map[string]interface{}) { raw["disk_size"] = 64 }, want: "require the disk format"},
+		{name: "pushed disk", modify: func(raw map[string]interface{}) {
+			raw["format"] = "disk"
+			raw["push"] = true
+		}, want: "disk images cannot be pushed"},
+		{name: "unknown format", modify: func(raw map[string]interface{}) { raw["format"] = "tar" }, want: `unknown format "tar"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := valid()
+			tt.modify(raw)
+
+			var b Builder
+			_, _, err := b.Prepare(raw)
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Errorf("expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestPrepareNormalizesFirecracker(t *testing.T) {
+	var c Config
+	_, err := c.Prepare(map[string]interface{}{
+		"kernel":       "build/app_fc-x86_64",
+		"architecture": "x86_64",
+		"platform":     "firecracker",
+		"destination":  "unikraft.org/app:latest",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := c.ImportedKernel().TargetName(); got != "fc-x86_64" {
+		t.Errorf("target = %s, want fc-x86_64", got)
+	}
+}
+
+// recordingPackager records the kernels it packages.
+type recordingPackager struct {
+	kernel  unikraft.ImportedKernel
+	pkgName string
+	push    bool
+	err     error
+}
+
+func (p *recordingPackager) PkgKernel(k unikraft.ImportedKernel, pkgName, workdir string, push bool) error {
+	p.kernel, p.pkgName, p.push = k, pkgName, push
+	return p.err
+}
+
+func TestStepPkgKernel(t *testing.T) {
+	config := &Config{
+		Kernel:       "/out/app_qemu-x86_64",
+		Initrd:       "/out/initramfs.cpio",
+		Architecture: "x86_64",
+		Platform:     "qemu",
+		Cmdline:      "-- /bin/app",
+		Destination:  "unikraft.org/app:latest",
+		Format:       "oci",
+		Push:         true,
+	}
+	packager := &recordingPackager{}
+
+	state := new(multistep.BasicStateBag)
+	state.Put("ui", &packersdk.BasicUi{Reader: new(bytes.Buffer), Writer: new(bytes.Buffer)})
+	state.Put("config", config)
+	state.Put("driver", packager)
+
+	if action := (&StepPkgKernel{}).Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("step halted: %v", state.Get("error"))
+	}
+
+	if packager.kernel != config.ImportedKernel() || packager.pkgName != config.Destination || !packager.push {
+		t.Errorf("packaged %+v as %s, push %v", packager.kernel, packager.pkgName, packager.push)
+	}
+
+	artifact := &unikraft.Artifact{StateData: state.Get("artifact").(map[string]interface{})}
+	if got := artifact.State("oci"); got != "unikraft.org/app:latest" {
+		t.Errorf("oci = %v", got)
+	}
+	if got := artifact.State("cmdline"); got != "-- /bin/app" {
+		t.Errorf("cmdline = %v", got)
+	}
+	targets, err := unikraft.ArtifactTargets(artifact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []unikraft.TargetArtifact{{
+		Platform:     "qemu",
+		Architecture: "x86_64",
+		Kernel:       "/out/app_qemu-x86_64",
+		Target:       "qemu-x86_64",
+	}}
+	if !reflect.DeepEqual(targets, want) {
+		t.Errorf("targets = %+v, want %+v", targets, want)
+	}
+}
+
+func TestStepPkgKernelFails(t *testing.T) {
+	state := new(multistep.BasicStateBag)
+	state.Put("ui", &packersdk.BasicUi{Reader: new(bytes.Buffer), Writer: new(bytes.Buffer)})
+	state.Put("config", &Config{Kernel: "/out/app", Destination: "app"})
+	state.Put("driver", &recordingPackager{err: errors.New("invalid imported kernel")})
+
+	if action := (&StepPkgKernel{}).Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatal("expected the step to halt")
+	}
+	if err, ok := state.Get("error").(error); !ok || !strings.Contains(err.Error(), "invalid imported kernel") {
+		t.Errorf("error = %v", state.Get("error"))
+	}
+}
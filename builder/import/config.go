@@ -0,0 +1,141 @@
+//go:generate packer-sdc mapstructure-to-hcl2 -type Config
+
+package importbuilder
+
+import (
+	"fmt"
+	unikraft "packer-plugin-unikraft/builder/unikraft"
+	"strings"
+
+	"github.com/hashicorp/packer-plugin-sdk/common"
+	"github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/hashicorp/packer-plugin-sdk/template/config"
+	"github.com/hashicorp/packer-plugin-sdk/template/interpolate"
+	"github.com/mitchellh/mapstructure"
+)
+
+const BuilderId = "packer.builder.unikraft-import"
+
+type Config struct {
+	common.PackerConfig `mapstructure:",squash"`
+
+	// The pre-built kernel to package.
+	Kernel string `mapstructure:"kernel" required:"true"`
+	// The initramfs packaged along with the kernel.
+	Initrd string `mapstructure:"initrd"`
+	// The architecture the kernel was built for.
+	Architecture string `mapstructure:"architecture" required:"true"`
+	// The platform the kernel was built for. `firecracker` is accepted for
+	// `fc`.
+	Platform string `mapstructure:"platform" required:"true"`
+	// The command line the kernel is packaged with.
+	Cmdline string `mapstructure:"cmdline"`
+	// The name of the target of the kernel. Defaults to
+	// `<platform>-<architecture>`.
+	Target string `mapstructure:"target"`
+	// The version of Unikraft the kernel was built with.
+	UnikraftVersion string `mapstructure:"unikraft_version"`
+	// The name of the package, or the path of the disk image with the disk
+	// format.
+	Destination string `mapstructure:"destination" required:"true"`
+	// Whether to push the package to a registry.
+	Push bool `mapstructure:"push"`
+	// The format to package in: `oci`, or `disk` for a bootable disk image
+	// written to destination. Defaults to `oci`.
+	Format string `mapstructure:"format"`
+	// The format of the disk image: `raw`, `qcow2` or `vmdk`. Defaults to
+	// `raw`.
+	DiskFormat string `mapstructure:"disk_format"`
+	// The size of the disk image in MiB. Defaults to the smallest size the
+	// kernel fits in.
+	DiskSize int `mapstructure:"disk_size"`
+	// Log level to use.
+	LogLevel string `mapstructure:"log_level"`
+	// Force fancy output even when not writing to a terminal.
+	FancyOutput bool `mapstructure:"fancy_output"`
+
+	ctx interpolate.Context
+}
+
+func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
+	var md mapstructure.Metadata
+	err := config.Decode(c, &config.DecodeOpts{
+		Metadata:           &md,
+		PluginType:         BuilderId,
+		Interpolate:        true,
+		InterpolateContext: &c.ctx,
+	}, raws...)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Platform == "firecracker" {
+		c.Platform = "fc"
+	}
+
+	if c.Format == "" {
+		c.Format = "oci"
+	}
+
+	// Accumulate any errors
+	var errs *packer.MultiError
+	if c.Kernel == "" {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("kernel must be specified"))
+	}
+
+	if c.Architecture == "" {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("architecture must be specified"))
+	}
+
+	if c.Platform == "" {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("platform must be specified"))
+	}
+
+	if c.Destination == "" {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("destination must be specified"))
+	}
+
+	switch c.Format {
+	case "oci":
+		if c.DiskFormat != "" || c.DiskSize != 0 {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("disk_format and disk_size require the disk format"))
+		}
+	case unikraft.FormatDisk:
+		if c.Push {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("disk images cannot be pushed"))
+		}
+
+		valid := c.DiskFormat == ""
+		for _, f := range unikraft.DiskFormats {
+			valid = valid || c.DiskFormat == f
+		}
+		if !valid {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("unknown disk_format %q, expected %s", c.DiskFormat, strings.Join(unikraft.DiskFormats, ", ")))
+		}
+
+		if c.DiskSize < 0 {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("disk_size must not be negative"))
+		}
+	default:
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("unknown format %q, expected oci or disk", c.Format))
+	}
+
+	if errs != nil && len(errs.Errors) > 0 {
+		return nil, errs
+	}
+
+	return nil, nil
+}
+
+// ImportedKernel returns the kernel packaged by the builder.
+func (c *Config) ImportedKernel() unikraft.ImportedKernel {
+	return unikraft.ImportedKernel{
+		Name:            c.Target,
+		Kernel:          c.Kernel,
+		Architecture:    c.Architecture,
+		Platform:        c.Platform,
+		Initrd:          c.Initrd,
+		Cmdline:         c.Cmdline,
+		UnikraftVersion: c.UnikraftVersion,
+	}
+}
@@ -0,0 +1,92 @@
+package importbuilder
+
+import (
+	"context"
+	"fmt"
+	"os"
+	unikraft "packer-plugin-unikraft/builder/unikraft"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+type StepPkgKernel struct{}
+
+// Run packages the imported kernel, and records the package as the artifact
+// the post-processors of the unikraft builder receive.
+func (s *StepPkgKernel) Run(_ context.Context, state multistep.StateBag) multistep.StepAction {
+	ui := state.Get("ui").(packersdk.Ui)
+	config, ok := state.Get("config").(*Config)
+	if !ok {
+		err := fmt.Errorf("error encountered obtaining import config")
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	driver := state.Get("driver").(unikraft.KernelPackager)
+	kernel := config.ImportedKernel()
+
+	// The initramfs is staged in a project of its own.
+	workdir, err := os.MkdirTemp("", "packer-unikraft-import-")
+	if err != nil {
+		err := fmt.Errorf("error encountered packaging %s: %s", config.Kernel, err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	defer os.RemoveAll(workdir)
+
+	ui.Say(fmt.Sprintf("Packaging %s as %s", config.Kernel, config.Destination))
+	if err := driver.PkgKernel(kernel, config.Destination, workdir, config.Push); err != nil {
+		err := fmt.Errorf("error encountered packaging %s: %s", config.Kernel, err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	state.Put("artifact", artifactState(config))
+
+	return multistep.ActionContinue
+}
+
+func (s *StepPkgKernel) Cleanup(state multistep.StateBag) {}
+
+// artifactState returns the state of the artifact of the packaged kernel,
+// shaped like the one of the unikraft post-processor.
+func artifactState(config *Config) map[string]interface{} {
+	target := map[string]string{
+		"architecture": config.Architecture,
+		"platform":     config.Platform,
+		"kernel":       config.Kernel,
+		"target":       config.ImportedKernel().TargetName(),
+	}
+
+	packages := []string{config.Destination}
+	state := map[string]interface{}{
+		"binaries": []string{config.Kernel},
+		"kernel":   config.Kernel,
+		"packages": packages,
+		"format":   config.Format,
+		"targets":  []map[string]string{target},
+	}
+	if config.Initrd != "" {
+		state["initrd"] = config.Initrd
+	}
+	if config.Cmdline != "" {
+		state["cmdline"] = config.Cmdline
+	}
+	if config.Format == unikraft.FormatDisk {
+		diskFormat := config.DiskFormat
+		if diskFormat == "" {
+			diskFormat = "raw"
+		}
+
+		state["disks"] = packages
+		state["disk_format"] = diskFormat
+	} else {
+		state["oci"] = config.Destination
+	}
+
+	return state
+}
@@ -1,6 +1,11 @@
 package unikraft
 
-import "fmt"
+import (
+	"fmt"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/mitchellh/mapstructure"
+)
 
 // packersdk.Artifact implementation
 type Artifact struct {
@@ -9,18 +14,106 @@ type Artifact struct {
 	StateData map[string]interface{}
 }
 
+// TargetArtifact describes the kernel built for a single target.  The
+// artifact of a build lists them under its "targets" state so that
+// post-processors can handle each target individually.
+type TargetArtifact struct {
+	Platform     string `mapstructure:"platform"`
+	Architecture string `mapstructure:"architecture"`
+	Kernel       string `mapstructure:"kernel"`
+	// Target is the name of the target, when the builder was given one.
+	Target string `mapstructure:"target"`
+	// BuildID is the build ID of the target, if known.
+	BuildID string `mapstructure:"build_id"`
+	// KernelDbg is the debug kernel of the target, if built.
+	KernelDbg string `mapstructure:"kernel_dbg"`
+	// DebugBundle is the gzipped tarball of the debug kernel of the target
+	// along with a System.map of its symbols, with debug_bundle.
+	DebugBundle string `mapstructure:"debug_bundle"`
+	// SHA256 is the `sha256:<hex>` digest of the kernel.
+	SHA256 string `mapstructure:"sha256"`
+	// KConfigDigest is the `sha256:<hex>` digest of the .config the kernel
+	// was built with, if known.
+	KConfigDigest string `mapstructure:"kconfig_digest"`
+	// KConfig is the .config the kernel was built with, if known.
+	KConfig string `mapstructure:"kconfig"`
+	// FirecrackerConfig is the firecracker configuration file booting the
+	// kernel, for fc targets.
+	FirecrackerConfig string `mapstructure:"firecracker_config"`
+	// XenConfig is the xl domain configuration file booting the kernel, for
+	// xen targets.
+	XenConfig string `mapstructure:"xen_config"`
+	// Output is the copy of the kernel in output_dir, if set.
+	Output string `mapstructure:"output"`
+}
+
+// ArtifactChecksums returns the `sha256:<hex>` digest of every file of an
+// artifact produced by the builder, keyed by path.
+func ArtifactChecksums(a packersdk.Artifact) (map[string]string, error) {
+	checksums := map[string]string{}
+	if err := mapstructure.Decode(a.State("checksums"), &checksums); err != nil {
+		return nil, fmt.Errorf("failed to decode checksums: %w", err)
+	}
+
+	return checksums, nil
+}
+
+// ArtifactTargets returns the per-target kernels of an artifact produced by
+// the builder.
+func ArtifactTargets(a packersdk.Artifact) ([]TargetArtifact, error) {
+	var targets []TargetArtifact
+	if err := mapstructure.Decode(a.State("targets"), &targets); err != nil {
+		return nil, fmt.Errorf("failed to decode targets: %w", err)
+	}
+
+	return targets, nil
+}
+
 func (*Artifact) BuilderId() string {
 	return BuilderId
 }
 
 func (a *Artifact) Files() []string {
-	files := append([]string{}, a.StateData["binaries"].([]string)...)
-	files = append(files, a.StateData["initramfs"].([]string)...)
+	var files []string
+	if binaries, ok := a.StateData["binaries"].([]string); ok {
+		files = append(files, binaries...)
+	}
+	if initramfs, ok := a.StateData["initramfs"].([]string); ok {
+		files = append(files, initramfs...)
+	}
+	if disks, ok := a.StateData["disks"].([]string); ok {
+		files = append(files, disks...)
+	}
+	if archives, ok := a.StateData["archives"].([]string); ok {
+		files = append(files, archives...)
+	}
+	if outputs, ok := a.StateData["outputs"].([]string); ok {
+		files = append(files, outputs...)
+	}
+	if manifests, ok := a.StateData["manifests"].([]string); ok {
+		files = append(files, manifests...)
+	}
+	if xenConfigs, ok := a.StateData["xen_configs"].([]string); ok {
+		files = append(files, xenConfigs...)
+	}
+	if dbgs, ok := a.StateData["dbg_outputs"].([]string); ok {
+		files = append(files, dbgs...)
+	} else if dbg, ok := a.StateData["kernel_dbg"].(string); ok && dbg != "" {
+		files = append(files, dbg)
+	}
+	if bundles, ok := a.StateData["debug_bundles"].([]string); ok {
+		files = append(files, bundles...)
+	}
+	if report, ok := a.StateData["build_report"].(string); ok && report != "" {
+		files = append(files, report)
+	}
 	return files
 }
 
-func (*Artifact) Id() string {
-	return ""
+// Id returns the build ID of the artifact, if known.
+func (a *Artifact) Id() string {
+	id, _ := a.StateData["build_id"].(string)
+	return id
 }
 
 func (a *Artifact) String() string {
@@ -0,0 +1,76 @@
+package unikraft
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestArtifactTargetsAndChecksums(t *testing.T) {
+	a := &Artifact{
+		StateData: map[string]interface{}{
+			"targets": []map[string]string{{
+				"platform":       "qemu",
+				"architecture":   "x86_64",
+				"kernel":         "/app/.unikraft/build/nginx_qemu-x86_64",
+				"kernel_dbg":     "/app/.unikraft/build/nginx_qemu-x86_64.dbg",
+				"sha256":         "sha256:aaaa",
+				"kconfig_digest": "sha256:bbbb",
+				"debug_bundle":   "/app/.unikraft/build/nginx_qemu-x86_64.debug.tar.gz",
+			}},
+			"checksums": map[string]string{
+				"/app/.unikraft/build/nginx_qemu-x86_64": "sha256:aaaa",
+			},
+		},
+	}
+
+	targets, err := ArtifactTargets(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) != 1 {
+		t.Fatalf("expected 1 target, got %d", len(targets))
+	}
+
+	got := targets[0]
+	if got.KernelDbg != "/app/.unikraft/build/nginx_qemu-x86_64.dbg" || got.SHA256 != "sha256:aaaa" || got.KConfigDigest != "sha256:bbbb" || got.DebugBundle == "" {
+		t.Errorf("unexpected target: %+v", got)
+	}
+
+	checksums, err := ArtifactChecksums(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checksums[got.Kernel] != got.SHA256 {
+		t.Errorf("checksum of %s = %s, want %s", got.Kernel, checksums[got.Kernel], got.SHA256)
+	}
+}
+
+func TestArtifactFilesDebugKernel(t *testing.T) {
+	a := &Artifact{
+		StateData: map[string]interface{}{
+			"binaries":      []string{"/app/.unikraft/build/nginx_qemu-x86_64"},
+			"kernel_dbg":    "/symbols/nginx.dbg",
+			"debug_bundles": []string{"/app/.unikraft/build/nginx_qemu-x86_64.debug.tar.gz"},
+		},
+	}
+
+	want := []string{
+		"/app/.unikraft/build/nginx_qemu-x86_64",
+		"/symbols/nginx.dbg",
+		"/app/.unikraft/build/nginx_qemu-x86_64.debug.tar.gz",
+	}
+	if got := a.Files(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Files() = %q, want %q", got, want)
+	}
+	// The debug kernels of every target replace the one of the first.
+	a.StateData["dbg_outputs"] = []string{"/symbols/nginx.dbg", "/symbols/nginx-arm64.dbg"}
+	want = []string{
+		"/app/.unikraft/build/nginx_qemu-x86_64",
+		"/symbols/nginx.dbg",
+		"/symbols/nginx-arm64.dbg",
+		"/app/.unikraft/build/nginx_qemu-x86_64.debug.tar.gz",
+	}
+	if got := a.Files(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Files() = %q, want %q", got, want)
+	}
+}
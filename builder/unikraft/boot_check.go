@@ -0,0 +1,136 @@
+package unikraft
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultBootTimeout is how long a boot test waits for the unikernel when no
+// timeout is given.
+const DefaultBootTimeout = time.Minute
+
+// bootCrashMarkers are the console messages printed by Unikraft when the
+// unikernel crashes.
+var bootCrashMarkers = []string{
+	"Crash dump",
+	"Unhandled Trap",
+	"Kernel panic",
+}
+
+// bootConsoleTail is the number of console lines reported when a boot test
+// fails.
+const bootConsoleTail = 20
+
+// BootCheck describes when a booted unikernel is considered healthy.
+type BootCheck struct {
+	// ExpectConsole is a string the unikernel must print on its console.
+	ExpectConsole string
+	// ExpectExitCode, when set, is the code the unikernel must exit with.
+	ExpectExitCode *int
+	// Timeout is how long to wait for the unikernel.  Defaults to
+	// DefaultBootTimeout.
+	Timeout time.Duration
+}
+
+// watchBoot waits for a booted unikernel to satisfy check, reading its
+// console lines from console and its exit code from exited.  It fails when
+// the unikernel crashes, exits with an unexpected code, or the timeout
+// elapses first.  Without an expected exit code, seeing the console string
+// is enough; without an expected console string, the unikernel must exit.
+func watchBoot(ctx context.Context, console <-chan string, exited <-chan int, check BootCheck) error {
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = DefaultBootTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var tail []string
+	seen := len(check.ExpectConsole) == 0
+
+	failure := func(format string, args ...interface{}) error {
+		msg := fmt.Sprintf(format, args...)
+		if len(tail) > 0 {
+			msg += ":\n" + strings.Join(tail, "\n")
+		}
+		return fmt.Errorf("boot test failed: %s", msg)
+	}
+
+	// observe records a console line, and reports whether the boot test is
+	// over because the unikernel crashed or printed the expected string.
+	observe := func(line string) (bool, error) {
+		tail = append(tail, line)
+		if len(tail) > bootConsoleTail {
+			tail = tail[1:]
+		}
+
+		for _, marker := range bootCrashMarkers {
+			if strings.Contains(line, marker) {
+				return true, failure("unikernel crashed")
+			}
+		}
+
+		if !seen && strings.Contains(line, check.ExpectConsole) {
+			seen = true
+			return check.ExpectExitCode == nil, nil
+		}
+
+		return false, nil
+	}
+
+	for {
+		select {
+		case line, ok := <-console:
+			if !ok {
+				console = nil
+				continue
+			}
+
+			if done, err := observe(line); done {
+				return err
+			}
+
+		case code, ok := <-exited:
+			if !ok {
+				exited = nil
+				continue
+			}
+
+			// The console may still hold the last lines printed before the
+			// unikernel exited.
+			for drained := false; !drained && console != nil; {
+				select {
+				case line, ok := <-console:
+					if !ok {
+						drained = true
+					} else if done, err := observe(line); done && err != nil {
+						return err
+					}
+				default:
+					drained = true
+				}
+			}
+
+			if check.ExpectExitCode != nil && code != *check.ExpectExitCode {
+				return failure("unikernel exited with code %d, expected %d", code, *check.ExpectExitCode)
+			} else if check.ExpectExitCode == nil && code != 0 {
+				return failure("unikernel exited with code %d", code)
+			}
+
+			if !seen {
+				return failure("unikernel exited without printing %q", check.ExpectConsole)
+			}
+
+			return nil
+
+		case <-ctx.Done():
+			if len(check.ExpectConsole) > 0 && !seen {
+				return failure("timed out after %s waiting for %q", timeout, check.ExpectConsole)
+			}
+			return failure("timed out after %s waiting for the unikernel to exit", timeout)
+		}
+	}
+}
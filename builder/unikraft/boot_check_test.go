@@ -0,0 +1,102 @@
+package unikraft
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func bootRun(lines []string, code *int) (<-chan string, <-chan int) {
+	console := make(chan string, len(lines))
+	for _, line := range lines {
+		console <- line
+	}
+	close(console)
+
+	exited := make(chan int, 1)
+	if code != nil {
+		exited <- *code
+		close(exited)
+	}
+
+	return console, exited
+}
+
+func TestWatchBoot(t *testing.T) {
+	zero, one := 0, 1
+
+	tests := []struct {
+		name  string
+		lines []string
+		code  *int
+		check BootCheck
+		want  string
+	}{
+		{
+			name:  "console string",
+			lines: []string{"Powered by Unikraft", "Hello world!"},
+			check: BootCheck{ExpectConsole: "Hello world!"},
+		},
+		{
+			name:  "console string and exit code",
+			lines: []string{"Hello world!"},
+			code:  &zero,
+			check: BootCheck{ExpectConsole: "Hello world!", ExpectExitCode: &zero},
+		},
+		{
+			name:  "exit code only",
+			code:  &one,
+			check: BootCheck{ExpectExitCode: &one},
+		},
+		{
+			name:  "crash",
+			lines: []string{"Powered by Unikraft", "CRIT: [libkvmplat] Unhandled Trap 14 (page fault)"},
+			check: BootCheck{ExpectConsole: "Hello world!"},
+			want:  "unikernel crashed",
+		},
+		{
+			name:  "unexpected exit code",
+			lines: []string{"Hello world!"},
+			code:  &one,
+			check: BootCheck{ExpectExitCode: &zero},
+			want:  "exited with code 1, expected 0",
+		},
+		{
+			name:  "exit without console string",
+			lines: []string{"Powered by Unikraft"},
+			code:  &zero,
+			check: BootCheck{ExpectConsole: "Hello world!"},
+			want:  `exited without printing "Hello world!"`,
+		},
+		{
+			name:  "timeout",
+			lines: []string{"Powered by Unikraft"},
+			check: BootCheck{ExpectConsole: "Hello world!", Timeout: 10 * time.Millisecond},
+			want:  "timed out",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			console, exited := bootRun(tt.lines, tt.code)
+
+			err := watchBoot(context.Background(), console, exited, tt.check)
+			if tt.want == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestWatchBootReportsConsoleTail(t *testing.T) {
+	one := 1
+	console, exited := bootRun([]string{"Powered by Unikraft", "main returned 1"}, &one)
+
+	err := watchBoot(context.Background(), console, exited, BootCheck{})
+	if err == nil || !strings.Contains(err.Error(), "main returned 1") {
+		t.Errorf("expected the console output in the error, got %v", err)
+	}
+}
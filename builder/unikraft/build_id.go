@@ -0,0 +1,46 @@
+package unikraft
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+)
+
+// BuildInputs are the resolved inputs of a build which determine its output.
+type BuildInputs struct {
+	Targets []BuildInputTarget `json:"targets"`
+	// Components maps the name of every component to its resolved version.
+	Components map[string]string `json:"components"`
+	// Options are the build options affecting the built kernels.
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// BuildInputTarget describes a target built by a build.
+type BuildInputTarget struct {
+	Name         string            `json:"name"`
+	Architecture string            `json:"architecture"`
+	Platform     string            `json:"platform"`
+	KConfig      map[string]string `json:"kconfig,omitempty"`
+}
+
+// ID returns an identifier derived from the inputs only, so that identical
+// builds share it and any change of their inputs changes it.  The order of
+// the targets does not matter.
+func (in BuildInputs) ID() string {
+	targets := append([]BuildInputTarget(nil), in.Targets...)
+	sort.SliceStable(targets, func(i, j int) bool {
+		return targets[i].Name < targets[j].Name
+	})
+	in.Targets = targets
+
+	// Maps are encoded with sorted keys, which keeps the encoding stable.
+	raw, err := json.Marshal(in)
+	if err != nil {
+		// Only strings are encoded.
+		panic(err)
+	}
+
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,42 @@
+package unikraft
+
+import "testing"
+
+func TestBuildInputsID(t *testing.T) {
+	inputs := func() BuildInputs {
+		return BuildInputs{
+			Targets: []BuildInputTarget{
+				{Name: "nginx-qemu-x86_64", Architecture: "x86_64", Platform: "qemu", KConfig: map[string]string{"CONFIG_LIBVFSCORE": "y"}},
+				{Name: "nginx-fc-arm64", Architecture: "arm64", Platform: "fc"},
+			},
+			Components: map[string]string{"unikraft": "stable", "musl": "stable", "nginx": "stable"},
+			Options:    map[string]string{"kernel_dbg": "false"},
+		}
+	}
+
+	id := inputs().ID()
+	if len(id) != 64 {
+		t.Fatalf("unexpected id %q", id)
+	}
+
+	reordered := inputs()
+	reordered.Targets[0], reordered.Targets[1] = reordered.Targets[1], reordered.Targets[0]
+	if got := reordered.ID(); got != id {
+		t.Errorf("identical builds have different ids: %s != %s", got, id)
+	}
+
+	changes := map[string]func(*BuildInputs){
+		"component version": func(in *BuildInputs) { in.Components["musl"] = "v0.15.0" },
+		"kconfig":           func(in *BuildInputs) { in.Targets[0].KConfig["CONFIG_LIBVFSCORE"] = "n" },
+		"option":            func(in *BuildInputs) { in.Options["kernel_dbg"] = "true" },
+		"target":            func(in *BuildInputs) { in.Targets = in.Targets[:1] },
+		"platform":          func(in *BuildInputs) { in.Targets[1].Platform = "qemu" },
+	}
+	for name, change := range changes {
+		in := inputs()
+		change(&in)
+		if in.ID() == id {
+			t.Errorf("changing the %s does not change the id", name)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package unikraft
+
+import (
+	"fmt"
+	"time"
+)
+
+// BuildLog streams the output of the configure, prepare and build phases of
+// targets, a line at a time, with the target each line comes from.
+type BuildLog struct {
+	// Message and Error receive the lines written to the standard output and
+	// error of a phase.
+	Message func(string)
+	Error   func(string)
+
+	// Timestamps prefixes every line with the time it was written.
+	Timestamps bool
+	// Quiet drops the standard output of the phases, such that only their
+	// errors are shown.
+	Quiet bool
+
+	// now overrides the clock of the timestamps.
+	now func() time.Time
+}
+
+// BuildLogPrefix returns the prefix of the lines of a target, as
+// `[name/platform/architecture]`, or `[platform/architecture]` for unnamed
+// targets.
+func BuildLogPrefix(name, platform, architecture string) string {
+	if name == "" {
+		return fmt.Sprintf("[%s/%s]", platform, architecture)
+	}
+
+	return fmt.Sprintf("[%s/%s/%s]", name, platform, architecture)
+}
+
+// Writer returns the writer of the standard output, or error when stderr is
+// set, of a phase whose lines are prefixed by prefix.  It must be flushed once
+// the phase is over.
+func (l *BuildLog) Writer(prefix string, stderr bool) *LineWriter {
+	emit := l.Message
+	if stderr {
+		emit = l.Error
+	}
+
+	return &LineWriter{Emit: func(line string) {
+		if l.Quiet && !stderr {
+			return
+		}
+
+		if l.Timestamps {
+			now := l.now
+			if now == nil {
+				now = time.Now
+			}
+			line = fmt.Sprintf("%s %s %s", now().Format("15:04:05"), prefix, line)
+		} else {
+			line = fmt.Sprintf("%s %s", prefix, line)
+		}
+
+		emit(line)
+	}}
+}
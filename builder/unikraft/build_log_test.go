@@ -0,0 +1,65 @@
+package unikraft
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBuildLogPrefix(t *testing.T) {
+	if got := BuildLogPrefix("nginx", "qemu", "x86_64"); got != "[nginx/qemu/x86_64]" {
+		t.Errorf("BuildLogPrefix() = %q", got)
+	}
+	if got := BuildLogPrefix("", "fc", "arm64"); got != "[fc/arm64]" {
+		t.Errorf("BuildLogPrefix() = %q", got)
+	}
+}
+
+func TestBuildLogWriter(t *testing.T) {
+	tests := []struct {
+		name       string
+		timestamps bool
+		quiet      bool
+		want       []string
+	}{
+		{name: "default", want: []string{
+			"message: [nginx/qemu/x86_64]   CC      lwip/tcp.o",
+			"error: [nginx/qemu/x86_64] tcp.c:12: error: unknown type",
+			"message: [nginx/qemu/x86_64]   LD      nginx_qemu-x86_64",
+		}},
+		{name: "timestamps", timestamps: true, want: []string{
+			"message: 09:30:00 [nginx/qemu/x86_64]   CC      lwip/tcp.o",
+			"error: 09:30:00 [nginx/qemu/x86_64] tcp.c:12: error: unknown type",
+			"message: 09:30:00 [nginx/qemu/x86_64]   LD      nginx_qemu-x86_64",
+		}},
+		{name: "quiet", quiet: true, want: []string{
+			"error: [nginx/qemu/x86_64] tcp.c:12: error: unknown type",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var lines []string
+			l := &BuildLog{
+				Message:    func(s string) { lines = append(lines, "message: "+s) },
+				Error:      func(s string) { lines = append(lines, "error: "+s) },
+				Timestamps: tt.timestamps,
+				Quiet:      tt.quiet,
+				now:        func() time.Time { return time.Date(2023, 9, 1, 9, 30, 0, 0, time.UTC) },
+			}
+
+			prefix := BuildLogPrefix("nginx", "qemu", "x86_64")
+			stdout, stderr := l.Writer(prefix, false), l.Writer(prefix, true)
+			fmt.Fprint(stdout, "  CC      lwip/tcp.o\n  LD")
+			fmt.Fprint(stderr, "tcp.c:12: error: unknown type\n")
+			fmt.Fprint(stdout, "      nginx_qemu-x86_64")
+			stdout.Flush()
+			stderr.Flush()
+
+			if !reflect.DeepEqual(lines, tt.want) {
+				t.Errorf("lines = %q, want %q", lines, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package unikraft
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ManifestCommand is a command executed during a build.
+type ManifestCommand struct {
+	Phase      string `json:"phase"`
+	Target     string `json:"target"`
+	Dir        string `json:"dir"`
+	Command    string `json:"command"`
+	ExitStatus int    `json:"exit_status"`
+}
+
+// commandRecorder records the make invocations of a build.  It provides a
+// shim to put in front of the real make binary on the PATH of make, which logs
+// the command line, working directory and exit status of every invocation
+// along with the phase and target being built.
+type commandRecorder struct {
+	dir      string
+	realMake string
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// newCommandRecorder installs the make shim in dir.
+func newCommandRecorder(dir string) (*commandRecorder, error) {
+	realMake, err := exec.LookPath("make")
+	if err != nil {
+		return nil, fmt.Errorf("could not find make: %w", err)
+	}
+
+	r := &commandRecorder{dir: dir, realMake: realMake}
+	if err := os.MkdirAll(r.binDir(), 0o755); err != nil {
+		return nil, err
+	}
+
+	// Sub-makes are started through $(MAKE), which points at the real binary,
+	// so only the commands issued by the build itself are recorded.  The
+	// arguments are logged quoted, such that the command can be run again.
+	shim := fmt.Sprintf(`#!/bin/sh
+ctx=$(cat %[1]s 2>/dev/null)
+%[2]s "$@"
+status=$?
+cmd=make
+for arg in "$@"; do
+	case $arg in
+	''|*[!A-Za-z0-9_./:=+,@%%-]*)
+		arg="'$(printf '%%s' "$arg" | sed "s/'/'\\\\''/g")'" ;;
+	esac
+	cmd="$cmd $arg"
+done
+printf '%%s\t%%s\t%%s\t%%s\n' "$ctx" "$status" "$PWD" "$cmd" >> %[3]s
+exit $status
+`, shellQuote(r.contextFile()), shellQuote(realMake), shellQuote(r.logFile()))
+
+	if err := os.WriteFile(filepath.Join(r.binDir(), "make"), []byte(shim), 0o755); err != nil {
+		return nil, err
+	}
+
+	return r, r.SetPhase("", "")
+}
+
+func (r *commandRecorder) binDir() string      { return filepath.Join(r.dir, "bin") }
+func (r *commandRecorder) contextFile() string { return filepath.Join(r.dir, "context") }
+func (r *commandRecorder) logFile() string     { return filepath.Join(r.dir, "commands.log") }
+
+// PathEnv returns the PATH for make which puts the shim in front of the real
+// make.
+func (r *commandRecorder) PathEnv() string {
+	return r.binDir() + string(os.PathListSeparator) + os.Getenv("PATH")
+}
+
+// SetPhase attributes the following invocations to a phase and target.
+func (r *commandRecorder) SetPhase(phase, target string) error {
+	return os.WriteFile(r.contextFile(), []byte(phase+"\t"+target), 0o644)
+}
+
+// Commands returns the recorded invocations in execution order.
+func (r *commandRecorder) Commands() ([]ManifestCommand, error) {
+	f, err := os.Open(r.logFile())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var commands []ManifestCommand
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), "\t", 5)
+		if len(fields) != 5 {
+			continue
+		}
+
+		status, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+
+		commands = append(commands, ManifestCommand{
+			Phase:      fields[0],
+			Target:     fields[1],
+			ExitStatus: status,
+			Dir:        fields[3],
+			Command:    fields[4],
+		})
+	}
+
+	return commands, scanner.Err()
+}
+
+// writeBuildManifest writes the executed commands to path as JSON.
+func writeBuildManifest(path string, commands []ManifestCommand) error {
+	if commands == nil {
+		commands = []ManifestCommand{}
+	}
+
+	raw, err := json.MarshalIndent(commands, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, raw, 0o644)
+}
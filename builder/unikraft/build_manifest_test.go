@@ -0,0 +1,81 @@
+package unikraft
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestCommandRecorder(t *testing.T) {
+	if _, err := exec.LookPath("make"); err != nil {
+		t.Skip("make is not available")
+	}
+
+	dir := t.TempDir()
+	work := filepath.Join(dir, "app")
+	if err := os.MkdirAll(work, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	makefile := "ok:\n\t@true\nfail:\n\t@false\n"
+	if err := os.WriteFile(filepath.Join(work, "Makefile"), []byte(makefile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rec, err := newCommandRecorder(filepath.Join(dir, "recorder"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The shim is only on the PATH of the shell running make.
+	run := func(phase, target string, args ...string) {
+		if err := rec.SetPhase(phase, target); err != nil {
+			t.Fatal(err)
+		}
+
+		cmd := exec.Command("sh", append([]string{"-c", `make "$@"`, "sh"}, args...)...)
+		cmd.Env = append(os.Environ(), "PATH="+rec.PathEnv())
+		_ = cmd.Run()
+	}
+
+	run("configure", "nginx-qemu-x86_64", "-C", work, "ok")
+	run("build", "nginx-qemu-x86_64", "-C", work, "fail")
+	run("build", "nginx-app-qemu-x86_64", "-C", work, "ok", "CFLAGS=-O2 -g", "NAME=it's")
+
+	commands, err := rec.Commands()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manifest := filepath.Join(dir, "out", "manifest.json")
+	if err := writeBuildManifest(manifest, commands); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []ManifestCommand
+	if err := json.Unmarshal(raw, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 3 {
+		t.Fatalf("expected 3 recorded commands, got %+v", got)
+	}
+
+	want := []ManifestCommand{
+		{Phase: "configure", Target: "nginx-qemu-x86_64", Command: "make -C " + work + " ok", ExitStatus: 0},
+		{Phase: "build", Target: "nginx-qemu-x86_64", Command: "make -C " + work + " fail", ExitStatus: 2},
+		{Phase: "build", Target: "nginx-app-qemu-x86_64", Command: "make -C " + work + ` ok 'CFLAGS=-O2 -g' 'NAME=it'\''s'`, ExitStatus: 0},
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Phase != w.Phase || g.Target != w.Target || g.Command != w.Command || g.ExitStatus != w.ExitStatus {
+			t.Errorf("command %d: got %+v, want %+v", i, g, w)
+		}
+	}
+}
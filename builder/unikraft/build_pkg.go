@@ -0,0 +1,81 @@
+package unikraft
+
+import (
+	"context"
+
+	"kraftkit.sh/log"
+	"kraftkit.sh/pack"
+)
+
+// BuildPkg builds the selected targets and packages those which built
+// successfully in a single pass, sharing the project parsed and the targets
+// selected by the build.  Targets which failed to build are skipped and
+// reported.
+type BuildPkg struct {
+	Build Build
+	Pkg   Pkg
+
+	// Skipped holds the targets which were not packaged because their build
+	// failed.
+	Skipped []string
+}
+
+func (opts *BuildPkg) BuildPkgCmd(ctx context.Context, workdir string) ([]pack.Package, error) {
+	if err := opts.Build.BuildCmd(ctx, workdir); err != nil {
+		return nil, err
+	}
+
+	built, skipped := splitResults(opts.Build.Results())
+	opts.Skipped = skipped
+
+	for _, name := range skipped {
+		log.G(ctx).Warnf("not packaging %s: build did not succeed", name)
+	}
+
+	if len(built) == 0 {
+		return nil, nil
+	}
+
+	// The target set supersedes any architecture, platform or target filter.
+	p := opts.Pkg
+	p.Project = opts.Build.project
+	p.Workdir = workdir
+	p.targets = targetsNamed(opts.Build.project.Targets(), built)
+
+	return p.PackCmd(ctx, workdir)
+}
+
+// splitResults returns the names of the targets which were built
+// successfully and of those which were not.
+func splitResults(results []TargetResult) ([]string, []string) {
+	var built, skipped []string
+
+	for _, res := range results {
+		if res.Status != TargetStatusSuccess {
+			skipped = append(skipped, res.Target)
+			continue
+		}
+
+		built = append(built, res.Target)
+	}
+
+	return built, skipped
+}
+
+// targetsNamed returns the targets with the given names, in the order of
+// targets.
+func targetsNamed[T interface{ Name() string }](targets []T, names []string) []T {
+	wanted := map[string]bool{}
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	selected := []T{}
+	for _, t := range targets {
+		if wanted[t.Name()] {
+			selected = append(selected, t)
+		}
+	}
+
+	return selected
+}
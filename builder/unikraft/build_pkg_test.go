@@ -0,0 +1,40 @@
+package unikraft
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitResultsSkipsFailedTargets(t *testing.T) {
+	results := []TargetResult{
+		{Target: "nginx-qemu-x86_64", Status: TargetStatusSuccess},
+		{Target: "nginx-qemu-arm64", Status: TargetStatusFailed, Error: "make failed"},
+		{Target: "nginx-fc-x86_64", Status: TargetStatusSuccess},
+	}
+
+	built, skipped := splitResults(results)
+
+	if want := []string{"nginx-qemu-x86_64", "nginx-fc-x86_64"}; !reflect.DeepEqual(built, want) {
+		t.Errorf("packaged %v, want %v", built, want)
+	}
+	if want := []string{"nginx-qemu-arm64"}; !reflect.DeepEqual(skipped, want) {
+		t.Errorf("skipped %v, want %v", skipped, want)
+	}
+}
+
+type namedTarget string
+
+func (n namedTarget) Name() string { return string(n) }
+
+func TestTargetsNamedSharesBuiltTargets(t *testing.T) {
+	project := []namedTarget{"nginx-qemu-x86_64", "nginx-qemu-arm64", "nginx-fc-x86_64"}
+
+	got := targetsNamed(project, []string{"nginx-fc-x86_64", "nginx-qemu-x86_64"})
+	if want := []namedTarget{"nginx-qemu-x86_64", "nginx-fc-x86_64"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("targets %v, want %v", got, want)
+	}
+
+	if got := targetsNamed(project, nil); got == nil || len(got) != 0 {
+		t.Errorf("expected an empty, non-nil selection, got %#v", got)
+	}
+}
@@ -0,0 +1,271 @@
+package unikraft
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	TargetStatusSuccess = "success"
+	TargetStatusFailed  = "failed"
+	TargetStatusSkipped = "skipped"
+)
+
+// BuildReportFile is the name of the JSON report written by the builder next
+// to the kernels it built.
+const BuildReportFile = "build-report.json"
+
+// TargetResult is the outcome of building a single target.
+type TargetResult struct {
+	Target       string            `json:"target"`
+	Architecture string            `json:"architecture"`
+	Platform     string            `json:"platform"`
+	Status       string            `json:"status"`
+	Error        string            `json:"error,omitempty"`
+	Duration     time.Duration     `json:"duration"`
+	Kernel       string            `json:"kernel,omitempty"`
+	KernelSize   int64             `json:"kernel_size,omitempty"`
+	Versions     map[string]string `json:"versions,omitempty"`
+	// Phases is the duration of every phase of the target, e.g. `configure`,
+	// `prepare`, `build` and `package`.
+	Phases map[string]time.Duration `json:"phases,omitempty"`
+	// Warnings is the number of compiler warnings of the build phase.
+	Warnings int `json:"warnings,omitempty"`
+	// SHA256 is the `sha256:<hex>` digest of the kernel.
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// BuildReport aggregates the results of all targets of a build into a single
+// document.
+type BuildReport struct {
+	// BuildID identifies the build by its resolved inputs.
+	BuildID     string            `json:"build_id,omitempty"`
+	Targets     []TargetResult    `json:"targets"`
+	Environment *BuildEnvironment `json:"environment,omitempty"`
+	// Phases is the duration of the phases which are not specific to a
+	// target, such as the `pull` of the sources.
+	Phases map[string]time.Duration `json:"phases,omitempty"`
+
+	mu sync.Mutex
+}
+
+// ReadBuildReport reads the JSON report at path.
+func ReadBuildReport(path string) (*BuildReport, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &BuildReport{}
+	if err := json.Unmarshal(raw, r); err != nil {
+		return nil, fmt.Errorf("invalid build report %s: %w", path, err)
+	}
+
+	return r, nil
+}
+
+// Add records the result of a target.
+func (r *BuildReport) Add(res TargetResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Targets = append(r.Targets, res)
+}
+
+// AddPhase records the duration of a phase of the targets matching target,
+// platform and architecture, where empty values match any target.  It returns
+// whether any target matched.
+func (r *BuildReport) AddPhase(target, platform, architecture, phase string, d time.Duration) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	matched := false
+	for i := range r.Targets {
+		t := &r.Targets[i]
+		if (target != "" && t.Target != target) ||
+			(platform != "" && t.Platform != platform) ||
+			(architecture != "" && t.Architecture != architecture) {
+			continue
+		}
+
+		if t.Phases == nil {
+			t.Phases = map[string]time.Duration{}
+		}
+		t.Phases[phase] += d
+		matched = true
+	}
+
+	return matched
+}
+
+// Failed returns the number of targets which failed to build.
+func (r *BuildReport) Failed() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	failed := 0
+	for _, t := range r.Targets {
+		if t.Status == TargetStatusFailed {
+			failed++
+		}
+	}
+
+	return failed
+}
+
+// AllFailed reports whether at least one target failed to build and every
+// target which was not skipped failed.
+func (r *BuildReport) AllFailed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	failed := 0
+	for _, t := range r.Targets {
+		if t.Status == TargetStatusSkipped {
+			continue
+		} else if t.Status != TargetStatusFailed {
+			return false
+		}
+		failed++
+	}
+
+	return failed > 0
+}
+
+// allSkipped reports whether every target of results was skipped, such that
+// nothing was built.  It is false when there are no results.
+func allSkipped(results []TargetResult) bool {
+	for _, res := range results {
+		if res.Status != TargetStatusSkipped {
+			return false
+		}
+	}
+
+	return len(results) > 0
+}
+
+// Text renders the report as a human readable summary.
+func (r *BuildReport) Text() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var b strings.Builder
+	for _, t := range r.Targets {
+		fmt.Fprintf(&b, "%s (%s/%s): %s in %s", t.Target, t.Platform, t.Architecture, t.Status, t.Duration.Round(time.Millisecond))
+		if t.KernelSize > 0 {
+			fmt.Fprintf(&b, ", kernel %d bytes", t.KernelSize)
+		}
+		if t.Warnings > 0 {
+			fmt.Fprintf(&b, ", %d warnings", t.Warnings)
+		}
+		if t.Error != "" {
+			fmt.Fprintf(&b, ": %s", t.Error)
+		}
+		b.WriteString("\n")
+
+		names := make([]string, 0, len(t.Versions))
+		for name := range t.Versions {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(&b, "  %s %s\n", name, t.Versions[name])
+		}
+	}
+
+	return b.String()
+}
+
+// WriteFile writes the report to path.  Paths ending in `.json` receive a JSON
+// document, any other path the text summary.
+func (r *BuildReport) WriteFile(path string) error {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		r.mu.Lock()
+		raw, err := json.MarshalIndent(r, "", "  ")
+		r.mu.Unlock()
+		if err != nil {
+			return err
+		}
+
+		return os.WriteFile(path, raw, 0o644)
+	}
+
+	return os.WriteFile(path, []byte(r.Text()), 0o644)
+}
+
+// newTargetResult creates the result of a target which started building at
+// start and finished with err.
+func newTargetResult(name, architecture, platform, kernel string, start time.Time, err error, versions map[string]string) TargetResult {
+	res := TargetResult{
+		Target:       name,
+		Architecture: architecture,
+		Platform:     platform,
+		Status:       TargetStatusSuccess,
+		Duration:     time.Since(start),
+		Versions:     versions,
+	}
+
+	if err != nil {
+		res.Status = TargetStatusFailed
+		res.Error = err.Error()
+		return res
+	}
+
+	if fi, err := os.Stat(kernel); err == nil {
+		res.Kernel = kernel
+		res.KernelSize = fi.Size()
+	}
+
+	return res
+}
+
+// phaseTimes records the duration of the phases of every target of a build.
+type phaseTimes map[string]map[string]time.Duration
+
+func (p phaseTimes) add(target, phase string, d time.Duration) {
+	if p[target] == nil {
+		p[target] = map[string]time.Duration{}
+	}
+	p[target][phase] += d
+}
+
+// countWarnings returns the number of warnings among diagnostics.
+func countWarnings(diagnostics []Diagnostic) int {
+	n := 0
+	for _, d := range diagnostics {
+		if d.Severity == "warning" {
+			n++
+		}
+	}
+
+	return n
+}
+
+// attachKernels records the kernel and its digest, as moved out of the build
+// folder, in the results of the targets they were built for.
+func attachKernels(results []TargetResult, targets []map[string]string) []TargetResult {
+	for i := range results {
+		for _, target := range targets {
+			if target["platform"] != results[i].Platform || target["architecture"] != results[i].Architecture {
+				continue
+			}
+
+			results[i].Kernel = target["kernel"]
+			results[i].SHA256 = target["sha256"]
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,167 @@
+package unikraft
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildReportCoversAllTargets(t *testing.T) {
+	dir := t.TempDir()
+	kernel := filepath.Join(dir, "nginx_qemu-x86_64")
+	if err := os.WriteFile(kernel, make([]byte, 1024), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	versions := map[string]string{"unikraft": "stable", "musl": "stable"}
+	start := time.Now()
+
+	report := &BuildReport{}
+	report.Add(newTargetResult("nginx-qemu-x86_64", "x86_64", "qemu", kernel, start, nil, versions))
+	report.Add(newTargetResult("nginx-fc-arm64", "arm64", "fc", "", start, errors.New("make failed"), versions))
+
+	path := filepath.Join(dir, "report", "build.json")
+	if err := report.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got BuildReport
+	if err := json.Unmarshal(raw, &got); err != nil {
+		t.Fatalf("report is not valid JSON: %v", err)
+	}
+
+	if len(got.Targets) != 2 {
+		t.Fatalf("expected 2 targets in report, got %d", len(got.Targets))
+	}
+	if got.Targets[0].Status != TargetStatusSuccess || got.Targets[0].KernelSize != 1024 {
+		t.Errorf("unexpected result for first target: %+v", got.Targets[0])
+	}
+	if got.Targets[1].Status != TargetStatusFailed || got.Targets[1].Error != "make failed" {
+		t.Errorf("unexpected result for second target: %+v", got.Targets[1])
+	}
+	if got.Targets[0].Versions["musl"] != "stable" {
+		t.Errorf("expected resolved versions in report, got %v", got.Targets[0].Versions)
+	}
+	if report.Failed() != 1 {
+		t.Errorf("expected 1 failed target, got %d", report.Failed())
+	}
+
+	text := report.Text()
+	for _, want := range []string{"nginx-qemu-x86_64 (qemu/x86_64): success", "nginx-fc-arm64 (fc/arm64): failed"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text report missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestBuildReportAllFailed(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []string
+		want     bool
+	}{
+		{name: "no targets"},
+		{name: "all failed", statuses: []string{TargetStatusFailed, TargetStatusFailed}, want: true},
+		{name: "one skipped, the others failed", statuses: []string{TargetStatusSkipped, TargetStatusFailed, TargetStatusFailed}, want: true},
+		{name: "one succeeded", statuses: []string{TargetStatusFailed, TargetStatusSuccess}},
+		{name: "all skipped", statuses: []string{TargetStatusSkipped, TargetStatusSkipped}},
+	}
+
+	for _, tt := range tests {
+		report := &BuildReport{}
+		for i, status := range tt.statuses {
+			report.Add(TargetResult{Target: fmt.Sprintf("app-%d", i), Status: status})
+		}
+
+		if got := report.AllFailed(); got != tt.want {
+			t.Errorf("%s: AllFailed() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAllSkipped(t *testing.T) {
+	skipped := TargetResult{Target: "app-qemu-arm64", Status: TargetStatusSkipped}
+	built := TargetResult{Target: "app-qemu-x86_64", Status: TargetStatusSuccess}
+
+	if allSkipped(nil) {
+		t.Error("expected no results not to be skipped")
+	}
+	if !allSkipped([]TargetResult{skipped}) {
+		t.Error("expected a skipped target to be skipped")
+	}
+	if allSkipped([]TargetResult{skipped, built}) {
+		t.Error("expected a built target not to be skipped")
+	}
+}
+
+func TestBuildReportPhases(t *testing.T) {
+	report := &BuildReport{Targets: []TargetResult{
+		{Target: "app-qemu-x86_64", Platform: "qemu", Architecture: "x86_64", Phases: map[string]time.Duration{"build": time.Minute}},
+		{Target: "app-fc-x86_64", Platform: "fc", Architecture: "x86_64"},
+	}}
+
+	if !report.AddPhase("", "qemu", "x86_64", "package", 2*time.Second) {
+		t.Fatal("expected the qemu target to match")
+	}
+	if report.AddPhase("", "xen", "x86_64", "package", time.Second) {
+		t.Error("expected no target to match xen")
+	}
+	if !report.AddPhase("app-fc-x86_64", "", "", "package", time.Second) {
+		t.Fatal("expected the target to match by name")
+	}
+
+	path := filepath.Join(t.TempDir(), BuildReportFile)
+	if err := report.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadBuildReport(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if phases := got.Targets[0].Phases; phases["build"] != time.Minute || phases["package"] != 2*time.Second {
+		t.Errorf("unexpected phases of the qemu target: %v", phases)
+	}
+	if phases := got.Targets[1].Phases; len(phases) != 1 || phases["package"] != time.Second {
+		t.Errorf("unexpected phases of the fc target: %v", phases)
+	}
+}
+
+func TestAttachKernels(t *testing.T) {
+	results := []TargetResult{
+		{Target: "app-qemu-x86_64", Platform: "qemu", Architecture: "x86_64", Kernel: ".unikraft/build/app_qemu-x86_64"},
+		{Target: "app-fc-x86_64", Platform: "fc", Architecture: "x86_64", Status: TargetStatusFailed},
+	}
+	targets := []map[string]string{
+		{"platform": "qemu", "architecture": "x86_64", "kernel": "out/app_qemu-x86_64", "sha256": "sha256:abc"},
+	}
+
+	got := attachKernels(results, targets)
+	if got[0].Kernel != "out/app_qemu-x86_64" || got[0].SHA256 != "sha256:abc" {
+		t.Errorf("unexpected kernel of the qemu target: %+v", got[0])
+	}
+	if got[1].Kernel != "" || got[1].SHA256 != "" {
+		t.Errorf("expected no kernel for the failed target, got %+v", got[1])
+	}
+}
+
+func TestCountWarnings(t *testing.T) {
+	c := &diagnosticCollector{}
+	c.Write([]byte("main.c:3:5: warning: unused variable 'x' [-Wunused-variable]\n"))
+	c.Write([]byte("main.c:7:1: error: expected ';' before '}' token\n"))
+	c.Write([]byte("lib.c:9:2: warning: implicit declaration of function 'f'\n"))
+
+	if got := countWarnings(c.Diagnostics()); got != 2 {
+		t.Errorf("countWarnings() = %d, want 2", got)
+	}
+}
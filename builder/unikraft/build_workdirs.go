@@ -0,0 +1,27 @@
+package unikraft
+
+import (
+	"context"
+	"fmt"
+)
+
+// BuildWorkdirsCmd builds the projects of several workdirs in turn.  The
+// components pulled for one project are reused by the others when their
+// version and contents match.
+func (opts *Build) BuildWorkdirsCmd(ctx context.Context, workdirs ...string) error {
+	components := opts.Components
+	if components == nil {
+		components = NewComponentRegistry()
+	}
+
+	for _, workdir := range workdirs {
+		build := *opts
+		build.Components = components
+
+		if err := build.BuildCmd(ctx, workdir); err != nil {
+			return fmt.Errorf("could not build %s: %w", workdir, err)
+		}
+	}
+
+	return nil
+}
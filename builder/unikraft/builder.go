@@ -2,12 +2,15 @@ package unikraft
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2/hcldec"
 	"github.com/hashicorp/packer-plugin-sdk/multistep"
 	"github.com/hashicorp/packer-plugin-sdk/multistep/commonsteps"
 	"github.com/hashicorp/packer-plugin-sdk/packer"
-	"github.com/hashicorp/packer-plugin-sdk/template/config"
 )
 
 const BuilderId = "packer.builder.unikraft"
@@ -15,15 +18,15 @@ const BuilderId = "packer.builder.unikraft"
 type Builder struct {
 	config Config
 	runner multistep.Runner
+
+	// resolveImage overrides remoteImageDigest.
+	resolveImage func(context.Context, string) (string, error)
 }
 
 func (b *Builder) ConfigSpec() hcldec.ObjectSpec { return b.config.FlatMapstructure().HCL2Spec() }
 
 func (b *Builder) Prepare(raws ...interface{}) (generatedVars []string, warnings []string, err error) {
-	err = config.Decode(&b.config, &config.DecodeOpts{
-		PluginType:  "packer.builder.unikraft",
-		Interpolate: true,
-	}, raws...)
+	warnings, err = b.config.Prepare(raws...)
 	if err != nil {
 		return nil, warnings, err
 	}
@@ -31,23 +34,76 @@ func (b *Builder) Prepare(raws ...interface{}) (generatedVars []string, warnings
 	// If the builder doesn't generate any data, just return an empty slice of string: []string{}
 	buildGeneratedData := []string{
 		"binaries",
+		"build_id",
+		"initramfs",
+		"kernel",
+		"kernel_dbg",
 	}
 	return buildGeneratedData, warnings, nil
 }
 
 func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (packer.Artifact, error) {
-	driver := &KraftDriver{
-		Ctx:            &b.config.ctx,
-		Ui:             ui,
-		CommandContext: KraftCommandContext(ui, b.config.LogLevel),
+	paths, cached, err := b.config.CachePaths()
+	if err != nil {
+		return nil, err
+	}
+
+	var cache *CachePaths
+	if cached {
+		cache = &paths
+		if b.config.CleanCache {
+			ui.Say(fmt.Sprintf("Cleaning cache in %s", filepath.Dir(cache.Manifests)))
+			if err := cache.Clean(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var driver Driver
+	if b.config.UsesCLI() {
+		var container *Container
+		if b.config.BuildInContainer {
+			container, err = b.container(ctx, ui, cache)
+			if err != nil {
+				return nil, err
+			}
+			defer os.RemoveAll(container.Home)
+		}
+
+		driver = &KraftCLIDriver{
+			Binary:         b.config.KraftBinary,
+			Output:         &LineWriter{Emit: ui.Message},
+			CommandContext: ctx,
+			Kraftfile:      b.config.Kraftfile,
+			Env:            b.config.BuildEnv,
+			CrossCompile:   b.config.CrossCompile,
+			Jobs:           b.config.BuildJobs,
+			NoFast:         b.config.NoFast(),
+			Cache:          cache,
+			Container:      container,
+		}
+	} else {
+		// KraftKit runs in a context of its own, which is cancelled along
+		// with the build.
+		kctx, cancel := context.WithCancel(KraftCacheCommandContext(ui, b.config.LogLevel, b.config.FancyOutput, cache))
+		defer cancel()
+		stop := context.AfterFunc(ctx, cancel)
+		defer stop()
+
+		driver = b.kraftDriver(kctx, ui)
 	}
 
 	steps := []multistep.Step{
+		&StepGitSource{},
 		&StepPkgSource{},
 		&StepPkgUpdate{},
 		&StepPkgPull{},
 		&StepSet{},
 		&StepBuild{},
+		&StepBuildRootfs{},
+		&StepTestBoot{},
+		&StepSmokeTestFirecracker{},
+		&StepSmokeDebug{},
 		new(commonsteps.StepProvision),
 	}
 
@@ -73,8 +129,133 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 
 	artifact := &Artifact{
 		StateData: map[string]interface{}{
-			"binaries": state.Get("binaries"),
+			"binaries":      state.Get("binaries"),
+			"build_id":      state.Get("build_id"),
+			"checksums":     state.Get("checksums"),
+			"initramfs":     state.Get("initramfs"),
+			"kernel":        state.Get("kernel"),
+			"kernel_dbg":    state.Get("kernel_dbg"),
+			"dbg_outputs":   state.Get("dbg_outputs"),
+			"targets":       state.Get("targets"),
+			"outputs":       state.Get("outputs"),
+			"xen_configs":   state.Get("xen_configs"),
+			"debug_bundles": state.Get("debug_bundles"),
+			"build_report":  state.Get("build_report"),
 		},
 	}
+	if b.config.Cmdline != "" {
+		artifact.StateData["cmdline"] = b.config.Cmdline
+	}
+	if b.config.Kraftfile != "" {
+		artifact.StateData["kraftfile"] = findKraftfile(b.config.Path, b.config.Kraftfile)
+	}
+	if !b.config.NoBuildEnvironment {
+		artifact.StateData["environment"] = collectBuildEnvironment(hostProbe).Map()
+	}
 	return artifact, nil
 }
+
+// kraftDriver returns the driver building with KraftKit linked into the
+// plugin, running in the KraftKit context kctx.
+func (b *Builder) kraftDriver(kctx context.Context, ui packer.Ui) *KraftDriver {
+	retries, backoff := b.config.Retries()
+	return &KraftDriver{
+		Ctx:             &b.config.ctx,
+		Ui:              ui,
+		CommandContext:  kctx,
+		Kraftfile:       b.config.Kraftfile,
+		Spec:            b.config.ProjectSpec(),
+		Env:             b.config.BuildEnv,
+		CrossCompile:    b.config.CrossCompile,
+		Jobs:            b.config.BuildJobs,
+		NoFast:          b.config.NoFast(),
+		CCache:          b.config.UseCCache,
+		Retries:         retries,
+		RetryBackoff:    backoff,
+		ChecksumPolicy:  b.config.PullChecksumPolicy(),
+		Resolution:      b.config.CandidateResolution.Policy(),
+		LicensePolicy:   b.config.LicensePolicy.Policy(),
+		Cmdline:         b.config.Cmdline,
+		Mirrors:         b.config.ComponentMirrors,
+		Proxy:           b.config.Proxy(),
+		RateLimit:       b.config.PullRateLimit,
+		SourceAuth:      b.config.SourceAuths(),
+		Incremental:     b.config.Incremental,
+		SkipUnbuildable: b.config.SkipUnbuildable,
+		ExpectedDigests: b.config.ExpectedDigests,
+		ContinueOnError: b.config.ContinueOnError,
+		Validator:       b.config.Validator,
+		ChangedSince:    b.config.ChangedSince,
+		BuildLog:        b.config.buildLog(ui),
+
+		ConfigureTimeout: b.config.ConfigureTimeout,
+		BuildTimeout:     b.config.BuildTimeout,
+	}
+}
+
+// putGeneratedData records data announced by Prepare, which provisioners
+// receive and templates reference as `build.<name>`.
+func putGeneratedData(state multistep.StateBag, data map[string]interface{}) {
+	generated, ok := state.Get("generated_data").(map[string]interface{})
+	if !ok {
+		generated = map[string]interface{}{}
+		state.Put("generated_data", generated)
+	}
+
+	for k, v := range data {
+		generated[k] = v
+	}
+}
+
+// container returns the containers of the build, with the directories of
+// cache mounted in them.  An image pinned by digest is verified to still have
+// this digest.  The home directory of kraft in the containers is created for
+// the build, and must be removed once it is over.
+func (b *Builder) container(ctx context.Context, ui packer.Ui, cache *CachePaths) (*Container, error) {
+	runtime, err := LookupContainerRuntime(b.config.ContainerRuntime)
+	if err != nil {
+		return nil, err
+	}
+
+	image := b.config.ContainerImage
+	if image == "" {
+		image = DefaultContainerImage
+	}
+
+	if strings.Contains(image, "@") {
+		pinned, err := ParsePinnedImage(image)
+		if err != nil {
+			return nil, err
+		}
+
+		resolve := b.resolveImage
+		if resolve == nil {
+			resolve = remoteImageDigest
+		}
+		if err := pinned.Verify(ctx, resolve); err != nil {
+			return nil, err
+		}
+		ui.Say(fmt.Sprintf("Verified the digest of %s", pinned))
+	}
+
+	// The directories are created beforehand, as the runtime would create
+	// them as root otherwise.
+	var mounts []string
+	if cache != nil {
+		mounts = append(mounts, cache.Manifests, cache.Sources)
+	}
+	for _, dir := range mounts {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("could not create cache: %w", err)
+		}
+	}
+
+	home, err := os.MkdirTemp("", "packer-unikraft-home-")
+	if err != nil {
+		return nil, err
+	}
+
+	ui.Say(fmt.Sprintf("Building in containers of %s with %s", image, filepath.Base(runtime)))
+
+	return NewContainer(runtime, image, home, mounts), nil
+}
@@ -0,0 +1,485 @@
+package unikraft
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+func TestBuilderPrepare(t *testing.T) {
+	valid := func(t *testing.T) map[string]interface{} {
+		return map[string]interface{}{
+			"build_path":   t.TempDir(),
+			"architecture": "x86_64",
+			"platform":     "qemu",
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(map[string]interface{})
+		want   string
+	}{
+		{name: "valid", modify: func(map[string]interface{}) {}},
+		{name: "no architecture", modify: func(raw map[string]interface{}) { delete(raw, "architecture") }, want: "architecture must be specified"},
+		{name: "targets", modify: func(raw map[string]interface{}) {
+			delete(raw, "architecture")
+			delete(raw, "platform")
+			raw["targets"] = []map[string]interface{}{
+				{"architecture": "x86_64", "platform": "qemu"},
+				{"architecture": "arm64", "platform": "fc"},
+			}
+		}},
+		{name: "targets with architecture", modify: func(raw map[string]interface{}) {
+			delete(raw, "platform")
+			raw["targets"] = []map[string]interface{}{
+				{"architecture": "arm64", "platform": "fc"},
+			}
+		}, want: "cannot be combined with targets"},
+		{name: "incomplete target", modify: func(raw map[string]interface{}) {
+			delete(raw, "architecture")
+			delete(raw, "platform")
+			raw["targets"] = []map[string]interface{}{
+				{"architecture": "x86_64", "platform": "qemu"},
+				{"architecture": "arm64"},
+			}
+		}, want: "targets[1]: platform must be specified"},
+		{name: "firecracker architecture", modify: func(raw map[string]interface{}) {
+			raw["architecture"] = "riscv64"
+			raw["platform"] = "firecracker"
+		}, want: "firecracker does not run riscv64 kernels"},
+		{name: "negative firecracker memory", modify: func(raw map[string]interface{}) {
+			raw["platform"] = "fc"
+			raw["firecracker"] = map[string]interface{}{"memory": -1}
+		}, want: "firecracker memory and vcpus must not be negative"},
+		{name: "test boot", modify: func(raw map[string]interface{}) {
+			raw["test_boot"] = map[string]interface{}{"timeout": "30s"}
+		}},
+		{name: "negative boot timeout", modify: func(raw map[string]interface{}) {
+			raw["test_boot"] = map[string]interface{}{"timeout": "-1s"}
+		}, want: "test_boot timeout must not be negative"},
+		{name: "pull options", modify: func(raw map[string]interface{}) {
+			raw["pull_sources"] = []string{"https://manifests.kraftkit.sh/index.yaml"}
+			raw["pull_manager"] = "manifest"
+			raw["pull_no_checksum"] = true
+			raw["pull_force_cache"] = true
+		}},
+		{name: "unknown pull manager", modify: func(raw map[string]interface{}) { raw["pull_manager"] = "apt" }, want: "unknown pull_manager"},
+		{name: "source repository", modify: func(raw map[string]interface{}) {
+			delete(raw, "build_path")
+			raw["source_repository"] = "https://github.com/unikraft/catalog"
+			raw["source_ref"] = "stable"
+			raw["source_path"] = "library/nginx/1.25"
+		}},
+		{name: "source repository and build path", modify: func(raw map[string]interface{}) {
+			raw["source_repository"] = "https://github.com/unikraft/catalog"
+		}, want: "build_path cannot be combined with source_repository"},
+		{name: "source ref without repository", modify: func(raw map[string]interface{}) { raw["source_ref"] = "stable" }, want: "require source_repository"},
+		{name: "pull concurrency", modify: func(raw map[string]interface{}) { raw["pull_concurrency"] = 8 }},
+		{name: "negative pull concurrency", modify: func(raw map[string]interface{}) { raw["pull_concurrency"] = -1 }, want: "pull_concurrency must not be negative"},
+		{name: "rootfs dir", modify: func(raw map[string]interface{}) { raw["rootfs_dir"] = raw["build_path"] }},
+		{name: "missing rootfs dockerfile", modify: func(raw map[string]interface{}) {
+			raw["rootfs_dockerfile"] = "/nonexistent/Dockerfile"
+			raw["rootfs_buildkit_host"] = "tcp://buildkitd:1234"
+		}, want: "rootfs_dockerfile:"},
+		{name: "rootfs dir and dockerfile", modify: func(raw map[string]interface{}) {
+			raw["rootfs_dir"] = "rootfs"
+			raw["rootfs_dockerfile"] = "Dockerfile"
+		}, want: "rootfs_dir cannot be combined with rootfs_dockerfile"},
+		{name: "retries", modify: func(raw map[string]interface{}) {
+			raw["max_retries"] = 5
+			raw["retry_backoff"] = "2s"
+		}},
+		{name: "negative max retries", modify: func(raw map[string]interface{}) { raw["max_retries"] = -1 }, want: "max_retries must not be negative"},
+		{name: "negative retry backoff", modify: func(raw map[string]interface{}) { raw["retry_backoff"] = "-1s" }, want: "retry_backoff must not be negative"},
+		{name: "missing kraftfile", modify: func(raw map[string]interface{}) { raw["kraftfile"] = "Kraftfile.prod" }, want: `kraftfile "Kraftfile.prod" not found in build_path`},
+		{name: "build env", modify: func(raw map[string]interface{}) {
+			raw["architecture"] = "arm64"
+			raw["cross_compile"] = "aarch64-linux-gnu-"
+			raw["build_env"] = map[string]string{"CC": "clang"}
+		}},
+		{name: "conflicting cross compile", modify: func(raw map[string]interface{}) {
+			raw["architecture"] = "arm64"
+			raw["cross_compile"] = "aarch64-linux-gnu-"
+			raw["build_env"] = map[string]string{"CROSS_COMPILE": "aarch64-none-elf-"}
+		}, want: "cross_compile cannot be combined with CROSS_COMPILE in build_env"},
+		{name: "cli driver", modify: func(raw map[string]interface{}) {
+			raw["driver"] = "cli"
+			raw["kraft_binary"] = "/usr/local/bin/kraft"
+		}},
+		{name: "unknown driver", modify: func(raw map[string]interface{}) { raw["driver"] = "docker" }, want: `unknown driver "docker"`},
+		{name: "kraft binary with library driver", modify: func(raw map[string]interface{}) { raw["kraft_binary"] = "/usr/local/bin/kraft" }, want: "kraft_binary requires the cli driver"},
+		{name: "clean shared cache", modify: func(raw map[string]interface{}) {
+			raw["shared_cache"] = true
+			raw["clean_cache"] = true
+		}},
+		{name: "cache dir and shared cache", modify: func(raw map[string]interface{}) {
+			raw["cache_dir"] = "/var/cache/unikraft"
+			raw["shared_cache"] = true
+		}, want: "cache_dir and shared_cache cannot be combined"},
+		{name: "clean cache without cache", modify: func(raw map[string]interface{}) { raw["clean_cache"] = true }, want: "clean_cache requires cache_dir, shared_cache or build_in_container"},
+		{name: "phase timeouts", modify: func(raw map[string]interface{}) {
+			raw["configure_timeout"] = "5m"
+			raw["build_timeout"] = "1h"
+		}},
+		{name: "negative build timeout", modify: func(raw map[string]interface{}) { raw["build_timeout"] = "-1m" }, want: "configure_timeout and build_timeout must not be negative"},
+		{name: "timeouts with cli driver", modify: func(raw map[string]interface{}) {
+			raw["driver"] = "cli"
+			raw["configure_timeout"] = "5m"
+		}, want: "the cli driver cannot bound phases"},
+		{name: "build jobs", modify: func(raw map[string]interface{}) {
+			raw["build_jobs"] = 8
+			raw["build_fast"] = false
+			raw["use_ccache"] = true
+		}},
+		{name: "negative build jobs", modify: func(raw map[string]interface{}) { raw["build_jobs"] = -1 }, want: "build_jobs must not be negative"},
+		{name: "ccache with compiler", modify: func(raw map[string]interface{}) {
+			raw["use_ccache"] = true
+			raw["build_env"] = map[string]string{"CC": "clang"}
+		}, want: "use_ccache cannot be combined with CC"},
+		{name: "build in container", modify: func(raw map[string]interface{}) {
+			raw["build_in_container"] = true
+			raw["container_runtime"] = "podman"
+			raw["container_image"] = "kraftkit.sh/myself-full:latest"
+		}},
+		{name: "build in container with library driver", modify: func(raw map[string]interface{}) {
+			raw["driver"] = "library"
+			raw["build_in_container"] = true
+		}, want: "build_in_container cannot be combined with the library driver"},
+		{name: "container image without container", modify: func(raw map[string]interface{}) { raw["container_image"] = "kraftkit.sh/myself-full:latest" }, want: "container_image and container_runtime require build_in_container"},
+		{name: "unknown container runtime", modify: func(raw map[string]interface{}) {
+			raw["build_in_container"] = true
+			raw["container_runtime"] = "lxc"
+		}, want: `unknown container_runtime "lxc"`},
+		{name: "output templates", modify: func(raw map[string]interface{}) {
+			raw["output_dir"] = "dist/{{ .Plat }}"
+			raw["artifact_name"] = "{{ .Target }}-{{ timestamp }}"
+		}},
+		{name: "artifact name without output dir", modify: func(raw map[string]interface{}) { raw["artifact_name"] = "{{ .Target }}" }, want: "artifact_name requires output_dir"},
+		{name: "artifact name of a directory", modify: func(raw map[string]interface{}) {
+			raw["output_dir"] = "dist"
+			raw["artifact_name"] = "{{ .Plat }}/{{ .Arch }}"
+		}, want: "expected a filename"},
+		{name: "checksum policy", modify: func(raw map[string]interface{}) { raw["checksum_policy"] = "warn" }},
+		{name: "unknown checksum policy", modify: func(raw map[string]interface{}) { raw["checksum_policy"] = "strict" }, want: `unknown checksum_policy "strict"`},
+		{name: "no checksum with enforced checksums", modify: func(raw map[string]interface{}) {
+			raw["pull_no_checksum"] = true
+			raw["checksum_policy"] = "enforce"
+		}, want: "pull_no_checksum cannot be combined with checksum_policy"},
+		{name: "kconfig script", modify: func(raw map[string]interface{}) {
+			raw["kconfig_script"] = []string{"enable LIBLWIP", "set-val STACK_SIZE_PAGE_ORDER 4"}
+		}},
+		{name: "invalid kconfig script", modify: func(raw map[string]interface{}) {
+			raw["kconfig_script"] = []string{"enable LIBLWIP", "toggle LIBUKNETDEV"}
+		}, want: `kconfig_script[1]: unknown operation "toggle"`},
+		{name: "kconfig script with cli driver", modify: func(raw map[string]interface{}) {
+			raw["driver"] = "cli"
+			raw["kconfig_script"] = []string{"enable LIBLWIP"}
+		}, want: "the cli driver cannot merge kconfig, kconfig_fragments or kconfig_script"},
+		{name: "xen", modify: func(raw map[string]interface{}) {
+			raw["platform"] = "xen"
+			raw["xen"] = map[string]interface{}{"memory": 64, "vcpus": 2, "vifs": []string{"bridge=xenbr0"}}
+		}},
+		{name: "negative xen memory", modify: func(raw map[string]interface{}) {
+			raw["platform"] = "xen"
+			raw["xen"] = map[string]interface{}{"memory": -1}
+		}, want: "xen memory and vcpus must not be negative"},
+		{name: "empty xen vif", modify: func(raw map[string]interface{}) {
+			raw["platform"] = "xen"
+			raw["xen"] = map[string]interface{}{"vifs": []string{"bridge=xenbr0", " "}}
+		}, want: "xen vifs[1] must not be empty"},
+		{name: "incremental", modify: func(raw map[string]interface{}) { raw["incremental"] = true }},
+		{name: "incremental with cli driver", modify: func(raw map[string]interface{}) {
+			raw["driver"] = "cli"
+			raw["incremental"] = true
+		}, want: "the cli driver cannot build incrementally"},
+		{name: "candidate resolution", modify: func(raw map[string]interface{}) {
+			raw["candidate_resolution"] = map[string]interface{}{"prefer_version": "highest"}
+		}},
+		{name: "unknown preferred candidate format", modify: func(raw map[string]interface{}) {
+			raw["candidate_resolution"] = map[string]interface{}{"prefer_format": "tarball"}
+		}, want: `candidate_resolution: unknown prefer_format "tarball"`},
+		{name: "candidate resolution with cli driver", modify: func(raw map[string]interface{}) {
+			raw["driver"] = "cli"
+			raw["candidate_resolution"] = map[string]interface{}{"prefer_version": "highest"}
+		}, want: "the cli driver cannot pick among catalog candidates"},
+		{name: "cmdline", modify: func(raw map[string]interface{}) { raw["cmdline"] = `/bin/app -c "/etc/app.conf"` }},
+		{name: "unterminated cmdline", modify: func(raw map[string]interface{}) { raw["cmdline"] = `/bin/app "unterminated` }, want: "invalid cmdline"},
+		{name: "mirrors and proxies", modify: func(raw map[string]interface{}) {
+			raw["component_mirrors"] = map[string]string{"https://github.com/unikraft": "https://mirror.example.com/unikraft"}
+			raw["https_proxy"] = "socks5://proxy.example.com:1080"
+			raw["no_proxy"] = "localhost,.internal"
+		}},
+		{name: "relative component mirror", modify: func(raw map[string]interface{}) {
+			raw["component_mirrors"] = map[string]string{"https://github.com/unikraft": "mirror/unikraft"}
+		}, want: `invalid component mirror "mirror/unikraft"`},
+		{name: "unknown proxy scheme", modify: func(raw map[string]interface{}) { raw["http_proxy"] = "ftp://proxy.example.com" }, want: "invalid http_proxy"},
+		{name: "update index", modify: func(raw map[string]interface{}) {
+			raw["update_index"] = true
+			raw["update_manager"] = "all"
+		}},
+		{name: "unknown update manager", modify: func(raw map[string]interface{}) {
+			raw["update_index"] = true
+			raw["update_manager"] = "apt"
+		}, want: "unknown update_manager"},
+		{name: "update manager without update index", modify: func(raw map[string]interface{}) { raw["update_manager"] = "oci" }, want: "update_manager requires update_index"},
+		{name: "source auth", modify: func(raw map[string]interface{}) {
+			raw["source_auth"] = []map[string]interface{}{
+				{"source": "https://manifests.example.com", "username": "ci", "password": "secret"},
+			}
+		}},
+		{name: "source auth without credentials", modify: func(raw map[string]interface{}) {
+			raw["source_auth"] = []map[string]interface{}{{"source": "https://manifests.example.com"}}
+		}, want: "expected username and password, token or ssh_private_key_file"},
+		{name: "gdb smoke test", modify: func(raw map[string]interface{}) {
+			raw["gdb_smoke_test"] = map[string]interface{}{"gdb_binary": "gdb-multiarch", "entry_symbol": "_libkvmplat_entry"}
+		}},
+		{name: "negative gdb smoke test timeout", modify: func(raw map[string]interface{}) {
+			raw["gdb_smoke_test"] = map[string]interface{}{"timeout": "-1s"}
+		}, want: "gdb_smoke_test timeout must not be negative"},
+		{name: "kernel templates", modify: func(raw map[string]interface{}) {
+			raw["kernel_name"] = "app-{{ .Version }}_{{ .Platform }}-{{ .Architecture }}"
+			raw["dbg_output"] = "symbols/{{ .Target }}.dbg"
+		}},
+		{name: "kernel name of a directory", modify: func(raw map[string]interface{}) { raw["kernel_name"] = "{{ .Plat }}/{{ .Arch }}" }, want: "kernel_name renders to"},
+		{name: "skip unbuildable", modify: func(raw map[string]interface{}) { raw["skip_unbuildable"] = true }},
+		{name: "skip unbuildable with cli driver", modify: func(raw map[string]interface{}) {
+			raw["driver"] = "cli"
+			raw["skip_unbuildable"] = true
+		}, want: "the cli driver cannot skip unbuildable targets"},
+		{name: "expected digests", modify: func(raw map[string]interface{}) {
+			raw["expected_digests"] = map[string]string{"helloworld-qemu-x86_64": "sha256:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"}
+		}},
+		{name: "invalid expected digest", modify: func(raw map[string]interface{}) {
+			raw["expected_digests"] = map[string]string{"helloworld-qemu-x86_64": "sha256:0000"}
+		}, want: `expected_digests["helloworld-qemu-x86_64"]: invalid digest`},
+		{name: "expected digests with cli driver", modify: func(raw map[string]interface{}) {
+			raw["driver"] = "cli"
+			raw["expected_digests"] = map[string]string{"helloworld-qemu-x86_64": "sha256:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"}
+		}, want: "the cli driver cannot verify the digests of expected_digests"},
+		{name: "continue on error", modify: func(raw map[string]interface{}) {
+			raw["driver"] = "cli"
+			raw["continue_on_error"] = true
+		}},
+		{name: "validator", modify: func(raw map[string]interface{}) { raw["validator"] = "./check-kernel.sh --max-size 4M" }},
+		{name: "unterminated validator", modify: func(raw map[string]interface{}) { raw["validator"] = `./check-kernel.sh "unterminated` }, want: "invalid validator"},
+		{name: "validator with cli driver", modify: func(raw map[string]interface{}) {
+			raw["driver"] = "cli"
+			raw["validator"] = "./check-kernel.sh"
+		}, want: "the cli driver cannot run a validator"},
+		{name: "changed since", modify: func(raw map[string]interface{}) { raw["changed_since"] = "origin/main" }},
+		{name: "changed since with cli driver", modify: func(raw map[string]interface{}) {
+			raw["driver"] = "cli"
+			raw["changed_since"] = "origin/main"
+		}, want: "the cli driver cannot build the targets affected by changed_since only"},
+		{name: "license policy", modify: func(raw map[string]interface{}) {
+			raw["license_policy"] = map[string]interface{}{"allow": []string{"BSD-3-Clause", "MIT"}, "deny": []string{"GPL-3.0-only"}}
+		}},
+		{name: "empty license in license policy", modify: func(raw map[string]interface{}) {
+			raw["license_policy"] = map[string]interface{}{"deny": []string{""}}
+		}, want: "license_policy deny[0] must not be empty"},
+		{name: "license policy with cli driver", modify: func(raw map[string]interface{}) {
+			raw["driver"] = "cli"
+			raw["license_policy"] = map[string]interface{}{"allow": []string{"MIT"}}
+		}, want: "the cli driver cannot check the licenses of the components with license_policy"},
+		{name: "project", modify: func(raw map[string]interface{}) {
+			raw["project"] = map[string]interface{}{
+				"name":             "helloworld",
+				"unikraft_version": "stable",
+				"libraries":        []map[string]interface{}{{"name": "musl", "version": "stable"}},
+			}
+		}},
+		{name: "project without name", modify: func(raw map[string]interface{}) {
+			raw["project"] = map[string]interface{}{"unikraft_version": "stable"}
+		}, want: "project: invalid project spec: name is required"},
+		{name: "project with duplicate library", modify: func(raw map[string]interface{}) {
+			raw["project"] = map[string]interface{}{
+				"name":      "helloworld",
+				"libraries": []map[string]interface{}{{"name": "musl"}, {"name": "musl"}},
+			}
+		}, want: "library musl: listed more than once"},
+		{name: "project with kraftfile", modify: func(raw map[string]interface{}) {
+			raw["kraftfile"] = "Kraftfile"
+			raw["project"] = map[string]interface{}{"name": "helloworld"}
+		}, want: "project and kraftfile cannot be combined"},
+		{name: "project with cli driver", modify: func(raw map[string]interface{}) {
+			raw["driver"] = "cli"
+			raw["project"] = map[string]interface{}{"name": "helloworld"}
+		}, want: "the cli driver cannot build a project block without a Kraftfile"},
+		{name: "pull rate limit", modify: func(raw map[string]interface{}) { raw["pull_rate_limit"] = 1048576 }},
+		{name: "negative pull rate limit", modify: func(raw map[string]interface{}) { raw["pull_rate_limit"] = -1 }, want: "pull_rate_limit must not be negative"},
+		{name: "cli driver pull rate limit", modify: func(raw map[string]interface{}) {
+			raw["driver"] = "cli"
+			raw["pull_rate_limit"] = 1048576
+		}, want: "the cli driver cannot cap the bandwidth of downloads with pull_rate_limit"},
+		{name: "container image pinned by digest", modify: func(raw map[string]interface{}) {
+			raw["build_in_container"] = true
+			raw["container_image"] = "kraftkit.sh/myself-full@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+		}},
+		{name: "container image with invalid digest", modify: func(raw map[string]interface{}) {
+			raw["build_in_container"] = true
+			raw["container_image"] = "kraftkit.sh/myself-full@sha256:1234"
+		}, want: `container_image: image "kraftkit.sh/myself-full@sha256:1234" has an invalid digest`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := valid(t)
+			tt.modify(raw)
+
+			var b Builder
+			generated, _, err := b.Prepare(raw)
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.want == "" && len(generated) == 0:
+				t.Error("expected the builder to generate data")
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Errorf("expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestBuilderPrepareNormalizesFirecracker(t *testing.T) {
+	var b Builder
+	_, _, err := b.Prepare(map[string]interface{}{
+		"build_path":   t.TempDir(),
+		"architecture": "x86_64",
+		"platform":     "firecracker",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := b.config.BuildTargets()[0].Platform; got != "fc" {
+		t.Errorf("platform = %q, want fc", got)
+	}
+}
+
+func TestBuilderKraftDriver(t *testing.T) {
+	var b Builder
+	_, _, err := b.Prepare(map[string]interface{}{
+		"build_path":        t.TempDir(),
+		"architecture":      "arm64",
+		"platform":          "qemu",
+		"skip_unbuildable":  true,
+		"continue_on_error": true,
+		"validator":         "./check-kernel.sh",
+		"changed_since":     "origin/main",
+		"pull_rate_limit":   1048576,
+		"license_policy":    map[string]interface{}{"allow": []string{"MIT"}},
+		"expected_digests":  map[string]string{"helloworld-qemu-arm64": "sha256:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := b.kraftDriver(context.Background(), &packer.BasicUi{Reader: new(bytes.Buffer), Writer: new(bytes.Buffer)})
+	if !d.SkipUnbuildable {
+		t.Error("expected skip_unbuildable to skip unbuildable targets")
+	}
+	if !d.ContinueOnError {
+		t.Error("expected continue_on_error to continue on errors")
+	}
+	if d.Validator != "./check-kernel.sh" {
+		t.Errorf("validator = %q", d.Validator)
+	}
+	if d.ChangedSince != "origin/main" {
+		t.Errorf("changed since = %q", d.ChangedSince)
+	}
+	if d.RateLimit != 1048576 {
+		t.Errorf("rate limit = %d", d.RateLimit)
+	}
+	if !d.LicensePolicy.Permits("MIT") || d.LicensePolicy.Permits("GPL-3.0-only") {
+		t.Errorf("license policy = %+v, want only MIT allowed", d.LicensePolicy)
+	}
+	if got := d.ExpectedDigests["helloworld-qemu-arm64"]; got != "sha256:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" {
+		t.Errorf("expected digest = %q", got)
+	}
+}
+
+func TestBuilderKraftDriverProject(t *testing.T) {
+	var b Builder
+	_, _, err := b.Prepare(map[string]interface{}{
+		"build_path": t.TempDir(),
+		"targets": []map[string]interface{}{
+			{"architecture": "x86_64", "platform": "qemu"},
+			{"architecture": "arm64", "platform": "fc", "target": "helloworld-fc"},
+		},
+		"project": map[string]interface{}{
+			"name":             "helloworld",
+			"unikraft_version": "stable",
+			"libraries":        []map[string]interface{}{{"name": "musl", "version": "stable"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := b.kraftDriver(context.Background(), &packer.BasicUi{Reader: new(bytes.Buffer), Writer: new(bytes.Buffer)})
+	if d.Spec == nil {
+		t.Fatal("expected the project to be built from its spec")
+	}
+	if d.Spec.Name != "helloworld" || d.Spec.Unikraft.Version != "stable" {
+		t.Errorf("spec = %+v", d.Spec)
+	}
+	if len(d.Spec.Libraries) != 1 || d.Spec.Libraries[0].Name != "musl" {
+		t.Errorf("libraries = %+v", d.Spec.Libraries)
+	}
+
+	var names []string
+	for _, target := range d.Spec.Targets {
+		names = append(names, target.Name)
+	}
+	if got := strings.Join(names, ","); got != "helloworld-qemu-x86_64,helloworld-fc" {
+		t.Errorf("targets = %s", got)
+	}
+}
+
+func TestBuilderContainerPinnedImage(t *testing.T) {
+	runtime := filepath.Join(t.TempDir(), "docker")
+	if err := os.WriteFile(runtime, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	image := "kraftkit.sh/myself-full@" + pinnedDigest
+	ui := &packer.BasicUi{Reader: new(bytes.Buffer), Writer: new(bytes.Buffer)}
+
+	var resolved []string
+	b := Builder{
+		config: Config{BuildInContainer: true, ContainerRuntime: runtime, ContainerImage: image},
+		resolveImage: func(_ context.Context, ref string) (string, error) {
+			resolved = append(resolved, ref)
+			return otherDigest, nil
+		},
+	}
+
+	// The build does not start with an image of another digest.
+	if _, err := b.container(context.Background(), ui, nil); err == nil || !strings.Contains(err.Error(), otherDigest) {
+		t.Fatalf("expected digest mismatch, got %v", err)
+	}
+	if len(resolved) != 1 || resolved[0] != image {
+		t.Errorf("resolved %v, want %s", resolved, image)
+	}
+
+	b.resolveImage = func(context.Context, string) (string, error) { return pinnedDigest, nil }
+	container, err := b.container(context.Background(), ui, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(container.Home)
+
+	args, err := container.Args(nil, nil, "kraft", "build")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(strings.Join(args, " "), " "+image+" kraft build") {
+		t.Errorf("expected the commands to run in %s: %v", image, args)
+	}
+}
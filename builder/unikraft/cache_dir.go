@@ -0,0 +1,58 @@
+package unikraft
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// sharedCacheName is the directory of the shared cache in the cache
+// directory of the user.
+const sharedCacheName = "packer-plugin-unikraft"
+
+// CachePaths are the directories KraftKit keeps the manifest index and the
+// sources of pulled components in, within a cache directory.
+type CachePaths struct {
+	Manifests string
+	Sources   string
+}
+
+// NewCachePaths returns the paths of the cache in dir.
+func NewCachePaths(dir string) CachePaths {
+	return CachePaths{
+		Manifests: filepath.Join(dir, "manifests"),
+		Sources:   filepath.Join(dir, "sources"),
+	}
+}
+
+// Env returns the variables pointing the kraft executable at the cache.
+func (p CachePaths) Env() map[string]string {
+	return map[string]string{
+		"KRAFTKIT_PATHS_MANIFESTS": p.Manifests,
+		"KRAFTKIT_PATHS_SOURCES":   p.Sources,
+	}
+}
+
+// Clean removes the contents of the cache.  Only the directories of the cache
+// are removed, such that a cache directory shared with other files is safe
+// to clean.
+func (p CachePaths) Clean() error {
+	for _, dir := range []string{p.Manifests, p.Sources} {
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("could not clean cache: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// sharedCacheDir returns the cache directory shared by the builds of the
+// user.
+func sharedCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("could not locate the shared cache: %w", err)
+	}
+
+	return filepath.Join(dir, sharedCacheName), nil
+}
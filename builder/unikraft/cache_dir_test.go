@@ -0,0 +1,64 @@
+package unikraft
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCachePaths(t *testing.T) {
+	paths := NewCachePaths("/var/cache/unikraft")
+
+	want := CachePaths{
+		Manifests: "/var/cache/unikraft/manifests",
+		Sources:   "/var/cache/unikraft/sources",
+	}
+	if paths != want {
+		t.Errorf("paths = %+v, want %+v", paths, want)
+	}
+
+	env := map[string]string{
+		"KRAFTKIT_PATHS_MANIFESTS": "/var/cache/unikraft/manifests",
+		"KRAFTKIT_PATHS_SOURCES":   "/var/cache/unikraft/sources",
+	}
+	if got := paths.Env(); !reflect.DeepEqual(got, env) {
+		t.Errorf("env = %v, want %v", got, env)
+	}
+}
+
+func TestCachePathsClean(t *testing.T) {
+	dir := t.TempDir()
+	paths := NewCachePaths(dir)
+
+	for _, f := range []string{
+		filepath.Join(paths.Manifests, "index.yaml"),
+		filepath.Join(paths.Sources, "lib-musl", "musl.tar.gz"),
+		filepath.Join(dir, "other", "keep"),
+	} {
+		if err := os.MkdirAll(filepath.Dir(f), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(f, []byte("data"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := paths.Clean(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, d := range []string{paths.Manifests, paths.Sources} {
+		if _, err := os.Stat(d); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed, got %v", d, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "other", "keep")); err != nil {
+		t.Errorf("expected files outside of the cache to be kept: %v", err)
+	}
+
+	// Cleaning an empty cache is not an error.
+	if err := paths.Clean(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
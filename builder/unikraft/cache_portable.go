@@ -0,0 +1,136 @@
+package unikraft
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// portableCacheIndex is the name of the index file kept at the root of a
+// portable cache.
+const portableCacheIndex = "index.json"
+
+// PortableCache is an on-disk component layout which only records paths
+// relative to its root.  The directory can therefore be moved to another
+// location or machine and still be resolved, which allows a pre-populated
+// set of dependencies to be shipped alongside a project.
+type PortableCache struct {
+	Root string
+
+	// mu serializes updates of the index by concurrent pulls.
+	mu sync.Mutex
+}
+
+type portableCacheEntry struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Path    string `json:"path"`
+}
+
+// Place returns the directory a component should be pulled into, creating it
+// if it does not yet exist.
+func (c *PortableCache) Place(typ, name, version string) (string, error) {
+	dir := filepath.Join(c.Root, c.relPath(typ, name, version))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("could not create cache directory: %w", err)
+	}
+
+	return dir, nil
+}
+
+// Record saves a pulled component in the index of the cache.
+func (c *PortableCache) Record(typ, name, version string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries, err := c.entries()
+	if err != nil {
+		return err
+	}
+
+	entry := portableCacheEntry{
+		Type:    typ,
+		Name:    name,
+		Version: version,
+		Path:    filepath.ToSlash(c.relPath(typ, name, version)),
+	}
+
+	replaced := false
+	for i, e := range entries {
+		if e.Type == typ && e.Name == name && e.Version == version {
+			entries[i] = entry
+			replaced = true
+		}
+	}
+	if !replaced {
+		entries = append(entries, entry)
+	}
+
+	raw, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(c.Root, portableCacheIndex+".tmp")
+	if err := os.WriteFile(tmp, raw, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, filepath.Join(c.Root, portableCacheIndex))
+}
+
+// Resolve returns the absolute location of a previously recorded component
+// relative to the current root of the cache.
+func (c *PortableCache) Resolve(typ, name, version string) (string, error) {
+	entries, err := c.entries()
+	if err != nil {
+		return "", err
+	}
+
+	for _, e := range entries {
+		if e.Type != typ || e.Name != name || (version != "" && e.Version != version) {
+			continue
+		}
+
+		if filepath.IsAbs(e.Path) {
+			return "", fmt.Errorf("cache entry for %s/%s is not relocatable: %s", typ, name, e.Path)
+		}
+
+		dir := filepath.Join(c.Root, filepath.FromSlash(e.Path))
+		if _, err := os.Stat(dir); err != nil {
+			return "", fmt.Errorf("cache entry for %s/%s is missing: %w", typ, name, err)
+		}
+
+		return dir, nil
+	}
+
+	return "", fmt.Errorf("component %s/%s not found in cache", typ, name)
+}
+
+func (c *PortableCache) relPath(typ, name, version string) string {
+	if version == "" {
+		version = "latest"
+	}
+
+	return filepath.Join(typ, name, version)
+}
+
+func (c *PortableCache) entries() ([]portableCacheEntry, error) {
+	var entries []portableCacheEntry
+
+	raw, err := os.ReadFile(filepath.Join(c.Root, portableCacheIndex))
+	if os.IsNotExist(err) {
+		return entries, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, fmt.Errorf("could not parse cache index: %w", err)
+	}
+
+	return entries, nil
+}
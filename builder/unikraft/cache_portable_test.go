@@ -0,0 +1,74 @@
+package unikraft
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestPortableCacheRelocation(t *testing.T) {
+	base := t.TempDir()
+	cache := &PortableCache{Root: filepath.Join(base, "cache")}
+
+	dir, err := cache.Place("lib", "musl", "stable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Makefile.uk"), []byte("# musl"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Record("lib", "musl", "stable"); err != nil {
+		t.Fatal(err)
+	}
+
+	moved := filepath.Join(base, "elsewhere", "cache")
+	if err := os.MkdirAll(filepath.Dir(moved), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(cache.Root, moved); err != nil {
+		t.Fatal(err)
+	}
+
+	relocated := &PortableCache{Root: moved}
+	got, err := relocated.Resolve("lib", "musl", "stable")
+	if err != nil {
+		t.Fatalf("resolving relocated component: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(got, "Makefile.uk")); err != nil {
+		t.Errorf("relocated component not usable at %s: %v", got, err)
+	}
+
+	if _, err := relocated.Resolve("lib", "newlib", ""); err == nil {
+		t.Errorf("expected error resolving unknown component")
+	}
+}
+
+func TestPortableCacheConcurrentRecord(t *testing.T) {
+	cache := &PortableCache{Root: t.TempDir()}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			name := fmt.Sprintf("lib-%d", i)
+			if _, err := cache.Place("lib", name, "stable"); err != nil {
+				t.Error(err)
+				return
+			}
+			if err := cache.Record("lib", name, "stable"); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < 16; i++ {
+		if _, err := cache.Resolve("lib", fmt.Sprintf("lib-%d", i), "stable"); err != nil {
+			t.Errorf("lost a concurrent record: %v", err)
+		}
+	}
+}
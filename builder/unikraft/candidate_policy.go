@@ -0,0 +1,113 @@
+package unikraft
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// PreferHighestVersion prefers the candidate with the highest version.
+	PreferHighestVersion = "highest"
+	// PreferLowestVersion prefers the candidate with the lowest version.
+	PreferLowestVersion = "lowest"
+)
+
+// candidateFormats are the package formats candidates may be preferred by.
+var candidateFormats = []string{"manifest", "oci"}
+
+// CandidatePolicy picks a single package among the several candidates the
+// catalog may return for a component, rather than failing the build.  Each
+// preference narrows the candidates down to the ones it matches, and is
+// ignored when it matches none of them.
+type CandidatePolicy struct {
+	// PreferFormat prefers the candidates of a package format, `oci` or
+	// `manifest`.
+	PreferFormat string
+	// PreferSource prefers the candidates whose source contains it.
+	PreferSource string
+	// PreferVersion prefers the candidates of a version, or the highest or
+	// lowest version.
+	PreferVersion string
+}
+
+// IsZero returns whether the policy has no preference.
+func (p CandidatePolicy) IsZero() bool {
+	return p == CandidatePolicy{}
+}
+
+// Validate checks that the preferred format is known.
+func (p CandidatePolicy) Validate() error {
+	if p.PreferFormat == "" {
+		return nil
+	}
+
+	for _, f := range candidateFormats {
+		if p.PreferFormat == f {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown prefer_format %q, expected %s", p.PreferFormat, strings.Join(candidateFormats, " or "))
+}
+
+// Resolve returns the index of the candidate the policy picks, applying the
+// format, source and version preferences in turn.  It fails when more than
+// one candidate remains.
+func (p CandidatePolicy) Resolve(candidates []CatalogEntry) (int, error) {
+	if len(candidates) == 0 {
+		return -1, fmt.Errorf("no candidates")
+	}
+
+	remaining := make([]int, len(candidates))
+	for i := range candidates {
+		remaining[i] = i
+	}
+
+	prefer := func(match func(c CatalogEntry) bool) {
+		var matched []int
+		for _, i := range remaining {
+			if match(candidates[i]) {
+				matched = append(matched, i)
+			}
+		}
+		if len(matched) > 0 {
+			remaining = matched
+		}
+	}
+
+	if p.PreferFormat != "" {
+		prefer(func(c CatalogEntry) bool { return c.Format == p.PreferFormat })
+	}
+	if p.PreferSource != "" {
+		prefer(func(c CatalogEntry) bool { return strings.Contains(c.Source, p.PreferSource) })
+	}
+
+	switch p.PreferVersion {
+	case "":
+	case PreferHighestVersion, PreferLowestVersion:
+		// Versions which are not numeric, such as `stable`, compare as lower.
+		version := func(c CatalogEntry) string { return strings.TrimPrefix(c.Version, "v") }
+		best := version(candidates[remaining[0]])
+		for _, i := range remaining[1:] {
+			c := compareSpecVersions(version(candidates[i]), best)
+			if (p.PreferVersion == PreferHighestVersion && c > 0) || (p.PreferVersion == PreferLowestVersion && c < 0) {
+				best = version(candidates[i])
+			}
+		}
+		prefer(func(c CatalogEntry) bool { return compareSpecVersions(version(c), best) == 0 })
+	default:
+		prefer(func(c CatalogEntry) bool { return c.Version == p.PreferVersion })
+	}
+
+	if len(remaining) == 1 {
+		return remaining[0], nil
+	}
+
+	var names []string
+	for _, i := range remaining {
+		c := candidates[i]
+		names = append(names, fmt.Sprintf("%s@%s (%s, %s)", c.Name, c.Version, c.Format, c.Source))
+	}
+
+	return -1, fmt.Errorf("%d candidates remain, set candidate_resolution to pick one of %s", len(remaining), strings.Join(names, ", "))
+}
@@ -0,0 +1,54 @@
+package unikraft
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCandidatePolicyResolve(t *testing.T) {
+	candidates := []CatalogEntry{
+		{Name: "musl", Version: "0.15.0", Format: "manifest", Source: "https://github.com/unikraft/lib-musl.git"},
+		{Name: "musl", Version: "0.16.1", Format: "manifest", Source: "https://github.com/unikraft/lib-musl.git"},
+		{Name: "musl", Version: "0.16.1", Format: "oci", Source: "unikraft.org/lib/musl"},
+		{Name: "musl", Version: "stable", Format: "oci", Source: "mirror.example.com/lib/musl"},
+	}
+
+	tests := []struct {
+		name   string
+		policy CandidatePolicy
+		want   int
+		err    string
+	}{
+		{name: "no preference", err: "4 candidates remain"},
+		{name: "format", policy: CandidatePolicy{PreferFormat: "manifest"}, err: "2 candidates remain"},
+		{name: "format and highest version", policy: CandidatePolicy{PreferFormat: "manifest", PreferVersion: PreferHighestVersion}, want: 1},
+		{name: "lowest version", policy: CandidatePolicy{PreferVersion: PreferLowestVersion}, want: 3},
+		{name: "source", policy: CandidatePolicy{PreferSource: "unikraft.org"}, want: 2},
+		{name: "exact version", policy: CandidatePolicy{PreferVersion: "stable"}, want: 3},
+		{name: "unmatched source is ignored", policy: CandidatePolicy{PreferSource: "example.org", PreferVersion: "0.15.0"}, want: 0},
+		{name: "remaining candidates are listed", policy: CandidatePolicy{PreferVersion: PreferHighestVersion}, err: "musl@0.16.1 (oci, unikraft.org/lib/musl)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.policy.Resolve(candidates)
+			switch {
+			case tt.err == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Errorf("expected an error containing %q, got %v", tt.err, err)
+			case tt.err == "" && got != tt.want:
+				t.Errorf("Resolve() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCandidatePolicyValidate(t *testing.T) {
+	if err := (CandidatePolicy{PreferFormat: "oci"}).Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := (CandidatePolicy{PreferFormat: "tarball"}).Validate(); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
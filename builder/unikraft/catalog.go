@@ -0,0 +1,116 @@
+package unikraft
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"kraftkit.sh/pack"
+	"kraftkit.sh/packmanager"
+	"kraftkit.sh/unikraft"
+)
+
+// DefaultCatalogLimit caps the number of packages returned by a catalog query
+// when no explicit limit is given.
+const DefaultCatalogLimit = 100
+
+// Catalog queries the package catalog.  Either a name or at least one
+// component type must be given; a type-only query returns every component of
+// those types.
+type Catalog struct {
+	Limit   int
+	Name    string
+	Offset  int
+	Types   []unikraft.ComponentType
+	Update  bool
+	Version string
+}
+
+// catalogItem is the subset of a package needed to select catalog results.
+type catalogItem interface {
+	Name() string
+	Type() unikraft.ComponentType
+}
+
+func (opts *Catalog) CatalogCmd(ctx context.Context) ([]pack.Package, error) {
+	if len(opts.Name) == 0 && len(opts.Types) == 0 {
+		return nil, fmt.Errorf("cannot query the catalog without a name or component type")
+	}
+
+	qopts := []packmanager.QueryOption{
+		packmanager.WithUpdate(opts.Update),
+	}
+	if len(opts.Types) > 0 {
+		qopts = append(qopts, packmanager.WithTypes(opts.Types...))
+	}
+	if len(opts.Name) > 0 {
+		qopts = append(qopts, packmanager.WithName(opts.Name))
+	}
+	if len(opts.Version) > 0 {
+		qopts = append(qopts, packmanager.WithVersion(opts.Version))
+	}
+
+	packages, err := packmanager.G(ctx).Catalog(ctx, qopts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return selectCatalog(packages, opts.Types, opts.Offset, opts.Limit), nil
+}
+
+// selectCatalog keeps the items of the given types, sorted by name, and
+// returns the page starting at offset with at most limit items.  A limit of
+// zero uses DefaultCatalogLimit.
+func selectCatalog[T catalogItem](items []T, types []unikraft.ComponentType, offset, limit int) []T {
+	if limit <= 0 {
+		limit = DefaultCatalogLimit
+	}
+
+	selected := []T{}
+	for _, item := range items {
+		if len(types) > 0 && !containsType(types, item.Type()) {
+			continue
+		}
+		selected = append(selected, item)
+	}
+
+	sort.SliceStable(selected, func(i, j int) bool {
+		if selected[i].Type() != selected[j].Type() {
+			return selected[i].Type() < selected[j].Type()
+		}
+		return selected[i].Name() < selected[j].Name()
+	})
+
+	if offset >= len(selected) {
+		return []T{}
+	}
+	if offset > 0 {
+		selected = selected[offset:]
+	}
+	if len(selected) > limit {
+		selected = selected[:limit]
+	}
+
+	return selected
+}
+
+func containsType(types []unikraft.ComponentType, typ unikraft.ComponentType) bool {
+	for _, t := range types {
+		if t == typ {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewCatalogEntry describes a package found in the catalog.
+func NewCatalogEntry(p pack.Package) CatalogEntry {
+	return CatalogEntry{
+		Name:    p.Name(),
+		Version: p.Version(),
+		Type:    string(p.Type()),
+		Format:  string(p.Format()),
+		Source:  componentSource(p.Metadata()),
+	}
+}
@@ -0,0 +1,69 @@
+package unikraft
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// catalogComponentTypes are the component types the catalog may be filtered
+// by.
+var catalogComponentTypes = []string{"core", "arch", "plat", "lib", "app"}
+
+// CatalogEntry is the plain description of a package found in the catalog.
+type CatalogEntry struct {
+	Name    string `mapstructure:"name"`
+	Version string `mapstructure:"version"`
+	Type    string `mapstructure:"type"`
+	Format  string `mapstructure:"format"`
+	Source  string `mapstructure:"source"`
+}
+
+// ValidateCatalogTypes checks that every type names a component type.
+func ValidateCatalogTypes(types []string) error {
+	for _, t := range types {
+		known := false
+		for _, c := range catalogComponentTypes {
+			known = known || t == c
+		}
+
+		if !known {
+			return fmt.Errorf("unknown component type %q, expected one of %s", t, strings.Join(catalogComponentTypes, ", "))
+		}
+	}
+
+	return nil
+}
+
+// componentSource extracts the URL a package is retrieved from out of its
+// metadata, or returns an empty string when the metadata has none.
+func componentSource(metadata interface{}) string {
+	if metadata == nil {
+		return ""
+	}
+
+	raw, err := json.Marshal(metadata)
+	if err != nil {
+		return ""
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return ""
+	}
+
+	for _, key := range []string{"origin", "source", "url"} {
+		for k, value := range fields {
+			if !strings.EqualFold(k, key) {
+				continue
+			}
+
+			var source string
+			if json.Unmarshal(value, &source) == nil && len(source) > 0 {
+				return source
+			}
+		}
+	}
+
+	return ""
+}
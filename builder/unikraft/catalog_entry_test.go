@@ -0,0 +1,40 @@
+package unikraft
+
+import "testing"
+
+func TestValidateCatalogTypes(t *testing.T) {
+	if err := ValidateCatalogTypes([]string{"lib", "app"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := ValidateCatalogTypes([]string{"lib", "library"}); err == nil {
+		t.Error("expected an unknown type to be rejected")
+	}
+}
+
+func TestComponentSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata interface{}
+		want     string
+	}{
+		{name: "nil"},
+		{name: "origin", metadata: map[string]string{
+			"name":   "musl",
+			"origin": "https://github.com/unikraft/lib-musl.git",
+		}, want: "https://github.com/unikraft/lib-musl.git"},
+		{name: "origin before url", metadata: map[string]string{
+			"url":    "https://manifests.kraftkit.sh/libs/musl.yaml",
+			"Origin": "https://github.com/unikraft/lib-musl.git",
+		}, want: "https://github.com/unikraft/lib-musl.git"},
+		{name: "none", metadata: map[string]string{"name": "musl"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := componentSource(tt.metadata); got != tt.want {
+				t.Errorf("componentSource() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
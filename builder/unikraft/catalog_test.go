@@ -0,0 +1,50 @@
+package unikraft
+
+import (
+	"reflect"
+	"testing"
+
+	"kraftkit.sh/unikraft"
+)
+
+type fakeCatalogItem struct {
+	name string
+	typ  unikraft.ComponentType
+}
+
+func (f fakeCatalogItem) Name() string                 { return f.name }
+func (f fakeCatalogItem) Type() unikraft.ComponentType { return f.typ }
+
+func TestSelectCatalogTypeOnly(t *testing.T) {
+	catalog := []fakeCatalogItem{
+		{"nginx", unikraft.ComponentTypeApp},
+		{"musl", unikraft.ComponentTypeLib},
+		{"unikraft", unikraft.ComponentTypeCore},
+		{"lwip", unikraft.ComponentTypeLib},
+		{"helloworld", unikraft.ComponentTypeApp},
+		{"compiler-rt", unikraft.ComponentTypeLib},
+	}
+
+	names := func(items []fakeCatalogItem) []string {
+		out := []string{}
+		for _, i := range items {
+			out = append(out, i.name)
+		}
+		return out
+	}
+
+	got := names(selectCatalog(catalog, []unikraft.ComponentType{unikraft.ComponentTypeLib}, 0, 0))
+	if want := []string{"compiler-rt", "lwip", "musl"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("type-only query: got %v, want %v", got, want)
+	}
+
+	got = names(selectCatalog(catalog, []unikraft.ComponentType{unikraft.ComponentTypeLib}, 1, 1))
+	if want := []string{"lwip"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("paginated query: got %v, want %v", got, want)
+	}
+
+	got = names(selectCatalog(catalog, []unikraft.ComponentType{unikraft.ComponentTypeLib}, 5, 0))
+	if len(got) != 0 {
+		t.Errorf("expected empty page past the end, got %v", got)
+	}
+}
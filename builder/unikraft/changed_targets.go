@@ -0,0 +1,139 @@
+package unikraft
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// coreArchitectureDirs maps architectures to their directory in the Unikraft
+// core.
+var coreArchitectureDirs = map[string]string{
+	"x86_64": "x86",
+	"arm64":  "arm",
+	"arm":    "arm",
+}
+
+// corePlatformDirs maps platforms to their directory in the Unikraft core.
+var corePlatformDirs = map[string]string{
+	"qemu":        "kvm",
+	"kvm":         "kvm",
+	"fc":          "kvm",
+	"firecracker": "kvm",
+	"xen":         "xen",
+	"linuxu":      "linuxu",
+}
+
+// targetChangeScope lists the paths, relative to the workdir, which only
+// affect a single target.  A directory ends with a slash.
+type targetChangeScope struct {
+	Name  string
+	Paths []string
+}
+
+// newTargetChangeScope returns the scope of a target: its configuration file
+// and the architecture and platform directories of the Unikraft core, when
+// the core is within the workdir.
+func newTargetChangeScope(workdir, name, configFile, corePath, architecture, platform string) targetChangeScope {
+	scope := targetChangeScope{Name: name}
+
+	if rel, ok := relativeTo(workdir, configFile); ok {
+		scope.Paths = append(scope.Paths, rel)
+	}
+
+	if core, ok := relativeTo(workdir, corePath); ok {
+		if dir, ok := coreArchitectureDirs[architecture]; ok {
+			scope.Paths = append(scope.Paths, path.Join(core, "arch", dir)+"/")
+		}
+		if dir, ok := corePlatformDirs[platform]; ok {
+			scope.Paths = append(scope.Paths, path.Join(core, "plat", dir)+"/")
+		}
+	}
+
+	return scope
+}
+
+func relativeTo(base, target string) (string, bool) {
+	if len(target) == 0 {
+		return "", false
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(base, target)
+	}
+
+	rel, err := filepath.Rel(base, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", false
+	}
+
+	return filepath.ToSlash(rel), true
+}
+
+// changedTargets returns the names of the targets affected by the changed
+// files.  Since any file outside of the scope of a target, e.g. the
+// Kraftfile or a library, may affect every target, the mapping is only
+// certain when every changed file belongs to the scope of some target.
+func changedTargets(changed []string, scopes []targetChangeScope) ([]string, bool) {
+	affected := map[string]bool{}
+
+	for _, file := range changed {
+		file = filepath.ToSlash(file)
+
+		matched := false
+		for _, scope := range scopes {
+			for _, p := range scope.Paths {
+				if file == p || (strings.HasSuffix(p, "/") && strings.HasPrefix(file, p)) {
+					affected[scope.Name] = true
+					matched = true
+				}
+			}
+		}
+
+		if !matched {
+			return nil, false
+		}
+	}
+
+	names := []string{}
+	for _, scope := range scopes {
+		if affected[scope.Name] {
+			names = append(names, scope.Name)
+		}
+	}
+
+	return names, true
+}
+
+// gitChangedFiles returns the files of dir, relative to it, which changed
+// since ref, including files which are not yet tracked.
+func gitChangedFiles(ctx context.Context, dir, ref string) ([]string, error) {
+	var files []string
+
+	for _, args := range [][]string{
+		{"diff", "--name-only", "--relative", ref, "--"},
+		{"ls-files", "--others", "--exclude-standard"},
+	} {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = dir
+
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+		}
+
+		for _, line := range strings.Split(string(out), "\n") {
+			if line = strings.TrimSpace(line); len(line) > 0 {
+				files = append(files, line)
+			}
+		}
+	}
+
+	return files, nil
+}
@@ -0,0 +1,118 @@
+package unikraft
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestChangedTargets(t *testing.T) {
+	workdir := "/src/nginx"
+	core := "/src/nginx/.unikraft/unikraft"
+
+	scopes := []targetChangeScope{
+		newTargetChangeScope(workdir, "nginx-qemu-x86_64", ".config.nginx-qemu-x86_64", core, "x86_64", "qemu"),
+		newTargetChangeScope(workdir, "nginx-fc-x86_64", ".config.nginx-fc-x86_64", core, "x86_64", "fc"),
+		newTargetChangeScope(workdir, "nginx-xen-arm64", ".config.nginx-xen-arm64", core, "arm64", "xen"),
+	}
+
+	tests := []struct {
+		name    string
+		changed []string
+		want    []string
+		certain bool
+	}{
+		{
+			name:    "target configuration",
+			changed: []string{".config.nginx-xen-arm64"},
+			want:    []string{"nginx-xen-arm64"},
+			certain: true,
+		},
+		{
+			name:    "architecture",
+			changed: []string{".unikraft/unikraft/arch/x86/x86_64/entry64.S"},
+			want:    []string{"nginx-qemu-x86_64", "nginx-fc-x86_64"},
+			certain: true,
+		},
+		{
+			name:    "platform and configuration",
+			changed: []string{".unikraft/unikraft/plat/xen/setup.c", ".config.nginx-fc-x86_64"},
+			want:    []string{"nginx-fc-x86_64", "nginx-xen-arm64"},
+			certain: true,
+		},
+		{
+			name:    "nothing",
+			changed: nil,
+			want:    []string{},
+			certain: true,
+		},
+		{
+			name:    "Kraftfile",
+			changed: []string{".config.nginx-fc-x86_64", "Kraftfile"},
+			certain: false,
+		},
+	}
+
+	for _, tt := range tests {
+		got, certain := changedTargets(tt.changed, scopes)
+		if certain != tt.certain {
+			t.Errorf("%s: certain = %v, want %v", tt.name, certain, tt.certain)
+			continue
+		}
+		if certain && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: changedTargets() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	outside := newTargetChangeScope(workdir, "nginx-qemu-x86_64", "/etc/unikraft/.config", "/opt/unikraft", "x86_64", "qemu")
+	if len(outside.Paths) != 0 {
+		t.Errorf("expected no paths outside of the workdir, got %v", outside.Paths)
+	}
+}
+
+func TestGitChangedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	write := func(name string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init", "-q")
+	write("Kraftfile")
+	write(".config.nginx-qemu-x86_64")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+
+	os.WriteFile(filepath.Join(dir, ".config.nginx-qemu-x86_64"), []byte("CONFIG_LIBVFSCORE=y\n"), 0o644)
+	write(".config.nginx-fc-x86_64")
+
+	got, err := gitChangedFiles(context.Background(), dir, "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(got)
+
+	if want := []string{".config.nginx-fc-x86_64", ".config.nginx-qemu-x86_64"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("gitChangedFiles() = %v, want %v", got, want)
+	}
+
+	if _, err := gitChangedFiles(context.Background(), dir, "no-such-ref"); err == nil {
+		t.Error("expected an error for an unknown ref")
+	}
+}
@@ -0,0 +1,102 @@
+package unikraft
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// ChecksumEnforce fails pulls whose checksum does not match.
+	ChecksumEnforce = "enforce"
+	// ChecksumWarn reports mismatching checksums and pulls the components
+	// again without verifying them.
+	ChecksumWarn = "warn"
+	// ChecksumOff does not verify the checksums of the pulled components.
+	ChecksumOff = "off"
+)
+
+// ChecksumPolicies are the values of checksum_policy.
+var ChecksumPolicies = []string{ChecksumEnforce, ChecksumWarn, ChecksumOff}
+
+// checksumDigestPattern matches the digests quoted by checksum errors, with
+// or without their algorithm.
+var checksumDigestPattern = regexp.MustCompile(`(?:sha(?:256|512):)?[0-9a-fA-F]{32,}`)
+
+// ChecksumMismatch is the error of a pull whose checksum does not match the
+// one of the manifest of the component.
+type ChecksumMismatch struct {
+	Package string
+	// Expected and Actual are the digests quoted by Err, if any.
+	Expected string
+	Actual   string
+	Err      error
+}
+
+func (e *ChecksumMismatch) Error() string {
+	if e.Expected != "" && e.Actual != "" {
+		return fmt.Sprintf("checksum of %s does not match: expected %s, got %s", e.Package, e.Expected, e.Actual)
+	}
+
+	return fmt.Sprintf("checksum of %s does not match: %v", e.Package, e.Err)
+}
+
+func (e *ChecksumMismatch) Unwrap() error {
+	return e.Err
+}
+
+// checksumMismatch returns err as the ChecksumMismatch of pulling pkg, when it
+// reports a checksum failure.  KraftKit reports them as plain errors, which
+// quote the expected digest before the actual one.
+func checksumMismatch(pkg string, err error) (*ChecksumMismatch, bool) {
+	var mismatch *ChecksumMismatch
+	if errors.As(err, &mismatch) {
+		return mismatch, true
+	}
+
+	if err == nil || !strings.Contains(strings.ToLower(err.Error()), "checksum") {
+		return nil, false
+	}
+
+	mismatch = &ChecksumMismatch{Package: pkg, Err: err}
+	if digests := checksumDigestPattern.FindAllString(err.Error(), 2); len(digests) == 2 {
+		mismatch.Expected = digests[0]
+		mismatch.Actual = digests[1]
+	}
+
+	return mismatch, true
+}
+
+// pullVerified pulls pkg with pull, verifying its checksum according to
+// policy, ChecksumEnforce when empty.  With ChecksumWarn, a mismatch is
+// reported to warn and pkg is pulled again without verifying it.
+func pullVerified(policy, pkg string, warn func(string), pull func(checksum bool) error) error {
+	if policy == ChecksumOff {
+		return pull(false)
+	}
+
+	err := pull(true)
+	mismatch, ok := checksumMismatch(pkg, err)
+	if !ok {
+		return err
+	}
+
+	if policy != ChecksumWarn {
+		return mismatch
+	}
+
+	warn(mismatch.Error() + ", pulling it unverified")
+	return pull(false)
+}
+
+// checkChecksumPolicy checks that policy is one of ChecksumPolicies.
+func checkChecksumPolicy(policy string) error {
+	for _, p := range ChecksumPolicies {
+		if policy == p {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown checksum_policy %q, expected one of %s", policy, strings.Join(ChecksumPolicies, ", "))
+}
@@ -0,0 +1,89 @@
+package unikraft
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	expectedDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	actualDigest   = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+)
+
+func TestChecksumMismatch(t *testing.T) {
+	mismatch, ok := checksumMismatch("lib-musl", fmt.Errorf("could not verify checksum: expected %s but got %s", expectedDigest, actualDigest))
+	if !ok {
+		t.Fatal("expected a checksum mismatch")
+	}
+	if mismatch.Expected != expectedDigest || mismatch.Actual != actualDigest {
+		t.Errorf("digests = %s, %s", mismatch.Expected, mismatch.Actual)
+	}
+	if want := "checksum of lib-musl does not match: expected " + expectedDigest + ", got " + actualDigest; mismatch.Error() != want {
+		t.Errorf("Error() = %s, want %s", mismatch.Error(), want)
+	}
+
+	if _, ok := checksumMismatch("lib-musl", errors.New("connection reset by peer")); ok {
+		t.Error("expected other errors not to be checksum mismatches")
+	}
+	if _, ok := checksumMismatch("lib-musl", nil); ok {
+		t.Error("expected no error not to be a checksum mismatch")
+	}
+}
+
+func TestPullVerified(t *testing.T) {
+	mismatch := fmt.Errorf("checksum mismatch: %s != %s", expectedDigest, actualDigest)
+
+	tests := []struct {
+		policy string
+		pulls  []bool
+		warned bool
+		err    string
+	}{
+		{policy: "", pulls: []bool{true}, err: "expected " + expectedDigest + ", got " + actualDigest},
+		{policy: ChecksumEnforce, pulls: []bool{true}, err: "does not match"},
+		{policy: ChecksumWarn, pulls: []bool{true, false}, warned: true},
+		{policy: ChecksumOff, pulls: []bool{false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			var pulls []bool
+			var warnings []string
+			err := pullVerified(tt.policy, "lib-musl", func(msg string) {
+				warnings = append(warnings, msg)
+			}, func(checksum bool) error {
+				pulls = append(pulls, checksum)
+				if checksum {
+					return mismatch
+				}
+				return nil
+			})
+
+			if tt.err == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+				t.Errorf("expected error containing %q, got %v", tt.err, err)
+			}
+			if !reflect.DeepEqual(pulls, tt.pulls) {
+				t.Errorf("pulls = %v, want %v", pulls, tt.pulls)
+			}
+			if tt.warned != (len(warnings) == 1) {
+				t.Errorf("warnings = %q", warnings)
+			}
+		})
+	}
+}
+
+func TestPullVerifiedReturnsOtherErrors(t *testing.T) {
+	calls := 0
+	err := pullVerified(ChecksumWarn, "lib-musl", func(string) {}, func(bool) error {
+		calls++
+		return errors.New("connection reset by peer")
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("expected the error to be returned without pulling again, got %v after %d pulls", err, calls)
+	}
+}
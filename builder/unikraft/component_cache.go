@@ -0,0 +1,47 @@
+package unikraft
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// componentNoCache reports whether the catalog query and pull of a component
+// bypass the cache, either because caching is disabled for all components or
+// because the component is listed in names.
+func componentNoCache(all bool, names []string, component string) bool {
+	if all {
+		return true
+	}
+
+	for _, name := range names {
+		if name == component {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateComponentNames returns an error listing the names which are not
+// among the known components.
+func validateComponentNames(names, known []string) error {
+	exists := make(map[string]bool, len(known))
+	for _, name := range known {
+		exists[name] = true
+	}
+
+	var unknown []string
+	for _, name := range names {
+		if !exists[name] {
+			unknown = append(unknown, name)
+		}
+	}
+
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	sort.Strings(unknown)
+	return fmt.Errorf("unknown components: %s", strings.Join(unknown, ", "))
+}
@@ -0,0 +1,53 @@
+package unikraft
+
+import "testing"
+
+func TestComponentNoCache(t *testing.T) {
+	components := []string{"unikraft", "musl", "lwip"}
+
+	tests := []struct {
+		name  string
+		all   bool
+		names []string
+		want  map[string]bool
+	}{
+		{
+			name: "cache everything",
+			want: map[string]bool{"unikraft": false, "musl": false, "lwip": false},
+		},
+		{
+			name:  "only the listed component",
+			names: []string{"musl"},
+			want:  map[string]bool{"unikraft": false, "musl": true, "lwip": false},
+		},
+		{
+			name:  "global flag wins",
+			all:   true,
+			names: []string{"musl"},
+			want:  map[string]bool{"unikraft": true, "musl": true, "lwip": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, c := range components {
+				if got := componentNoCache(tt.all, tt.names, c); got != tt.want[c] {
+					t.Errorf("componentNoCache(%s) = %v, want %v", c, got, tt.want[c])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateComponentNames(t *testing.T) {
+	known := []string{"unikraft", "musl"}
+
+	if err := validateComponentNames([]string{"musl"}, known); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	err := validateComponentNames([]string{"newlib", "musl", "lwip"}, known)
+	if err == nil || err.Error() != "unknown components: lwip, newlib" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
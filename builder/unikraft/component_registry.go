@@ -0,0 +1,137 @@
+package unikraft
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ComponentRegistry records the components pulled during an invocation so
+// that projects sharing a component reuse the first copy rather than pulling
+// it again.  It is safe for concurrent use.
+type ComponentRegistry struct {
+	mu      sync.Mutex
+	entries map[string]sharedComponent
+	pulling map[string]*sync.Mutex
+}
+
+type sharedComponent struct {
+	path    string
+	version string
+	digest  string
+}
+
+// NewComponentRegistry returns an empty registry.
+func NewComponentRegistry() *ComponentRegistry {
+	return &ComponentRegistry{
+		entries: map[string]sharedComponent{},
+		pulling: map[string]*sync.Mutex{},
+	}
+}
+
+// lock serializes the handling of a component, so that concurrent projects
+// wait for the first pull rather than pulling the component twice.
+func (r *ComponentRegistry) lock(key string) func() {
+	r.mu.Lock()
+	m, ok := r.pulling[key]
+	if !ok {
+		m = &sync.Mutex{}
+		r.pulling[key] = m
+	}
+	r.mu.Unlock()
+
+	m.Lock()
+	return m.Unlock
+}
+
+// Ensure places the component identified by key and version at dst.  A copy
+// registered by another project is reused when its version matches and its
+// contents are unchanged; otherwise pull is called and the result registered.
+// It reports whether an existing copy was reused.  A nil registry always
+// pulls.
+func (r *ComponentRegistry) Ensure(key, version, dst string, pull func() error) (bool, error) {
+	if r == nil {
+		return false, pull()
+	}
+
+	unlock := r.lock(key)
+	defer unlock()
+
+	r.mu.Lock()
+	shared, ok := r.entries[key]
+	r.mu.Unlock()
+
+	if ok && shared.version == version && shared.path != dst {
+		if digest, err := dirDigest(shared.path); err == nil && digest == shared.digest {
+			if err := copyDir(shared.path, dst); err != nil {
+				return false, fmt.Errorf("could not reuse %s: %w", shared.path, err)
+			}
+			return true, nil
+		}
+	}
+
+	if err := pull(); err != nil {
+		return false, err
+	}
+
+	digest, err := dirDigest(dst)
+	if err != nil {
+		// The component cannot be verified, so it is not shared.
+		return false, nil
+	}
+
+	r.mu.Lock()
+	r.entries[key] = sharedComponent{path: dst, version: version, digest: digest}
+	r.mu.Unlock()
+
+	return false, nil
+}
+
+// copyDir copies the regular files, directories and symbolic links below src
+// to dst.
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0o700)
+
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+
+		case info.Mode().IsRegular():
+			in, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer in.Close()
+
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(out, in); err != nil {
+				out.Close()
+				return err
+			}
+			return out.Close()
+		}
+
+		return nil
+	})
+}
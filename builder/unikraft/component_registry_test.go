@@ -0,0 +1,101 @@
+package unikraft
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestComponentRegistrySharesPulls(t *testing.T) {
+	dir := t.TempDir()
+	registry := NewComponentRegistry()
+	key := resolutionKey("lib", "musl", "stable", "")
+
+	var pulls int32
+	pull := func(dst string) func() error {
+		return func() error {
+			atomic.AddInt32(&pulls, 1)
+			if err := os.MkdirAll(filepath.Join(dst, "include"), 0o755); err != nil {
+				return err
+			}
+			return os.WriteFile(filepath.Join(dst, "include", "stdio.h"), []byte("/* musl */\n"), 0o644)
+		}
+	}
+
+	projects := []string{
+		filepath.Join(dir, "app-nginx", ".unikraft", "libs", "musl"),
+		filepath.Join(dir, "app-redis", ".unikraft", "libs", "musl"),
+	}
+
+	var wg sync.WaitGroup
+	var reused int32
+	for _, dst := range projects {
+		wg.Add(1)
+		go func(dst string) {
+			defer wg.Done()
+
+			ok, err := registry.Ensure(key, "stable", dst, pull(dst))
+			if err != nil {
+				t.Error(err)
+			}
+			if ok {
+				atomic.AddInt32(&reused, 1)
+			}
+		}(dst)
+	}
+	wg.Wait()
+
+	if pulls != 1 || reused != 1 {
+		t.Errorf("expected one pull and one reuse, got %d pulls and %d reuses", pulls, reused)
+	}
+	for _, dst := range projects {
+		if _, err := os.Stat(filepath.Join(dst, "include", "stdio.h")); err != nil {
+			t.Errorf("expected component in %s: %v", dst, err)
+		}
+	}
+
+	// A different version is pulled again.
+	other := filepath.Join(dir, "app-sqlite", ".unikraft", "libs", "musl")
+	if ok, err := registry.Ensure(key, "staging", other, pull(other)); err != nil || ok {
+		t.Errorf("expected a version mismatch to pull, got reuse=%v err=%v", ok, err)
+	}
+	if pulls != 2 {
+		t.Errorf("expected 2 pulls, got %d", pulls)
+	}
+}
+
+func TestComponentRegistryDigestMismatch(t *testing.T) {
+	dir := t.TempDir()
+	registry := NewComponentRegistry()
+
+	first := filepath.Join(dir, "a")
+	pulled := 0
+	pull := func(dst string) func() error {
+		return func() error {
+			pulled++
+			if err := os.MkdirAll(dst, 0o755); err != nil {
+				return err
+			}
+			return os.WriteFile(filepath.Join(dst, "Makefile.uk"), []byte("x"), 0o644)
+		}
+	}
+
+	if _, err := registry.Ensure("k", "v", first, pull(first)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first copy was modified since it was registered.
+	if err := os.WriteFile(filepath.Join(first, "Makefile.uk"), []byte("changed"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	second := filepath.Join(dir, "b")
+	if ok, err := registry.Ensure("k", "v", second, pull(second)); err != nil || ok {
+		t.Errorf("expected a modified copy not to be reused, got reuse=%v err=%v", ok, err)
+	}
+	if pulled != 2 {
+		t.Errorf("expected 2 pulls, got %d", pulled)
+	}
+}
@@ -1,46 +1,800 @@
-//go:generate packer-sdc mapstructure-to-hcl2 -type Config
+//go:generate packer-sdc mapstructure-to-hcl2 -type Config,TargetConfig,TestBootConfig,FirecrackerConfig,XenConfig,BuildLogConfig,CandidateResolutionConfig,LicensePolicyConfig,SourceAuthConfig,GDBSmokeTestConfig,ProjectConfig,ProjectLibraryConfig
 
 package unikraft
 
 import (
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/packer-plugin-sdk/common"
 	"github.com/hashicorp/packer-plugin-sdk/packer"
 	"github.com/hashicorp/packer-plugin-sdk/template/config"
 	"github.com/hashicorp/packer-plugin-sdk/template/interpolate"
+	"github.com/mattn/go-shellwords"
 	"github.com/mitchellh/mapstructure"
 )
 
 type Config struct {
 	common.PackerConfig `mapstructure:",squash"`
 
-	// The architecture to build for. This is required.
+	// The architecture to build for. This is required unless targets are set.
 	Architecture string `mapstructure:"architecture" required:"true"`
-	// The platform to build for. This is required.
+	// The platform to build for. This is required unless targets are set.
 	Platform string `mapstructure:"platform" required:"true"`
+	// The architecture and platform combinations to build in a single run,
+	// instead of architecture, platform and target.  The kernel_name and
+	// dbg_output options apply to the first of them, unless they are
+	// templates rendered for every target.
+	Targets []TargetConfig `mapstructure:"targets"`
 	// Force a rebuild of the image from scratch.
 	Force bool `mapstructure:"force"`
 	// The name of the image to build.
 	Target string `mapstructure:"target"`
-	// The path to the build directory. This is required.
+	// The path to the build directory. This is required unless
+	// source_repository is set.
 	Path string `mapstructure:"build_path" required:"true"`
+	// The Git repository to clone the project from, instead of building
+	// build_path.
+	SourceRepository string `mapstructure:"source_repository"`
+	// The branch, tag or commit of source_repository to build. Defaults to
+	// its default branch.
+	SourceRef string `mapstructure:"source_ref"`
+	// The directory of the project in source_repository.
+	SourcePath string `mapstructure:"source_path"`
+	// The Kraftfile to build, relative to the project directory. Defaults to
+	// the first of Kraftfile, kraft.yaml and kraft.yml found.
+	Kraftfile string `mapstructure:"kraftfile"`
+	// The project to build, described in the template instead of a Kraftfile.
+	// Its targets are the ones of the build.
+	Project *ProjectConfig `mapstructure:"project"`
+	// Variables added to the environment of make, such as KCFLAGS.
+	BuildEnv map[string]string `mapstructure:"build_env"`
+	// The toolchain prefix to build with, passed to make as CROSS_COMPILE.
+	CrossCompile string `mapstructure:"cross_compile"`
+	// KConfig symbols merged into the configuration of every target before
+	// configuring it, overriding kconfig_fragments and the Kraftfile.
+	KConfig map[string]string `mapstructure:"kconfig"`
+	// Files of KConfig symbols, in the syntax of a .config file, merged into
+	// the configuration of every target before configuring it. Fragments
+	// setting a symbol to different values conflict.
+	KConfigFragments []string `mapstructure:"kconfig_fragments"`
+	// Operations applied to the configuration of every target after
+	// kconfig_fragments and kconfig, one per line: `enable SYMBOL`,
+	// `disable SYMBOL`, `module SYMBOL`, `set-str SYMBOL VALUE` or
+	// `set-val SYMBOL VALUE`.
+	KConfigScript []string `mapstructure:"kconfig_script"`
 	// The path to the pull source.
 	PullSource string `mapstructure:"pull_source"`
+	// Additional sources to pull along with pull_source.
+	PullSources []string `mapstructure:"pull_sources"`
+	// The package manager to pull with: `auto`, `manifest` or `oci`.
+	PullManager string `mapstructure:"pull_manager"`
+	// Do not verify the checksum of the pulled components. Same as a
+	// checksum_policy of `off`.
+	PullNoChecksum bool `mapstructure:"pull_no_checksum"`
+	// How the checksums of the pulled components are verified: `enforce`
+	// fails the build on a mismatch, `warn` reports it and pulls the
+	// component unverified, `off` does not verify them. Defaults to
+	// `enforce`.
+	ChecksumPolicy string `mapstructure:"checksum_policy"`
+	// How a single package is picked when the catalog returns several for
+	// a component, rather than failing the build.
+	CandidateResolution *CandidateResolutionConfig `mapstructure:"candidate_resolution"`
+	// The licenses the components resolved during the build may use. The
+	// build fails before pulling them when one is not permitted.
+	LicensePolicy *LicensePolicyConfig `mapstructure:"license_policy"`
+	// Download the components from mirrors, keyed by the upstream URL they
+	// replace, e.g. `https://github.com/unikraft` to
+	// `https://mirror.example.com/unikraft`.
+	ComponentMirrors map[string]string `mapstructure:"component_mirrors"`
+	// The proxy plain HTTP downloads are made through, an `http`, `https`
+	// or `socks5` URL.
+	HTTPProxy string `mapstructure:"http_proxy"`
+	// The proxy HTTPS downloads are made through. Defaults to http_proxy.
+	HTTPSProxy string `mapstructure:"https_proxy"`
+	// Comma-separated hosts and domains downloaded from without proxy, or
+	// `*` for all of them.
+	NoProxy string `mapstructure:"no_proxy"`
+	// Refresh the package index at the start of the build, rather than
+	// relying on the local one.
+	UpdateIndex bool `mapstructure:"update_index"`
+	// The package manager whose index update_index refreshes: `manifest`,
+	// `oci` or `all`. Defaults to `manifest`.
+	UpdateManager string `mapstructure:"update_manager"`
+	// Resolve the pulled components from the local cache only.
+	PullForceCache bool `mapstructure:"pull_force_cache"`
+	// The maximum number of components pulled at the same time. Defaults
+	// to 4.
+	PullConcurrency int `mapstructure:"pull_concurrency"`
+	// The bandwidth of the downloads of the components, in bytes per
+	// second, shared by those pulled at the same time. Unlimited when not
+	// set.
+	PullRateLimit int64 `mapstructure:"pull_rate_limit"`
+	// The number of times a catalog query or pull failing with a transient
+	// error is retried. Defaults to 3, 0 disables retries.
+	MaxRetries *int `mapstructure:"max_retries"`
+	// The delay before the first retry, doubling for every further retry.
+	// Defaults to 1s.
+	RetryBackoff time.Duration `mapstructure:"retry_backoff"`
 	// The workdir to pull in.
 	Workdir string `mapstructure:"workdir"`
 	// Links to the sources.
 	Sources []string `mapstructure:"sources"`
 	// Unsources the default manifest location for using custom sources.
 	SourcesNoDefault bool `mapstructure:"sources_no_default"`
+	// The credentials of the private manifest sources, used when sourcing,
+	// updating and pulling from them.
+	SourceAuth []SourceAuthConfig `mapstructure:"source_auth"`
 	// Set of options to set.
 	Options string `mapstructure:"options"`
 	// Log level to use.
 	LogLevel string `mapstructure:"log_level"`
+	// Force fancy output even when not writing to a terminal.
+	FancyOutput bool `mapstructure:"fancy_output"`
+	// Stream the output of the configure, prepare and build phases to the
+	// Packer UI, prefixed by the target it comes from.
+	BuildLog *BuildLogConfig `mapstructure:"build_log"`
+	// How kraft is driven: `library` builds with KraftKit linked into the
+	// plugin, `cli` runs the kraft executable. Defaults to `library`.
+	Driver string `mapstructure:"driver"`
+	// The kraft executable run by the `cli` driver. Defaults to kraft.
+	KraftBinary string `mapstructure:"kraft_binary"`
+	// Build with the kraft executable of a Docker or Podman container, such
+	// that the toolchains of the targets need not be installed on the host.
+	// The build uses the cli driver, and the cache defaults to the shared
+	// cache.
+	BuildInContainer bool `mapstructure:"build_in_container"`
+	// The image of the build container. Defaults to the builder image of
+	// KraftKit. Pin it by digest, as `image@sha256:<digest>`, for a stable
+	// toolchain: the build then fails before it starts when the image in the
+	// registry does not have this digest.
+	ContainerImage string `mapstructure:"container_image"`
+	// The container runtime: docker or podman. Defaults to the first found in
+	// `PATH`.
+	ContainerRuntime string `mapstructure:"container_runtime"`
+	// The directory KraftKit keeps its manifest index and the sources of
+	// pulled components in, instead of the ones of its configuration.
+	CacheDir string `mapstructure:"cache_dir"`
+	// Keep the cache in a directory shared by every build of the user, such
+	// that builds reuse the components pulled by the others.
+	SharedCache bool `mapstructure:"shared_cache"`
+	// Empty the cache before building.
+	CleanCache bool `mapstructure:"clean_cache"`
+	// How long the configure and prepare phases of every target may take.
+	// Unbounded by default.
+	ConfigureTimeout time.Duration `mapstructure:"configure_timeout"`
+	// How long the build phase of every target may take. Unbounded by
+	// default.
+	BuildTimeout time.Duration `mapstructure:"build_timeout"`
+	// The number of jobs make builds with. Defaults to as many as there are
+	// CPUs, or one when build_fast is disabled.
+	BuildJobs int `mapstructure:"build_jobs"`
+	// Build with as many jobs as there are CPUs when build_jobs is not set.
+	// Defaults to true.
+	BuildFast *bool `mapstructure:"build_fast"`
+	// Wrap the compilers with ccache, such that the objects of unchanged
+	// libraries are reused across builds. ccache must be installed.
+	UseCCache bool `mapstructure:"use_ccache"`
+	// Skip the configure and prepare phases of the targets whose Kraftfile,
+	// KConfig options and component versions are unchanged since their last
+	// build, and keep the objects of the build folder for the next one. The
+	// components are not fetched again when every target is unchanged.
+	Incremental bool `mapstructure:"incremental"`
+	// Skip, with a warning, the targets whose architecture cannot be built
+	// on the host because no cross toolchain is available.
+	SkipUnbuildable bool `mapstructure:"skip_unbuildable"`
+	// Keep building the remaining targets when one fails to build. The build
+	// only fails when no target is built.
+	ContinueOnError bool `mapstructure:"continue_on_error"`
+	// A command run after every target is built, receiving the JSON result
+	// of the target on its standard input. A non-zero exit status fails the
+	// target.
+	Validator string `mapstructure:"validator"`
+	// Only build the targets affected by the changes of the project since
+	// this git ref. Every target is built when it cannot be told which
+	// targets the changes affect.
+	ChangedSince string `mapstructure:"changed_since"`
+	// The digests the kernels of the targets must have once built, by target
+	// name, as `sha256:<hex>`. A mismatch fails the build.
+	ExpectedDigests map[string]string `mapstructure:"expected_digests"`
+	// The directory the initramfs of the build is constructed from.
+	RootfsDir string `mapstructure:"rootfs_dir"`
+	// The Dockerfile the initramfs of the build is constructed from, with
+	// BuildKit.
+	RootfsDockerfile string `mapstructure:"rootfs_dockerfile"`
+	// The address of the BuildKit daemon building rootfs_dockerfile.
+	RootfsBuildKitHost string `mapstructure:"rootfs_buildkit_host"`
+	// The filename the built kernel is saved as.  When it refers to the
+	// target, e.g. `{{ .Target }}`, it is rendered for every target.
+	KernelName string `mapstructure:"kernel_name"`
+	// The path the debug kernel is copied to.  When it refers to the target,
+	// it is rendered for every target.
+	DbgOutput string `mapstructure:"dbg_output"`
+	// Bundle the debug kernel of every target along with a System.map of
+	// its symbols, for crash analysis tooling to symbolicate its panics.
+	DebugBundle bool `mapstructure:"debug_bundle"`
+	// The directory the kernel of every target is copied to once built,
+	// rendered for each target like artifact_name.
+	OutputDir string `mapstructure:"output_dir"`
+	// The filename the kernel of every target is copied to output_dir under,
+	// rendered for each target with its `{{ .Target }}`, `{{ .Arch }}`,
+	// `{{ .Plat }}`, `{{ .Name }}` and `{{ .BuildID }}`. Defaults to the
+	// filename of the kernel.
+	ArtifactName string `mapstructure:"artifact_name"`
+	// Do not record the metadata of the build host in the artifact.
+	NoBuildEnvironment bool `mapstructure:"no_build_environment"`
+	// The command line of the unikernel, overriding the command of the
+	// Kraftfile. The kernels are booted with it by test_boot and the
+	// firecracker smoke test, and packaged with it by the unikraft
+	// post-processor.
+	Cmdline string `mapstructure:"cmdline"`
+	// Boot the built kernels of the qemu targets and fail the build unless
+	// they boot as expected.
+	TestBoot *TestBootConfig `mapstructure:"test_boot"`
+	// Boot the built kernels of the qemu targets halted, attach gdb to them
+	// with the symbols of their debug kernel and fail the build unless a
+	// breakpoint on their entry symbol is hit.
+	GDBSmokeTest *GDBSmokeTestConfig `mapstructure:"gdb_smoke_test"`
+	// The microVM settings of the fc targets, written next to their kernels
+	// as firecracker configuration files.
+	Firecracker *FirecrackerConfig `mapstructure:"firecracker"`
+	// The domain settings of the xen targets, written next to their kernels
+	// as xl domain configuration files.
+	Xen *XenConfig `mapstructure:"xen"`
 
 	ctx interpolate.Context
 }
 
+// TargetConfig is a single architecture and platform combination of a
+// multi-target build.
+type TargetConfig struct {
+	// The architecture to build for. This is required.
+	Architecture string `mapstructure:"architecture" required:"true"`
+	// The platform to build for. This is required.
+	Platform string `mapstructure:"platform" required:"true"`
+	// The name of the target to build.
+	Target string `mapstructure:"target"`
+	// KConfig symbols of this target, overriding the kconfig of the build.
+	KConfig map[string]string `mapstructure:"kconfig"`
+}
+
+// ProjectConfig describes a project built without a Kraftfile.
+type ProjectConfig struct {
+	// The name of the application. This is required.
+	Name string `mapstructure:"name" required:"true"`
+	// The version of the Unikraft core to build against.
+	UnikraftVersion string `mapstructure:"unikraft_version"`
+	// The source of the Unikraft core to build against.
+	UnikraftSource string `mapstructure:"unikraft_source"`
+	// The libraries of the project.
+	Libraries []ProjectLibraryConfig `mapstructure:"libraries"`
+	// KConfig symbols of every target of the project.
+	KConfig map[string]string `mapstructure:"kconfig"`
+}
+
+// ProjectLibraryConfig is a library of a project described in the template.
+type ProjectLibraryConfig struct {
+	// The name of the library. This is required.
+	Name string `mapstructure:"name" required:"true"`
+	// The version of the library.
+	Version string `mapstructure:"version"`
+	// The source of the library.
+	Source string `mapstructure:"source"`
+}
+
+// ProjectSpec returns the project described by the project block, built for
+// the targets of the build, or nil when the Kraftfile is built.  Targets
+// without a name are named after the project, platform and architecture.
+func (c *Config) ProjectSpec() *ProjectSpec {
+	if c.Project == nil {
+		return nil
+	}
+
+	spec := &ProjectSpec{
+		Name: c.Project.Name,
+		Unikraft: ComponentSpec{
+			Name:    "unikraft",
+			Version: c.Project.UnikraftVersion,
+			Source:  c.Project.UnikraftSource,
+		},
+		Config: c.Project.KConfig,
+	}
+	for _, l := range c.Project.Libraries {
+		spec.Libraries = append(spec.Libraries, ComponentSpec{
+			Name:    l.Name,
+			Version: l.Version,
+			Source:  l.Source,
+		})
+	}
+	for _, t := range c.BuildTargets() {
+		name := t.Target
+		if name == "" {
+			name = fmt.Sprintf("%s-%s-%s", c.Project.Name, t.Platform, t.Architecture)
+		}
+
+		spec.Targets = append(spec.Targets, TargetDefinition{
+			Name:         name,
+			Architecture: t.Architecture,
+			Platform:     t.Platform,
+			Config:       t.KConfig,
+		})
+	}
+
+	return spec
+}
+
+// Drivers kraft may be driven with.
+const (
+	DriverLibrary = "library"
+	DriverCLI     = "cli"
+)
+
+// pullManagers are the package managers components may be pulled with.
+var pullManagers = []string{"", "auto", "manifest", "oci"}
+
+// updateManagers are the package managers whose index may be updated.
+var updateManagers = []string{"", "manifest", "oci", "all"}
+
+// IndexManager returns the package manager whose index update_index
+// refreshes.
+func (c *Config) IndexManager() string {
+	if c.UpdateManager == "" {
+		return "manifest"
+	}
+
+	return c.UpdateManager
+}
+
+// RootfsSource returns the directory or Dockerfile the initramfs of the build
+// is constructed from, if any.
+func (c *Config) RootfsSource() string {
+	if c.RootfsDir != "" {
+		return c.RootfsDir
+	}
+
+	return c.RootfsDockerfile
+}
+
+// DefaultPullConcurrency is the number of components pulled at the same time
+// when pull_concurrency is not set.
+const DefaultPullConcurrency = 4
+
+const (
+	// DefaultMaxRetries is the number of retries of a catalog query or pull
+	// when max_retries is not set.
+	DefaultMaxRetries = 3
+	// DefaultRetryBackoff is the delay before the first retry when
+	// retry_backoff is not set.
+	DefaultRetryBackoff = time.Second
+)
+
+// PullSourceList returns pull_source followed by pull_sources.
+func (c *Config) PullSourceList() []string {
+	var sources []string
+	if c.PullSource != "" {
+		sources = append(sources, c.PullSource)
+	}
+
+	return append(sources, c.PullSources...)
+}
+
+// PullOptions returns the options components are pulled with.
+func (c *Config) PullOptions() PullOptions {
+	concurrency := c.PullConcurrency
+	if concurrency == 0 {
+		concurrency = DefaultPullConcurrency
+	}
+
+	retries, backoff := c.Retries()
+
+	return PullOptions{
+		Manager:        c.PullManager,
+		ChecksumPolicy: c.PullChecksumPolicy(),
+		Resolution:     c.CandidateResolution.Policy(),
+		Mirrors:        c.ComponentMirrors,
+		Proxy:          c.Proxy(),
+		Auth:           c.SourceAuths(),
+		ForceCache:     c.PullForceCache,
+		Concurrency:    concurrency,
+		RateLimit:      c.PullRateLimit,
+		Retries:        retries,
+		RetryBackoff:   backoff,
+	}
+}
+
+// Proxy returns the proxies components are downloaded through.
+func (c *Config) Proxy() ProxyConfig {
+	return ProxyConfig{
+		HTTP:    c.HTTPProxy,
+		HTTPS:   c.HTTPSProxy,
+		NoProxy: c.NoProxy,
+	}
+}
+
+// PullChecksumPolicy returns how the checksums of the pulled components are
+// verified.
+func (c *Config) PullChecksumPolicy() string {
+	switch {
+	case c.PullNoChecksum:
+		return ChecksumOff
+	case c.ChecksumPolicy != "":
+		return c.ChecksumPolicy
+	}
+
+	return ChecksumEnforce
+}
+
+// Retries returns the number of retries of failing catalog queries and pulls,
+// and the delay before the first one.
+func (c *Config) Retries() (int, time.Duration) {
+	retries := DefaultMaxRetries
+	if c.MaxRetries != nil {
+		retries = *c.MaxRetries
+	}
+
+	backoff := c.RetryBackoff
+	if backoff == 0 {
+		backoff = DefaultRetryBackoff
+	}
+
+	return retries, backoff
+}
+
+// UsesCLI reports whether the build runs the kraft executable, on the host or
+// in a container.
+func (c *Config) UsesCLI() bool {
+	return c.Driver == DriverCLI || c.BuildInContainer
+}
+
+// NoFast reports whether make builds with a single job when build_jobs is not
+// set.
+func (c *Config) NoFast() bool {
+	return c.BuildFast != nil && !*c.BuildFast
+}
+
+// CachePaths returns the paths of the configured cache, and whether one is
+// configured at all.  Container builds use the shared cache by default, as
+// their containers do not outlive a command.
+func (c *Config) CachePaths() (CachePaths, bool, error) {
+	dir := c.CacheDir
+	if dir == "" && (c.SharedCache || c.BuildInContainer) {
+		shared, err := sharedCacheDir()
+		if err != nil {
+			return CachePaths{}, false, err
+		}
+		dir = shared
+	}
+
+	if dir == "" {
+		return CachePaths{}, false, nil
+	}
+
+	return NewCachePaths(dir), true, nil
+}
+
+// KConfigOptions returns the effective KConfig options merged into the
+// configuration of a target: kconfig_fragments, overridden by kconfig, then
+// by kconfig_script, themselves overridden by the kconfig of the target.
+func (c *Config) KConfigOptions(t TargetConfig) (map[string]KConfigOption, error) {
+	fragments := make([]KConfigLayer, 0, len(c.KConfigFragments))
+	for _, path := range c.KConfigFragments {
+		layer, err := readKConfigFragment(path)
+		if err != nil {
+			return nil, err
+		}
+		fragments = append(fragments, layer)
+	}
+
+	script, err := ParseKConfigScript(c.KConfigScript)
+	if err != nil {
+		return nil, err
+	}
+
+	return MergeKConfig(fragments,
+		KConfigLayer{Source: "kconfig", Values: c.KConfig},
+		script,
+		KConfigLayer{Source: fmt.Sprintf("kconfig of %s/%s", t.Platform, t.Architecture), Values: t.KConfig},
+	)
+}
+
+// hasKConfig reports whether any KConfig option is merged into the
+// configuration of the targets.
+func (c *Config) hasKConfig() bool {
+	if len(c.KConfig) > 0 || len(c.KConfigFragments) > 0 || len(c.KConfigScript) > 0 {
+		return true
+	}
+
+	for _, t := range c.Targets {
+		if len(t.KConfig) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// buildLog returns the log streaming the output of the build phases to ui,
+// or nil when build_log is not set.
+func (c *Config) buildLog(ui packer.Ui) *BuildLog {
+	if c.BuildLog == nil {
+		return nil
+	}
+
+	return &BuildLog{
+		Message:    ui.Message,
+		Error:      ui.Error,
+		Timestamps: c.BuildLog.Timestamps,
+		Quiet:      c.BuildLog.Quiet,
+	}
+}
+
+// BuildLogConfig describes how the output of the build phases is streamed.
+type BuildLogConfig struct {
+	// Prefix every line with the time it was written.
+	Timestamps bool `mapstructure:"timestamps"`
+	// Only show the error output of the phases.
+	Quiet bool `mapstructure:"quiet"`
+}
+
+// TestBootConfig describes when a kernel booted after the build is
+// considered healthy.
+type TestBootConfig struct {
+	// A string the unikernel must print on its console.
+	ExpectConsole string `mapstructure:"expect_console"`
+	// The code the unikernel must exit with.
+	ExpectExitCode *int `mapstructure:"expect_exit_code"`
+	// How long to wait for the unikernel. Defaults to 1m.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// BootCheck returns the check the booted kernels must pass.
+func (c *TestBootConfig) BootCheck() BootCheck {
+	return BootCheck{
+		ExpectConsole:  c.ExpectConsole,
+		ExpectExitCode: c.ExpectExitCode,
+		Timeout:        c.Timeout,
+	}
+}
+
+// GDBSmokeTestConfig describes how the kernels of the qemu targets are
+// debugged by the gdb smoke test.
+type GDBSmokeTestConfig struct {
+	// The qemu executable. Defaults to `qemu-system-<arch>` of each target.
+	QEMUBinary string `mapstructure:"qemu_binary"`
+	// The gdb executable. Defaults to `gdb`.
+	GDBBinary string `mapstructure:"gdb_binary"`
+	// The symbol the breakpoint is set on. Defaults to the symbol at the
+	// entry point of the debug kernel.
+	EntrySymbol string `mapstructure:"entry_symbol"`
+	// How long to wait for the breakpoint to be hit. Defaults to 1m.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// Test returns the smoke test of kernel, debugged with the symbols of dbg.
+func (c *GDBSmokeTestConfig) Test(kernel, dbg, architecture string) GDBSmokeTest {
+	return GDBSmokeTest{
+		Kernel:       kernel,
+		DebugKernel:  dbg,
+		Architecture: architecture,
+		QEMU:         c.QEMUBinary,
+		GDB:          c.GDBBinary,
+		EntrySymbol:  c.EntrySymbol,
+		Timeout:      c.Timeout,
+	}
+}
+
+// GitSource returns the repository the project is cloned from, if any.
+func (c *Config) GitSource() *GitSource {
+	if c.SourceRepository == "" {
+		return nil
+	}
+
+	return &GitSource{
+		Repository: c.SourceRepository,
+		Ref:        c.SourceRef,
+		Path:       c.SourcePath,
+	}
+}
+
+// FirecrackerConfig describes the microVM the kernels of the fc targets are
+// booted in.
+type FirecrackerConfig struct {
+	// The firecracker executable to smoke test with. Defaults to
+	// `firecracker`.
+	Binary string `mapstructure:"binary"`
+	// The memory of the microVM in MiB. Defaults to 128.
+	Memory int `mapstructure:"memory"`
+	// The number of vCPUs of the microVM. Defaults to 1.
+	VCPUs int `mapstructure:"vcpus"`
+	// The kernel command line of the microVM.
+	BootArgs string `mapstructure:"boot_args"`
+	// Boot the built kernels in firecracker and fail the build unless they
+	// boot as expected.
+	SmokeTest *TestBootConfig `mapstructure:"smoke_test"`
+}
+
+// VM returns the microVM booting kernel, with cmdline unless boot_args is
+// set.
+func (c *FirecrackerConfig) VM(kernel, cmdline string) FirecrackerVM {
+	if c == nil {
+		return FirecrackerVM{Kernel: kernel, BootArgs: cmdline}
+	}
+
+	bootArgs := c.BootArgs
+	if bootArgs == "" {
+		bootArgs = cmdline
+	}
+
+	return FirecrackerVM{
+		Kernel:   kernel,
+		BootArgs: bootArgs,
+		Memory:   c.Memory,
+		VCPUs:    c.VCPUs,
+	}
+}
+
+// CandidateResolutionConfig describes how a single package is picked among
+// the candidates the catalog returns for a component.  Each preference
+// narrows the candidates down to the ones it matches, in the order below, and
+// is ignored when it matches none of them.
+type CandidateResolutionConfig struct {
+	// Prefer the candidates of a package format: `oci` or `manifest`.
+	PreferFormat string `mapstructure:"prefer_format"`
+	// Prefer the candidates whose source contains this string.
+	PreferSource string `mapstructure:"prefer_source"`
+	// Prefer the candidates of a version, or `highest` or `lowest` for the
+	// highest or lowest version.
+	PreferVersion string `mapstructure:"prefer_version"`
+}
+
+// Policy returns the policy picking among the candidates.
+func (c *CandidateResolutionConfig) Policy() CandidatePolicy {
+	if c == nil {
+		return CandidatePolicy{}
+	}
+
+	return CandidatePolicy{
+		PreferFormat:  c.PreferFormat,
+		PreferSource:  c.PreferSource,
+		PreferVersion: c.PreferVersion,
+	}
+}
+
+// LicensePolicyConfig restricts the licenses of the components resolved
+// during the build, as SPDX identifiers.
+type LicensePolicyConfig struct {
+	// The only licenses the components may use, when not empty.
+	Allow []string `mapstructure:"allow"`
+	// The licenses the components must not use, taking precedence over
+	// allow.
+	Deny []string `mapstructure:"deny"`
+}
+
+// Policy returns the policy the licenses of the components are checked
+// against.
+func (c *LicensePolicyConfig) Policy() LicensePolicy {
+	if c == nil {
+		return LicensePolicy{}
+	}
+
+	return LicensePolicy{
+		Allow: c.Allow,
+		Deny:  c.Deny,
+	}
+}
+
+// SourceAuthConfig are the credentials of a private manifest source.  A
+// source served over HTTP(S) authenticates with either a username and
+// password or a token, and a Git source cloned over SSH with a private key.
+type SourceAuthConfig struct {
+	// The source, or the URL prefix of the sources, the credentials are sent
+	// to.
+	Source string `mapstructure:"source" required:"true"`
+	// The username authenticating with password.
+	Username string `mapstructure:"username"`
+	// The password of username.
+	Password string `mapstructure:"password"`
+	// The token authenticating as a bearer token.
+	Token string `mapstructure:"token"`
+	// The private key the Git sources on the host of source, under its path,
+	// are cloned over SSH with.
+	SSHPrivateKeyFile string `mapstructure:"ssh_private_key_file"`
+	// The passphrase of the private key, when it is encrypted.
+	SSHPassphrase string `mapstructure:"ssh_passphrase"`
+}
+
+// SourceAuths returns the credentials of the private manifest sources.
+func (c *Config) SourceAuths() []SourceAuth {
+	var auths []SourceAuth
+	for _, a := range c.SourceAuth {
+		auths = append(auths, SourceAuth{
+			Source:            a.Source,
+			Username:          a.Username,
+			Password:          a.Password,
+			Token:             a.Token,
+			SSHPrivateKeyFile: a.SSHPrivateKeyFile,
+			SSHPassphrase:     a.SSHPassphrase,
+		})
+	}
+
+	return auths
+}
+
+// XenConfig describes the domain the kernels of the xen targets are booted
+// in.
+type XenConfig struct {
+	// The memory of the domain in MiB. Defaults to 64.
+	Memory int `mapstructure:"memory"`
+	// The number of vCPUs of the domain. Defaults to 1.
+	VCPUs int `mapstructure:"vcpus"`
+	// The network interfaces of the domain, each an xl vif specification
+	// such as `bridge=xenbr0`.
+	Vifs []string `mapstructure:"vifs"`
+	// The kernel command line of the domain.
+	BootArgs string `mapstructure:"boot_args"`
+}
+
+// Domain returns the domain name booting kernel, with cmdline unless boot_args
+// is set.
+func (c *XenConfig) Domain(name, kernel, cmdline string) XenDomain {
+	if c == nil {
+		return XenDomain{Name: name, Kernel: kernel, BootArgs: cmdline}
+	}
+
+	bootArgs := c.BootArgs
+	if bootArgs == "" {
+		bootArgs = cmdline
+	}
+
+	return XenDomain{
+		Name:     name,
+		Kernel:   kernel,
+		BootArgs: bootArgs,
+		Memory:   c.Memory,
+		VCPUs:    c.VCPUs,
+		Vifs:     c.Vifs,
+	}
+}
+
+// firecrackerArchitectures are the architectures firecracker runs on.
+var firecrackerArchitectures = []string{"x86_64", "arm64"}
+
+// normalizePlatform returns the name kraft and the built kernels use for
+// platform, accepting `firecracker` for `fc`.
+func normalizePlatform(platform string) string {
+	if platform == "firecracker" {
+		return "fc"
+	}
+
+	return platform
+}
+
+// BuildTargets returns the targets built by the builder: either the targets
+// list or the single architecture, platform and target.
+func (c *Config) BuildTargets() []TargetConfig {
+	if len(c.Targets) > 0 {
+		return c.Targets
+	}
+
+	return []TargetConfig{{
+		Architecture: c.Architecture,
+		Platform:     c.Platform,
+		Target:       c.Target,
+	}}
+}
+
+// uninterpolated are the options rendered during the build rather than when
+// the configuration is decoded.
+var uninterpolated = []string{
+	"run_command",
+	"output_dir",
+	"artifact_name",
+	"kernel_name",
+	"dbg_output",
+}
+
 func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 	var md mapstructure.Metadata
 	err := config.Decode(c, &config.DecodeOpts{
@@ -49,27 +803,340 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 		Interpolate:        true,
 		InterpolateContext: &c.ctx,
 		InterpolateFilter: &interpolate.RenderFilter{
-			Exclude: []string{
-				"run_command",
-			},
+			Exclude: uninterpolated,
 		},
 	}, raws...)
 	if err != nil {
 		return nil, err
 	}
 
+	c.Platform = normalizePlatform(c.Platform)
+	for i := range c.Targets {
+		c.Targets[i].Platform = normalizePlatform(c.Targets[i].Platform)
+	}
+
 	// Accumulate any errors
 	var errs *packer.MultiError
-	if c.Architecture == "" {
-		errs = packer.MultiErrorAppend(errs, fmt.Errorf("architecture must be specified"))
+	if len(c.Targets) > 0 {
+		if c.Architecture != "" || c.Platform != "" || c.Target != "" {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("architecture, platform and target cannot be combined with targets"))
+		}
+
+		for i, t := range c.Targets {
+			if t.Architecture == "" {
+				errs = packer.MultiErrorAppend(errs, fmt.Errorf("targets[%d]: architecture must be specified", i))
+			}
+
+			if t.Platform == "" {
+				errs = packer.MultiErrorAppend(errs, fmt.Errorf("targets[%d]: platform must be specified", i))
+			}
+		}
+	} else {
+		if c.Architecture == "" {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("architecture must be specified"))
+		}
+
+		if c.Platform == "" {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("platform must be specified"))
+		}
+	}
+
+	driver := c.Driver
+	if c.BuildInContainer {
+		if driver == DriverLibrary {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("build_in_container cannot be combined with the library driver"))
+		}
+		driver = DriverCLI
+
+		if strings.Contains(c.ContainerImage, "@") {
+			if _, err := ParsePinnedImage(c.ContainerImage); err != nil {
+				errs = packer.MultiErrorAppend(errs, fmt.Errorf("container_image: %w", err))
+			}
+		}
+	} else if c.ContainerImage != "" || c.ContainerRuntime != "" {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("container_image and container_runtime require build_in_container"))
+	}
+
+	if c.ContainerRuntime != "" {
+		known := false
+		for _, r := range ContainerRuntimes {
+			known = known || c.ContainerRuntime == r
+		}
+		if !known {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("unknown container_runtime %q, expected %s", c.ContainerRuntime, strings.Join(ContainerRuntimes, " or ")))
+		}
+	}
+
+	switch driver {
+	case "", DriverLibrary:
+		if c.KraftBinary != "" {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("kraft_binary requires the cli driver"))
+		}
+	case DriverCLI:
+		if c.RootfsSource() != "" {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("the cli driver cannot construct an initramfs from rootfs_dir or rootfs_dockerfile"))
+		}
+		if c.TestBoot != nil {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("the cli driver cannot boot kernels for test_boot"))
+		}
+		if c.hasKConfig() {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("the cli driver cannot merge kconfig, kconfig_fragments or kconfig_script"))
+		}
+		if c.ConfigureTimeout != 0 || c.BuildTimeout != 0 {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("the cli driver cannot bound phases with configure_timeout or build_timeout"))
+		}
+		if c.UseCCache {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("the cli driver cannot wrap the compilers with use_ccache"))
+		}
+		if c.Incremental {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("the cli driver cannot build incrementally"))
+		}
+		if c.SkipUnbuildable {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("the cli driver cannot skip unbuildable targets with skip_unbuildable"))
+		}
+		if len(c.ExpectedDigests) > 0 {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("the cli driver cannot verify the digests of expected_digests"))
+		}
+		if c.Validator != "" {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("the cli driver cannot run a validator"))
+		}
+		if c.ChangedSince != "" {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("the cli driver cannot build the targets affected by changed_since only"))
+		}
+		if len(c.SourceAuth) > 0 {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("the cli driver cannot authenticate against private sources with source_auth"))
+		}
+		if len(c.ComponentMirrors) > 0 || !c.Proxy().IsZero() {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("the cli driver cannot download components from component_mirrors or through proxies"))
+		}
+		if c.PullRateLimit > 0 {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("the cli driver cannot cap the bandwidth of downloads with pull_rate_limit"))
+		}
+		if c.CandidateResolution != nil {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("the cli driver cannot pick among catalog candidates with candidate_resolution"))
+		}
+		if c.LicensePolicy != nil {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("the cli driver cannot check the licenses of the components with license_policy"))
+		}
+		if c.Project != nil {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("the cli driver cannot build a project block without a Kraftfile"))
+		}
+	default:
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("unknown driver %q, expected library or cli", c.Driver))
+	}
+
+	validManager := false
+	for _, m := range pullManagers {
+		validManager = validManager || c.PullManager == m
+	}
+	if !validManager {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("unknown pull_manager %q, expected auto, manifest or oci", c.PullManager))
 	}
 
-	if c.Platform == "" {
-		errs = packer.MultiErrorAppend(errs, fmt.Errorf("platform must be specified"))
+	validManager = false
+	for _, m := range updateManagers {
+		validManager = validManager || c.UpdateManager == m
+	}
+	if !validManager {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("unknown update_manager %q, expected manifest, oci or all", c.UpdateManager))
+	}
+	if c.UpdateManager != "" && !c.UpdateIndex {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("update_manager requires update_index"))
 	}
 
-	if c.Path == "" {
+	if c.CacheDir != "" && c.SharedCache {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("cache_dir and shared_cache cannot be combined"))
+	}
+
+	if c.CleanCache && c.CacheDir == "" && !c.SharedCache && !c.BuildInContainer {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("clean_cache requires cache_dir, shared_cache or build_in_container"))
+	}
+
+	if err := checkMakeEnv(c.BuildEnv, c.CrossCompile); err != nil {
+		errs = packer.MultiErrorAppend(errs, err)
+	}
+
+	if c.UseCCache {
+		if err := checkCCache(c.BuildEnv); err != nil {
+			errs = packer.MultiErrorAppend(errs, err)
+		}
+	}
+
+	if c.ArtifactName != "" && c.OutputDir == "" {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("artifact_name requires output_dir"))
+	}
+
+	if c.OutputDir != "" {
+		if err := checkOutputTemplates(c.ctx, c.OutputDir, c.ArtifactName); err != nil {
+			errs = packer.MultiErrorAppend(errs, err)
+		}
+	}
+
+	if err := checkKernelTemplates(c.ctx, c.KernelName, c.DbgOutput); err != nil {
+		errs = packer.MultiErrorAppend(errs, err)
+	}
+
+	for _, name := range sortedKeys(c.ExpectedDigests) {
+		if err := checkDigest(c.ExpectedDigests[name]); err != nil {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("expected_digests[%q]: %w", name, err))
+		}
+	}
+
+	if c.BuildJobs < 0 {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("build_jobs must not be negative"))
+	}
+
+	if err := checkRootfsSource(c.RootfsDir, c.RootfsDockerfile); err != nil {
+		errs = packer.MultiErrorAppend(errs, err)
+	}
+
+	// Fragments and the script are shared by every target, so their errors
+	// are only reported once.
+	for _, t := range c.BuildTargets() {
+		if _, err := c.KConfigOptions(t); err != nil {
+			errs = packer.MultiErrorAppend(errs, err)
+			break
+		}
+	}
+
+	if c.PullConcurrency < 0 {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("pull_concurrency must not be negative"))
+	}
+
+	if c.PullRateLimit < 0 {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("pull_rate_limit must not be negative"))
+	}
+
+	if c.ChecksumPolicy != "" {
+		if err := checkChecksumPolicy(c.ChecksumPolicy); err != nil {
+			errs = packer.MultiErrorAppend(errs, err)
+		} else if c.PullNoChecksum && c.ChecksumPolicy != ChecksumOff {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("pull_no_checksum cannot be combined with checksum_policy %q", c.ChecksumPolicy))
+		}
+	}
+
+	if c.MaxRetries != nil && *c.MaxRetries < 0 {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("max_retries must not be negative"))
+	}
+
+	if c.RetryBackoff < 0 {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("retry_backoff must not be negative"))
+	}
+
+	if c.ConfigureTimeout < 0 || c.BuildTimeout < 0 {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("configure_timeout and build_timeout must not be negative"))
+	}
+
+	if _, err := shellwords.Parse(c.Cmdline); err != nil {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("invalid cmdline: %w", err))
+	}
+
+	if _, err := shellwords.Parse(c.Validator); err != nil {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("invalid validator: %w", err))
+	}
+
+	if c.TestBoot != nil && c.TestBoot.Timeout < 0 {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("test_boot timeout must not be negative"))
+	}
+
+	if c.GDBSmokeTest != nil && c.GDBSmokeTest.Timeout < 0 {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("gdb_smoke_test timeout must not be negative"))
+	}
+
+	for _, t := range c.BuildTargets() {
+		if t.Platform != "fc" || t.Architecture == "" {
+			continue
+		}
+
+		supported := false
+		for _, arch := range firecrackerArchitectures {
+			supported = supported || t.Architecture == arch
+		}
+		if !supported {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("firecracker does not run %s kernels, expected x86_64 or arm64", t.Architecture))
+		}
+	}
+
+	if c.Firecracker != nil {
+		if c.Firecracker.Memory < 0 || c.Firecracker.VCPUs < 0 {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("firecracker memory and vcpus must not be negative"))
+		}
+
+		if c.Firecracker.SmokeTest != nil && c.Firecracker.SmokeTest.Timeout < 0 {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("firecracker smoke_test timeout must not be negative"))
+		}
+	}
+
+	if err := ValidateSourceAuths(c.SourceAuths()); err != nil {
+		errs = packer.MultiErrorAppend(errs, err)
+	}
+	for _, a := range c.SourceAuths() {
+		packer.LogSecretFilter.Set(a.Secrets()...)
+	}
+
+	if err := ValidateMirrors(c.ComponentMirrors); err != nil {
+		errs = packer.MultiErrorAppend(errs, err)
+	}
+
+	if err := c.Proxy().Validate(); err != nil {
+		errs = packer.MultiErrorAppend(errs, err)
+	}
+
+	if err := c.CandidateResolution.Policy().Validate(); err != nil {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("candidate_resolution: %w", err))
+	}
+
+	if spec := c.ProjectSpec(); spec != nil {
+		if c.Kraftfile != "" {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("project and kraftfile cannot be combined"))
+		}
+
+		if err := spec.Validate(); err != nil {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("project: %w", err))
+		}
+	}
+
+	if c.LicensePolicy != nil {
+		for i, id := range c.LicensePolicy.Allow {
+			if strings.TrimSpace(id) == "" {
+				errs = packer.MultiErrorAppend(errs, fmt.Errorf("license_policy allow[%d] must not be empty", i))
+			}
+		}
+		for i, id := range c.LicensePolicy.Deny {
+			if strings.TrimSpace(id) == "" {
+				errs = packer.MultiErrorAppend(errs, fmt.Errorf("license_policy deny[%d] must not be empty", i))
+			}
+		}
+	}
+
+	if c.Xen != nil {
+		if c.Xen.Memory < 0 || c.Xen.VCPUs < 0 {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("xen memory and vcpus must not be negative"))
+		}
+
+		for i, vif := range c.Xen.Vifs {
+			if strings.TrimSpace(vif) == "" {
+				errs = packer.MultiErrorAppend(errs, fmt.Errorf("xen vifs[%d] must not be empty", i))
+			}
+		}
+	}
+
+	if source := c.GitSource(); source != nil {
+		if c.Path != "" {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("build_path cannot be combined with source_repository"))
+		}
+
+		if err := source.Validate(); err != nil {
+			errs = packer.MultiErrorAppend(errs, err)
+		}
+	} else if c.SourceRef != "" || c.SourcePath != "" {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("source_ref and source_path require source_repository"))
+	} else if c.Path == "" {
 		errs = packer.MultiErrorAppend(errs, fmt.Errorf("build_path must be specified"))
+	} else if c.Kraftfile != "" {
+		if _, err := os.Stat(findKraftfile(c.Path, c.Kraftfile)); err != nil {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("kraftfile %q not found in build_path: %s", c.Kraftfile, err))
+		}
 	}
 
 	if errs != nil && len(errs.Errors) > 0 {
@@ -7,28 +7,144 @@ import (
 	"github.com/zclconf/go-cty/cty"
 )
 
+// FlatBuildLogConfig is an auto-generated flat version of BuildLogConfig.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatBuildLogConfig struct {
+	Timestamps *bool `mapstructure:"timestamps" cty:"timestamps" hcl:"timestamps"`
+	Quiet      *bool `mapstructure:"quiet" cty:"quiet" hcl:"quiet"`
+}
+
+// FlatMapstructure returns a new FlatBuildLogConfig.
+// FlatBuildLogConfig is an auto-generated flat version of BuildLogConfig.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*BuildLogConfig) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatBuildLogConfig)
+}
+
+// HCL2Spec returns the hcl spec of a BuildLogConfig.
+// This spec is used by HCL to read the fields of BuildLogConfig.
+// The decoded values from this spec will then be applied to a FlatBuildLogConfig.
+func (*FlatBuildLogConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"timestamps": &hcldec.AttrSpec{Name: "timestamps", Type: cty.Bool, Required: false},
+		"quiet":      &hcldec.AttrSpec{Name: "quiet", Type: cty.Bool, Required: false},
+	}
+	return s
+}
+
+// FlatCandidateResolutionConfig is an auto-generated flat version of CandidateResolutionConfig.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatCandidateResolutionConfig struct {
+	PreferFormat  *string `mapstructure:"prefer_format" cty:"prefer_format" hcl:"prefer_format"`
+	PreferSource  *string `mapstructure:"prefer_source" cty:"prefer_source" hcl:"prefer_source"`
+	PreferVersion *string `mapstructure:"prefer_version" cty:"prefer_version" hcl:"prefer_version"`
+}
+
+// FlatMapstructure returns a new FlatCandidateResolutionConfig.
+// FlatCandidateResolutionConfig is an auto-generated flat version of CandidateResolutionConfig.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*CandidateResolutionConfig) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatCandidateResolutionConfig)
+}
+
+// HCL2Spec returns the hcl spec of a CandidateResolutionConfig.
+// This spec is used by HCL to read the fields of CandidateResolutionConfig.
+// The decoded values from this spec will then be applied to a FlatCandidateResolutionConfig.
+func (*FlatCandidateResolutionConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"prefer_format":  &hcldec.AttrSpec{Name: "prefer_format", Type: cty.String, Required: false},
+		"prefer_source":  &hcldec.AttrSpec{Name: "prefer_source", Type: cty.String, Required: false},
+		"prefer_version": &hcldec.AttrSpec{Name: "prefer_version", Type: cty.String, Required: false},
+	}
+	return s
+}
+
 // FlatConfig is an auto-generated flat version of Config.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlatConfig struct {
-	PackerBuildName     *string           `mapstructure:"packer_build_name" cty:"packer_build_name" hcl:"packer_build_name"`
-	PackerBuilderType   *string           `mapstructure:"packer_builder_type" cty:"packer_builder_type" hcl:"packer_builder_type"`
-	PackerCoreVersion   *string           `mapstructure:"packer_core_version" cty:"packer_core_version" hcl:"packer_core_version"`
-	PackerDebug         *bool             `mapstructure:"packer_debug" cty:"packer_debug" hcl:"packer_debug"`
-	PackerForce         *bool             `mapstructure:"packer_force" cty:"packer_force" hcl:"packer_force"`
-	PackerOnError       *string           `mapstructure:"packer_on_error" cty:"packer_on_error" hcl:"packer_on_error"`
-	PackerUserVars      map[string]string `mapstructure:"packer_user_variables" cty:"packer_user_variables" hcl:"packer_user_variables"`
-	PackerSensitiveVars []string          `mapstructure:"packer_sensitive_variables" cty:"packer_sensitive_variables" hcl:"packer_sensitive_variables"`
-	Architecture        *string           `mapstructure:"architecture" required:"true" cty:"architecture" hcl:"architecture"`
-	Platform            *string           `mapstructure:"platform" required:"true" cty:"platform" hcl:"platform"`
-	Force               *bool             `mapstructure:"force" cty:"force" hcl:"force"`
-	Target              *string           `mapstructure:"target" cty:"target" hcl:"target"`
-	Path                *string           `mapstructure:"build_path" required:"true" cty:"build_path" hcl:"build_path"`
-	PullSource          *string           `mapstructure:"pull_source" cty:"pull_source" hcl:"pull_source"`
-	Workdir             *string           `mapstructure:"workdir" cty:"workdir" hcl:"workdir"`
-	Sources             []string          `mapstructure:"sources" cty:"sources" hcl:"sources"`
-	SourcesNoDefault    *bool             `mapstructure:"sources_no_default" cty:"sources_no_default" hcl:"sources_no_default"`
-	Options             *string           `mapstructure:"options" cty:"options" hcl:"options"`
-	LogLevel            *string           `mapstructure:"log_level" cty:"log_level" hcl:"log_level"`
+	PackerBuildName     *string                        `mapstructure:"packer_build_name" cty:"packer_build_name" hcl:"packer_build_name"`
+	PackerBuilderType   *string                        `mapstructure:"packer_builder_type" cty:"packer_builder_type" hcl:"packer_builder_type"`
+	PackerCoreVersion   *string                        `mapstructure:"packer_core_version" cty:"packer_core_version" hcl:"packer_core_version"`
+	PackerDebug         *bool                          `mapstructure:"packer_debug" cty:"packer_debug" hcl:"packer_debug"`
+	PackerForce         *bool                          `mapstructure:"packer_force" cty:"packer_force" hcl:"packer_force"`
+	PackerOnError       *string                        `mapstructure:"packer_on_error" cty:"packer_on_error" hcl:"packer_on_error"`
+	PackerUserVars      map[string]string              `mapstructure:"packer_user_variables" cty:"packer_user_variables" hcl:"packer_user_variables"`
+	PackerSensitiveVars []string                       `mapstructure:"packer_sensitive_variables" cty:"packer_sensitive_variables" hcl:"packer_sensitive_variables"`
+	Architecture        *string                        `mapstructure:"architecture" required:"true" cty:"architecture" hcl:"architecture"`
+	Platform            *string                        `mapstructure:"platform" required:"true" cty:"platform" hcl:"platform"`
+	Targets             []FlatTargetConfig             `mapstructure:"targets" cty:"targets" hcl:"targets"`
+	Force               *bool                          `mapstructure:"force" cty:"force" hcl:"force"`
+	Target              *string                        `mapstructure:"target" cty:"target" hcl:"target"`
+	Path                *string                        `mapstructure:"build_path" required:"true" cty:"build_path" hcl:"build_path"`
+	SourceRepository    *string                        `mapstructure:"source_repository" cty:"source_repository" hcl:"source_repository"`
+	SourceRef           *string                        `mapstructure:"source_ref" cty:"source_ref" hcl:"source_ref"`
+	SourcePath          *string                        `mapstructure:"source_path" cty:"source_path" hcl:"source_path"`
+	Kraftfile           *string                        `mapstructure:"kraftfile" cty:"kraftfile" hcl:"kraftfile"`
+	Project             *FlatProjectConfig             `mapstructure:"project" cty:"project" hcl:"project"`
+	BuildEnv            map[string]string              `mapstructure:"build_env" cty:"build_env" hcl:"build_env"`
+	CrossCompile        *string                        `mapstructure:"cross_compile" cty:"cross_compile" hcl:"cross_compile"`
+	KConfig             map[string]string              `mapstructure:"kconfig" cty:"kconfig" hcl:"kconfig"`
+	KConfigFragments    []string                       `mapstructure:"kconfig_fragments" cty:"kconfig_fragments" hcl:"kconfig_fragments"`
+	KConfigScript       []string                       `mapstructure:"kconfig_script" cty:"kconfig_script" hcl:"kconfig_script"`
+	PullSource          *string                        `mapstructure:"pull_source" cty:"pull_source" hcl:"pull_source"`
+	PullSources         []string                       `mapstructure:"pull_sources" cty:"pull_sources" hcl:"pull_sources"`
+	PullManager         *string                        `mapstructure:"pull_manager" cty:"pull_manager" hcl:"pull_manager"`
+	PullNoChecksum      *bool                          `mapstructure:"pull_no_checksum" cty:"pull_no_checksum" hcl:"pull_no_checksum"`
+	ChecksumPolicy      *string                        `mapstructure:"checksum_policy" cty:"checksum_policy" hcl:"checksum_policy"`
+	CandidateResolution *FlatCandidateResolutionConfig `mapstructure:"candidate_resolution" cty:"candidate_resolution" hcl:"candidate_resolution"`
+	LicensePolicy       *FlatLicensePolicyConfig       `mapstructure:"license_policy" cty:"license_policy" hcl:"license_policy"`
+	ComponentMirrors    map[string]string              `mapstructure:"component_mirrors" cty:"component_mirrors" hcl:"component_mirrors"`
+	HTTPProxy           *string                        `mapstructure:"http_proxy" cty:"http_proxy" hcl:"http_proxy"`
+	HTTPSProxy          *string                        `mapstructure:"https_proxy" cty:"https_proxy" hcl:"https_proxy"`
+	NoProxy             *string                        `mapstructure:"no_proxy" cty:"no_proxy" hcl:"no_proxy"`
+	UpdateIndex         *bool                          `mapstructure:"update_index" cty:"update_index" hcl:"update_index"`
+	UpdateManager       *string                        `mapstructure:"update_manager" cty:"update_manager" hcl:"update_manager"`
+	PullForceCache      *bool                          `mapstructure:"pull_force_cache" cty:"pull_force_cache" hcl:"pull_force_cache"`
+	PullConcurrency     *int                           `mapstructure:"pull_concurrency" cty:"pull_concurrency" hcl:"pull_concurrency"`
+	PullRateLimit       *int64                         `mapstructure:"pull_rate_limit" cty:"pull_rate_limit" hcl:"pull_rate_limit"`
+	MaxRetries          *int                           `mapstructure:"max_retries" cty:"max_retries" hcl:"max_retries"`
+	RetryBackoff        *string                        `mapstructure:"retry_backoff" cty:"retry_backoff" hcl:"retry_backoff"`
+	Workdir             *string                        `mapstructure:"workdir" cty:"workdir" hcl:"workdir"`
+	Sources             []string                       `mapstructure:"sources" cty:"sources" hcl:"sources"`
+	SourcesNoDefault    *bool                          `mapstructure:"sources_no_default" cty:"sources_no_default" hcl:"sources_no_default"`
+	SourceAuth          []FlatSourceAuthConfig         `mapstructure:"source_auth" cty:"source_auth" hcl:"source_auth"`
+	Options             *string                        `mapstructure:"options" cty:"options" hcl:"options"`
+	LogLevel            *string                        `mapstructure:"log_level" cty:"log_level" hcl:"log_level"`
+	FancyOutput         *bool                          `mapstructure:"fancy_output" cty:"fancy_output" hcl:"fancy_output"`
+	BuildLog            *FlatBuildLogConfig            `mapstructure:"build_log" cty:"build_log" hcl:"build_log"`
+	Driver              *string                        `mapstructure:"driver" cty:"driver" hcl:"driver"`
+	KraftBinary         *string                        `mapstructure:"kraft_binary" cty:"kraft_binary" hcl:"kraft_binary"`
+	BuildInContainer    *bool                          `mapstructure:"build_in_container" cty:"build_in_container" hcl:"build_in_container"`
+	ContainerImage      *string                        `mapstructure:"container_image" cty:"container_image" hcl:"container_image"`
+	ContainerRuntime    *string                        `mapstructure:"container_runtime" cty:"container_runtime" hcl:"container_runtime"`
+	CacheDir            *string                        `mapstructure:"cache_dir" cty:"cache_dir" hcl:"cache_dir"`
+	SharedCache         *bool                          `mapstructure:"shared_cache" cty:"shared_cache" hcl:"shared_cache"`
+	CleanCache          *bool                          `mapstructure:"clean_cache" cty:"clean_cache" hcl:"clean_cache"`
+	ConfigureTimeout    *string                        `mapstructure:"configure_timeout" cty:"configure_timeout" hcl:"configure_timeout"`
+	BuildTimeout        *string                        `mapstructure:"build_timeout" cty:"build_timeout" hcl:"build_timeout"`
+	BuildJobs           *int                           `mapstructure:"build_jobs" cty:"build_jobs" hcl:"build_jobs"`
+	BuildFast           *bool                          `mapstructure:"build_fast" cty:"build_fast" hcl:"build_fast"`
+	UseCCache           *bool                          `mapstructure:"use_ccache" cty:"use_ccache" hcl:"use_ccache"`
+	Incremental         *bool                          `mapstructure:"incremental" cty:"incremental" hcl:"incremental"`
+	SkipUnbuildable     *bool                          `mapstructure:"skip_unbuildable" cty:"skip_unbuildable" hcl:"skip_unbuildable"`
+	ContinueOnError     *bool                          `mapstructure:"continue_on_error" cty:"continue_on_error" hcl:"continue_on_error"`
+	Validator           *string                        `mapstructure:"validator" cty:"validator" hcl:"validator"`
+	ChangedSince        *string                        `mapstructure:"changed_since" cty:"changed_since" hcl:"changed_since"`
+	ExpectedDigests     map[string]string              `mapstructure:"expected_digests" cty:"expected_digests" hcl:"expected_digests"`
+	RootfsDir           *string                        `mapstructure:"rootfs_dir" cty:"rootfs_dir" hcl:"rootfs_dir"`
+	RootfsDockerfile    *string                        `mapstructure:"rootfs_dockerfile" cty:"rootfs_dockerfile" hcl:"rootfs_dockerfile"`
+	RootfsBuildKitHost  *string                        `mapstructure:"rootfs_buildkit_host" cty:"rootfs_buildkit_host" hcl:"rootfs_buildkit_host"`
+	KernelName          *string                        `mapstructure:"kernel_name" cty:"kernel_name" hcl:"kernel_name"`
+	DbgOutput           *string                        `mapstructure:"dbg_output" cty:"dbg_output" hcl:"dbg_output"`
+	DebugBundle         *bool                          `mapstructure:"debug_bundle" cty:"debug_bundle" hcl:"debug_bundle"`
+	OutputDir           *string                        `mapstructure:"output_dir" cty:"output_dir" hcl:"output_dir"`
+	ArtifactName        *string                        `mapstructure:"artifact_name" cty:"artifact_name" hcl:"artifact_name"`
+	NoBuildEnvironment  *bool                          `mapstructure:"no_build_environment" cty:"no_build_environment" hcl:"no_build_environment"`
+	Cmdline             *string                        `mapstructure:"cmdline" cty:"cmdline" hcl:"cmdline"`
+	TestBoot            *FlatTestBootConfig            `mapstructure:"test_boot" cty:"test_boot" hcl:"test_boot"`
+	GDBSmokeTest        *FlatGDBSmokeTestConfig        `mapstructure:"gdb_smoke_test" cty:"gdb_smoke_test" hcl:"gdb_smoke_test"`
+	Firecracker         *FlatFirecrackerConfig         `mapstructure:"firecracker" cty:"firecracker" hcl:"firecracker"`
+	Xen                 *FlatXenConfig                 `mapstructure:"xen" cty:"xen" hcl:"xen"`
 }
 
 // FlatMapstructure returns a new FlatConfig.
@@ -53,15 +169,340 @@ func (*FlatConfig) HCL2Spec() map[string]hcldec.Spec {
 		"packer_sensitive_variables": &hcldec.AttrSpec{Name: "packer_sensitive_variables", Type: cty.List(cty.String), Required: false},
 		"architecture":               &hcldec.AttrSpec{Name: "architecture", Type: cty.String, Required: false},
 		"platform":                   &hcldec.AttrSpec{Name: "platform", Type: cty.String, Required: false},
+		"targets":                    &hcldec.BlockListSpec{TypeName: "targets", Nested: hcldec.ObjectSpec((*FlatTargetConfig)(nil).HCL2Spec())},
 		"force":                      &hcldec.AttrSpec{Name: "force", Type: cty.Bool, Required: false},
 		"target":                     &hcldec.AttrSpec{Name: "target", Type: cty.String, Required: false},
 		"build_path":                 &hcldec.AttrSpec{Name: "build_path", Type: cty.String, Required: false},
+		"source_repository":          &hcldec.AttrSpec{Name: "source_repository", Type: cty.String, Required: false},
+		"source_ref":                 &hcldec.AttrSpec{Name: "source_ref", Type: cty.String, Required: false},
+		"source_path":                &hcldec.AttrSpec{Name: "source_path", Type: cty.String, Required: false},
+		"kraftfile":                  &hcldec.AttrSpec{Name: "kraftfile", Type: cty.String, Required: false},
+		"project":                    &hcldec.BlockSpec{TypeName: "project", Nested: hcldec.ObjectSpec((*FlatProjectConfig)(nil).HCL2Spec())},
+		"build_env":                  &hcldec.AttrSpec{Name: "build_env", Type: cty.Map(cty.String), Required: false},
+		"cross_compile":              &hcldec.AttrSpec{Name: "cross_compile", Type: cty.String, Required: false},
+		"kconfig":                    &hcldec.AttrSpec{Name: "kconfig", Type: cty.Map(cty.String), Required: false},
+		"kconfig_fragments":          &hcldec.AttrSpec{Name: "kconfig_fragments", Type: cty.List(cty.String), Required: false},
+		"kconfig_script":             &hcldec.AttrSpec{Name: "kconfig_script", Type: cty.List(cty.String), Required: false},
 		"pull_source":                &hcldec.AttrSpec{Name: "pull_source", Type: cty.String, Required: false},
+		"pull_sources":               &hcldec.AttrSpec{Name: "pull_sources", Type: cty.List(cty.String), Required: false},
+		"pull_manager":               &hcldec.AttrSpec{Name: "pull_manager", Type: cty.String, Required: false},
+		"pull_no_checksum":           &hcldec.AttrSpec{Name: "pull_no_checksum", Type: cty.Bool, Required: false},
+		"checksum_policy":            &hcldec.AttrSpec{Name: "checksum_policy", Type: cty.String, Required: false},
+		"candidate_resolution":       &hcldec.BlockSpec{TypeName: "candidate_resolution", Nested: hcldec.ObjectSpec((*FlatCandidateResolutionConfig)(nil).HCL2Spec())},
+		"license_policy":             &hcldec.BlockSpec{TypeName: "license_policy", Nested: hcldec.ObjectSpec((*FlatLicensePolicyConfig)(nil).HCL2Spec())},
+		"component_mirrors":          &hcldec.AttrSpec{Name: "component_mirrors", Type: cty.Map(cty.String), Required: false},
+		"http_proxy":                 &hcldec.AttrSpec{Name: "http_proxy", Type: cty.String, Required: false},
+		"https_proxy":                &hcldec.AttrSpec{Name: "https_proxy", Type: cty.String, Required: false},
+		"no_proxy":                   &hcldec.AttrSpec{Name: "no_proxy", Type: cty.String, Required: false},
+		"update_index":               &hcldec.AttrSpec{Name: "update_index", Type: cty.Bool, Required: false},
+		"update_manager":             &hcldec.AttrSpec{Name: "update_manager", Type: cty.String, Required: false},
+		"pull_force_cache":           &hcldec.AttrSpec{Name: "pull_force_cache", Type: cty.Bool, Required: false},
+		"pull_concurrency":           &hcldec.AttrSpec{Name: "pull_concurrency", Type: cty.Number, Required: false},
+		"pull_rate_limit":            &hcldec.AttrSpec{Name: "pull_rate_limit", Type: cty.Number, Required: false},
+		"max_retries":                &hcldec.AttrSpec{Name: "max_retries", Type: cty.Number, Required: false},
+		"retry_backoff":              &hcldec.AttrSpec{Name: "retry_backoff", Type: cty.String, Required: false},
 		"workdir":                    &hcldec.AttrSpec{Name: "workdir", Type: cty.String, Required: false},
 		"sources":                    &hcldec.AttrSpec{Name: "sources", Type: cty.List(cty.String), Required: false},
 		"sources_no_default":         &hcldec.AttrSpec{Name: "sources_no_default", Type: cty.Bool, Required: false},
+		"source_auth":                &hcldec.BlockListSpec{TypeName: "source_auth", Nested: hcldec.ObjectSpec((*FlatSourceAuthConfig)(nil).HCL2Spec())},
 		"options":                    &hcldec.AttrSpec{Name: "options", Type: cty.String, Required: false},
 		"log_level":                  &hcldec.AttrSpec{Name: "log_level", Type: cty.String, Required: false},
+		"fancy_output":               &hcldec.AttrSpec{Name: "fancy_output", Type: cty.Bool, Required: false},
+		"build_log":                  &hcldec.BlockSpec{TypeName: "build_log", Nested: hcldec.ObjectSpec((*FlatBuildLogConfig)(nil).HCL2Spec())},
+		"driver":                     &hcldec.AttrSpec{Name: "driver", Type: cty.String, Required: false},
+		"kraft_binary":               &hcldec.AttrSpec{Name: "kraft_binary", Type: cty.String, Required: false},
+		"build_in_container":         &hcldec.AttrSpec{Name: "build_in_container", Type: cty.Bool, Required: false},
+		"container_image":            &hcldec.AttrSpec{Name: "container_image", Type: cty.String, Required: false},
+		"container_runtime":          &hcldec.AttrSpec{Name: "container_runtime", Type: cty.String, Required: false},
+		"cache_dir":                  &hcldec.AttrSpec{Name: "cache_dir", Type: cty.String, Required: false},
+		"shared_cache":               &hcldec.AttrSpec{Name: "shared_cache", Type: cty.Bool, Required: false},
+		"clean_cache":                &hcldec.AttrSpec{Name: "clean_cache", Type: cty.Bool, Required: false},
+		"configure_timeout":          &hcldec.AttrSpec{Name: "configure_timeout", Type: cty.String, Required: false},
+		"build_timeout":              &hcldec.AttrSpec{Name: "build_timeout", Type: cty.String, Required: false},
+		"build_jobs":                 &hcldec.AttrSpec{Name: "build_jobs", Type: cty.Number, Required: false},
+		"build_fast":                 &hcldec.AttrSpec{Name: "build_fast", Type: cty.Bool, Required: false},
+		"use_ccache":                 &hcldec.AttrSpec{Name: "use_ccache", Type: cty.Bool, Required: false},
+		"incremental":                &hcldec.AttrSpec{Name: "incremental", Type: cty.Bool, Required: false},
+		"skip_unbuildable":           &hcldec.AttrSpec{Name: "skip_unbuildable", Type: cty.Bool, Required: false},
+		"continue_on_error":          &hcldec.AttrSpec{Name: "continue_on_error", Type: cty.Bool, Required: false},
+		"validator":                  &hcldec.AttrSpec{Name: "validator", Type: cty.String, Required: false},
+		"changed_since":              &hcldec.AttrSpec{Name: "changed_since", Type: cty.String, Required: false},
+		"expected_digests":           &hcldec.AttrSpec{Name: "expected_digests", Type: cty.Map(cty.String), Required: false},
+		"rootfs_dir":                 &hcldec.AttrSpec{Name: "rootfs_dir", Type: cty.String, Required: false},
+		"rootfs_dockerfile":          &hcldec.AttrSpec{Name: "rootfs_dockerfile", Type: cty.String, Required: false},
+		"rootfs_buildkit_host":       &hcldec.AttrSpec{Name: "rootfs_buildkit_host", Type: cty.String, Required: false},
+		"kernel_name":                &hcldec.AttrSpec{Name: "kernel_name", Type: cty.String, Required: false},
+		"dbg_output":                 &hcldec.AttrSpec{Name: "dbg_output", Type: cty.String, Required: false},
+		"debug_bundle":               &hcldec.AttrSpec{Name: "debug_bundle", Type: cty.Bool, Required: false},
+		"output_dir":                 &hcldec.AttrSpec{Name: "output_dir", Type: cty.String, Required: false},
+		"artifact_name":              &hcldec.AttrSpec{Name: "artifact_name", Type: cty.String, Required: false},
+		"no_build_environment":       &hcldec.AttrSpec{Name: "no_build_environment", Type: cty.Bool, Required: false},
+		"cmdline":                    &hcldec.AttrSpec{Name: "cmdline", Type: cty.String, Required: false},
+		"test_boot":                  &hcldec.BlockSpec{TypeName: "test_boot", Nested: hcldec.ObjectSpec((*FlatTestBootConfig)(nil).HCL2Spec())},
+		"gdb_smoke_test":             &hcldec.BlockSpec{TypeName: "gdb_smoke_test", Nested: hcldec.ObjectSpec((*FlatGDBSmokeTestConfig)(nil).HCL2Spec())},
+		"firecracker":                &hcldec.BlockSpec{TypeName: "firecracker", Nested: hcldec.ObjectSpec((*FlatFirecrackerConfig)(nil).HCL2Spec())},
+		"xen":                        &hcldec.BlockSpec{TypeName: "xen", Nested: hcldec.ObjectSpec((*FlatXenConfig)(nil).HCL2Spec())},
+	}
+	return s
+}
+
+// FlatFirecrackerConfig is an auto-generated flat version of FirecrackerConfig.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatFirecrackerConfig struct {
+	Binary    *string             `mapstructure:"binary" cty:"binary" hcl:"binary"`
+	Memory    *int                `mapstructure:"memory" cty:"memory" hcl:"memory"`
+	VCPUs     *int                `mapstructure:"vcpus" cty:"vcpus" hcl:"vcpus"`
+	BootArgs  *string             `mapstructure:"boot_args" cty:"boot_args" hcl:"boot_args"`
+	SmokeTest *FlatTestBootConfig `mapstructure:"smoke_test" cty:"smoke_test" hcl:"smoke_test"`
+}
+
+// FlatMapstructure returns a new FlatFirecrackerConfig.
+// FlatFirecrackerConfig is an auto-generated flat version of FirecrackerConfig.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*FirecrackerConfig) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatFirecrackerConfig)
+}
+
+// HCL2Spec returns the hcl spec of a FirecrackerConfig.
+// This spec is used by HCL to read the fields of FirecrackerConfig.
+// The decoded values from this spec will then be applied to a FlatFirecrackerConfig.
+func (*FlatFirecrackerConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"binary":     &hcldec.AttrSpec{Name: "binary", Type: cty.String, Required: false},
+		"memory":     &hcldec.AttrSpec{Name: "memory", Type: cty.Number, Required: false},
+		"vcpus":      &hcldec.AttrSpec{Name: "vcpus", Type: cty.Number, Required: false},
+		"boot_args":  &hcldec.AttrSpec{Name: "boot_args", Type: cty.String, Required: false},
+		"smoke_test": &hcldec.BlockSpec{TypeName: "smoke_test", Nested: hcldec.ObjectSpec((*FlatTestBootConfig)(nil).HCL2Spec())},
+	}
+	return s
+}
+
+// FlatGDBSmokeTestConfig is an auto-generated flat version of GDBSmokeTestConfig.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatGDBSmokeTestConfig struct {
+	QEMUBinary  *string `mapstructure:"qemu_binary" cty:"qemu_binary" hcl:"qemu_binary"`
+	GDBBinary   *string `mapstructure:"gdb_binary" cty:"gdb_binary" hcl:"gdb_binary"`
+	EntrySymbol *string `mapstructure:"entry_symbol" cty:"entry_symbol" hcl:"entry_symbol"`
+	Timeout     *string `mapstructure:"timeout" cty:"timeout" hcl:"timeout"`
+}
+
+// FlatMapstructure returns a new FlatGDBSmokeTestConfig.
+// FlatGDBSmokeTestConfig is an auto-generated flat version of GDBSmokeTestConfig.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*GDBSmokeTestConfig) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatGDBSmokeTestConfig)
+}
+
+// HCL2Spec returns the hcl spec of a GDBSmokeTestConfig.
+// This spec is used by HCL to read the fields of GDBSmokeTestConfig.
+// The decoded values from this spec will then be applied to a FlatGDBSmokeTestConfig.
+func (*FlatGDBSmokeTestConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"qemu_binary":  &hcldec.AttrSpec{Name: "qemu_binary", Type: cty.String, Required: false},
+		"gdb_binary":   &hcldec.AttrSpec{Name: "gdb_binary", Type: cty.String, Required: false},
+		"entry_symbol": &hcldec.AttrSpec{Name: "entry_symbol", Type: cty.String, Required: false},
+		"timeout":      &hcldec.AttrSpec{Name: "timeout", Type: cty.String, Required: false},
+	}
+	return s
+}
+
+// FlatLicensePolicyConfig is an auto-generated flat version of LicensePolicyConfig.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatLicensePolicyConfig struct {
+	Allow []string `mapstructure:"allow" cty:"allow" hcl:"allow"`
+	Deny  []string `mapstructure:"deny" cty:"deny" hcl:"deny"`
+}
+
+// FlatMapstructure returns a new FlatLicensePolicyConfig.
+// FlatLicensePolicyConfig is an auto-generated flat version of LicensePolicyConfig.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*LicensePolicyConfig) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatLicensePolicyConfig)
+}
+
+// HCL2Spec returns the hcl spec of a LicensePolicyConfig.
+// This spec is used by HCL to read the fields of LicensePolicyConfig.
+// The decoded values from this spec will then be applied to a FlatLicensePolicyConfig.
+func (*FlatLicensePolicyConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"allow": &hcldec.AttrSpec{Name: "allow", Type: cty.List(cty.String), Required: false},
+		"deny":  &hcldec.AttrSpec{Name: "deny", Type: cty.List(cty.String), Required: false},
+	}
+	return s
+}
+
+// FlatProjectConfig is an auto-generated flat version of ProjectConfig.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatProjectConfig struct {
+	Name            *string                    `mapstructure:"name" required:"true" cty:"name" hcl:"name"`
+	UnikraftVersion *string                    `mapstructure:"unikraft_version" cty:"unikraft_version" hcl:"unikraft_version"`
+	UnikraftSource  *string                    `mapstructure:"unikraft_source" cty:"unikraft_source" hcl:"unikraft_source"`
+	Libraries       []FlatProjectLibraryConfig `mapstructure:"libraries" cty:"libraries" hcl:"libraries"`
+	KConfig         map[string]string          `mapstructure:"kconfig" cty:"kconfig" hcl:"kconfig"`
+}
+
+// FlatMapstructure returns a new FlatProjectConfig.
+// FlatProjectConfig is an auto-generated flat version of ProjectConfig.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*ProjectConfig) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatProjectConfig)
+}
+
+// HCL2Spec returns the hcl spec of a ProjectConfig.
+// This spec is used by HCL to read the fields of ProjectConfig.
+// The decoded values from this spec will then be applied to a FlatProjectConfig.
+func (*FlatProjectConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"name":             &hcldec.AttrSpec{Name: "name", Type: cty.String, Required: false},
+		"unikraft_version": &hcldec.AttrSpec{Name: "unikraft_version", Type: cty.String, Required: false},
+		"unikraft_source":  &hcldec.AttrSpec{Name: "unikraft_source", Type: cty.String, Required: false},
+		"libraries":        &hcldec.BlockListSpec{TypeName: "libraries", Nested: hcldec.ObjectSpec((*FlatProjectLibraryConfig)(nil).HCL2Spec())},
+		"kconfig":          &hcldec.AttrSpec{Name: "kconfig", Type: cty.Map(cty.String), Required: false},
+	}
+	return s
+}
+
+// FlatProjectLibraryConfig is an auto-generated flat version of ProjectLibraryConfig.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatProjectLibraryConfig struct {
+	Name    *string `mapstructure:"name" required:"true" cty:"name" hcl:"name"`
+	Version *string `mapstructure:"version" cty:"version" hcl:"version"`
+	Source  *string `mapstructure:"source" cty:"source" hcl:"source"`
+}
+
+// FlatMapstructure returns a new FlatProjectLibraryConfig.
+// FlatProjectLibraryConfig is an auto-generated flat version of ProjectLibraryConfig.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*ProjectLibraryConfig) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatProjectLibraryConfig)
+}
+
+// HCL2Spec returns the hcl spec of a ProjectLibraryConfig.
+// This spec is used by HCL to read the fields of ProjectLibraryConfig.
+// The decoded values from this spec will then be applied to a FlatProjectLibraryConfig.
+func (*FlatProjectLibraryConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"name":    &hcldec.AttrSpec{Name: "name", Type: cty.String, Required: false},
+		"version": &hcldec.AttrSpec{Name: "version", Type: cty.String, Required: false},
+		"source":  &hcldec.AttrSpec{Name: "source", Type: cty.String, Required: false},
+	}
+	return s
+}
+
+// FlatSourceAuthConfig is an auto-generated flat version of SourceAuthConfig.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatSourceAuthConfig struct {
+	Source            *string `mapstructure:"source" required:"true" cty:"source" hcl:"source"`
+	Username          *string `mapstructure:"username" cty:"username" hcl:"username"`
+	Password          *string `mapstructure:"password" cty:"password" hcl:"password"`
+	Token             *string `mapstructure:"token" cty:"token" hcl:"token"`
+	SSHPrivateKeyFile *string `mapstructure:"ssh_private_key_file" cty:"ssh_private_key_file" hcl:"ssh_private_key_file"`
+	SSHPassphrase     *string `mapstructure:"ssh_passphrase" cty:"ssh_passphrase" hcl:"ssh_passphrase"`
+}
+
+// FlatMapstructure returns a new FlatSourceAuthConfig.
+// FlatSourceAuthConfig is an auto-generated flat version of SourceAuthConfig.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*SourceAuthConfig) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatSourceAuthConfig)
+}
+
+// HCL2Spec returns the hcl spec of a SourceAuthConfig.
+// This spec is used by HCL to read the fields of SourceAuthConfig.
+// The decoded values from this spec will then be applied to a FlatSourceAuthConfig.
+func (*FlatSourceAuthConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"source":               &hcldec.AttrSpec{Name: "source", Type: cty.String, Required: false},
+		"username":             &hcldec.AttrSpec{Name: "username", Type: cty.String, Required: false},
+		"password":             &hcldec.AttrSpec{Name: "password", Type: cty.String, Required: false},
+		"token":                &hcldec.AttrSpec{Name: "token", Type: cty.String, Required: false},
+		"ssh_private_key_file": &hcldec.AttrSpec{Name: "ssh_private_key_file", Type: cty.String, Required: false},
+		"ssh_passphrase":       &hcldec.AttrSpec{Name: "ssh_passphrase", Type: cty.String, Required: false},
+	}
+	return s
+}
+
+// FlatTargetConfig is an auto-generated flat version of TargetConfig.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatTargetConfig struct {
+	Architecture *string           `mapstructure:"architecture" required:"true" cty:"architecture" hcl:"architecture"`
+	Platform     *string           `mapstructure:"platform" required:"true" cty:"platform" hcl:"platform"`
+	Target       *string           `mapstructure:"target" cty:"target" hcl:"target"`
+	KConfig      map[string]string `mapstructure:"kconfig" cty:"kconfig" hcl:"kconfig"`
+}
+
+// FlatMapstructure returns a new FlatTargetConfig.
+// FlatTargetConfig is an auto-generated flat version of TargetConfig.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*TargetConfig) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatTargetConfig)
+}
+
+// HCL2Spec returns the hcl spec of a TargetConfig.
+// This spec is used by HCL to read the fields of TargetConfig.
+// The decoded values from this spec will then be applied to a FlatTargetConfig.
+func (*FlatTargetConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"architecture": &hcldec.AttrSpec{Name: "architecture", Type: cty.String, Required: false},
+		"platform":     &hcldec.AttrSpec{Name: "platform", Type: cty.String, Required: false},
+		"target":       &hcldec.AttrSpec{Name: "target", Type: cty.String, Required: false},
+		"kconfig":      &hcldec.AttrSpec{Name: "kconfig", Type: cty.Map(cty.String), Required: false},
+	}
+	return s
+}
+
+// FlatTestBootConfig is an auto-generated flat version of TestBootConfig.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatTestBootConfig struct {
+	ExpectConsole  *string `mapstructure:"expect_console" cty:"expect_console" hcl:"expect_console"`
+	ExpectExitCode *int    `mapstructure:"expect_exit_code" cty:"expect_exit_code" hcl:"expect_exit_code"`
+	Timeout        *string `mapstructure:"timeout" cty:"timeout" hcl:"timeout"`
+}
+
+// FlatMapstructure returns a new FlatTestBootConfig.
+// FlatTestBootConfig is an auto-generated flat version of TestBootConfig.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*TestBootConfig) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatTestBootConfig)
+}
+
+// HCL2Spec returns the hcl spec of a TestBootConfig.
+// This spec is used by HCL to read the fields of TestBootConfig.
+// The decoded values from this spec will then be applied to a FlatTestBootConfig.
+func (*FlatTestBootConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"expect_console":   &hcldec.AttrSpec{Name: "expect_console", Type: cty.String, Required: false},
+		"expect_exit_code": &hcldec.AttrSpec{Name: "expect_exit_code", Type: cty.Number, Required: false},
+		"timeout":          &hcldec.AttrSpec{Name: "timeout", Type: cty.String, Required: false},
+	}
+	return s
+}
+
+// FlatXenConfig is an auto-generated flat version of XenConfig.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatXenConfig struct {
+	Memory   *int     `mapstructure:"memory" cty:"memory" hcl:"memory"`
+	VCPUs    *int     `mapstructure:"vcpus" cty:"vcpus" hcl:"vcpus"`
+	Vifs     []string `mapstructure:"vifs" cty:"vifs" hcl:"vifs"`
+	BootArgs *string  `mapstructure:"boot_args" cty:"boot_args" hcl:"boot_args"`
+}
+
+// FlatMapstructure returns a new FlatXenConfig.
+// FlatXenConfig is an auto-generated flat version of XenConfig.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*XenConfig) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatXenConfig)
+}
+
+// HCL2Spec returns the hcl spec of a XenConfig.
+// This spec is used by HCL to read the fields of XenConfig.
+// The decoded values from this spec will then be applied to a FlatXenConfig.
+func (*FlatXenConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"memory":    &hcldec.AttrSpec{Name: "memory", Type: cty.Number, Required: false},
+		"vcpus":     &hcldec.AttrSpec{Name: "vcpus", Type: cty.Number, Required: false},
+		"vifs":      &hcldec.AttrSpec{Name: "vifs", Type: cty.List(cty.String), Required: false},
+		"boot_args": &hcldec.AttrSpec{Name: "boot_args", Type: cty.String, Required: false},
 	}
 	return s
 }
@@ -0,0 +1,585 @@
+package unikraft
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+func TestConfigPrepareTargets(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  map[string]interface{}
+		want string
+	}{
+		{name: "single target", raw: map[string]interface{}{
+			"architecture": "x86_64",
+			"platform":     "qemu",
+		}},
+		{name: "targets", raw: map[string]interface{}{
+			"targets": []map[string]interface{}{
+				{"architecture": "x86_64", "platform": "qemu"},
+				{"architecture": "arm64", "platform": "fc"},
+			},
+		}},
+		{name: "targets with architecture", raw: map[string]interface{}{
+			"architecture": "x86_64",
+			"targets": []map[string]interface{}{
+				{"architecture": "arm64", "platform": "fc"},
+			},
+		}, want: "cannot be combined with targets"},
+		{name: "incomplete target", raw: map[string]interface{}{
+			"targets": []map[string]interface{}{
+				{"architecture": "x86_64", "platform": "qemu"},
+				{"architecture": "arm64"},
+			},
+		}, want: "targets[1]: platform must be specified"},
+		{name: "no target", raw: map[string]interface{}{}, want: "architecture must be specified"},
+		{name: "unknown pull manager", raw: map[string]interface{}{
+			"architecture": "x86_64",
+			"platform":     "qemu",
+			"pull_manager": "apt",
+		}, want: "unknown pull_manager"},
+		{name: "update index", raw: map[string]interface{}{
+			"architecture":   "x86_64",
+			"platform":       "qemu",
+			"update_index":   true,
+			"update_manager": "all",
+		}},
+		{name: "unknown update manager", raw: map[string]interface{}{
+			"architecture":   "x86_64",
+			"platform":       "qemu",
+			"update_index":   true,
+			"update_manager": "apt",
+		}, want: "unknown update_manager"},
+		{name: "update manager without update index", raw: map[string]interface{}{
+			"architecture":   "x86_64",
+			"platform":       "qemu",
+			"update_manager": "oci",
+		}, want: "update_manager requires update_index"},
+		{name: "rootfs dir and dockerfile", raw: map[string]interface{}{
+			"architecture":      "x86_64",
+			"platform":          "qemu",
+			"rootfs_dir":        "rootfs",
+			"rootfs_dockerfile": "Dockerfile",
+		}, want: "rootfs_dir cannot be combined with rootfs_dockerfile"},
+		{name: "negative pull concurrency", raw: map[string]interface{}{
+			"architecture":     "x86_64",
+			"platform":         "qemu",
+			"pull_concurrency": -1,
+		}, want: "pull_concurrency must not be negative"},
+		{name: "negative max retries", raw: map[string]interface{}{
+			"architecture": "x86_64",
+			"platform":     "qemu",
+			"max_retries":  -1,
+		}, want: "max_retries must not be negative"},
+		{name: "negative retry backoff", raw: map[string]interface{}{
+			"architecture":  "x86_64",
+			"platform":      "qemu",
+			"retry_backoff": "-1s",
+		}, want: "retry_backoff must not be negative"},
+		{name: "conflicting cross compile", raw: map[string]interface{}{
+			"architecture":  "arm64",
+			"platform":      "qemu",
+			"cross_compile": "aarch64-linux-gnu-",
+			"build_env":     map[string]string{"CROSS_COMPILE": "aarch64-none-elf-"},
+		}, want: "cross_compile cannot be combined with CROSS_COMPILE in build_env"},
+		{name: "unknown driver", raw: map[string]interface{}{
+			"architecture": "x86_64",
+			"platform":     "qemu",
+			"driver":       "docker",
+		}, want: `unknown driver "docker"`},
+		{name: "kraft binary with library driver", raw: map[string]interface{}{
+			"architecture": "x86_64",
+			"platform":     "qemu",
+			"kraft_binary": "/usr/local/bin/kraft",
+		}, want: "kraft_binary requires the cli driver"},
+		{name: "cli driver", raw: map[string]interface{}{
+			"architecture": "x86_64",
+			"platform":     "qemu",
+			"driver":       "cli",
+			"kraft_binary": "/usr/local/bin/kraft",
+		}},
+		{name: "cache dir and shared cache", raw: map[string]interface{}{
+			"architecture": "x86_64",
+			"platform":     "qemu",
+			"cache_dir":    "/var/cache/unikraft",
+			"shared_cache": true,
+		}, want: "cache_dir and shared_cache cannot be combined"},
+		{name: "clean cache without cache", raw: map[string]interface{}{
+			"architecture": "x86_64",
+			"platform":     "qemu",
+			"clean_cache":  true,
+		}, want: "clean_cache requires cache_dir, shared_cache or build_in_container"},
+		{name: "clean shared cache", raw: map[string]interface{}{
+			"architecture": "x86_64",
+			"platform":     "qemu",
+			"shared_cache": true,
+			"clean_cache":  true,
+		}},
+		{name: "missing kraftfile", raw: map[string]interface{}{
+			"architecture": "x86_64",
+			"platform":     "qemu",
+			"kraftfile":    "Kraftfile.prod",
+		}, want: `kraftfile "Kraftfile.prod" not found in build_path`},
+		{name: "per-target kconfig", raw: map[string]interface{}{
+			"kconfig": map[string]string{"CONFIG_LWIP": "y"},
+			"targets": []map[string]interface{}{
+				{"architecture": "x86_64", "platform": "qemu", "kconfig": map[string]string{"CONFIG_LWIP": "n"}},
+			},
+		}},
+		{name: "invalid kconfig symbol", raw: map[string]interface{}{
+			"architecture": "x86_64",
+			"platform":     "qemu",
+			"kconfig":      map[string]string{"LWIP=y": "y"},
+		}, want: `invalid kconfig symbol "LWIP=y"`},
+		{name: "missing kconfig fragment", raw: map[string]interface{}{
+			"architecture":      "x86_64",
+			"platform":          "qemu",
+			"kconfig_fragments": []string{"/nonexistent/net.config"},
+		}, want: "could not read kconfig fragment"},
+		{name: "kconfig script", raw: map[string]interface{}{
+			"architecture":   "x86_64",
+			"platform":       "qemu",
+			"kconfig_script": []string{"enable LIBLWIP", "set-val STACK_SIZE_PAGE_ORDER 4"},
+		}},
+		{name: "invalid kconfig script", raw: map[string]interface{}{
+			"architecture":   "x86_64",
+			"platform":       "qemu",
+			"kconfig_script": []string{"enable LIBLWIP", "toggle LIBUKNETDEV"},
+		}, want: `kconfig_script[1]: unknown operation "toggle"`},
+		{name: "kconfig with cli driver", raw: map[string]interface{}{
+			"architecture": "x86_64",
+			"platform":     "qemu",
+			"driver":       "cli",
+			"kconfig":      map[string]string{"CONFIG_LWIP": "y"},
+		}, want: "the cli driver cannot merge kconfig"},
+		{name: "negative build timeout", raw: map[string]interface{}{
+			"architecture":  "x86_64",
+			"platform":      "qemu",
+			"build_timeout": "-1m",
+		}, want: "configure_timeout and build_timeout must not be negative"},
+		{name: "timeouts with cli driver", raw: map[string]interface{}{
+			"architecture":      "x86_64",
+			"platform":          "qemu",
+			"driver":            "cli",
+			"configure_timeout": "5m",
+		}, want: "the cli driver cannot bound phases"},
+		{name: "build jobs", raw: map[string]interface{}{
+			"architecture": "x86_64",
+			"platform":     "qemu",
+			"build_jobs":   8,
+			"build_fast":   false,
+			"use_ccache":   true,
+		}},
+		{name: "negative build jobs", raw: map[string]interface{}{
+			"architecture": "x86_64",
+			"platform":     "qemu",
+			"build_jobs":   -1,
+		}, want: "build_jobs must not be negative"},
+		{name: "ccache with compiler", raw: map[string]interface{}{
+			"architecture": "x86_64",
+			"platform":     "qemu",
+			"use_ccache":   true,
+			"build_env":    map[string]string{"CC": "clang"},
+		}, want: "use_ccache cannot be combined with CC"},
+		{name: "ccache with cli driver", raw: map[string]interface{}{
+			"architecture": "x86_64",
+			"platform":     "qemu",
+			"driver":       "cli",
+			"use_ccache":   true,
+		}, want: "the cli driver cannot wrap the compilers"},
+		{name: "incremental with cli driver", raw: map[string]interface{}{
+			"architecture": "x86_64",
+			"platform":     "qemu",
+			"driver":       "cli",
+			"incremental":  true,
+		}, want: "the cli driver cannot build incrementally"},
+		{name: "candidate resolution with cli driver", raw: map[string]interface{}{
+			"architecture":         "x86_64",
+			"platform":             "qemu",
+			"driver":               "cli",
+			"candidate_resolution": map[string]interface{}{"prefer_version": "highest"},
+		}, want: "the cli driver cannot pick among catalog candidates"},
+		{name: "component mirrors with cli driver", raw: map[string]interface{}{
+			"architecture":      "x86_64",
+			"platform":          "qemu",
+			"driver":            "cli",
+			"component_mirrors": map[string]string{"https://github.com/unikraft": "https://mirror.example.com/unikraft"},
+		}, want: "the cli driver cannot download components from component_mirrors or through proxies"},
+		{name: "source auth with cli driver", raw: map[string]interface{}{
+			"architecture": "x86_64",
+			"platform":     "qemu",
+			"driver":       "cli",
+			"source_auth":  []map[string]interface{}{{"source": "https://manifests.example.com", "token": "secret"}},
+		}, want: "the cli driver cannot authenticate against private sources with source_auth"},
+		{name: "build in container", raw: map[string]interface{}{
+			"architecture":       "x86_64",
+			"platform":           "qemu",
+			"build_in_container": true,
+			"container_runtime":  "podman",
+			"clean_cache":        true,
+		}},
+		{name: "build in container with library driver", raw: map[string]interface{}{
+			"architecture":       "x86_64",
+			"platform":           "qemu",
+			"driver":             "library",
+			"build_in_container": true,
+		}, want: "build_in_container cannot be combined with the library driver"},
+		{name: "build in container with kconfig", raw: map[string]interface{}{
+			"architecture":       "x86_64",
+			"platform":           "qemu",
+			"build_in_container": true,
+			"kconfig":            map[string]string{"CONFIG_LWIP": "y"},
+		}, want: "the cli driver cannot merge kconfig"},
+		{name: "container image without container", raw: map[string]interface{}{
+			"architecture":    "x86_64",
+			"platform":        "qemu",
+			"container_image": "kraftkit.sh/myself-full:latest",
+		}, want: "container_image and container_runtime require build_in_container"},
+		{name: "unknown container runtime", raw: map[string]interface{}{
+			"architecture":       "x86_64",
+			"platform":           "qemu",
+			"build_in_container": true,
+			"container_runtime":  "lxc",
+		}, want: `unknown container_runtime "lxc"`},
+		{name: "negative boot timeout", raw: map[string]interface{}{
+			"architecture": "x86_64",
+			"platform":     "qemu",
+			"test_boot":    map[string]interface{}{"timeout": "-1s"},
+		}, want: "test_boot timeout must not be negative"},
+		{name: "gdb smoke test", raw: map[string]interface{}{
+			"architecture":   "x86_64",
+			"platform":       "qemu",
+			"gdb_smoke_test": map[string]interface{}{"gdb_binary": "gdb-multiarch", "entry_symbol": "_libkvmplat_entry"},
+		}},
+		{name: "negative gdb smoke test timeout", raw: map[string]interface{}{
+			"architecture":   "x86_64",
+			"platform":       "qemu",
+			"gdb_smoke_test": map[string]interface{}{"timeout": "-1s"},
+		}, want: "gdb_smoke_test timeout must not be negative"},
+		{name: "firecracker alias", raw: map[string]interface{}{
+			"architecture": "x86_64",
+			"platform":     "firecracker",
+		}},
+		{name: "unsupported firecracker architecture", raw: map[string]interface{}{
+			"targets": []map[string]interface{}{
+				{"architecture": "arm", "platform": "fc"},
+			},
+		}, want: "firecracker does not run arm kernels"},
+		{name: "unknown checksum policy", raw: map[string]interface{}{
+			"architecture":    "x86_64",
+			"platform":        "qemu",
+			"checksum_policy": "strict",
+		}, want: `unknown checksum_policy "strict"`},
+		{name: "no checksum with enforced checksums", raw: map[string]interface{}{
+			"architecture":     "x86_64",
+			"platform":         "qemu",
+			"pull_no_checksum": true,
+			"checksum_policy":  "enforce",
+		}, want: "pull_no_checksum cannot be combined with checksum_policy"},
+		{name: "output templates", raw: map[string]interface{}{
+			"architecture":  "x86_64",
+			"platform":      "qemu",
+			"output_dir":    "dist/{{ .Plat }}",
+			"artifact_name": "{{ .Target }}-{{ timestamp }}",
+		}},
+		{name: "artifact name without output dir", raw: map[string]interface{}{
+			"architecture":  "x86_64",
+			"platform":      "qemu",
+			"artifact_name": "{{ .Target }}",
+		}, want: "artifact_name requires output_dir"},
+		{name: "artifact name of a directory", raw: map[string]interface{}{
+			"architecture":  "x86_64",
+			"platform":      "qemu",
+			"output_dir":    "dist",
+			"artifact_name": "{{ .Plat }}/{{ .Arch }}",
+		}, want: "expected a filename"},
+		{name: "kernel templates", raw: map[string]interface{}{
+			"targets": []map[string]interface{}{
+				{"architecture": "x86_64", "platform": "qemu"},
+				{"architecture": "arm64", "platform": "qemu"},
+			},
+			"kernel_name": "app-{{ .Version }}_{{ .Platform }}-{{ .Architecture }}",
+			"dbg_output":  "symbols/{{ .Target }}.dbg",
+		}},
+		{name: "kernel name of a directory", raw: map[string]interface{}{
+			"architecture": "x86_64",
+			"platform":     "qemu",
+			"kernel_name":  "{{ .Plat }}/{{ .Arch }}",
+		}, want: "kernel_name renders to"},
+		{name: "negative firecracker memory", raw: map[string]interface{}{
+			"architecture": "x86_64",
+			"platform":     "fc",
+			"firecracker":  map[string]interface{}{"memory": -1},
+		}, want: "memory and vcpus must not be negative"},
+		{name: "empty xen vif", raw: map[string]interface{}{
+			"architecture": "x86_64",
+			"platform":     "xen",
+			"xen":          map[string]interface{}{"vifs": []string{"bridge=xenbr0", " "}},
+		}, want: "xen vifs[1] must not be empty"},
+		{name: "unterminated cmdline", raw: map[string]interface{}{
+			"architecture": "x86_64",
+			"platform":     "qemu",
+			"cmdline":      `/bin/app "unterminated`,
+		}, want: "invalid cmdline"},
+		{name: "unknown preferred candidate format", raw: map[string]interface{}{
+			"architecture":         "x86_64",
+			"platform":             "qemu",
+			"candidate_resolution": map[string]interface{}{"prefer_format": "tarball"},
+		}, want: `candidate_resolution: unknown prefer_format "tarball"`},
+		{name: "mirrors and proxies", raw: map[string]interface{}{
+			"architecture":      "x86_64",
+			"platform":          "qemu",
+			"component_mirrors": map[string]string{"https://github.com/unikraft": "https://mirror.example.com/unikraft"},
+			"https_proxy":       "socks5://proxy.example.com:1080",
+			"no_proxy":          "localhost,.internal",
+		}},
+		{name: "relative component mirror", raw: map[string]interface{}{
+			"architecture":      "x86_64",
+			"platform":          "qemu",
+			"component_mirrors": map[string]string{"https://github.com/unikraft": "mirror/unikraft"},
+		}, want: `invalid component mirror "mirror/unikraft"`},
+		{name: "source auth", raw: map[string]interface{}{
+			"architecture": "x86_64",
+			"platform":     "qemu",
+			"sources":      []string{"https://manifests.example.com/index.yaml"},
+			"source_auth": []map[string]interface{}{
+				{"source": "https://manifests.example.com", "username": "ci", "password": "secret"},
+				{"source": "https://github.com/acme", "token": "ghp_123"},
+			},
+		}},
+		{name: "source auth without credentials", raw: map[string]interface{}{
+			"architecture": "x86_64",
+			"platform":     "qemu",
+			"source_auth":  []map[string]interface{}{{"source": "https://manifests.example.com"}},
+		}, want: "source_auth[0]: https://manifests.example.com: expected username and password, token or ssh_private_key_file"},
+		{name: "unknown proxy scheme", raw: map[string]interface{}{
+			"architecture": "x86_64",
+			"platform":     "qemu",
+			"http_proxy":   "ftp://proxy.example.com",
+		}, want: "invalid http_proxy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.raw["build_path"] = t.TempDir()
+
+			var c Config
+			_, err := c.Prepare(tt.raw)
+			if tt.want == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestConfigBuildTargets(t *testing.T) {
+	single := Config{Architecture: "x86_64", Platform: "qemu", Target: "app-qemu-x86_64"}
+	if got, want := single.BuildTargets(), []TargetConfig{{Architecture: "x86_64", Platform: "qemu", Target: "app-qemu-x86_64"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("BuildTargets() = %v, want %v", got, want)
+	}
+
+	matrix := Config{Targets: []TargetConfig{
+		{Architecture: "x86_64", Platform: "qemu"},
+		{Architecture: "arm64", Platform: "fc"},
+	}}
+	if got := matrix.BuildTargets(); !reflect.DeepEqual(got, matrix.Targets) {
+		t.Errorf("BuildTargets() = %v, want %v", got, matrix.Targets)
+	}
+}
+
+func TestConfigPullOptionsConcurrency(t *testing.T) {
+	if got := (&Config{}).PullOptions().Concurrency; got != DefaultPullConcurrency {
+		t.Errorf("Concurrency = %d, want %d", got, DefaultPullConcurrency)
+	}
+
+	if got := (&Config{PullConcurrency: 1}).PullOptions().Concurrency; got != 1 {
+		t.Errorf("Concurrency = %d, want 1", got)
+	}
+}
+
+func TestConfigPullChecksumPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   string
+	}{
+		{name: "default", config: Config{}, want: ChecksumEnforce},
+		{name: "warn", config: Config{ChecksumPolicy: ChecksumWarn}, want: ChecksumWarn},
+		{name: "no checksum", config: Config{PullNoChecksum: true}, want: ChecksumOff},
+	}
+
+	for _, tt := range tests {
+		if got := tt.config.PullOptions().ChecksumPolicy; got != tt.want {
+			t.Errorf("%s: ChecksumPolicy = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestConfigCachePaths(t *testing.T) {
+	if _, cached, err := (&Config{}).CachePaths(); cached || err != nil {
+		t.Errorf("expected no cache, got %v, %v", cached, err)
+	}
+
+	paths, cached, err := (&Config{CacheDir: "/var/cache/unikraft"}).CachePaths()
+	if err != nil || !cached || paths != NewCachePaths("/var/cache/unikraft") {
+		t.Errorf("CachePaths() = %+v, %v, %v", paths, cached, err)
+	}
+
+	t.Setenv("XDG_CACHE_HOME", "/home/ci/.cache")
+	shared, err := os.UserCacheDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths, cached, err = (&Config{SharedCache: true}).CachePaths()
+	if err != nil || !cached || paths != NewCachePaths(filepath.Join(shared, "packer-plugin-unikraft")) {
+		t.Errorf("CachePaths() = %+v, %v, %v", paths, cached, err)
+	}
+
+	paths, cached, err = (&Config{BuildInContainer: true}).CachePaths()
+	if err != nil || !cached || paths != NewCachePaths(filepath.Join(shared, "packer-plugin-unikraft")) {
+		t.Errorf("expected container builds to use the shared cache, got %+v, %v, %v", paths, cached, err)
+	}
+}
+
+func TestConfigNoFast(t *testing.T) {
+	var c Config
+	if c.NoFast() {
+		t.Error("expected builds to be fast by default")
+	}
+
+	fast := false
+	c.BuildFast = &fast
+	if !c.NoFast() {
+		t.Error("expected build_fast = false to disable fast builds")
+	}
+}
+
+func TestConfigRetries(t *testing.T) {
+	retries, backoff := (&Config{}).Retries()
+	if retries != DefaultMaxRetries || backoff != DefaultRetryBackoff {
+		t.Errorf("Retries() = %d, %s, want %d, %s", retries, backoff, DefaultMaxRetries, DefaultRetryBackoff)
+	}
+
+	disabled := 0
+	retries, backoff = (&Config{MaxRetries: &disabled, RetryBackoff: 5 * time.Second}).Retries()
+	if retries != 0 || backoff != 5*time.Second {
+		t.Errorf("Retries() = %d, %s, want 0, 5s", retries, backoff)
+	}
+
+	opts := (&Config{}).PullOptions()
+	if opts.Retries != DefaultMaxRetries || opts.RetryBackoff != DefaultRetryBackoff {
+		t.Errorf("PullOptions() retries = %d, %s", opts.Retries, opts.RetryBackoff)
+	}
+}
+
+func TestConfigPullSourceList(t *testing.T) {
+	c := Config{PullSource: "app-helloworld", PullSources: []string{"lib-musl", "lib-lwip"}}
+	if got, want := c.PullSourceList(), []string{"app-helloworld", "lib-musl", "lib-lwip"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("PullSourceList() = %v, want %v", got, want)
+	}
+
+	if got := (&Config{}).PullSourceList(); len(got) != 0 {
+		t.Errorf("expected no sources, got %v", got)
+	}
+}
+
+func TestConfigPrepareGitSource(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  map[string]interface{}
+		want string
+	}{
+		{name: "repository", raw: map[string]interface{}{
+			"source_repository": "https://github.com/unikraft/catalog",
+			"source_ref":        "stable",
+			"source_path":       "library/nginx/1.25",
+		}},
+		{name: "repository and build path", raw: map[string]interface{}{
+			"source_repository": "https://github.com/unikraft/catalog",
+			"build_path":        "/tmp/nginx",
+		}, want: "build_path cannot be combined with source_repository"},
+		{name: "escaping path", raw: map[string]interface{}{
+			"source_repository": "https://github.com/unikraft/catalog",
+			"source_path":       "../nginx",
+		}, want: "must not leave the repository"},
+		{name: "ref without repository", raw: map[string]interface{}{
+			"build_path": "/tmp/nginx",
+			"source_ref": "stable",
+		}, want: "require source_repository"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.raw["architecture"] = "x86_64"
+			tt.raw["platform"] = "qemu"
+
+			var c Config
+			_, err := c.Prepare(tt.raw)
+			if tt.want == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestConfigPrepareNormalizesFirecracker(t *testing.T) {
+	var c Config
+	_, err := c.Prepare(map[string]interface{}{
+		"build_path": t.TempDir(),
+		"targets": []map[string]interface{}{
+			{"architecture": "x86_64", "platform": "firecracker"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := c.BuildTargets()[0].Platform; got != "fc" {
+		t.Errorf("platform = %q, want fc", got)
+	}
+}
+
+func TestConfigPrepareFiltersSourceAuthSecrets(t *testing.T) {
+	var c Config
+	_, err := c.Prepare(map[string]interface{}{
+		"build_path":   t.TempDir(),
+		"architecture": "x86_64",
+		"platform":     "qemu",
+		"source_auth":  []map[string]interface{}{{"source": "https://github.com/acme", "token": "ghp_sourceauth"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := packer.LogSecretFilter.FilterString("token ghp_sourceauth"); got != "token <sensitive>" {
+		t.Errorf("filtered = %q, want the token filtered out", got)
+	}
+}
+
+func TestCmdlineBootArgs(t *testing.T) {
+	var fc *FirecrackerConfig
+	if got := fc.VM("app_fc-x86_64", "-- /bin/app").BootArgs; got != "-- /bin/app" {
+		t.Errorf("firecracker boot args = %q, want the cmdline", got)
+	}
+
+	fc = &FirecrackerConfig{BootArgs: "console=ttyS0"}
+	if got := fc.VM("app_fc-x86_64", "-- /bin/app").BootArgs; got != "console=ttyS0" {
+		t.Errorf("firecracker boot args = %q, want boot_args", got)
+	}
+
+	xen := &XenConfig{Memory: 128}
+	if got := xen.Domain("app", "app_xen-x86_64", "-- /bin/app").BootArgs; got != "-- /bin/app" {
+		t.Errorf("xen boot args = %q, want the cmdline", got)
+	}
+}
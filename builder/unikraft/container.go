@@ -0,0 +1,150 @@
+package unikraft
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultContainerImage is the image builds run in when container_image is
+// not set: the builder image of KraftKit, which ships kraft along with the
+// toolchains of every architecture.
+const DefaultContainerImage = "kraftkit.sh/myself-full:latest"
+
+// ContainerRuntimes are the runtimes builds may run containers with, in the
+// order they are looked up.
+var ContainerRuntimes = []string{"docker", "podman"}
+
+// Container runs kraft in containers of Image, each removed once its command
+// exits.  Directories are mounted at the same path as on the host, such that
+// the paths of the commands and of their outputs are the same in and out of
+// the containers.
+type Container struct {
+	// Runtime is the docker or podman executable.
+	Runtime string
+	Image   string
+	// Home is the home directory of kraft, which keeps its configuration
+	// across the commands of a build.
+	Home string
+	// Mounts are the directories mounted in every container, such as the
+	// cache.
+	Mounts []string
+	// User, when set, is the `uid:gid` the commands run as, such that their
+	// outputs are owned by the user of the host.
+	User string
+	// Workdir is the directory the commands run in, the current directory
+	// when empty.
+	Workdir string
+}
+
+// NewContainer returns the containers of image run with runtime.  Docker runs
+// the commands as the user of the host, which rootless Podman does already.
+func NewContainer(runtime, image, home string, mounts []string) *Container {
+	c := &Container{
+		Runtime: runtime,
+		Image:   image,
+		Home:    home,
+		Mounts:  mounts,
+	}
+	if strings.TrimSuffix(filepath.Base(runtime), ".exe") == "docker" {
+		c.User = fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid())
+	}
+
+	return c
+}
+
+// LookupContainerRuntime returns the path of runtime, or of the first of
+// ContainerRuntimes found when it is empty.
+func LookupContainerRuntime(runtime string) (string, error) {
+	if runtime != "" {
+		path, err := exec.LookPath(runtime)
+		if err != nil {
+			return "", fmt.Errorf("could not find container runtime: %w", err)
+		}
+		return path, nil
+	}
+
+	for _, name := range ContainerRuntimes {
+		if path, err := exec.LookPath(name); err == nil {
+			return path, nil
+		}
+	}
+
+	return "", fmt.Errorf("could not find a container runtime, expected %s", strings.Join(ContainerRuntimes, " or "))
+}
+
+// Args returns the arguments of the runtime running command in a container,
+// with dirs mounted along with Home and Mounts, and env set.
+func (c *Container) Args(env map[string]string, dirs []string, command ...string) ([]string, error) {
+	workdir := c.Workdir
+	if workdir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		workdir = wd
+	}
+
+	args := []string{"run", "--rm", "--workdir", workdir}
+	if c.User != "" {
+		args = append(args, "--user", c.User)
+	}
+
+	var mounts []string
+	for _, m := range append(append([]string{c.Home}, c.Mounts...), dirs...) {
+		switch {
+		case m == "":
+			continue
+		case !filepath.IsAbs(m):
+			m = filepath.Join(workdir, m)
+		}
+		mounts = append(mounts, m)
+	}
+	for _, m := range containerMounts(mounts) {
+		args = append(args, "--volume", m+":"+m)
+	}
+
+	vars := map[string]string{}
+	for k, v := range env {
+		vars[k] = v
+	}
+	if c.Home != "" {
+		vars["HOME"] = c.Home
+	}
+	for _, k := range sortedKeys(vars) {
+		args = append(args, "--env", k+"="+vars[k])
+	}
+
+	args = append(args, c.Image)
+
+	return append(args, command...), nil
+}
+
+// containerMounts returns the directories of mounts to mount, sorted, leaving
+// out those within another.
+func containerMounts(mounts []string) []string {
+	cleaned := make([]string, 0, len(mounts))
+	for _, m := range mounts {
+		cleaned = append(cleaned, filepath.Clean(m))
+	}
+	sort.Strings(cleaned)
+
+	var kept []string
+	for _, m := range cleaned {
+		nested := false
+		for _, k := range kept {
+			if m == k || strings.HasPrefix(m, strings.TrimSuffix(k, string(filepath.Separator))+string(filepath.Separator)) {
+				nested = true
+				break
+			}
+		}
+		if !nested {
+			kept = append(kept, m)
+		}
+	}
+
+	return kept
+}
@@ -0,0 +1,89 @@
+package unikraft
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestContainerArgs(t *testing.T) {
+	c := &Container{
+		Runtime: "docker",
+		Image:   DefaultContainerImage,
+		Home:    "/tmp/home",
+		Mounts:  []string{"/cache/manifests", "/cache/sources"},
+		User:    "1000:1000",
+		Workdir: "/src",
+	}
+
+	got, err := c.Args(
+		map[string]string{"CROSS_COMPILE": "aarch64-linux-gnu-"},
+		[]string{"app", "/src/app/.unikraft", ""},
+		"kraft", "build", "app",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"run", "--rm", "--workdir", "/src",
+		"--user", "1000:1000",
+		"--volume", "/cache/manifests:/cache/manifests",
+		"--volume", "/cache/sources:/cache/sources",
+		"--volume", "/src/app:/src/app",
+		"--volume", "/tmp/home:/tmp/home",
+		"--env", "CROSS_COMPILE=aarch64-linux-gnu-",
+		"--env", "HOME=/tmp/home",
+		DefaultContainerImage,
+		"kraft", "build", "app",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Args() = %q, want %q", got, want)
+	}
+}
+
+func TestContainerMounts(t *testing.T) {
+	got := containerMounts([]string{"/a/b/c", "/a-c", "/a/", "/a/b", "/d", "/a-c"})
+	want := []string{"/a", "/a-c", "/d"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("containerMounts() = %q, want %q", got, want)
+	}
+}
+
+func TestNewContainerUser(t *testing.T) {
+	if c := NewContainer("/usr/bin/docker", DefaultContainerImage, "", nil); c.User != fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()) {
+		t.Errorf("expected docker to run as the user of the host, got %q", c.User)
+	}
+
+	if c := NewContainer("/usr/bin/podman", DefaultContainerImage, "", nil); c.User != "" {
+		t.Errorf("expected podman to run as its default user, got %q", c.User)
+	}
+}
+
+func TestLookupContainerRuntime(t *testing.T) {
+	dir := t.TempDir()
+	podman := filepath.Join(dir, "podman")
+	if err := os.WriteFile(podman, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+
+	got, err := LookupContainerRuntime("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != podman {
+		t.Errorf("LookupContainerRuntime() = %s, want %s", got, podman)
+	}
+
+	if _, err := LookupContainerRuntime("docker"); err == nil {
+		t.Error("expected a missing runtime to fail")
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	if _, err := LookupContainerRuntime(""); err == nil {
+		t.Error("expected no runtime to fail")
+	}
+}
@@ -0,0 +1,269 @@
+package unikraft
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"debug/dwarf"
+	"debug/elf"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	// DebugBundleSuffix is appended to the name of a kernel to name its
+	// debug bundle.
+	DebugBundleSuffix = ".debug.tar.gz"
+	// SystemMapFile is the symbol table of the kernel in a debug bundle.
+	SystemMapFile = "System.map"
+	// DebugInfoFile describes the kernel of a debug bundle.
+	DebugInfoFile = "debug-info.json"
+)
+
+// DebugBundle gathers what crash analysis tooling needs to symbolicate the
+// panics of a stripped kernel: its unstripped kernel, which carries its DWARF
+// info, along with a System.map of its symbols.
+type DebugBundle struct {
+	// Kernel is the unstripped kernel.
+	Kernel       string
+	Platform     string
+	Architecture string
+	// BuildID is the build ID of the kernel, if known.
+	BuildID string
+	// SHA256 is the `sha256:<hex>` digest of the stripped kernel the bundle
+	// symbolicates.
+	SHA256 string
+}
+
+// DebugInfo describes the kernel of a debug bundle, as its DebugInfoFile.
+type DebugInfo struct {
+	Kernel       string `json:"kernel"`
+	Platform     string `json:"platform"`
+	Architecture string `json:"architecture"`
+	BuildID      string `json:"build_id,omitempty"`
+	SHA256       string `json:"sha256,omitempty"`
+	// Symbols is the number of symbols of the System.map.
+	Symbols int `json:"symbols"`
+	// DWARF is whether the unstripped kernel carries DWARF info, and
+	// CompileUnits the number of compile units it describes.
+	DWARF        bool `json:"dwarf"`
+	CompileUnits int  `json:"compile_units,omitempty"`
+}
+
+// Write writes the bundle as a gzipped tarball to output, and returns what it
+// describes the kernel as along with the `sha256:<hex>` digest of the bundle.
+func (b DebugBundle) Write(output string) (DebugInfo, string, error) {
+	f, err := elf.Open(b.Kernel)
+	if err != nil {
+		return DebugInfo{}, "", fmt.Errorf("could not read debug kernel %s: %w", b.Kernel, err)
+	}
+	defer f.Close()
+
+	symbols, count, err := systemMap(f)
+	if err != nil {
+		return DebugInfo{}, "", fmt.Errorf("could not read the symbols of %s: %w", b.Kernel, err)
+	}
+
+	info := DebugInfo{
+		Kernel:       filepath.Base(b.Kernel),
+		Platform:     b.Platform,
+		Architecture: b.Architecture,
+		BuildID:      b.BuildID,
+		SHA256:       b.SHA256,
+		Symbols:      count,
+	}
+	info.DWARF, info.CompileUnits = compileUnits(f)
+
+	raw, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return DebugInfo{}, "", err
+	}
+
+	stat, err := os.Stat(b.Kernel)
+	if err != nil {
+		return DebugInfo{}, "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(output), 0o755); err != nil {
+		return DebugInfo{}, "", err
+	}
+
+	out, err := os.Create(output)
+	if err != nil {
+		return DebugInfo{}, "", err
+	}
+
+	err = writeDebugBundle(out, archiveEntry{
+		name: info.Kernel,
+		path: b.Kernel,
+		mode: 0o755,
+		size: stat.Size(),
+	}, symbols, append(raw, '\n'))
+	if err != nil {
+		out.Close()
+		os.Remove(output)
+		return DebugInfo{}, "", fmt.Errorf("could not write debug bundle: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return DebugInfo{}, "", err
+	}
+
+	digest, err := fileDigest(output)
+	if err != nil {
+		return DebugInfo{}, "", err
+	}
+
+	return info, digest, nil
+}
+
+// writeDebugBundle writes the kernel, System.map and debug info of a bundle
+// to w as a gzipped tarball.
+func writeDebugBundle(w io.Writer, kernel archiveEntry, symbols, info []byte) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	header := func(name string, mode, size int64) error {
+		return tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Mode:     mode,
+			Size:     size,
+			ModTime:  time.Unix(0, 0),
+		})
+	}
+
+	if err := header(kernel.name, kernel.mode, kernel.size); err != nil {
+		return err
+	}
+	if err := copyEntry(tw, kernel); err != nil {
+		return err
+	}
+
+	for _, f := range []struct {
+		name string
+		data []byte
+	}{{SystemMapFile, symbols}, {DebugInfoFile, info}} {
+		if err := header(f.name, 0o644, int64(len(f.data))); err != nil {
+			return err
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	return gw.Close()
+}
+
+// systemMap returns the symbols of f in the System.map format of `nm`,
+// sorted by address, along with their number.  Undefined, file and section
+// symbols, and the ones of sections which are not loaded, are left out.
+func systemMap(f *elf.File) ([]byte, int, error) {
+	syms, err := f.Symbols()
+	if err != nil && err != elf.ErrNoSymbols {
+		return nil, 0, err
+	}
+
+	type entry struct {
+		addr uint64
+		kind byte
+		name string
+	}
+
+	var entries []entry
+	for _, s := range syms {
+		if s.Name == "" || s.Section == elf.SHN_UNDEF {
+			continue
+		}
+		if t := elf.ST_TYPE(s.Info); t == elf.STT_FILE || t == elf.STT_SECTION {
+			continue
+		}
+
+		kind := symbolKind(f, s)
+		if kind == 0 {
+			continue
+		}
+		if elf.ST_BIND(s.Info) == elf.STB_LOCAL {
+			kind += 'a' - 'A'
+		}
+
+		entries = append(entries, entry{addr: s.Value, kind: kind, name: s.Name})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].addr != entries[j].addr {
+			return entries[i].addr < entries[j].addr
+		}
+		return entries[i].name < entries[j].name
+	})
+
+	width := 16
+	if f.Class == elf.ELFCLASS32 {
+		width = 8
+	}
+
+	var buf bytes.Buffer
+	for _, e := range entries {
+		fmt.Fprintf(&buf, "%0*x %c %s\n", width, e.addr, e.kind, e.name)
+	}
+
+	return buf.Bytes(), len(entries), nil
+}
+
+// symbolKind returns the upper-case `nm` type of s: `A` for absolute, `T`
+// for text, `D` for data, `B` for bss and `R` for read-only data symbols, or
+// 0 for the symbols of sections which are not loaded.
+func symbolKind(f *elf.File, s elf.Symbol) byte {
+	if s.Section == elf.SHN_ABS {
+		return 'A'
+	}
+	if int(s.Section) >= len(f.Sections) {
+		return 0
+	}
+
+	section := f.Sections[s.Section]
+	switch {
+	case section.Flags&elf.SHF_ALLOC == 0:
+		return 0
+	case section.Flags&elf.SHF_EXECINSTR != 0:
+		return 'T'
+	case section.Flags&elf.SHF_WRITE != 0 && section.Type == elf.SHT_NOBITS:
+		return 'B'
+	case section.Flags&elf.SHF_WRITE != 0:
+		return 'D'
+	}
+
+	return 'R'
+}
+
+// compileUnits returns whether f carries DWARF info, and the number of
+// compile units it describes.
+func compileUnits(f *elf.File) (bool, int) {
+	d, err := f.DWARF()
+	if err != nil {
+		return false, 0
+	}
+
+	units := 0
+	r := d.Reader()
+	for {
+		e, err := r.Next()
+		if err != nil || e == nil {
+			break
+		}
+		if e.Tag == dwarf.TagCompileUnit {
+			units++
+		}
+		r.SkipChildren()
+	}
+
+	return units > 0, units
+}
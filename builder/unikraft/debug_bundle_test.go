@@ -0,0 +1,212 @@
+package unikraft
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"debug/elf"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeKernelELF writes a minimal x86_64 ELF kernel to path, with a text,
+// data and bss section and symbols in each of them.
+func writeKernelELF(t *testing.T, path string) {
+	t.Helper()
+
+	var strtab, shstrtab bytes.Buffer
+	str := func(b *bytes.Buffer, s string) uint32 {
+		if b.Len() == 0 {
+			b.WriteByte(0)
+		}
+		off := b.Len()
+		b.WriteString(s + "\x00")
+		return uint32(off)
+	}
+
+	const (
+		text = iota + 1
+		data
+		bss
+		symtab
+		strtabIdx
+		shstrtabIdx
+	)
+	sym := func(name string, bind elf.SymBind, typ elf.SymType, section elf.SectionIndex, value uint64) elf.Sym64 {
+		return elf.Sym64{Name: str(&strtab, name), Info: elf.ST_INFO(bind, typ), Shndx: uint16(section), Value: value}
+	}
+	syms := []elf.Sym64{
+		{},
+		sym("kernel.c", elf.STB_LOCAL, elf.STT_FILE, elf.SHN_ABS, 0),
+		sym("helper", elf.STB_LOCAL, elf.STT_FUNC, text, 0x100010),
+		sym("_start", elf.STB_GLOBAL, elf.STT_FUNC, text, 0x100000),
+		sym("table", elf.STB_GLOBAL, elf.STT_OBJECT, data, 0x200000),
+		sym("counter", elf.STB_GLOBAL, elf.STT_OBJECT, bss, 0x300000),
+		sym("__end", elf.STB_GLOBAL, elf.STT_NOTYPE, elf.SHN_ABS, 0x400000),
+		sym("ukplat_halt", elf.STB_GLOBAL, elf.STT_FUNC, elf.SHN_UNDEF, 0),
+	}
+
+	var body bytes.Buffer
+	body.Write(make([]byte, 64))
+	blob := func(b []byte) uint64 {
+		off := uint64(body.Len())
+		body.Write(b)
+		return off
+	}
+	textOff := blob(make([]byte, 32))
+	dataOff := blob(make([]byte, 8))
+	var symbuf bytes.Buffer
+	if err := binary.Write(&symbuf, binary.LittleEndian, syms); err != nil {
+		t.Fatal(err)
+	}
+	symOff := blob(symbuf.Bytes())
+	strOff := blob(strtab.Bytes())
+
+	sections := []elf.Section64{
+		{},
+		{Name: str(&shstrtab, ".text"), Type: uint32(elf.SHT_PROGBITS), Flags: uint64(elf.SHF_ALLOC | elf.SHF_EXECINSTR), Addr: 0x100000, Off: textOff, Size: 32, Addralign: 16},
+		{Name: str(&shstrtab, ".data"), Type: uint32(elf.SHT_PROGBITS), Flags: uint64(elf.SHF_ALLOC | elf.SHF_WRITE), Addr: 0x200000, Off: dataOff, Size: 8, Addralign: 8},
+		{Name: str(&shstrtab, ".bss"), Type: uint32(elf.SHT_NOBITS), Flags: uint64(elf.SHF_ALLOC | elf.SHF_WRITE), Addr: 0x300000, Size: 8, Addralign: 8},
+		{Name: str(&shstrtab, ".symtab"), Type: uint32(elf.SHT_SYMTAB), Off: symOff, Size: uint64(symbuf.Len()), Link: strtabIdx, Info: 3, Addralign: 8, Entsize: 24},
+		{Name: str(&shstrtab, ".strtab"), Type: uint32(elf.SHT_STRTAB), Off: strOff, Size: uint64(strtab.Len()), Addralign: 1},
+	}
+	name := str(&shstrtab, ".shstrtab")
+	sections = append(sections, elf.Section64{Name: name, Type: uint32(elf.SHT_STRTAB), Off: uint64(body.Len()), Size: uint64(shstrtab.Len()), Addralign: 1})
+	body.Write(shstrtab.Bytes())
+
+	shoff := uint64(body.Len())
+	if err := binary.Write(&body, binary.LittleEndian, sections); err != nil {
+		t.Fatal(err)
+	}
+
+	header := elf.Header64{
+		Type:      uint16(elf.ET_EXEC),
+		Machine:   uint16(elf.EM_X86_64),
+		Version:   uint32(elf.EV_CURRENT),
+		Entry:     0x100000,
+		Shoff:     shoff,
+		Ehsize:    64,
+		Shentsize: 64,
+		Shnum:     uint16(len(sections)),
+		Shstrndx:  shstrtabIdx,
+	}
+	copy(header.Ident[:], elf.ELFMAG)
+	header.Ident[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+	header.Ident[elf.EI_DATA] = byte(elf.ELFDATA2LSB)
+	header.Ident[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+
+	var hdr bytes.Buffer
+	if err := binary.Write(&hdr, binary.LittleEndian, header); err != nil {
+		t.Fatal(err)
+	}
+
+	raw := body.Bytes()
+	copy(raw, hdr.Bytes())
+	if err := os.WriteFile(path, raw, 0o755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDebugBundleWrite(t *testing.T) {
+	dir := t.TempDir()
+	kernel := filepath.Join(dir, "app_qemu-x86_64.dbg")
+	writeKernelELF(t, kernel)
+	output := filepath.Join(dir, "dist", "app_qemu-x86_64"+DebugBundleSuffix)
+
+	bundle := DebugBundle{
+		Kernel:       kernel,
+		Platform:     "qemu",
+		Architecture: "x86_64",
+		BuildID:      "0123abcd",
+		SHA256:       "sha256:feed",
+	}
+	info, digest, err := bundle.Write(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(digest, "sha256:") {
+		t.Errorf("unexpected digest %s", digest)
+	}
+	if info.Symbols != 5 || info.DWARF {
+		t.Errorf("expected 5 symbols and no DWARF info, got %+v", info)
+	}
+
+	f, err := os.Open(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string][]byte{}
+	tr := tar.NewReader(gr)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+
+		raw, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[h.Name] = raw
+	}
+
+	stat, err := os.Stat(kernel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(files[filepath.Base(kernel)]); int64(got) != stat.Size() {
+		t.Errorf("bundled kernel has %d bytes, want %d", got, stat.Size())
+	}
+
+	want := "" +
+		"0000000000100000 T _start\n" +
+		"0000000000100010 t helper\n" +
+		"0000000000200000 D table\n" +
+		"0000000000300000 B counter\n" +
+		"0000000000400000 A __end\n"
+	if got := string(files[SystemMapFile]); got != want {
+		t.Errorf("System.map =\n%s\nwant\n%s", got, want)
+	}
+
+	var got DebugInfo
+	if err := json.Unmarshal(files[DebugInfoFile], &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != info {
+		t.Errorf("debug info = %+v, want %+v", got, info)
+	}
+	if got.BuildID != "0123abcd" || got.SHA256 != "sha256:feed" || got.Platform != "qemu" {
+		t.Errorf("unexpected debug info %+v", got)
+	}
+}
+
+func TestDebugBundleWriteNotELF(t *testing.T) {
+	dir := t.TempDir()
+	kernel := filepath.Join(dir, "app_qemu-x86_64.dbg")
+	if err := os.WriteFile(kernel, []byte("not an ELF"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(dir, "bundle.tar.gz")
+	_, _, err := DebugBundle{Kernel: kernel}.Write(output)
+	if err == nil || !strings.Contains(err.Error(), "could not read debug kernel") {
+		t.Errorf("expected the kernel to be rejected, got %v", err)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Errorf("expected no bundle to be written, got %v", err)
+	}
+}
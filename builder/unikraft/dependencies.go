@@ -0,0 +1,128 @@
+package unikraft
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Requirement is a dependency on a component at a specific version.  An empty
+// version accepts any version.
+type Requirement struct {
+	Name    string
+	Version string
+}
+
+func (r Requirement) String() string {
+	if r.Version == "" {
+		return r.Name
+	}
+
+	return r.Name + "@" + r.Version
+}
+
+// DependencySource returns the direct dependencies of a component.
+type DependencySource interface {
+	Dependencies(ctx context.Context, req Requirement) ([]Requirement, error)
+}
+
+// DependencyConflict is a component required at incompatible versions.
+type DependencyConflict struct {
+	Name string
+	// Versions maps each required version to the components requiring it.
+	Versions map[string][]string
+}
+
+func (c DependencyConflict) String() string {
+	versions := make([]string, 0, len(c.Versions))
+	for v := range c.Versions {
+		versions = append(versions, v)
+	}
+	sort.Strings(versions)
+
+	parts := []string{}
+	for _, v := range versions {
+		parts = append(parts, fmt.Sprintf("%s (required by %s)", v, strings.Join(c.Versions[v], ", ")))
+	}
+
+	return fmt.Sprintf("%s: %s", c.Name, strings.Join(parts, " vs "))
+}
+
+// DependencyConflictError reports all conflicts found in a dependency graph.
+type DependencyConflictError struct {
+	Conflicts []DependencyConflict
+}
+
+func (e *DependencyConflictError) Error() string {
+	lines := []string{"conflicting component versions:"}
+	for _, c := range e.Conflicts {
+		lines = append(lines, " - "+c.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// CheckDependencyGraph walks the dependency graph starting at the roots,
+// which are required by the project itself, and returns a
+// DependencyConflictError when a component is required at more than one
+// version.
+func CheckDependencyGraph(ctx context.Context, roots []Requirement, src DependencySource) error {
+	const project = "project"
+
+	required := map[string]map[string][]string{}
+	visited := map[string]bool{}
+
+	type edge struct {
+		req Requirement
+		by  string
+	}
+
+	queue := []edge{}
+	for _, r := range roots {
+		queue = append(queue, edge{r, project})
+	}
+
+	for len(queue) > 0 {
+		e := queue[0]
+		queue = queue[1:]
+
+		if e.req.Version != "" {
+			if required[e.req.Name] == nil {
+				required[e.req.Name] = map[string][]string{}
+			}
+			required[e.req.Name][e.req.Version] = append(required[e.req.Name][e.req.Version], e.by)
+		}
+
+		if visited[e.req.String()] {
+			continue
+		}
+		visited[e.req.String()] = true
+
+		deps, err := src.Dependencies(ctx, e.req)
+		if err != nil {
+			return fmt.Errorf("could not resolve dependencies of %s: %w", e.req, err)
+		}
+
+		for _, d := range deps {
+			queue = append(queue, edge{d, e.req.String()})
+		}
+	}
+
+	var conflicts []DependencyConflict
+	for name, versions := range required {
+		if len(versions) > 1 {
+			conflicts = append(conflicts, DependencyConflict{Name: name, Versions: versions})
+		}
+	}
+
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].Name < conflicts[j].Name
+	})
+
+	return &DependencyConflictError{Conflicts: conflicts}
+}
@@ -0,0 +1,61 @@
+package unikraft
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type fakeDependencySource map[string][]Requirement
+
+func (f fakeDependencySource) Dependencies(_ context.Context, req Requirement) ([]Requirement, error) {
+	return f[req.String()], nil
+}
+
+func TestCheckDependencyGraphTransitiveConflict(t *testing.T) {
+	src := fakeDependencySource{
+		"lwip@stable":   {{Name: "musl", Version: "stable"}},
+		"nginx@staging": {{Name: "musl", Version: "staging"}},
+	}
+
+	roots := []Requirement{
+		{Name: "unikraft", Version: "stable"},
+		{Name: "lwip", Version: "stable"},
+		{Name: "nginx", Version: "staging"},
+	}
+
+	err := CheckDependencyGraph(context.Background(), roots, src)
+
+	var conflict *DependencyConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("expected a conflict error, got %v", err)
+	}
+	if len(conflict.Conflicts) != 1 || conflict.Conflicts[0].Name != "musl" {
+		t.Fatalf("expected a single conflict on musl, got %+v", conflict.Conflicts)
+	}
+	for _, want := range []string{"stable (required by lwip@stable)", "staging (required by nginx@staging)"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %q", want, err.Error())
+		}
+	}
+}
+
+func TestCheckDependencyGraphNoConflict(t *testing.T) {
+	src := fakeDependencySource{
+		"lwip@stable":   {{Name: "musl", Version: "stable"}},
+		"nginx@stable":  {{Name: "musl"}, {Name: "lwip", Version: "stable"}},
+		"musl@stable":   nil,
+		"unikraft@main": nil,
+	}
+
+	roots := []Requirement{
+		{Name: "unikraft", Version: "main"},
+		{Name: "nginx", Version: "stable"},
+		{Name: "musl", Version: "stable"},
+	}
+
+	if err := CheckDependencyGraph(context.Background(), roots, src); err != nil {
+		t.Errorf("expected no conflicts, got %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+package unikraft
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync"
+)
+
+// Diagnostic is a warning or error reported by the compiler during a build.
+type Diagnostic struct {
+	File     string
+	Line     int
+	Column   int
+	Severity string
+	Message  string
+	Rule     string
+}
+
+// diagnosticPattern matches the `file:line:col: severity: message [-Wflag]`
+// lines emitted by GCC and Clang.
+var diagnosticPattern = regexp.MustCompile(`^(.+?):(\d+):(?:(\d+):)? (warning|error|fatal error|note): (.*?)(?: \[(-W[^\]]+)\])?$`)
+
+// parseDiagnostic parses a single line of compiler output.
+func parseDiagnostic(line string) (Diagnostic, bool) {
+	m := diagnosticPattern.FindStringSubmatch(line)
+	if m == nil {
+		return Diagnostic{}, false
+	}
+
+	d := Diagnostic{
+		File:     m[1],
+		Severity: m[4],
+		Message:  m[5],
+	}
+	d.Line, _ = strconv.Atoi(m[2])
+	d.Column, _ = strconv.Atoi(m[3])
+	if d.Severity == "fatal error" {
+		d.Severity = "error"
+	}
+
+	switch {
+	case m[6] != "":
+		d.Rule = m[6][2:]
+	case d.Severity == "error":
+		d.Rule = "compiler-error"
+	default:
+		d.Rule = "compiler-" + d.Severity
+	}
+
+	return d, true
+}
+
+// diagnosticCollector is an io.Writer which collects the diagnostics found in
+// the compiler output written to it.
+type diagnosticCollector struct {
+	mu          sync.Mutex
+	buf         []byte
+	diagnostics []Diagnostic
+}
+
+func (c *diagnosticCollector) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.buf = append(c.buf, p...)
+	for {
+		i := bytes.IndexByte(c.buf, '\n')
+		if i < 0 {
+			break
+		}
+
+		if d, ok := parseDiagnostic(string(bytes.TrimRight(c.buf[:i], "\r"))); ok {
+			c.diagnostics = append(c.diagnostics, d)
+		}
+		c.buf = c.buf[i+1:]
+	}
+
+	return len(p), nil
+}
+
+// Diagnostics returns the diagnostics collected so far.
+func (c *diagnosticCollector) Diagnostics() []Diagnostic {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]Diagnostic{}, c.diagnostics...)
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// newSARIF converts diagnostics into a SARIF 2.1.0 log.
+func newSARIF(diagnostics []Diagnostic) sarifLog {
+	run := sarifRun{
+		Tool:    sarifTool{Driver: sarifDriver{Name: "unikraft", Rules: []sarifRule{}}},
+		Results: []sarifResult{},
+	}
+
+	rules := map[string]bool{}
+	for _, d := range diagnostics {
+		if !rules[d.Rule] {
+			rules[d.Rule] = true
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: d.Rule})
+		}
+
+		run.Results = append(run.Results, sarifResult{
+			RuleID:  d.Rule,
+			Level:   d.Severity,
+			Message: sarifMessage{Text: d.Message},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(d.File)},
+					Region:           sarifRegion{StartLine: d.Line, StartColumn: d.Column},
+				},
+			}},
+		})
+	}
+
+	return sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs:    []sarifRun{run},
+	}
+}
+
+// writeSARIF writes diagnostics to path as a SARIF document.
+func writeSARIF(path string, diagnostics []Diagnostic) error {
+	raw, err := json.MarshalIndent(newSARIF(diagnostics), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, raw, 0o644)
+}
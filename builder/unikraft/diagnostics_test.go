@@ -0,0 +1,84 @@
+package unikraft
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	c := &diagnosticCollector{}
+	output := "  CC      libnginx/src/core/nginx.o\n" +
+		"src/core/nginx.c:42:7: warning: unused variable 'x' [-Wunused-variable]\n" +
+		"src/event/ngx_event.c:10:1: error: expected ';' before '}' token\n" +
+		"make[1]: *** [Makefile:12: all] Error 2\n"
+
+	// Write in two chunks to cover lines split across writes.
+	fmt.Fprint(c, output[:60])
+	fmt.Fprint(c, output[60:])
+
+	path := filepath.Join(t.TempDir(), "build.sarif")
+	if err := writeSARIF(path, c.Diagnostics()); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region struct {
+							StartLine   int `json:"startLine"`
+							StartColumn int `json:"startColumn"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if doc.Version != "2.1.0" || len(doc.Runs) != 1 {
+		t.Fatalf("unexpected SARIF document: %s", raw)
+	}
+
+	run := doc.Runs[0]
+	if len(run.Tool.Driver.Rules) != 2 || run.Tool.Driver.Rules[0].ID != "unused-variable" || run.Tool.Driver.Rules[1].ID != "compiler-error" {
+		t.Errorf("unexpected rules: %+v", run.Tool.Driver.Rules)
+	}
+	if len(run.Results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(run.Results))
+	}
+
+	warning := run.Results[0]
+	loc := warning.Locations[0].PhysicalLocation
+	if warning.RuleID != "unused-variable" || warning.Level != "warning" ||
+		loc.ArtifactLocation.URI != "src/core/nginx.c" || loc.Region.StartLine != 42 || loc.Region.StartColumn != 7 {
+		t.Errorf("unexpected warning result: %+v", warning)
+	}
+	if run.Results[1].Level != "error" {
+		t.Errorf("unexpected error result: %+v", run.Results[1])
+	}
+}
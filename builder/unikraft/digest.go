@@ -0,0 +1,58 @@
+package unikraft
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// fileDigest returns the SHA256 digest of the file at path in the
+// `sha256:<hex>` form.
+func fileDigest(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// checkDigest checks that expected is a SHA256 digest, with or without the
+// `sha256:` prefix.
+func checkDigest(expected string) error {
+	sum := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(expected)), "sha256:")
+	if _, err := hex.DecodeString(sum); err != nil || len(sum) != 2*sha256.Size {
+		return fmt.Errorf("invalid digest %q, expected sha256:<64 hex digits>", expected)
+	}
+
+	return nil
+}
+
+// verifyDigest checks that the file at path matches the expected digest,
+// which may be given with or without the `sha256:` prefix.
+func verifyDigest(path, expected string) error {
+	actual, err := fileDigest(path)
+	if err != nil {
+		return fmt.Errorf("could not compute digest of %s: %w", path, err)
+	}
+
+	want := strings.ToLower(strings.TrimSpace(expected))
+	if !strings.HasPrefix(want, "sha256:") {
+		want = "sha256:" + want
+	}
+
+	if actual != want {
+		return fmt.Errorf("digest mismatch for %s: expected %s, got %s", path, want, actual)
+	}
+
+	return nil
+}
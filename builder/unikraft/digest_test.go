@@ -0,0 +1,48 @@
+package unikraft
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVerifyDigest(t *testing.T) {
+	kernel := filepath.Join(t.TempDir(), "helloworld_qemu-x86_64")
+	if err := os.WriteFile(kernel, []byte("hello"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	const sum = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+	if err := verifyDigest(kernel, "sha256:"+sum); err != nil {
+		t.Errorf("expected matching digest to pass: %v", err)
+	}
+	if err := verifyDigest(kernel, strings.ToUpper(sum)); err != nil {
+		t.Errorf("expected bare digest to pass: %v", err)
+	}
+
+	err := verifyDigest(kernel, "sha256:0000")
+	if err == nil {
+		t.Fatalf("expected mismatching digest to fail")
+	}
+	if !strings.Contains(err.Error(), "sha256:0000") || !strings.Contains(err.Error(), sum) {
+		t.Errorf("expected error to print both digests, got: %v", err)
+	}
+}
+
+func TestCheckDigest(t *testing.T) {
+	const sum = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+	for _, digest := range []string{"sha256:" + sum, strings.ToUpper(sum)} {
+		if err := checkDigest(digest); err != nil {
+			t.Errorf("expected %s to be valid: %v", digest, err)
+		}
+	}
+
+	for _, digest := range []string{"", "sha256:0000", "sha512:" + sum, "sha256:" + strings.Repeat("z", 64)} {
+		if err := checkDigest(digest); err == nil {
+			t.Errorf("expected %q to be invalid", digest)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package unikraft
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// FormatDisk is the package format writing a bootable disk image instead of
+// a package.
+const FormatDisk = "disk"
+
+// DiskFormats are the formats a disk image may be written in.
+var DiskFormats = []string{"raw", "qcow2", "vmdk"}
+
+// diskFormatArgs are the qemu-img options converting a raw disk image to each
+// format.  VMDK images are stream-optimized, as cloud image imports expect.
+var diskFormatArgs = map[string][]string{
+	"qcow2": {"-O", "qcow2"},
+	"vmdk":  {"-O", "vmdk", "-o", "subformat=streamOptimized"},
+}
+
+// DiskImage is a bootable disk image wrapping a multiboot kernel with GRUB,
+// which boots with BIOS as well as UEFI when GRUB's EFI modules are
+// installed.
+type DiskImage struct {
+	Kernel  string
+	Initrd  string
+	Cmdline string
+	// Format is the format of the image, raw when empty.
+	Format string
+	// SizeMiB is the size of the image.  The image is as small as GRUB and
+	// the kernel allow when not positive.
+	SizeMiB int
+
+	// run overrides how the external tools are run.
+	run func(ctx context.Context, name string, args ...string) error
+}
+
+// Write writes the image to output, using grub-mkrescue to make it bootable
+// and qemu-img to convert it.
+func (d DiskImage) Write(ctx context.Context, output string) error {
+	format := d.Format
+	if format == "" {
+		format = "raw"
+	}
+	if format != "raw" && diskFormatArgs[format] == nil {
+		return fmt.Errorf("unsupported disk format %q, expected %s", format, strings.Join(DiskFormats, ", "))
+	}
+
+	run := d.run
+	if run == nil {
+		run = runTool
+	}
+
+	staging, err := os.MkdirTemp("", "packer-unikraft-disk-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(staging)
+
+	root := filepath.Join(staging, "root")
+	if err := os.MkdirAll(filepath.Join(root, "boot", "grub"), 0o755); err != nil {
+		return err
+	}
+	if err := copyFile(d.Kernel, filepath.Join(root, "boot", "kernel")); err != nil {
+		return fmt.Errorf("could not stage kernel: %w", err)
+	}
+	if d.Initrd != "" {
+		if err := copyFile(d.Initrd, filepath.Join(root, "boot", "initrd")); err != nil {
+			return fmt.Errorf("could not stage initrd: %w", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "boot", "grub", "grub.cfg"), []byte(d.grubConfig()), 0o644); err != nil {
+		return err
+	}
+
+	raw := filepath.Join(staging, "disk.raw")
+	if err := run(ctx, "grub-mkrescue", "-o", raw, root); err != nil {
+		return err
+	}
+
+	if d.SizeMiB > 0 {
+		info, err := os.Stat(raw)
+		if err != nil {
+			return err
+		}
+
+		size := int64(d.SizeMiB) << 20
+		if info.Size() > size {
+			return fmt.Errorf("disk image needs %d MiB, more than disk_size of %d MiB", (info.Size()+1<<20-1)>>20, d.SizeMiB)
+		}
+		if err := os.Truncate(raw, size); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(output), 0o755); err != nil {
+		return err
+	}
+
+	if format == "raw" {
+		return copyFile(raw, output)
+	}
+
+	args := append([]string{"convert", "-f", "raw"}, diskFormatArgs[format]...)
+	return run(ctx, "qemu-img", append(args, raw, output)...)
+}
+
+// grubConfig returns the GRUB configuration booting the kernel right away.
+func (d DiskImage) grubConfig() string {
+	var b strings.Builder
+	b.WriteString("set timeout=0\nset default=0\n\n")
+	b.WriteString("menuentry \"unikraft\" {\n")
+	fmt.Fprintf(&b, "\tmultiboot /boot/kernel %s\n", d.Cmdline)
+	if d.Initrd != "" {
+		b.WriteString("\tmodule /boot/initrd\n")
+	}
+	b.WriteString("\tboot\n}\n")
+
+	return b.String()
+}
+
+// runTool runs an external tool, returning its output on failure.
+func runTool(ctx context.Context, name string, args ...string) error {
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if err != nil {
+		if output := strings.TrimSpace(string(out)); output != "" {
+			return fmt.Errorf("%s failed: %w: %s", name, err, output)
+		}
+		return fmt.Errorf("%s failed: %w", name, err)
+	}
+
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
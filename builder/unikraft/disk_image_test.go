@@ -0,0 +1,128 @@
+package unikraft
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeDiskTools stands in for grub-mkrescue, writing a 1 MiB image holding
+// the GRUB configuration it was given, and qemu-img, copying its input.  The
+// qemu-img arguments are recorded.
+func fakeDiskTools(t *testing.T, converted *[]string) func(ctx context.Context, name string, args ...string) error {
+	t.Helper()
+
+	return func(_ context.Context, name string, args ...string) error {
+		switch name {
+		case "grub-mkrescue":
+			cfg, err := os.ReadFile(filepath.Join(args[2], "boot", "grub", "grub.cfg"))
+			if err != nil {
+				return err
+			}
+			image := make([]byte, 1<<20)
+			copy(image, cfg)
+			return os.WriteFile(args[1], image, 0o644)
+		case "qemu-img":
+			*converted = args
+			return copyFile(args[len(args)-2], args[len(args)-1])
+		}
+		t.Fatalf("unexpected tool %s", name)
+		return nil
+	}
+}
+
+func TestDiskImageWrite(t *testing.T) {
+	dir := t.TempDir()
+	kernel := filepath.Join(dir, "nginx_qemu-x86_64")
+	initrd := filepath.Join(dir, "initramfs.cpio")
+	for _, f := range []string{kernel, initrd} {
+		if err := os.WriteFile(f, []byte("data"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var converted []string
+	output := filepath.Join(dir, "out", "nginx.vmdk")
+	err := DiskImage{
+		Kernel:  kernel,
+		Initrd:  initrd,
+		Cmdline: "-c /nginx/conf/nginx.conf",
+		Format:  "vmdk",
+		SizeMiB: 8,
+		run:     fakeDiskTools(t, &converted),
+	}.Write(context.Background(), output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() != 8<<20 {
+		t.Errorf("size = %d, want %d", info.Size(), 8<<20)
+	}
+
+	want := []string{"convert", "-f", "raw", "-O", "vmdk", "-o", "subformat=streamOptimized"}
+	if len(converted) < len(want) || !reflect.DeepEqual(converted[:len(want)], want) {
+		t.Errorf("qemu-img arguments = %q", converted)
+	}
+
+	raw, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(raw), "multiboot /boot/kernel -c /nginx/conf/nginx.conf\n\tmodule /boot/initrd\n") {
+		t.Errorf("unexpected GRUB configuration in %q", raw[:200])
+	}
+}
+
+func TestDiskImageWriteRaw(t *testing.T) {
+	dir := t.TempDir()
+	kernel := filepath.Join(dir, "helloworld_qemu-x86_64")
+	if err := os.WriteFile(kernel, []byte("data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var converted []string
+	output := filepath.Join(dir, "helloworld.img")
+	if err := (DiskImage{Kernel: kernel, run: fakeDiskTools(t, &converted)}).Write(context.Background(), output); err != nil {
+		t.Fatal(err)
+	}
+
+	if converted != nil {
+		t.Errorf("expected no conversion, got %q", converted)
+	}
+	if info, err := os.Stat(output); err != nil || info.Size() != 1<<20 {
+		t.Errorf("expected the image as written by grub-mkrescue, got %v, %v", info, err)
+	}
+}
+
+func TestDiskImageWriteErrors(t *testing.T) {
+	dir := t.TempDir()
+	kernel := filepath.Join(dir, "helloworld_qemu-x86_64")
+	if err := os.WriteFile(kernel, []byte("data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var converted []string
+	tools := fakeDiskTools(t, &converted)
+
+	err := DiskImage{Kernel: kernel, Format: "vhd", run: tools}.Write(context.Background(), filepath.Join(dir, "out"))
+	if err == nil || !strings.Contains(err.Error(), "unsupported disk format") {
+		t.Errorf("expected an unsupported format error, got %v", err)
+	}
+
+	err = DiskImage{Kernel: kernel, SizeMiB: 0, run: tools}.Write(context.Background(), filepath.Join(dir, "out"))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	err = DiskImage{Kernel: filepath.Join(dir, "missing"), run: tools}.Write(context.Background(), filepath.Join(dir, "out"))
+	if err == nil || !strings.Contains(err.Error(), "could not stage kernel") {
+		t.Errorf("expected a missing kernel error, got %v", err)
+	}
+}
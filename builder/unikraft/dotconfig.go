@@ -0,0 +1,142 @@
+package unikraft
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// architectureSymbols are the KConfig symbols selected by a .config built for
+// a Unikraft architecture.
+var architectureSymbols = map[string][]string{
+	"x86_64": {"CONFIG_ARCH_X86_64"},
+	"arm64":  {"CONFIG_ARCH_ARM_64"},
+	"arm":    {"CONFIG_ARCH_ARM_32"},
+}
+
+// platformSymbols are the KConfig symbols selected by a .config built for a
+// Unikraft platform.
+var platformSymbols = map[string][]string{
+	"qemu":   {"CONFIG_PLAT_KVM"},
+	"kvm":    {"CONFIG_PLAT_KVM"},
+	"fc":     {"CONFIG_PLAT_KVM", "CONFIG_KVM_VMM_FIRECRACKER"},
+	"xen":    {"CONFIG_PLAT_XEN"},
+	"linuxu": {"CONFIG_PLAT_LINUXU"},
+}
+
+// defaultKraftfiles are the names of the project files looked up in a workdir
+// when no explicit Kraftfile is given.
+var defaultKraftfiles = []string{"Kraftfile", "kraft.yaml", "kraft.yml"}
+
+// readDotConfig parses the symbols set in a .config file.  Symbols which are
+// explicitly not set are recorded with the value `n`.
+func readDotConfig(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	symbols := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if strings.HasPrefix(line, "# CONFIG_") && strings.HasSuffix(line, " is not set") {
+			symbols[strings.TrimSuffix(strings.TrimPrefix(line, "# "), " is not set")] = "n"
+			continue
+		}
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		symbols[k] = strings.Trim(v, `"`)
+	}
+
+	return symbols, scanner.Err()
+}
+
+// dotConfigCompatible reports whether the .config at path can be reused to
+// build for a target instead of reconfiguring it.  A .config is compatible
+// when:
+//
+//   - it is not older than the Kraftfile, when one is given;
+//   - it selects the symbols of the target architecture and platform;
+//   - every symbol set by the target has the same value.
+//
+// When the .config is not compatible, the reason is returned.
+func dotConfigCompatible(path, kraftfile, architecture, platform string, symbols map[string]string) (bool, string) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return false, fmt.Sprintf("no existing configuration at %s", path)
+	}
+
+	if kraftfile != "" {
+		if kstat, err := os.Stat(kraftfile); err == nil && kstat.ModTime().After(stat.ModTime()) {
+			return false, fmt.Sprintf("%s changed since %s was written", filepath.Base(kraftfile), filepath.Base(path))
+		}
+	}
+
+	current, err := readDotConfig(path)
+	if err != nil {
+		return false, fmt.Sprintf("could not read %s: %v", path, err)
+	}
+
+	archSymbols, ok := architectureSymbols[architecture]
+	if !ok {
+		return false, fmt.Sprintf("unknown architecture %s", architecture)
+	}
+	platSymbols, ok := platformSymbols[platform]
+	if !ok {
+		return false, fmt.Sprintf("unknown platform %s", platform)
+	}
+
+	for _, sym := range append(archSymbols, platSymbols...) {
+		if current[sym] != "y" {
+			return false, fmt.Sprintf("%s is not set", sym)
+		}
+	}
+
+	for k, v := range symbols {
+		if !strings.HasPrefix(k, "CONFIG_") {
+			k = "CONFIG_" + k
+		}
+
+		got, ok := current[k]
+		if !ok && v == "n" {
+			continue
+		}
+		if got != strings.Trim(v, `"`) {
+			return false, fmt.Sprintf("%s is %q, want %q", k, got, v)
+		}
+	}
+
+	return true, ""
+}
+
+// findKraftfile returns the path of the Kraftfile of the project in workdir,
+// or an empty string when none is found.
+func findKraftfile(workdir, kraftfile string) string {
+	if kraftfile != "" {
+		if filepath.IsAbs(kraftfile) {
+			return kraftfile
+		}
+		return filepath.Join(workdir, kraftfile)
+	}
+
+	for _, name := range defaultKraftfiles {
+		path := filepath.Join(workdir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,79 @@
+package unikraft
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testDotConfig = `#
+# Unikraft configuration
+#
+CONFIG_ARCH_X86_64=y
+# CONFIG_ARCH_ARM_64 is not set
+CONFIG_PLAT_KVM=y
+CONFIG_LIBPOSIX_PROCESS=y
+CONFIG_LIBVFSCORE_ROOTFS="initrd"
+# CONFIG_LIBUKDEBUG_ANSI_COLOR is not set
+`
+
+func TestDotConfigCompatible(t *testing.T) {
+	dir := t.TempDir()
+	kraftfile := filepath.Join(dir, "Kraftfile")
+	dotconfig := filepath.Join(dir, ".config.nginx_qemu-x86_64")
+
+	if err := os.WriteFile(kraftfile, []byte("spec: v0.6\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dotconfig, []byte(testDotConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(kraftfile, past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	symbols := map[string]string{
+		"CONFIG_LIBPOSIX_PROCESS":      "y",
+		"LIBVFSCORE_ROOTFS":            `"initrd"`,
+		"CONFIG_LIBUKDEBUG_ANSI_COLOR": "n",
+	}
+
+	if ok, reason := dotConfigCompatible(dotconfig, kraftfile, "x86_64", "qemu", symbols); !ok {
+		t.Errorf("expected matching configuration to be reused: %s", reason)
+	}
+
+	tests := []struct {
+		name     string
+		arch     string
+		plat     string
+		symbols  map[string]string
+		contains string
+	}{
+		{"architecture", "arm64", "qemu", nil, "CONFIG_ARCH_ARM_64"},
+		{"platform", "x86_64", "xen", nil, "CONFIG_PLAT_XEN"},
+		{"symbol", "x86_64", "qemu", map[string]string{"CONFIG_LIBPOSIX_PROCESS": "n"}, "CONFIG_LIBPOSIX_PROCESS"},
+		{"missing symbol", "x86_64", "qemu", map[string]string{"CONFIG_LIBUKNETDEV": "y"}, "CONFIG_LIBUKNETDEV"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, reason := dotConfigCompatible(dotconfig, kraftfile, tt.arch, tt.plat, tt.symbols)
+			if ok {
+				t.Fatalf("expected mismatching configuration to be rejected")
+			}
+			if !strings.Contains(reason, tt.contains) {
+				t.Errorf("expected reason to mention %s, got %q", tt.contains, reason)
+			}
+		})
+	}
+
+	if err := os.Chtimes(kraftfile, time.Now().Add(time.Hour), time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := dotConfigCompatible(dotconfig, kraftfile, "x86_64", "qemu", symbols); ok {
+		t.Errorf("expected configuration older than the Kraftfile to be stale")
+	}
+}
@@ -0,0 +1,181 @@
+package unikraft
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// proxySchemes are the schemes of the proxies downloads may be made through.
+var proxySchemes = []string{"http", "https", "socks5"}
+
+// ProxyConfig are the proxies the downloads of components are made through.
+type ProxyConfig struct {
+	// HTTP is the proxy of the plain HTTP downloads.
+	HTTP string
+	// HTTPS is the proxy of the HTTPS downloads.  Defaults to HTTP.
+	HTTPS string
+	// NoProxy is a comma-separated list of hosts and domains downloaded from
+	// directly, or `*` for all of them.
+	NoProxy string
+}
+
+// IsZero returns whether no proxy is set.
+func (p ProxyConfig) IsZero() bool {
+	return p.HTTP == "" && p.HTTPS == ""
+}
+
+// Validate checks that the proxies are absolute URLs of a known scheme.
+func (p ProxyConfig) Validate() error {
+	for name, proxy := range map[string]string{"http_proxy": p.HTTP, "https_proxy": p.HTTPS} {
+		if proxy == "" {
+			continue
+		}
+
+		u, err := url.Parse(proxy)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+
+		known := false
+		for _, scheme := range proxySchemes {
+			known = known || u.Scheme == scheme
+		}
+		if !known || u.Host == "" {
+			return fmt.Errorf("invalid %s %q: expected a %s URL", name, proxy, strings.Join(proxySchemes, ", "))
+		}
+	}
+
+	return nil
+}
+
+// proxy returns the proxy req is made through, or nil when it is made
+// directly.
+func (p ProxyConfig) proxy(req *http.Request) (*url.URL, error) {
+	if p.bypass(req.URL.Hostname()) {
+		return nil, nil
+	}
+
+	proxy := p.HTTP
+	if req.URL.Scheme == "https" && p.HTTPS != "" {
+		proxy = p.HTTPS
+	}
+	if proxy == "" {
+		return nil, nil
+	}
+
+	return url.Parse(proxy)
+}
+
+// bypass returns whether host is downloaded from directly.
+func (p ProxyConfig) bypass(host string) bool {
+	for _, entry := range strings.Split(p.NoProxy, ",") {
+		entry = strings.TrimPrefix(strings.TrimSpace(entry), ".")
+		switch {
+		case entry == "":
+		case entry == "*":
+			return true
+		case strings.EqualFold(host, entry), strings.HasSuffix(strings.ToLower(host), "."+strings.ToLower(entry)):
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidateMirrors checks that every upstream and mirror of mirrors is an
+// absolute HTTP(S) URL.
+func ValidateMirrors(mirrors map[string]string) error {
+	for upstream, mirror := range mirrors {
+		for _, raw := range []string{upstream, mirror} {
+			u, err := url.Parse(raw)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("invalid component mirror %q: expected an http or https URL", raw)
+			}
+		}
+	}
+
+	return nil
+}
+
+// mirrorURL returns u with the longest upstream of mirrors it starts with
+// replaced by its mirror, and whether it has one.
+func mirrorURL(mirrors map[string]string, u *url.URL) (*url.URL, bool) {
+	upstreams := make([]string, 0, len(mirrors))
+	for upstream := range mirrors {
+		upstreams = append(upstreams, upstream)
+	}
+	sort.Slice(upstreams, func(i, j int) bool {
+		return len(upstreams[i]) > len(upstreams[j])
+	})
+
+	raw := u.String()
+	for _, upstream := range upstreams {
+		rest, ok := hasURLPrefix(raw, upstream)
+		if !ok {
+			continue
+		}
+
+		mirrored, err := url.Parse(strings.TrimSuffix(mirrors[upstream], "/") + rest)
+		if err != nil {
+			continue
+		}
+
+		return mirrored, true
+	}
+
+	return u, false
+}
+
+// routedTransport sends the requests of base to the mirrors of their URL.
+type routedTransport struct {
+	base    http.RoundTripper
+	mirrors map[string]string
+}
+
+func (t *routedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	mirrored, ok := mirrorURL(t.mirrors, req.URL)
+	if !ok {
+		return t.base.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	req.URL = mirrored
+	req.Host = ""
+
+	return t.base.RoundTrip(req)
+}
+
+// newDownloadTransport returns the transport the downloads of a pull are made
+// with: a copy of the default transport sending them through proxy, to the
+// mirrors of their URL, with the credentials of auths of the host they are
+// sent to, no faster than limiter allows, and counting them with the counter
+// of their context.  It is handed to the package managers rather than
+// replacing the default transport, so that concurrent builds download with
+// settings of their own and only count their own downloads.
+func newDownloadTransport(mirrors map[string]string, proxy ProxyConfig, auths []SourceAuth, limiter *RateLimiter) http.RoundTripper {
+	base := &http.Transport{Proxy: http.ProxyFromEnvironment}
+	if t, ok := http.DefaultTransport.(*http.Transport); ok {
+		base = t.Clone()
+	}
+	if !proxy.IsZero() {
+		base.Proxy = proxy.proxy
+	}
+
+	// Downloads are routed before they are authenticated, so that they are
+	// matched against the URL of the mirror they are sent to.
+	var t http.RoundTripper = base
+	if len(auths) > 0 {
+		t = &authTransport{base: t, auths: auths}
+	}
+	if len(mirrors) > 0 {
+		t = &routedTransport{base: t, mirrors: mirrors}
+	}
+	if limiter != nil {
+		t = &throttledTransport{base: t, limiter: limiter}
+	}
+
+	return &countingTransport{base: t}
+}
@@ -0,0 +1,133 @@
+package unikraft
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestDownloadTransportToMirror(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "upstream")
+	}))
+	defer upstream.Close()
+
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "mirror"+r.URL.Path)
+	}))
+	defer mirror.Close()
+
+	client := &http.Client{
+		Transport: newDownloadTransport(map[string]string{upstream.URL + "/unikraft": mirror.URL + "/cache"}, ProxyConfig{}, nil, nil),
+	}
+
+	get := func(client *http.Client, u string) string {
+		res, err := client.Get(u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+
+		body, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(body)
+	}
+
+	if got := get(client, upstream.URL+"/unikraft/lib-musl.tar.gz"); got != "mirror/cache/lib-musl.tar.gz" {
+		t.Errorf("mirrored download = %q", got)
+	}
+	if got := get(client, upstream.URL+"/unikraft-extra/index.yaml"); got != "upstream" {
+		t.Errorf("expected a download outside of the mirrored path from upstream, got %q", got)
+	}
+	if got := get(http.DefaultClient, upstream.URL+"/unikraft/lib-musl.tar.gz"); got != "upstream" {
+		t.Errorf("expected the default client to be left alone, got %q", got)
+	}
+}
+
+func TestMirrorURLPrefersLongestUpstream(t *testing.T) {
+	mirrors := map[string]string{
+		"https://github.com":               "https://mirror.example.com/github",
+		"https://github.com/unikraft/":     "https://mirror.example.com/unikraft/",
+		"https://manifests.kraftkit.sh/v1": "http://10.0.0.1/manifests",
+	}
+
+	tests := map[string]string{
+		"https://github.com/unikraft/lib-musl/archive/v0.16.tar.gz": "https://mirror.example.com/unikraft/lib-musl/archive/v0.16.tar.gz",
+		"https://github.com/other/repo.git":                         "https://mirror.example.com/github/other/repo.git",
+		"https://manifests.kraftkit.sh/v1?index=1":                  "http://10.0.0.1/manifests?index=1",
+		"https://unikraft.org/index.yaml":                           "https://unikraft.org/index.yaml",
+	}
+
+	for raw, want := range tests {
+		u, _ := url.Parse(raw)
+		if got, _ := mirrorURL(mirrors, u); got.String() != want {
+			t.Errorf("mirrorURL(%s) = %s, want %s", raw, got, want)
+		}
+	}
+}
+
+func TestProxyConfig(t *testing.T) {
+	p := ProxyConfig{HTTP: "http://proxy:3128", NoProxy: "localhost, .internal.example.com"}
+
+	tests := map[string]string{
+		"http://github.com/unikraft":            "http://proxy:3128",
+		"https://github.com/unikraft":           "http://proxy:3128",
+		"https://registry.internal.example.com": "",
+		"http://localhost:8080/index.yaml":      "",
+	}
+	for raw, want := range tests {
+		req, _ := http.NewRequest(http.MethodGet, raw, nil)
+		proxy, err := p.proxy(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := ""
+		if proxy != nil {
+			got = proxy.String()
+		}
+		if got != want {
+			t.Errorf("proxy of %s = %q, want %q", raw, got, want)
+		}
+	}
+
+	p.HTTPS = "socks5://proxy:1080"
+	req, _ := http.NewRequest(http.MethodGet, "https://github.com", nil)
+	if proxy, _ := p.proxy(req); proxy == nil || proxy.String() != "socks5://proxy:1080" {
+		t.Errorf("expected https downloads through https_proxy, got %v", proxy)
+	}
+
+	if err := (ProxyConfig{HTTP: "ftp://proxy"}).Validate(); err == nil {
+		t.Error("expected an error for an ftp proxy")
+	}
+	if err := ValidateMirrors(map[string]string{"github.com": "https://mirror"}); err == nil {
+		t.Error("expected an error for a mirror of a URL without scheme")
+	}
+}
+
+func TestDownloadTransportThroughProxy(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "proxied "+r.URL.String())
+	}))
+	defer proxy.Close()
+
+	client := &http.Client{Transport: newDownloadTransport(nil, ProxyConfig{HTTP: proxy.URL, NoProxy: "127.0.0.1"}, nil, nil)}
+
+	res, err := client.Get("http://manifests.example.com/index.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(body), "proxied http://manifests.example.com/index.yaml"; got != want {
+		t.Errorf("download = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,146 @@
+package unikraft
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ComponentDownload is the number of bytes downloaded to pull a component.
+type ComponentDownload struct {
+	Component string
+	Bytes     int64
+}
+
+// DownloadSizes collects the bytes downloaded per component.
+type DownloadSizes struct {
+	mu    sync.Mutex
+	sizes map[string]int64
+}
+
+// Add records n more bytes downloaded for component.
+func (d *DownloadSizes) Add(component string, n int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.sizes == nil {
+		d.sizes = map[string]int64{}
+	}
+	d.sizes[component] += n
+}
+
+// Bytes returns the number of bytes downloaded for component.
+func (d *DownloadSizes) Bytes(component string) int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.sizes[component]
+}
+
+// Summary returns the downloads from the heaviest to the lightest.
+func (d *DownloadSizes) Summary() []ComponentDownload {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	summary := make([]ComponentDownload, 0, len(d.sizes))
+	for component, n := range d.sizes {
+		summary = append(summary, ComponentDownload{Component: component, Bytes: n})
+	}
+
+	sort.Slice(summary, func(i, j int) bool {
+		if summary[i].Bytes != summary[j].Bytes {
+			return summary[i].Bytes > summary[j].Bytes
+		}
+		return summary[i].Component < summary[j].Component
+	})
+
+	return summary
+}
+
+// Text renders the summary as one line per component.
+func (d *DownloadSizes) Text() string {
+	var b strings.Builder
+	var total int64
+
+	for _, c := range d.Summary() {
+		fmt.Fprintf(&b, "%-24s %s\n", c.Component, formatBytes(c.Bytes))
+		total += c.Bytes
+	}
+	fmt.Fprintf(&b, "%-24s %s\n", "total", formatBytes(total))
+
+	return b.String()
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+type downloadKey struct{}
+
+type downloadCounter struct {
+	sizes     *DownloadSizes
+	component string
+}
+
+// withDownloadCounter attributes the downloads made with ctx through a
+// countingTransport to component.
+func withDownloadCounter(ctx context.Context, sizes *DownloadSizes, component string) context.Context {
+	if sizes == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, downloadKey{}, downloadCounter{sizes: sizes, component: component})
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r       io.ReadCloser
+	counter downloadCounter
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	if n > 0 {
+		c.counter.sizes.Add(c.counter.component, int64(n))
+	}
+
+	return n, err
+}
+
+func (c *countingReader) Close() error {
+	return c.r.Close()
+}
+
+// countingTransport counts the bodies of the responses of base made with a
+// context from withDownloadCounter.
+type countingTransport struct {
+	base http.RoundTripper
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.base.RoundTrip(req)
+	if err != nil || res.Body == nil {
+		return res, err
+	}
+
+	if counter, ok := req.Context().Value(downloadKey{}).(downloadCounter); ok {
+		res.Body = &countingReader{r: res.Body, counter: counter}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,83 @@
+package unikraft
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestDownloadSizes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/"))
+		w.Write(make([]byte, n))
+	}))
+	defer srv.Close()
+
+	sizes := &DownloadSizes{}
+	client := &http.Client{Transport: newDownloadTransport(nil, ProxyConfig{}, nil, nil)}
+
+	get := func(ctx context.Context, client *http.Client, n int) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/"+strconv.Itoa(n), nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		res, err := client.Do(req)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer res.Body.Close()
+		io.Copy(io.Discard, res.Body)
+	}
+
+	transfers := map[string][]int{
+		"musl":     {300000, 200000},
+		"unikraft": {2 << 20},
+		"lwip":     {500000},
+	}
+
+	var wg sync.WaitGroup
+	for component, ns := range transfers {
+		for _, n := range ns {
+			wg.Add(1)
+			go func(component string, n int) {
+				defer wg.Done()
+				get(withDownloadCounter(context.Background(), sizes, component), client, n)
+			}(component, n)
+		}
+	}
+	wg.Wait()
+
+	// Downloads outside of a pull, or not made through its transport, such
+	// as those of another build, are not counted.
+	get(context.Background(), client, 1000)
+	get(withDownloadCounter(context.Background(), sizes, "musl"), http.DefaultClient, 1000)
+
+	want := []ComponentDownload{
+		{Component: "unikraft", Bytes: 2 << 20},
+		{Component: "lwip", Bytes: 500000},
+		{Component: "musl", Bytes: 500000},
+	}
+	got := sizes.Summary()
+	if len(got) != len(want) {
+		t.Fatalf("expected %d components, got %v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("summary[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	text := sizes.Text()
+	for _, line := range []string{"unikraft", "2.0 MiB", "488.3 KiB", "total"} {
+		if !strings.Contains(text, line) {
+			t.Errorf("summary text missing %q:\n%s", line, text)
+		}
+	}
+}
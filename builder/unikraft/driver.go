@@ -1,16 +1,18 @@
 package unikraft
 
+import "time"
+
 // Driver is the interface that has to be implemented to communicate with
 // Kraft. The Driver interface also allows the steps to be tested since
 // a mock driver can be shimmed in.
 type Driver interface {
 	Build(path, architecture, platform, target string) error
 
-	Pkg(architecture, platform, target, pkgName, rootfs, workdir string, push bool) error
+	Pkg(architecture, platform, target, pkgName, workdir, rootfs string, push bool) error
 
 	Clean(path string) error
 
-	Pull(source, workdir string) error
+	Pull(sources []string, workdir string, opts PullOptions) error
 
 	Set(options map[string]string) error
 
@@ -18,5 +20,76 @@ type Driver interface {
 
 	Unsource(source string) error
 
-	Update() error
+	// Update refreshes the package index of manager, `manifest`, `oci` or
+	// `all`.
+	Update(manager string) error
+}
+
+// PullOptions control how components are retrieved by Driver.Pull.
+type PullOptions struct {
+	// Manager is the package manager to pull with, `auto` when empty.
+	Manager string
+	// ChecksumPolicy is how the checksums of the pulled components are
+	// verified, one of ChecksumPolicies.  Defaults to ChecksumEnforce.
+	ChecksumPolicy string
+	// ForceCache resolves the components from the local cache only.
+	ForceCache bool
+	// Resolution picks the package of the template when the catalog returns
+	// several.
+	Resolution CandidatePolicy
+	// Mirrors maps the upstream URLs components are downloaded from to the
+	// mirrors they are downloaded from instead.
+	Mirrors map[string]string
+	// Proxy are the proxies components are downloaded through.
+	Proxy ProxyConfig
+	// Auth are the credentials of the private sources components are
+	// pulled from.
+	Auth []SourceAuth
+	// Concurrency is the maximum number of components pulled at the same
+	// time.
+	Concurrency int
+	// RateLimit caps the bandwidth of the downloads, in bytes per second.
+	// It is unlimited when not positive.
+	RateLimit int64
+	// Retries is the number of times a catalog query or pull failing with a
+	// transient error is retried.
+	Retries int
+	// RetryBackoff is the delay before the first retry.
+	RetryBackoff time.Duration
+}
+
+// BuildIdentifier is implemented by drivers which can tell the identifier of
+// the last build, derived from its resolved inputs.
+type BuildIdentifier interface {
+	BuildID() string
+}
+
+// BuildReporter is implemented by drivers which can tell the outcome of every
+// target of the last build, down to the duration of its phases.
+type BuildReporter interface {
+	BuildResults() []TargetResult
+}
+
+// KConfigBuilder is implemented by drivers which can merge KConfig symbols
+// into the configuration of a target before building it.
+type KConfigBuilder interface {
+	BuildWithKConfig(path, architecture, platform, target string, kconfig map[string]string) error
+}
+
+// BootTester is implemented by drivers which can boot a built kernel to check
+// that it runs.
+type BootTester interface {
+	TestBoot(kernel, architecture string, check BootCheck) error
+}
+
+// RootfsBuilder is implemented by drivers which can construct an initramfs
+// from a directory or a Dockerfile.
+type RootfsBuilder interface {
+	BuildRootfs(source, output, workdir, buildkitHost string) error
+}
+
+// KernelPackager is implemented by drivers which can package a kernel built
+// outside of Packer, without a project to build it from.
+type KernelPackager interface {
+	PkgKernel(k ImportedKernel, pkgName, workdir string, push bool) error
 }
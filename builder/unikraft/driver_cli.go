@@ -0,0 +1,268 @@
+package unikraft
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultKraftBinary is the kraft executable run by the CLI driver when
+// kraft_binary is not set.
+const DefaultKraftBinary = "kraft"
+
+// KraftCLIDriver implements Driver by running the kraft executable, for hosts
+// where the behaviour of an installed kraft has to be matched.  Unlike
+// KraftDriver, it cannot identify builds nor construct an initramfs.
+type KraftCLIDriver struct {
+	// Binary is the kraft executable, DefaultKraftBinary when empty.
+	Binary string
+	// Output, when set, receives the output of every command as it runs.
+	Output io.Writer
+
+	CommandContext context.Context
+
+	// Kraftfile is the Kraftfile of the projects built and packaged,
+	// relative to their directory.
+	Kraftfile string
+	// Env are the variables added to the environment of make.
+	Env map[string]string
+	// CrossCompile is the toolchain prefix passed to make as CROSS_COMPILE.
+	CrossCompile string
+	// Jobs is the number of jobs make builds with, as many as allowed by
+	// NoFast when not positive.
+	Jobs int
+	// NoFast builds with a single job when Jobs is not set.
+	NoFast bool
+	// Cache, when set, is where kraft keeps its manifest index and the
+	// sources of pulled components.
+	Cache *CachePaths
+	// Container, when set, runs Binary in a container rather than on the
+	// host.
+	Container *Container
+}
+
+var _ Driver = (*KraftCLIDriver)(nil)
+
+func (d *KraftCLIDriver) Build(path, architecture, platform, target string) error {
+	args := []string{"build", "--no-cache", "--no-update"}
+	args = appendFlag(args, "--arch", architecture)
+	args = appendFlag(args, "--plat", platform)
+	args = appendFlag(args, "--target", target)
+	args = appendFlag(args, "--kraftfile", d.kraftfile(path))
+	if d.Jobs > 0 {
+		args = append(args, "--jobs", strconv.Itoa(d.Jobs))
+	} else if d.NoFast {
+		args = append(args, "--no-fast")
+	}
+
+	return d.runIn([]string{path}, append(args, path)...)
+}
+
+func (d *KraftCLIDriver) Pkg(architecture, platform, target, pkgName, workdir, rootfs string, push bool) error {
+	args := []string{"pkg", "--format", "oci", "--name", pkgName}
+	args = appendFlag(args, "--arch", architecture)
+	args = appendFlag(args, "--plat", platform)
+	args = appendFlag(args, "--target", target)
+	args = appendFlag(args, "--rootfs", rootfs)
+	args = appendFlag(args, "--kraftfile", d.kraftfile(workdir))
+	if push {
+		args = append(args, "--push")
+	}
+
+	return d.runIn([]string{workdir, rootfs}, append(args, workdir)...)
+}
+
+func (d *KraftCLIDriver) Clean(path string) error {
+	return d.runIn([]string{path}, "clean", path)
+}
+
+func (d *KraftCLIDriver) Pull(sources []string, workdir string, opts PullOptions) error {
+	args := []string{"pkg", "pull", "--workdir", workdir}
+	args = appendFlag(args, "--manager", opts.Manager)
+	if opts.ForceCache {
+		args = append(args, "--force-cache")
+	}
+
+	return pullVerified(opts.ChecksumPolicy, strings.Join(sources, ", "), d.warn, func(checksum bool) error {
+		pullArgs := append([]string{}, args...)
+		if !checksum {
+			pullArgs = append(pullArgs, "--no-checksum")
+		}
+
+		return d.runIn([]string{workdir}, append(pullArgs, sources...)...)
+	})
+}
+
+func (d *KraftCLIDriver) Set(options map[string]string) error {
+	args := []string{"set"}
+	for _, k := range sortedKeys(options) {
+		args = append(args, fmt.Sprintf("%s=%s", k, options[k]))
+	}
+
+	return d.run(args...)
+}
+
+func (d *KraftCLIDriver) Source(source string) error {
+	return d.run("pkg", "source", source)
+}
+
+func (d *KraftCLIDriver) Unsource(source string) error {
+	return d.run("pkg", "unsource", source)
+}
+
+func (d *KraftCLIDriver) Update(manager string) error {
+	return d.run("pkg", "update", "--manager", manager)
+}
+
+// kraftfile returns the path of the configured Kraftfile of the project in
+// workdir, or an empty string to use the default ones.
+func (d *KraftCLIDriver) kraftfile(workdir string) string {
+	if d.Kraftfile == "" {
+		return ""
+	}
+
+	return findKraftfile(workdir, d.Kraftfile)
+}
+
+// warn reports msg along with the output of kraft.
+func (d *KraftCLIDriver) warn(msg string) {
+	if d.Output == nil {
+		log.Print(msg)
+		return
+	}
+
+	fmt.Fprintln(d.Output, msg)
+}
+
+// run runs kraft with args.  Its output is included in the returned error
+// when it fails.
+func (d *KraftCLIDriver) run(args ...string) error {
+	return d.runIn(nil, args...)
+}
+
+// runIn runs kraft with args, mounting dirs when it runs in a container.
+func (d *KraftCLIDriver) runIn(dirs []string, args ...string) error {
+	ctx := d.CommandContext
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	binary := d.Binary
+	if binary == "" {
+		binary = DefaultKraftBinary
+	}
+
+	var out bytes.Buffer
+	var w io.Writer = &out
+	if d.Output != nil {
+		w = io.MultiWriter(&out, d.Output)
+		if f, ok := d.Output.(interface{ Flush() }); ok {
+			defer f.Flush()
+		}
+	}
+
+	env := makeEnv(d.Env, d.CrossCompile)
+	if d.Cache != nil {
+		if env == nil {
+			env = map[string]string{}
+		}
+		for k, v := range d.Cache.Env() {
+			env[k] = v
+		}
+	}
+
+	name, cmdArgs := binary, args
+	if d.Container != nil {
+		containerArgs, err := d.Container.Args(env, dirs, append([]string{binary}, args...)...)
+		if err != nil {
+			return err
+		}
+		name, cmdArgs = d.Container.Runtime, containerArgs
+		// The environment is passed to the container instead.
+		env = nil
+	}
+
+	cmd := exec.CommandContext(ctx, name, cmdArgs...)
+	inProcessGroup(cmd, killGracePeriod)
+	cmd.Stdout = w
+	cmd.Stderr = w
+	if env != nil {
+		cmd.Env = os.Environ()
+		for _, k := range sortedKeys(env) {
+			cmd.Env = append(cmd.Env, k+"="+env[k])
+		}
+	}
+
+	if err := cmd.Run(); err != nil {
+		if output := strings.TrimSpace(out.String()); output != "" {
+			return fmt.Errorf("%s %s failed: %w: %s", binary, args[0], err, output)
+		}
+		return fmt.Errorf("%s %s failed: %w", binary, args[0], err)
+	}
+
+	return nil
+}
+
+// appendFlag appends flag and its value to args, unless the value is empty.
+func appendFlag(args []string, flag, value string) []string {
+	if value == "" {
+		return args
+	}
+
+	return append(args, flag, value)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// LineWriter calls Emit with every complete line written to it, such that the
+// output of a command can be forwarded to a packer UI.
+type LineWriter struct {
+	Emit func(string)
+
+	mu      sync.Mutex
+	pending []byte
+}
+
+func (w *LineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.pending = append(w.pending, p...)
+	for {
+		i := bytes.IndexByte(w.pending, '\n')
+		if i < 0 {
+			break
+		}
+		w.Emit(strings.TrimRight(string(w.pending[:i]), "\r"))
+		w.pending = w.pending[i+1:]
+	}
+
+	return len(p), nil
+}
+
+// Flush emits the last line written, when it is not terminated.
+func (w *LineWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.pending) > 0 {
+		w.Emit(string(w.pending))
+		w.pending = nil
+	}
+}
@@ -0,0 +1,267 @@
+package unikraft
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// fakeKraft writes a kraft stand-in recording its arguments, one per line,
+// and the CROSS_COMPILE variable of its environment, then exiting with code.
+func fakeKraft(t *testing.T, code string) (binary, record string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	binary = filepath.Join(dir, "kraft")
+	record = filepath.Join(dir, "args")
+	script := "#!/bin/sh\n" +
+		"for arg in \"$@\"; do echo \"$arg\" >> " + record + "; done\n" +
+		"echo \"CROSS_COMPILE=$CROSS_COMPILE\" >> " + record + "\n" +
+		"echo building\n" +
+		"exit " + code + "\n"
+	if err := os.WriteFile(binary, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	return binary, record
+}
+
+func readArgs(t *testing.T, record string) []string {
+	t.Helper()
+
+	raw, err := os.ReadFile(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return strings.Split(strings.TrimSpace(string(raw)), "\n")
+}
+
+func TestKraftCLIDriverBuild(t *testing.T) {
+	binary, record := fakeKraft(t, "0")
+
+	var lines []string
+	output := &LineWriter{Emit: func(line string) { lines = append(lines, line) }}
+	d := &KraftCLIDriver{
+		Binary:       binary,
+		Output:       output,
+		Kraftfile:    "Kraftfile.prod",
+		CrossCompile: "aarch64-linux-gnu-",
+	}
+
+	if err := d.Build("/src/app", "arm64", "qemu", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"build", "--no-cache", "--no-update",
+		"--arch", "arm64",
+		"--plat", "qemu",
+		"--kraftfile", "/src/app/Kraftfile.prod",
+		"/src/app",
+		"CROSS_COMPILE=aarch64-linux-gnu-",
+	}
+	if got := readArgs(t, record); !reflect.DeepEqual(got, want) {
+		t.Errorf("args = %q, want %q", got, want)
+	}
+	if !reflect.DeepEqual(lines, []string{"building"}) {
+		t.Errorf("output = %q", lines)
+	}
+}
+
+func TestKraftCLIDriverBuildJobs(t *testing.T) {
+	tests := []struct {
+		name   string
+		jobs   int
+		noFast bool
+		want   []string
+	}{
+		{name: "default"},
+		{name: "jobs", jobs: 4, noFast: true, want: []string{"--jobs", "4"}},
+		{name: "no fast", noFast: true, want: []string{"--no-fast"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			binary, record := fakeKraft(t, "0")
+			d := &KraftCLIDriver{Binary: binary, Jobs: tt.jobs, NoFast: tt.noFast}
+
+			if err := d.Build("/src/app", "", "", ""); err != nil {
+				t.Fatal(err)
+			}
+
+			want := append([]string{"build", "--no-cache", "--no-update"}, tt.want...)
+			want = append(want, "/src/app", "CROSS_COMPILE=")
+			if got := readArgs(t, record); !reflect.DeepEqual(got, want) {
+				t.Errorf("args = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestKraftCLIDriverContainer(t *testing.T) {
+	runtime, record := fakeKraft(t, "0")
+	d := &KraftCLIDriver{
+		CrossCompile: "aarch64-linux-gnu-",
+		Container: &Container{
+			Runtime: runtime,
+			Image:   "kraftkit.sh/myself-full:latest",
+			Workdir: "/src",
+		},
+	}
+
+	if err := d.Build("/src/app", "arm64", "qemu", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"run", "--rm", "--workdir", "/src",
+		"--volume", "/src/app:/src/app",
+		"--env", "CROSS_COMPILE=aarch64-linux-gnu-",
+		"kraftkit.sh/myself-full:latest",
+		"kraft", "build", "--no-cache", "--no-update",
+		"--arch", "arm64",
+		"--plat", "qemu",
+		"/src/app",
+		// The variables are only set in the container.
+		"CROSS_COMPILE=",
+	}
+	if got := readArgs(t, record); !reflect.DeepEqual(got, want) {
+		t.Errorf("args = %q, want %q", got, want)
+	}
+}
+
+func TestKraftCLIDriverPull(t *testing.T) {
+	binary, record := fakeKraft(t, "0")
+	d := &KraftCLIDriver{Binary: binary}
+
+	err := d.Pull([]string{"app-helloworld", "lib-musl"}, "/work", PullOptions{Manager: "manifest", ChecksumPolicy: ChecksumOff})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"pkg", "pull", "--workdir", "/work",
+		"--manager", "manifest",
+		"--no-checksum",
+		"app-helloworld", "lib-musl",
+		"CROSS_COMPILE=",
+	}
+	if got := readArgs(t, record); !reflect.DeepEqual(got, want) {
+		t.Errorf("args = %q, want %q", got, want)
+	}
+}
+
+func TestKraftCLIDriverUpdate(t *testing.T) {
+	binary, record := fakeKraft(t, "0")
+	d := &KraftCLIDriver{Binary: binary}
+
+	if err := d.Update("oci"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"pkg", "update", "--manager", "oci", "CROSS_COMPILE="}
+	if got := readArgs(t, record); !reflect.DeepEqual(got, want) {
+		t.Errorf("args = %q, want %q", got, want)
+	}
+}
+
+func TestKraftCLIDriverPullChecksumWarn(t *testing.T) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "kraft")
+	script := "#!/bin/sh\n" +
+		"case \"$*\" in *--no-checksum*) exit 0;; esac\n" +
+		"echo 'checksum mismatch: expected sha256:" + strings.Repeat("1", 64) + " got sha256:" + strings.Repeat("2", 64) + "'\n" +
+		"exit 1\n"
+	if err := os.WriteFile(binary, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	d := &KraftCLIDriver{Binary: binary, Output: &out}
+
+	if err := d.Pull([]string{"lib-musl"}, "/work", PullOptions{}); err == nil || !strings.Contains(err.Error(), "checksum of lib-musl does not match") {
+		t.Errorf("expected the mismatch to fail the pull, got %v", err)
+	}
+
+	out.Reset()
+	if err := d.Pull([]string{"lib-musl"}, "/work", PullOptions{ChecksumPolicy: ChecksumWarn}); err != nil {
+		t.Fatalf("expected the pull to succeed unverified, got %v", err)
+	}
+	if !strings.Contains(out.String(), "expected sha256:"+strings.Repeat("1", 64)+", got sha256:"+strings.Repeat("2", 64)+", pulling it unverified") {
+		t.Errorf("expected the digests to be reported, got %q", out.String())
+	}
+}
+
+func TestKraftCLIDriverFailure(t *testing.T) {
+	binary, _ := fakeKraft(t, "2")
+	d := &KraftCLIDriver{Binary: binary}
+
+	err := d.Clean("/src/app")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if !strings.Contains(err.Error(), "clean failed") || !strings.Contains(err.Error(), "building") {
+		t.Errorf("expected the command and its output in %q", err)
+	}
+}
+
+func TestKraftCLIDriverCancelled(t *testing.T) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "kraft")
+	pidFile := filepath.Join(dir, "pid")
+	script := "#!/bin/sh\nsleep 60 &\necho $! > " + pidFile + "\nwait\n"
+	if err := os.WriteFile(binary, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	d := &KraftCLIDriver{Binary: binary, CommandContext: ctx}
+	if err := d.Clean("/src/app"); err == nil {
+		t.Fatal("expected the cancelled command to fail")
+	}
+
+	raw, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(raw)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The processes kraft started are stopped along with it.
+	deadline := time.Now().Add(5 * time.Second)
+	for syscall.Kill(pid, 0) == nil {
+		if time.Now().After(deadline) {
+			syscall.Kill(pid, syscall.SIGKILL)
+			t.Fatal("expected the child of kraft to be stopped")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestLineWriter(t *testing.T) {
+	var lines []string
+	w := &LineWriter{Emit: func(line string) { lines = append(lines, line) }}
+
+	w.Write([]byte("first\r\nsec"))
+	w.Write([]byte("ond\nlast"))
+	if want := []string{"first", "second"}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("lines = %q, want %q", lines, want)
+	}
+
+	w.Flush()
+	if want := []string{"first", "second", "last"}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("lines = %q, want %q", lines, want)
+	}
+}
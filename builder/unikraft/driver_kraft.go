@@ -3,6 +3,7 @@ package unikraft
 import (
 	"context"
 	"fmt"
+	"time"
 
 	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
 	"github.com/hashicorp/packer-plugin-sdk/template/interpolate"
@@ -13,17 +14,153 @@ type KraftDriver struct {
 	Ctx *interpolate.Context
 
 	CommandContext context.Context
+
+	// Kraftfile is the Kraftfile of the projects built and packaged,
+	// relative to their directory.  The default Kraftfiles are looked up
+	// when empty.
+	Kraftfile string
+	// Spec, when set, is the project built in the path of Build instead of
+	// its Kraftfile.
+	Spec *ProjectSpec
+	// Env are the variables added to the environment of make.
+	Env map[string]string
+	// CrossCompile is the toolchain prefix passed to make as CROSS_COMPILE.
+	CrossCompile string
+	// Jobs is the number of jobs make builds with, as many as allowed by
+	// NoFast when not positive.
+	Jobs int
+	// NoFast builds with a single job when Jobs is not set.
+	NoFast bool
+	// CCache wraps the compilers with ccache.
+	CCache bool
+	// BuildLog, when set, receives the output of the build phases.
+	BuildLog *BuildLog
+	// ConfigureTimeout and BuildTimeout bound the phases of every target.
+	ConfigureTimeout time.Duration
+	BuildTimeout     time.Duration
+
+	// PkgFormat is the format Pkg packages in, `oci` when empty.
+	PkgFormat string
+	// DiskFormat and DiskSize describe the image written when PkgFormat is
+	// FormatDisk.
+	DiskFormat string
+	DiskSize   int
+	// SBOMFormat, when set, is the format of the software bill of materials
+	// Pkg writes for every package: `spdx` or `cyclonedx`.
+	SBOMFormat string
+	// Volumes are the `source:destination[:driver]` volumes declared by the
+	// packages Pkg writes and Push pushes.
+	Volumes []string
+
+	// Retries is the number of times a catalog query or pull failing with a
+	// transient error is retried during the build.
+	Retries int
+	// RetryBackoff is the delay before the first retry.
+	RetryBackoff time.Duration
+	// ChecksumPolicy is how the checksums of the components pulled during
+	// the build are verified.  Defaults to ChecksumEnforce.
+	ChecksumPolicy string
+	// Resolution picks the package of a component when the catalog returns
+	// several.
+	Resolution CandidatePolicy
+	// LicensePolicy restricts the licenses of the components resolved during
+	// the build.
+	LicensePolicy LicensePolicy
+	// Mirrors maps the upstream URLs of the components downloaded during the
+	// build to the mirrors they are downloaded from instead.
+	Mirrors map[string]string
+	// Proxy are the proxies the components downloaded during the build are
+	// downloaded through.
+	Proxy ProxyConfig
+	// RateLimit caps the bandwidth of the downloads of the build, in bytes
+	// per second.  It is unlimited when not positive.
+	RateLimit int64
+	// SourceAuth are the credentials of the private sources, used when
+	// sourcing, updating and pulling from them.
+	SourceAuth []SourceAuth
+	// Cmdline, when set, is the command line the kernels are packaged and
+	// test booted with, instead of the command of the Kraftfile.
+	Cmdline string
+	// Incremental skips the configure and prepare phases of the targets
+	// unchanged since their last build, and the fetch when all of them are.
+	Incremental bool
+	// SkipUnbuildable skips, with a warning, the targets whose architecture
+	// cannot be built on the host for lack of a cross toolchain.
+	SkipUnbuildable bool
+	// ExpectedDigests maps target names to the digest their kernel must have
+	// once built.
+	ExpectedDigests map[string]string
+	// ContinueOnError keeps building the remaining targets of a build when
+	// one fails.
+	ContinueOnError bool
+	// Validator is a command run after every target is built, receiving the
+	// JSON result of the target on its standard input.
+	Validator string
+	// ChangedSince, when set, only builds the targets affected by the changes
+	// of the project since this git ref.
+	ChangedSince string
+
+	buildID string
+	results []TargetResult
+	sbom    string
 }
 
+var _ Driver = (*KraftDriver)(nil)
+var _ KernelPackager = (*KraftDriver)(nil)
+
 func (d *KraftDriver) Build(path, architecture, platform, target string) error {
+	return d.BuildWithKConfig(path, architecture, platform, target, nil)
+}
+
+// BuildWithKConfig builds a target with kconfig merged into its
+// configuration.
+func (d *KraftDriver) BuildWithKConfig(path, architecture, platform, target string, kconfig map[string]string) error {
 	c := Build{
-		Architecture: architecture,
-		Platform:     platform,
-		Target:       target,
-		NoCache:      true,
-		NoUpdate:     true,
+		Architecture:     architecture,
+		Platform:         platform,
+		Target:           target,
+		NoCache:          true,
+		NoUpdate:         true,
+		Kraftfile:        d.kraftfile(path),
+		Spec:             d.Spec,
+		Env:              d.Env,
+		CrossCompile:     d.CrossCompile,
+		Jobs:             d.Jobs,
+		NoFast:           d.NoFast,
+		CCache:           d.CCache,
+		KConfig:          kconfig,
+		Log:              d.BuildLog,
+		ConfigureTimeout: d.ConfigureTimeout,
+		BuildTimeout:     d.BuildTimeout,
+		Retries:          d.Retries,
+		RetryBackoff:     d.RetryBackoff,
+		ChecksumPolicy:   d.ChecksumPolicy,
+		Resolution:       d.Resolution,
+		LicensePolicy:    d.LicensePolicy,
+		Mirrors:          d.Mirrors,
+		Proxy:            d.Proxy,
+		RateLimit:        d.RateLimit,
+		Auth:             d.SourceAuth,
+		Incremental:      d.Incremental,
+		SkipUnbuildable:  d.SkipUnbuildable,
+		ExpectedDigests:  d.ExpectedDigests,
+		ContinueOnError:  d.ContinueOnError,
+		Validator:        d.Validator,
+		ChangedSince:     d.ChangedSince,
 	}
-	return c.BuildCmd(d.CommandContext, path)
+	err := c.BuildCmd(d.CommandContext, path)
+	d.buildID = c.ID()
+	d.results = c.Results()
+	return err
+}
+
+func (d *KraftDriver) BuildID() string {
+	return d.buildID
+}
+
+// BuildResults returns the outcome of every target of the last build.
+func (d *KraftDriver) BuildResults() []TargetResult {
+	return d.results
 }
 
 func (d *KraftDriver) Pkg(architecture, platform, target, pkgName, workdir, rootfs string, push bool) error {
@@ -31,28 +168,156 @@ func (d *KraftDriver) Pkg(architecture, platform, target, pkgName, workdir, root
 		Architecture: architecture,
 		Platform:     platform,
 		Target:       target,
-		Format:       "oci",
+		Format:       d.pkgFormat(),
 		Name:         pkgName,
 		Push:         push,
 		Rootfs:       rootfs,
+		Kraftfile:    d.kraftfile(workdir),
+		DiskFormat:   d.DiskFormat,
+		DiskSize:     d.DiskSize,
+		Volumes:      d.Volumes,
+	}
+	if d.Cmdline != "" {
+		c.Args = []string{d.Cmdline}
+	}
+
+	d.sbom = ""
+	if d.SBOMFormat != "" {
+		c.SBOM = d.SBOMFormat
+		c.SBOMOutput = SBOMPath(workdir, pkgName, d.SBOMFormat, c.Format == FormatDisk)
 	}
 
 	_, err := c.PackCmd(d.CommandContext, workdir)
+	if err == nil {
+		d.sbom = c.SBOMOutput
+	}
+	return err
+}
+
+// PkgKernel packages the imported kernel k, with its initramfs and command
+// line, without building it.  The initramfs is staged in workdir.
+func (d *KraftDriver) PkgKernel(k ImportedKernel, pkgName, workdir string, push bool) error {
+	project, err := NewProjectFromKernel(d.CommandContext, workdir, k)
+	if err != nil {
+		return err
+	}
+
+	c := Pkg{
+		Project:    project,
+		Format:     d.pkgFormat(),
+		Name:       pkgName,
+		Push:       push,
+		Rootfs:     k.Initrd,
+		NoKConfig:  true,
+		Workdir:    workdir,
+		DiskFormat: d.DiskFormat,
+		DiskSize:   d.DiskSize,
+	}
+	if k.Cmdline != "" {
+		c.Args = []string{k.Cmdline}
+	}
+
+	d.sbom = ""
+	_, err = c.PackCmd(d.CommandContext, workdir)
 	return err
 }
 
+// SBOM returns the path of the software bill of materials written by the last
+// call to Pkg, if any.
+func (d *KraftDriver) SBOM() string {
+	return d.sbom
+}
+
+func (d *KraftDriver) pkgFormat() string {
+	if d.PkgFormat == "" {
+		return "oci"
+	}
+
+	return d.PkgFormat
+}
+
+// kraftfile returns the path of the configured Kraftfile of the project in
+// workdir, or an empty string to use the default ones.
+func (d *KraftDriver) kraftfile(workdir string) string {
+	if d.Kraftfile == "" {
+		return ""
+	}
+
+	return findKraftfile(workdir, d.Kraftfile)
+}
+
+// Push pushes a package built by Pkg to its registry, with additional tags.
+// It returns the references the package was pushed as.
+func (d *KraftDriver) Push(pkgName string, tags []string, username, password string) ([]string, error) {
+	volumes, err := ParseVolumes("", d.Volumes)
+	if err != nil {
+		return nil, err
+	}
+
+	annotations, err := VolumeAnnotations(volumes)
+	if err != nil {
+		return nil, err
+	}
+
+	c := PushPkg{
+		Name:        pkgName,
+		Tags:        tags,
+		Username:    username,
+		Password:    password,
+		Annotations: annotations,
+	}
+
+	return c.PushCmd(d.CommandContext)
+}
+
+// TestBoot boots a built kernel under QEMU and waits for it to pass check.
+func (d *KraftDriver) TestBoot(kernel, architecture string, check BootCheck) error {
+	c := BootTest{
+		Architecture: architecture,
+		Kernel:       kernel,
+		Cmdline:      d.Cmdline,
+		Check:        check,
+	}
+
+	return c.BootCmd(d.CommandContext)
+}
+
+// BuildRootfs constructs the initramfs output from a directory or a
+// Dockerfile.
+func (d *KraftDriver) BuildRootfs(source, output, workdir, buildkitHost string) error {
+	c := Rootfs{
+		Source:       source,
+		Output:       output,
+		Workdir:      workdir,
+		BuildKitHost: buildkitHost,
+	}
+
+	return c.RootfsCmd(d.CommandContext)
+}
+
 func (d *KraftDriver) Clean(path string) error {
 	c := Clean{}
 
 	return c.CleanCmd(d.CommandContext, []string{path})
 }
 
-func (d *KraftDriver) Pull(source, workdir string) error {
+func (d *KraftDriver) Pull(sources []string, workdir string, opts PullOptions) error {
 	c := Pull{
-		Workdir: workdir,
+		Workdir:        workdir,
+		Manager:        opts.Manager,
+		ChecksumPolicy: opts.ChecksumPolicy,
+		Resolution:     opts.Resolution,
+		Mirrors:        opts.Mirrors,
+		Proxy:          opts.Proxy,
+		RateLimit:      opts.RateLimit,
+		Auth:           opts.Auth,
+		ForceCache:     opts.ForceCache,
+		Concurrency:    opts.Concurrency,
+		Retries:        opts.Retries,
+		RetryBackoff:   opts.RetryBackoff,
 	}
 
-	return c.PullCmd(d.CommandContext, []string{source})
+	return c.PullCmd(d.CommandContext, sources)
 }
 
 func (d *KraftDriver) Set(options map[string]string) error {
@@ -69,6 +334,7 @@ func (d *KraftDriver) Set(options map[string]string) error {
 func (d *KraftDriver) Source(source string) error {
 	c := Source{
 		Force: false,
+		Auth:  d.SourceAuth,
 	}
 
 	return c.SourceCmd(d.CommandContext, []string{source})
@@ -80,9 +346,10 @@ func (d *KraftDriver) Unsource(source string) error {
 	return c.UnsourceCmd(d.CommandContext, []string{source})
 }
 
-func (d *KraftDriver) Update() error {
+func (d *KraftDriver) Update(manager string) error {
 	c := Update{
-		Manager: "manifest",
+		Manager: manager,
+		Auth:    d.SourceAuth,
 	}
 
 	return c.UpdateCmd(d.CommandContext, []string{})
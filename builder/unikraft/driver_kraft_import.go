@@ -8,18 +8,31 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/mattn/go-shellwords"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	machineapi "kraftkit.sh/api/machine/v1alpha1"
 	"kraftkit.sh/config"
 	"kraftkit.sh/exec"
 	"kraftkit.sh/initrd"
 	"kraftkit.sh/iostreams"
+	"kraftkit.sh/kconfig"
 	"kraftkit.sh/log"
 	"kraftkit.sh/machine/platform"
+	"kraftkit.sh/machine/qemu"
 	"kraftkit.sh/make"
 	"kraftkit.sh/pack"
 	"kraftkit.sh/packmanager"
@@ -28,6 +41,10 @@ import (
 	"kraftkit.sh/unikraft"
 	"kraftkit.sh/unikraft/app"
 	"kraftkit.sh/unikraft/arch"
+	"kraftkit.sh/unikraft/component"
+	"kraftkit.sh/unikraft/core"
+	"kraftkit.sh/unikraft/lib"
+	"kraftkit.sh/unikraft/plat"
 	"kraftkit.sh/unikraft/target"
 )
 
@@ -43,55 +60,316 @@ type Build struct {
 	NoConfigure  bool
 	NoFast       bool
 	NoFetch      bool
+	NoPrepare    bool
 	NoUpdate     bool
 	Platform     string
 	Rootfs       string
 	SaveBuildLog string
 	Target       string
 
-	project app.Application
-	workdir string
+	// Env are the variables added to the environment of make in every
+	// phase of the build, such as KCFLAGS.
+	Env map[string]string
+	// CrossCompile, when set, is the toolchain prefix make builds with,
+	// passed as CROSS_COMPILE.
+	CrossCompile string
+	// CCache wraps the compilers with ccache, such that the objects of
+	// unchanged sources are reused across builds.
+	CCache bool
+
+	// ChecksumPolicy is how the checksums of the components pulled during
+	// the build are verified, one of ChecksumPolicies.  Defaults to
+	// ChecksumEnforce.
+	ChecksumPolicy string
+	// Resolution picks the package of a component or template when the
+	// catalog returns several.
+	Resolution CandidatePolicy
+
+	// KConfig are the symbols merged into the configuration of every
+	// selected target before configuring it, overriding those of the
+	// Kraftfile.
+	KConfig map[string]string
+
+	// Log, when set, receives the output of the configure, prepare and build
+	// phases of every target instead of the logger.
+	Log *BuildLog
+
+	// ConfigureTimeout and BuildTimeout bound how long the configure and
+	// prepare phases, and the build phase, of every target may take.  The
+	// phases are unbounded when not positive.
+	ConfigureTimeout time.Duration
+	BuildTimeout     time.Duration
+
+	// Components, when set, is shared by the builds of several projects so
+	// that a component pulled for one project is reused by the others.
+	Components *ComponentRegistry
+
+	// Volumes are the `source:destination[:driver]` volumes mounted by the
+	// built targets.  Each is validated against the platform of every
+	// selected target before building.
+	Volumes []string
+
+	// PlatformFallback lists the platforms tried in order when no target
+	// matches Platform.
+	PlatformFallback []string
+	// TargetFormat, when set, only selects the targets whose native package
+	// format matches.
+	TargetFormat string
+
+	// TargetsFile is the path of a JSON or YAML file of additional target
+	// definitions merged with the targets of the project before filtering.
+	TargetsFile string
+
+	// Explain logs, for every target, whether it was selected and why.
+	Explain bool
+
+	// SkipUnbuildable skips, with a warning, the targets whose architecture
+	// cannot be built on the host because no cross toolchain is available.
+	SkipUnbuildable bool
+
+	// ResolutionCache is the path of a file caching component resolutions
+	// across runs.  It is bypassed by NoCache and RefreshCatalog.
+	ResolutionCache string
+	// ResolutionCacheTTL is how long a cached resolution is trusted.
+	// Defaults to DefaultResolutionCacheTTL.
+	ResolutionCacheTTL time.Duration
+	// RefreshCatalog invalidates the resolution cache.
+	RefreshCatalog bool
+	// RateLimit caps the bandwidth of all downloads, in bytes per second,
+	// shared by concurrent pulls.  It is unlimited when not positive.
+	RateLimit int64
+	// Mirrors maps the upstream URLs components are downloaded from to the
+	// mirrors they are downloaded from instead.
+	Mirrors map[string]string
+	// Proxy are the proxies components are downloaded through.
+	Proxy ProxyConfig
+	// Auth are the credentials of the private sources components are
+	// pulled from.
+	Auth []SourceAuth
+	// ResolveConcurrency is the maximum number of components resolved in the
+	// catalog, and then pulled, at the same time.  Components are handled one
+	// at a time when it is not positive.
+	ResolveConcurrency int
+	// MaxConcurrency caps the number of catalog queries, pulls and target
+	// builds in flight at any time, across all of them.  It is unlimited when
+	// not positive.
+	MaxConcurrency int
+	// NoCacheComponents names the components whose catalog query and pull
+	// bypass the cache, while the others keep using it.
+	NoCacheComponents []string
+	// LicensePolicy restricts the licenses of the components resolved for
+	// pulling.  The build fails before anything is pulled when a component's
+	// license is not permitted.
+	LicensePolicy LicensePolicy
+
+	// ForceConfigure configures every target even when an existing .config
+	// is compatible with it.
+	ForceConfigure bool
+
+	// Incremental skips the configure and prepare phases of the targets whose
+	// Kraftfile, KConfig options and component versions are unchanged since
+	// their last successful build, as recorded in the build folder.  When
+	// every selected target is unchanged and the sources of the components
+	// are still there, the package index is not updated and nothing is
+	// pulled either.
+	Incremental bool
+
+	// ContinueOnError keeps building the remaining targets when one fails.
+	// The build only fails when every target which was not skipped failed.
+	ContinueOnError bool
+
+	// CheckDependencies resolves the component dependency graph and fails on
+	// version conflicts before anything is updated, pulled or built.
+	CheckDependencies bool
+
+	// Spec, when set, is the project built instead of the Kraftfile of the
+	// workdir.
+	Spec *ProjectSpec
+
+	// ChangedSince, when set, only builds the targets affected by the changes
+	// of the workdir since this git ref.  Every selected target is built when
+	// it cannot be told which targets the changes affect.
+	ChangedSince string
+
+	// Validator is a command run after each target is built, receiving the
+	// JSON result of the target on its standard input.  A non-zero exit
+	// status fails the target.
+	Validator string
+
+	// ExpectedDigests maps target names to the digest their kernel must have
+	// once built.  A mismatch fails the build.
+	ExpectedDigests map[string]string
+
+	// NoConfigureTargets restricts NoConfigure to the named targets.  When
+	// empty, NoConfigure applies to all selected targets.
+	NoConfigureTargets []string
+	// NoPrepareTargets restricts NoPrepare to the named targets.  When empty,
+	// NoPrepare applies to all selected targets.
+	NoPrepareTargets []string
+	// ForcePrepare runs the prepare phase even when no component registers
+	// a prepare rule.
+	ForcePrepare bool
+
+	// Retries is the number of times a catalog query or pull is retried when
+	// it fails with a transient error.
+	Retries int
+	// RetryBackoff is the delay before the first retry.  It doubles for
+	// every further retry, up to DefaultRetryMaxBackoff.
+	RetryBackoff time.Duration
+	// RetryPatterns are the error message regular expressions classified as
+	// transient.  DefaultTransientErrorPatterns is used when empty.
+	RetryPatterns []string
+
+	// Report is the path of a consolidated report of all built targets.  Paths
+	// ending in `.json` receive a JSON document, others a text summary.
+	Report string
+	// NoEnvironment omits the metadata of the build host from the report.
+	NoEnvironment bool
+
+	// Manifest is the path of a JSON document listing every make command
+	// executed during the build, with its phase, target and exit status.
+	Manifest string
+
+	// Events is the path of a file or named pipe receiving the progress of the
+	// build as newline-delimited JSON events.
+	Events string
+	// Observer, when set, is notified of the progress of the build.
+	Observer Observer
+
+	// Sarif is the path of a SARIF document listing the compiler warnings and
+	// errors reported while building.
+	Sarif string
+
+	// TraceEndpoint is the OTLP/HTTP endpoint, e.g. `http://localhost:4318`,
+	// the spans of the build are exported to.  Tracing is disabled when
+	// neither it nor TracerProvider is set.
+	TraceEndpoint string
+	// TracerProvider, when set, creates the spans of the build instead of
+	// exporting them to TraceEndpoint.
+	TracerProvider trace.TracerProvider
+
+	project     app.Application
+	diagnostics *diagnosticCollector
+	id          string
+	limiter     *Semaphore
+	noPrepare   bool
+	observer    Observer
+	phases      phaseTimes
+	recorder    *commandRecorder
+	report      *BuildReport
+	tracer      trace.Tracer
+	versions    map[string]string
+	warnings    map[string]int
+	workdir     string
+}
+
+// componentResolution is the package a component was resolved to in the
+// catalog.
+type componentResolution struct {
+	pack    pack.Package
+	key     string
+	path    string
+	noCache bool
+}
+
+// resolveCandidate returns the package of what among the candidates the
+// catalog returned for it, picked by policy when there are several.
+func resolveCandidate(ctx context.Context, policy CandidatePolicy, what string, candidates []pack.Package) (pack.Package, error) {
+	switch len(candidates) {
+	case 0:
+		return nil, fmt.Errorf("could not find: %s", what)
+	case 1:
+		return candidates[0], nil
+	}
+
+	entries := make([]CatalogEntry, len(candidates))
+	for i, p := range candidates {
+		entries[i] = NewCatalogEntry(p)
+	}
+
+	i, err := policy.Resolve(entries)
+	if err != nil {
+		return nil, fmt.Errorf("too many options for %s: %w", what, err)
+	}
+
+	log.G(ctx).Infof("resolved %s to %s out of %d candidates", what, candidates[i].String(), len(candidates))
+
+	return candidates[i], nil
+}
+
+// retrier returns the retry policy used for catalog queries and pulls.
+func (opts *Build) retrier() (*Retrier, error) {
+	classifier, err := NewRetryClassifier(opts.RetryPatterns)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Retrier{
+		Attempts:   opts.Retries + 1,
+		Backoff:    opts.RetryBackoff,
+		MaxBackoff: DefaultRetryMaxBackoff,
+		Classifier: classifier,
+	}, nil
 }
 
 func (opts *Build) pull(ctx context.Context) error {
 	var missingPacks []pack.Package
+
+	defer registerSourceAuth(ctx, opts.Auth)()
 	auths := config.G[config.KraftKit](ctx).Auth
+	client := &http.Client{Transport: newDownloadTransport(opts.Mirrors, opts.Proxy, opts.Auth, NewRateLimiter(opts.RateLimit))}
+	gitAuth := gitSSHAuth(opts.Auth)
+	downloads := &DownloadSizes{}
+
+	retrier, err := opts.retrier()
+	if err != nil {
+		return err
+	}
 
 	if template := opts.project.Template(); template != nil {
 		if stat, err := os.Stat(template.Path()); err != nil || !stat.IsDir() || opts.ForcePull {
 			var templatePack pack.Package
-
-			p, err := packmanager.G(ctx).Catalog(ctx,
-				packmanager.WithName(template.Name()),
-				packmanager.WithTypes(template.Type()),
-				packmanager.WithVersion(template.Version()),
-				packmanager.WithSource(template.Source()),
-				packmanager.WithUpdate(opts.NoCache),
-				packmanager.WithAuthConfig(auths),
-			)
+			var p []pack.Package
+
+			err := retrier.Do(ctx, func() error {
+				var err error
+				p, err = packmanager.G(ctx).Catalog(ctx,
+					packmanager.WithName(template.Name()),
+					packmanager.WithTypes(template.Type()),
+					packmanager.WithVersion(template.Version()),
+					packmanager.WithSource(template.Source()),
+					packmanager.WithUpdate(opts.NoCache),
+					packmanager.WithAuthConfig(auths),
+					packmanager.WithHTTPClient(client),
+					packmanager.WithGitSSHAuth(gitAuth),
+				)
+				return err
+			})
 			if err != nil {
 				return err
 			}
 
-			if len(p) == 0 {
-				return fmt.Errorf("could not find: %s",
-					unikraft.TypeNameVersion(template),
-				)
-			} else if len(p) > 1 {
-				return fmt.Errorf("too many options for %s",
-					unikraft.TypeNameVersion(template),
-				)
+			templatePack, err = resolveCandidate(ctx, opts.Resolution, unikraft.TypeNameVersion(template), p)
+			if err != nil {
+				return err
 			}
 
-			templatePack = p[0]
-
-			templatePack.Pull(
-				ctx,
-				pack.WithPullWorkdir(opts.workdir),
-				// pack.WithPullChecksum(!opts.NoChecksum),
-				pack.WithPullCache(!opts.NoCache),
-				pack.WithPullAuthConfig(auths),
-			)
+			err = pullVerified(opts.ChecksumPolicy, templatePack.Name(), logWarn(ctx), func(checksum bool) error {
+				return retrier.Do(ctx, func() error {
+					return templatePack.Pull(
+						ctx,
+						pack.WithPullWorkdir(opts.workdir),
+						pack.WithPullChecksum(checksum),
+						pack.WithPullCache(!opts.NoCache),
+						pack.WithPullAuthConfig(auths),
+						pack.WithPullHTTPClient(client),
+						pack.WithPullGitSSHAuth(gitAuth),
+					)
+				})
+			})
+			if err != nil {
+				return err
+			}
 		}
 
 		templateProject, err := app.NewProjectFromOptions(ctx,
@@ -113,8 +391,43 @@ func (opts *Build) pull(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+
+	if len(opts.NoCacheComponents) > 0 {
+		var names []string
+		for _, component := range components {
+			names = append(names, component.Name())
+		}
+
+		if err := validateComponentNames(opts.NoCacheComponents, names); err != nil {
+			return fmt.Errorf("could not disable cache: %w", err)
+		}
+	}
+
+	var resolutions *ResolutionCache
+	if len(opts.ResolutionCache) > 0 {
+		resolutions, err = LoadResolutionCache(opts.ResolutionCache, opts.ResolutionCacheTTL)
+		if err != nil {
+			return err
+		}
+
+		if opts.NoCache || opts.RefreshCatalog {
+			resolutions.Invalidate()
+		}
+	}
+
+	// Resolved components whose digest is recorded once they are pulled,
+	// keyed by their resolution cache key.
+	resolved := map[string]Requirement{}
+	resolvedPaths := map[string]string{}
+
+	// Whether each of the missing packages is pulled without the cache, and
+	// their resolution cache key.
+	var missingNoCache []bool
+	var missingKeys []string
+
+	var pending []component.Component
 	for _, component := range components {
-		// Skip "finding" the component if path is the same as the source (which
+		// Skip "finding the component if path is the same as the source (which
 		// means that the source code is already available as it is a directory on
 		// disk.  In this scenario, the developer is likely hacking the particular
 		// microlibrary/component.
@@ -128,175 +441,1120 @@ func (opts *Build) pull(ctx context.Context) error {
 			continue
 		}
 
-		component := component // loop closure
-		auths := auths
-
 		if f, err := os.Stat(component.Source()); err == nil && f.IsDir() {
 			continue
 		}
 
-		p, err := packmanager.G(ctx).Catalog(ctx,
-			packmanager.WithName(component.Name()),
-			packmanager.WithTypes(component.Type()),
-			packmanager.WithVersion(component.Version()),
-			packmanager.WithSource(component.Source()),
-			packmanager.WithUpdate(opts.NoCache),
-			packmanager.WithAuthConfig(auths),
-		)
+		pending = append(pending, component)
+	}
+
+	// Catalog queries are independent from one another and are resolved by a
+	// bounded number of workers.
+	results, err := mapBounded(ctx, pending, opts.ResolveConcurrency, opts.limiter, func(ctx context.Context, component component.Component) (componentResolution, error) {
+		// A fresh cached resolution pins the version and is resolved against
+		// the local index rather than refreshing the remote catalog.
+		key := resolutionKey(string(component.Type()), component.Name(), component.Version(), component.Source())
+		version := component.Version()
+		update := componentNoCache(opts.NoCache, opts.NoCacheComponents, component.Name())
+		if resolutions != nil && !update {
+			if res, ok := resolutions.Get(key); ok {
+				log.G(ctx).Debugf("using cached resolution of %s: %s", component.Name(), res.Version)
+				version = res.Version
+			}
+		}
+
+		var p []pack.Package
+		err := retrier.Do(ctx, func() error {
+			var err error
+			p, err = packmanager.G(ctx).Catalog(ctx,
+				packmanager.WithName(component.Name()),
+				packmanager.WithTypes(component.Type()),
+				packmanager.WithVersion(version),
+				packmanager.WithSource(component.Source()),
+				packmanager.WithUpdate(update),
+				packmanager.WithAuthConfig(auths),
+				packmanager.WithHTTPClient(client),
+				packmanager.WithGitSSHAuth(gitAuth),
+			)
+			return err
+		})
+		if err != nil {
+			return componentResolution{}, err
+		}
+
+		resolved, err := resolveCandidate(ctx, opts.Resolution, unikraft.TypeNameVersion(component), p)
+		if err != nil {
+			return componentResolution{}, err
+		}
+
+		return componentResolution{
+			pack:    resolved,
+			key:     key,
+			path:    component.Path(),
+			noCache: update,
+		}, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, res := range results {
+		missingPacks = append(missingPacks, res.pack)
+		missingNoCache = append(missingNoCache, res.noCache)
+		missingKeys = append(missingKeys, res.key)
+		resolved[res.key] = Requirement{Name: res.pack.Name(), Version: res.pack.Version()}
+		resolvedPaths[res.key] = res.path
+	}
+
+	if !opts.LicensePolicy.Empty() {
+		licenses := map[string]string{}
+		for _, res := range results {
+			licenses[res.pack.Name()] = componentLicense(res.pack.Metadata())
+		}
+
+		missing, err := opts.LicensePolicy.CheckLicenses(licenses)
+		for _, name := range missing {
+			log.G(ctx).Warnf("component %s has no license metadata", name)
+		}
 		if err != nil {
 			return err
 		}
+	}
+
+	indexes := make([]int, len(missingPacks))
+	for i := range missingPacks {
+		indexes[i] = i
+	}
+
+	_, err = mapBounded(ctx, indexes, opts.ResolveConcurrency, opts.limiter, func(ctx context.Context, i int) (struct{}, error) {
+		p := missingPacks[i]
+		key := missingKeys[i]
+
+		ctx, span := startPullSpan(ctx, opts.tracer, p.Name(), p.Version())
+
+		reused, err := opts.Components.Ensure(key, p.Version(), resolvedPaths[key], func() error {
+			// An aborted build stops the pull mid-transfer and removes what
+			// was partially pulled.
+			pctx := withDownloadCounter(ctx, downloads, p.Name())
+			return pullOrCleanup(pctx, resolvedPaths[key], func(ctx context.Context) error {
+				return pullVerified(opts.ChecksumPolicy, p.Name(), logWarn(ctx), func(checksum bool) error {
+					return retrier.Do(ctx, func() error {
+						return p.Pull(
+							ctx,
+							pack.WithPullWorkdir(opts.workdir),
+							pack.WithPullChecksum(checksum),
+							pack.WithPullCache(!missingNoCache[i]),
+							pack.WithPullAuthConfig(auths),
+							pack.WithPullHTTPClient(client),
+							pack.WithPullGitSSHAuth(gitAuth),
+							pack.WithPullProgressFunc(func(progress float64) {
+								emit(opts.observer, EventPullProgress, "", map[string]interface{}{
+									"package":  p.Name(),
+									"progress": progress,
+								})
+							}),
+						)
+					})
+				})
+			})
+		})
+		span.SetAttributes(attrBytes.Int64(downloads.Bytes(p.Name())))
+		endSpan(span, err)
+
+		if reused {
+			log.G(ctx).Debugf("reusing %s pulled for another project", p.Name())
+		}
 
-		if len(p) == 0 {
-			return fmt.Errorf("could not find: %s",
-				unikraft.TypeNameVersion(component),
-			)
-		} else if len(p) > 1 {
-			log.G(ctx).Warnf("too many options for %s, %d",
-				unikraft.TypeNameVersion(component),
-				len(p),
+		return struct{}{}, err
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(downloads.Summary()) > 0 {
+		log.G(ctx).Infof("downloaded per component:\n%s", downloads.Text())
+	}
+
+	if resolutions != nil {
+		for key, req := range resolved {
+			digest, err := dirDigest(resolvedPaths[key])
+			if err != nil {
+				log.G(ctx).Debugf("could not compute digest of %s: %v", req.Name, err)
+			}
+
+			resolutions.Put(key, req.Version, digest)
+		}
+
+		if err := resolutions.Save(); err != nil {
+			log.G(ctx).Warnf("could not save resolution cache: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func (opts *Build) BuildCmd(ctx context.Context, args ...string) error {
+	var err error
+
+	if err := checkMakeEnv(opts.Env, opts.CrossCompile); err != nil {
+		return err
+	}
+
+	if opts.CCache {
+		if err := checkCCache(opts.Env); err != nil {
+			return err
+		}
+		if err := lookCCache(); err != nil {
+			return err
+		}
+	}
+
+	if len(args) == 0 {
+		opts.workdir, err = os.Getwd()
+		if err != nil {
+			return err
+		}
+	} else {
+		opts.workdir = args[0]
+	}
+
+	popts := []app.ProjectOption{
+		app.WithProjectWorkdir(opts.workdir),
+	}
+
+	if len(opts.Kraftfile) > 0 {
+		popts = append(popts, app.WithProjectKraftfile(opts.Kraftfile))
+	} else {
+		popts = append(popts, app.WithProjectDefaultKraftfiles())
+	}
+
+	if opts.Spec != nil {
+		opts.project, err = NewProjectFromSpec(ctx, opts.workdir, *opts.Spec)
+		if err != nil {
+			return err
+		}
+	} else {
+		if err := checkKraftfileSpec(opts.workdir, opts.Kraftfile); err != nil {
+			return err
+		}
+
+		// Initialize at least the configuration options for a project
+		opts.project, err = app.NewProjectFromOptions(ctx, popts...)
+		if err != nil && errors.Is(err, app.ErrNoKraftfile) {
+			return fmt.Errorf("cannot build project directory without a Kraftfile")
+		} else if err != nil {
+			return fmt.Errorf("could not initialize project directory: %w", err)
+		}
+	}
+
+	opts.Platform = platform.PlatformByName(opts.Platform).String()
+	opts.limiter = NewSemaphore(opts.MaxConcurrency)
+
+	opts.observer = opts.Observer
+	if len(opts.Events) > 0 {
+		stream, err := OpenEventStream(opts.Events)
+		if err != nil {
+			return fmt.Errorf("could not open event stream: %w", err)
+		}
+		defer stream.Close()
+
+		if opts.Observer != nil {
+			opts.observer = observers{opts.Observer, stream}
+		} else {
+			opts.observer = stream
+		}
+	}
+
+	provider := opts.TracerProvider
+	if provider == nil && len(opts.TraceEndpoint) > 0 {
+		tp, err := newTracerProvider(ctx, opts.TraceEndpoint)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := tp.Shutdown(context.Background()); err != nil {
+				log.G(ctx).Warnf("could not export build trace: %v", err)
+			}
+		}()
+		provider = tp
+	} else if provider == nil {
+		provider = trace.NewNoopTracerProvider()
+	}
+	opts.tracer = provider.Tracer(tracerName)
+
+	ctx, span := opts.tracer.Start(ctx, "build")
+	defer span.End()
+
+	selected := opts.project.Targets()
+	if len(opts.TargetsFile) > 0 {
+		defs, err := LoadTargetDefinitions(opts.TargetsFile)
+		if err != nil {
+			return err
+		}
+
+		selected, err = mergeTargetDefinitions(selected, defs)
+		if err != nil {
+			return err
+		}
+	}
+	if len(selected) == 0 {
+		return fmt.Errorf("no targets to build")
+	}
+	if !opts.All {
+		filter := TargetFilter{
+			Architecture:     opts.Architecture,
+			Platform:         opts.Platform,
+			Target:           opts.Target,
+			PlatformFallback: opts.PlatformFallback,
+			Format:           opts.TargetFormat,
+		}
+
+		if len(opts.Target) > 0 && len(filter.Architectures()) > 1 {
+			return fmt.Errorf("multiple architectures cannot be combined with a target name")
+		}
+
+		if opts.Explain {
+			for _, d := range FilterTargetsDetailed(selected, filter) {
+				log.G(ctx).Info(d.String())
+			}
+		}
+
+		selected = FilterTargets(selected, filter)
+
+		if len(opts.PlatformFallback) > 0 && len(selected) > 0 {
+			log.G(ctx).Infof("selected platform %s", selected[0].Platform().Name())
+		}
+
+		if !config.G[config.KraftKit](ctx).NoPrompt {
+			res, err := target.Select(selected)
+			if err != nil {
+				return err
+			}
+			selected = []target.Target{res}
+		}
+	}
+
+	if len(selected) == 0 {
+		return fmt.Errorf("no targets selected to build")
+	}
+
+	// unchanged are the results of the targets skipped as unaffected by the
+	// changes since ChangedSince.
+	var unchanged []TargetResult
+	if len(opts.ChangedSince) > 0 {
+		changed, ok := opts.changedTargets(ctx, selected)
+		if ok {
+			affected := map[string]bool{}
+			for _, targ := range changed {
+				affected[targ.Name()] = true
+			}
+
+			for _, targ := range selected {
+				if affected[targ.Name()] {
+					continue
+				}
+
+				unchanged = append(unchanged, TargetResult{
+					Target:       targ.Name(),
+					Architecture: targ.Architecture().Name(),
+					Platform:     targ.Platform().Name(),
+					Status:       TargetStatusSkipped,
+					Error:        fmt.Sprintf("unchanged since %s", opts.ChangedSince),
+				})
+			}
+			selected = changed
+		}
+		if ok && len(changed) == 0 {
+			log.G(ctx).Infof("no target is affected by the changes since %s", opts.ChangedSince)
+			opts.report = &BuildReport{}
+			for _, res := range unchanged {
+				opts.complete(opts.report, res)
+			}
+			return nil
+		}
+	}
+
+	if len(opts.Volumes) > 0 {
+		var volumes []Volume
+		for _, spec := range opts.Volumes {
+			v, err := ParseVolume(spec)
+			if err != nil {
+				return err
+			}
+			volumes = append(volumes, v)
+		}
+
+		for _, targ := range selected {
+			if err := validateVolumes(targ.Platform().Name(), volumes); err != nil {
+				return fmt.Errorf("target %s: %w", targ.Name(), err)
+			}
+		}
+	}
+
+	if opts.CheckDependencies {
+		if err := opts.checkDependencies(ctx); err != nil {
+			return err
+		}
+	}
+
+	if opts.fetchUnchanged(ctx, selected) {
+		log.G(ctx).Info("the targets are unchanged since their last build, skipping fetch")
+	} else {
+		if opts.ForcePull || !opts.NoUpdate {
+			err := packmanager.G(ctx).Update(ctx)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := opts.pull(ctx); err != nil {
+			return err
+		}
+	}
+
+	var mopts []make.MakeOption
+	if opts.Jobs > 0 {
+		mopts = append(mopts, make.WithJobs(opts.Jobs))
+	} else {
+		mopts = append(mopts, make.WithMaxJobs(!opts.NoFast && !config.G[config.KraftKit](ctx).NoParallel))
+	}
+
+	if opts.CCache {
+		vars := ccacheVars(opts.CrossCompile)
+		for _, name := range sortedKeys(vars) {
+			mopts = append(mopts, make.WithVar(name, vars[name]))
+		}
+	}
+
+	if len(opts.Manifest) > 0 {
+		cleanup, err := opts.recordCommands(ctx)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+	}
+
+	if len(opts.Sarif) > 0 {
+		opts.diagnostics = &diagnosticCollector{}
+		defer func() {
+			if err := writeSARIF(opts.Sarif, opts.diagnostics.Diagnostics()); err != nil {
+				log.G(ctx).Warnf("could not write SARIF diagnostics: %v", err)
+			}
+		}()
+	}
+
+	report := &BuildReport{}
+	if !opts.NoEnvironment {
+		report.Environment = collectBuildEnvironment(hostProbe)
+	}
+	opts.report = report
+	for _, res := range unchanged {
+		opts.complete(report, res)
+	}
+	opts.phases = phaseTimes{}
+	opts.warnings = map[string]int{}
+	if len(opts.Report) > 0 {
+		defer func() {
+			if err := report.WriteFile(opts.Report); err != nil {
+				log.G(ctx).Warnf("could not write build report: %v", err)
+			}
+		}()
+	}
+
+	versions := opts.componentVersions(ctx)
+	opts.versions = versions
+
+	opts.id = opts.buildInputs(selected, versions).ID()
+	report.BuildID = opts.id
+	log.G(ctx).Infof("build id: %s", opts.id)
+
+	if !opts.ForcePrepare {
+		opts.noPrepare = !hasPrepareRules(opts.componentDirs(ctx))
+	}
+
+	var validator []string
+	if len(opts.Validator) > 0 {
+		validator, err = shellwords.Parse(opts.Validator)
+		if err != nil {
+			return fmt.Errorf("invalid validator command: %w", err)
+		}
+	}
+
+	// failures are the errors of the targets which failed when continuing on
+	// errors.
+	var failures []error
+
+	for _, targ := range selected {
+		// See: https://github.com/golang/go/wiki/CommonMistakes#using-reference-to-loop-iterator-variable
+		targ := targ
+		start := time.Now()
+
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("build cancelled before %s: %w", targ.Name(), err)
+		}
+
+		if opts.SkipUnbuildable && !buildableOnHost(targ.Architecture().Name()) {
+			log.G(ctx).Warnf("skipping %s: no toolchain available to build %s on this host",
+				targ.Name(),
+				targ.Architecture().Name(),
 			)
-			for _, p1 := range p {
-				log.G(ctx).Warnf(" - %s", p1.String())
+			opts.complete(report, TargetResult{
+				Target:       targ.Name(),
+				Architecture: targ.Architecture().Name(),
+				Platform:     targ.Platform().Name(),
+				Status:       TargetStatusSkipped,
+				Error:        "no toolchain available on host",
+			})
+			continue
+		}
+
+		tctx, tspan := startTargetSpan(ctx, opts.tracer,
+			targ.Name(),
+			targ.Architecture().Name(),
+			targ.Platform().Name(),
+		)
+
+		err := opts.limiter.Acquire(tctx)
+		if err == nil {
+			err = opts.buildTarget(tctx, targ, mopts)
+			opts.limiter.Release()
+		}
+		if err == nil {
+			err = checkKernelBuilt(targ.Name(), targ.Kernel())
+		}
+		if expected, ok := opts.ExpectedDigests[targ.Name()]; ok && err == nil {
+			err = verifyDigest(targ.Kernel(), expected)
+		}
+		err = targetError(targ.Name(), err)
+
+		res := newTargetResult(
+			targ.Name(),
+			targ.Architecture().Name(),
+			targ.Platform().Name(),
+			targ.Kernel(),
+			start,
+			err,
+			versions,
+		)
+		res.Phases = opts.phases[targ.Name()]
+		res.Warnings = opts.warnings[targ.Name()]
+		if err == nil && len(validator) > 0 {
+			out, verr := runValidator(ctx, validator, res)
+			if len(out) > 0 {
+				log.G(ctx).Infof("validator: %s", out)
 			}
-			return fmt.Errorf("too many options for %s",
-				unikraft.TypeNameVersion(component),
+			if verr != nil {
+				err = targetError(targ.Name(), verr)
+				res.Status = TargetStatusFailed
+				res.Error = verr.Error()
+			}
+		}
+
+		opts.complete(report, res)
+		endSpan(tspan, err)
+
+		// A cancelled build does not continue with the next targets.
+		if err != nil && opts.ContinueOnError && ctx.Err() == nil {
+			log.G(ctx).Warnf("could not build %s, continuing: %v", targ.Name(), err)
+			failures = append(failures, err)
+		} else if err != nil {
+			return err
+		}
+	}
+
+	// Skipped targets were not attempted, so they do not keep the build from
+	// failing when all the others did.
+	if report.AllFailed() {
+		return fmt.Errorf("all %d attempted targets failed to build: %w", report.Failed(), errors.Join(failures...))
+	}
+
+	return nil
+}
+
+// recordCommands installs the make shim of a commandRecorder, which execEnv
+// puts on the PATH of make for the duration of the build.  The returned
+// function writes the manifest.
+func (opts *Build) recordCommands(ctx context.Context) (func(), error) {
+	dir, err := os.MkdirTemp("", "packer-unikraft-manifest-")
+	if err != nil {
+		return nil, err
+	}
+
+	recorder, err := newCommandRecorder(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("could not record build commands: %w", err)
+	}
+
+	opts.recorder = recorder
+
+	return func() {
+		opts.recorder = nil
+		defer os.RemoveAll(dir)
+
+		commands, err := recorder.Commands()
+		if err == nil {
+			err = writeBuildManifest(opts.Manifest, commands)
+		}
+		if err != nil {
+			log.G(ctx).Warnf("could not write build manifest: %v", err)
+		}
+	}, nil
+}
+
+// runPhase runs a phase of a target, attributing the make commands it runs
+// to it in the build manifest and notifying the observer of its start and
+// end.  The context the phase runs make with is cancelled when ctx is done or
+// the phase times out.
+func (opts *Build) runPhase(ctx context.Context, name string, targ target.Target, fn func(ctx context.Context) error) error {
+	if err := ctx.Err(); err != nil {
+		return &TargetError{Target: targ.Name(), Phase: name, Err: err}
+	}
+
+	timeout := opts.phaseTimeout(name)
+	pctx, cancel := ctx, context.CancelFunc(func() {})
+	if timeout > 0 {
+		pctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	defer cancel()
+
+	if opts.recorder != nil {
+		if err := opts.recorder.SetPhase(name, targ.Name()); err != nil {
+			log.G(ctx).Debugf("could not record %s phase of %s: %v", name, targ.Name(), err)
+		}
+	}
+
+	emit(opts.observer, EventPhaseStart, targ.Name(), map[string]interface{}{"phase": name})
+	start := time.Now()
+
+	_, span := startPhaseSpan(ctx, opts.tracer, name, targ.Name())
+	err := fn(pctx)
+	if ctx.Err() != nil {
+		err = ctx.Err()
+	} else if pctx.Err() != nil {
+		err = fmt.Errorf("timed out after %s", timeout)
+	}
+	if err != nil {
+		err = &TargetError{Target: targ.Name(), Phase: name, Err: err}
+	}
+	endSpan(span, err)
+
+	duration := time.Since(start)
+	if opts.phases != nil {
+		opts.phases.add(targ.Name(), name, duration)
+	}
+
+	payload := map[string]interface{}{
+		"phase":    name,
+		"duration": duration.String(),
+	}
+	if err != nil {
+		payload["error"] = err.Error()
+	}
+	emit(opts.observer, EventPhaseEnd, targ.Name(), payload)
+
+	return err
+}
+
+// phaseTimeout returns the timeout of a phase, or 0 when it is unbounded.
+func (opts *Build) phaseTimeout(phase string) time.Duration {
+	switch phase {
+	case "configure", "prepare":
+		return opts.ConfigureTimeout
+	case "build":
+		return opts.BuildTimeout
+	}
+
+	return 0
+}
+
+// complete records the result of a target and notifies the observer.
+func (opts *Build) complete(report *BuildReport, res TargetResult) {
+	report.Add(res)
+
+	payload := map[string]interface{}{
+		"status":   res.Status,
+		"duration": res.Duration.String(),
+	}
+	if res.Error != "" {
+		payload["error"] = res.Error
+	}
+	if res.Kernel != "" {
+		payload["kernel"] = res.Kernel
+	}
+
+	emit(opts.observer, EventTargetComplete, res.Target, payload)
+}
+
+// Results returns the outcome of every target handled by the last call to
+// BuildCmd.
+func (opts *Build) Results() []TargetResult {
+	if opts.report == nil {
+		return nil
+	}
+
+	return opts.report.Targets
+}
+
+// changedTargets returns the selected targets affected by the changes since
+// ChangedSince, and whether they could be told.
+func (opts *Build) changedTargets(ctx context.Context, selected []target.Target) ([]target.Target, bool) {
+	files, err := gitChangedFiles(ctx, opts.workdir, opts.ChangedSince)
+	if err != nil {
+		log.G(ctx).Warnf("could not list changes since %s, building every target: %v", opts.ChangedSince, err)
+		return nil, false
+	}
+
+	var corePath string
+	if core := opts.project.Unikraft(ctx); core != nil {
+		corePath = core.Path()
+	}
+
+	scopes := make([]targetChangeScope, 0, len(selected))
+	for _, targ := range selected {
+		scopes = append(scopes, newTargetChangeScope(
+			opts.workdir,
+			targ.Name(),
+			targ.ConfigFilename(),
+			corePath,
+			targ.Architecture().Name(),
+			targ.Platform().Name(),
+		))
+	}
+
+	names, ok := changedTargets(files, scopes)
+	if !ok {
+		log.G(ctx).Infof("changes since %s may affect every target, building all", opts.ChangedSince)
+		return nil, false
+	}
+
+	log.G(ctx).Infof("building the targets affected by the changes since %s: %s", opts.ChangedSince, strings.Join(names, ", "))
+
+	return targetsNamed(selected, names), true
+}
+
+// checkDependencies walks the dependency graph of the project components and
+// reports conflicting versions.
+func (opts *Build) checkDependencies(ctx context.Context) error {
+	components, err := opts.project.Components(ctx)
+	if err != nil {
+		return err
+	}
+
+	src := &projectDependencySource{paths: map[string]string{}}
+	roots := []Requirement{}
+	for _, component := range components {
+		roots = append(roots, Requirement{Name: component.Name(), Version: component.Version()})
+		src.paths[component.Name()] = component.Path()
+	}
+
+	return CheckDependencyGraph(ctx, roots, src)
+}
+
+// projectDependencySource resolves the dependencies of the components which
+// are available on disk by interpreting their own Kraftfile.  Components
+// without a Kraftfile have no dependencies.
+type projectDependencySource struct {
+	paths map[string]string
+}
+
+func (s *projectDependencySource) Dependencies(ctx context.Context, req Requirement) ([]Requirement, error) {
+	path, ok := s.paths[req.Name]
+	if !ok {
+		return nil, nil
+	}
+
+	if stat, err := os.Stat(path); err != nil || !stat.IsDir() {
+		return nil, nil
+	}
+
+	project, err := app.NewProjectFromOptions(ctx,
+		app.WithProjectWorkdir(path),
+		app.WithProjectDefaultKraftfiles(),
+	)
+	if err != nil && errors.Is(err, app.ErrNoKraftfile) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	components, err := project.Components(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var deps []Requirement
+	for _, component := range components {
+		if component.Name() == req.Name {
+			continue
+		}
+
+		deps = append(deps, Requirement{Name: component.Name(), Version: component.Version()})
+		if _, ok := s.paths[component.Name()]; !ok {
+			s.paths[component.Name()] = component.Path()
+		}
+	}
+
+	return deps, nil
+}
+
+// buildTarget runs the configure, prepare and build phases for a single
+// target.
+func (opts *Build) buildTarget(ctx context.Context, targ target.Target, mopts []make.MakeOption) error {
+	fingerprint, unchanged := opts.unchangedSinceLastBuild(ctx, targ)
+	if unchanged {
+		log.G(ctx).Infof("%s is unchanged since its last build, skipping configure and prepare", targ.Name())
+	}
+
+	if !unchanged && !skipPhase(opts.NoConfigure, opts.NoConfigureTargets, targ.Name()) && !opts.reuseDotConfig(ctx, targ) {
+		stdout, stderr, flush := opts.phaseOutput(ctx, targ, logrus.ErrorLevel)
+		err := opts.runPhase(ctx, "configure", targ, func(ctx context.Context) error {
+			return opts.project.Configure(
+				ctx,
+				targ,                // Target-specific options
+				opts.extraKConfig(), // Merged configuration options
+				make.WithSilent(true),
+				make.WithExecOptions(append(opts.execEnv(),
+					exec.WithStdin(iostreams.G(ctx).In),
+					exec.WithStdout(stdout),
+					exec.WithStderr(stderr),
+				)...),
 			)
+		})
+		flush()
+		if err != nil {
+			return err
+		}
+	}
+
+	prepare := !unchanged && !skipPhase(opts.NoPrepare, opts.NoPrepareTargets, targ.Name())
+	if prepare && opts.noPrepare {
+		log.G(ctx).Infof("skipping prepare of %s: no component has prepare rules", targ.Name())
+		prepare = false
+	}
+
+	if prepare {
+		stdout, stderr, flush := opts.phaseOutput(ctx, targ, logrus.WarnLevel)
+		err := opts.runPhase(ctx, "prepare", targ, func(ctx context.Context) error {
+			return opts.project.Prepare(
+				ctx,
+				targ, // Target-specific options
+				append(mopts,
+					make.WithExecOptions(append(opts.execEnv(),
+						exec.WithStdout(stdout),
+						exec.WithStderr(stderr),
+					)...),
+				)...,
+			)
+		})
+		flush()
+		if err != nil {
+			return err
+		}
+	}
+
+	stdout, stderr, flush := opts.phaseOutput(ctx, targ, logrus.WarnLevel)
+	defer flush()
+	if opts.diagnostics != nil {
+		stderr = io.MultiWriter(stderr, opts.diagnostics)
+	}
+
+	// The warnings of the target are counted in the build report.
+	warnings := &diagnosticCollector{}
+	stderr = io.MultiWriter(stderr, warnings)
+	defer func() {
+		if opts.warnings != nil {
+			opts.warnings[targ.Name()] = countWarnings(warnings.Diagnostics())
+		}
+	}()
+
+	err := opts.runPhase(ctx, "build", targ, func(ctx context.Context) error {
+		return opts.project.Build(
+			ctx,
+			targ, // Target-specific options
+			app.WithBuildMakeOptions(append(mopts,
+				make.WithExecOptions(append(opts.execEnv(),
+					exec.WithStdout(stdout),
+					exec.WithStderr(stderr),
+				)...),
+			)...),
+			app.WithBuildLogFile(opts.SaveBuildLog),
+		)
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(fingerprint) > 0 {
+		if err := writeBuildFingerprint(buildFingerprintPath(opts.workdir, targ.Name()), fingerprint); err != nil {
+			log.G(ctx).Warnf("could not record the fingerprint of %s: %v", targ.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// unchangedSinceLastBuild returns the fingerprint of building targ when
+// building incrementally, and whether its last successful build had the same,
+// such that it does not need to be configured and prepared again.  Otherwise,
+// the fingerprint of the last build is forgotten, as the configuration and the
+// prepared sources are about to change.
+func (opts *Build) unchangedSinceLastBuild(ctx context.Context, targ target.Target) (string, bool) {
+	if !opts.Incremental || opts.Spec != nil {
+		return "", false
+	}
+
+	path := buildFingerprintPath(opts.workdir, targ.Name())
+	fingerprint, err := buildFingerprint(
+		findKraftfile(opts.workdir, opts.Kraftfile),
+		opts.buildInputs([]target.Target{targ}, opts.versions),
+	)
+	if err != nil {
+		log.G(ctx).Debugf("could not fingerprint %s: %v", targ.Name(), err)
+		_ = os.Remove(path)
+		return "", false
+	}
+
+	ok, reason := fingerprintUnchanged(path, fingerprint, filepath.Join(opts.workdir, targ.ConfigFilename()))
+	if ok && !opts.ForceConfigure {
+		return fingerprint, true
+	}
+	if !ok {
+		log.G(ctx).Debugf("configuring and preparing %s: %s", targ.Name(), reason)
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.G(ctx).Warnf("could not forget the fingerprint of %s: %v", targ.Name(), err)
+	}
+
+	return fingerprint, false
+}
+
+// fetchUnchanged reports whether updating the package index and pulling the
+// components can be skipped when building incrementally, as the selected
+// targets are unchanged since their last build and the components they were
+// built with are still there.  The components of a project with a template
+// are only known once the template is pulled, so it is always fetched.
+func (opts *Build) fetchUnchanged(ctx context.Context, selected []target.Target) bool {
+	if !opts.Incremental || opts.Spec != nil || opts.ForcePull || opts.project.Template() != nil {
+		return false
+	}
+
+	components, err := opts.project.Components(ctx)
+	if err != nil {
+		return false
+	}
+
+	dirs := make([]string, 0, len(components))
+	versions := map[string]string{}
+	for _, component := range components {
+		dirs = append(dirs, component.Path())
+		versions[component.Name()] = component.Version()
+	}
+
+	kraftfile := findKraftfile(opts.workdir, opts.Kraftfile)
+	targets := make([]targetFingerprint, 0, len(selected))
+	for _, targ := range selected {
+		fingerprint, err := buildFingerprint(kraftfile, opts.buildInputs([]target.Target{targ}, versions))
+		if err != nil {
+			return false
+		}
+
+		targets = append(targets, targetFingerprint{
+			path:        buildFingerprintPath(opts.workdir, targ.Name()),
+			fingerprint: fingerprint,
+			dotconfig:   filepath.Join(opts.workdir, targ.ConfigFilename()),
+		})
+	}
+
+	ok, reason := sourcesUnchanged(dirs, targets)
+	if !ok {
+		log.G(ctx).Debugf("fetching the sources of the project: %s", reason)
+	}
+
+	return ok
+}
+
+// phaseOutput returns the writers of the standard output and error of a
+// phase of targ: the lines of Log prefixed by the target when it is set, the
+// logger otherwise, with errors logged at errLevel.  flush must be called once
+// the phase is over.
+func (opts *Build) phaseOutput(ctx context.Context, targ target.Target, errLevel logrus.Level) (stdout, stderr io.Writer, flush func()) {
+	if opts.Log == nil {
+		return log.G(ctx).Writer(), log.G(ctx).WriterLevel(errLevel), func() {}
+	}
+
+	prefix := BuildLogPrefix(targ.Name(), targ.Platform().Name(), targ.Architecture().Name())
+	out, errOut := opts.Log.Writer(prefix, false), opts.Log.Writer(prefix, true)
+
+	return out, errOut, func() {
+		out.Flush()
+		errOut.Flush()
+	}
+}
+
+// execEnv returns the options adding Env, CrossCompile and the PATH of the
+// make shim recording the build to the environment make runs with, which
+// otherwise inherits the environment of the plugin.
+func (opts *Build) execEnv() []exec.ExecOption {
+	env := makeEnv(opts.Env, opts.CrossCompile)
+	if opts.recorder != nil {
+		if env == nil {
+			env = map[string]string{}
 		}
+		env["PATH"] = opts.recorder.PathEnv()
+	}
+	if env == nil {
+		return nil
+	}
 
-		missingPacks = append(missingPacks, p...)
+	return []exec.ExecOption{
+		exec.WithOSEnv(true),
+		exec.WithEnv(env),
 	}
+}
 
-	if len(missingPacks) > 0 {
-		for _, p := range missingPacks {
-			p := p // loop closure
-			auths := auths
-			p.Pull(
-				ctx,
-				pack.WithPullWorkdir(opts.workdir),
-				// pack.WithPullChecksum(!opts.NoChecksum),
-				pack.WithPullCache(!opts.NoCache),
-				pack.WithPullAuthConfig(auths),
-			)
-		}
+// extraKConfig returns KConfig as the extra configuration options of the
+// configure phase.
+func (opts *Build) extraKConfig() kconfig.KeyValueMap {
+	if len(opts.KConfig) == 0 {
+		return nil
 	}
 
-	return nil
+	values := kconfig.KeyValueMap{}
+	for k, v := range opts.KConfig {
+		values.Set(kconfigSymbol(k), v)
+	}
+
+	return values
 }
 
-func (opts *Build) BuildCmd(ctx context.Context, args ...string) error {
-	var err error
+// targetKConfig returns the symbols set by a target, with KConfig merged.
+func (opts *Build) targetKConfig(targ target.Target) map[string]string {
+	symbols := map[string]string{}
+	for k, v := range targ.KConfig() {
+		symbols[kconfigSymbol(k)] = v.Value
+	}
+	for k, v := range opts.KConfig {
+		symbols[kconfigSymbol(k)] = v
+	}
 
-	if len(args) == 0 {
-		opts.workdir, err = os.Getwd()
-		if err != nil {
-			return err
-		}
-	} else {
-		opts.workdir = args[0]
+	return symbols
+}
+
+// reuseDotConfig reports whether the existing configuration of a target is
+// compatible with it, such that configuring the target again can be skipped.
+func (opts *Build) reuseDotConfig(ctx context.Context, targ target.Target) bool {
+	if opts.ForceConfigure {
+		return false
 	}
 
-	popts := []app.ProjectOption{
-		app.WithProjectWorkdir(opts.workdir),
+	ok, reason := dotConfigCompatible(
+		filepath.Join(opts.workdir, targ.ConfigFilename()),
+		findKraftfile(opts.workdir, opts.Kraftfile),
+		targ.Architecture().Name(),
+		targ.Platform().Name(),
+		opts.targetKConfig(targ),
+	)
+	if !ok {
+		log.G(ctx).Debugf("configuring %s: %s", targ.Name(), reason)
+		return false
 	}
 
-	if len(opts.Kraftfile) > 0 {
-		popts = append(popts, app.WithProjectKraftfile(opts.Kraftfile))
-	} else {
-		popts = append(popts, app.WithProjectDefaultKraftfiles())
+	log.G(ctx).Infof("reusing existing configuration of %s", targ.Name())
+	return true
+}
+
+// componentVersions returns the versions of the components of the project,
+// keyed by their name.
+func (opts *Build) componentVersions(ctx context.Context) map[string]string {
+	versions := map[string]string{}
+
+	components, err := opts.project.Components(ctx)
+	if err != nil {
+		return versions
 	}
 
-	// Initialize at least the configuration options for a project
-	opts.project, err = app.NewProjectFromOptions(ctx, popts...)
-	if err != nil && errors.Is(err, app.ErrNoKraftfile) {
-		return fmt.Errorf("cannot build project directory without a Kraftfile")
-	} else if err != nil {
-		return fmt.Errorf("could not initialize project directory: %w", err)
+	for _, component := range components {
+		versions[component.Name()] = component.Version()
 	}
 
-	opts.Platform = platform.PlatformByName(opts.Platform).String()
+	return versions
+}
 
-	selected := opts.project.Targets()
-	if len(selected) == 0 {
-		return fmt.Errorf("no targets to build")
+// componentDirs returns the directories of the project and of its
+// components, whose Makefile.uk files register the prepare rules.
+func (opts *Build) componentDirs(ctx context.Context) []string {
+	dirs := []string{opts.workdir}
+
+	components, err := opts.project.Components(ctx)
+	if err != nil {
+		// Without the components, the prepare phase cannot be told a no-op.
+		return append(dirs, "")
 	}
-	if !opts.All {
-		selected = target.Filter(
-			selected,
-			opts.Architecture,
-			opts.Platform,
-			opts.Target,
-		)
 
-		if !config.G[config.KraftKit](ctx).NoPrompt {
-			res, err := target.Select(selected)
-			if err != nil {
-				return err
-			}
-			selected = []target.Target{res}
-		}
+	for _, component := range components {
+		dirs = append(dirs, component.Path())
 	}
 
-	if len(selected) == 0 {
-		return fmt.Errorf("no targets selected to build")
+	return dirs
+}
+
+// buildInputs returns the resolved inputs of building the selected targets.
+func (opts *Build) buildInputs(selected []target.Target, versions map[string]string) BuildInputs {
+	in := BuildInputs{
+		Components: versions,
+		Options: map[string]string{
+			"kernel_dbg": strconv.FormatBool(opts.KernelDbg),
+		},
 	}
 
-	if opts.ForcePull || !opts.NoUpdate {
-		err := packmanager.G(ctx).Update(ctx)
+	if len(opts.DotConfig) > 0 {
+		digest, err := fileDigest(opts.DotConfig)
 		if err != nil {
-			return err
+			// The build fails on its own when the file cannot be read.
+			digest = opts.DotConfig
 		}
+		in.Options["dotconfig"] = digest
 	}
 
-	if err := opts.pull(ctx); err != nil {
-		return err
+	for _, targ := range selected {
+		in.Targets = append(in.Targets, BuildInputTarget{
+			Name:         targ.Name(),
+			Architecture: targ.Architecture().Name(),
+			Platform:     targ.Platform().Name(),
+			KConfig:      opts.targetKConfig(targ),
+		})
 	}
 
-	var mopts []make.MakeOption
-	if opts.Jobs > 0 {
-		mopts = append(mopts, make.WithJobs(opts.Jobs))
-	} else {
-		mopts = append(mopts, make.WithMaxJobs(!opts.NoFast && !config.G[config.KraftKit](ctx).NoParallel))
+	return in
+}
+
+// ID returns the identifier of the last build, derived from its resolved
+// inputs.
+func (opts *Build) ID() string {
+	return opts.id
+}
+
+// skipPhase reports whether a phase disabled by flag should be skipped for the
+// target with the given name.  The flag applies to every target unless it is
+// restricted to a list of target names.
+func skipPhase(flag bool, targets []string, name string) bool {
+	if !flag {
+		return false
 	}
 
-	for _, targ := range selected {
-		// See: https://github.com/golang/go/wiki/CommonMistakes#using-reference-to-loop-iterator-variable
-		targ := targ
-		if !opts.NoConfigure {
-			err := opts.project.Configure(
-				ctx,
-				targ, // Target-specific options
-				nil,  // No extra configuration options
-				make.WithSilent(true),
-				make.WithExecOptions(
-					exec.WithStdin(iostreams.G(ctx).In),
-					exec.WithStdout(log.G(ctx).Writer()),
-					exec.WithStderr(log.G(ctx).WriterLevel(logrus.ErrorLevel)),
-				),
-			)
-			if err != nil {
-				return err
-			}
-		}
+	if len(targets) == 0 {
+		return true
+	}
 
-		err := opts.project.Build(
-			ctx,
-			targ, // Target-specific options
-			app.WithBuildMakeOptions(append(mopts,
-				make.WithExecOptions(
-					exec.WithStdout(log.G(ctx).Writer()),
-					exec.WithStderr(log.G(ctx).WriterLevel(logrus.WarnLevel)),
-					// exec.WithOSEnv(true),
-				),
-			)...),
-			app.WithBuildLogFile(opts.SaveBuildLog),
-		)
-		if err != nil {
-			return err
+	for _, t := range targets {
+		if t == name {
+			return true
 		}
 	}
 
-	return nil
+	return false
 }
 
 type Pkg struct {
@@ -318,8 +1576,100 @@ type Pkg struct {
 	Target       string
 	Workdir      string
 
+	// TargetFormat, when set, only packages the targets whose native package
+	// format matches.
+	TargetFormat string
+
+	// Spec, when set and Project is not, is the project packaged instead of
+	// the Kraftfile of the workdir.
+	Spec *ProjectSpec
+
+	// RootfsPermissions, when set, is applied to the entries of the initramfs
+	// built from the rootfs.
+	RootfsPermissions *InitrdPermissions
+
+	// InitrdManifest is the path of a JSON object mapping every file of the
+	// initramfs to its digest.  Packaging fails when the initramfs has
+	// missing, extra or changed files.
+	InitrdManifest string
+
+	// FormatTools lists, per format, external tools required in addition to
+	// DefaultFormatTools.
+	FormatTools map[string][]string
+	// MaxKernelSize fails packaging when a kernel is larger than this many
+	// bytes.  It is unlimited when not positive.
+	MaxKernelSize int64
+	// MaxKernelSizes overrides MaxKernelSize for the named targets.
+	MaxKernelSizes map[string]int64
+
+	// SigningKey is the path of a PEM encoded Ed25519 private key used to sign
+	// the package written to Output.  The checksum of the package is always
+	// recomputed, since the one of a previous packaging no longer applies.
+	SigningKey string
+
+	// Volumes are the `source:destination[:driver]` volumes required by the
+	// packaged targets.  The host source of each must be readable.  They are
+	// declared in the VolumesAnnotation of the package once pushed.
+	Volumes []string
+
+	// NoPreflight skips checking that the tools required by the format are
+	// available before packaging.
+	NoPreflight bool
+
+	// FormatOptions are options specific to Format, passed to the package
+	// manager.  Options the format does not know are ignored with a warning.
+	FormatOptions map[string]string
+
+	// DiskFormat is the format of the image written when Format is
+	// FormatDisk: `raw`, `qcow2` or `vmdk`.  Defaults to `raw`.
+	DiskFormat string
+	// DiskSize is the size in MiB of the image written when Format is
+	// FormatDisk.  The image is as small as possible when not positive.
+	DiskSize int
+
+	// SBOM, when set, is the format of a software bill of materials of the
+	// packaged components written to SBOMOutput: `spdx` or `cyclonedx`.
+	SBOM       string
+	SBOMOutput string
+
 	packopts []packmanager.PackOption
 	pm       packmanager.PackageManager
+	seals    []PackageSeal
+	// targets, when set, are the targets to package instead of those
+	// selected from the project.
+	targets []target.Target
+}
+
+// Seals returns the checksums and signatures of the package files written by
+// the last call to PackCmd.
+func (opts *Pkg) Seals() []PackageSeal {
+	return opts.seals
+}
+
+// seal recomputes the checksum of the package written to Output and signs it
+// when a signing key is configured.
+func (opts *Pkg) seal(ctx context.Context) error {
+	opts.seals = nil
+	if len(opts.Output) == 0 {
+		return nil
+	}
+
+	if stat, err := os.Stat(opts.Output); err != nil || !stat.Mode().IsRegular() {
+		return nil
+	}
+
+	seal, err := sealPackage(opts.Output, opts.SigningKey)
+	if err != nil {
+		return err
+	}
+
+	if seal.StaleSignature {
+		log.G(ctx).Warnf("%s was signed but no signing key was provided: the new package is not signed", opts.Output)
+	}
+
+	opts.seals = append(opts.seals, *seal)
+
+	return nil
 }
 
 // buildRootfs generates a rootfs based on the provided path
@@ -345,12 +1695,146 @@ func (opts *Pkg) buildRootfs(ctx context.Context) error {
 		return err
 	}
 
+	if opts.RootfsPermissions != nil {
+		staged := filepath.Join(opts.Workdir, unikraft.BuildDir, "initramfs-staged.cpio")
+		if err := stageInitrdPermissions(opts.Rootfs, staged, opts.RootfsPermissions); err != nil {
+			return err
+		}
+
+		opts.Rootfs = staged
+	}
+
+	if len(opts.InitrdManifest) > 0 {
+		expected, err := LoadInitrdManifest(opts.InitrdManifest)
+		if err != nil {
+			return err
+		}
+
+		actual, err := initrdDigests(opts.Rootfs)
+		if err != nil {
+			return err
+		}
+
+		if err := verifyInitrdManifest(actual, expected); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// NewProjectFromSpec returns the project described by spec, built in workdir,
+// without reading a Kraftfile.
+func NewProjectFromSpec(ctx context.Context, workdir string, spec ProjectSpec) (app.Application, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+
+	uk, err := core.NewUnikraftFromOptions(ctx,
+		core.WithVersion(spec.Unikraft.Version),
+		core.WithSource(spec.Unikraft.Source),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize unikraft core: %w", err)
+	}
+
+	libraries := lib.Libraries{}
+	for _, l := range spec.Libraries {
+		library, err := lib.NewLibraryFromOptions(ctx,
+			lib.WithName(l.Name),
+			lib.WithVersion(l.Version),
+			lib.WithSource(l.Source),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize library %s: %w", l.Name, err)
+		}
+
+		libraries[l.Name] = library
+	}
+
+	targets := make([]target.Target, 0, len(spec.Targets))
+	for _, d := range spec.Targets {
+		t, err := newTargetFromDefinition(d)
+		if err != nil {
+			return nil, err
+		}
+
+		targets = append(targets, t)
+	}
+
+	var values []*kconfig.KeyValue
+	for k, v := range spec.Config {
+		values = append(values, &kconfig.KeyValue{Key: k, Value: v})
+	}
+
+	project, err := app.NewApplicationFromOptions(
+		app.WithName(spec.Name),
+		app.WithWorkingDir(workdir),
+		app.WithUnikraft(uk),
+		app.WithLibraries(libraries),
+		app.WithTargets(targets),
+		app.WithConfiguration(values...),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize project %s: %w", spec.Name, err)
+	}
+
+	return project, nil
+}
+
+// NewProjectFromKernel returns a project of a single target whose kernel is
+// the imported kernel k, such that it can be packaged without being built.
+func NewProjectFromKernel(ctx context.Context, workdir string, k ImportedKernel) (app.Application, error) {
+	if err := k.Validate(); err != nil {
+		return nil, err
+	}
+
+	a, err := arch.NewArchitectureFromOptions(arch.WithName(k.Architecture))
+	if err != nil {
+		return nil, fmt.Errorf("target %s: %w", k.TargetName(), err)
+	}
+
+	p, err := plat.NewPlatformFromOptions(plat.WithName(k.Platform))
+	if err != nil {
+		return nil, fmt.Errorf("target %s: %w", k.TargetName(), err)
+	}
+
+	t, err := target.NewTargetFromOptions(
+		target.WithName(k.TargetName()),
+		target.WithArchitecture(a),
+		target.WithPlatform(p),
+		target.WithKernel(k.Kernel),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("target %s: %w", k.TargetName(), err)
+	}
+
+	uk, err := core.NewUnikraftFromOptions(ctx, core.WithVersion(k.UnikraftVersion))
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize unikraft core: %w", err)
+	}
+
+	project, err := app.NewApplicationFromOptions(
+		app.WithName(k.TargetName()),
+		app.WithWorkingDir(workdir),
+		app.WithUnikraft(uk),
+		app.WithTargets([]target.Target{t}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize project of %s: %w", k.Kernel, err)
+	}
+
+	return project, nil
+}
+
 func (opts *Pkg) initProject(ctx context.Context) error {
 	var err error
 
+	if opts.Spec != nil {
+		opts.Project, err = NewProjectFromSpec(ctx, opts.Workdir, *opts.Spec)
+		return err
+	}
+
 	popts := []app.ProjectOption{
 		app.WithProjectWorkdir(opts.Workdir),
 	}
@@ -361,6 +1845,10 @@ func (opts *Pkg) initProject(ctx context.Context) error {
 		popts = append(popts, app.WithProjectDefaultKraftfiles())
 	}
 
+	if err := checkKraftfileSpec(opts.Workdir, opts.Kraftfile); err != nil {
+		return err
+	}
+
 	// Interpret the project directory
 	opts.Project, err = app.NewProjectFromOptions(ctx, popts...)
 	if err != nil {
@@ -370,6 +1858,152 @@ func (opts *Pkg) initProject(ctx context.Context) error {
 	return nil
 }
 
+// packOptions returns the pack options overriding the defaults of Pkg.
+func (o formatPackOptions) packOptions() []packmanager.PackOption {
+	var popts []packmanager.PackOption
+	if o.Args != nil {
+		popts = append(popts, packmanager.PackArgs(o.Args...))
+	}
+	if o.KConfig != nil {
+		popts = append(popts, packmanager.PackKConfig(*o.KConfig))
+	}
+	if len(o.KernelVersion) > 0 {
+		popts = append(popts, packmanager.PackWithKernelVersion(o.KernelVersion))
+	}
+
+	return popts
+}
+
+// selectedTargets returns the targets to package, before prompting.
+func (opts *Pkg) selectedTargets() []target.Target {
+	if opts.targets != nil {
+		return opts.targets
+	}
+
+	if len(opts.Target) > 0 || len(opts.Architecture) > 0 || len(opts.Platform) > 0 || len(opts.TargetFormat) > 0 {
+		return FilterTargets(opts.Project.Targets(), TargetFilter{
+			Architecture: opts.Architecture,
+			Platform:     opts.Platform,
+			Target:       opts.Target,
+			Format:       opts.TargetFormat,
+		})
+	}
+
+	return opts.Project.Targets()
+}
+
+// packDisk writes the kernel of the selected target as a bootable disk image
+// to Output, or to Name when Output is not set.
+func (opts *Pkg) packDisk(ctx context.Context) error {
+	if opts.Push {
+		return fmt.Errorf("cannot push disk images")
+	}
+
+	if opts.DiskFormat != "" && opts.DiskFormat != "raw" && !opts.NoPreflight {
+		if err := formatToolsOnHost(opts.DiskFormat, nil); err != nil {
+			return err
+		}
+	}
+
+	if err := opts.buildRootfs(ctx); err != nil {
+		return fmt.Errorf("could not build rootfs: %w", err)
+	}
+
+	selected := opts.selectedTargets()
+	if len(selected) != 1 {
+		return fmt.Errorf("disk images package a single target, %d selected", len(selected))
+	}
+
+	targ := selected[0]
+	if targ.Architecture().Name() != "x86_64" || targ.Platform().Name() != "qemu" {
+		return fmt.Errorf("cannot package %s as a disk image: only qemu/x86_64 kernels boot with GRUB", targ.Name())
+	}
+
+	args := opts.Args
+	if len(args) == 0 {
+		args = targ.Command()
+	}
+
+	output := opts.Output
+	if output == "" {
+		output = opts.Name
+	}
+
+	format := opts.DiskFormat
+	if format == "" {
+		format = "raw"
+	}
+
+	log.G(ctx).Infof("writing %s disk image of %s to %s", format, targ.Name(), output)
+
+	return DiskImage{
+		Kernel:  targ.Kernel(),
+		Initrd:  opts.Rootfs,
+		Cmdline: strings.Join(args, " "),
+		Format:  format,
+		SizeMiB: opts.DiskSize,
+	}.Write(ctx, output)
+}
+
+// writeSBOM writes the software bill of materials of the project, its
+// components and the kernels of targets to SBOMOutput, when SBOM is set.
+func (opts *Pkg) writeSBOM(ctx context.Context, targets []target.Target) error {
+	if len(opts.SBOM) == 0 {
+		return nil
+	}
+
+	components, err := opts.Project.Components(ctx)
+	if err != nil {
+		return err
+	}
+
+	bom := SBOM{
+		Name:    opts.Name,
+		Created: time.Now(),
+		Components: []SBOMComponent{{
+			Type:   "app",
+			Name:   opts.Project.Name(),
+			Source: opts.Workdir,
+		}},
+	}
+
+	for _, c := range components {
+		entry := SBOMComponent{
+			Type:    string(c.Type()),
+			Name:    c.Name(),
+			Version: c.Version(),
+			Source:  c.Source(),
+		}
+
+		// Components are only digested once pulled.
+		if stat, err := os.Stat(c.Path()); err == nil && stat.IsDir() {
+			entry.Digest, err = dirDigest(c.Path())
+			if err != nil {
+				return fmt.Errorf("could not compute digest of %s: %w", c.Name(), err)
+			}
+		}
+
+		bom.Components = append(bom.Components, entry)
+	}
+
+	for _, targ := range targets {
+		digest, err := fileDigest(targ.Kernel())
+		if err != nil {
+			return fmt.Errorf("could not compute digest of %s: %w", targ.Kernel(), err)
+		}
+
+		bom.Components = append(bom.Components, SBOMComponent{
+			Type:   "kernel",
+			Name:   targ.Name(),
+			Digest: digest,
+		})
+	}
+
+	log.G(ctx).Infof("writing %s SBOM of %s to %s", opts.SBOM, opts.Name, opts.SBOMOutput)
+
+	return bom.WriteFile(opts.SBOMOutput, opts.SBOM)
+}
+
 func (opts *Pkg) PackCmd(ctx context.Context, args ...string) ([]pack.Package, error) {
 	var err error
 
@@ -406,11 +2040,33 @@ func (opts *Pkg) PackCmd(ctx context.Context, args ...string) ([]pack.Package, e
 		return nil, fmt.Errorf("the `--arch` and `--plat` options are not supported in addition to `--target`")
 	}
 
-	if config.G[config.KraftKit](ctx).NoPrompt && opts.Strategy == packmanager.StrategyPrompt {
-		return nil, fmt.Errorf("cannot mix --strategy=prompt when --no-prompt is enabled in settings")
-	}
+	if config.G[config.KraftKit](ctx).NoPrompt && opts.Strategy == packmanager.StrategyPrompt {
+		return nil, fmt.Errorf("cannot mix --strategy=prompt when --no-prompt is enabled in settings")
+	}
+
+	opts.Platform = platform.PlatformByName(opts.Platform).String()
+
+	if len(opts.Format) > 0 && !opts.NoPreflight {
+		if err := formatToolsOnHost(opts.Format, opts.FormatTools); err != nil {
+			return nil, err
+		}
+	}
+
+	formatOptions, unknown, err := parseFormatOptions(opts.Format, opts.FormatOptions)
+	if err != nil {
+		return nil, err
+	}
+	if len(unknown) > 0 {
+		log.G(ctx).Warnf("ignoring unknown %s format options: %s", opts.Format, strings.Join(unknown, ", "))
+	}
+
+	if opts.Format == FormatDisk {
+		if err := opts.packDisk(ctx); err != nil {
+			return nil, err
+		}
 
-	opts.Platform = platform.PlatformByName(opts.Platform).String()
+		return nil, opts.writeSBOM(ctx, opts.selectedTargets())
+	}
 
 	if len(opts.Format) > 0 {
 		// Switch the package manager the desired format for this target
@@ -459,12 +2115,8 @@ func (opts *Pkg) PackCmd(ctx context.Context, args ...string) ([]pack.Package, e
 		return nil, fmt.Errorf("could not build rootfs: %w", err)
 	}
 
-	selected := opts.Project.Targets()
-	if len(opts.Target) > 0 || len(opts.Architecture) > 0 || len(opts.Platform) > 0 {
-		selected = target.Filter(opts.Project.Targets(), opts.Architecture, opts.Platform, opts.Target)
-	}
-
-	if len(selected) > 1 && !config.G[config.KraftKit](ctx).NoPrompt {
+	selected := opts.selectedTargets()
+	if len(selected) > 1 && opts.targets == nil && !config.G[config.KraftKit](ctx).NoPrompt {
 		selected, err = multiselect.MultiSelect[target.Target]("select what to package", opts.Project.Targets()...)
 		if err != nil {
 			return nil, err
@@ -475,6 +2127,36 @@ func (opts *Pkg) PackCmd(ctx context.Context, args ...string) ([]pack.Package, e
 		return nil, fmt.Errorf("nothing selected to package")
 	}
 
+	var annotations map[string]string
+	if len(opts.Volumes) > 0 {
+		volumes, err := ParseVolumes("", opts.Volumes)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, targ := range selected {
+			if err := validateVolumes(targ.Platform().Name(), volumes); err != nil {
+				return nil, fmt.Errorf("cannot package %s: %w", targ.Name(), err)
+			}
+		}
+
+		if err := checkVolumeSources(volumes); err != nil {
+			return nil, err
+		}
+
+		annotations, err = VolumeAnnotations(volumes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, targ := range selected {
+		budget := kernelSizeBudget(targ.Name(), opts.MaxKernelSize, opts.MaxKernelSizes)
+		if err := checkKernelSize(targ.Kernel(), budget); err != nil {
+			return nil, fmt.Errorf("cannot package %s: %w", targ.Name(), err)
+		}
+	}
+
 	i := 0
 
 	var result []pack.Package
@@ -521,6 +2203,8 @@ func (opts *Pkg) PackCmd(ctx context.Context, args ...string) ([]pack.Package, e
 			)
 		}
 
+		popts = append(popts, formatOptions.packOptions()...)
+
 		more, err := opts.pm.Pack(ctx, targ, popts...)
 		if err != nil {
 			return nil, err
@@ -542,6 +2226,14 @@ func (opts *Pkg) PackCmd(ctx context.Context, args ...string) ([]pack.Package, e
 		}
 	}
 
+	if err := opts.seal(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := opts.writeSBOM(ctx, selected); err != nil {
+		return nil, err
+	}
+
 	if opts.Push {
 		for _, p := range result {
 			err := p.Push(ctx)
@@ -549,11 +2241,39 @@ func (opts *Pkg) PackCmd(ctx context.Context, args ...string) ([]pack.Package, e
 				return nil, err
 			}
 		}
+
+		if err := annotatePackage(ctx, opts.Name, annotations, authn.DefaultKeychain); err != nil {
+			return nil, err
+		}
 	}
 
 	return result, nil
 }
 
+// annotatePackage adds annotations to the package pushed as pkg.
+func annotatePackage(ctx context.Context, pkg string, annotations map[string]string, keychain authn.Keychain) error {
+	if len(annotations) == 0 {
+		return nil
+	}
+
+	ref, err := name.ParseReference(pkg)
+	if err != nil {
+		return fmt.Errorf("invalid package reference %s: %w", pkg, err)
+	}
+
+	err = annotatePushed(ref, annotations,
+		remote.WithContext(ctx),
+		remote.WithAuthFromKeychain(keychain),
+	)
+	if err != nil {
+		return fmt.Errorf("could not annotate %s: %w", pkg, err)
+	}
+
+	log.G(ctx).Infof("annotated %s", pkg)
+
+	return nil
+}
+
 type Clean struct {
 	Architecture string
 	Kraftfile    string
@@ -595,12 +2315,11 @@ func (opts *Clean) CleanCmd(ctx context.Context, args []string) error {
 	}
 
 	// Filter project targets by any provided CLI options
-	targets := target.Filter(
-		project.Targets(),
-		opts.Architecture,
-		opts.Platform,
-		opts.Target,
-	)
+	targets := FilterTargets(project.Targets(), TargetFilter{
+		Architecture: opts.Architecture,
+		Platform:     opts.Platform,
+		Target:       opts.Target,
+	})
 
 	t, err := target.Select(targets)
 	if err != nil {
@@ -614,24 +2333,309 @@ func (opts *Clean) CleanCmd(ctx context.Context, args []string) error {
 	return project.Clean(ctx, t)
 }
 
+// PushPkg pushes a package built by Pkg to its registry.
+type PushPkg struct {
+	// Name is the reference of the package, e.g.
+	// `unikraft.org/helloworld:latest`.
+	Name string
+	// Tags are the additional tags of the pushed package in the registry.
+	Tags []string
+	// Username and Password authenticate against the registry of the package
+	// instead of the KraftKit configuration, when set.
+	Username string
+	Password string
+	// Annotations are added to the package once pushed, before it is tagged.
+	Annotations map[string]string
+}
+
+// PushCmd pushes the package and tags it in the registry.  It returns the
+// references the package was pushed as.
+func (opts *PushPkg) PushCmd(ctx context.Context) ([]string, error) {
+	ref, tags, err := pushReferences(opts.Name, opts.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	keychain := authn.DefaultKeychain
+	if len(opts.Username) > 0 {
+		registry := ref.Context().RegistryStr()
+		cfg := config.G[config.KraftKit](ctx)
+		if cfg.Auth == nil {
+			cfg.Auth = map[string]config.AuthConfig{}
+		}
+		cfg.Auth[registry] = config.AuthConfig{
+			Endpoint:  registry,
+			User:      opts.Username,
+			Token:     opts.Password,
+			VerifySSL: true,
+		}
+
+		keychain = staticKeychain{authn.FromConfig(authn.AuthConfig{
+			Username: opts.Username,
+			Password: opts.Password,
+		})}
+	}
+
+	packages, err := packmanager.G(ctx).Catalog(ctx,
+		packmanager.WithName(opts.Name),
+		packmanager.WithUpdate(false),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(packages) == 0 {
+		return nil, fmt.Errorf("could not find package %s, it must be packaged before it is pushed", opts.Name)
+	}
+
+	for _, p := range packages {
+		if err := p.Push(ctx); err != nil {
+			return nil, fmt.Errorf("could not push %s: %w", opts.Name, err)
+		}
+	}
+
+	if err := annotatePackage(ctx, opts.Name, opts.Annotations, keychain); err != nil {
+		return nil, err
+	}
+
+	pushed := []string{ref.Name()}
+	if len(tags) == 0 {
+		return pushed, nil
+	}
+
+	desc, err := remote.Get(ref,
+		remote.WithContext(ctx),
+		remote.WithAuthFromKeychain(keychain),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not get pushed package %s: %w", opts.Name, err)
+	}
+
+	for _, tag := range tags {
+		err := remote.Tag(tag, desc,
+			remote.WithContext(ctx),
+			remote.WithAuthFromKeychain(keychain),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("could not tag %s: %w", tag, err)
+		}
+
+		log.G(ctx).Infof("tagged %s", tag)
+		pushed = append(pushed, tag.Name())
+	}
+
+	return pushed, nil
+}
+
+// staticKeychain resolves every registry to the same credentials.
+type staticKeychain struct {
+	auth authn.Authenticator
+}
+
+func (k staticKeychain) Resolve(authn.Resource) (authn.Authenticator, error) {
+	return k.auth, nil
+}
+
+// BootTest boots a built kernel under QEMU and waits for it to pass a boot
+// check.
+type BootTest struct {
+	Architecture string
+	Kernel       string
+	// Cmdline, when set, is the command line the application is booted
+	// with.
+	Cmdline string
+	Check   BootCheck
+}
+
+// BootCmd boots the kernel, watches its console and exit code, and removes
+// the machine once the check passed or failed.
+func (opts *BootTest) BootCmd(ctx context.Context) error {
+	args, err := shellwords.Parse(opts.Cmdline)
+	if err != nil {
+		return fmt.Errorf("invalid cmdline: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	service, err := qemu.NewMachineV1alpha1Service(ctx)
+	if err != nil {
+		return fmt.Errorf("could not initialize qemu: %w", err)
+	}
+
+	uid := uuid.NewUUID()
+	machine := &machineapi.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "packer-boot-test-" + string(uid)[:8],
+			UID:  uid,
+		},
+		Spec: machineapi.MachineSpec{
+			Architecture:    opts.Architecture,
+			Platform:        "qemu",
+			ApplicationArgs: args,
+		},
+		Status: machineapi.MachineStatus{
+			KernelPath: opts.Kernel,
+		},
+	}
+	machine.Status.StateDir = filepath.Join(config.G[config.KraftKit](ctx).RuntimeDir, string(uid))
+	if err := os.MkdirAll(machine.Status.StateDir, 0o755); err != nil {
+		return err
+	}
+	defer os.RemoveAll(machine.Status.StateDir)
+
+	machine, err = service.Create(ctx, machine)
+	if err != nil {
+		return fmt.Errorf("could not create machine: %w", err)
+	}
+	defer func() {
+		if _, err := service.Delete(context.Background(), machine); err != nil {
+			log.G(ctx).Warnf("could not remove machine: %v", err)
+		}
+	}()
+
+	machine, err = service.Start(ctx, machine)
+	if err != nil {
+		return fmt.Errorf("could not start machine: %w", err)
+	}
+
+	console, consoleErrs, err := service.Logs(ctx, machine)
+	if err != nil {
+		return fmt.Errorf("could not read machine console: %w", err)
+	}
+
+	events, eventErrs, err := service.Watch(ctx, machine)
+	if err != nil {
+		return fmt.Errorf("could not watch machine: %w", err)
+	}
+
+	exited := make(chan int, 1)
+	go func() {
+		for {
+			select {
+			case m, ok := <-events:
+				if !ok {
+					return
+				}
+
+				switch m.Status.State {
+				case machineapi.MachineStateExited, machineapi.MachineStateErrored:
+					exited <- m.Status.ExitCode
+					return
+				}
+			case err, ok := <-consoleErrs:
+				if !ok {
+					consoleErrs = nil
+					continue
+				}
+				log.G(ctx).Debugf("reading machine console: %v", err)
+			case err, ok := <-eventErrs:
+				if !ok {
+					eventErrs = nil
+					continue
+				}
+				log.G(ctx).Debugf("watching machine: %v", err)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return watchBoot(ctx, console, exited, opts.Check)
+}
+
+// Rootfs constructs an initramfs from a directory or a Dockerfile.
+type Rootfs struct {
+	Source  string
+	Output  string
+	Workdir string
+
+	// BuildKitHost, when set, is the address of the BuildKit daemon building
+	// a Dockerfile source.
+	BuildKitHost string
+}
+
+func (opts *Rootfs) RootfsCmd(ctx context.Context) error {
+	if len(opts.BuildKitHost) > 0 {
+		config.G[config.KraftKit](ctx).BuildKitHost = opts.BuildKitHost
+	}
+
+	ramfs, err := initrd.New(ctx, opts.Source,
+		initrd.WithOutput(opts.Output),
+		initrd.WithCacheDir(filepath.Join(opts.Workdir, unikraft.VendorDir, "rootfs-cache")),
+	)
+	if err != nil {
+		return fmt.Errorf("could not prepare initramfs: %w", err)
+	}
+
+	if _, err := ramfs.Build(ctx); err != nil {
+		return fmt.Errorf("could not build initramfs: %w", err)
+	}
+
+	return nil
+}
+
 type Pull struct {
 	All          bool
 	Architecture string
 	ForceCache   bool
 	Kraftfile    string
 	Manager      string
-	NoChecksum   bool
 	NoDeps       bool
 	Platform     string
 	WithDeps     bool
 	Workdir      string
 	KConfig      []string
+
+	// ChecksumPolicy is how the checksums of the pulled components are
+	// verified, one of ChecksumPolicies.  Defaults to ChecksumEnforce.
+	ChecksumPolicy string
+	// Resolution picks the package of the template when the catalog returns
+	// several.
+	Resolution CandidatePolicy
+
+	// PortableCache, when set, pulls every component into a relocatable cache
+	// rooted at this directory instead of the workdir.  The components the
+	// cache already holds are used as they are.
+	PortableCache string
+
+	// Output, when set, places the pulled components in this directory
+	// instead of the workdir, e.g. to inspect them.
+	Output string
+
+	// RateLimit caps the bandwidth of all downloads, in bytes per second.  It
+	// is unlimited when not positive.
+	RateLimit int64
+	// Mirrors maps the upstream URLs components are downloaded from to the
+	// mirrors they are downloaded from instead.
+	Mirrors map[string]string
+	// Proxy are the proxies components are downloaded through.
+	Proxy ProxyConfig
+	// Auth are the credentials of the private sources components are
+	// pulled from.
+	Auth []SourceAuth
+
+	// Concurrency is the maximum number of components queried and pulled at
+	// the same time.  Components are pulled one at a time when not positive.
+	Concurrency int
+
+	// Retries is the number of times a catalog query or pull is retried when
+	// it fails with a transient error.
+	Retries int
+	// RetryBackoff is the delay before the first retry.  It doubles for
+	// every further retry, up to DefaultRetryMaxBackoff.
+	RetryBackoff time.Duration
 }
 
 func (opts *Pull) PullCmd(ctx context.Context, args []string) error {
 	var err error
 	var project app.Application
 
+	defer registerSourceAuth(ctx, opts.Auth)()
+	downloads := &DownloadSizes{}
+	client := &http.Client{Transport: newDownloadTransport(opts.Mirrors, opts.Proxy, opts.Auth, NewRateLimiter(opts.RateLimit))}
+	gitAuth := gitSSHAuth(opts.Auth)
+
 	workdir := opts.Workdir
 	if len(workdir) == 0 {
 		workdir, err = os.Getwd()
@@ -712,23 +2716,27 @@ func (opts *Pull) PullCmd(ctx context.Context, args []string) error {
 				packmanager.WithUpdate(opts.ForceCache),
 				packmanager.WithPlatform(opts.Platform),
 				packmanager.WithArchitecture(opts.Architecture),
+				packmanager.WithHTTPClient(client),
+				packmanager.WithGitSSHAuth(gitAuth),
 			)
 			if err != nil {
 				return err
 			}
 
-			if len(packages) == 0 {
-				return fmt.Errorf("could not find: %s", unikraft.TypeNameVersion(project.Template()))
-			} else if len(packages) > 1 {
-				return fmt.Errorf("too many options for %s", unikraft.TypeNameVersion(project.Template()))
+			templatePack, err := resolveCandidate(ctx, opts.Resolution, unikraft.TypeNameVersion(project.Template()), packages)
+			if err != nil {
+				return err
 			}
 
-			err := packages[0].Pull(
-				ctx,
-				pack.WithPullWorkdir(workdir),
-				// pack.WithPullChecksum(!opts.NoChecksum),
-				// pack.WithPullCache(!opts.NoCache),
-			)
+			err = pullVerified(opts.ChecksumPolicy, templatePack.Name(), logWarn(ctx), func(checksum bool) error {
+				return templatePack.Pull(
+					ctx,
+					pack.WithPullWorkdir(workdir),
+					pack.WithPullChecksum(checksum),
+					pack.WithPullHTTPClient(client),
+					pack.WithPullGitSSHAuth(gitAuth),
+				)
+			})
 			if err != nil {
 				return err
 			}
@@ -768,6 +2776,8 @@ func (opts *Pull) PullCmd(ctx context.Context, args []string) error {
 					packmanager.WithUpdate(!opts.ForceCache),
 					packmanager.WithPlatform(opts.Platform),
 					packmanager.WithArchitecture(opts.Architecture),
+					packmanager.WithHTTPClient(client),
+					packmanager.WithGitSSHAuth(gitAuth),
 				},
 			})
 		}
@@ -790,39 +2800,122 @@ func (opts *Pull) PullCmd(ctx context.Context, args []string) error {
 					packmanager.WithArchitecture(opts.Architecture),
 					packmanager.WithPlatform(opts.Platform),
 					packmanager.WithKConfig(opts.KConfig),
+					packmanager.WithHTTPClient(client),
+					packmanager.WithGitSSHAuth(gitAuth),
 				},
 			})
 		}
 	}
 
-	for _, c := range queries {
+	var cache *PortableCache
+	if len(opts.PortableCache) > 0 {
+		cache = &PortableCache{Root: opts.PortableCache}
+	}
+
+	output := workdir
+	if len(opts.Output) > 0 {
+		if cache != nil {
+			return fmt.Errorf("cannot pull to an output directory and a portable cache at the same time")
+		}
+
+		output, err = preparePullOutput(opts.Output)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Resolve every query, then pull the packages found, each with at most
+	// Concurrency operations in flight.
+	classifier, err := NewRetryClassifier(nil)
+	if err != nil {
+		return err
+	}
+	retrier := &Retrier{
+		Attempts:   opts.Retries + 1,
+		Backoff:    opts.RetryBackoff,
+		MaxBackoff: DefaultRetryMaxBackoff,
+		Classifier: classifier,
+	}
+
+	resolved, err := mapBounded(ctx, queries, opts.Concurrency, nil, func(ctx context.Context, c pmQuery) ([]pack.Package, error) {
 		query := packmanager.NewQuery(c.query...)
-		next, err := c.pm.Catalog(ctx, c.query...)
+
+		var next []pack.Package
+		err := retrier.Do(ctx, func() error {
+			var err error
+			next, err = c.pm.Catalog(ctx, c.query...)
+			return err
+		})
 		if err != nil {
 			log.G(ctx).
 				WithField("format", pm.Format().String()).
 				WithField("name", query.Name()).
 				Warn(err)
-			continue
+			return nil, nil
 		}
 
 		if len(next) == 0 {
 			log.G(ctx).Warnf("could not find %s", query.String())
-			continue
 		}
 
-		for _, p := range next {
-			p := p
-			err := p.Pull(
-				ctx,
-				pack.WithPullWorkdir(workdir),
-				pack.WithPullChecksum(!opts.NoChecksum),
-				pack.WithPullCache(opts.ForceCache),
-			)
+		return next, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var packages []pack.Package
+	for _, next := range resolved {
+		packages = append(packages, next...)
+	}
+
+	_, err = mapBounded(ctx, packages, opts.Concurrency, nil, func(ctx context.Context, p pack.Package) (struct{}, error) {
+		var err error
+		pullWorkdir := output
+		if cache != nil {
+			// Components already in the cache, e.g. shipped along with the
+			// project, are not pulled again.
+			if dir, err := cache.Resolve(string(p.Type()), p.Name(), p.Version()); err == nil {
+				log.G(ctx).Infof("using %s from the portable cache in %s", p.Name(), dir)
+				return struct{}{}, nil
+			}
+
+			pullWorkdir, err = cache.Place(string(p.Type()), p.Name(), p.Version())
 			if err != nil {
-				return err
+				return struct{}{}, err
 			}
 		}
+
+		err = pullVerified(opts.ChecksumPolicy, p.Name(), logWarn(ctx), func(checksum bool) error {
+			return retrier.Do(ctx, func() error {
+				return p.Pull(
+					withDownloadCounter(ctx, downloads, p.Name()),
+					pack.WithPullWorkdir(pullWorkdir),
+					pack.WithPullChecksum(checksum),
+					pack.WithPullCache(opts.ForceCache),
+					pack.WithPullHTTPClient(client),
+					pack.WithPullGitSSHAuth(gitAuth),
+				)
+			})
+		})
+		if err != nil {
+			return struct{}{}, fmt.Errorf("could not pull %s: %w", p.Name(), err)
+		}
+
+		if cache != nil {
+			if err := cache.Record(string(p.Type()), p.Name(), p.Version()); err != nil {
+				return struct{}{}, err
+			}
+		}
+
+		return struct{}{}, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(downloads.Summary()) > 0 {
+		log.G(ctx).Infof("downloaded per component:\n%s", downloads.Text())
 	}
 
 	if project != nil {
@@ -834,23 +2927,14 @@ func (opts *Pull) PullCmd(ctx context.Context, args []string) error {
 
 type Source struct {
 	Force bool
+	// Auth are the credentials of the private sources.
+	Auth []SourceAuth
 }
 
 func (opts *Source) SourceCmd(ctx context.Context, args []string) error {
 	for _, source := range args {
 		if !opts.Force {
-			_, compatible, err := packmanager.G(ctx).IsCompatible(ctx,
-				source,
-				packmanager.WithUpdate(true),
-			)
-			if err != nil {
-				return err
-			} else if !compatible {
-				return errors.New("incompatible package manager")
-			}
-
-			err = packmanager.G(ctx).AddSource(ctx, source)
-			if err != nil {
+			if err := opts.add(ctx, source); err != nil {
 				return err
 			}
 		}
@@ -875,6 +2959,149 @@ func (opts *Source) SourceCmd(ctx context.Context, args []string) error {
 	return nil
 }
 
+// SourceHealthCheck reports the health of catalog sources.
+type SourceHealthCheck struct {
+	// Sources are the sources to check.  It defaults to the configured
+	// manifests.
+	Sources []string
+	// MaxAge is the age after which a source is stale.  It defaults to
+	// DefaultSourceMaxAge.
+	MaxAge time.Duration
+	// Auth are the credentials of the private sources.
+	Auth []SourceAuth
+}
+
+func (opts *SourceHealthCheck) SourceHealthCmd(ctx context.Context) ([]SourceHealth, error) {
+	sources := opts.Sources
+	if len(sources) == 0 {
+		sources = config.G[config.KraftKit](ctx).Unikraft.Manifests
+	}
+
+	defer registerSourceAuth(ctx, opts.Auth)()
+	client := &http.Client{Transport: newDownloadTransport(nil, ProxyConfig{}, opts.Auth, nil)}
+	gitAuth := gitSSHAuth(opts.Auth)
+
+	probe := func(ctx context.Context, source string) (sourceProbe, error) {
+		pm, compatible, err := packmanager.G(ctx).IsCompatible(ctx,
+			source,
+			packmanager.WithUpdate(true),
+			packmanager.WithHTTPClient(client),
+			packmanager.WithGitSSHAuth(gitAuth),
+		)
+		if err != nil {
+			return sourceProbe{}, err
+		} else if !compatible {
+			return sourceProbe{}, errors.New("incompatible package manager")
+		}
+
+		packages, err := pm.Catalog(ctx,
+			packmanager.WithSource(source),
+			packmanager.WithUpdate(true),
+			packmanager.WithHTTPClient(client),
+			packmanager.WithGitSSHAuth(gitAuth),
+		)
+		if err != nil {
+			return sourceProbe{}, err
+		}
+
+		updated, err := sourceLastUpdated(ctx, client, source)
+		if err != nil {
+			log.G(ctx).Debugf("could not tell when %s was last updated: %v", source, err)
+		}
+
+		return sourceProbe{Updated: updated, Components: len(packages)}, nil
+	}
+
+	health := assessSources(ctx, sources, probe, opts.MaxAge, time.Now())
+	for _, h := range health {
+		if h.Healthy() {
+			log.G(ctx).Info(h.String())
+		} else {
+			log.G(ctx).Warn(h.String())
+		}
+	}
+
+	return health, nil
+}
+
+// add checks that source is compatible with a package manager and adds it
+// to it, authenticating with the credentials of the private sources.  They
+// are removed from the KraftKit configuration before it is written, so that
+// they are never saved to it.
+func (opts *Source) add(ctx context.Context, source string) error {
+	defer registerSourceAuth(ctx, opts.Auth)()
+
+	_, compatible, err := packmanager.G(ctx).IsCompatible(ctx,
+		source,
+		packmanager.WithUpdate(true),
+		packmanager.WithAuthConfig(config.G[config.KraftKit](ctx).Auth),
+		packmanager.WithHTTPClient(&http.Client{Transport: newDownloadTransport(nil, ProxyConfig{}, opts.Auth, nil)}),
+		packmanager.WithGitSSHAuth(gitSSHAuth(opts.Auth)),
+	)
+	if err != nil {
+		return err
+	} else if !compatible {
+		return errors.New("incompatible package manager")
+	}
+
+	return packmanager.G(ctx).AddSource(ctx, source)
+}
+
+// registerSourceAuth adds the credentials of the private sources of auths
+// served over HTTP(S) to the KraftKit configuration, which catalog queries
+// authenticate with, until the returned function is called.  Downloads and Git
+// clones over SSH are authenticated by the options of the queries and pulls
+// instead.
+func registerSourceAuth(ctx context.Context, auths []SourceAuth) func() {
+	if len(auths) == 0 {
+		return func() {}
+	}
+
+	cfg := config.G[config.KraftKit](ctx)
+	if cfg.Auth == nil {
+		cfg.Auth = map[string]config.AuthConfig{}
+	}
+
+	// The credentials of the configuration replaced by the ones of auths,
+	// nil when there were none.
+	previous := map[string]*config.AuthConfig{}
+	for _, a := range auths {
+		if a.IsSSH() {
+			continue
+		}
+
+		host := a.Host()
+		if _, ok := previous[host]; !ok {
+			if auth, ok := cfg.Auth[host]; ok {
+				previous[host] = &auth
+			} else {
+				previous[host] = nil
+			}
+		}
+
+		token := a.Token
+		if token == "" {
+			token = a.Password
+		}
+		cfg.Auth[host] = config.AuthConfig{
+			Endpoint:  host,
+			User:      a.Username,
+			Token:     token,
+			VerifySSL: true,
+		}
+	}
+
+	return func() {
+		for host, auth := range previous {
+			if auth == nil {
+				delete(cfg.Auth, host)
+			} else {
+				cfg.Auth[host] = *auth
+			}
+		}
+	}
+}
+
 type Unsource struct{}
 
 func (opts *Unsource) UnsourceCmd(ctx context.Context, args []string) error {
@@ -907,11 +3134,15 @@ func (opts *Unsource) UnsourceCmd(ctx context.Context, args []string) error {
 
 type Update struct {
 	Manager string
+	// Auth are the credentials of the private sources.
+	Auth []SourceAuth
 }
 
 func (opts *Update) UpdateCmd(ctx context.Context, args []string) error {
 	var err error
 
+	defer registerSourceAuth(ctx, opts.Auth)()
+
 	pm := packmanager.G(ctx)
 
 	// Force a particular package manager
@@ -945,6 +3176,9 @@ func (opts *Update) UpdateCmd(ctx context.Context, args []string) error {
 type Set struct {
 	Kraftfile string
 	Workdir   string
+
+	// Policy restricts the symbols which may be changed.
+	Policy KConfigPolicy
 }
 
 func (opts *Set) SetCmd(ctx context.Context, args []string) error {
@@ -974,6 +3208,11 @@ func (opts *Set) SetCmd(ctx context.Context, args []string) error {
 			return fmt.Errorf("invalid or malformed argument: %s", arg)
 		}
 
+		symbol, _, _ := strings.Cut(arg, "=")
+		if err := opts.Policy.Check(symbol); err != nil {
+			return err
+		}
+
 		confOpts = append(confOpts, arg)
 	}
 
@@ -1005,3 +3244,11 @@ func (opts *Set) SetCmd(ctx context.Context, args []string) error {
 
 	return project.Set(ctx, nil)
 }
+
+// logWarn returns a function logging messages as warnings with the logger of
+// ctx.
+func logWarn(ctx context.Context) func(string) {
+	return func(msg string) {
+		log.G(ctx).Warn(msg)
+	}
+}
@@ -0,0 +1,45 @@
+package unikraft
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSkipPhase(t *testing.T) {
+	tests := []struct {
+		name    string
+		flag    bool
+		targets []string
+		target  string
+		want    bool
+	}{
+		{"flag unset", false, nil, "qemu-x86_64", false},
+		{"flag unset with list", false, []string{"qemu-x86_64"}, "qemu-x86_64", false},
+		{"flag applies to all", true, nil, "qemu-x86_64", true},
+		{"listed target", true, []string{"qemu-x86_64", "fc-x86_64"}, "fc-x86_64", true},
+		{"unlisted target", true, []string{"qemu-x86_64"}, "qemu-arm64", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := skipPhase(tt.flag, tt.targets, tt.target); got != tt.want {
+				t.Errorf("skipPhase(%v, %v, %q) = %v, want %v", tt.flag, tt.targets, tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPhaseTimeout(t *testing.T) {
+	opts := &Build{ConfigureTimeout: time.Minute, BuildTimeout: time.Hour}
+
+	for phase, want := range map[string]time.Duration{
+		"configure": time.Minute,
+		"prepare":   time.Minute,
+		"build":     time.Hour,
+		"package":   0,
+	} {
+		if got := opts.phaseTimeout(phase); got != want {
+			t.Errorf("phaseTimeout(%q) = %s, want %s", phase, got, want)
+		}
+	}
+}
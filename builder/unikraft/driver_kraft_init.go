@@ -3,6 +3,7 @@ package unikraft
 import (
 	"context"
 	"fmt"
+	"os"
 
 	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
 	"github.com/rancher/wrangler/pkg/signals"
@@ -22,7 +23,15 @@ func (k *Kraft) Run(ctx context.Context, args []string) error {
 
 // KraftCommandContext returns a context with the Kraft commands registered.
 // It needs to initialise the commands to ensure that internal context functions are called.
-func KraftCommandContext(ui packersdk.Ui, logLevel string) context.Context {
+// Fancy output is only rendered to a terminal, unless fancyOutput forces it.
+func KraftCommandContext(ui packersdk.Ui, logLevel string, fancyOutput bool) context.Context {
+	return KraftCacheCommandContext(ui, logLevel, fancyOutput, nil)
+}
+
+// KraftCacheCommandContext is KraftCommandContext keeping the manifest index
+// and component sources in cache instead of the paths of the KraftKit
+// configuration, when it is set.
+func KraftCacheCommandContext(ui packersdk.Ui, logLevel string, fancyOutput bool, cache *CachePaths) context.Context {
 	ctx := signals.SetupSignalContext()
 
 	cfg, err := config.NewDefaultKraftKitConfig()
@@ -39,6 +48,14 @@ func KraftCommandContext(ui packersdk.Ui, logLevel string) context.Context {
 		panic(err)
 	}
 
+	// Applied once the configuration file has been read, which may set the
+	// log type as well.
+	cfgm.Config.Log.Type = logTypeFor(cfgm.Config.Log.Type, os.Stdout, fancyOutput)
+	if cache != nil {
+		cfgm.Config.Paths.Manifests = cache.Manifests
+		cfgm.Config.Paths.Sources = cache.Sources
+	}
+
 	ctx = config.WithConfigManager(ctx, cfgm)
 
 	// Set up a default logger based on the internal TextFormatter
@@ -11,14 +11,18 @@ type MockDriver struct {
 	PkgArchitecture string
 	PkgPlatform     string
 	PkgTarget       string
+	PkgName         string
+	PkgWorkdir      string
+	PkgRootfs       string
 	PkgPush         bool
 
 	CleanCalled bool
 	CleanPath   string
 
 	PullCalled  bool
-	PullSource  string
+	PullSources []string
 	PullWorkdir string
+	PullOptions PullOptions
 
 	SourceCalled bool
 	SourceSource string
@@ -26,7 +30,8 @@ type MockDriver struct {
 	UnsourceCalled bool
 	UnsourceSource string
 
-	UpdateCalled bool
+	UpdateCalled  bool
+	UpdateManager string
 
 	SetCalled  bool
 	SetOptions map[string]string
@@ -35,6 +40,8 @@ type MockDriver struct {
 	UnsetOptions []string
 }
 
+var _ Driver = (*MockDriver)(nil)
+
 func (d *MockDriver) Build(path, architecture, platform, target string) error {
 	d.BuildCalled = true
 	d.BuildPath = path
@@ -44,10 +51,13 @@ func (d *MockDriver) Build(path, architecture, platform, target string) error {
 	return nil
 }
 
-func (d *MockDriver) Pkg(architecture, platform, target, pkgName string, push bool) error {
+func (d *MockDriver) Pkg(architecture, platform, target, pkgName, workdir, rootfs string, push bool) error {
 	d.PkgArchitecture = architecture
 	d.PkgPlatform = platform
 	d.PkgTarget = target
+	d.PkgName = pkgName
+	d.PkgWorkdir = workdir
+	d.PkgRootfs = rootfs
 	d.PkgCalled = true
 	d.PkgPush = push
 	return nil
@@ -59,10 +69,11 @@ func (d *MockDriver) Clean(path string) error {
 	return nil
 }
 
-func (d *MockDriver) Pull(source, workdir string) error {
+func (d *MockDriver) Pull(sources []string, workdir string, opts PullOptions) error {
 	d.PullCalled = true
-	d.PullSource = source
+	d.PullSources = sources
 	d.PullWorkdir = workdir
+	d.PullOptions = opts
 	return nil
 }
 
@@ -78,8 +89,9 @@ func (d *MockDriver) Unsource(source string) error {
 	return nil
 }
 
-func (d *MockDriver) Update() error {
+func (d *MockDriver) Update(manager string) error {
 	d.UpdateCalled = true
+	d.UpdateManager = manager
 	return nil
 }
 
@@ -0,0 +1,145 @@
+package unikraft
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// BuildEnvironment describes the host a build ran on.
+type BuildEnvironment struct {
+	OS          string            `json:"os"`
+	Arch        string            `json:"arch"`
+	Kernel      string            `json:"kernel,omitempty"`
+	CPU         string            `json:"cpu,omitempty"`
+	CPUs        int               `json:"cpus"`
+	MemoryBytes uint64            `json:"memory_bytes,omitempty"`
+	Toolchain   map[string]string `json:"toolchain,omitempty"`
+	KraftKit    string            `json:"kraftkit,omitempty"`
+}
+
+// toolchainCommands are the tools whose version is recorded, with the
+// argument printing it.
+var toolchainCommands = map[string]string{
+	"gcc":  "--version",
+	"make": "--version",
+	"ld":   "--version",
+}
+
+// environmentProbe abstracts the host so that the collection can be tested.
+type environmentProbe struct {
+	readFile func(string) ([]byte, error)
+	command  func(name string, args ...string) (string, error)
+	kraftkit func() string
+}
+
+// hostProbe inspects the current host.
+var hostProbe = environmentProbe{
+	readFile: os.ReadFile,
+	command: func(name string, args ...string) (string, error) {
+		out, err := exec.Command(name, args...).Output()
+		return string(out), err
+	},
+	kraftkit: func() string {
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			return ""
+		}
+
+		for _, dep := range info.Deps {
+			if dep.Path == "kraftkit.sh" {
+				return dep.Version
+			}
+		}
+
+		return ""
+	},
+}
+
+// collectBuildEnvironment gathers the metadata of the host.  Values which
+// cannot be determined are left empty.
+func collectBuildEnvironment(p environmentProbe) *BuildEnvironment {
+	env := &BuildEnvironment{
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		CPUs:      runtime.NumCPU(),
+		Toolchain: map[string]string{},
+		KraftKit:  p.kraftkit(),
+	}
+
+	if raw, err := p.readFile("/proc/sys/kernel/osrelease"); err == nil {
+		env.Kernel = strings.TrimSpace(string(raw))
+	} else if out, err := p.command("uname", "-r"); err == nil {
+		env.Kernel = strings.TrimSpace(out)
+	}
+
+	if raw, err := p.readFile("/proc/cpuinfo"); err == nil {
+		env.CPU = procField(string(raw), "model name")
+	}
+
+	if raw, err := p.readFile("/proc/meminfo"); err == nil {
+		// MemTotal is reported in kB.
+		fields := strings.Fields(procField(string(raw), "MemTotal"))
+		if len(fields) > 0 {
+			if kb, err := strconv.ParseUint(fields[0], 10, 64); err == nil {
+				env.MemoryBytes = kb * 1024
+			}
+		}
+	}
+
+	for tool, arg := range toolchainCommands {
+		out, err := p.command(tool, arg)
+		if err != nil {
+			continue
+		}
+
+		if line, _, _ := strings.Cut(out, "\n"); strings.TrimSpace(line) != "" {
+			env.Toolchain[tool] = strings.TrimSpace(line)
+		}
+	}
+
+	return env
+}
+
+// procField returns the value of the first `key: value` line of a /proc file.
+func procField(contents, key string) string {
+	scanner := bufio.NewScanner(strings.NewReader(contents))
+	for scanner.Scan() {
+		k, v, ok := strings.Cut(scanner.Text(), ":")
+		if ok && strings.TrimSpace(k) == key {
+			return strings.TrimSpace(v)
+		}
+	}
+
+	return ""
+}
+
+// Map flattens the environment into string values, as carried by artifacts.
+func (e *BuildEnvironment) Map() map[string]string {
+	m := map[string]string{
+		"os":   e.OS,
+		"arch": e.Arch,
+		"cpus": strconv.Itoa(e.CPUs),
+	}
+	if e.Kernel != "" {
+		m["kernel"] = e.Kernel
+	}
+	if e.CPU != "" {
+		m["cpu"] = e.CPU
+	}
+	if e.MemoryBytes > 0 {
+		m["memory_bytes"] = strconv.FormatUint(e.MemoryBytes, 10)
+	}
+	if e.KraftKit != "" {
+		m["kraftkit"] = e.KraftKit
+	}
+	for tool, version := range e.Toolchain {
+		m["toolchain_"+tool] = version
+	}
+
+	return m
+}
@@ -0,0 +1,61 @@
+package unikraft
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCollectBuildEnvironment(t *testing.T) {
+	files := map[string]string{
+		"/proc/sys/kernel/osrelease": "6.1.0-13-amd64\n",
+		"/proc/cpuinfo":              "processor\t: 0\nmodel name\t: AMD EPYC 7B13\n",
+		"/proc/meminfo":              "MemTotal:       16384000 kB\nMemFree:         1024 kB\n",
+	}
+	commands := map[string]string{
+		"gcc":  "gcc (Debian 12.2.0-14) 12.2.0\nCopyright (C) 2022\n",
+		"make": "GNU Make 4.3\n",
+	}
+
+	env := collectBuildEnvironment(environmentProbe{
+		readFile: func(path string) ([]byte, error) {
+			if s, ok := files[path]; ok {
+				return []byte(s), nil
+			}
+			return nil, errors.New("not found")
+		},
+		command: func(name string, _ ...string) (string, error) {
+			if s, ok := commands[name]; ok {
+				return s, nil
+			}
+			return "", errors.New("not found")
+		},
+		kraftkit: func() string { return "v0.7.0" },
+	})
+
+	if env.OS == "" || env.Arch == "" || env.CPUs == 0 {
+		t.Errorf("expected host runtime fields, got %+v", env)
+	}
+	if env.Kernel != "6.1.0-13-amd64" {
+		t.Errorf("unexpected kernel: %q", env.Kernel)
+	}
+	if env.CPU != "AMD EPYC 7B13" {
+		t.Errorf("unexpected cpu: %q", env.CPU)
+	}
+	if env.MemoryBytes != 16384000*1024 {
+		t.Errorf("unexpected memory: %d", env.MemoryBytes)
+	}
+	if env.Toolchain["gcc"] != "gcc (Debian 12.2.0-14) 12.2.0" || env.Toolchain["make"] != "GNU Make 4.3" {
+		t.Errorf("unexpected toolchain: %v", env.Toolchain)
+	}
+	if _, ok := env.Toolchain["ld"]; ok {
+		t.Errorf("expected missing ld to be omitted")
+	}
+	if env.KraftKit != "v0.7.0" {
+		t.Errorf("unexpected kraftkit version: %q", env.KraftKit)
+	}
+
+	m := env.Map()
+	if m["toolchain_gcc"] == "" || m["kernel"] == "" || m["kraftkit"] == "" {
+		t.Errorf("unexpected flattened environment: %v", m)
+	}
+}
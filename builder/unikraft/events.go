@@ -0,0 +1,99 @@
+package unikraft
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Types of the events emitted while building.
+const (
+	EventPhaseStart     = "phase_start"
+	EventPhaseEnd       = "phase_end"
+	EventPullProgress   = "pull_progress"
+	EventTargetComplete = "target_complete"
+)
+
+// Event is a progress event of a build.
+type Event struct {
+	Time    time.Time              `json:"timestamp"`
+	Type    string                 `json:"type"`
+	Target  string                 `json:"target,omitempty"`
+	Payload map[string]interface{} `json:"payload,omitempty"`
+}
+
+// Observer is notified of the progress of a build.
+type Observer interface {
+	Observe(Event)
+}
+
+// EventStream is an Observer writing every event as a line of JSON.
+type EventStream struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	c   io.Closer
+}
+
+// NewEventStream returns an EventStream writing to w.
+func NewEventStream(w io.Writer) *EventStream {
+	s := &EventStream{enc: json.NewEncoder(w)}
+	if c, ok := w.(io.Closer); ok {
+		s.c = c
+	}
+
+	return s
+}
+
+// OpenEventStream returns an EventStream appending to the file or named pipe
+// at path.
+func OpenEventStream(path string) (*EventStream, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewEventStream(f), nil
+}
+
+// Observe writes the event.  Events which cannot be written are dropped so
+// that progress reporting never fails a build.
+func (s *EventStream) Observe(e Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_ = s.enc.Encode(e)
+}
+
+// Close closes the underlying writer, if it can be closed.
+func (s *EventStream) Close() error {
+	if s.c == nil {
+		return nil
+	}
+
+	return s.c.Close()
+}
+
+// observers fans events out to several observers.
+type observers []Observer
+
+func (o observers) Observe(e Event) {
+	for _, obs := range o {
+		obs.Observe(e)
+	}
+}
+
+// emit notifies obs of an event, if any.
+func emit(obs Observer, typ, target string, payload map[string]interface{}) {
+	if obs == nil {
+		return
+	}
+
+	obs.Observe(Event{
+		Time:    time.Now().UTC(),
+		Type:    typ,
+		Target:  target,
+		Payload: payload,
+	})
+}
@@ -0,0 +1,54 @@
+package unikraft
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEventStream(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+
+	stream, err := OpenEventStream(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	emit(stream, EventPhaseStart, "nginx-qemu-x86_64", map[string]interface{}{"phase": "build"})
+	emit(stream, EventPullProgress, "", map[string]interface{}{"package": "musl", "progress": 0.5})
+	emit(stream, EventTargetComplete, "nginx-qemu-x86_64", map[string]interface{}{"status": TargetStatusSuccess})
+	if err := stream.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var types []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("malformed event %q: %v", scanner.Text(), err)
+		}
+		if e.Time.IsZero() {
+			t.Errorf("event %q has no timestamp", scanner.Text())
+		}
+		types = append(types, e.Type)
+	}
+
+	want := []string{EventPhaseStart, EventPullProgress, EventTargetComplete}
+	if len(types) != len(want) {
+		t.Fatalf("expected %d events, got %v", len(want), types)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Errorf("event %d is %s, want %s", i, types[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package unikraft
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+const (
+	// DefaultFirecrackerBinary is the firecracker executable looked up in
+	// the PATH when none is given.
+	DefaultFirecrackerBinary = "firecracker"
+	// DefaultFirecrackerMemory is the memory of a microVM, in MiB.
+	DefaultFirecrackerMemory = 128
+	// DefaultFirecrackerVCPUs is the number of vCPUs of a microVM.
+	DefaultFirecrackerVCPUs = 1
+)
+
+// FirecrackerVM describes the microVM a kernel built for the fc platform is
+// booted in.
+type FirecrackerVM struct {
+	Kernel   string
+	Initrd   string
+	BootArgs string
+	// Memory is the memory of the microVM in MiB.  Defaults to
+	// DefaultFirecrackerMemory.
+	Memory int
+	// VCPUs is the number of vCPUs of the microVM.  Defaults to
+	// DefaultFirecrackerVCPUs.
+	VCPUs int
+}
+
+type firecrackerBootSource struct {
+	KernelImagePath string `json:"kernel_image_path"`
+	BootArgs        string `json:"boot_args,omitempty"`
+	InitrdPath      string `json:"initrd_path,omitempty"`
+}
+
+type firecrackerMachineConfig struct {
+	VCPUCount  int  `json:"vcpu_count"`
+	MemSizeMiB int  `json:"mem_size_mib"`
+	SMT        bool `json:"smt"`
+}
+
+// firecrackerConfigFile is the document read by `firecracker --config-file`.
+type firecrackerConfigFile struct {
+	BootSource        firecrackerBootSource    `json:"boot-source"`
+	Drives            []struct{}               `json:"drives"`
+	MachineConfig     firecrackerMachineConfig `json:"machine-config"`
+	NetworkInterfaces []struct{}               `json:"network-interfaces"`
+}
+
+// Config returns the firecracker configuration file of the microVM.
+func (vm FirecrackerVM) Config() ([]byte, error) {
+	if len(vm.Kernel) == 0 {
+		return nil, fmt.Errorf("firecracker microVM has no kernel")
+	}
+
+	memory := vm.Memory
+	if memory <= 0 {
+		memory = DefaultFirecrackerMemory
+	}
+
+	vcpus := vm.VCPUs
+	if vcpus <= 0 {
+		vcpus = DefaultFirecrackerVCPUs
+	}
+
+	return json.MarshalIndent(firecrackerConfigFile{
+		BootSource: firecrackerBootSource{
+			KernelImagePath: vm.Kernel,
+			BootArgs:        vm.BootArgs,
+			InitrdPath:      vm.Initrd,
+		},
+		Drives: []struct{}{},
+		MachineConfig: firecrackerMachineConfig{
+			VCPUCount:  vcpus,
+			MemSizeMiB: memory,
+		},
+		NetworkInterfaces: []struct{}{},
+	}, "", "  ")
+}
+
+// WriteConfig writes the firecracker configuration file of the microVM to
+// path.
+func (vm FirecrackerVM) WriteConfig(path string) error {
+	raw, err := vm.Config()
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(raw, '\n'), 0o644)
+}
+
+// smokeTestFirecracker boots the microVM with the firecracker binary, without
+// its API socket, and waits for it to pass check.
+func smokeTestFirecracker(ctx context.Context, binary string, vm FirecrackerVM, check BootCheck) error {
+	if len(binary) == 0 {
+		binary = DefaultFirecrackerBinary
+	}
+
+	dir, err := os.MkdirTemp("", "packer-firecracker-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	config := filepath.Join(dir, "vm.json")
+	if err := vm.WriteConfig(config); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, binary, "--no-api", "--config-file", config)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	cmd.Stderr = cmd.Stdout
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("could not start firecracker: %w", err)
+	}
+
+	console := make(chan string)
+	exited := make(chan int, 1)
+	go func() {
+		defer close(console)
+
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			select {
+			case console <- scanner.Text():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		err := cmd.Wait()
+
+		var exitErr *exec.ExitError
+		switch {
+		case err == nil:
+			exited <- 0
+		case errors.As(err, &exitErr):
+			exited <- exitErr.ExitCode()
+		default:
+			exited <- -1
+		}
+	}()
+
+	return watchBoot(ctx, console, exited, check)
+}
@@ -0,0 +1,131 @@
+package unikraft
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFirecrackerVMConfig(t *testing.T) {
+	raw, err := FirecrackerVM{
+		Kernel:   "/out/helloworld_fc-x86_64",
+		BootArgs: "console=ttyS0",
+	}.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		BootSource    map[string]interface{} `json:"boot-source"`
+		MachineConfig map[string]interface{} `json:"machine-config"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		t.Fatalf("invalid config %s: %v", raw, err)
+	}
+
+	if got := doc.BootSource["kernel_image_path"]; got != "/out/helloworld_fc-x86_64" {
+		t.Errorf("kernel_image_path = %v", got)
+	}
+	if got := doc.BootSource["boot_args"]; got != "console=ttyS0" {
+		t.Errorf("boot_args = %v", got)
+	}
+	if _, ok := doc.BootSource["initrd_path"]; ok {
+		t.Errorf("expected no initrd_path, got %v", doc.BootSource["initrd_path"])
+	}
+	if got := doc.MachineConfig["mem_size_mib"]; got != float64(DefaultFirecrackerMemory) {
+		t.Errorf("mem_size_mib = %v, want %d", got, DefaultFirecrackerMemory)
+	}
+	if got := doc.MachineConfig["vcpu_count"]; got != float64(DefaultFirecrackerVCPUs) {
+		t.Errorf("vcpu_count = %v, want %d", got, DefaultFirecrackerVCPUs)
+	}
+}
+
+func TestFirecrackerVMConfigWithoutKernel(t *testing.T) {
+	if _, err := (FirecrackerVM{}).Config(); err == nil {
+		t.Error("expected an error without a kernel")
+	}
+}
+
+// fakeFirecracker writes a firecracker stand-in printing the kernel of its
+// configuration file followed by output, and exiting with code.
+func fakeFirecracker(t *testing.T, output string, code int) string {
+	t.Helper()
+
+	binary := filepath.Join(t.TempDir(), "firecracker")
+	script := "#!/bin/sh\n" +
+		"grep kernel_image_path \"$3\"\n" +
+		"printf '%s\\n' '" + output + "'\n" +
+		"exit " + strconv.Itoa(code) + "\n"
+	if err := os.WriteFile(binary, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	return binary
+}
+
+func TestSmokeTestFirecracker(t *testing.T) {
+	zero := 0
+	vm := FirecrackerVM{Kernel: "/out/helloworld_fc-x86_64"}
+
+	tests := []struct {
+		name   string
+		output string
+		code   int
+		check  BootCheck
+		want   string
+	}{
+		{
+			name:   "boots",
+			output: "Hello world!",
+			check:  BootCheck{ExpectConsole: "Hello world!", ExpectExitCode: &zero},
+		},
+		{
+			name:   "sees its configuration",
+			output: "Hello world!",
+			check:  BootCheck{ExpectConsole: "helloworld_fc-x86_64"},
+		},
+		{
+			name:   "crashes",
+			output: "Kernel panic",
+			code:   1,
+			check:  BootCheck{ExpectConsole: "Hello world!"},
+			want:   "unikernel crashed",
+		},
+		{
+			name:   "fails",
+			output: "main returned 3",
+			code:   3,
+			check:  BootCheck{ExpectExitCode: &zero},
+			want:   "exited with code 3, expected 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			binary := fakeFirecracker(t, tt.output, tt.code)
+			tt.check.Timeout = 10 * time.Second
+
+			err := smokeTestFirecracker(context.Background(), binary, vm, tt.check)
+			if tt.want == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestSmokeTestFirecrackerMissingBinary(t *testing.T) {
+	vm := FirecrackerVM{Kernel: "/out/helloworld_fc-x86_64"}
+	binary := filepath.Join(t.TempDir(), "firecracker")
+
+	err := smokeTestFirecracker(context.Background(), binary, vm, BootCheck{})
+	if err == nil || !strings.Contains(err.Error(), "could not start firecracker") {
+		t.Errorf("expected a start error, got %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package unikraft
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/mattn/go-shellwords"
+)
+
+// formatOptionKeys lists, per package format, the options accepted in
+// Pkg.FormatOptions.
+var formatOptionKeys = map[string][]string{
+	"oci": {"args", "kconfig", "kernel_version"},
+}
+
+// formatPackOptions are the pack options set through Pkg.FormatOptions.
+// They override the ones derived from the other fields of Pkg.
+type formatPackOptions struct {
+	Args          []string
+	KConfig       *bool
+	KernelVersion string
+}
+
+// parseFormatOptions parses the options of a format, returning the sorted
+// keys the format does not know, which are otherwise ignored.
+func parseFormatOptions(format string, options map[string]string) (formatPackOptions, []string, error) {
+	var parsed formatPackOptions
+	var unknown []string
+
+	known := map[string]bool{}
+	for _, key := range formatOptionKeys[format] {
+		known[key] = true
+	}
+
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := options[key]
+		if !known[key] {
+			unknown = append(unknown, key)
+			continue
+		}
+
+		switch key {
+		case "args":
+			args, err := shellwords.Parse(value)
+			if err != nil {
+				return parsed, nil, fmt.Errorf("invalid %s option %s: %w", format, key, err)
+			}
+			parsed.Args = args
+		case "kconfig":
+			kconfig, err := strconv.ParseBool(value)
+			if err != nil {
+				return parsed, nil, fmt.Errorf("invalid %s option %s: %w", format, key, err)
+			}
+			parsed.KConfig = &kconfig
+		case "kernel_version":
+			parsed.KernelVersion = value
+		}
+	}
+
+	return parsed, unknown, nil
+}
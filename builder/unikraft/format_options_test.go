@@ -0,0 +1,40 @@
+package unikraft
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFormatOptions(t *testing.T) {
+	parsed, unknown, err := parseFormatOptions("oci", map[string]string{
+		"args":           `/nginx/sbin/nginx -c "/nginx/conf/nginx.conf"`,
+		"kconfig":        "false",
+		"kernel_version": "0.15.0",
+		"compression":    "zstd",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"/nginx/sbin/nginx", "-c", "/nginx/conf/nginx.conf"}; !reflect.DeepEqual(parsed.Args, want) {
+		t.Errorf("args = %q, want %q", parsed.Args, want)
+	}
+	if parsed.KConfig == nil || *parsed.KConfig {
+		t.Errorf("expected kconfig to be disabled, got %v", parsed.KConfig)
+	}
+	if parsed.KernelVersion != "0.15.0" {
+		t.Errorf("kernel version = %q, want 0.15.0", parsed.KernelVersion)
+	}
+	if !reflect.DeepEqual(unknown, []string{"compression"}) {
+		t.Errorf("unknown = %v, want [compression]", unknown)
+	}
+
+	if _, _, err := parseFormatOptions("oci", map[string]string{"kconfig": "maybe"}); err == nil {
+		t.Error("expected an error for an invalid boolean")
+	}
+
+	_, unknown, err = parseFormatOptions("fs", map[string]string{"kernel_version": "0.15.0"})
+	if err != nil || !reflect.DeepEqual(unknown, []string{"kernel_version"}) {
+		t.Errorf("expected every option of an unknown format to be reported, got %v (%v)", unknown, err)
+	}
+}
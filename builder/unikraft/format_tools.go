@@ -0,0 +1,46 @@
+package unikraft
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// DefaultFormatTools lists, per package format, the external tools which must
+// be available on the host to package into that format.
+var DefaultFormatTools = map[string][]string{
+	"oci":   {},
+	"qcow2": {"qemu-img"},
+	"iso":   {"grub-mkrescue", "xorriso"},
+	"disk":  {"grub-mkrescue", "xorriso"},
+	"vmdk":  {"qemu-img"},
+}
+
+// checkFormatTools returns an error naming the tools required by format which
+// cannot be found by lookPath.  Tools listed in extra for the format are
+// required in addition to the defaults.
+func checkFormatTools(format string, extra map[string][]string, lookPath func(string) (string, error)) error {
+	tools := append(append([]string{}, DefaultFormatTools[format]...), extra[format]...)
+
+	var missing []string
+	for _, tool := range tools {
+		if _, err := lookPath(tool); err != nil {
+			missing = append(missing, tool)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("packaging as %s requires %s which could not be found in PATH",
+			format,
+			strings.Join(missing, ", "),
+		)
+	}
+
+	return nil
+}
+
+// formatToolsOnHost checks that the tools required by format are available on
+// the current host.
+func formatToolsOnHost(format string, extra map[string][]string) error {
+	return checkFormatTools(format, extra, exec.LookPath)
+}
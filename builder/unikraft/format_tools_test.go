@@ -0,0 +1,42 @@
+package unikraft
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckFormatTools(t *testing.T) {
+	installed := map[string]bool{"qemu-img": true, "grub-mkrescue": true}
+	lookPath := func(name string) (string, error) {
+		if installed[name] {
+			return "/usr/bin/" + name, nil
+		}
+		return "", errors.New("not found")
+	}
+
+	tests := []struct {
+		format  string
+		extra   map[string][]string
+		missing string
+	}{
+		{format: "oci"},
+		{format: "qcow2"},
+		{format: "iso", missing: "xorriso"},
+		{format: "oci", extra: map[string][]string{"oci": {"skopeo"}}, missing: "skopeo"},
+	}
+
+	for _, tt := range tests {
+		err := checkFormatTools(tt.format, tt.extra, lookPath)
+		if tt.missing == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tt.format, err)
+			}
+			continue
+		}
+
+		if err == nil || !strings.Contains(err.Error(), tt.missing) {
+			t.Errorf("%s: expected error naming %s, got %v", tt.format, tt.missing, err)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package unikraft
+
+import (
+	"bytes"
+	"context"
+	"debug/elf"
+	"fmt"
+	"net"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultGDBBinary is the gdb executable looked up in the PATH when none
+	// is given.
+	DefaultGDBBinary = "gdb"
+	// DefaultGDBSmokeTimeout is how long a debug smoke test waits for the
+	// entry breakpoint to be hit when no timeout is given.
+	DefaultGDBSmokeTimeout = time.Minute
+)
+
+// qemuMachines are the qemu executables, and the arguments of the machine,
+// the kernels of every architecture are booted with.
+var qemuMachines = map[string][]string{
+	"x86_64": {"qemu-system-x86_64", "-cpu", "max"},
+	"arm64":  {"qemu-system-aarch64", "-machine", "virt", "-cpu", "max"},
+	"arm":    {"qemu-system-arm", "-machine", "virt"},
+}
+
+// GDBSmokeTest boots a kernel of the qemu platform halted in qemu, attaches
+// gdb to it with the symbols of its debug kernel, and checks that a
+// breakpoint on its entry symbol is hit.
+type GDBSmokeTest struct {
+	// Kernel is the kernel booted by qemu.
+	Kernel string
+	// DebugKernel is the unstripped kernel gdb loads the symbols of.
+	DebugKernel  string
+	Architecture string
+	// QEMU is the qemu executable.  Defaults to the one of Architecture.
+	QEMU string
+	// GDB is the gdb executable.  Defaults to DefaultGDBBinary.
+	GDB string
+	// EntrySymbol is the symbol the breakpoint is set on.  Defaults to the
+	// symbol of the entry point of DebugKernel.
+	EntrySymbol string
+	// Timeout is how long to wait for the breakpoint to be hit.  Defaults to
+	// DefaultGDBSmokeTimeout.
+	Timeout time.Duration
+}
+
+// qemuArgs returns the qemu executable and arguments booting the kernel
+// halted, waiting for gdb on port.
+func (t GDBSmokeTest) qemuArgs(port int) (string, []string, error) {
+	machine, ok := qemuMachines[t.Architecture]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown architecture %q", t.Architecture)
+	}
+
+	binary := machine[0]
+	if t.QEMU != "" {
+		binary = t.QEMU
+	}
+
+	args := append([]string{}, machine[1:]...)
+	args = append(args,
+		"-kernel", t.Kernel,
+		"-display", "none",
+		"-no-reboot",
+		// Same as -s, on a port of its own so that tests may run side by side.
+		"-gdb", "tcp:127.0.0.1:"+strconv.Itoa(port),
+		"-S",
+	)
+
+	return binary, args, nil
+}
+
+// gdbArgs returns the arguments of gdb attaching to the qemu waiting on port
+// and breaking on symbol.
+func (t GDBSmokeTest) gdbArgs(port int, symbol string) []string {
+	return []string{
+		"-batch", "-nx",
+		"-ex", "set pagination off",
+		"-ex", "set confirm off",
+		"-ex", "target remote 127.0.0.1:" + strconv.Itoa(port),
+		// The kernel may still be copied to its load address once qemu
+		// resumes, overwriting software breakpoints.
+		"-ex", "hbreak " + symbol,
+		"-ex", "continue",
+		"-ex", "kill",
+		t.DebugKernel,
+	}
+}
+
+// Run runs the smoke test and returns the output of gdb.
+func (t GDBSmokeTest) Run(ctx context.Context) (string, error) {
+	// gdb keeps going when the breakpoint cannot be set, the symbols are
+	// thus checked beforehand.
+	symbol, err := entrySymbol(t.DebugKernel, t.EntrySymbol)
+	if err != nil {
+		return "", err
+	}
+
+	timeout := t.Timeout
+	if timeout <= 0 {
+		timeout = DefaultGDBSmokeTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	port, err := freePort()
+	if err != nil {
+		return "", err
+	}
+
+	binary, args, err := t.qemuArgs(port)
+	if err != nil {
+		return "", err
+	}
+
+	var console bytes.Buffer
+	qemu := exec.CommandContext(ctx, binary, args...)
+	qemu.Stdout = &console
+	qemu.Stderr = &console
+	if err := qemu.Start(); err != nil {
+		return "", fmt.Errorf("could not start qemu: %w", err)
+	}
+	defer func() {
+		qemu.Process.Kill()
+		qemu.Wait()
+	}()
+
+	if err := waitForPort(ctx, port); err != nil {
+		qemu.Process.Kill()
+		qemu.Wait()
+		return "", fmt.Errorf("qemu is not waiting for gdb: %w: %s", err, strings.TrimSpace(console.String()))
+	}
+
+	gdb := t.GDB
+	if gdb == "" {
+		gdb = DefaultGDBBinary
+	}
+
+	out, err := exec.CommandContext(ctx, gdb, t.gdbArgs(port, symbol)...).CombinedOutput()
+	output := string(out)
+	if m := noSymbols.FindString(output); m != "" {
+		return output, fmt.Errorf("debug symbols do not load: %s", m)
+	}
+	if ctx.Err() != nil {
+		return output, fmt.Errorf("breakpoint on %s not hit within %s", symbol, timeout)
+	}
+	if err != nil && len(out) == 0 {
+		return output, fmt.Errorf("could not run gdb: %w", err)
+	}
+
+	return output, checkBreakpointHit(output, symbol)
+}
+
+// noSymbols matches the complaints of gdb about the symbols of a kernel.
+var noSymbols = regexp.MustCompile(`(?i)no symbol table is loaded|no debugging symbols found|function "[^"]*" not defined`)
+
+// checkBreakpointHit checks that the output of gdb reports the breakpoint on
+// symbol as hit.
+func checkBreakpointHit(output, symbol string) error {
+	hit := regexp.MustCompile(`(?m)^(Hardware assisted )?[Bb]reakpoint 1, .*\b` + regexp.QuoteMeta(symbol) + `\b`)
+	if !hit.MatchString(output) {
+		return fmt.Errorf("breakpoint on %s not hit", symbol)
+	}
+
+	return nil
+}
+
+// entrySymbol checks that the ELF kernel at path has the symbol want, when
+// set, and returns it.  Otherwise it returns the name of the function at the
+// entry point of the kernel.
+func entrySymbol(path, want string) (string, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("could not read debug kernel %s: %w", path, err)
+	}
+	defer f.Close()
+
+	syms, err := f.Symbols()
+	if err != nil {
+		return "", fmt.Errorf("debug kernel %s has no symbols: %w", path, err)
+	}
+
+	if want != "" {
+		for _, s := range syms {
+			if s.Name == want && s.Section != elf.SHN_UNDEF {
+				return want, nil
+			}
+		}
+
+		return "", fmt.Errorf("debug kernel %s has no symbol %s", path, want)
+	}
+
+	name := ""
+	for _, s := range syms {
+		if s.Value != f.Entry || s.Name == "" || s.Section == elf.SHN_UNDEF {
+			continue
+		}
+		if t := elf.ST_TYPE(s.Info); t != elf.STT_FUNC && t != elf.STT_NOTYPE {
+			continue
+		}
+
+		// Prefer global symbols over local aliases of the entry point.
+		if name == "" || elf.ST_BIND(s.Info) == elf.STB_GLOBAL {
+			name = s.Name
+		}
+	}
+
+	if name == "" {
+		return "", fmt.Errorf("no symbol of debug kernel %s is at its entry point %#x, set entry_symbol", path, f.Entry)
+	}
+
+	return name, nil
+}
+
+// freePort returns a local TCP port nothing listens on.
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// waitForPort waits until something listens on the local TCP port.
+func waitForPort(ctx context.Context, port int) error {
+	addr := "127.0.0.1:" + strconv.Itoa(port)
+	for {
+		conn, err := net.DialTimeout("tcp", addr, time.Second)
+		if err == nil {
+			return conn.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
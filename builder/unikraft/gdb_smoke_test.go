@@ -0,0 +1,113 @@
+package unikraft
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGDBSmokeTestQEMUArgs(t *testing.T) {
+	test := GDBSmokeTest{Kernel: "/app/.unikraft/dist/app_qemu-arm64", Architecture: "arm64"}
+
+	binary, args, err := test.qemuArgs(4321)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if binary != "qemu-system-aarch64" {
+		t.Errorf("binary = %s, want qemu-system-aarch64", binary)
+	}
+
+	want := []string{
+		"-machine", "virt", "-cpu", "max",
+		"-kernel", "/app/.unikraft/dist/app_qemu-arm64",
+		"-display", "none",
+		"-no-reboot",
+		"-gdb", "tcp:127.0.0.1:4321",
+		"-S",
+	}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %q, want %q", args, want)
+	}
+
+	test.QEMU = "/opt/qemu/bin/qemu-system-aarch64"
+	if binary, _, _ := test.qemuArgs(4321); binary != test.QEMU {
+		t.Errorf("binary = %s, want %s", binary, test.QEMU)
+	}
+
+	test.Architecture = "riscv64"
+	if _, _, err := test.qemuArgs(4321); err == nil {
+		t.Error("expected an unknown architecture to be rejected")
+	}
+}
+
+func TestGDBSmokeTestGDBArgs(t *testing.T) {
+	test := GDBSmokeTest{DebugKernel: "/app/.unikraft/dist/app_qemu-x86_64.dbg"}
+
+	args := test.gdbArgs(4321, "_libkvmplat_entry")
+	joined := strings.Join(args, " ")
+	for _, want := range []string{"-batch", "target remote 127.0.0.1:4321", "hbreak _libkvmplat_entry", "continue"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("args %q do not contain %q", args, want)
+		}
+	}
+	if args[len(args)-1] != test.DebugKernel {
+		t.Errorf("expected gdb to load the debug kernel, got %q", args)
+	}
+}
+
+func TestCheckBreakpointHit(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{name: "hardware breakpoint", output: "Hardware assisted breakpoint 1 at 0x100000\n\nHardware assisted breakpoint 1, 0x0000000000100000 in _libkvmplat_entry ()\n"},
+		{name: "breakpoint with source", output: "Breakpoint 1, _libkvmplat_entry (arg=0x0) at plat/kvm/x86/setup.c:42\n"},
+		{name: "other symbol", output: "Breakpoint 1, _libkvmplat_entry2 () at setup.c:1\n", want: "breakpoint on _libkvmplat_entry not hit"},
+		{name: "not hit", output: "Remote debugging using 127.0.0.1:4321\n", want: "breakpoint on _libkvmplat_entry not hit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkBreakpointHit(tt.output, "_libkvmplat_entry")
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Errorf("expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestEntrySymbol(t *testing.T) {
+	kernel := filepath.Join(t.TempDir(), "app_qemu-x86_64.dbg")
+	writeKernelELF(t, kernel)
+
+	if got, err := entrySymbol(kernel, ""); err != nil || got != "_start" {
+		t.Errorf("entrySymbol() = %q, %v, want _start", got, err)
+	}
+	if got, err := entrySymbol(kernel, "helper"); err != nil || got != "helper" {
+		t.Errorf("entrySymbol(helper) = %q, %v, want helper", got, err)
+	}
+	if _, err := entrySymbol(kernel, "ukplat_halt"); err == nil || !strings.Contains(err.Error(), "has no symbol ukplat_halt") {
+		t.Errorf("expected an undefined symbol to be rejected, got %v", err)
+	}
+}
+
+func TestGDBSmokeTestMissingQEMU(t *testing.T) {
+	kernel := filepath.Join(t.TempDir(), "app_qemu-x86_64.dbg")
+	writeKernelELF(t, kernel)
+
+	test := GDBSmokeTest{
+		Kernel:       kernel,
+		DebugKernel:  kernel,
+		Architecture: "x86_64",
+		QEMU:         filepath.Join(t.TempDir(), "missing"),
+	}
+	if _, err := test.Run(context.Background()); err == nil || !strings.Contains(err.Error(), "could not start qemu") {
+		t.Errorf("expected a missing qemu to be reported, got %v", err)
+	}
+}
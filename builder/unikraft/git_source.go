@@ -0,0 +1,134 @@
+package unikraft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// GitSource is a Kraftfile project cloned from a Git repository before it is
+// built.
+type GitSource struct {
+	// Repository is the URL of the repository to clone.
+	Repository string
+	// Ref is the branch, tag or commit to check out.  Defaults to the
+	// default branch of the repository.
+	Ref string
+	// Path is the directory of the project in the repository, relative to
+	// its root.
+	Path string
+}
+
+// commitHash matches abbreviated and full Git commit hashes.
+var commitHash = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+// Validate checks that the source names a repository and a project inside of
+// it.
+func (s GitSource) Validate() error {
+	if len(s.Repository) == 0 {
+		return fmt.Errorf("source_repository must be specified")
+	}
+
+	if len(s.Path) > 0 {
+		if path.IsAbs(s.Path) || filepath.IsAbs(s.Path) {
+			return fmt.Errorf("source_path %q must be relative to the repository root", s.Path)
+		}
+
+		if clean := path.Clean(filepath.ToSlash(s.Path)); clean == ".." || strings.HasPrefix(clean, "../") {
+			return fmt.Errorf("source_path %q must not leave the repository", s.Path)
+		}
+	}
+
+	return nil
+}
+
+// IsCommit reports whether the ref of the source is a commit hash rather than
+// a branch or a tag.
+func (s GitSource) IsCommit() bool {
+	return commitHash.MatchString(s.Ref)
+}
+
+// RefCandidates returns the fully qualified references the ref of the source
+// may name, in the order they are tried: a branch, then a tag.  Refs which
+// are already qualified, and commit hashes, are returned as is.
+func (s GitSource) RefCandidates() []string {
+	switch {
+	case len(s.Ref) == 0:
+		return nil
+	case strings.HasPrefix(s.Ref, "refs/"), s.IsCommit():
+		return []string{s.Ref}
+	}
+
+	return []string{
+		"refs/heads/" + s.Ref,
+		"refs/tags/" + s.Ref,
+	}
+}
+
+// ProjectPath returns the directory of the project once the repository is
+// cloned to dir.
+func (s GitSource) ProjectPath(dir string) string {
+	return filepath.Join(dir, filepath.FromSlash(s.Path))
+}
+
+// Clone clones the repository of the source to dir and checks out its ref.
+// Branches and tags are cloned shallowly; commits need the full history.
+func (s GitSource) Clone(ctx context.Context, dir string) error {
+	if s.IsCommit() {
+		repo, err := git.PlainCloneContext(ctx, dir, false, &git.CloneOptions{
+			URL: s.Repository,
+		})
+		if err != nil {
+			return fmt.Errorf("could not clone %s: %w", s.Repository, err)
+		}
+
+		hash, err := repo.ResolveRevision(plumbing.Revision(s.Ref))
+		if err != nil {
+			return fmt.Errorf("could not find commit %s in %s: %w", s.Ref, s.Repository, err)
+		}
+
+		worktree, err := repo.Worktree()
+		if err != nil {
+			return err
+		}
+
+		return worktree.Checkout(&git.CheckoutOptions{Hash: *hash})
+	}
+
+	candidates := s.RefCandidates()
+	if len(candidates) == 0 {
+		// The default branch of the repository.
+		candidates = []string{""}
+	}
+
+	for _, ref := range candidates {
+		_, err := git.PlainCloneContext(ctx, dir, false, &git.CloneOptions{
+			URL:           s.Repository,
+			ReferenceName: plumbing.ReferenceName(ref),
+			SingleBranch:  true,
+			Depth:         1,
+		})
+		if err == nil {
+			return nil
+		}
+
+		if !errors.Is(err, git.NoMatchingRefSpecError{}) && !errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return fmt.Errorf("could not clone %s: %w", s.Repository, err)
+		}
+
+		// Try the next candidate from a clean directory.
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+	}
+
+	return fmt.Errorf("could not clone %s: no branch or tag named %s", s.Repository, s.Ref)
+}
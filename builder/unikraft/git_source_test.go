@@ -0,0 +1,72 @@
+package unikraft
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGitSourceValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		source GitSource
+		want   string
+	}{
+		{name: "repository", source: GitSource{Repository: "https://github.com/unikraft/catalog"}},
+		{name: "project path", source: GitSource{Repository: "https://github.com/unikraft/catalog", Path: "library/nginx/1.25"}},
+		{name: "no repository", source: GitSource{Path: "library/nginx/1.25"}, want: "source_repository must be specified"},
+		{name: "absolute path", source: GitSource{Repository: "https://github.com/unikraft/catalog", Path: "/library"}, want: "must be relative"},
+		{name: "escaping path", source: GitSource{Repository: "https://github.com/unikraft/catalog", Path: "library/../../etc"}, want: "must not leave the repository"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.source.Validate()
+			if tt.want == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestGitSourceRefCandidates(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want []string
+	}{
+		{ref: "", want: nil},
+		{ref: "stable", want: []string{"refs/heads/stable", "refs/tags/stable"}},
+		{ref: "v0.15.0", want: []string{"refs/heads/v0.15.0", "refs/tags/v0.15.0"}},
+		{ref: "refs/pull/42/head", want: []string{"refs/pull/42/head"}},
+		{ref: "3f2a9c1", want: []string{"3f2a9c1"}},
+	}
+
+	for _, tt := range tests {
+		if got := (GitSource{Ref: tt.ref}).RefCandidates(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("RefCandidates(%q) = %v, want %v", tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestGitSourceIsCommit(t *testing.T) {
+	if !(GitSource{Ref: "3f2a9c1e5b7d"}).IsCommit() {
+		t.Error("expected an abbreviated hash to be a commit")
+	}
+	if (GitSource{Ref: "staging"}).IsCommit() {
+		t.Error("expected a branch not to be a commit")
+	}
+}
+
+func TestGitSourceProjectPath(t *testing.T) {
+	s := GitSource{Path: "library/nginx/1.25"}
+	if got, want := s.ProjectPath("/tmp/clone"), filepath.Join("/tmp/clone", "library", "nginx", "1.25"); got != want {
+		t.Errorf("ProjectPath() = %q, want %q", got, want)
+	}
+
+	if got := (GitSource{}).ProjectPath("/tmp/clone"); got != "/tmp/clone" {
+		t.Errorf("ProjectPath() = %q, want the clone itself", got)
+	}
+}
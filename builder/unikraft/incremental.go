@@ -0,0 +1,151 @@
+package unikraft
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// buildFingerprintPath returns the file recording the fingerprint of the last
+// build of target in the project at workdir.  It is kept in the build folder,
+// such that cleaning the build forgets it along with the prepared sources.
+func buildFingerprintPath(workdir, target string) string {
+	return filepath.Join(workdir, ".unikraft", "build", ".packer-fingerprint-"+target)
+}
+
+// buildFingerprint returns the fingerprint of building a target with in, the
+// project being described by the Kraftfile at kraftfile.  It changes with
+// anything the configure and prepare phases depend on.
+func buildFingerprint(kraftfile string, in BuildInputs) (string, error) {
+	digest, err := fileDigest(kraftfile)
+	if err != nil {
+		return "", err
+	}
+
+	options := map[string]string{"kraftfile": digest}
+	for k, v := range in.Options {
+		options[k] = v
+	}
+	in.Options = options
+
+	return in.ID(), nil
+}
+
+// fingerprintUnchanged reports whether the last build recorded at path had
+// fingerprint and left its configuration at dotconfig, such that configuring
+// and preparing the target again can be skipped.  Otherwise, the reason is
+// returned.
+func fingerprintUnchanged(path, fingerprint, dotconfig string) (bool, string) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return false, "no previous build"
+	}
+
+	if strings.TrimSpace(string(raw)) != fingerprint {
+		return false, "inputs changed since the previous build"
+	}
+
+	if _, err := os.Stat(dotconfig); err != nil {
+		return false, fmt.Sprintf("no existing configuration at %s", dotconfig)
+	}
+
+	return true, ""
+}
+
+// targetFingerprint is the fingerprint of building a target, along with where
+// its last build recorded its own and left its configuration.
+type targetFingerprint struct {
+	path        string
+	fingerprint string
+	dotconfig   string
+}
+
+// sourcesUnchanged reports whether fetching the sources of a build can be
+// skipped: the sources of its components are at dirs already, and each of
+// targets is unchanged since its last build.  Otherwise, the reason is
+// returned.
+func sourcesUnchanged(dirs []string, targets []targetFingerprint) (bool, string) {
+	if len(targets) == 0 {
+		return false, "no target to build"
+	}
+
+	for _, dir := range dirs {
+		if _, err := os.Stat(dir); err != nil {
+			return false, fmt.Sprintf("no sources at %s", dir)
+		}
+	}
+
+	for _, t := range targets {
+		if ok, reason := fingerprintUnchanged(t.path, t.fingerprint, t.dotconfig); !ok {
+			return false, reason
+		}
+	}
+
+	return true, ""
+}
+
+// writeBuildFingerprint records fingerprint at path once a build succeeded.
+func writeBuildFingerprint(path, fingerprint string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, []byte(fingerprint+"\n"), 0o644)
+}
+
+// removeBuiltKernels removes the executable files at the root of build, such
+// that the kernels kept from a previous build are not mistaken for those of
+// the current one.  Only the final link of the kernels is redone.
+func removeBuiltKernels(build string) error {
+	entries, err := os.ReadDir(build)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		if info.Mode().IsRegular() && info.Mode()&0o111 != 0 {
+			if err := os.Remove(filepath.Join(build, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// restoreBuildDir moves the files of dist into build, replacing those of the
+// same name, and removes dist.  The objects of the build are kept for the
+// next one.
+func restoreBuildDir(dist, build string) error {
+	entries, err := os.ReadDir(dist)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(build, 0o755); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		dst := filepath.Join(build, entry.Name())
+		if err := os.RemoveAll(dst); err != nil {
+			return err
+		}
+		if err := os.Rename(filepath.Join(dist, entry.Name()), dst); err != nil {
+			return err
+		}
+	}
+
+	return os.Remove(dist)
+}
@@ -0,0 +1,197 @@
+package unikraft
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildFingerprint(t *testing.T) {
+	kraftfile := filepath.Join(t.TempDir(), "Kraftfile")
+	if err := os.WriteFile(kraftfile, []byte("spec: v0.6\nname: app\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	in := BuildInputs{
+		Targets: []BuildInputTarget{{
+			Name:         "app-qemu-x86_64",
+			Architecture: "x86_64",
+			Platform:     "qemu",
+			KConfig:      map[string]string{"CONFIG_LIBUKDEBUG": "y"},
+		}},
+		Components: map[string]string{"unikraft": "0.16.0"},
+		Options:    map[string]string{"kernel_dbg": "false"},
+	}
+
+	first, err := buildFingerprint(kraftfile, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := buildFingerprint(kraftfile, in); again != first {
+		t.Errorf("fingerprint is not stable: %s, then %s", first, again)
+	}
+	if in.Options["kraftfile"] != "" {
+		t.Error("expected the inputs to be left unchanged")
+	}
+
+	in.Components = map[string]string{"unikraft": "0.17.0"}
+	if other, _ := buildFingerprint(kraftfile, in); other == first {
+		t.Error("expected a component version to change the fingerprint")
+	}
+	in.Components = map[string]string{"unikraft": "0.16.0"}
+
+	if err := os.WriteFile(kraftfile, []byte("spec: v0.6\nname: other\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if other, _ := buildFingerprint(kraftfile, in); other == first {
+		t.Error("expected the Kraftfile to change the fingerprint")
+	}
+
+	if _, err := buildFingerprint(filepath.Join(t.TempDir(), "Kraftfile"), in); err == nil {
+		t.Error("expected a missing Kraftfile to fail")
+	}
+}
+
+func TestFingerprintUnchanged(t *testing.T) {
+	workdir := t.TempDir()
+	path := buildFingerprintPath(workdir, "app-qemu-x86_64")
+	dotconfig := filepath.Join(workdir, ".config.app_qemu-x86_64")
+
+	if ok, reason := fingerprintUnchanged(path, "abc", dotconfig); ok || reason != "no previous build" {
+		t.Errorf("without a previous build: %v, %q", ok, reason)
+	}
+
+	if err := writeBuildFingerprint(path, "abc"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, reason := fingerprintUnchanged(path, "abc", dotconfig); ok {
+		t.Error("expected a missing .config to require configuring")
+	} else if reason != "no existing configuration at "+dotconfig {
+		t.Errorf("reason = %q", reason)
+	}
+
+	if err := os.WriteFile(dotconfig, []byte("CONFIG_PLAT_KVM=y\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if ok, reason := fingerprintUnchanged(path, "abc", dotconfig); !ok {
+		t.Errorf("expected the build to be unchanged: %s", reason)
+	}
+	if ok, reason := fingerprintUnchanged(path, "def", dotconfig); ok || reason != "inputs changed since the previous build" {
+		t.Errorf("with other inputs: %v, %q", ok, reason)
+	}
+}
+
+func TestSourcesUnchanged(t *testing.T) {
+	workdir := t.TempDir()
+	sources := filepath.Join(workdir, ".unikraft", "unikraft")
+	if err := os.MkdirAll(sources, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	targets := []targetFingerprint{{
+		path:        buildFingerprintPath(workdir, "app-qemu-x86_64"),
+		fingerprint: "abc",
+		dotconfig:   filepath.Join(workdir, ".config.app_qemu-x86_64"),
+	}}
+	if ok, reason := sourcesUnchanged([]string{sources}, targets); ok || reason != "no previous build" {
+		t.Errorf("without a previous build: %v, %q", ok, reason)
+	}
+
+	// A rebuild with the same inputs does not fetch anything.
+	if err := writeBuildFingerprint(targets[0].path, "abc"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(targets[0].dotconfig, []byte("CONFIG_PLAT_KVM=y\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if ok, reason := sourcesUnchanged([]string{sources}, targets); !ok {
+		t.Errorf("expected the fetch to be skipped: %s", reason)
+	}
+
+	missing := filepath.Join(workdir, ".unikraft", "libs", "musl")
+	if ok, reason := sourcesUnchanged([]string{sources, missing}, targets); ok || reason != "no sources at "+missing {
+		t.Errorf("with missing sources: %v, %q", ok, reason)
+	}
+
+	targets[0].fingerprint = "def"
+	if ok, reason := sourcesUnchanged([]string{sources}, targets); ok || reason != "inputs changed since the previous build" {
+		t.Errorf("with other inputs: %v, %q", ok, reason)
+	}
+
+	if ok, _ := sourcesUnchanged([]string{sources}, nil); ok {
+		t.Error("expected a build without targets to fetch")
+	}
+}
+
+func TestRemoveBuiltKernels(t *testing.T) {
+	build := t.TempDir()
+	files := map[string]os.FileMode{
+		"app_qemu-x86_64":             0o755,
+		"app_qemu-x86_64.dbg":         0o755,
+		".packer-fingerprint-app":     0o644,
+		"libukdebug/origin/script.sh": 0o755,
+	}
+	for name, mode := range files {
+		path := filepath.Join(build, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := removeBuiltKernels(build); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, mode := range files {
+		_, err := os.Stat(filepath.Join(build, name))
+		kept := err == nil
+		if want := mode&0o111 == 0 || filepath.Dir(name) != "."; kept != want {
+			t.Errorf("%s kept = %v, want %v", name, kept, want)
+		}
+	}
+
+	if err := removeBuiltKernels(filepath.Join(build, "missing")); err != nil {
+		t.Errorf("expected a missing build folder to be ignored, got %v", err)
+	}
+}
+
+func TestRestoreBuildDir(t *testing.T) {
+	project := t.TempDir()
+	build := filepath.Join(project, "build")
+	dist := filepath.Join(project, "dist")
+	for _, dir := range []string{filepath.Join(build, "libukdebug"), dist} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for path, content := range map[string]string{
+		filepath.Join(build, "libukdebug", "ukdebug.o"): "object",
+		filepath.Join(build, "app_qemu-x86_64"):         "old",
+		filepath.Join(dist, "app_qemu-x86_64"):          "new",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := restoreBuildDir(dist, build); err != nil {
+		t.Fatal(err)
+	}
+
+	if raw, err := os.ReadFile(filepath.Join(build, "app_qemu-x86_64")); err != nil || string(raw) != "new" {
+		t.Errorf("kernel = %q, %v, want the one of dist", raw, err)
+	}
+	if _, err := os.Stat(filepath.Join(build, "libukdebug", "ukdebug.o")); err != nil {
+		t.Errorf("expected the objects to be kept: %v", err)
+	}
+	if _, err := os.Stat(dist); !os.IsNotExist(err) {
+		t.Errorf("expected dist to be removed, got %v", err)
+	}
+
+	if err := restoreBuildDir(dist, build); err != nil {
+		t.Errorf("expected a missing dist to be ignored, got %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package unikraft
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	newcTypeMask    = 0o170000
+	newcTypeRegular = 0o100000
+	newcTypeSymlink = 0o120000
+)
+
+// walkNewc calls fn with the name, mode and contents of every entry of a newc
+// cpio archive.
+func walkNewc(r io.Reader, fn func(name string, mode uint32, body io.Reader) error) error {
+	for {
+		hdr := make([]byte, newcHeaderSize)
+		if _, err := io.ReadFull(r, hdr); err != nil {
+			return fmt.Errorf("reading header: %w", err)
+		}
+
+		if string(hdr[:6]) != newcMagic {
+			return fmt.Errorf("unsupported cpio format: expected newc archive")
+		}
+
+		field := func(i int) (uint32, error) {
+			v, err := strconv.ParseUint(string(hdr[6+i*8:6+(i+1)*8]), 16, 32)
+			return uint32(v), err
+		}
+
+		mode, err1 := field(1)
+		size, err2 := field(6)
+		namesize, err3 := field(11)
+		for _, err := range []error{err1, err2, err3} {
+			if err != nil {
+				return fmt.Errorf("malformed header: %w", err)
+			}
+		}
+
+		name := make([]byte, int64(namesize)+pad4(newcHeaderSize+int64(namesize)))
+		if _, err := io.ReadFull(r, name); err != nil {
+			return fmt.Errorf("reading name: %w", err)
+		}
+		entry := strings.TrimRight(string(name[:namesize]), "\x00")
+
+		if entry == newcTrailer {
+			return nil
+		}
+
+		body := io.LimitReader(r, int64(size))
+		if err := fn(entry, mode, body); err != nil {
+			return err
+		}
+
+		// Skip whatever fn did not consume, along with the padding.
+		if _, err := io.Copy(io.Discard, body); err != nil {
+			return fmt.Errorf("reading %s: %w", entry, err)
+		}
+		if _, err := io.CopyN(io.Discard, r, pad4(int64(size))); err != nil {
+			return fmt.Errorf("reading %s: %w", entry, err)
+		}
+	}
+}
+
+// initrdDigests returns the `sha256:<hex>` digest of every file and symbolic
+// link of the newc archive at path, keyed by their cleaned path.
+func initrdDigests(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	digests := map[string]string{}
+	err = walkNewc(bufio.NewReader(f), func(name string, mode uint32, body io.Reader) error {
+		switch mode & newcTypeMask {
+		case newcTypeRegular, newcTypeSymlink:
+		default:
+			return nil
+		}
+
+		h := sha256.New()
+		if _, err := io.Copy(h, body); err != nil {
+			return err
+		}
+
+		digests[cleanInitrdPath(name)] = "sha256:" + hex.EncodeToString(h.Sum(nil))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not read initramfs %s: %w", path, err)
+	}
+
+	return digests, nil
+}
+
+// LoadInitrdManifest reads an expected initramfs manifest: a JSON object
+// mapping paths to their `sha256:<hex>` digest.
+func LoadInitrdManifest(path string) (map[string]string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read initramfs manifest: %w", err)
+	}
+
+	var manifest map[string]string
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return nil, fmt.Errorf("could not parse initramfs manifest %s: %w", path, err)
+	}
+
+	return manifest, nil
+}
+
+// verifyInitrdManifest compares the digests of an initramfs against the
+// expected manifest and returns an error listing every missing, extra and
+// changed file.
+func verifyInitrdManifest(actual, expected map[string]string) error {
+	want := map[string]string{}
+	for path, digest := range expected {
+		digest = strings.ToLower(strings.TrimSpace(digest))
+		if !strings.HasPrefix(digest, "sha256:") {
+			digest = "sha256:" + digest
+		}
+		want[cleanInitrdPath(path)] = digest
+	}
+
+	var diff []string
+	for path, digest := range want {
+		got, ok := actual[path]
+		switch {
+		case !ok:
+			diff = append(diff, fmt.Sprintf("- %s: missing", path))
+		case got != digest:
+			diff = append(diff, fmt.Sprintf("~ %s: expected %s, got %s", path, digest, got))
+		}
+	}
+	for path := range actual {
+		if _, ok := want[path]; !ok {
+			diff = append(diff, fmt.Sprintf("+ %s: unexpected", path))
+		}
+	}
+
+	if len(diff) == 0 {
+		return nil
+	}
+
+	sort.Slice(diff, func(i, j int) bool { return diff[i][2:] < diff[j][2:] })
+	return fmt.Errorf("initramfs does not match its manifest:\n%s", strings.Join(diff, "\n"))
+}
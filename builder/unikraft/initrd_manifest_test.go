@@ -0,0 +1,65 @@
+package unikraft
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func sha256Digest(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func TestVerifyInitrdManifest(t *testing.T) {
+	dir := t.TempDir()
+
+	expected := map[string]string{
+		"/etc/nginx/nginx.conf": sha256Digest("worker_processes 1;\n"),
+		"/www/index.html":       sha256Digest("<html></html>\n"),
+	}
+
+	matching := filepath.Join(dir, "matching.cpio")
+	writeNewc(t, matching, []newcEntry{
+		{name: "./etc", mode: 0o040755},
+		{name: "./etc/nginx", mode: 0o040755},
+		{name: "./etc/nginx/nginx.conf", mode: 0o100644, data: "worker_processes 1;\n"},
+		{name: "./www", mode: 0o040755},
+		{name: "./www/index.html", mode: 0o100644, data: "<html></html>\n"},
+	})
+
+	actual, err := initrdDigests(matching)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyInitrdManifest(actual, expected); err != nil {
+		t.Errorf("expected matching initramfs to pass, got %v", err)
+	}
+
+	drifted := filepath.Join(dir, "drifted.cpio")
+	writeNewc(t, drifted, []newcEntry{
+		{name: "./etc/nginx/nginx.conf", mode: 0o100644, data: "worker_processes 4;\n"},
+		{name: "./www/debug.html", mode: 0o100644, data: "debug\n"},
+	})
+
+	actual, err = initrdDigests(drifted)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = verifyInitrdManifest(actual, expected)
+	if err == nil {
+		t.Fatal("expected drifted initramfs to fail")
+	}
+	for _, want := range []string{
+		"~ etc/nginx/nginx.conf: expected " + expected["/etc/nginx/nginx.conf"],
+		"+ www/debug.html: unexpected",
+		"- www/index.html: missing",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected diff to contain %q, got:\n%v", want, err)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package unikraft
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	newcMagic      = "070701"
+	newcHeaderSize = 110
+	newcTrailer    = "TRAILER!!!"
+)
+
+// InitrdPermissions describes the ownership and mode of the entries staged in
+// an initramfs built from a directory.
+type InitrdPermissions struct {
+	// Uid is the default owner of every entry.
+	Uid int
+	// Gid is the default group of every entry.
+	Gid int
+	// Umask is removed from the permission bits of every entry.
+	Umask os.FileMode
+	// Overrides set the ownership or mode of specific paths in the archive.
+	Overrides map[string]InitrdEntryPermissions
+}
+
+// InitrdEntryPermissions overrides the ownership or mode of a single entry.
+// Unset fields keep the defaults of InitrdPermissions.
+type InitrdEntryPermissions struct {
+	Uid  *int
+	Gid  *int
+	Mode *os.FileMode
+}
+
+// apply returns the mode, uid and gid an entry is archived with.
+func (p *InitrdPermissions) apply(name string, mode, uid, gid uint32) (uint32, uint32, uint32) {
+	uid = uint32(p.Uid)
+	gid = uint32(p.Gid)
+	mode = mode &^ uint32(p.Umask.Perm())
+
+	for path, o := range p.Overrides {
+		if cleanInitrdPath(path) != cleanInitrdPath(name) {
+			continue
+		}
+
+		if o.Uid != nil {
+			uid = uint32(*o.Uid)
+		}
+		if o.Gid != nil {
+			gid = uint32(*o.Gid)
+		}
+		if o.Mode != nil {
+			mode = (mode &^ 0o7777) | (uint32(*o.Mode) & 0o7777)
+		}
+	}
+
+	return mode, uid, gid
+}
+
+// cleanInitrdPath strips the leading `./` or `/` of an archive path so that
+// overrides match regardless of how the archive names its entries.
+func cleanInitrdPath(name string) string {
+	return strings.TrimLeft(strings.TrimPrefix(filepath.ToSlash(name), "./"), "/")
+}
+
+// stageInitrdPermissions rewrites the newc cpio archive at src into dst with
+// the given permissions applied to each entry.
+func stageInitrdPermissions(src, dst string, perms *InitrdPermissions) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	if err := rewriteNewc(bufio.NewReader(in), out, perms.apply); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return fmt.Errorf("could not stage initramfs permissions: %w", err)
+	}
+
+	return out.Close()
+}
+
+// rewriteNewc copies a newc cpio archive from r to w, passing the mode, uid
+// and gid of every entry through fn.  Entry names and contents are copied
+// verbatim.
+func rewriteNewc(r io.Reader, w io.Writer, fn func(name string, mode, uid, gid uint32) (uint32, uint32, uint32)) error {
+	for {
+		hdr := make([]byte, newcHeaderSize)
+		if _, err := io.ReadFull(r, hdr); err != nil {
+			return fmt.Errorf("reading header: %w", err)
+		}
+
+		if string(hdr[:6]) != newcMagic {
+			return fmt.Errorf("unsupported cpio format: expected newc archive")
+		}
+
+		field := func(i int) (uint32, error) {
+			v, err := strconv.ParseUint(string(hdr[6+i*8:6+(i+1)*8]), 16, 32)
+			return uint32(v), err
+		}
+
+		mode, err1 := field(1)
+		uid, err2 := field(2)
+		gid, err3 := field(3)
+		size, err4 := field(6)
+		namesize, err5 := field(11)
+		for _, err := range []error{err1, err2, err3, err4, err5} {
+			if err != nil {
+				return fmt.Errorf("malformed header: %w", err)
+			}
+		}
+
+		name := make([]byte, int64(namesize)+pad4(newcHeaderSize+int64(namesize)))
+		if _, err := io.ReadFull(r, name); err != nil {
+			return fmt.Errorf("reading name: %w", err)
+		}
+		entry := strings.TrimRight(string(name[:namesize]), "\x00")
+
+		if entry != newcTrailer {
+			mode, uid, gid = fn(entry, mode, uid, gid)
+			copy(hdr[6+1*8:], fmt.Sprintf("%08X%08X%08X", mode, uid, gid))
+		}
+
+		if _, err := w.Write(hdr); err != nil {
+			return err
+		}
+		if _, err := w.Write(name); err != nil {
+			return err
+		}
+
+		body := int64(size) + pad4(int64(size))
+		if _, err := io.CopyN(w, r, body); err != nil {
+			return fmt.Errorf("copying %s: %w", entry, err)
+		}
+
+		if entry == newcTrailer {
+			// Preserve any block padding following the trailer.
+			_, err := io.Copy(w, r)
+			return err
+		}
+	}
+}
+
+func pad4(n int64) int64 {
+	return (4 - n%4) % 4
+}
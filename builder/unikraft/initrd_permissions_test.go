@@ -0,0 +1,110 @@
+package unikraft
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+type newcEntry struct {
+	name string
+	mode uint32
+	uid  uint32
+	gid  uint32
+	data string
+}
+
+func writeNewc(t *testing.T, path string, entries []newcEntry) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	for _, e := range append(entries, newcEntry{name: newcTrailer}) {
+		name := e.name + "\x00"
+		fmt.Fprintf(&buf, "%s%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X",
+			newcMagic, 0, e.mode, e.uid, e.gid, 1, 0, len(e.data), 0, 0, 0, 0, len(name), 0)
+		buf.WriteString(name)
+		buf.Write(make([]byte, pad4(int64(newcHeaderSize+len(name)))))
+		buf.WriteString(e.data)
+		buf.Write(make([]byte, pad4(int64(len(e.data)))))
+	}
+
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readNewc(t *testing.T, path string) map[string]newcEntry {
+	t.Helper()
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries := map[string]newcEntry{}
+	err = rewriteNewc(bytes.NewReader(raw), &bytes.Buffer{}, func(name string, mode, uid, gid uint32) (uint32, uint32, uint32) {
+		entries[name] = newcEntry{name: name, mode: mode, uid: uid, gid: gid}
+		return mode, uid, gid
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return entries
+}
+
+func TestStageInitrdPermissions(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "initramfs.cpio")
+	dst := filepath.Join(dir, "initramfs-staged.cpio")
+
+	writeNewc(t, src, []newcEntry{
+		{name: "./etc", mode: 0o40777, uid: 1000, gid: 1000},
+		{name: "./etc/passwd", mode: 0o100666, uid: 1000, gid: 1000, data: "root:x:0:0::/:\n"},
+		{name: "./bin/nginx", mode: 0o100777, uid: 1000, gid: 1000, data: "ELF"},
+	})
+
+	nobody := 65534
+	secret := os.FileMode(0o600)
+	perms := &InitrdPermissions{
+		Uid:   0,
+		Gid:   0,
+		Umask: 0o022,
+		Overrides: map[string]InitrdEntryPermissions{
+			"/etc/passwd": {Uid: &nobody, Mode: &secret},
+		},
+	}
+
+	if err := stageInitrdPermissions(src, dst, perms); err != nil {
+		t.Fatal(err)
+	}
+
+	got := readNewc(t, dst)
+	want := map[string]newcEntry{
+		"./etc":        {mode: 0o40755, uid: 0, gid: 0},
+		"./etc/passwd": {mode: 0o100600, uid: 65534, gid: 0},
+		"./bin/nginx":  {mode: 0o100755, uid: 0, gid: 0},
+	}
+
+	for name, w := range want {
+		g, ok := got[name]
+		if !ok {
+			t.Errorf("missing entry %s", name)
+			continue
+		}
+		if g.mode != w.mode || g.uid != w.uid || g.gid != w.gid {
+			t.Errorf("%s: got mode=%s uid=%d gid=%d, want mode=%s uid=%d gid=%d", name,
+				strconv.FormatUint(uint64(g.mode), 8), g.uid, g.gid,
+				strconv.FormatUint(uint64(w.mode), 8), w.uid, w.gid)
+		}
+	}
+
+	srcInfo, _ := os.Stat(src)
+	dstInfo, _ := os.Stat(dst)
+	if srcInfo.Size() != dstInfo.Size() {
+		t.Errorf("staged archive size %d differs from source %d", dstInfo.Size(), srcInfo.Size())
+	}
+}
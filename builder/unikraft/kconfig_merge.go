@@ -0,0 +1,125 @@
+package unikraft
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// kconfigSymbolPattern matches KConfig symbol names, with or without their
+// `CONFIG_` prefix.
+var kconfigSymbolPattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// KConfigLayer is a set of KConfig symbols merged into the configuration of a
+// target, along with where they come from.
+type KConfigLayer struct {
+	// Source names the layer in reports and errors, such as the path of a
+	// fragment.
+	Source string
+	Values map[string]string
+}
+
+// KConfigOption is the effective value of a symbol and the layer setting it.
+type KConfigOption struct {
+	Value  string
+	Source string
+}
+
+// kconfigSymbol returns symbol with its `CONFIG_` prefix.
+func kconfigSymbol(symbol string) string {
+	if strings.HasPrefix(symbol, "CONFIG_") {
+		return symbol
+	}
+
+	return "CONFIG_" + symbol
+}
+
+// readKConfigFragment parses a fragment, which uses the syntax of a .config
+// file.
+func readKConfigFragment(path string) (KConfigLayer, error) {
+	values, err := readDotConfig(path)
+	if err != nil {
+		return KConfigLayer{}, fmt.Errorf("could not read kconfig fragment: %w", err)
+	}
+
+	return KConfigLayer{Source: path, Values: values}, nil
+}
+
+// MergeKConfig returns the effective options of the fragments followed by the
+// overrides.  Fragments are merged as equals, so that two of them setting a
+// symbol to different values conflict.  Each override then replaces the
+// values set before it.
+func MergeKConfig(fragments []KConfigLayer, overrides ...KConfigLayer) (map[string]KConfigOption, error) {
+	options := map[string]KConfigOption{}
+
+	var conflicts []string
+	for _, layer := range fragments {
+		err := mergeKConfigLayer(options, layer, func(symbol string, prev, next KConfigOption) {
+			conflicts = append(conflicts, fmt.Sprintf("%s is %q in %s but %q in %s", symbol, prev.Value, prev.Source, next.Value, next.Source))
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("conflicting kconfig fragments: %s", strings.Join(conflicts, "; "))
+	}
+
+	for _, layer := range overrides {
+		if err := mergeKConfigLayer(options, layer, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return options, nil
+}
+
+// mergeKConfigLayer sets the values of layer in options.  When conflict is
+// not nil, it is called instead of replacing a different value.
+func mergeKConfigLayer(options map[string]KConfigOption, layer KConfigLayer, conflict func(symbol string, prev, next KConfigOption)) error {
+	for _, k := range sortedKeys(layer.Values) {
+		if !kconfigSymbolPattern.MatchString(k) {
+			return fmt.Errorf("%s: invalid kconfig symbol %q", layer.Source, k)
+		}
+
+		symbol := kconfigSymbol(k)
+		next := KConfigOption{Value: strings.Trim(layer.Values[k], `"`), Source: layer.Source}
+		if prev, ok := options[symbol]; ok && prev.Value != next.Value && conflict != nil {
+			conflict(symbol, prev, next)
+			continue
+		}
+
+		options[symbol] = next
+	}
+
+	return nil
+}
+
+// KConfigValues returns the values of the effective options.
+func KConfigValues(options map[string]KConfigOption) map[string]string {
+	values := make(map[string]string, len(options))
+	for symbol, o := range options {
+		values[symbol] = o.Value
+	}
+
+	return values
+}
+
+// KConfigReport returns a line per effective option, sorted by symbol, naming
+// the layer setting it.
+func KConfigReport(options map[string]KConfigOption) []string {
+	symbols := make([]string, 0, len(options))
+	for symbol := range options {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	lines := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		lines = append(lines, fmt.Sprintf("%s=%s (%s)", symbol, options[symbol].Value, options[symbol].Source))
+	}
+
+	return lines
+}
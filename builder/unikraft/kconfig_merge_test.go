@@ -0,0 +1,74 @@
+package unikraft
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMergeKConfig(t *testing.T) {
+	dir := t.TempDir()
+	net := filepath.Join(dir, "net.config")
+	debug := filepath.Join(dir, "debug.config")
+	if err := os.WriteFile(net, []byte("CONFIG_LWIP=y\nCONFIG_LWIP_TCP=y\n# CONFIG_LWIP_UDP is not set\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(debug, []byte("CONFIG_LIBUKDEBUG_PRINTK_INFO=y\nCONFIG_LWIP_TCP=y\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var fragments []KConfigLayer
+	for _, path := range []string{net, debug} {
+		layer, err := readKConfigFragment(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fragments = append(fragments, layer)
+	}
+
+	options, err := MergeKConfig(fragments,
+		KConfigLayer{Source: "kconfig", Values: map[string]string{"LWIP_TCP": "n", "CONFIG_STACK_SIZE": `"65536"`}},
+		KConfigLayer{Source: "kconfig of qemu/x86_64", Values: map[string]string{"CONFIG_STACK_SIZE": "131072"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]KConfigOption{
+		"CONFIG_LWIP":                   {Value: "y", Source: net},
+		"CONFIG_LWIP_TCP":               {Value: "n", Source: "kconfig"},
+		"CONFIG_LWIP_UDP":               {Value: "n", Source: net},
+		"CONFIG_LIBUKDEBUG_PRINTK_INFO": {Value: "y", Source: debug},
+		"CONFIG_STACK_SIZE":             {Value: "131072", Source: "kconfig of qemu/x86_64"},
+	}
+	if !reflect.DeepEqual(options, want) {
+		t.Errorf("MergeKConfig() = %v, want %v", options, want)
+	}
+
+	report := KConfigReport(options)
+	if len(report) != len(want) || report[0] != "CONFIG_LIBUKDEBUG_PRINTK_INFO=y ("+debug+")" {
+		t.Errorf("unexpected report %q", report)
+	}
+}
+
+func TestMergeKConfigConflicts(t *testing.T) {
+	fragments := []KConfigLayer{
+		{Source: "a.config", Values: map[string]string{"CONFIG_LWIP": "y", "CONFIG_LWIP_TCP": "y"}},
+		{Source: "b.config", Values: map[string]string{"LWIP": "y", "CONFIG_LWIP_TCP": "n"}},
+	}
+
+	_, err := MergeKConfig(fragments)
+	if err == nil || !strings.Contains(err.Error(), `CONFIG_LWIP_TCP is "y" in a.config but "n" in b.config`) {
+		t.Errorf("expected a conflict on CONFIG_LWIP_TCP, got %v", err)
+	}
+	if err != nil && strings.Contains(err.Error(), "CONFIG_LWIP is") {
+		t.Errorf("unexpected conflict on CONFIG_LWIP: %v", err)
+	}
+
+	_, err = MergeKConfig(nil, KConfigLayer{Source: "kconfig", Values: map[string]string{"LWIP TCP": "y"}})
+	if err == nil || !strings.Contains(err.Error(), `invalid kconfig symbol "LWIP TCP"`) {
+		t.Errorf("expected an invalid symbol error, got %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package unikraft
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// KConfigPolicy restricts the KConfig symbols which may be changed.  Patterns
+// may use shell wildcards and match symbols with or without their `CONFIG_`
+// prefix.  An empty policy permits every change.
+type KConfigPolicy struct {
+	// Allow lists the only symbols which may be changed, when not empty.
+	Allow []string
+	// Deny lists the symbols which must not be changed.  It takes precedence
+	// over Allow.
+	Deny []string
+}
+
+// Check returns an error when changing symbol is not permitted.
+func (p KConfigPolicy) Check(symbol string) error {
+	if matchSymbol(p.Deny, symbol) {
+		return fmt.Errorf("changing %s is denied by policy", symbol)
+	}
+
+	if len(p.Allow) > 0 && !matchSymbol(p.Allow, symbol) {
+		return fmt.Errorf("changing %s is not allowed by policy", symbol)
+	}
+
+	return nil
+}
+
+func matchSymbol(patterns []string, symbol string) bool {
+	symbol = strings.TrimPrefix(symbol, "CONFIG_")
+
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(strings.TrimPrefix(pattern, "CONFIG_"), symbol); ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,29 @@
+package unikraft
+
+import "testing"
+
+func TestKConfigPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy KConfigPolicy
+		symbol string
+		ok     bool
+	}{
+		{name: "empty policy", symbol: "CONFIG_LIBUKDEBUG", ok: true},
+		{name: "allowed", policy: KConfigPolicy{Allow: []string{"CONFIG_LIBUKDEBUG*"}}, symbol: "CONFIG_LIBUKDEBUG_PRINTK", ok: true},
+		{name: "outside allowlist", policy: KConfigPolicy{Allow: []string{"LIBUKDEBUG*"}}, symbol: "CONFIG_LIBUKSIGNAL"},
+		{name: "denied", policy: KConfigPolicy{Deny: []string{"CONFIG_LIBUKSP"}}, symbol: "LIBUKSP"},
+		{name: "deny wins", policy: KConfigPolicy{Allow: []string{"*"}, Deny: []string{"LIBUKSP"}}, symbol: "CONFIG_LIBUKSP"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Check(tt.symbol)
+			if tt.ok && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if !tt.ok && err == nil {
+				t.Errorf("expected %s to be rejected", tt.symbol)
+			}
+		})
+	}
+}
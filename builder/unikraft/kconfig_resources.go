@@ -0,0 +1,48 @@
+package unikraft
+
+import "strconv"
+
+// networkSymbols are the KConfig symbols of which any set to `y` tells the
+// kernel has network drivers.
+var networkSymbols = []string{"CONFIG_LIBUKNETDEV", "CONFIG_LIBLWIP"}
+
+// ResourceHints are the resources a kernel is expected to use, as told by the
+// KConfig it was built with.
+type ResourceHints struct {
+	// CPUs is the number of logical CPUs the kernel brings up.
+	CPUs int
+	// Network reports whether the kernel has network drivers.
+	Network bool
+}
+
+// KConfigResourceHints returns the resource hints of the kernel built with the
+// .config at path.
+func KConfigResourceHints(path string) (ResourceHints, error) {
+	symbols, err := readDotConfig(path)
+	if err != nil {
+		return ResourceHints{}, err
+	}
+
+	return kconfigResourceHints(symbols), nil
+}
+
+// kconfigResourceHints returns the resource hints of a kernel configured with
+// symbols.  Kernels without SMP support bring up a single CPU, whatever the
+// maximum number of CPUs.
+func kconfigResourceHints(symbols map[string]string) ResourceHints {
+	hints := ResourceHints{CPUs: 1}
+
+	if symbols["CONFIG_HAVE_SMP"] == "y" {
+		if n, err := strconv.Atoi(symbols["CONFIG_UKPLAT_LCPU_MAXCOUNT"]); err == nil && n > 1 {
+			hints.CPUs = n
+		}
+	}
+
+	for _, symbol := range networkSymbols {
+		if symbols[symbol] == "y" {
+			hints.Network = true
+		}
+	}
+
+	return hints
+}
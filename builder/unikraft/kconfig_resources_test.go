@@ -0,0 +1,56 @@
+package unikraft
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestKConfigResourceHints(t *testing.T) {
+	tests := []struct {
+		name    string
+		symbols map[string]string
+		want    ResourceHints
+	}{
+		{name: "defaults", symbols: map[string]string{}, want: ResourceHints{CPUs: 1}},
+		{name: "smp", symbols: map[string]string{
+			"CONFIG_HAVE_SMP":             "y",
+			"CONFIG_UKPLAT_LCPU_MAXCOUNT": "4",
+		}, want: ResourceHints{CPUs: 4}},
+		{name: "no smp", symbols: map[string]string{
+			"CONFIG_HAVE_SMP":             "n",
+			"CONFIG_UKPLAT_LCPU_MAXCOUNT": "4",
+		}, want: ResourceHints{CPUs: 1}},
+		{name: "network", symbols: map[string]string{
+			"CONFIG_LIBUKNETDEV": "y",
+		}, want: ResourceHints{CPUs: 1, Network: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := kconfigResourceHints(tt.symbols); got != tt.want {
+				t.Errorf("kconfigResourceHints() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKConfigResourceHintsReadsDotConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".config")
+	dotconfig := "CONFIG_HAVE_SMP=y\nCONFIG_UKPLAT_LCPU_MAXCOUNT=2\n# CONFIG_LIBUKNETDEV is not set\n"
+	if err := os.WriteFile(path, []byte(dotconfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := KConfigResourceHints(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (ResourceHints{CPUs: 2}); got != want {
+		t.Errorf("KConfigResourceHints() = %+v, want %+v", got, want)
+	}
+
+	if _, err := KConfigResourceHints(filepath.Join(t.TempDir(), ".config")); err == nil {
+		t.Error("expected a missing .config to fail")
+	}
+}
@@ -0,0 +1,68 @@
+package unikraft
+
+import (
+	"fmt"
+	"strings"
+)
+
+// kconfigScriptValues are the values set by the operations of a KConfig
+// script taking a symbol alone.
+var kconfigScriptValues = map[string]string{
+	"enable":  "y",
+	"disable": "n",
+	"module":  "m",
+}
+
+// ParseKConfigScript returns the symbols set by a KConfig script, in the
+// spirit of the `scripts/config` tool of Linux.  Every line is one of:
+//
+//	enable SYMBOL
+//	disable SYMBOL
+//	module SYMBOL
+//	set-str SYMBOL VALUE
+//	set-val SYMBOL VALUE
+//
+// Symbols are given with or without their `CONFIG_` prefix, and later lines
+// override earlier ones.  Empty lines and lines starting with `#` are
+// ignored.
+func ParseKConfigScript(lines []string) (KConfigLayer, error) {
+	layer := KConfigLayer{Source: "kconfig_script", Values: map[string]string{}}
+
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		op, rest, _ := strings.Cut(line, " ")
+		symbol, value, _ := strings.Cut(strings.TrimSpace(rest), " ")
+		value = strings.TrimSpace(value)
+
+		if symbol == "" {
+			return KConfigLayer{}, fmt.Errorf("kconfig_script[%d]: %s requires a symbol", i, op)
+		}
+		if !kconfigSymbolPattern.MatchString(symbol) {
+			return KConfigLayer{}, fmt.Errorf("kconfig_script[%d]: invalid kconfig symbol %q", i, symbol)
+		}
+		symbol = kconfigSymbol(symbol)
+
+		switch op {
+		case "enable", "disable", "module":
+			if value != "" {
+				return KConfigLayer{}, fmt.Errorf("kconfig_script[%d]: %s takes no value", i, op)
+			}
+			layer.Values[symbol] = kconfigScriptValues[op]
+		case "set-str":
+			layer.Values[symbol] = strings.Trim(value, `"`)
+		case "set-val":
+			if value == "" {
+				return KConfigLayer{}, fmt.Errorf("kconfig_script[%d]: set-val requires a value", i)
+			}
+			layer.Values[symbol] = value
+		default:
+			return KConfigLayer{}, fmt.Errorf("kconfig_script[%d]: unknown operation %q, expected enable, disable, module, set-str or set-val", i, op)
+		}
+	}
+
+	return layer, nil
+}
@@ -0,0 +1,75 @@
+package unikraft
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseKConfigScript(t *testing.T) {
+	layer, err := ParseKConfigScript([]string{
+		"# networking",
+		"enable LIBLWIP",
+		"",
+		"module CONFIG_LIBUKNETDEV",
+		"set-str LIBUKBOOT_BANNER \"Hello, world\"",
+		"set-val STACK_SIZE_PAGE_ORDER 4",
+		"disable LIBLWIP",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"CONFIG_LIBLWIP":               "n",
+		"CONFIG_LIBUKNETDEV":           "m",
+		"CONFIG_LIBUKBOOT_BANNER":      "Hello, world",
+		"CONFIG_STACK_SIZE_PAGE_ORDER": "4",
+	}
+	if !reflect.DeepEqual(layer.Values, want) {
+		t.Errorf("Values = %v, want %v", layer.Values, want)
+	}
+	if layer.Source != "kconfig_script" {
+		t.Errorf("Source = %s, want kconfig_script", layer.Source)
+	}
+}
+
+func TestParseKConfigScriptErrors(t *testing.T) {
+	tests := map[string]string{
+		"toggle LIBLWIP":        `kconfig_script[0]: unknown operation "toggle"`,
+		"enable":                "kconfig_script[0]: enable requires a symbol",
+		"enable LIBLWIP y":      "enable takes no value",
+		"set-val STACK_SIZE":    "set-val requires a value",
+		"enable CONFIG-LIBLWIP": `invalid kconfig symbol "CONFIG-LIBLWIP"`,
+	}
+
+	for line, want := range tests {
+		if _, err := ParseKConfigScript([]string{line}); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseKConfigScript(%q) = %v, want error containing %q", line, err, want)
+		}
+	}
+}
+
+func TestConfigKConfigOptionsScript(t *testing.T) {
+	c := &Config{
+		KConfig:       map[string]string{"CONFIG_LIBLWIP": "y", "CONFIG_LIBUKDEBUG": "y"},
+		KConfigScript: []string{"disable LIBLWIP", "disable LIBUKDEBUG"},
+	}
+
+	options, err := c.KConfigOptions(TargetConfig{
+		Architecture: "x86_64",
+		Platform:     "qemu",
+		KConfig:      map[string]string{"CONFIG_LIBUKDEBUG": "y"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]KConfigOption{
+		"CONFIG_LIBLWIP":    {Value: "n", Source: "kconfig_script"},
+		"CONFIG_LIBUKDEBUG": {Value: "y", Source: "kconfig of qemu/x86_64"},
+	}
+	if !reflect.DeepEqual(options, want) {
+		t.Errorf("KConfigOptions() = %v, want %v", options, want)
+	}
+}
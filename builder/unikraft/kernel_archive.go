@@ -0,0 +1,207 @@
+package unikraft
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// FormatRaw is the package format writing a copy of the kernel.
+	FormatRaw = "raw"
+	// FormatCPIO is the package format writing a newc cpio archive of the
+	// kernel and its initramfs.
+	FormatCPIO = "cpio"
+	// FormatTar is the package format writing a tarball of the kernel and its
+	// initramfs.
+	FormatTar = "tar"
+)
+
+// ArchiveFormats are the package formats written as a single file by the
+// plugin itself rather than by kraft.
+var ArchiveFormats = []string{FormatRaw, FormatCPIO, FormatTar}
+
+// IsArchiveFormat returns whether format is one of ArchiveFormats.
+func IsArchiveFormat(format string) bool {
+	for _, f := range ArchiveFormats {
+		if f == format {
+			return true
+		}
+	}
+
+	return false
+}
+
+// KernelArchive is a kernel, along with its initramfs, packaged as a single
+// file.
+type KernelArchive struct {
+	Kernel string
+	// Initrd, when set, is the initramfs file archived next to the kernel.
+	Initrd string
+}
+
+// archiveEntry is a file of a kernel archive.
+type archiveEntry struct {
+	name string
+	path string
+	mode int64
+	size int64
+}
+
+// Write writes the archive in format to output, and returns its
+// `sha256:<hex>` digest.
+func (a KernelArchive) Write(format, output string) (string, error) {
+	if !IsArchiveFormat(format) {
+		return "", fmt.Errorf("unsupported archive format %q, expected %s", format, strings.Join(ArchiveFormats, ", "))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(output), 0o755); err != nil {
+		return "", err
+	}
+
+	if format == FormatRaw {
+		if err := copyOutput(a.Kernel, output); err != nil {
+			return "", err
+		}
+
+		return fileDigest(output)
+	}
+
+	entries, err := a.entries()
+	if err != nil {
+		return "", err
+	}
+
+	out, err := os.Create(output)
+	if err != nil {
+		return "", err
+	}
+
+	if format == FormatTar {
+		err = writeTar(out, entries)
+	} else {
+		err = writeNewcArchive(out, entries)
+	}
+	if err != nil {
+		out.Close()
+		os.Remove(output)
+		return "", fmt.Errorf("could not write %s archive: %w", format, err)
+	}
+	if err := out.Close(); err != nil {
+		return "", err
+	}
+
+	return fileDigest(output)
+}
+
+// entries returns the files of the archive, named after their base name.
+func (a KernelArchive) entries() ([]archiveEntry, error) {
+	var entries []archiveEntry
+	for _, f := range []struct {
+		path string
+		mode int64
+	}{{a.Kernel, 0o755}, {a.Initrd, 0o644}} {
+		if f.path == "" {
+			continue
+		}
+
+		info, err := os.Stat(f.path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.Mode().IsRegular() {
+			return nil, fmt.Errorf("%s is not a file", f.path)
+		}
+
+		entries = append(entries, archiveEntry{
+			name: filepath.Base(f.path),
+			path: f.path,
+			mode: f.mode,
+			size: info.Size(),
+		})
+	}
+
+	return entries, nil
+}
+
+// writeTar writes entries to w as a tarball.
+func writeTar(w io.Writer, entries []archiveEntry) error {
+	tw := tar.NewWriter(w)
+	for _, e := range entries {
+		err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     e.name,
+			Mode:     e.mode,
+			Size:     e.size,
+			ModTime:  time.Unix(0, 0),
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := copyEntry(tw, e); err != nil {
+			return err
+		}
+	}
+
+	return tw.Close()
+}
+
+// writeNewcArchive writes entries to w as a newc cpio archive.
+func writeNewcArchive(w io.Writer, entries []archiveEntry) error {
+	header := func(ino int, name string, mode, size int64) error {
+		_, err := fmt.Fprintf(w, "%s%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%s\x00",
+			newcMagic,
+			ino,         // inode
+			mode,        // mode
+			0,           // uid
+			0,           // gid
+			1,           // nlink
+			0,           // mtime
+			size,        // filesize
+			0,           // devmajor
+			0,           // devminor
+			0,           // rdevmajor
+			0,           // rdevminor
+			len(name)+1, // namesize
+			0,           // check
+			name,
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = w.Write(make([]byte, pad4(newcHeaderSize+int64(len(name))+1)))
+		return err
+	}
+
+	for i, e := range entries {
+		if err := header(i+1, e.name, newcTypeRegular|e.mode, e.size); err != nil {
+			return err
+		}
+		if err := copyEntry(w, e); err != nil {
+			return err
+		}
+		if _, err := w.Write(make([]byte, pad4(e.size))); err != nil {
+			return err
+		}
+	}
+
+	return header(0, newcTrailer, 0, 0)
+}
+
+// copyEntry copies the contents of the file of e to w.
+func copyEntry(w io.Writer, e archiveEntry) error {
+	f, err := os.Open(e.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.CopyN(w, f, e.size)
+	return err
+}
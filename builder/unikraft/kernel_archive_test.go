@@ -0,0 +1,110 @@
+package unikraft
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestKernelArchiveWrite(t *testing.T) {
+	dir := t.TempDir()
+	kernel := filepath.Join(dir, "app_qemu-x86_64")
+	initrd := filepath.Join(dir, "initramfs.cpio")
+	if err := os.WriteFile(kernel, []byte("ELF kernel"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(initrd, []byte("initramfs"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"app_qemu-x86_64": "ELF kernel", "initramfs.cpio": "initramfs"}
+	archive := KernelArchive{Kernel: kernel, Initrd: initrd}
+
+	t.Run("raw", func(t *testing.T) {
+		output := filepath.Join(dir, "out", "app")
+		digest, err := archive.Write(FormatRaw, output)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		raw, err := os.ReadFile(output)
+		if err != nil || string(raw) != "ELF kernel" {
+			t.Errorf("expected a copy of the kernel, got %q (%v)", raw, err)
+		}
+		if !strings.HasPrefix(digest, "sha256:") {
+			t.Errorf("unexpected digest %s", digest)
+		}
+	})
+
+	t.Run("cpio", func(t *testing.T) {
+		output := filepath.Join(dir, "app.cpio")
+		if _, err := archive.Write(FormatCPIO, output); err != nil {
+			t.Fatal(err)
+		}
+
+		f, err := os.Open(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+
+		got := map[string]string{}
+		err = walkNewc(f, func(name string, mode uint32, body io.Reader) error {
+			raw, err := io.ReadAll(body)
+			got[name] = string(raw)
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for name, content := range want {
+			if got[name] != content {
+				t.Errorf("entry %s = %q, want %q", name, got[name], content)
+			}
+		}
+	})
+
+	t.Run("tar", func(t *testing.T) {
+		output := filepath.Join(dir, "app.tar")
+		if _, err := archive.Write(FormatTar, output); err != nil {
+			t.Fatal(err)
+		}
+
+		raw, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := map[string]string{}
+		tr := tar.NewReader(bytes.NewReader(raw))
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			content, err := io.ReadAll(tr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got[hdr.Name] = string(content)
+		}
+		for name, content := range want {
+			if got[name] != content {
+				t.Errorf("entry %s = %q, want %q", name, got[name], content)
+			}
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		if _, err := archive.Write("zip", filepath.Join(dir, "app.zip")); err == nil {
+			t.Error("expected an error for an unknown format")
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package unikraft
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ImportedKernel is a kernel built outside of Packer, packaged as it is
+// rather than built from a project.
+type ImportedKernel struct {
+	// Name is the name of the target of the kernel.  Defaults to
+	// `<plat>-<arch>`.
+	Name         string
+	Kernel       string
+	Architecture string
+	Platform     string
+	// Initrd, when set, is the initramfs packaged along with the kernel.
+	Initrd string
+	// Cmdline is the command line the kernel is packaged with.
+	Cmdline string
+	// UnikraftVersion is the version of Unikraft the kernel was built with,
+	// if known.
+	UnikraftVersion string
+}
+
+// TargetName returns the name of the target of the kernel.
+func (k ImportedKernel) TargetName() string {
+	if k.Name != "" {
+		return k.Name
+	}
+
+	return k.Platform + "-" + k.Architecture
+}
+
+// Validate checks that the kernel, and the initramfs when it is set and not a
+// directory, are non-empty files, and that the architecture and platform are
+// known.
+func (k ImportedKernel) Validate() error {
+	var errs []string
+
+	if err := checkImportedFile("kernel", k.Kernel); err != nil {
+		errs = append(errs, err.Error())
+	}
+	// The initramfs may also be constructed from a directory.
+	if info, err := os.Stat(k.Initrd); k.Initrd != "" && (err != nil || !info.IsDir()) {
+		if err := checkImportedFile("initrd", k.Initrd); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if _, ok := architectureSymbols[k.Architecture]; !ok {
+		errs = append(errs, fmt.Sprintf("unknown architecture %q, expected %s", k.Architecture, strings.Join(knownNames(architectureSymbols), ", ")))
+	}
+	if _, ok := platformSymbols[k.Platform]; !ok {
+		errs = append(errs, fmt.Sprintf("unknown platform %q, expected %s", k.Platform, strings.Join(knownNames(platformSymbols), ", ")))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid imported kernel: %s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// checkImportedFile checks that the file at path, imported as what, exists
+// and is not empty.
+func checkImportedFile(what, path string) error {
+	if path == "" {
+		return fmt.Errorf("%s is required", what)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("%s %s cannot be read: %w", what, path, err)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%s %s is not a file", what, path)
+	}
+	if info.Size() == 0 {
+		return fmt.Errorf("%s %s is empty", what, path)
+	}
+
+	return nil
+}
+
+// knownNames returns the sorted names of the architectures or platforms of
+// symbols.
+func knownNames(symbols map[string][]string) []string {
+	names := make([]string, 0, len(symbols))
+	for name := range symbols {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,59 @@
+package unikraft
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImportedKernelValidate(t *testing.T) {
+	dir := t.TempDir()
+	kernel := filepath.Join(dir, "app_qemu-x86_64")
+	initrd := filepath.Join(dir, "initramfs.cpio")
+	empty := filepath.Join(dir, "empty")
+	for path, content := range map[string]string{kernel: "ELF", initrd: "070701", empty: ""} {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		kernel ImportedKernel
+		want   string
+	}{
+		{name: "valid", kernel: ImportedKernel{Kernel: kernel, Initrd: initrd, Architecture: "x86_64", Platform: "qemu"}},
+		{name: "no kernel", kernel: ImportedKernel{Architecture: "x86_64", Platform: "qemu"}, want: "kernel is required"},
+		{name: "missing kernel", kernel: ImportedKernel{Kernel: filepath.Join(dir, "missing"), Architecture: "x86_64", Platform: "qemu"}, want: "cannot be read"},
+		{name: "directory kernel", kernel: ImportedKernel{Kernel: dir, Architecture: "x86_64", Platform: "qemu"}, want: "is not a file"},
+		{name: "initrd directory", kernel: ImportedKernel{Kernel: kernel, Initrd: dir, Architecture: "x86_64", Platform: "qemu"}},
+		{name: "empty initrd", kernel: ImportedKernel{Kernel: kernel, Initrd: empty, Architecture: "x86_64", Platform: "qemu"}, want: "initrd " + empty + " is empty"},
+		{name: "unknown architecture", kernel: ImportedKernel{Kernel: kernel, Architecture: "riscv64", Platform: "qemu"}, want: `unknown architecture "riscv64", expected arm, arm64, x86_64`},
+		{name: "unknown platform", kernel: ImportedKernel{Kernel: kernel, Architecture: "x86_64", Platform: "hyperv"}, want: `unknown platform "hyperv"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.kernel.Validate()
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Errorf("expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestImportedKernelTargetName(t *testing.T) {
+	k := ImportedKernel{Architecture: "arm64", Platform: "fc"}
+	if got := k.TargetName(); got != "fc-arm64" {
+		t.Errorf("TargetName() = %s, want fc-arm64", got)
+	}
+
+	k.Name = "app"
+	if got := k.TargetName(); got != "app" {
+		t.Errorf("TargetName() = %s, want app", got)
+	}
+}
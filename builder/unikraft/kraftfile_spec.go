@@ -0,0 +1,123 @@
+package unikraft
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SupportedKraftfileSpecs are the Kraftfile specification versions the
+// plugin can build, from the oldest to the newest.
+var SupportedKraftfileSpecs = []string{"0.5", "0.6"}
+
+// kraftfileNames are the names a Kraftfile is looked up by in a project
+// directory, in order.
+var kraftfileNames = []string{
+	"Kraftfile",
+	"Kraftfile.yml",
+	"Kraftfile.yaml",
+	"kraft.yaml",
+	"kraft.yml",
+	".kraft.yaml",
+	".kraft.yml",
+}
+
+// checkKraftfileSpec checks that the Kraftfile of a project declares a
+// supported specification version, so that an unsupported one fails with an
+// upgrade message rather than a parse error.  Kraftfiles which do not declare
+// a version, or cannot be found, are left for the project loader to handle.
+func checkKraftfileSpec(workdir, kraftfile string) error {
+	path := kraftfile
+	if len(path) == 0 {
+		for _, name := range kraftfileNames {
+			if _, err := os.Stat(filepath.Join(workdir, name)); err == nil {
+				path = filepath.Join(workdir, name)
+				break
+			}
+		}
+	}
+	if len(path) == 0 {
+		return nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var spec struct {
+		Spec          interface{} `yaml:"spec"`
+		Specification interface{} `yaml:"specification"`
+	}
+	if err := yaml.Unmarshal(raw, &spec); err != nil {
+		return nil
+	}
+
+	declared := spec.Spec
+	if declared == nil {
+		declared = spec.Specification
+	}
+	if declared == nil {
+		return nil
+	}
+
+	version := normalizeSpecVersion(fmt.Sprint(declared))
+	for _, supported := range SupportedKraftfileSpecs {
+		if version == supported {
+			return nil
+		}
+	}
+
+	oldest := SupportedKraftfileSpecs[0]
+	newest := SupportedKraftfileSpecs[len(SupportedKraftfileSpecs)-1]
+
+	hint := "migrate the Kraftfile to a supported specification"
+	if compareSpecVersions(version, newest) > 0 {
+		hint = "upgrade the plugin to build it"
+	}
+
+	return fmt.Errorf("%s declares specification v%s, but only v%s to v%s are supported: %s",
+		path, version, oldest, newest, hint,
+	)
+}
+
+// normalizeSpecVersion strips the `v` prefix and patch level of a version.
+func normalizeSpecVersion(version string) string {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) > 2 {
+		parts = parts[:2]
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// compareSpecVersions compares two `major.minor` versions.  Versions which
+// are not numeric compare as lower.
+func compareSpecVersions(a, b string) int {
+	pa := strings.Split(a, ".")
+	pb := strings.Split(b, ".")
+
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x, _ = strconv.Atoi(pa[i])
+		}
+		if i < len(pb) {
+			y, _ = strconv.Atoi(pb[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,42 @@
+package unikraft
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckKraftfileSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		err     string
+	}{
+		{"supported", "Kraftfile", "spec: v0.6\nname: helloworld\n", ""},
+		{"supported legacy", "kraft.yaml", "specification: '0.5'\nname: helloworld\n", ""},
+		{"numeric", "Kraftfile", "spec: 0.5\n", ""},
+		{"undeclared", "Kraftfile", "name: helloworld\n", ""},
+		{"newer", "Kraftfile", "spec: v0.9\nname: helloworld\n", "declares specification v0.9, but only v0.5 to v0.6 are supported: upgrade the plugin"},
+		{"older", "kraft.yaml", "specification: '0.4'\n", "migrate the Kraftfile"},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, tt.file), []byte(tt.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		err := checkKraftfileSpec(dir, "")
+		if tt.err == "" && err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		} else if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.err, err)
+		}
+	}
+
+	if err := checkKraftfileSpec(t.TempDir(), ""); err != nil {
+		t.Errorf("expected a missing Kraftfile to be left to the project loader, got %v", err)
+	}
+}
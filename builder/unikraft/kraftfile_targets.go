@@ -0,0 +1,207 @@
+package unikraft
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// KraftfileTarget is a target declared in a Kraftfile.
+type KraftfileTarget struct {
+	Name         string
+	Architecture string
+	Platform     string
+	// KConfig are the symbols set by the target, with their `CONFIG_`
+	// prefix.
+	KConfig map[string]string
+}
+
+// Spec returns the plain description of the declared target.
+func (t KraftfileTarget) Spec() TargetSpec {
+	return TargetSpec{
+		Name:         t.Name,
+		Architecture: t.Architecture,
+		Platform:     t.Platform,
+	}
+}
+
+// ReadKraftfileTargets returns the path of the Kraftfile of the project in
+// workdir and the targets it declares, in order.  The Kraftfile is only
+// parsed, such that its components are neither pulled nor resolved.
+func ReadKraftfileTargets(workdir, kraftfile string) (string, []KraftfileTarget, error) {
+	path := ""
+	if kraftfile != "" {
+		path = findKraftfile(workdir, kraftfile)
+	} else {
+		for _, name := range kraftfileNames {
+			if _, err := os.Stat(filepath.Join(workdir, name)); err == nil {
+				path = filepath.Join(workdir, name)
+				break
+			}
+		}
+	}
+	if path == "" {
+		return "", nil, fmt.Errorf("no Kraftfile in %s", workdir)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("could not read Kraftfile: %w", err)
+	}
+
+	var doc struct {
+		Targets []yaml.Node `yaml:"targets"`
+	}
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return "", nil, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+
+	targets := make([]KraftfileTarget, 0, len(doc.Targets))
+	names := map[string]bool{}
+	for i := range doc.Targets {
+		t, err := parseKraftfileTarget(&doc.Targets[i])
+		if err != nil {
+			return "", nil, fmt.Errorf("%s:%d: %w", path, doc.Targets[i].Line, err)
+		}
+
+		if names[t.Name] {
+			return "", nil, fmt.Errorf("%s:%d: duplicate target %s", path, doc.Targets[i].Line, t.Name)
+		}
+		names[t.Name] = true
+
+		targets = append(targets, t)
+	}
+
+	return path, targets, nil
+}
+
+// FilterKraftfileTargets returns the declared targets selected by filter.
+func FilterKraftfileTargets(targets []KraftfileTarget, filter TargetFilter) []KraftfileTarget {
+	specs := make([]TargetSpec, 0, len(targets))
+	for _, t := range targets {
+		specs = append(specs, t.Spec())
+	}
+
+	var selected []KraftfileTarget
+	for i, d := range filterTargetSpecs(specs, filter) {
+		if d.Selected {
+			selected = append(selected, targets[i])
+		}
+	}
+
+	return selected
+}
+
+// parseKraftfileTarget parses a target of a Kraftfile, either in its short
+// `platform/architecture` form or as a mapping.  Unnamed targets are named
+// `platform-architecture`.
+func parseKraftfileTarget(node *yaml.Node) (KraftfileTarget, error) {
+	var t KraftfileTarget
+
+	switch node.Kind {
+	case yaml.ScalarNode:
+		platform, architecture, ok := strings.Cut(node.Value, "/")
+		if !ok || platform == "" || architecture == "" {
+			return t, fmt.Errorf("invalid target %q, expected platform/architecture", node.Value)
+		}
+		t.Platform, t.Architecture = platform, architecture
+
+	case yaml.MappingNode:
+		var raw struct {
+			Name         string    `yaml:"name"`
+			Architecture string    `yaml:"architecture"`
+			Arch         string    `yaml:"arch"`
+			Platform     string    `yaml:"platform"`
+			Plat         string    `yaml:"plat"`
+			KConfig      yaml.Node `yaml:"kconfig"`
+		}
+		if err := node.Decode(&raw); err != nil {
+			return t, err
+		}
+
+		t.Name = raw.Name
+		t.Architecture = raw.Architecture
+		if t.Architecture == "" {
+			t.Architecture = raw.Arch
+		}
+		t.Platform = raw.Platform
+		if t.Platform == "" {
+			t.Platform = raw.Plat
+		}
+		if t.Architecture == "" || t.Platform == "" {
+			return t, fmt.Errorf("target must declare an architecture and a platform")
+		}
+
+		kconfig, err := parseKraftfileKConfig(&raw.KConfig)
+		if err != nil {
+			return t, err
+		}
+		t.KConfig = kconfig
+
+	default:
+		return t, fmt.Errorf("invalid target, expected platform/architecture or a mapping")
+	}
+
+	if t.Name == "" {
+		t.Name = t.Platform + "-" + t.Architecture
+	}
+	if t.KConfig == nil {
+		t.KConfig = map[string]string{}
+	}
+
+	return t, nil
+}
+
+// parseKraftfileKConfig parses the kconfig of a target, either a mapping of
+// symbols or a list of `SYMBOL=value` entries.  Boolean values are written as
+// `y` and `n`.
+func parseKraftfileKConfig(node *yaml.Node) (map[string]string, error) {
+	symbols := map[string]string{}
+
+	switch node.Kind {
+	case 0:
+		// The target does not set any symbol.
+	case yaml.MappingNode:
+		var values map[string]interface{}
+		if err := node.Decode(&values); err != nil {
+			return nil, err
+		}
+		for k, v := range values {
+			symbols[kconfigSymbol(k)] = kconfigValue(v)
+		}
+	case yaml.SequenceNode:
+		var entries []string
+		if err := node.Decode(&entries); err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			k, v, ok := strings.Cut(e, "=")
+			if !ok || k == "" {
+				return nil, fmt.Errorf("invalid kconfig entry %q, expected SYMBOL=value", e)
+			}
+			symbols[kconfigSymbol(k)] = strings.Trim(v, `"`)
+		}
+	default:
+		return nil, fmt.Errorf("invalid kconfig, expected a mapping or a list")
+	}
+
+	return symbols, nil
+}
+
+// kconfigValue returns the value of a symbol as written in a .config.
+func kconfigValue(v interface{}) string {
+	switch v := v.(type) {
+	case bool:
+		if v {
+			return "y"
+		}
+		return "n"
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,130 @@
+package unikraft
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeKraftfile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return dir
+}
+
+func TestReadKraftfileTargets(t *testing.T) {
+	dir := writeKraftfile(t, "Kraftfile", `spec: v0.6
+name: helloworld
+unikraft: stable
+targets:
+- qemu/x86_64
+- name: fc-net
+  arch: x86_64
+  plat: fc
+  kconfig:
+    CONFIG_LIBUKNETDEV: y
+    LWIP_POOLS: true
+    CONFIG_LIBUKDEBUG_PRINTK: false
+    UK_STACK_SIZE: 65536
+- architecture: arm64
+  platform: qemu
+  kconfig:
+  - CONFIG_LIBVFSCORE_AUTOMOUNT_ROOTFS=y
+  - CONFIG_LIBVFSCORE_ROOTFS="initrd"
+`)
+
+	path, targets, err := ReadKraftfileTargets(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != filepath.Join(dir, "Kraftfile") {
+		t.Errorf("path = %s", path)
+	}
+
+	want := []KraftfileTarget{
+		{Name: "qemu-x86_64", Architecture: "x86_64", Platform: "qemu", KConfig: map[string]string{}},
+		{Name: "fc-net", Architecture: "x86_64", Platform: "fc", KConfig: map[string]string{
+			"CONFIG_LIBUKNETDEV":       "y",
+			"CONFIG_LWIP_POOLS":        "y",
+			"CONFIG_LIBUKDEBUG_PRINTK": "n",
+			"CONFIG_UK_STACK_SIZE":     "65536",
+		}},
+		{Name: "qemu-arm64", Architecture: "arm64", Platform: "qemu", KConfig: map[string]string{
+			"CONFIG_LIBVFSCORE_AUTOMOUNT_ROOTFS": "y",
+			"CONFIG_LIBVFSCORE_ROOTFS":           "initrd",
+		}},
+	}
+	if !reflect.DeepEqual(targets, want) {
+		t.Errorf("targets = %+v, want %+v", targets, want)
+	}
+}
+
+func TestReadKraftfileTargetsExplicitKraftfile(t *testing.T) {
+	dir := writeKraftfile(t, "Kraftfile.prod", "targets:\n- xen/arm64\n")
+
+	if _, _, err := ReadKraftfileTargets(dir, ""); err == nil {
+		t.Error("expected the default Kraftfiles to be missing")
+	}
+
+	_, targets, err := ReadKraftfileTargets(dir, "Kraftfile.prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) != 1 || targets[0].Name != "xen-arm64" {
+		t.Errorf("targets = %+v", targets)
+	}
+}
+
+func TestReadKraftfileTargetsInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		targets string
+		want    string
+	}{
+		{name: "short form", targets: "- qemu", want: `:2: invalid target "qemu"`},
+		{name: "missing platform", targets: "- arch: x86_64", want: "must declare an architecture and a platform"},
+		{name: "duplicate", targets: "- qemu/x86_64\n- name: qemu-x86_64\n  arch: arm64\n  plat: qemu", want: "duplicate target qemu-x86_64"},
+		{name: "kconfig entry", targets: "- arch: x86_64\n  plat: qemu\n  kconfig: [CONFIG_LWIP]", want: `invalid kconfig entry "CONFIG_LWIP"`},
+		{name: "kconfig scalar", targets: "- arch: x86_64\n  plat: qemu\n  kconfig: y", want: "expected a mapping or a list"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeKraftfile(t, "Kraftfile", "targets:\n"+tt.targets+"\n")
+
+			_, _, err := ReadKraftfileTargets(dir, "")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestFilterKraftfileTargets(t *testing.T) {
+	targets := []KraftfileTarget{
+		{Name: "qemu-x86_64", Architecture: "x86_64", Platform: "qemu"},
+		{Name: "qemu-arm64", Architecture: "arm64", Platform: "qemu"},
+		{Name: "fc-x86_64", Architecture: "x86_64", Platform: "fc"},
+	}
+
+	got := FilterKraftfileTargets(targets, TargetFilter{Architecture: "x86_64"})
+	if len(got) != 2 || got[0].Name != "qemu-x86_64" || got[1].Name != "fc-x86_64" {
+		t.Errorf("architecture filter selected %+v", got)
+	}
+
+	got = FilterKraftfileTargets(targets, TargetFilter{Platform: "qemu", Architecture: "arm64"})
+	if len(got) != 1 || got[0].Name != "qemu-arm64" {
+		t.Errorf("platform and architecture filter selected %+v", got)
+	}
+
+	if got := FilterKraftfileTargets(targets, TargetFilter{}); len(got) != 3 {
+		t.Errorf("expected every target without a filter, got %+v", got)
+	}
+}
@@ -0,0 +1,153 @@
+package unikraft
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ociLicensesAnnotation is the OCI image annotation carrying the SPDX license
+// expression of the packaged content.
+const ociLicensesAnnotation = "org.opencontainers.image.licenses"
+
+// LicensePolicy restricts the licenses of the components pulled for a build.
+// Licenses are SPDX identifiers, compared case-insensitively.  An empty policy
+// permits every license.
+type LicensePolicy struct {
+	// Allow lists the only licenses components may use, when not empty.
+	Allow []string
+	// Deny lists the licenses components must not use.  It takes precedence
+	// over Allow.
+	Deny []string
+}
+
+// Empty reports whether the policy permits every license.
+func (p LicensePolicy) Empty() bool {
+	return len(p.Allow) == 0 && len(p.Deny) == 0
+}
+
+// Permits reports whether a component licensed under the SPDX expression
+// license may be used.  An expression offering a choice with `OR` is permitted
+// when any alternative is, and one combining licenses with `AND` when all of
+// them are.
+func (p LicensePolicy) Permits(license string) bool {
+	for _, alternative := range splitLicenseExpression(license, "OR") {
+		permitted := true
+		for _, id := range splitLicenseExpression(alternative, "AND") {
+			if !p.permitsID(id) {
+				permitted = false
+				break
+			}
+		}
+
+		if permitted {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p LicensePolicy) permitsID(id string) bool {
+	if containsLicense(p.Deny, id) {
+		return false
+	}
+
+	return len(p.Allow) == 0 || containsLicense(p.Allow, id)
+}
+
+func containsLicense(ids []string, id string) bool {
+	for _, i := range ids {
+		if strings.EqualFold(i, id) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// splitLicenseExpression splits an SPDX expression on the operator op.
+// Parentheses are not nested and are dropped.
+func splitLicenseExpression(expr, op string) []string {
+	expr = strings.NewReplacer("(", " ", ")", " ").Replace(expr)
+
+	var terms []string
+	var term []string
+	for _, field := range strings.Fields(expr) {
+		if strings.EqualFold(field, op) {
+			terms = append(terms, strings.Join(term, " "))
+			term = nil
+			continue
+		}
+
+		term = append(term, field)
+	}
+
+	return append(terms, strings.Join(term, " "))
+}
+
+// CheckLicenses checks the licenses of components, keyed by component name,
+// against the policy.  It returns the components without license metadata,
+// and an error listing every component whose license is not permitted.
+func (p LicensePolicy) CheckLicenses(licenses map[string]string) ([]string, error) {
+	var missing, offenders []string
+
+	for name, license := range licenses {
+		if len(license) == 0 {
+			missing = append(missing, name)
+			continue
+		}
+
+		if !p.Permits(license) {
+			offenders = append(offenders, fmt.Sprintf("%s (%s)", name, license))
+		}
+	}
+
+	sort.Strings(missing)
+	sort.Strings(offenders)
+
+	if len(offenders) > 0 {
+		return missing, fmt.Errorf("components with disallowed licenses: %s", strings.Join(offenders, ", "))
+	}
+
+	return missing, nil
+}
+
+// componentLicense extracts the SPDX license expression from the metadata of
+// a resolved component.  The metadata is inspected for a top-level `license`
+// field or the OCI licenses annotation, and an empty string is returned when
+// it has neither.
+func componentLicense(metadata interface{}) string {
+	if metadata == nil {
+		return ""
+	}
+
+	raw, err := json.Marshal(metadata)
+	if err != nil {
+		return ""
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return ""
+	}
+
+	for key, value := range fields {
+		switch strings.ToLower(key) {
+		case "license", "licenses":
+			var license string
+			if json.Unmarshal(value, &license) == nil && len(license) > 0 {
+				return license
+			}
+
+		case "annotations", "labels":
+			var annotations map[string]string
+			if json.Unmarshal(value, &annotations) == nil && len(annotations[ociLicensesAnnotation]) > 0 {
+				return annotations[ociLicensesAnnotation]
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,89 @@
+package unikraft
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLicensePolicyPermits(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  LicensePolicy
+		license string
+		ok      bool
+	}{
+		{name: "empty policy", license: "GPL-2.0-only", ok: true},
+		{name: "allowed", policy: LicensePolicy{Allow: []string{"BSD-3-Clause", "MIT"}}, license: "mit", ok: true},
+		{name: "outside allowlist", policy: LicensePolicy{Allow: []string{"BSD-3-Clause"}}, license: "GPL-2.0-only"},
+		{name: "denied", policy: LicensePolicy{Deny: []string{"GPL-3.0-only"}}, license: "GPL-3.0-only"},
+		{name: "deny wins", policy: LicensePolicy{Allow: []string{"GPL-3.0-only"}, Deny: []string{"GPL-3.0-only"}}, license: "GPL-3.0-only"},
+		{name: "any alternative", policy: LicensePolicy{Deny: []string{"GPL-2.0-only"}}, license: "GPL-2.0-only OR MIT", ok: true},
+		{name: "every conjunct", policy: LicensePolicy{Allow: []string{"MIT"}}, license: "(MIT AND BSD-2-Clause)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Permits(tt.license); got != tt.ok {
+				t.Errorf("Permits(%q) = %v, want %v", tt.license, got, tt.ok)
+			}
+		})
+	}
+}
+
+func TestLicensePolicyCheckLicenses(t *testing.T) {
+	policy := LicensePolicy{Allow: []string{"BSD-3-Clause", "MIT"}}
+
+	missing, err := policy.CheckLicenses(map[string]string{
+		"unikraft": "BSD-3-Clause",
+		"musl":     "MIT",
+		"lwip":     "",
+		"newlib":   "GPL-2.0-only",
+		"openssl":  "Apache-2.0",
+	})
+	if err == nil {
+		t.Fatal("expected disallowed licenses to be rejected")
+	}
+
+	for _, want := range []string{"newlib (GPL-2.0-only)", "openssl (Apache-2.0)"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not list %s", err, want)
+		}
+	}
+	for _, allowed := range []string{"unikraft", "musl"} {
+		if strings.Contains(err.Error(), allowed) {
+			t.Errorf("error %q lists allowed component %s", err, allowed)
+		}
+	}
+
+	if len(missing) != 1 || missing[0] != "lwip" {
+		t.Errorf("expected lwip to have no license metadata, got %v", missing)
+	}
+
+	if _, err := policy.CheckLicenses(map[string]string{"unikraft": "BSD-3-Clause"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestComponentLicense(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata interface{}
+		want     string
+	}{
+		{name: "nil", metadata: nil},
+		{name: "field", metadata: struct{ License string }{"BSD-3-Clause"}, want: "BSD-3-Clause"},
+		{name: "annotation", metadata: map[string]interface{}{
+			"annotations": map[string]string{ociLicensesAnnotation: "MIT"},
+		}, want: "MIT"},
+		{name: "none", metadata: map[string]string{"name": "lwip"}},
+		{name: "not an object", metadata: "lwip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := componentLicense(tt.metadata); got != tt.want {
+				t.Errorf("componentLicense() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package unikraft
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// crossCompileVar is the variable make reads the toolchain prefix from.
+const crossCompileVar = "CROSS_COMPILE"
+
+// checkMakeEnv checks that the names of the variables of build_env can be
+// set in the environment of make.
+func checkMakeEnv(env map[string]string, crossCompile string) error {
+	for name := range env {
+		if name == "" || strings.ContainsAny(name, "= \t\n") {
+			return fmt.Errorf("invalid build_env variable name %q", name)
+		}
+	}
+
+	if _, ok := env[crossCompileVar]; ok && crossCompile != "" {
+		return fmt.Errorf("cross_compile cannot be combined with %s in build_env", crossCompileVar)
+	}
+
+	return nil
+}
+
+// makeEnv returns the variables added to the environment of make, or nil
+// when make runs with the environment of the plugin only.
+func makeEnv(env map[string]string, crossCompile string) map[string]string {
+	if len(env) == 0 && crossCompile == "" {
+		return nil
+	}
+
+	vars := make(map[string]string, len(env)+1)
+	for name, value := range env {
+		vars[name] = value
+	}
+	if crossCompile != "" {
+		vars[crossCompileVar] = crossCompile
+	}
+
+	return vars
+}
+
+// ccacheBinary is the executable use_ccache wraps the compilers with.
+const ccacheBinary = "ccache"
+
+// ccacheCompilers are the make variables of the compilers wrapped by
+// use_ccache.
+var ccacheCompilers = []string{"CC", "CXX"}
+
+// checkCCache checks that the compilers wrapped by use_ccache are not also set
+// in build_env.
+func checkCCache(env map[string]string) error {
+	for _, name := range ccacheCompilers {
+		if _, ok := env[name]; ok {
+			return fmt.Errorf("use_ccache cannot be combined with %s in build_env", name)
+		}
+	}
+
+	return nil
+}
+
+// lookCCache returns an error when ccache is not installed on the host.
+func lookCCache() error {
+	if _, err := exec.LookPath(ccacheBinary); err != nil {
+		return fmt.Errorf("use_ccache requires %s: %w", ccacheBinary, err)
+	}
+
+	return nil
+}
+
+// ccacheVars returns the make variables wrapping the GCC compilers of the
+// toolchain prefixed by crossCompile with ccache.  They are passed on the
+// command line of make, as the Makefiles of Unikraft assign the compilers
+// themselves.
+func ccacheVars(crossCompile string) map[string]string {
+	return map[string]string{
+		"CC":  ccacheBinary + " " + crossCompile + "gcc",
+		"CXX": ccacheBinary + " " + crossCompile + "g++",
+	}
+}
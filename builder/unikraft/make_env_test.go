@@ -0,0 +1,77 @@
+package unikraft
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckMakeEnv(t *testing.T) {
+	tests := []struct {
+		name         string
+		env          map[string]string
+		crossCompile string
+		want         string
+	}{
+		{name: "none"},
+		{name: "variables", env: map[string]string{"KCFLAGS": "-O2", "CC": "ccache gcc"}, crossCompile: "aarch64-linux-gnu-"},
+		{name: "cross compile variable", env: map[string]string{"CROSS_COMPILE": "aarch64-linux-gnu-"}},
+		{name: "empty name", env: map[string]string{"": "x"}, want: "invalid build_env variable name"},
+		{name: "assignment name", env: map[string]string{"A=B": "x"}, want: "invalid build_env variable name"},
+		{name: "both cross compile", env: map[string]string{"CROSS_COMPILE": "x86_64-linux-gnu-"}, crossCompile: "aarch64-linux-gnu-", want: "cannot be combined"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkMakeEnv(tt.env, tt.crossCompile)
+			if tt.want == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestMakeEnv(t *testing.T) {
+	if got := makeEnv(nil, ""); got != nil {
+		t.Errorf("expected no variables, got %v", got)
+	}
+
+	env := map[string]string{"KCFLAGS": "-O2"}
+	got := makeEnv(env, "aarch64-linux-gnu-")
+	want := map[string]string{"KCFLAGS": "-O2", "CROSS_COMPILE": "aarch64-linux-gnu-"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("makeEnv() = %v, want %v", got, want)
+	}
+
+	if _, ok := env["CROSS_COMPILE"]; ok {
+		t.Error("expected build_env to be left unchanged")
+	}
+}
+
+func TestCheckCCache(t *testing.T) {
+	if err := checkCCache(map[string]string{"KCFLAGS": "-O2"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	err := checkCCache(map[string]string{"CXX": "clang++"})
+	if err == nil || !strings.Contains(err.Error(), "CXX") {
+		t.Errorf("expected an error naming CXX, got %v", err)
+	}
+}
+
+func TestCCacheVars(t *testing.T) {
+	got := ccacheVars("aarch64-linux-gnu-")
+	want := map[string]string{
+		"CC":  "ccache aarch64-linux-gnu-gcc",
+		"CXX": "ccache aarch64-linux-gnu-g++",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ccacheVars() = %v, want %v", got, want)
+	}
+
+	if got := ccacheVars("")["CC"]; got != "ccache gcc" {
+		t.Errorf("expected the native compiler, got %q", got)
+	}
+}
@@ -0,0 +1,125 @@
+package unikraft
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// isKernelFor reports whether the file at path is the kernel produced for the
+// given platform and architecture, following the `<name>_<plat>-<arch>`
+// naming of the Unikraft build system.
+func isKernelFor(path, platform, architecture string) bool {
+	return strings.HasSuffix(filepath.Base(path), fmt.Sprintf("_%s-%s", platform, architecture))
+}
+
+// targetOutput is where the kernel of a platform and architecture is saved:
+// the filename it is saved as in the build, and the path its debug kernel is
+// copied to, when set.
+type targetOutput struct {
+	Platform     string
+	Architecture string
+	KernelName   string
+	DbgOutput    string
+}
+
+// outputNames returns the filename each collected file is saved under.  The
+// kernels of the platforms and architectures of outputs are renamed to their
+// kernel name, when set.  Colliding names are rejected.
+func outputNames(files []string, outputs ...targetOutput) (map[string]string, error) {
+	names := map[string]string{}
+	owners := map[string]string{}
+
+	for _, file := range files {
+		name := filepath.Base(file)
+		for _, o := range outputs {
+			if o.KernelName != "" && isKernelFor(file, o.Platform, o.Architecture) {
+				name = o.KernelName
+			}
+		}
+
+		if owner, ok := owners[name]; ok {
+			return nil, fmt.Errorf("output name %s of %s collides with %s", name, file, owner)
+		}
+
+		owners[name] = file
+		names[file] = name
+	}
+
+	return names, nil
+}
+
+// kernelTarget returns the platform and architecture of the kernel at path,
+// as encoded in its `<name>_<plat>-<arch>` filename.  ok is false for files
+// which are not named like a kernel.
+func kernelTarget(path string) (platform, architecture string, ok bool) {
+	base := filepath.Base(path)
+	if strings.Contains(base, ".") {
+		return "", "", false
+	}
+
+	// Architectures may themselves contain an underscore (e.g. x86_64), so the
+	// separator is the last underscore followed by a `<plat>-` prefix.
+	for i := strings.LastIndex(base, "_"); i > 0; i = strings.LastIndex(base[:i], "_") {
+		platform, architecture, ok = strings.Cut(base[i+1:], "-")
+		if ok && platform != "" && architecture != "" && !strings.Contains(platform, "_") {
+			return platform, architecture, true
+		}
+	}
+
+	return "", "", false
+}
+
+// copyDebugKernel copies the debug kernel of the given platform and
+// architecture found among files to dst, creating its directory when needed.
+func copyDebugKernel(files []string, platform, architecture, dst string) error {
+	var src string
+	for _, file := range files {
+		if strings.HasSuffix(file, ".dbg") && isKernelFor(strings.TrimSuffix(file, ".dbg"), platform, architecture) {
+			src = file
+			break
+		}
+	}
+
+	if src == "" {
+		return fmt.Errorf("no debug kernel found for %s/%s", platform, architecture)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o755)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+// kernelDotConfig returns the .config the kernel at path was built with in
+// workdir: the `.config.<name>_<plat>-<arch>` of its target, or else the
+// `.config` of the project.  An empty string is returned when neither exists.
+func kernelDotConfig(workdir, kernel string) string {
+	for _, name := range []string{".config." + filepath.Base(kernel), ".config"} {
+		path := filepath.Join(workdir, name)
+		if stat, err := os.Stat(path); err == nil && stat.Mode().IsRegular() {
+			return path
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,224 @@
+package unikraft
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/packer-plugin-sdk/template/interpolate"
+)
+
+// DefaultArtifactName is the name the kernels are copied to output_dir under
+// when artifact_name is not set: the name they are saved under in the build.
+const DefaultArtifactName = "{{ .Name }}"
+
+// OutputTemplateData are the values output_dir, artifact_name, and the
+// kernel_name and dbg_output templates, are rendered with for every target.
+type OutputTemplateData struct {
+	// Target is the name of the target, `<plat>-<arch>` when the builder
+	// was not given one.
+	Target string
+	// Arch and Plat are the architecture and platform of the target, also
+	// available as Architecture and Platform.
+	Arch         string
+	Plat         string
+	Architecture string
+	Platform     string
+	// Name is the filename of the kernel in the build.
+	Name string
+	// BuildID is the build ID of the target, if known.
+	BuildID string
+	// Version is the version of Unikraft the kernel was built with, as set
+	// by the .config of the target, if known.
+	Version string
+}
+
+// NewOutputTemplateData returns the values the output of the kernel of t,
+// saved as name, is rendered with.  The version is read from the .config of
+// t, when it records one.
+func NewOutputTemplateData(t TargetArtifact, name string) OutputTemplateData {
+	target := t.Target
+	if target == "" {
+		target = t.Platform + "-" + t.Architecture
+	}
+
+	return OutputTemplateData{
+		Target:       target,
+		Arch:         t.Architecture,
+		Plat:         t.Platform,
+		Architecture: t.Architecture,
+		Platform:     t.Platform,
+		Name:         name,
+		BuildID:      t.BuildID,
+		Version:      unikraftVersion(t.KConfig),
+	}
+}
+
+// unikraftVersion returns the version of Unikraft set by the .config at path,
+// or an empty string when it cannot be read.
+func unikraftVersion(path string) string {
+	if path == "" {
+		return ""
+	}
+
+	symbols, err := readDotConfig(path)
+	if err != nil {
+		return ""
+	}
+
+	return symbols["CONFIG_UK_FULLVERSION"]
+}
+
+// isTargetTemplate reports whether the value of an option refers to the
+// target it is rendered for, or to any other template value.
+func isTargetTemplate(value string) bool {
+	return strings.Contains(value, "{{")
+}
+
+// renderTargetTemplate renders the value of option with data.
+func renderTargetTemplate(ctx interpolate.Context, option, value string, data OutputTemplateData) (string, error) {
+	ctx.Data = &data
+	rendered, err := interpolate.Render(value, &ctx)
+	if err != nil {
+		return "", fmt.Errorf("could not render %s: %w", option, err)
+	}
+
+	return rendered, nil
+}
+
+// renderFilename renders the value of option with data, which must be a
+// filename.
+func renderFilename(ctx interpolate.Context, option, value string, data OutputTemplateData) (string, error) {
+	rendered, err := renderTargetTemplate(ctx, option, value, data)
+	if err != nil {
+		return "", err
+	}
+	if rendered == "" || rendered != filepath.Base(rendered) || rendered == "." || rendered == ".." {
+		return "", fmt.Errorf("%s renders to %q, expected a filename", option, rendered)
+	}
+
+	return rendered, nil
+}
+
+// renderOutputPath returns the path the kernel described by data is copied
+// to: artifact name in dir, both rendered with data.
+func renderOutputPath(ctx interpolate.Context, dir, name string, data OutputTemplateData) (string, error) {
+	if name == "" {
+		name = DefaultArtifactName
+	}
+
+	renderedDir, err := renderTargetTemplate(ctx, "output_dir", dir, data)
+	if err != nil {
+		return "", err
+	}
+
+	renderedName, err := renderFilename(ctx, "artifact_name", name, data)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(renderedDir, renderedName), nil
+}
+
+// exampleTemplateData are the values of an example target the templates are
+// checked with before the build.
+var exampleTemplateData = OutputTemplateData{
+	Target:       "qemu-x86_64",
+	Arch:         "x86_64",
+	Plat:         "qemu",
+	Architecture: "x86_64",
+	Platform:     "qemu",
+	Name:         "app_qemu-x86_64",
+	BuildID:      "example",
+	Version:      "0.0.0",
+}
+
+// checkOutputTemplates checks that output_dir and artifact_name render, with
+// the values of an example target.
+func checkOutputTemplates(ctx interpolate.Context, dir, name string) error {
+	_, err := renderOutputPath(ctx, dir, name, exampleTemplateData)
+
+	return err
+}
+
+// checkKernelTemplates checks that kernel_name and dbg_output render, with the
+// values of an example target, when they refer to it.
+func checkKernelTemplates(ctx interpolate.Context, kernelName, dbgOutput string) error {
+	if isTargetTemplate(kernelName) {
+		if _, err := renderFilename(ctx, "kernel_name", kernelName, exampleTemplateData); err != nil {
+			return err
+		}
+	}
+
+	if isTargetTemplate(dbgOutput) {
+		if _, err := renderTargetTemplate(ctx, "dbg_output", dbgOutput, exampleTemplateData); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// renderTargetOutputs returns where the kernels of the targets described by
+// data are saved.  kernel_name and dbg_output are rendered for every target
+// when they are templates, and only apply to the first target otherwise.
+// Targets may not share a name or a debug output.
+func renderTargetOutputs(ctx interpolate.Context, kernelName, dbgOutput string, data []OutputTemplateData) ([]targetOutput, error) {
+	var outputs []targetOutput
+	names := map[string]string{}
+	dbgs := map[string]string{}
+	for i, d := range data {
+		o := targetOutput{Platform: d.Platform, Architecture: d.Architecture}
+
+		switch {
+		case isTargetTemplate(kernelName):
+			name, err := renderFilename(ctx, "kernel_name", kernelName, d)
+			if err != nil {
+				return nil, err
+			}
+			o.KernelName = name
+		case i == 0:
+			o.KernelName = kernelName
+		}
+
+		switch {
+		case isTargetTemplate(dbgOutput):
+			dbg, err := renderTargetTemplate(ctx, "dbg_output", dbgOutput, d)
+			if err != nil {
+				return nil, err
+			}
+			o.DbgOutput = dbg
+		case i == 0:
+			o.DbgOutput = dbgOutput
+		}
+
+		if owner, ok := names[o.KernelName]; ok && o.KernelName != "" {
+			return nil, fmt.Errorf("the kernels of %s and %s are both saved as %s, name them apart with {{ .Target }}", owner, d.Target, o.KernelName)
+		}
+		names[o.KernelName] = d.Target
+
+		if owner, ok := dbgs[o.DbgOutput]; ok && o.DbgOutput != "" {
+			return nil, fmt.Errorf("the debug kernels of %s and %s are both copied to %s, name them apart with {{ .Target }}", owner, d.Target, o.DbgOutput)
+		}
+		dbgs[o.DbgOutput] = d.Target
+
+		outputs = append(outputs, o)
+	}
+
+	return outputs, nil
+}
+
+// copyOutput copies the kernel at src to dst, creating its directory when
+// needed.  The copy is executable like the kernel.
+func copyOutput(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+
+	if err := copyFile(src, dst); err != nil {
+		return err
+	}
+
+	return os.Chmod(dst, 0o755)
+}
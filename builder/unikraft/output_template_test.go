@@ -0,0 +1,150 @@
+package unikraft
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/packer-plugin-sdk/template/interpolate"
+)
+
+func TestRenderOutputPath(t *testing.T) {
+	data := NewOutputTemplateData(TargetArtifact{
+		Architecture: "arm64",
+		Platform:     "qemu",
+		BuildID:      "0123abcd",
+	}, "app_qemu-arm64")
+
+	tests := []struct {
+		name    string
+		dir     string
+		artName string
+		want    string
+		err     string
+	}{
+		{name: "default name", dir: "dist", want: "dist/app_qemu-arm64"},
+		{name: "target", dir: "dist/{{ .Plat }}", artName: "{{ .Target }}-{{ .Arch }}", want: "dist/qemu/qemu-arm64-arm64"},
+		{name: "build id", dir: "dist", artName: "{{ .Name }}.{{ .BuildID }}", want: "dist/app_qemu-arm64.0123abcd"},
+		{name: "directory", dir: "dist", artName: "{{ .Platform }}/{{ .Architecture }}", err: "expected a filename"},
+		{name: "invalid", dir: "dist/{{ .Missing }}", err: "could not render output_dir"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderOutputPath(interpolate.Context{}, tt.dir, tt.artName, data)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("renderOutputPath() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewOutputTemplateDataTarget(t *testing.T) {
+	named := NewOutputTemplateData(TargetArtifact{Target: "app", Architecture: "x86_64", Platform: "fc"}, "app")
+	if named.Target != "app" {
+		t.Errorf("Target = %s, want app", named.Target)
+	}
+
+	unnamed := NewOutputTemplateData(TargetArtifact{Architecture: "x86_64", Platform: "fc"}, "app")
+	if unnamed.Target != "fc-x86_64" {
+		t.Errorf("Target = %s, want fc-x86_64", unnamed.Target)
+	}
+}
+
+func TestNewOutputTemplateDataVersion(t *testing.T) {
+	dotconfig := filepath.Join(t.TempDir(), ".config")
+	if err := os.WriteFile(dotconfig, []byte("CONFIG_UK_FULLVERSION=\"0.16.1\"\nCONFIG_PLAT_KVM=y\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	data := NewOutputTemplateData(TargetArtifact{Architecture: "x86_64", Platform: "qemu", KConfig: dotconfig}, "app")
+	if data.Version != "0.16.1" {
+		t.Errorf("Version = %q, want 0.16.1", data.Version)
+	}
+
+	if data := NewOutputTemplateData(TargetArtifact{Architecture: "x86_64", Platform: "qemu"}, "app"); data.Version != "" {
+		t.Errorf("expected no version without a .config, got %q", data.Version)
+	}
+}
+
+func TestRenderTargetOutputs(t *testing.T) {
+	data := []OutputTemplateData{
+		NewOutputTemplateData(TargetArtifact{Architecture: "x86_64", Platform: "qemu"}, "app_qemu-x86_64"),
+		NewOutputTemplateData(TargetArtifact{Architecture: "arm64", Platform: "qemu", Target: "edge"}, "app_qemu-arm64"),
+	}
+
+	outputs, err := renderTargetOutputs(interpolate.Context{}, "{{ .Target }}.bin", "dbg/{{ .Name }}.dbg", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []targetOutput{
+		{Platform: "qemu", Architecture: "x86_64", KernelName: "qemu-x86_64.bin", DbgOutput: "dbg/app_qemu-x86_64.dbg"},
+		{Platform: "qemu", Architecture: "arm64", KernelName: "edge.bin", DbgOutput: "dbg/app_qemu-arm64.dbg"},
+	}
+	if !reflect.DeepEqual(outputs, want) {
+		t.Errorf("renderTargetOutputs() = %+v, want %+v", outputs, want)
+	}
+
+	// Plain values only apply to the first target.
+	outputs, err = renderTargetOutputs(interpolate.Context{}, "kernel.bin", "app.dbg", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []targetOutput{
+		{Platform: "qemu", Architecture: "x86_64", KernelName: "kernel.bin", DbgOutput: "app.dbg"},
+		{Platform: "qemu", Architecture: "arm64"},
+	}
+	if !reflect.DeepEqual(outputs, want) {
+		t.Errorf("renderTargetOutputs() = %+v, want %+v", outputs, want)
+	}
+
+	_, err = renderTargetOutputs(interpolate.Context{}, "", "dbg/{{ .Plat }}.dbg", data)
+	if err == nil || !strings.Contains(err.Error(), "both copied to dbg/qemu.dbg") {
+		t.Errorf("expected shared debug outputs to be rejected, got %v", err)
+	}
+
+	_, err = renderTargetOutputs(interpolate.Context{}, "app-{{ .Version }}", "", data)
+	if err == nil || !strings.Contains(err.Error(), "both saved as app-") {
+		t.Errorf("expected shared kernel names to be rejected, got %v", err)
+	}
+}
+
+func TestCopyOutput(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "kernel")
+	if err := os.WriteFile(src, []byte("kernel"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(dir, "dist", "qemu", "app")
+	if err := copyOutput(src, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "kernel" {
+		t.Errorf("copied %q, want kernel", got)
+	}
+
+	info, err := os.Stat(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm()&0o111 == 0 {
+		t.Errorf("expected the copy to be executable, got %s", info.Mode())
+	}
+}
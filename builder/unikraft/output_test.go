@@ -0,0 +1,105 @@
+package unikraft
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOutputNamesKernelName(t *testing.T) {
+	files := []string{
+		"/app/.unikraft/build/nginx_qemu-x86_64",
+		"/app/.unikraft/build/nginx_qemu-x86_64.dbg",
+	}
+
+	names, err := outputNames(files, targetOutput{Platform: "qemu", Architecture: "x86_64", KernelName: "kernel.bin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names[files[0]]; got != "kernel.bin" {
+		t.Errorf("expected kernel to be renamed to kernel.bin, got %s", got)
+	}
+	if got := names[files[1]]; got != "nginx_qemu-x86_64.dbg" {
+		t.Errorf("expected debug kernel to keep its name, got %s", got)
+	}
+
+	if _, err := outputNames(files, targetOutput{Platform: "qemu", Architecture: "x86_64", KernelName: "nginx_qemu-x86_64.dbg"}); err == nil {
+		t.Errorf("expected colliding kernel name to be rejected")
+	}
+}
+
+func TestKernelTarget(t *testing.T) {
+	tests := []struct {
+		path         string
+		platform     string
+		architecture string
+		ok           bool
+	}{
+		{path: "/app/.unikraft/build/nginx_qemu-x86_64", platform: "qemu", architecture: "x86_64", ok: true},
+		{path: "/app/.unikraft/build/my_app_fc-arm64", platform: "fc", architecture: "arm64", ok: true},
+		{path: "/app/.unikraft/build/nginx_qemu-x86_64.dbg"},
+		{path: "/app/.unikraft/build/config"},
+	}
+
+	for _, tt := range tests {
+		plat, arch, ok := kernelTarget(tt.path)
+		if plat != tt.platform || arch != tt.architecture || ok != tt.ok {
+			t.Errorf("kernelTarget(%s) = %s, %s, %v", tt.path, plat, arch, ok)
+		}
+	}
+}
+
+func TestCopyDebugKernel(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{
+		filepath.Join(dir, "nginx_qemu-x86_64"),
+		filepath.Join(dir, "nginx_qemu-x86_64.dbg"),
+	}
+	for _, f := range files {
+		if err := os.WriteFile(f, []byte(filepath.Base(f)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dst := filepath.Join(dir, "symbols", "nginx", "kernel.dbg")
+	if err := copyDebugKernel(files, "qemu", "x86_64", dst); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(raw) != "nginx_qemu-x86_64.dbg" {
+		t.Errorf("expected the debug kernel at %s, got %q", dst, raw)
+	}
+
+	if err := copyDebugKernel(files, "fc", "x86_64", dst); err == nil {
+		t.Error("expected an error when no debug kernel matches")
+	}
+}
+
+func TestKernelDotConfig(t *testing.T) {
+	dir := t.TempDir()
+	kernel := filepath.Join(dir, ".unikraft", "build", "nginx_qemu-x86_64")
+
+	if got := kernelDotConfig(dir, kernel); got != "" {
+		t.Errorf("expected no .config, got %s", got)
+	}
+
+	project := filepath.Join(dir, ".config")
+	if err := os.WriteFile(project, []byte("CONFIG_LIBUKDEBUG=y\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := kernelDotConfig(dir, kernel); got != project {
+		t.Errorf("kernelDotConfig() = %s, want %s", got, project)
+	}
+
+	target := filepath.Join(dir, ".config.nginx_qemu-x86_64")
+	if err := os.WriteFile(target, []byte("CONFIG_LIBUKDEBUG=n\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := kernelDotConfig(dir, kernel); got != target {
+		t.Errorf("kernelDotConfig() = %s, want %s", got, target)
+	}
+}
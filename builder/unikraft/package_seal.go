@@ -0,0 +1,100 @@
+package unikraft
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PackageSeal records the checksum and signature written next to a package
+// file once it has been (re)packaged.
+type PackageSeal struct {
+	// Path is the package file.
+	Path string
+	// Digest is the `sha256:<hex>` digest of the package file.
+	Digest string
+	// ChecksumFile is the `sha256sum` compatible file holding the digest.
+	ChecksumFile string
+	// SignatureFile is the detached signature, empty when the package is not
+	// signed.
+	SignatureFile string
+	// StaleSignature is set when the package carried a signature which was
+	// removed because no signing key was provided for the new package.
+	StaleSignature bool
+}
+
+// sealPackage computes the checksum of the package at path and, when keyFile
+// is set, signs it.  Any checksum or signature left by a previous packaging
+// of the same path is replaced, since it no longer applies.
+func sealPackage(path, keyFile string) (*PackageSeal, error) {
+	digest, err := fileDigest(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not compute checksum of %s: %w", path, err)
+	}
+
+	seal := &PackageSeal{
+		Path:         path,
+		Digest:       digest,
+		ChecksumFile: path + ".sha256",
+	}
+
+	sum := fmt.Sprintf("%s  %s\n", strings.TrimPrefix(digest, "sha256:"), filepath.Base(path))
+	if err := os.WriteFile(seal.ChecksumFile, []byte(sum), 0o644); err != nil {
+		return nil, err
+	}
+
+	signature := path + ".sig"
+	if keyFile == "" {
+		if _, err := os.Stat(signature); err == nil {
+			if err := os.Remove(signature); err != nil {
+				return nil, err
+			}
+			seal.StaleSignature = true
+		}
+
+		return seal, nil
+	}
+
+	key, err := loadSigningKey(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(key, []byte(digest)))
+	if err := os.WriteFile(signature, []byte(sig+"\n"), 0o644); err != nil {
+		return nil, err
+	}
+	seal.SignatureFile = signature
+
+	return seal, nil
+}
+
+// loadSigningKey reads a PEM encoded PKCS #8 Ed25519 private key.
+func loadSigningKey(path string) (ed25519.PrivateKey, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read signing key: %w", err)
+	}
+
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("signing key %s is not PEM encoded", path)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse signing key: %w", err)
+	}
+
+	ed, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key %s is not an Ed25519 key", path)
+	}
+
+	return ed, nil
+}
@@ -0,0 +1,84 @@
+package unikraft
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSealPackageAfterRepackaging(t *testing.T) {
+	dir := t.TempDir()
+	pkg := filepath.Join(dir, "nginx.img")
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := filepath.Join(dir, "signing.pem")
+	if err := os.WriteFile(key, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(pkg, []byte("original"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	original, err := sealPackage(pkg, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Repackage into the same path with different contents.
+	if err := os.WriteFile(pkg, []byte("repackaged"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	repackaged, err := sealPackage(pkg, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if repackaged.Digest == original.Digest {
+		t.Errorf("expected a new checksum after repackaging")
+	}
+
+	sum, err := os.ReadFile(repackaged.ChecksumFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(sum), strings.TrimPrefix(repackaged.Digest, "sha256:")+"  nginx.img") {
+		t.Errorf("unexpected checksum file: %s", sum)
+	}
+
+	raw, err := os.ReadFile(repackaged.SignatureFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(raw)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ed25519.Verify(pub, []byte(repackaged.Digest), sig) {
+		t.Errorf("signature does not match the repackaged checksum")
+	}
+
+	// Without a key the previous signature no longer applies.
+	unsigned, err := sealPackage(pkg, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !unsigned.StaleSignature || unsigned.SignatureFile != "" {
+		t.Errorf("expected stale signature to be reported, got %+v", unsigned)
+	}
+	if _, err := os.Stat(pkg + ".sig"); !os.IsNotExist(err) {
+		t.Errorf("expected stale signature to be removed")
+	}
+}
@@ -0,0 +1,95 @@
+package unikraft
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Semaphore bounds the number of operations in flight across several worker
+// pools.  A nil Semaphore does not bound anything.
+type Semaphore struct {
+	slots chan struct{}
+}
+
+// NewSemaphore returns a semaphore admitting n operations at a time, or nil
+// when n is not positive.
+func NewSemaphore(n int) *Semaphore {
+	if n <= 0 {
+		return nil
+	}
+
+	return &Semaphore{slots: make(chan struct{}, n)}
+}
+
+// Acquire blocks until a slot is available or ctx is done.
+func (s *Semaphore) Acquire(ctx context.Context) error {
+	if s == nil {
+		return ctx.Err()
+	}
+
+	select {
+	case s.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Release frees a slot taken by Acquire.
+func (s *Semaphore) Release() {
+	if s == nil {
+		return
+	}
+
+	<-s.slots
+}
+
+// mapBounded calls fn for every item using at most workers concurrent calls
+// and returns the results in the order of items.  Each call additionally
+// holds a slot of the shared semaphore, if any.  The errors of all failing
+// items are joined.  Items which have not started when ctx is cancelled fail
+// with the context error.
+func mapBounded[T, R any](ctx context.Context, items []T, workers int, shared *Semaphore, fn func(context.Context, T) (R, error)) ([]R, error) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	results := make([]R, len(items))
+	errs := make([]error, len(items))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, workers)
+
+	for i := range items {
+		if err := ctx.Err(); err != nil {
+			errs[i] = err
+			continue
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := shared.Acquire(ctx); err != nil {
+				errs[i] = err
+				return
+			}
+			defer shared.Release()
+
+			results[i], errs[i] = fn(ctx, items[i])
+		}(i)
+	}
+
+	wg.Wait()
+
+	return results, errors.Join(errs...)
+}
@@ -0,0 +1,125 @@
+package unikraft
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMapBounded(t *testing.T) {
+	var components []string
+	for i := 0; i < 64; i++ {
+		components = append(components, fmt.Sprintf("lib-%02d", i))
+	}
+
+	var running, peak int32
+	var mu sync.Mutex
+	var resolved []string
+
+	results, err := mapBounded(context.Background(), components, 4, nil, func(_ context.Context, name string) (string, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+
+		mu.Lock()
+		resolved = append(resolved, name)
+		mu.Unlock()
+
+		switch name {
+		case "lib-07":
+			return "", fmt.Errorf("could not find: %s", name)
+		case "lib-42":
+			return "", fmt.Errorf("too many options for %s", name)
+		}
+
+		return name + "@stable", nil
+	})
+
+	if peak > 4 {
+		t.Errorf("expected at most 4 concurrent resolutions, got %d", peak)
+	}
+	if len(resolved) != len(components) {
+		t.Errorf("expected %d resolutions, got %d", len(components), len(resolved))
+	}
+
+	if err == nil {
+		t.Fatal("expected errors to be aggregated")
+	}
+	for _, want := range []string{"could not find: lib-07", "too many options for lib-42"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to contain %q, got %v", want, err)
+		}
+	}
+
+	for i, name := range components {
+		want := name + "@stable"
+		if name == "lib-07" || name == "lib-42" {
+			want = ""
+		}
+		if results[i] != want {
+			t.Errorf("result %d = %q, want %q", i, results[i], want)
+		}
+	}
+}
+
+func TestMapBoundedCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := mapBounded(ctx, []int{1, 2, 3}, 1, nil, func(context.Context, int) (int, error) {
+		return 0, nil
+	})
+	if err == nil {
+		t.Error("expected cancelled context to fail")
+	}
+}
+
+func TestSemaphoreSharedAcrossPools(t *testing.T) {
+	const limit = 3
+
+	shared := NewSemaphore(limit)
+	var inflight, peak int32
+
+	op := func(context.Context, int) (int, error) {
+		n := atomic.AddInt32(&inflight, 1)
+		defer atomic.AddInt32(&inflight, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		return int(n), nil
+	}
+
+	items := make([]int, 32)
+
+	var wg sync.WaitGroup
+	for _, workers := range []int{4, 8} {
+		wg.Add(1)
+		go func(workers int) {
+			defer wg.Done()
+			if _, err := mapBounded(context.Background(), items, workers, shared, op); err != nil {
+				t.Error(err)
+			}
+		}(workers)
+	}
+	wg.Wait()
+
+	if peak > limit {
+		t.Errorf("expected at most %d operations in flight across pools, got %d", limit, peak)
+	}
+	if peak < 2 {
+		t.Errorf("expected operations to run concurrently, got %d", peak)
+	}
+}
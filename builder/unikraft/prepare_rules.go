@@ -0,0 +1,81 @@
+package unikraft
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// prepareRuleMarkers are the constructs of a Makefile.uk which register work
+// for the prepare phase, directly or through the fetch and patch helpers.
+var prepareRuleMarkers = []string{
+	"UK_PREPARE",
+	"UK_FETCH",
+	"call fetch",
+	"call unarchive",
+	"call patch",
+}
+
+// hasPrepareRules reports whether any Makefile.uk under the given directories
+// registers a prepare rule.  It errs on the side of preparing: a directory
+// which cannot be read, or which has no Makefile.uk at all, counts as having
+// prepare rules.
+func hasPrepareRules(dirs []string) bool {
+	for _, dir := range dirs {
+		found := false
+		prepares := false
+
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() && path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			if d.IsDir() || d.Name() != "Makefile.uk" {
+				return nil
+			}
+
+			found = true
+			ok, err := makefileHasPrepareRules(path)
+			if err != nil {
+				return err
+			}
+			if ok {
+				prepares = true
+				return filepath.SkipAll
+			}
+
+			return nil
+		})
+		if err != nil || !found || prepares {
+			return true
+		}
+	}
+
+	return false
+}
+
+func makefileHasPrepareRules(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.Join(strings.Fields(line), " ")
+
+		for _, marker := range prepareRuleMarkers {
+			if strings.Contains(line, marker) {
+				return true, nil
+			}
+		}
+	}
+
+	return false, scanner.Err()
+}
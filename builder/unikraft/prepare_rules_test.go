@@ -0,0 +1,49 @@
+package unikraft
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHasPrepareRules(t *testing.T) {
+	dir := t.TempDir()
+
+	write := func(path, content string) string {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return filepath.Dir(path)
+	}
+
+	app := write("app/Makefile.uk", "$(eval $(call addlib,apphelloworld))\nAPPHELLOWORLD_SRCS-y += $(APPHELLOWORLD_BASE)/main.c\n")
+	core := write("unikraft/lib/ukdebug/Makefile.uk", "# Libraries may use UK_PREPARE to register prepare rules\n$(eval $(call addlib_s,libukdebug,$(CONFIG_LIBUKDEBUG)))\n")
+	musl := write("libs/musl/Makefile.uk", "$(eval $(call fetch, libmusl, $(LIBMUSL_URL)))\n")
+	patched := write("libs/lwip/Makefile.uk", "$(eval $(call  patch,liblwip,$(LIBLWIP_PATCHDIR),$(LIBLWIP_ZIPNAME)))\n")
+	empty := filepath.Join(dir, "libs", "empty")
+	if err := os.MkdirAll(empty, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		dirs []string
+		want bool
+	}{
+		{"no prepare rules", []string{app, filepath.Join(dir, "unikraft")}, false},
+		{"fetched library", []string{app, musl}, true},
+		{"patched library", []string{core, patched}, true},
+		{"missing Makefile.uk", []string{app, empty}, true},
+		{"unreadable directory", []string{app, filepath.Join(dir, "missing")}, true},
+	}
+
+	for _, tt := range tests {
+		if got := hasPrepareRules(tt.dirs); got != tt.want {
+			t.Errorf("%s: hasPrepareRules() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package unikraft
+
+import (
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// killGracePeriod is how long the processes of a cancelled command have to
+// exit once terminated before they are killed.
+const killGracePeriod = 5 * time.Second
+
+// inProcessGroup starts cmd in a process group of its own.  When the context
+// of cmd is done, the group is terminated, and killed when still running
+// after grace, such that the processes cmd started are stopped along with it
+// while the other processes of the host are left alone.
+func inProcessGroup(cmd *exec.Cmd, grace time.Duration) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		terminateGroup(cmd.Process.Pid, grace)
+		return nil
+	}
+}
+
+// terminateGroup terminates the process group pgid, and kills it when still
+// running after grace.
+func terminateGroup(pgid int, grace time.Duration) {
+	// The group may be gone in the meantime.
+	if syscall.Kill(-pgid, syscall.SIGTERM) != nil {
+		return
+	}
+
+	deadline := time.Now().Add(grace)
+	for time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+		if syscall.Kill(-pgid, 0) != nil {
+			return
+		}
+	}
+
+	syscall.Kill(-pgid, syscall.SIGKILL)
+}
@@ -0,0 +1,60 @@
+package unikraft
+
+import (
+	"context"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestInProcessGroup(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The shell ignores SIGTERM, and is only stopped by the SIGKILL sent
+	// after the grace period, along with the sleep it started.
+	cmd := exec.CommandContext(ctx, "sh", "-c", "trap '' TERM; sleep 60 & wait")
+	inProcessGroup(cmd, 300*time.Millisecond)
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	if pgid, err := syscall.Getpgid(cmd.Process.Pid); err != nil || pgid != cmd.Process.Pid {
+		t.Errorf("process group = %d, want a group of its own: %v", pgid, err)
+	}
+
+	// Processes outside of the group are left alone.
+	other := exec.Command("sleep", "60")
+	if err := other.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer other.Process.Kill()
+
+	// Wait for sleep to be started.
+	time.Sleep(200 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("expected the shell to be killed")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the shell is still running")
+	}
+
+	if err := other.Process.Signal(syscall.Signal(0)); err != nil {
+		t.Errorf("expected the other process to be running: %v", err)
+	}
+	// The killed processes of the group are reaped in the background.
+	deadline := time.Now().Add(5 * time.Second)
+	for syscall.Kill(-cmd.Process.Pid, 0) == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the group of the command to be gone")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
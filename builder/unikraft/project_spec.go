@@ -0,0 +1,67 @@
+package unikraft
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ProjectSpec describes a project constructed in code rather than read from a
+// Kraftfile on disk.
+type ProjectSpec struct {
+	// Name is the name of the application.
+	Name string
+	// Workdir is the directory the project is built in.  Defaults to the
+	// current working directory.
+	Workdir string
+	// Unikraft is the core component the project is built against.
+	Unikraft ComponentSpec
+	// Libraries are the additional components of the project.
+	Libraries []ComponentSpec
+	// Targets are the targets of the project.
+	Targets []TargetDefinition
+	// Config are the KConfig options applied to every target.
+	Config map[string]string
+}
+
+// ComponentSpec describes a component of a project spec.  Version and Source
+// are resolved against the catalog like those of a Kraftfile.
+type ComponentSpec struct {
+	Name    string
+	Version string
+	Source  string
+}
+
+// Validate checks that the spec has a name and at least one target, that each
+// target is complete and that no library is listed twice.
+func (s ProjectSpec) Validate() error {
+	var errs []string
+
+	if len(s.Name) == 0 {
+		errs = append(errs, "name is required")
+	}
+
+	if len(s.Targets) == 0 {
+		errs = append(errs, "at least one target is required")
+	} else if err := validateTargetDefinitions(s.Targets, nil); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	libraries := map[string]bool{}
+	for i, l := range s.Libraries {
+		if len(l.Name) == 0 {
+			errs = append(errs, fmt.Sprintf("library %d: name is required", i))
+			continue
+		}
+
+		if libraries[l.Name] {
+			errs = append(errs, fmt.Sprintf("library %s: listed more than once", l.Name))
+		}
+		libraries[l.Name] = true
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid project spec: %s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package unikraft
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testProjectSpec() ProjectSpec {
+	return ProjectSpec{
+		Name:     "helloworld",
+		Unikraft: ComponentSpec{Version: "stable"},
+		Libraries: []ComponentSpec{
+			{Name: "musl", Version: "stable"},
+		},
+		Targets: []TargetDefinition{
+			{Name: "helloworld-qemu-x86_64", Architecture: "x86_64", Platform: "qemu"},
+			{Name: "helloworld-fc-arm64", Architecture: "arm64", Platform: "fc"},
+		},
+		Config: map[string]string{"CONFIG_LIBUKDEBUG": "y"},
+	}
+}
+
+func TestNewProjectFromSpec(t *testing.T) {
+	workdir := t.TempDir()
+
+	project, err := NewProjectFromSpec(context.Background(), workdir, testProjectSpec())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if project.Name() != "helloworld" {
+		t.Errorf("name = %q, want helloworld", project.Name())
+	}
+	if project.WorkingDir() != workdir {
+		t.Errorf("working directory = %q, want %q", project.WorkingDir(), workdir)
+	}
+
+	var targets []string
+	for _, targ := range project.Targets() {
+		targets = append(targets, targ.Name()+" "+targ.Architecture().Name()+" "+targ.Platform().Name())
+	}
+	want := []string{"helloworld-qemu-x86_64 x86_64 qemu", "helloworld-fc-arm64 arm64 fc"}
+	if !reflect.DeepEqual(targets, want) {
+		t.Errorf("targets %v, want %v", targets, want)
+	}
+
+	spec := testProjectSpec()
+	spec.Targets = nil
+	if _, err := NewProjectFromSpec(context.Background(), workdir, spec); err == nil || !strings.Contains(err.Error(), "at least one target") {
+		t.Errorf("expected an invalid spec to be rejected, got %v", err)
+	}
+}
+
+func TestProjectSpecValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*ProjectSpec)
+		want   string
+	}{
+		{name: "valid", modify: func(*ProjectSpec) {}},
+		{name: "no name", modify: func(s *ProjectSpec) { s.Name = "" }, want: "name is required"},
+		{name: "no targets", modify: func(s *ProjectSpec) { s.Targets = nil }, want: "at least one target"},
+		{name: "incomplete target", modify: func(s *ProjectSpec) { s.Targets[0].Platform = "" }, want: "platform is required"},
+		{name: "duplicate library", modify: func(s *ProjectSpec) {
+			s.Libraries = append(s.Libraries, ComponentSpec{Name: "musl"})
+		}, want: "musl: listed more than once"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := testProjectSpec()
+			tt.modify(&spec)
+
+			err := spec.Validate()
+			if tt.want == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
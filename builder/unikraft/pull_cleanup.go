@@ -0,0 +1,28 @@
+package unikraft
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// pullOrCleanup runs a pull placing a component in dir.  When the pull is
+// aborted because ctx is done, the partially pulled component is removed,
+// unless dir already existed, e.g. from a previous pull being updated.
+func pullOrCleanup(ctx context.Context, dir string, pull func(context.Context) error) error {
+	_, err := os.Stat(dir)
+	existed := err == nil
+
+	err = pull(ctx)
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+
+	if !existed && len(dir) > 0 {
+		if rerr := os.RemoveAll(dir); rerr != nil {
+			return fmt.Errorf("%w (could not remove partial pull %s: %v)", ctx.Err(), dir, rerr)
+		}
+	}
+
+	return fmt.Errorf("pull aborted: %w", ctx.Err())
+}
@@ -0,0 +1,102 @@
+package unikraft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// slowPull writes a chunk to a file of dir every tick until ctx is done, as
+// a download honouring its context would.
+func slowPull(dir string, started chan<- struct{}) func(context.Context) error {
+	return func(ctx context.Context) error {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+
+		f, err := os.Create(filepath.Join(dir, "libmusl.tar.gz"))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+
+		for i := 0; i < 1000; i++ {
+			if _, err := f.Write(make([]byte, 1024)); err != nil {
+				return err
+			}
+			if i == 0 {
+				close(started)
+			}
+
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("downloading libmusl: %w", ctx.Err())
+			case <-ticker.C:
+			}
+		}
+
+		return nil
+	}
+}
+
+func TestPullOrCleanupCancelled(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), ".unikraft", "libs", "musl")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- pullOrCleanup(ctx, dir, slowPull(dir, started))
+	}()
+
+	<-started
+	cancelled := time.Now()
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected the pull to be cancelled, got %v", err)
+		}
+		if elapsed := time.Since(cancelled); elapsed > time.Second {
+			t.Errorf("pull took %s to stop after being cancelled", elapsed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("pull did not stop after being cancelled")
+	}
+
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expected the partial pull to be removed, got %v", err)
+	}
+}
+
+func TestPullOrCleanupKeepsExistingComponent(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "Makefile.uk"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := pullOrCleanup(ctx, dir, func(ctx context.Context) error { return ctx.Err() })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the pull to be cancelled, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Makefile.uk")); err != nil {
+		t.Errorf("expected the existing component to be kept: %v", err)
+	}
+
+	if err := pullOrCleanup(context.Background(), dir, func(context.Context) error { return errors.New("unauthorized") }); err == nil || errors.Is(err, context.Canceled) {
+		t.Errorf("expected the pull error to be returned as is, got %v", err)
+	}
+}
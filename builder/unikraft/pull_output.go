@@ -0,0 +1,29 @@
+package unikraft
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// preparePullOutput creates the directory pulled components are placed in
+// and checks that it is writable, returning its absolute path.
+func preparePullOutput(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("could not create pull output %s: %w", dir, err)
+	}
+
+	f, err := os.CreateTemp(dir, ".kraft-pull-*")
+	if err != nil {
+		return "", fmt.Errorf("pull output %s is not writable: %w", dir, err)
+	}
+	f.Close()
+	os.Remove(f.Name())
+
+	return dir, nil
+}
@@ -0,0 +1,31 @@
+package unikraft
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPreparePullOutput(t *testing.T) {
+	dir := t.TempDir()
+
+	out, err := preparePullOutput(filepath.Join(dir, "inspect", "components"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi, err := os.Stat(out); err != nil || !fi.IsDir() {
+		t.Fatalf("expected output directory to be created, got %v", err)
+	}
+	if entries, _ := os.ReadDir(out); len(entries) != 0 {
+		t.Errorf("expected writability probe to be removed, found %d entries", len(entries))
+	}
+
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := preparePullOutput(file); err == nil || !strings.Contains(err.Error(), file) {
+		t.Errorf("expected error naming %s, got %v", file, err)
+	}
+}
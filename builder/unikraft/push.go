@@ -0,0 +1,65 @@
+package unikraft
+
+import (
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// pushReferences parses the reference of a package and the additional tags it
+// is pushed with, which are tags of the same repository.
+func pushReferences(pkg string, tags []string) (name.Reference, []name.Tag, error) {
+	ref, err := name.ParseReference(pkg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid package reference %s: %w", pkg, err)
+	}
+
+	var tagged []name.Tag
+	for _, tag := range tags {
+		t, err := name.NewTag(ref.Context().String() + ":" + tag)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid tag %s: %w", tag, err)
+		}
+
+		tagged = append(tagged, t)
+	}
+
+	return ref, tagged, nil
+}
+
+// annotatePushed adds annotations to the manifest, or index, of the package
+// pushed as ref, and pushes it again as ref.  The package manager of KraftKit
+// packages without annotations of our own, which are thus only recorded in
+// the registry.
+func annotatePushed(ref name.Reference, annotations map[string]string, options ...remote.Option) error {
+	if len(annotations) == 0 {
+		return nil
+	}
+	if _, ok := ref.(name.Tag); !ok {
+		return fmt.Errorf("cannot annotate %s, which is not a tag", ref)
+	}
+
+	desc, err := remote.Get(ref, options...)
+	if err != nil {
+		return err
+	}
+
+	if desc.MediaType.IsIndex() {
+		idx, err := desc.ImageIndex()
+		if err != nil {
+			return err
+		}
+
+		return remote.WriteIndex(ref, mutate.Annotations(idx, annotations).(v1.ImageIndex), options...)
+	}
+
+	img, err := desc.Image()
+	if err != nil {
+		return err
+	}
+
+	return remote.Write(ref, mutate.Annotations(img, annotations).(v1.Image), options...)
+}
@@ -0,0 +1,89 @@
+package unikraft
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+func TestPushReferences(t *testing.T) {
+	ref, tags, err := pushReferences("registry.io/unikraft/nginx:latest", []string{"1.25", "stable"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := ref.Context().RegistryStr(); got != "registry.io" {
+		t.Errorf("registry = %s, want registry.io", got)
+	}
+
+	var got []string
+	for _, tag := range tags {
+		got = append(got, tag.String())
+	}
+	want := []string{"registry.io/unikraft/nginx:1.25", "registry.io/unikraft/nginx:stable"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tags = %v, want %v", got, want)
+	}
+
+	if _, _, err := pushReferences("registry.io/unikraft/nginx:latest", []string{"not a tag"}); err == nil {
+		t.Error("expected an invalid tag to be rejected")
+	}
+}
+
+func TestAnnotatePushed(t *testing.T) {
+	srv := httptest.NewServer(registry.New())
+	defer srv.Close()
+
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ref, err := name.ParseReference(u.Host + "/unikraft/nginx:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(ref, img); err != nil {
+		t.Fatal(err)
+	}
+
+	annotations := map[string]string{VolumesAnnotation: `["./html:/nginx/html:9pfs"]`}
+	if err := annotatePushed(ref, annotations); err != nil {
+		t.Fatal(err)
+	}
+
+	pushed, err := remote.Image(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := pushed.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(manifest.Annotations, annotations) {
+		t.Errorf("annotations = %v, want %v", manifest.Annotations, annotations)
+	}
+
+	h, err := pushed.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest, err := name.NewDigest(u.Host + "/unikraft/nginx@" + h.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := annotatePushed(digest, annotations); err == nil {
+		t.Error("expected a package pinned by digest not to be annotated")
+	}
+}
@@ -0,0 +1,96 @@
+package unikraft
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestThrottledReaderRespectsLimit(t *testing.T) {
+	const limit = 64 * 1024
+
+	limiter := NewRateLimiter(limit)
+	payload := make([]byte, 48*1024)
+
+	// Two concurrent transfers share the limit.
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			r := &throttledReader{
+				ctx:     context.Background(),
+				r:       io.NopCloser(bytes.NewReader(payload)),
+				limiter: limiter,
+			}
+
+			n, err := io.Copy(io.Discard, r)
+			if err != nil || n != int64(len(payload)) {
+				t.Errorf("copied %d bytes: %v", n, err)
+			}
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	// The initial burst is free, the remainder is paced at the limit.
+	total := 2 * len(payload)
+	minimum := time.Duration(float64(total-limiter.burst) / limit * float64(time.Second))
+	if elapsed < minimum*9/10 {
+		t.Errorf("transferred %d bytes in %s, expected at least %s at %d B/s", total, elapsed, minimum, limit)
+	}
+}
+
+func TestRateLimiterCancelled(t *testing.T) {
+	limiter := NewRateLimiter(1024)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := limiter.WaitN(ctx, 1024*1024); err == nil {
+		t.Error("expected cancelled wait to fail")
+	}
+}
+
+func TestNewRateLimiterUnlimited(t *testing.T) {
+	if NewRateLimiter(0) != nil {
+		t.Error("expected no limiter without a limit")
+	}
+}
+
+func TestDownloadTransportRespectsLimit(t *testing.T) {
+	const limit = 32 * 1024
+
+	payload := make([]byte, 32*1024)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(payload)
+	}))
+	defer srv.Close()
+
+	limiter := NewRateLimiter(limit)
+	client := &http.Client{Transport: newDownloadTransport(nil, ProxyConfig{}, nil, limiter)}
+
+	start := time.Now()
+	res, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+	if err != nil || n != int64(len(payload)) {
+		t.Fatalf("copied %d bytes: %v", n, err)
+	}
+	elapsed := time.Since(start)
+
+	minimum := time.Duration(float64(len(payload)-limiter.burst) / limit * float64(time.Second))
+	if elapsed < minimum*9/10 {
+		t.Errorf("downloaded %d bytes in %s, expected at least %s at %d B/s", len(payload), elapsed, minimum, limit)
+	}
+}
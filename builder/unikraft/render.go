@@ -0,0 +1,42 @@
+package unikraft
+
+import (
+	"io"
+	"os"
+)
+
+// Log types understood by KraftKit.
+const (
+	LogTypeFancy = "fancy"
+	LogTypeBasic = "basic"
+)
+
+// isTerminal reports whether w is an interactive terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// logTypeFor returns the log type to render with.  Fancy output garbles logs
+// which are not written to a terminal, so it is replaced by basic output
+// unless forceFancy is set.
+func logTypeFor(configured string, out io.Writer, forceFancy bool) string {
+	if forceFancy {
+		return LogTypeFancy
+	}
+
+	if configured == LogTypeFancy && !isTerminal(out) {
+		return LogTypeBasic
+	}
+
+	return configured
+}
@@ -0,0 +1,37 @@
+package unikraft
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLogTypeFor(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "ci.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	tests := []struct {
+		name       string
+		configured string
+		out        interface{ Write([]byte) (int, error) }
+		force      bool
+		want       string
+	}{
+		{name: "buffer is not a terminal", configured: LogTypeFancy, out: &bytes.Buffer{}, want: LogTypeBasic},
+		{name: "file is not a terminal", configured: LogTypeFancy, out: f, want: LogTypeBasic},
+		{name: "forced fancy", configured: LogTypeBasic, out: &bytes.Buffer{}, force: true, want: LogTypeFancy},
+		{name: "other types are kept", configured: "json", out: &bytes.Buffer{}, want: "json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := logTypeFor(tt.configured, tt.out, tt.force); got != tt.want {
+				t.Errorf("logTypeFor() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
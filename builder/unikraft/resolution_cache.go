@@ -0,0 +1,160 @@
+package unikraft
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultResolutionCacheTTL is how long a cached component resolution is
+// trusted when no TTL is configured.
+const DefaultResolutionCacheTTL = time.Hour
+
+// Resolution is the outcome of resolving a component query against the
+// catalog.
+type Resolution struct {
+	Version    string    `json:"version"`
+	Digest     string    `json:"digest,omitempty"`
+	ResolvedAt time.Time `json:"resolved_at"`
+}
+
+// ResolutionCache persists component resolutions across runs so that repeated
+// builds can avoid refreshing the remote catalog.
+type ResolutionCache struct {
+	Path string
+	TTL  time.Duration
+
+	entries map[string]Resolution
+	mu      sync.Mutex
+	now     func() time.Time
+}
+
+// LoadResolutionCache reads the cache at path.  A missing file yields an
+// empty cache.
+func LoadResolutionCache(path string, ttl time.Duration) (*ResolutionCache, error) {
+	if ttl <= 0 {
+		ttl = DefaultResolutionCacheTTL
+	}
+
+	c := &ResolutionCache{
+		Path:    path,
+		TTL:     ttl,
+		entries: map[string]Resolution{},
+		now:     time.Now,
+	}
+
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(raw, &c.entries); err != nil {
+		return nil, fmt.Errorf("could not parse resolution cache %s: %w", path, err)
+	}
+
+	return c, nil
+}
+
+// Get returns the resolution of a query when it was cached within the TTL.
+func (c *ResolutionCache) Get(key string) (Resolution, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	res, ok := c.entries[key]
+	if !ok || c.now().Sub(res.ResolvedAt) > c.TTL {
+		return Resolution{}, false
+	}
+
+	return res, true
+}
+
+// Put records the resolution of a query.
+func (c *ResolutionCache) Put(key, version, digest string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = Resolution{
+		Version:    version,
+		Digest:     digest,
+		ResolvedAt: c.now(),
+	}
+}
+
+// Invalidate drops every cached resolution.
+func (c *ResolutionCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = map[string]Resolution{}
+}
+
+// Save writes the cache to its path.
+func (c *ResolutionCache) Save() error {
+	c.mu.Lock()
+	raw, err := json.MarshalIndent(c.entries, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.Path), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.Path, raw, 0o644)
+}
+
+// resolutionKey identifies a component query in the resolution cache.
+func resolutionKey(typ, name, version, source string) string {
+	return fmt.Sprintf("%s/%s@%s#%s", typ, name, version, source)
+}
+
+// dirDigest returns a SHA256 digest over the relative paths and contents of
+// every regular file below dir.
+func dirDigest(dir string) (string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sort.Strings(files)
+
+	h := sha256.New()
+	for _, file := range files {
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\x00", filepath.ToSlash(rel))
+
+		f, err := os.Open(file)
+		if err != nil {
+			return "", err
+		}
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
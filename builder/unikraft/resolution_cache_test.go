@@ -0,0 +1,72 @@
+package unikraft
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestResolutionCacheTTL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "resolutions.json")
+	now := time.Date(2023, 8, 1, 12, 0, 0, 0, time.UTC)
+
+	cache, err := LoadResolutionCache(path, 10*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.now = func() time.Time { return now }
+
+	key := resolutionKey("lib", "musl", "stable", "https://manifests.kraftkit.sh/index.yaml")
+	cache.Put(key, "stable", "sha256:abc")
+	if err := cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := LoadResolutionCache(path, 10*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloaded.now = func() time.Time { return now.Add(5 * time.Minute) }
+
+	res, ok := reloaded.Get(key)
+	if !ok || res.Version != "stable" || res.Digest != "sha256:abc" {
+		t.Errorf("expected cached resolution within TTL, got %+v (hit=%v)", res, ok)
+	}
+
+	reloaded.now = func() time.Time { return now.Add(15 * time.Minute) }
+	if _, ok := reloaded.Get(key); ok {
+		t.Errorf("expected cached resolution to expire after TTL")
+	}
+
+	reloaded.now = func() time.Time { return now }
+	reloaded.Invalidate()
+	if _, ok := reloaded.Get(key); ok {
+		t.Errorf("expected invalidated cache to be bypassed")
+	}
+}
+
+func TestDirDigest(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "Makefile.uk"), []byte("a"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := dirDigest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "Makefile.uk"), []byte("b"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := dirDigest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first == second {
+		t.Errorf("expected digest to change with contents")
+	}
+}
@@ -0,0 +1,125 @@
+package unikraft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"time"
+)
+
+// DefaultTransientErrorPatterns are the error message patterns which are
+// treated as transient when no custom patterns are provided.
+var DefaultTransientErrorPatterns = []string{
+	`(?i)timeout`,
+	`(?i)timed out`,
+	`(?i)connection (reset|refused)`,
+	`(?i)temporary failure`,
+	`(?i)too many requests`,
+	`(?i)service unavailable`,
+	`(?i)bad gateway`,
+	`(?i)unexpected EOF`,
+	`(?i)TLS handshake`,
+	`\b(429|502|503|504)\b`,
+}
+
+// DefaultRetryMaxBackoff caps the delay between two retries of catalog
+// queries and pulls.
+const DefaultRetryMaxBackoff = 30 * time.Second
+
+// RetryClassifier decides whether an error returned by a catalog or pull
+// operation is transient and thus worth retrying.
+type RetryClassifier struct {
+	patterns []*regexp.Regexp
+}
+
+// NewRetryClassifier compiles the given error message patterns.  When no
+// patterns are given, DefaultTransientErrorPatterns are used.
+func NewRetryClassifier(patterns []string) (*RetryClassifier, error) {
+	if len(patterns) == 0 {
+		patterns = DefaultTransientErrorPatterns
+	}
+
+	c := &RetryClassifier{}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid retry pattern %q: %w", p, err)
+		}
+		c.patterns = append(c.patterns, re)
+	}
+
+	return c, nil
+}
+
+// IsTransient reports whether err should be retried.
+func (c *RetryClassifier) IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var nerr net.Error
+	if errors.As(err, &nerr) && nerr.Timeout() {
+		return true
+	}
+
+	for _, re := range c.patterns {
+		if re.MatchString(err.Error()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Retrier retries an operation for as long as it fails with a transient error
+// and attempts remain.
+type Retrier struct {
+	// Attempts is the total number of times the operation is tried.
+	Attempts int
+	// Backoff is the delay before the first retry.  It doubles for every
+	// further retry.
+	Backoff time.Duration
+	// MaxBackoff caps the delay between two attempts.  It is unlimited when
+	// not positive.
+	MaxBackoff time.Duration
+	// Classifier decides which errors are transient.
+	Classifier *RetryClassifier
+}
+
+// Delay returns the delay before the given retry, counted from 1.
+func (r *Retrier) Delay(retry int) time.Duration {
+	delay := r.Backoff
+	for i := 1; i < retry && delay > 0; i++ {
+		if r.MaxBackoff > 0 && delay >= r.MaxBackoff {
+			break
+		}
+		delay *= 2
+	}
+
+	if r.MaxBackoff > 0 && delay > r.MaxBackoff {
+		delay = r.MaxBackoff
+	}
+
+	return delay
+}
+
+// Do calls fn until it succeeds, returns a non-transient error or no attempts
+// remain.
+func (r *Retrier) Do(ctx context.Context, fn func() error) error {
+	var err error
+
+	for attempt := 1; ; attempt++ {
+		err = fn()
+		if err == nil || attempt >= r.Attempts || r.Classifier == nil || !r.Classifier.IsTransient(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(r.Delay(attempt)):
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package unikraft
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetrierCustomPattern(t *testing.T) {
+	flaky := func(calls *int) func() error {
+		return func() error {
+			*calls++
+			if *calls == 1 {
+				return errors.New("registry: QUOTA_EXHAUSTED, try again later")
+			}
+			return nil
+		}
+	}
+
+	defaults, err := NewRetryClassifier(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := 0
+	r := &Retrier{Attempts: 3, Classifier: defaults}
+	if err := r.Do(context.Background(), flaky(&calls)); err == nil {
+		t.Errorf("expected default classifier not to retry unknown error")
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call with default patterns, got %d", calls)
+	}
+
+	custom, err := NewRetryClassifier([]string{`QUOTA_EXHAUSTED`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls = 0
+	r = &Retrier{Attempts: 3, Classifier: custom}
+	if err := r.Do(context.Background(), flaky(&calls)); err != nil {
+		t.Errorf("expected custom pattern to be retried, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 calls with custom pattern, got %d", calls)
+	}
+}
+
+func TestRetryClassifierDefaults(t *testing.T) {
+	c, err := NewRetryClassifier(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !c.IsTransient(errors.New("GET https://index.unikraft.io: 503 Service Unavailable")) {
+		t.Errorf("expected 503 to be transient")
+	}
+	if c.IsTransient(errors.New("manifest unknown")) {
+		t.Errorf("expected unknown manifest not to be transient")
+	}
+	if c.IsTransient(context.Canceled) {
+		t.Errorf("expected cancellation not to be transient")
+	}
+	if _, err := NewRetryClassifier([]string{"("}); err == nil {
+		t.Errorf("expected invalid pattern to be rejected")
+	}
+}
+
+func TestRetrierDelay(t *testing.T) {
+	r := &Retrier{Backoff: time.Second, MaxBackoff: 5 * time.Second}
+
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, w := range want {
+		if got := r.Delay(i + 1); got != w {
+			t.Errorf("Delay(%d) = %s, want %s", i+1, got, w)
+		}
+	}
+
+	if got := (&Retrier{}).Delay(3); got != 0 {
+		t.Errorf("expected no delay without a backoff, got %s", got)
+	}
+	if got := (&Retrier{Backoff: time.Millisecond}).Delay(11); got != 1024*time.Millisecond {
+		t.Errorf("expected an unlimited backoff to keep doubling, got %s", got)
+	}
+}
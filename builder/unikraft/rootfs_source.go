@@ -0,0 +1,37 @@
+package unikraft
+
+import (
+	"fmt"
+	"os"
+)
+
+// rootfsOutput is the name of the initramfs constructed by the builder, in
+// the build folder.
+const rootfsOutput = "initramfs.cpio"
+
+// checkRootfsSource checks that at most one of a rootfs directory and a
+// Dockerfile is given, and that it exists.
+func checkRootfsSource(dir, dockerfile string) error {
+	switch {
+	case dir != "" && dockerfile != "":
+		return fmt.Errorf("rootfs_dir cannot be combined with rootfs_dockerfile")
+
+	case dir != "":
+		info, err := os.Stat(dir)
+		if err != nil {
+			return fmt.Errorf("rootfs_dir: %w", err)
+		} else if !info.IsDir() {
+			return fmt.Errorf("rootfs_dir: %s is not a directory", dir)
+		}
+
+	case dockerfile != "":
+		info, err := os.Stat(dockerfile)
+		if err != nil {
+			return fmt.Errorf("rootfs_dockerfile: %w", err)
+		} else if info.IsDir() {
+			return fmt.Errorf("rootfs_dockerfile: %s is a directory", dockerfile)
+		}
+	}
+
+	return nil
+}
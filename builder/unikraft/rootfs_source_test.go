@@ -0,0 +1,42 @@
+package unikraft
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckRootfsSource(t *testing.T) {
+	dir := t.TempDir()
+	dockerfile := filepath.Join(dir, "Dockerfile")
+	if err := os.WriteFile(dockerfile, []byte("FROM scratch\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		dir        string
+		dockerfile string
+		want       string
+	}{
+		{name: "none"},
+		{name: "directory", dir: dir},
+		{name: "dockerfile", dockerfile: dockerfile},
+		{name: "both", dir: dir, dockerfile: dockerfile, want: "cannot be combined"},
+		{name: "missing directory", dir: filepath.Join(dir, "rootfs"), want: "rootfs_dir"},
+		{name: "file as directory", dir: dockerfile, want: "is not a directory"},
+		{name: "directory as dockerfile", dockerfile: dir, want: "is a directory"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkRootfsSource(tt.dir, tt.dockerfile)
+			if tt.want == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package unikraft
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+var sha256DigestPattern = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// PinnedImage is a container image reference pinned by digest, e.g.
+// `unikraft.org/base@sha256:...`.
+type PinnedImage struct {
+	Name   string
+	Digest string
+}
+
+// ParsePinnedImage parses an `image@sha256:...` reference.  References by
+// tag are rejected since the image they point to may change.
+func ParsePinnedImage(ref string) (PinnedImage, error) {
+	name, digest, ok := strings.Cut(ref, "@")
+	if !ok || name == "" {
+		return PinnedImage{}, fmt.Errorf("image %q is not pinned by digest, expected image@sha256:<digest>", ref)
+	}
+
+	if !sha256DigestPattern.MatchString(digest) {
+		return PinnedImage{}, fmt.Errorf("image %q has an invalid digest %q", ref, digest)
+	}
+
+	return PinnedImage{Name: name, Digest: digest}, nil
+}
+
+func (p PinnedImage) String() string {
+	return p.Name + "@" + p.Digest
+}
+
+// Verify checks that the digest resolve returns for the image matches the
+// pinned one.
+func (p PinnedImage) Verify(ctx context.Context, resolve func(context.Context, string) (string, error)) error {
+	digest, err := resolve(ctx, p.String())
+	if err != nil {
+		return fmt.Errorf("could not resolve image %s: %w", p.Name, err)
+	}
+
+	if digest != p.Digest {
+		return fmt.Errorf("image %s has digest %s, expected %s", p.Name, digest, p.Digest)
+	}
+
+	return nil
+}
+
+// remoteImageDigest returns the digest of the manifest of an image in its
+// registry.
+func remoteImageDigest(ctx context.Context, ref string) (string, error) {
+	r, err := name.ParseReference(ref)
+	if err != nil {
+		return "", err
+	}
+
+	desc, err := remote.Get(r,
+		remote.WithContext(ctx),
+		remote.WithAuthFromKeychain(authn.DefaultKeychain),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return desc.Digest.String(), nil
+}
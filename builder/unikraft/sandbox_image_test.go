@@ -0,0 +1,57 @@
+package unikraft
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const (
+	pinnedDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	otherDigest  = "sha256:fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210"
+)
+
+func TestParsePinnedImage(t *testing.T) {
+	img, err := ParsePinnedImage("unikraft.org/base@" + pinnedDigest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Name != "unikraft.org/base" || img.Digest != pinnedDigest {
+		t.Errorf("unexpected image %+v", img)
+	}
+
+	for _, ref := range []string{
+		"unikraft.org/base:latest",
+		"unikraft.org/base@sha256:1234",
+		"@" + pinnedDigest,
+	} {
+		if _, err := ParsePinnedImage(ref); err == nil {
+			t.Errorf("expected %q to be rejected", ref)
+		}
+	}
+}
+
+func TestPinnedImageVerify(t *testing.T) {
+	img, err := ParsePinnedImage("unikraft.org/base@" + pinnedDigest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resolved := func(digest string, err error) func(context.Context, string) (string, error) {
+		return func(context.Context, string) (string, error) { return digest, err }
+	}
+
+	if err := img.Verify(context.Background(), resolved(pinnedDigest, nil)); err != nil {
+		t.Errorf("unexpected error for matching digest: %v", err)
+	}
+
+	err = img.Verify(context.Background(), resolved(otherDigest, nil))
+	if err == nil || !strings.Contains(err.Error(), otherDigest) {
+		t.Errorf("expected digest mismatch, got %v", err)
+	}
+
+	if err := img.Verify(context.Background(), resolved("", errors.New("unauthorized"))); err == nil {
+		t.Error("expected an error when the image cannot be resolved")
+	}
+}
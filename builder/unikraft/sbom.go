@@ -0,0 +1,296 @@
+package unikraft
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// The formats a software bill of materials is written in.
+const (
+	SBOMSPDX      = "spdx"
+	SBOMCycloneDX = "cyclonedx"
+)
+
+// SBOMFormats are the formats a software bill of materials may be written in.
+var SBOMFormats = []string{SBOMSPDX, SBOMCycloneDX}
+
+// sbomTool is the tool recorded as the creator of the documents.
+const sbomTool = "packer-plugin-unikraft"
+
+// CheckSBOMFormat returns an error when format is not one of SBOMFormats.
+func CheckSBOMFormat(format string) error {
+	for _, f := range SBOMFormats {
+		if format == f {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown sbom format %q, expected %s", format, strings.Join(SBOMFormats, " or "))
+}
+
+// SBOMComponent is a component which went into a packaged unikernel.
+type SBOMComponent struct {
+	// Type is the type of the component: `core`, `lib` or `app` as in the
+	// Kraftfile, or `kernel` for the packaged kernels.
+	Type    string
+	Name    string
+	Version string
+	// Source is where the component was retrieved from.
+	Source string
+	// Digest is the digest of the component, in the `sha256:<hex>` form.
+	Digest string
+}
+
+// SBOM is the software bill of materials of a package.
+type SBOM struct {
+	// Name is the name of the package.
+	Name       string
+	Components []SBOMComponent
+	// Created is when the bill was created.
+	Created time.Time
+}
+
+// SBOMPath returns the path the bill of materials of a package is written to:
+// next to disk images, and in the .unikraft directory of the project for other
+// packages, whose name is a reference.
+func SBOMPath(workdir, pkgName, format string, disk bool) string {
+	ext := ".spdx.json"
+	if format == SBOMCycloneDX {
+		ext = ".cdx.json"
+	}
+
+	if disk {
+		return pkgName + ext
+	}
+
+	name := strings.NewReplacer("/", "_", ":", "_", "@", "_").Replace(pkgName)
+	return filepath.Join(workdir, ".unikraft", "sbom", name+ext)
+}
+
+// WriteFile writes the bill to path in format.
+func (s SBOM) WriteFile(path, format string) error {
+	var doc interface{}
+	switch format {
+	case SBOMSPDX:
+		doc = s.spdx()
+	case SBOMCycloneDX:
+		doc = s.cycloneDX()
+	default:
+		return CheckSBOMFormat(format)
+	}
+
+	raw, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(raw, '\n'), 0o644)
+}
+
+// sbomHex returns the hexadecimal SHA256 digest of a `sha256:<hex>` digest,
+// or an empty string for other digests.
+func sbomHex(digest string) string {
+	if !strings.HasPrefix(digest, "sha256:") {
+		return ""
+	}
+
+	return strings.TrimPrefix(digest, "sha256:")
+}
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name                  string         `json:"name"`
+	SPDXID                string         `json:"SPDXID"`
+	VersionInfo           string         `json:"versionInfo,omitempty"`
+	DownloadLocation      string         `json:"downloadLocation"`
+	FilesAnalyzed         bool           `json:"filesAnalyzed"`
+	Checksums             []spdxChecksum `json:"checksums,omitempty"`
+	PrimaryPackagePurpose string         `json:"primaryPackagePurpose,omitempty"`
+	Comment               string         `json:"comment,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// spdx returns the bill as an SPDX 2.3 document, describing the package which
+// contains every component.
+func (s SBOM) spdx() spdxDocument {
+	doc := spdxDocument{
+		SPDXVersion: "SPDX-2.3",
+		DataLicense: "CC0-1.0",
+		SPDXID:      "SPDXRef-DOCUMENT",
+		Name:        s.Name,
+		// The namespace must be unique to the document, which its contents
+		// identify.
+		DocumentNamespace: fmt.Sprintf("https://unikraft.org/spdx/%s-%s", s.Name, s.digest()),
+		CreationInfo: spdxCreationInfo{
+			Created:  s.Created.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: " + sbomTool},
+		},
+		Packages: []spdxPackage{{
+			Name:                  s.Name,
+			SPDXID:                "SPDXRef-Package",
+			DownloadLocation:      "NOASSERTION",
+			PrimaryPackagePurpose: "OPERATING-SYSTEM",
+		}},
+		Relationships: []spdxRelationship{{
+			SPDXElementID:      "SPDXRef-DOCUMENT",
+			RelationshipType:   "DESCRIBES",
+			RelatedSPDXElement: "SPDXRef-Package",
+		}},
+	}
+
+	for i, c := range s.Components {
+		p := spdxPackage{
+			Name:             c.Name,
+			SPDXID:           fmt.Sprintf("SPDXRef-Component-%d", i),
+			VersionInfo:      c.Version,
+			DownloadLocation: "NOASSERTION",
+			Comment:          "unikraft " + c.Type,
+		}
+		if c.Source != "" {
+			p.DownloadLocation = c.Source
+		}
+		if sum := sbomHex(c.Digest); sum != "" {
+			p.Checksums = []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: sum}}
+		}
+		switch c.Type {
+		case "lib", "core":
+			p.PrimaryPackagePurpose = "LIBRARY"
+		case "app":
+			p.PrimaryPackagePurpose = "SOURCE"
+		case "kernel":
+			p.PrimaryPackagePurpose = "OPERATING-SYSTEM"
+		}
+
+		doc.Packages = append(doc.Packages, p)
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID:      "SPDXRef-Package",
+			RelationshipType:   "CONTAINS",
+			RelatedSPDXElement: p.SPDXID,
+		})
+	}
+
+	return doc
+}
+
+type cycloneDXDocument struct {
+	BOMFormat   string               `json:"bomFormat"`
+	SpecVersion string               `json:"specVersion"`
+	Version     int                  `json:"version"`
+	Metadata    cycloneDXMetadata    `json:"metadata"`
+	Components  []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXMetadata struct {
+	Timestamp string             `json:"timestamp"`
+	Tools     []cycloneDXTool    `json:"tools"`
+	Component cycloneDXComponent `json:"component"`
+}
+
+type cycloneDXTool struct {
+	Name string `json:"name"`
+}
+
+type cycloneDXComponent struct {
+	Type               string               `json:"type"`
+	Group              string               `json:"group,omitempty"`
+	Name               string               `json:"name"`
+	Version            string               `json:"version,omitempty"`
+	Hashes             []cycloneDXHash      `json:"hashes,omitempty"`
+	ExternalReferences []cycloneDXReference `json:"externalReferences,omitempty"`
+}
+
+type cycloneDXHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cycloneDXReference struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// cycloneDX returns the bill as a CycloneDX 1.5 document, whose metadata
+// describe the package.
+func (s SBOM) cycloneDX() cycloneDXDocument {
+	doc := cycloneDXDocument{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.5",
+		Version:     1,
+		Metadata: cycloneDXMetadata{
+			Timestamp: s.Created.UTC().Format(time.RFC3339),
+			Tools:     []cycloneDXTool{{Name: sbomTool}},
+			Component: cycloneDXComponent{Type: "operating-system", Name: s.Name},
+		},
+		Components: []cycloneDXComponent{},
+	}
+
+	for _, c := range s.Components {
+		comp := cycloneDXComponent{
+			Type:    "library",
+			Group:   c.Type,
+			Name:    c.Name,
+			Version: c.Version,
+		}
+		switch c.Type {
+		case "app":
+			comp.Type = "application"
+		case "kernel":
+			comp.Type = "operating-system"
+		}
+		if sum := sbomHex(c.Digest); sum != "" {
+			comp.Hashes = []cycloneDXHash{{Alg: "SHA-256", Content: sum}}
+		}
+		if c.Source != "" {
+			comp.ExternalReferences = []cycloneDXReference{{Type: "distribution", URL: c.Source}}
+		}
+
+		doc.Components = append(doc.Components, comp)
+	}
+
+	return doc
+}
+
+// digest returns a digest of the components of the bill.
+func (s SBOM) digest() string {
+	h := sha256.New()
+	for _, c := range s.Components {
+		fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s\x00%s\n", c.Type, c.Name, c.Version, c.Source, c.Digest)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
@@ -0,0 +1,134 @@
+package unikraft
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testSBOM() SBOM {
+	return SBOM{
+		Name: "unikraft.org/nginx:latest",
+		Components: []SBOMComponent{
+			{Type: "app", Name: "nginx", Source: "/src/nginx"},
+			{Type: "core", Name: "unikraft", Version: "0.16.1", Source: "https://github.com/unikraft/unikraft.git", Digest: "sha256:aa11"},
+			{Type: "lib", Name: "musl", Version: "stable", Source: "https://github.com/unikraft/lib-musl.git", Digest: "sha256:bb22"},
+			{Type: "kernel", Name: "nginx-qemu-x86_64", Digest: "sha256:cc33"},
+		},
+		Created: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func readSBOM(t *testing.T, path string) map[string]interface{} {
+	t.Helper()
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	return doc
+}
+
+func TestSBOMWriteSPDX(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sbom", "nginx.spdx.json")
+	if err := testSBOM().WriteFile(path, SBOMSPDX); err != nil {
+		t.Fatal(err)
+	}
+
+	doc := readSBOM(t, path)
+	if doc["spdxVersion"] != "SPDX-2.3" || doc["name"] != "unikraft.org/nginx:latest" {
+		t.Errorf("unexpected document %v", doc)
+	}
+	if created := doc["creationInfo"].(map[string]interface{})["created"]; created != "2024-03-01T12:00:00Z" {
+		t.Errorf("created = %v", created)
+	}
+
+	packages := doc["packages"].([]interface{})
+	if len(packages) != 5 {
+		t.Fatalf("expected the package and 4 components, got %d", len(packages))
+	}
+
+	musl := packages[3].(map[string]interface{})
+	if musl["name"] != "musl" || musl["versionInfo"] != "stable" || musl["downloadLocation"] != "https://github.com/unikraft/lib-musl.git" {
+		t.Errorf("unexpected musl package %v", musl)
+	}
+	checksums := musl["checksums"].([]interface{})
+	if sum := checksums[0].(map[string]interface{}); sum["algorithm"] != "SHA256" || sum["checksumValue"] != "bb22" {
+		t.Errorf("unexpected checksum %v", sum)
+	}
+
+	if app := packages[1].(map[string]interface{}); app["checksums"] != nil {
+		t.Errorf("expected no checksum for the app, got %v", app["checksums"])
+	}
+
+	if relationships := doc["relationships"].([]interface{}); len(relationships) != 5 {
+		t.Errorf("expected 5 relationships, got %d", len(relationships))
+	}
+}
+
+func TestSBOMWriteCycloneDX(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nginx.cdx.json")
+	if err := testSBOM().WriteFile(path, SBOMCycloneDX); err != nil {
+		t.Fatal(err)
+	}
+
+	doc := readSBOM(t, path)
+	if doc["bomFormat"] != "CycloneDX" || doc["specVersion"] != "1.5" {
+		t.Errorf("unexpected document %v", doc)
+	}
+
+	components := doc["components"].([]interface{})
+	if len(components) != 4 {
+		t.Fatalf("expected 4 components, got %d", len(components))
+	}
+
+	var types []string
+	for _, c := range components {
+		types = append(types, c.(map[string]interface{})["type"].(string))
+	}
+	if got := strings.Join(types, ","); got != "application,library,library,operating-system" {
+		t.Errorf("types = %s", got)
+	}
+
+	core := components[1].(map[string]interface{})
+	if core["group"] != "core" || core["version"] != "0.16.1" {
+		t.Errorf("unexpected core component %v", core)
+	}
+	if hash := core["hashes"].([]interface{})[0].(map[string]interface{}); hash["alg"] != "SHA-256" || hash["content"] != "aa11" {
+		t.Errorf("unexpected hash %v", hash)
+	}
+}
+
+func TestSBOMWriteUnknownFormat(t *testing.T) {
+	err := testSBOM().WriteFile(filepath.Join(t.TempDir(), "sbom"), "swid")
+	if err == nil || !strings.Contains(err.Error(), `unknown sbom format "swid"`) {
+		t.Errorf("expected an unknown format error, got %v", err)
+	}
+}
+
+func TestSBOMPath(t *testing.T) {
+	tests := []struct {
+		pkgName, format string
+		disk            bool
+		want            string
+	}{
+		{"unikraft.org/nginx:latest", SBOMSPDX, false, "/src/nginx/.unikraft/sbom/unikraft.org_nginx_latest.spdx.json"},
+		{"unikraft.org/nginx:latest", SBOMCycloneDX, false, "/src/nginx/.unikraft/sbom/unikraft.org_nginx_latest.cdx.json"},
+		{"/out/nginx.img", SBOMSPDX, true, "/out/nginx.img.spdx.json"},
+	}
+
+	for _, tt := range tests {
+		if got := SBOMPath("/src/nginx", tt.pkgName, tt.format, tt.disk); got != tt.want {
+			t.Errorf("SBOMPath(%q, %s, %v) = %s, want %s", tt.pkgName, tt.format, tt.disk, got, tt.want)
+		}
+	}
+}
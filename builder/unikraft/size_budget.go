@@ -0,0 +1,41 @@
+package unikraft
+
+import (
+	"fmt"
+	"os"
+)
+
+// kernelSizeBudget returns the size budget of a target, preferring the budget
+// set for the target by name over the default.  A budget which is not positive
+// is unlimited.
+func kernelSizeBudget(name string, fallback int64, budgets map[string]int64) int64 {
+	if budget, ok := budgets[name]; ok {
+		return budget
+	}
+
+	return fallback
+}
+
+// checkKernelSize returns an error when the kernel at path is larger than
+// budget bytes.
+func checkKernelSize(path string, budget int64) error {
+	if budget <= 0 {
+		return nil
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("could not check kernel size: %w", err)
+	}
+
+	if fi.Size() > budget {
+		return fmt.Errorf("kernel %s is %d bytes which exceeds the allowed %d bytes by %d bytes",
+			path,
+			fi.Size(),
+			budget,
+			fi.Size()-budget,
+		)
+	}
+
+	return nil
+}
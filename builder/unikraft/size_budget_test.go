@@ -0,0 +1,38 @@
+package unikraft
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckKernelSize(t *testing.T) {
+	kernel := filepath.Join(t.TempDir(), "nginx_qemu-x86_64")
+	if err := os.WriteFile(kernel, make([]byte, 2048), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	budgets := map[string]int64{"nginx-fc-x86_64": 4096}
+
+	tests := []struct {
+		target string
+		ok     bool
+	}{
+		{target: "nginx-qemu-x86_64", ok: false},
+		{target: "nginx-fc-x86_64", ok: true},
+	}
+
+	for _, tt := range tests {
+		err := checkKernelSize(kernel, kernelSizeBudget(tt.target, 1024, budgets))
+		if tt.ok && err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.target, err)
+		} else if !tt.ok && (err == nil || !strings.Contains(err.Error(), "is 2048 bytes which exceeds the allowed 1024 bytes")) {
+			t.Errorf("%s: expected oversized kernel error, got %v", tt.target, err)
+		}
+	}
+
+	if err := checkKernelSize(kernel, 0); err != nil {
+		t.Errorf("expected no budget to pass, got %v", err)
+	}
+}
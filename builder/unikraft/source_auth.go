@@ -0,0 +1,232 @@
+package unikraft
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+)
+
+// SourceAuth are the credentials of a private manifest source.  A source
+// served over HTTP(S) authenticates with either a username and password or a
+// token, and a Git source cloned over SSH with a private key.
+type SourceAuth struct {
+	// Source is the source, or the URL prefix of the sources, the credentials
+	// are sent to.
+	Source   string
+	Username string
+	Password string
+	Token    string
+	// SSHPrivateKeyFile is the private key Git sources are cloned over SSH
+	// with, decrypted with SSHPassphrase when it is encrypted.
+	SSHPrivateKeyFile string
+	SSHPassphrase     string
+}
+
+// IsSSH returns whether the source authenticates with a private key.
+func (a SourceAuth) IsSSH() bool {
+	return a.SSHPrivateKeyFile != ""
+}
+
+// Host returns the host of the source, or an empty string when it is not an
+// HTTP(S) URL.
+func (a SourceAuth) Host() string {
+	u, err := url.Parse(a.Source)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+
+	return u.Host
+}
+
+// Secrets returns the sensitive values of the credentials, which are
+// filtered out of the logs.
+func (a SourceAuth) Secrets() []string {
+	var secrets []string
+	for _, s := range []string{a.Password, a.Token, a.SSHPassphrase} {
+		if s != "" {
+			secrets = append(secrets, s)
+		}
+	}
+
+	return secrets
+}
+
+// Validate checks that the credentials name a source and authenticate it in
+// exactly one way.
+func (a SourceAuth) Validate() error {
+	if a.Source == "" {
+		return fmt.Errorf("source must be specified")
+	}
+
+	methods := 0
+	if a.Username != "" || a.Password != "" {
+		methods++
+		if a.Username == "" || a.Password == "" {
+			return fmt.Errorf("%s: username and password must be set together", a.Source)
+		}
+	}
+	if a.Token != "" {
+		methods++
+	}
+	if a.IsSSH() {
+		methods++
+	} else if a.SSHPassphrase != "" {
+		return fmt.Errorf("%s: ssh_passphrase requires ssh_private_key_file", a.Source)
+	}
+
+	switch {
+	case methods == 0:
+		return fmt.Errorf("%s: expected username and password, token or ssh_private_key_file", a.Source)
+	case methods > 1:
+		return fmt.Errorf("%s: username and password, token and ssh_private_key_file cannot be combined", a.Source)
+	}
+
+	if a.IsSSH() {
+		if _, err := os.Stat(a.SSHPrivateKeyFile); err != nil {
+			return fmt.Errorf("%s: ssh_private_key_file cannot be read: %w", a.Source, err)
+		}
+		if ep, err := transport.NewEndpoint(a.Source); err != nil || ep.Protocol != "ssh" || ep.Host == "" {
+			return fmt.Errorf("%s: expected an ssh URL or user@host:path", a.Source)
+		}
+
+		return nil
+	}
+
+	if a.Host() == "" {
+		return fmt.Errorf("%s: expected an http or https URL", a.Source)
+	}
+
+	return nil
+}
+
+// authorize sets the credentials on req.
+func (a SourceAuth) authorize(req *http.Request) {
+	if a.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+a.Token)
+	} else {
+		req.SetBasicAuth(a.Username, a.Password)
+	}
+}
+
+// ValidateSourceAuths checks every credentials of auths, and that no source
+// has several of them.
+func ValidateSourceAuths(auths []SourceAuth) error {
+	sources := map[string]bool{}
+	for i, a := range auths {
+		if err := a.Validate(); err != nil {
+			return fmt.Errorf("source_auth[%d]: %w", i, err)
+		}
+
+		if sources[a.Source] {
+			return fmt.Errorf("source_auth[%d]: %s has several credentials", i, a.Source)
+		}
+		sources[a.Source] = true
+	}
+
+	return nil
+}
+
+// hasURLPrefix returns the rest of raw after prefix, and whether raw starts
+// with prefix at a path boundary.
+func hasURLPrefix(raw, prefix string) (string, bool) {
+	rest, ok := strings.CutPrefix(raw, strings.TrimSuffix(prefix, "/"))
+	if !ok || (rest != "" && !strings.HasPrefix(rest, "/") && !strings.HasPrefix(rest, "?")) {
+		return "", false
+	}
+
+	return rest, true
+}
+
+// sourceAuthFor returns the credentials of the longest source of auths u
+// starts with, and whether there is one.
+func sourceAuthFor(auths []SourceAuth, u *url.URL) (SourceAuth, bool) {
+	var found SourceAuth
+	for _, a := range auths {
+		if a.IsSSH() || len(a.Source) <= len(found.Source) {
+			continue
+		}
+		if _, ok := hasURLPrefix(u.String(), a.Source); ok {
+			found = a
+		}
+	}
+
+	return found, found.Source != ""
+}
+
+// authTransport sets the credentials of their source on the requests of base
+// which do not carry any.
+type authTransport struct {
+	base  http.RoundTripper
+	auths []SourceAuth
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	a, ok := sourceAuthFor(t.auths, req.URL)
+	if !ok || req.Header.Get("Authorization") != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	a.authorize(req)
+
+	return t.base.RoundTrip(req)
+}
+
+// sshAuthFor returns the credentials of the SSH source of auths on the host of
+// the Git repository repo with the longest path repo is in, and whether there
+// is one.
+func sshAuthFor(auths []SourceAuth, repo *transport.Endpoint) (SourceAuth, bool) {
+	var found SourceAuth
+	longest := -1
+	for _, a := range auths {
+		if !a.IsSSH() {
+			continue
+		}
+
+		source, err := transport.NewEndpoint(a.Source)
+		if err != nil || !strings.EqualFold(source.Host, repo.Host) {
+			continue
+		}
+
+		path := "/" + strings.Trim(source.Path, "/")
+		if _, ok := hasURLPrefix("/"+strings.TrimPrefix(repo.Path, "/"), path); ok && len(path) > longest {
+			found, longest = a, len(path)
+		}
+	}
+
+	return found, longest >= 0
+}
+
+// gitSSHAuth returns the function choosing the credentials a Git repository
+// is cloned over SSH with: the private key of its source in auths, or none,
+// to authenticate with the SSH agent, when it has no source.
+func gitSSHAuth(auths []SourceAuth) func(repo string) (gitssh.AuthMethod, error) {
+	return func(repo string) (gitssh.AuthMethod, error) {
+		ep, err := transport.NewEndpoint(repo)
+		if err != nil || ep.Protocol != "ssh" {
+			return nil, nil
+		}
+
+		a, ok := sshAuthFor(auths, ep)
+		if !ok {
+			return nil, nil
+		}
+
+		user := ep.User
+		if user == "" {
+			user = "git"
+		}
+
+		keys, err := gitssh.NewPublicKeysFromFile(user, a.SSHPrivateKeyFile, a.SSHPassphrase)
+		if err != nil {
+			return nil, fmt.Errorf("could not load the private key of %s: %w", a.Source, err)
+		}
+
+		return keys, nil
+	}
+}
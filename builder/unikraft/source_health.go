@@ -0,0 +1,117 @@
+package unikraft
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultSourceMaxAge is the age after which a source which has not been
+// updated is reported as stale.
+const DefaultSourceMaxAge = 7 * 24 * time.Hour
+
+// SourceHealth is the health of a single catalog source.
+type SourceHealth struct {
+	Source    string    `json:"source"`
+	Reachable bool      `json:"reachable"`
+	Updated   time.Time `json:"updated,omitempty"`
+	// Components is the number of components the source provides.
+	Components int    `json:"components"`
+	Stale      bool   `json:"stale"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Healthy reports whether the source is reachable and fresh.
+func (h SourceHealth) Healthy() bool {
+	return h.Reachable && !h.Stale
+}
+
+func (h SourceHealth) String() string {
+	if !h.Reachable {
+		return fmt.Sprintf("%s: unreachable: %s", h.Source, h.Error)
+	}
+
+	updated := "unknown"
+	if !h.Updated.IsZero() {
+		updated = h.Updated.UTC().Format(time.RFC3339)
+	}
+
+	status := "ok"
+	if h.Stale {
+		status = "stale"
+	}
+
+	return fmt.Sprintf("%s: %s, %d components, last updated %s", h.Source, status, h.Components, updated)
+}
+
+// sourceProbe is what probing a reachable source reveals.
+type sourceProbe struct {
+	Updated    time.Time
+	Components int
+}
+
+// assessSources probes every source and flags those which cannot be reached
+// or were last updated more than maxAge before now.  A source whose update
+// time is unknown is never stale.
+func assessSources(ctx context.Context, sources []string, probe func(context.Context, string) (sourceProbe, error), maxAge time.Duration, now time.Time) []SourceHealth {
+	if maxAge <= 0 {
+		maxAge = DefaultSourceMaxAge
+	}
+
+	health := make([]SourceHealth, 0, len(sources))
+	for _, source := range sources {
+		h := SourceHealth{Source: source}
+
+		p, err := probe(ctx, source)
+		if err != nil {
+			h.Error = err.Error()
+		} else {
+			h.Reachable = true
+			h.Updated = p.Updated
+			h.Components = p.Components
+			h.Stale = !p.Updated.IsZero() && now.Sub(p.Updated) > maxAge
+		}
+
+		health = append(health, h)
+	}
+
+	return health
+}
+
+// sourceLastUpdated returns when a source was last modified, from the
+// Last-Modified header of a remote source or the modification time of a
+// local one.  The zero time is returned when it cannot be told.
+func sourceLastUpdated(ctx context.Context, client *http.Client, source string) (time.Time, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		fi, err := os.Stat(source)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return fi.ModTime(), nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, source, nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return time.Time{}, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return time.Time{}, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	modified, err := http.ParseTime(resp.Header.Get("Last-Modified"))
+	if err != nil {
+		return time.Time{}, nil
+	}
+
+	return modified, nil
+}
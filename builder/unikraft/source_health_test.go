@@ -0,0 +1,97 @@
+package unikraft
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAssessSources(t *testing.T) {
+	now := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	fake := map[string]sourceProbe{
+		"https://manifests.kraftkit.sh/index.yaml": {Updated: now.Add(-time.Hour), Components: 42},
+		"https://old.example.com/index.yaml":       {Updated: now.Add(-30 * 24 * time.Hour), Components: 3},
+		"/srv/manifests":                           {Components: 5},
+	}
+	probe := func(_ context.Context, source string) (sourceProbe, error) {
+		p, ok := fake[source]
+		if !ok {
+			return sourceProbe{}, errors.New("connection refused")
+		}
+		return p, nil
+	}
+
+	health := assessSources(context.Background(), []string{
+		"https://manifests.kraftkit.sh/index.yaml",
+		"https://old.example.com/index.yaml",
+		"https://down.example.com/index.yaml",
+		"/srv/manifests",
+	}, probe, 0, now)
+
+	tests := []struct {
+		reachable, stale bool
+		components       int
+	}{
+		{true, false, 42},
+		{true, true, 3},
+		{false, false, 0},
+		{true, false, 5},
+	}
+
+	if len(health) != len(tests) {
+		t.Fatalf("expected %d results, got %d", len(tests), len(health))
+	}
+	for i, tt := range tests {
+		h := health[i]
+		if h.Reachable != tt.reachable || h.Stale != tt.stale || h.Components != tt.components {
+			t.Errorf("%s: unexpected health %+v", h.Source, h)
+		}
+	}
+
+	if !health[0].Healthy() || health[1].Healthy() || health[2].Healthy() {
+		t.Errorf("unexpected healthiness: %v", health)
+	}
+	if !strings.Contains(health[2].String(), "unreachable: connection refused") {
+		t.Errorf("unexpected report for unreachable source: %s", health[2])
+	}
+}
+
+func TestSourceLastUpdated(t *testing.T) {
+	modified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+	}))
+	defer srv.Close()
+
+	got, err := sourceLastUpdated(context.Background(), srv.Client(), srv.URL+"/index.yaml")
+	if err != nil || !got.Equal(modified) {
+		t.Errorf("expected %s, got %s (%v)", modified, got, err)
+	}
+
+	if _, err := sourceLastUpdated(context.Background(), srv.Client(), srv.URL+"/missing"); err == nil {
+		t.Error("expected an error for a missing remote source")
+	}
+
+	path := filepath.Join(t.TempDir(), "index.yaml")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+	got, err = sourceLastUpdated(context.Background(), srv.Client(), path)
+	if err != nil || !got.Equal(modified) {
+		t.Errorf("expected %s, got %s (%v)", modified, got, err)
+	}
+}
@@ -0,0 +1,23 @@
+package unikraft
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// StagedPath returns where a file recorded at path by the builder is found
+// while the build runs.  Built files are kept in the dist folder of the
+// project, and only moved to the build folder recorded in the artifact once
+// the build, provisioners included, completes.
+func StagedPath(path string) string {
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+
+	dir := filepath.Dir(path)
+	if filepath.Base(dir) != "build" || filepath.Base(filepath.Dir(dir)) != ".unikraft" {
+		return path
+	}
+
+	return filepath.Join(filepath.Dir(dir), "dist", filepath.Base(path))
+}
@@ -0,0 +1,39 @@
+package unikraft
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStagedPath(t *testing.T) {
+	project := t.TempDir()
+	dist := filepath.Join(project, ".unikraft", "dist")
+	if err := os.MkdirAll(dist, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dist, "helloworld_qemu-x86_64"), []byte("\x7fELF"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	recorded := filepath.Join(project, ".unikraft", "build", "helloworld_qemu-x86_64")
+	if got, want := StagedPath(recorded), filepath.Join(dist, "helloworld_qemu-x86_64"); got != want {
+		t.Errorf("StagedPath() = %q, want %q", got, want)
+	}
+
+	// Once moved, the recorded path is used.
+	if err := os.MkdirAll(filepath.Dir(recorded), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(recorded, []byte("\x7fELF"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got := StagedPath(recorded); got != recorded {
+		t.Errorf("StagedPath() = %q, want %q", got, recorded)
+	}
+
+	other := filepath.Join(project, "helloworld")
+	if got := StagedPath(other); got != other {
+		t.Errorf("StagedPath() = %q, want %q", got, other)
+	}
+}
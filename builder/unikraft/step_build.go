@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/packer-plugin-sdk/multistep"
 	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
@@ -16,7 +17,7 @@ type StepBuild struct {
 }
 
 // Run should execute the purpose of this step
-func (s *StepBuild) Run(_ context.Context, state multistep.StateBag) multistep.StepAction {
+func (s *StepBuild) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
 	ui := state.Get("ui").(packersdk.Ui)
 	config, ok := state.Get("config").(*Config)
 	if !ok {
@@ -28,18 +29,109 @@ func (s *StepBuild) Run(_ context.Context, state multistep.StateBag) multistep.S
 
 	driver := state.Get("driver").(Driver)
 
-	err := driver.Build(config.Path, config.Architecture, config.Platform, config.Target)
-	if err != nil {
-		err := fmt.Errorf("error encountered building kraft package: %s", err)
+	// The kernels kept from the previous build are linked again.
+	if config.Incremental {
+		if err := removeBuiltKernels(filepath.Join(config.Path, ".unikraft", "build")); err != nil {
+			err := fmt.Errorf("error encountered removing previously built kernels: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+	}
+
+	// Targets skipped by the driver, or which failed to build when continuing
+	// on errors, have no kernel and are left out of the outputs of the build.
+	var builds []TargetConfig
+	var failures []string
+	buildIDs := map[string]string{}
+	var results []TargetResult
+	for _, t := range config.BuildTargets() {
+		if err := ctx.Err(); err != nil {
+			err := fmt.Errorf("build cancelled before %s/%s: %s", t.Platform, t.Architecture, err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+
+		if len(config.BuildTargets()) > 1 {
+			ui.Say(fmt.Sprintf("Building %s/%s", t.Platform, t.Architecture))
+		}
+
+		start := time.Now()
+		err := buildTarget(ui, driver, config, t)
+		// A cancelled build does not continue with the next targets.
+		if err != nil && (!config.ContinueOnError || ctx.Err() != nil) {
+			err := fmt.Errorf("error encountered building kraft package for %s/%s: %s", t.Platform, t.Architecture, err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+
+		// Drivers which cannot tell the phases of the build only report how
+		// long the target took.
+		var reported []TargetResult
+		if d, ok := driver.(BuildReporter); ok {
+			reported = d.BuildResults()
+		}
+		if len(reported) > 0 {
+			results = append(results, reported...)
+		} else {
+			res := TargetResult{
+				Target:       t.Target,
+				Architecture: t.Architecture,
+				Platform:     t.Platform,
+				Status:       TargetStatusSuccess,
+				Duration:     time.Since(start),
+			}
+			if err != nil {
+				res.Status = TargetStatusFailed
+				res.Error = err.Error()
+			}
+			results = append(results, res)
+		}
+
+		if err != nil {
+			ui.Error(fmt.Sprintf("Could not build %s/%s, continuing: %s", t.Platform, t.Architecture, err))
+			failures = append(failures, fmt.Sprintf("%s/%s: %s", t.Platform, t.Architecture, err))
+			continue
+		}
+
+		if allSkipped(reported) {
+			ui.Say(fmt.Sprintf("Skipped %s/%s: %s", t.Platform, t.Architecture, reported[0].Error))
+			continue
+		}
+		builds = append(builds, t)
+
+		if d, ok := driver.(BuildIdentifier); ok && d.BuildID() != "" {
+			ui.Say(fmt.Sprintf("Build ID: %s", d.BuildID()))
+			buildIDs[t.Platform+"/"+t.Architecture] = d.BuildID()
+			if _, ok := state.GetOk("build_id"); !ok {
+				state.Put("build_id", d.BuildID())
+			}
+		}
+	}
+
+	if len(builds) == 0 {
+		err := fmt.Errorf("error encountered building kraft package: every target was skipped")
+		if len(failures) > 0 {
+			err = fmt.Errorf("error encountered building kraft package: no target was built: %s", strings.Join(failures, "; "))
+		}
 		state.Put("error", err)
 		ui.Error(err.Error())
 		return multistep.ActionHalt
 	}
 
+	// The kernel of the first target built is the one recorded as `kernel`.
+	primary := builds[0]
+
 	// Copy all executable files in the `path/build` folder and move them to `path/dist`
 	// Open the folder for reading
 	var executableFiles []string = []string{}
-	filepath.Walk(filepath.Join(config.Path, ".unikraft", "build"), func(path string, info os.FileInfo, err error) error {
+	err := filepath.Walk(filepath.Join(config.Path, ".unikraft", "build"), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
 		// Check if the file is executable and not a symlink or directory
 		if !info.IsDir() && info.Mode()&0111 != 0 && info.Mode()&os.ModeSymlink == 0 {
 			// Check if the file is in the root of the build folder
@@ -50,26 +142,297 @@ func (s *StepBuild) Run(_ context.Context, state multistep.StateBag) multistep.S
 
 		return nil
 	})
+	if err != nil {
+		err := fmt.Errorf("error encountered listing built kernels: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	if len(executableFiles) == 0 {
+		err := fmt.Errorf("error encountered listing built kernels: the build produced no kernel")
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
 
 	// Create the dist folder if it doesn't exist
-	if _, err := os.Stat(filepath.Join(config.Path, ".unikraft", "dist")); os.IsNotExist(err) {
-		os.Mkdir(filepath.Join(config.Path, ".unikraft", "dist"), 0755)
+	if err := os.MkdirAll(filepath.Join(config.Path, ".unikraft", "dist"), 0755); err != nil {
+		err := fmt.Errorf("error encountered saving kraft package: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	// The kernel name and debug output are rendered for every target, with
+	// the name of its kernel in the build.
+	var templateData []OutputTemplateData
+	for _, t := range builds {
+		artifact := TargetArtifact{
+			Platform:     t.Platform,
+			Architecture: t.Architecture,
+			Target:       t.Target,
+			BuildID:      buildIDs[t.Platform+"/"+t.Architecture],
+		}
+
+		name := ""
+		for _, file := range executableFiles {
+			if isKernelFor(file, t.Platform, t.Architecture) {
+				name = filepath.Base(file)
+				artifact.KConfig = kernelDotConfig(config.Path, file)
+				break
+			}
+		}
+
+		templateData = append(templateData, NewOutputTemplateData(artifact, name))
+	}
+
+	targetOutputs, err := renderTargetOutputs(config.ctx, config.KernelName, config.DbgOutput, templateData)
+	if err != nil {
+		err := fmt.Errorf("error encountered saving kraft package: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	names, err := outputNames(executableFiles, targetOutputs...)
+	if err != nil {
+		err := fmt.Errorf("error encountered saving kraft package: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	var dbgOutputs []string
+	for _, o := range targetOutputs {
+		if o.DbgOutput == "" {
+			continue
+		}
+
+		err := copyDebugKernel(executableFiles, o.Platform, o.Architecture, o.DbgOutput)
+		if err != nil {
+			err := fmt.Errorf("error encountered saving debug kernel: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+
+		ui.Say(fmt.Sprintf("Saved debug kernel to %s", o.DbgOutput))
+		if len(dbgOutputs) == 0 {
+			state.Put("kernel_dbg", o.DbgOutput)
+		}
+		dbgOutputs = append(dbgOutputs, o.DbgOutput)
+	}
+	if len(dbgOutputs) > 0 {
+		state.Put("dbg_outputs", dbgOutputs)
 	}
 
 	// Move the files to the dist folder
+	var resultingBinaries []string
+	var xenConfigs []string
+	var debugBundles []string
+	checksums := map[string]string{}
 	for _, file := range executableFiles {
-		ui.Say(fmt.Sprintf("Moving %s to %s", file, filepath.Join(config.Path, ".unikraft", "dist", filepath.Base(file))))
-		err := os.Rename(file, filepath.Join(config.Path, ".unikraft", "dist", filepath.Base(file)))
+		ui.Say(fmt.Sprintf("Moving %s to %s", file, filepath.Join(config.Path, ".unikraft", "dist", names[file])))
+		err := os.Rename(file, filepath.Join(config.Path, ".unikraft", "dist", names[file]))
 		if err != nil {
 			err := fmt.Errorf("error encountered saving kraft package: %s", err)
 			state.Put("error", err)
 			ui.Error(err.Error())
 			return multistep.ActionHalt
 		}
+
+		// The dist folder replaces the build folder during cleanup.
+		binary := filepath.Join(config.Path, ".unikraft", "build", names[file])
+		resultingBinaries = append(resultingBinaries, binary)
+		if isKernelFor(file, primary.Platform, primary.Architecture) {
+			state.Put("kernel", binary)
+		}
+
+		digest, err := fileDigest(filepath.Join(config.Path, ".unikraft", "dist", names[file]))
+		if err != nil {
+			err := fmt.Errorf("error encountered computing checksum of %s: %s", file, err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+		checksums[binary] = digest
+	}
+
+	targets := []map[string]string{}
+	for _, file := range executableFiles {
+		plat, arch, ok := kernelTarget(file)
+		if !ok {
+			continue
+		}
+
+		binary := filepath.Join(config.Path, ".unikraft", "build", names[file])
+		target := map[string]string{
+			"platform":     plat,
+			"architecture": arch,
+			"kernel":       binary,
+			"sha256":       checksums[binary],
+		}
+		for _, t := range builds {
+			if t.Platform == plat && t.Architecture == arch && t.Target != "" {
+				target["target"] = t.Target
+			}
+		}
+		if id, ok := buildIDs[plat+"/"+arch]; ok {
+			target["build_id"] = id
+		}
+		if dbg, ok := names[file+".dbg"]; ok {
+			target["kernel_dbg"] = filepath.Join(config.Path, ".unikraft", "build", dbg)
+		}
+		if config.DebugBundle {
+			dbg, ok := names[file+".dbg"]
+			if !ok {
+				err := fmt.Errorf("error encountered bundling debug info of %s: no debug kernel found for %s/%s", file, plat, arch)
+				state.Put("error", err)
+				ui.Error(err.Error())
+				return multistep.ActionHalt
+			}
+
+			bundle := names[file] + DebugBundleSuffix
+			info, digest, err := DebugBundle{
+				Kernel:       filepath.Join(config.Path, ".unikraft", "dist", dbg),
+				Platform:     plat,
+				Architecture: arch,
+				BuildID:      target["build_id"],
+				SHA256:       target["sha256"],
+			}.Write(filepath.Join(config.Path, ".unikraft", "dist", bundle))
+			if err != nil {
+				err := fmt.Errorf("error encountered bundling debug info of %s: %s", file, err)
+				state.Put("error", err)
+				ui.Error(err.Error())
+				return multistep.ActionHalt
+			}
+			if !info.DWARF {
+				ui.Message(fmt.Sprintf("The debug kernel of %s/%s carries no DWARF info, only its symbols are bundled", plat, arch))
+			}
+
+			target["debug_bundle"] = filepath.Join(config.Path, ".unikraft", "build", bundle)
+			checksums[target["debug_bundle"]] = digest
+			debugBundles = append(debugBundles, target["debug_bundle"])
+		}
+		if dotconfig := kernelDotConfig(config.Path, file); dotconfig != "" {
+			digest, err := fileDigest(dotconfig)
+			if err != nil {
+				err := fmt.Errorf("error encountered computing KConfig digest of %s: %s", file, err)
+				state.Put("error", err)
+				ui.Error(err.Error())
+				return multistep.ActionHalt
+			}
+			target["kconfig_digest"] = digest
+			target["kconfig"] = dotconfig
+		}
+		if plat == "fc" {
+			vmConfig := names[file] + ".json"
+			err := config.Firecracker.VM(binary, config.Cmdline).WriteConfig(filepath.Join(config.Path, ".unikraft", "dist", vmConfig))
+			if err != nil {
+				err := fmt.Errorf("error encountered writing firecracker config of %s: %s", file, err)
+				state.Put("error", err)
+				ui.Error(err.Error())
+				return multistep.ActionHalt
+			}
+			target["firecracker_config"] = filepath.Join(config.Path, ".unikraft", "build", vmConfig)
+		}
+		if plat == "xen" {
+			domainConfig := names[file] + ".cfg"
+			err := config.Xen.Domain(names[file], binary, config.Cmdline).WriteConfig(filepath.Join(config.Path, ".unikraft", "dist", domainConfig))
+			if err != nil {
+				err := fmt.Errorf("error encountered writing xl config of %s: %s", file, err)
+				state.Put("error", err)
+				ui.Error(err.Error())
+				return multistep.ActionHalt
+			}
+			target["xen_config"] = filepath.Join(config.Path, ".unikraft", "build", domainConfig)
+			xenConfigs = append(xenConfigs, target["xen_config"])
+		}
+
+		targets = append(targets, target)
+	}
+
+	var outputs []string
+	if config.OutputDir != "" {
+		owners := map[string]string{}
+		for _, target := range targets {
+			name := filepath.Base(target["kernel"])
+			data := NewOutputTemplateData(TargetArtifact{
+				Platform:     target["platform"],
+				Architecture: target["architecture"],
+				Target:       target["target"],
+				BuildID:      target["build_id"],
+			}, name)
+
+			output, err := renderOutputPath(config.ctx, config.OutputDir, config.ArtifactName, data)
+			if err != nil {
+				err := fmt.Errorf("error encountered copying kernel to output_dir: %s", err)
+				state.Put("error", err)
+				ui.Error(err.Error())
+				return multistep.ActionHalt
+			}
+
+			if owner, ok := owners[output]; ok {
+				err := fmt.Errorf("error encountered copying kernel to output_dir: the kernels of %s and %s are both copied to %s, name them apart with {{ .Target }}", owner, data.Target, output)
+				state.Put("error", err)
+				ui.Error(err.Error())
+				return multistep.ActionHalt
+			}
+
+			if err := copyOutput(filepath.Join(config.Path, ".unikraft", "dist", name), output); err != nil {
+				err := fmt.Errorf("error encountered copying kernel to output_dir: %s", err)
+				state.Put("error", err)
+				ui.Error(err.Error())
+				return multistep.ActionHalt
+			}
+
+			ui.Say(fmt.Sprintf("Copied %s to %s", name, output))
+			owners[output] = data.Target
+			target["output"] = output
+			outputs = append(outputs, output)
+		}
 	}
 
-	s.resultingBinariesPath = executableFiles
+	s.resultingBinariesPath = resultingBinaries
 	state.Put("binaries", s.resultingBinariesPath)
+	state.Put("targets", targets)
+	if len(outputs) > 0 {
+		state.Put("outputs", outputs)
+	}
+	if len(xenConfigs) > 0 {
+		state.Put("xen_configs", xenConfigs)
+	}
+	if len(debugBundles) > 0 {
+		state.Put("debug_bundles", debugBundles)
+	}
+	state.Put("checksums", checksums)
+
+	report := &BuildReport{Targets: attachKernels(results, targets)}
+	if id, ok := state.GetOk("build_id"); ok {
+		report.BuildID = id.(string)
+	}
+	if !config.NoBuildEnvironment {
+		report.Environment = collectBuildEnvironment(hostProbe)
+	}
+	if d, ok := state.GetOk("pull_duration"); ok {
+		report.Phases = map[string]time.Duration{"pull": d.(time.Duration)}
+	}
+	if err := report.WriteFile(filepath.Join(config.Path, ".unikraft", "dist", BuildReportFile)); err != nil {
+		err := fmt.Errorf("error encountered writing build report: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	state.Put("build_report", filepath.Join(config.Path, ".unikraft", "build", BuildReportFile))
+
+	generated := map[string]interface{}{"binaries": s.resultingBinariesPath}
+	for _, key := range []string{"build_id", "kernel", "kernel_dbg"} {
+		if v, ok := state.GetOk(key); ok {
+			generated[key] = v
+		}
+	}
+	putGeneratedData(state, generated)
 
 	return multistep.ActionContinue
 }
@@ -86,6 +449,17 @@ func (s *StepBuild) Cleanup(state multistep.StateBag) {
 		return
 	}
 
+	// Keep the objects of incremental builds, along with their fingerprints.
+	if config.Incremental {
+		err := restoreBuildDir(filepath.Join(config.Path, ".unikraft", "dist"), filepath.Join(config.Path, ".unikraft", "build"))
+		if err != nil {
+			err := fmt.Errorf("error encountered cleaning kraft package: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+		}
+		return
+	}
+
 	// Remove the build folder
 	err := os.RemoveAll(filepath.Join(config.Path, ".unikraft", "build"))
 	if err != nil {
@@ -102,3 +476,28 @@ func (s *StepBuild) Cleanup(state multistep.StateBag) {
 		ui.Error(err.Error())
 	}
 }
+
+// buildTarget builds a target with its effective KConfig options merged into
+// its configuration, reporting them first.
+func buildTarget(ui packersdk.Ui, driver Driver, config *Config, t TargetConfig) error {
+	options, err := config.KConfigOptions(t)
+	if err != nil {
+		return err
+	}
+
+	if len(options) == 0 {
+		return driver.Build(config.Path, t.Architecture, t.Platform, t.Target)
+	}
+
+	d, ok := driver.(KConfigBuilder)
+	if !ok {
+		return fmt.Errorf("the driver cannot merge kconfig options")
+	}
+
+	ui.Say(fmt.Sprintf("Merging KConfig options into %s/%s:", t.Platform, t.Architecture))
+	for _, line := range KConfigReport(options) {
+		ui.Message(line)
+	}
+
+	return d.BuildWithKConfig(config.Path, t.Architecture, t.Platform, t.Target, KConfigValues(options))
+}
@@ -0,0 +1,71 @@
+package unikraft
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+type StepBuildRootfs struct{}
+
+// Run constructs the initramfs of the build from rootfs_dir or
+// rootfs_dockerfile next to the built kernels.  This step is skipped if
+// neither is specified.
+func (s *StepBuildRootfs) Run(_ context.Context, state multistep.StateBag) multistep.StepAction {
+	ui := state.Get("ui").(packersdk.Ui)
+	config, ok := state.Get("config").(*Config)
+	if !ok {
+		err := fmt.Errorf("error encountered obtaining kraft config")
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	source := config.RootfsSource()
+	if source == "" {
+		return multistep.ActionContinue
+	}
+
+	driver, ok := state.Get("driver").(RootfsBuilder)
+	if !ok {
+		err := fmt.Errorf("error encountered building rootfs: driver cannot build an initramfs")
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	// The dist folder replaces the build folder during the cleanup of the
+	// build step.
+	output := filepath.Join(config.Path, ".unikraft", "dist", rootfsOutput)
+
+	ui.Say(fmt.Sprintf("Building initramfs from %s", source))
+	if err := driver.BuildRootfs(source, output, config.Path, config.RootfsBuildKitHost); err != nil {
+		err := fmt.Errorf("error encountered building rootfs: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	initramfs := filepath.Join(config.Path, ".unikraft", "build", rootfsOutput)
+	state.Put("initramfs", []string{initramfs})
+	putGeneratedData(state, map[string]interface{}{"initramfs": []string{initramfs}})
+
+	if checksums, ok := state.Get("checksums").(map[string]string); ok {
+		digest, err := fileDigest(output)
+		if err != nil {
+			err := fmt.Errorf("error encountered computing checksum of %s: %s", output, err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+		checksums[initramfs] = digest
+	}
+
+	return multistep.ActionContinue
+}
+
+// Cleanup does nothing, the initramfs is moved with the built kernels.
+func (s *StepBuildRootfs) Cleanup(state multistep.StateBag) {}
@@ -0,0 +1,172 @@
+package unikraft
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	"github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+// failingDriver builds the kernel of every target into the build folder of
+// its project, except for the targets of the failed architecture.
+type failingDriver struct {
+	MockDriver
+	failed string
+}
+
+func (d *failingDriver) Build(path, architecture, platform, target string) error {
+	if architecture == d.failed {
+		return fmt.Errorf("could not compile %s", architecture)
+	}
+
+	dir := filepath.Join(path, ".unikraft", "build")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, fmt.Sprintf("app_%s-%s", platform, architecture)), []byte("kernel"), 0o755)
+}
+
+// skippingDriver reports the targets of the skipped architecture as skipped,
+// without building them.
+type skippingDriver struct {
+	failingDriver
+	skipped string
+	results []TargetResult
+}
+
+func (d *skippingDriver) Build(path, architecture, platform, target string) error {
+	d.results = []TargetResult{{Architecture: architecture, Platform: platform, Status: TargetStatusSuccess}}
+	if architecture == d.skipped {
+		d.results[0].Status = TargetStatusSkipped
+		d.results[0].Error = "unchanged since origin/main"
+		return nil
+	}
+
+	return d.failingDriver.Build(path, architecture, platform, target)
+}
+
+func (d *skippingDriver) BuildResults() []TargetResult {
+	return d.results
+}
+
+func runStepBuild(t *testing.T, config *Config, driver Driver) (multistep.StateBag, multistep.StepAction) {
+	t.Helper()
+
+	state := new(multistep.BasicStateBag)
+	state.Put("ui", &packer.BasicUi{Reader: new(bytes.Buffer), Writer: new(bytes.Buffer)})
+	state.Put("config", config)
+	state.Put("driver", driver)
+
+	return state, (&StepBuild{}).Run(context.Background(), state)
+}
+
+func TestStepBuildContinueOnError(t *testing.T) {
+	config := &Config{
+		Path: t.TempDir(),
+		Targets: []TargetConfig{
+			{Architecture: "arm64", Platform: "qemu"},
+			{Architecture: "x86_64", Platform: "qemu"},
+		},
+		ContinueOnError:    true,
+		NoBuildEnvironment: true,
+	}
+
+	state, action := runStepBuild(t, config, &failingDriver{failed: "arm64"})
+	if action != multistep.ActionContinue {
+		t.Fatalf("step halted: %v", state.Get("error"))
+	}
+
+	targets := state.Get("targets").([]map[string]string)
+	if len(targets) != 1 || targets[0]["architecture"] != "x86_64" {
+		t.Errorf("targets = %v, want the x86_64 target only", targets)
+	}
+
+	report, err := ReadBuildReport(filepath.Join(config.Path, ".unikraft", "dist", BuildReportFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	statuses := map[string]string{}
+	for _, res := range report.Targets {
+		statuses[res.Architecture] = res.Status
+	}
+	if statuses["arm64"] != TargetStatusFailed || statuses["x86_64"] != TargetStatusSuccess {
+		t.Errorf("statuses = %v", statuses)
+	}
+}
+
+func TestStepBuildNothingBuilt(t *testing.T) {
+	config := &Config{
+		Path:               t.TempDir(),
+		Architecture:       "arm64",
+		Platform:           "qemu",
+		ContinueOnError:    true,
+		NoBuildEnvironment: true,
+	}
+
+	state, action := runStepBuild(t, config, &failingDriver{failed: "arm64"})
+	if action != multistep.ActionHalt {
+		t.Fatal("expected the step to halt")
+	}
+	if err, ok := state.Get("error").(error); !ok || !strings.Contains(err.Error(), "no target was built: qemu/arm64: could not compile arm64") {
+		t.Errorf("error = %v", state.Get("error"))
+	}
+}
+
+func TestStepBuildHaltsOnError(t *testing.T) {
+	config := &Config{
+		Path: t.TempDir(),
+		Targets: []TargetConfig{
+			{Architecture: "arm64", Platform: "qemu"},
+			{Architecture: "x86_64", Platform: "qemu"},
+		},
+		NoBuildEnvironment: true,
+	}
+
+	state, action := runStepBuild(t, config, &failingDriver{failed: "arm64"})
+	if action != multistep.ActionHalt {
+		t.Fatal("expected the step to halt without continue_on_error")
+	}
+	if err, ok := state.Get("error").(error); !ok || !strings.Contains(err.Error(), "qemu/arm64") {
+		t.Errorf("error = %v", state.Get("error"))
+	}
+}
+
+func TestStepBuildSkipped(t *testing.T) {
+	config := &Config{
+		Path: t.TempDir(),
+		Targets: []TargetConfig{
+			{Architecture: "arm64", Platform: "qemu"},
+			{Architecture: "x86_64", Platform: "qemu"},
+		},
+		NoBuildEnvironment: true,
+	}
+
+	state, action := runStepBuild(t, config, &skippingDriver{skipped: "arm64"})
+	if action != multistep.ActionContinue {
+		t.Fatalf("step halted: %v", state.Get("error"))
+	}
+
+	targets := state.Get("targets").([]map[string]string)
+	if len(targets) != 1 || targets[0]["architecture"] != "x86_64" {
+		t.Errorf("targets = %v, want the x86_64 target only", targets)
+	}
+	if kernel := state.Get("kernel").(string); !isKernelFor(kernel, "qemu", "x86_64") {
+		t.Errorf("kernel = %s, want the kernel of the first target built", kernel)
+	}
+
+	config.Targets = config.Targets[:1]
+	state, action = runStepBuild(t, config, &skippingDriver{skipped: "arm64"})
+	if action != multistep.ActionHalt {
+		t.Fatal("expected the step to halt when every target is skipped")
+	}
+	if err, ok := state.Get("error").(error); !ok || !strings.Contains(err.Error(), "every target was skipped") {
+		t.Errorf("error = %v", state.Get("error"))
+	}
+}
@@ -0,0 +1,97 @@
+package unikraft
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+type StepGitSource struct {
+	dir string
+}
+
+// Run clones the project from source_repository into a temporary directory,
+// which becomes the build path of the following steps.  This step is skipped
+// if no repository is specified.
+func (s *StepGitSource) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	ui := state.Get("ui").(packersdk.Ui)
+	config, ok := state.Get("config").(*Config)
+	if !ok {
+		err := fmt.Errorf("error encountered obtaining kraft config")
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	source := config.GitSource()
+	if source == nil {
+		return multistep.ActionContinue
+	}
+
+	dir, err := os.MkdirTemp("", "packer-unikraft-source-")
+	if err != nil {
+		err := fmt.Errorf("error encountered cloning source repository: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	s.dir = dir
+
+	ui.Say(fmt.Sprintf("Cloning %s", source.Repository))
+	if err := source.Clone(ctx, dir); err != nil {
+		err := fmt.Errorf("error encountered cloning source repository: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	project := source.ProjectPath(dir)
+	kraftfile := "Kraftfile"
+	found := false
+	if config.Kraftfile != "" {
+		kraftfile = config.Kraftfile
+		_, err := os.Stat(findKraftfile(project, config.Kraftfile))
+		found = err == nil
+	} else {
+		for _, name := range kraftfileNames {
+			if _, err := os.Stat(filepath.Join(project, name)); err == nil {
+				found = true
+				break
+			}
+		}
+	}
+	// A project described by the template needs no Kraftfile.
+	if !found && config.Project == nil {
+		err := fmt.Errorf("error encountered cloning source repository: no %s in %q of %s", kraftfile, source.Path, source.Repository)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	config.Path = project
+
+	return multistep.ActionContinue
+}
+
+// Cleanup removes the cloned repository if the build did not succeed.  The
+// kernels of a successful build are left in it for the artifact.
+func (s *StepGitSource) Cleanup(state multistep.StateBag) {
+	if s.dir == "" {
+		return
+	}
+
+	_, cancelled := state.GetOk(multistep.StateCancelled)
+	_, halted := state.GetOk(multistep.StateHalted)
+	if !cancelled && !halted {
+		return
+	}
+
+	if err := os.RemoveAll(s.dir); err != nil {
+		ui := state.Get("ui").(packersdk.Ui)
+		ui.Error(fmt.Sprintf("error encountered removing cloned source repository: %s", err))
+	}
+}
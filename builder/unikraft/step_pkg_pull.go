@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/packer-plugin-sdk/multistep"
 	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
@@ -25,19 +26,22 @@ func (s *StepPkgPull) Run(_ context.Context, state multistep.StateBag) multistep
 		return multistep.ActionHalt
 	}
 
-	if config.PullSource == "" || config.Workdir == "" {
+	sources := config.PullSourceList()
+	if len(sources) == 0 || config.Workdir == "" {
 		return multistep.ActionContinue
 	}
 
 	driver := state.Get("driver").(Driver)
 
-	err := driver.Pull(config.PullSource, config.Workdir)
+	start := time.Now()
+	err := driver.Pull(sources, config.Workdir, config.PullOptions())
 	if err != nil {
 		err := fmt.Errorf("error encountered pulling kraft package: %s", err)
 		state.Put("error", err)
 		ui.Error(err.Error())
 		return multistep.ActionHalt
 	}
+	state.Put("pull_duration", time.Since(start))
 
 	return multistep.ActionContinue
 }
@@ -58,28 +62,30 @@ func (s *StepPkgPull) Cleanup(state multistep.StateBag) {
 		return
 	}
 
-	if config.PullSource == "" || config.Workdir == "" {
+	if config.Workdir == "" {
 		return
 	}
 
-	baseDir := strings.TrimPrefix(config.PullSource, "app-")
-	unikraftDir := filepath.Join(config.Workdir, ".unikraft", "apps", baseDir, ".unikraft", "unikraft")
-	libsDir := filepath.Join(filepath.Dir(unikraftDir), "libs")
-
-	err := os.RemoveAll(unikraftDir)
-	if err != nil {
-		err := fmt.Errorf("error encountered removing directory: %s", err)
-		state.Put("error", err)
-		ui.Error(err.Error())
-		return
-	}
-
-	err = os.RemoveAll(libsDir)
-	if err != nil {
-		err := fmt.Errorf("error encountered removing directory: %s", err)
-		state.Put("error", err)
-		ui.Error(err.Error())
-		return
+	for _, source := range config.PullSourceList() {
+		baseDir := strings.TrimPrefix(source, "app-")
+		unikraftDir := filepath.Join(config.Workdir, ".unikraft", "apps", baseDir, ".unikraft", "unikraft")
+		libsDir := filepath.Join(filepath.Dir(unikraftDir), "libs")
+
+		err := os.RemoveAll(unikraftDir)
+		if err != nil {
+			err := fmt.Errorf("error encountered removing directory: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return
+		}
+
+		err = os.RemoveAll(libsDir)
+		if err != nil {
+			err := fmt.Errorf("error encountered removing directory: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return
+		}
 	}
 
 	// // Delete everything in the builddir except the resulting images in the `build` directory.
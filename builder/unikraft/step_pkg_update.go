@@ -11,11 +11,11 @@ import (
 type StepPkgUpdate struct {
 }
 
-// Run executes the step of updating the sources for a package by calling the `kraft pkg update` command.
-// This step will not do anything if no source is specified.
+// Run executes the step of updating the package index by calling the `kraft pkg update` command.
+// This step is skipped unless update_index is set.
 func (s *StepPkgUpdate) Run(_ context.Context, state multistep.StateBag) multistep.StepAction {
 	ui := state.Get("ui").(packersdk.Ui)
-	_, ok := state.Get("config").(*Config)
+	config, ok := state.Get("config").(*Config)
 	if !ok {
 		err := fmt.Errorf("error encountered obtaining kraft config")
 		state.Put("error", err)
@@ -23,9 +23,14 @@ func (s *StepPkgUpdate) Run(_ context.Context, state multistep.StateBag) multist
 		return multistep.ActionHalt
 	}
 
+	if !config.UpdateIndex {
+		return multistep.ActionContinue
+	}
+
 	driver := state.Get("driver").(Driver)
 
-	err := driver.Update()
+	ui.Say(fmt.Sprintf("Updating the %s package index", config.IndexManager()))
+	err := driver.Update(config.IndexManager())
 	if err != nil {
 		err := fmt.Errorf("error encountered updating kraft references: %s", err)
 		state.Put("error", err)
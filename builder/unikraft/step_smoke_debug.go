@@ -0,0 +1,95 @@
+package unikraft
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/mitchellh/mapstructure"
+)
+
+type StepSmokeDebug struct{}
+
+// Run boots the kernel of every qemu target built halted in qemu, attaches
+// gdb to it with the symbols of its debug kernel, and fails the build unless
+// a breakpoint on its entry symbol is hit.
+func (s *StepSmokeDebug) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	ui := state.Get("ui").(packersdk.Ui)
+	config, ok := state.Get("config").(*Config)
+	if !ok {
+		err := fmt.Errorf("error encountered obtaining kraft config")
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	if config.GDBSmokeTest == nil {
+		return multistep.ActionContinue
+	}
+
+	var targets []TargetArtifact
+	if err := mapstructure.Decode(state.Get("targets"), &targets); err != nil {
+		err := fmt.Errorf("error encountered debugging kernels: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	tested := 0
+	for _, t := range targets {
+		if t.Platform != "qemu" {
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			err := fmt.Errorf("gdb smoke test cancelled before %s/%s: %s", t.Platform, t.Architecture, err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+
+		if t.KernelDbg == "" {
+			err := fmt.Errorf("error encountered debugging %s/%s: no debug kernel was built", t.Platform, t.Architecture)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+
+		// The built kernels are only moved back to the build folder during
+		// the cleanup of the build step.
+		kernel := filepath.Join(config.Path, ".unikraft", "dist", filepath.Base(t.Kernel))
+		dbg := filepath.Join(config.Path, ".unikraft", "dist", filepath.Base(t.KernelDbg))
+
+		ui.Say(fmt.Sprintf("Debugging %s in qemu with gdb", filepath.Base(t.Kernel)))
+		output, err := config.GDBSmokeTest.Test(kernel, dbg, t.Architecture).Run(ctx)
+		if err != nil {
+			for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+				if line != "" {
+					ui.Message(line)
+				}
+			}
+
+			err := fmt.Errorf("error encountered debugging %s/%s: %s", t.Platform, t.Architecture, err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+
+		tested++
+	}
+
+	if tested == 0 {
+		err := fmt.Errorf("error encountered debugging kernels: no qemu target was built")
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	return multistep.ActionContinue
+}
+
+// Cleanup does nothing, qemu and gdb exit once the breakpoint is hit.
+func (s *StepSmokeDebug) Cleanup(state multistep.StateBag) {}
@@ -0,0 +1,79 @@
+package unikraft
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/mitchellh/mapstructure"
+)
+
+type StepSmokeTestFirecracker struct{}
+
+// Run boots the kernel of every fc target built in a firecracker microVM and
+// fails the build unless each of them passes the smoke test.
+func (s *StepSmokeTestFirecracker) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	ui := state.Get("ui").(packersdk.Ui)
+	config, ok := state.Get("config").(*Config)
+	if !ok {
+		err := fmt.Errorf("error encountered obtaining kraft config")
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	if config.Firecracker == nil || config.Firecracker.SmokeTest == nil {
+		return multistep.ActionContinue
+	}
+
+	var targets []TargetArtifact
+	if err := mapstructure.Decode(state.Get("targets"), &targets); err != nil {
+		err := fmt.Errorf("error encountered smoke testing firecracker: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	tested := 0
+	for _, t := range targets {
+		if t.Platform != "fc" {
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			err := fmt.Errorf("smoke test cancelled before %s/%s: %s", t.Platform, t.Architecture, err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+
+		// The built kernels are only moved back to the build folder during
+		// the cleanup of the build step.
+		kernel := filepath.Join(config.Path, ".unikraft", "dist", filepath.Base(t.Kernel))
+
+		ui.Say(fmt.Sprintf("Smoke testing %s in firecracker", filepath.Base(t.Kernel)))
+		err := smokeTestFirecracker(ctx, config.Firecracker.Binary, config.Firecracker.VM(kernel, config.Cmdline), config.Firecracker.SmokeTest.BootCheck())
+		if err != nil {
+			err := fmt.Errorf("error encountered smoke testing %s/%s: %s", t.Platform, t.Architecture, err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+
+		tested++
+	}
+
+	if tested == 0 {
+		err := fmt.Errorf("error encountered smoke testing firecracker: no fc target was built")
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	return multistep.ActionContinue
+}
+
+// Cleanup does nothing, the microVMs exit once tested.
+func (s *StepSmokeTestFirecracker) Cleanup(state multistep.StateBag) {}
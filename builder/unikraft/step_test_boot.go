@@ -0,0 +1,86 @@
+package unikraft
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/mitchellh/mapstructure"
+)
+
+type StepTestBoot struct{}
+
+// Run boots the kernel of every qemu target built and fails the build unless
+// each of them passes the boot test.
+func (s *StepTestBoot) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	ui := state.Get("ui").(packersdk.Ui)
+	config, ok := state.Get("config").(*Config)
+	if !ok {
+		err := fmt.Errorf("error encountered obtaining kraft config")
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	if config.TestBoot == nil {
+		return multistep.ActionContinue
+	}
+
+	driver, ok := state.Get("driver").(BootTester)
+	if !ok {
+		err := fmt.Errorf("error encountered testing boot: driver cannot boot kernels")
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	var targets []TargetArtifact
+	if err := mapstructure.Decode(state.Get("targets"), &targets); err != nil {
+		err := fmt.Errorf("error encountered testing boot: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	tested := 0
+	for _, t := range targets {
+		if t.Platform != "qemu" {
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			err := fmt.Errorf("boot test cancelled before %s/%s: %s", t.Platform, t.Architecture, err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+
+		// The built kernels are only moved back to the build folder during
+		// the cleanup of the build step.
+		kernel := filepath.Join(config.Path, ".unikraft", "dist", filepath.Base(t.Kernel))
+
+		ui.Say(fmt.Sprintf("Testing boot of %s", filepath.Base(t.Kernel)))
+		if err := driver.TestBoot(kernel, t.Architecture, config.TestBoot.BootCheck()); err != nil {
+			err := fmt.Errorf("error encountered testing boot of %s/%s: %s", t.Platform, t.Architecture, err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+
+		tested++
+	}
+
+	if tested == 0 {
+		err := fmt.Errorf("error encountered testing boot: no qemu target was built")
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	return multistep.ActionContinue
+}
+
+// Cleanup does nothing, the booted machines are removed once tested.
+func (s *StepTestBoot) Cleanup(state multistep.StateBag) {}
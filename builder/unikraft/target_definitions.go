@@ -0,0 +1,97 @@
+package unikraft
+
+import (
+	"fmt"
+	"strings"
+)
+
+// knownArchitectures are the architectures a target definition may use.
+var knownArchitectures = []string{"arm", "arm64", "x86_64"}
+
+func isKnownArchitecture(name string) bool {
+	for _, known := range knownArchitectures {
+		if name == known {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TargetDefinition describes a target supplied from outside the Kraftfile.
+type TargetDefinition struct {
+	Name         string            `json:"name" yaml:"name"`
+	Architecture string            `json:"architecture" yaml:"architecture"`
+	Platform     string            `json:"platform" yaml:"platform"`
+	Config       map[string]string `json:"config,omitempty" yaml:"config,omitempty"`
+}
+
+// TargetDefinitions is the document holding external target definitions.
+type TargetDefinitions struct {
+	Targets []TargetDefinition `json:"targets" yaml:"targets"`
+}
+
+// Spec returns the plain description of the defined target.
+func (d TargetDefinition) Spec() TargetSpec {
+	return TargetSpec{
+		Name:         d.Name,
+		Architecture: d.Architecture,
+		Platform:     d.Platform,
+	}
+}
+
+// validateTargetDefinitions checks that every definition is complete, names
+// a known architecture and does not conflict with another definition or with
+// an existing target.
+func validateTargetDefinitions(defs []TargetDefinition, existing []TargetSpec) error {
+	names := map[string]bool{}
+	for _, t := range existing {
+		names[t.Name] = true
+	}
+
+	var errs []string
+	for i, d := range defs {
+		switch {
+		case d.Name == "":
+			errs = append(errs, fmt.Sprintf("target %d: name is required", i))
+			continue
+		case d.Architecture == "":
+			errs = append(errs, fmt.Sprintf("target %s: architecture is required", d.Name))
+		case !isKnownArchitecture(d.Architecture):
+			errs = append(errs, fmt.Sprintf("target %s: unknown architecture %s, expected one of %s",
+				d.Name,
+				d.Architecture,
+				strings.Join(knownArchitectures, ", "),
+			))
+		}
+
+		if d.Platform == "" {
+			errs = append(errs, fmt.Sprintf("target %s: platform is required", d.Name))
+		}
+
+		if names[d.Name] {
+			errs = append(errs, fmt.Sprintf("target %s: conflicts with an existing target", d.Name))
+		}
+		names[d.Name] = true
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid target definitions: %s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// mergeTargetSpecs appends the defined targets to the existing ones.
+func mergeTargetSpecs(existing []TargetSpec, defs []TargetDefinition) ([]TargetSpec, error) {
+	if err := validateTargetDefinitions(defs, existing); err != nil {
+		return nil, err
+	}
+
+	merged := append([]TargetSpec{}, existing...)
+	for _, d := range defs {
+		merged = append(merged, d.Spec())
+	}
+
+	return merged, nil
+}
@@ -0,0 +1,84 @@
+package unikraft
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMergeTargetDefinitions(t *testing.T) {
+	existing := []TargetSpec{
+		{Name: "nginx-qemu-x86_64", Architecture: "x86_64", Platform: "qemu"},
+	}
+
+	defs := []TargetDefinition{
+		{Name: "nginx-fc-arm64", Architecture: "arm64", Platform: "fc", Config: map[string]string{"CONFIG_LIBUKDEBUG": "y"}},
+		{Name: "nginx-xen-x86_64", Architecture: "x86_64", Platform: "xen"},
+	}
+
+	merged, err := mergeTargetSpecs(existing, defs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(merged) != 3 {
+		t.Fatalf("expected 3 targets, got %d", len(merged))
+	}
+
+	selected := filterTargetSpecs(merged, TargetFilter{Target: "nginx-fc-arm64"})
+	var names []string
+	for _, d := range selected {
+		if d.Selected {
+			names = append(names, d.Target.Name)
+		}
+	}
+	if len(names) != 1 || names[0] != "nginx-fc-arm64" {
+		t.Errorf("expected the external target to be selected, got %v", names)
+	}
+}
+
+func TestValidateTargetDefinitions(t *testing.T) {
+	existing := []TargetSpec{{Name: "nginx-qemu-x86_64", Architecture: "x86_64", Platform: "qemu"}}
+
+	tests := []struct {
+		name string
+		defs []TargetDefinition
+		want string
+	}{
+		{
+			name: "conflict with existing",
+			defs: []TargetDefinition{{Name: "nginx-qemu-x86_64", Architecture: "x86_64", Platform: "qemu"}},
+			want: "conflicts with an existing target",
+		},
+		{
+			name: "duplicate definitions",
+			defs: []TargetDefinition{
+				{Name: "a", Architecture: "arm64", Platform: "qemu"},
+				{Name: "a", Architecture: "arm64", Platform: "fc"},
+			},
+			want: "target a: conflicts",
+		},
+		{
+			name: "unknown architecture",
+			defs: []TargetDefinition{{Name: "a", Architecture: "riscv64", Platform: "qemu"}},
+			want: "unknown architecture riscv64",
+		},
+		{
+			name: "missing platform",
+			defs: []TargetDefinition{{Name: "a", Architecture: "arm64"}},
+			want: "platform is required",
+		},
+		{
+			name: "missing name",
+			defs: []TargetDefinition{{Architecture: "arm64", Platform: "qemu"}},
+			want: "target 0: name is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTargetDefinitions(tt.defs, existing)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
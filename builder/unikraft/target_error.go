@@ -0,0 +1,59 @@
+package unikraft
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// TargetError reports the failure of a target, and the phase it failed in
+// when known.
+type TargetError struct {
+	Target string
+	// Phase is the phase of the target which failed: `configure`,
+	// `prepare` or `build`.  It is empty when the target failed outside of
+	// its phases.
+	Phase string
+	Err   error
+}
+
+func (e *TargetError) Error() string {
+	if e.Phase == "" {
+		return fmt.Sprintf("target %s: %v", e.Target, e.Err)
+	}
+
+	return fmt.Sprintf("target %s: %s failed: %v", e.Target, e.Phase, e.Err)
+}
+
+func (e *TargetError) Unwrap() error {
+	return e.Err
+}
+
+// targetError attributes err to target, unless it already is.
+func targetError(target string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var terr *TargetError
+	if errors.As(err, &terr) && terr.Target == target {
+		return err
+	}
+
+	return &TargetError{Target: target, Err: err}
+}
+
+// checkKernelBuilt fails when the build of a target reported success without
+// producing its kernel.
+func checkKernelBuilt(target, kernel string) error {
+	info, err := os.Stat(kernel)
+	if err != nil {
+		return &TargetError{Target: target, Phase: "build", Err: fmt.Errorf("no kernel was produced at %s", kernel)}
+	}
+
+	if info.Size() == 0 {
+		return &TargetError{Target: target, Phase: "build", Err: fmt.Errorf("the kernel produced at %s is empty", kernel)}
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package unikraft
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTargetError(t *testing.T) {
+	cause := errors.New("make: *** [build] Error 2")
+
+	err := &TargetError{Target: "helloworld-qemu-x86_64", Phase: "build", Err: cause}
+	if got, want := err.Error(), "target helloworld-qemu-x86_64: build failed: make: *** [build] Error 2"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if !errors.Is(err, cause) {
+		t.Error("expected the cause to be unwrapped")
+	}
+}
+
+func TestTargetErrorAttribution(t *testing.T) {
+	if targetError("helloworld-qemu-x86_64", nil) != nil {
+		t.Error("expected no error")
+	}
+
+	phase := &TargetError{Target: "helloworld-qemu-x86_64", Phase: "configure", Err: errors.New("invalid option")}
+	if got := targetError("helloworld-qemu-x86_64", phase); got != phase {
+		t.Errorf("expected the phase error to be kept, got %v", got)
+	}
+
+	err := targetError("helloworld-qemu-x86_64", errors.New("digest mismatch"))
+	if got, want := err.Error(), "target helloworld-qemu-x86_64: digest mismatch"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestCheckKernelBuilt(t *testing.T) {
+	dir := t.TempDir()
+
+	kernel := filepath.Join(dir, "helloworld_qemu-x86_64")
+	if err := os.WriteFile(kernel, []byte("\x7fELF"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkKernelBuilt("helloworld-qemu-x86_64", kernel); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	empty := filepath.Join(dir, "helloworld_fc-x86_64")
+	if err := os.WriteFile(empty, nil, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkKernelBuilt("helloworld-fc-x86_64", empty); err == nil || !strings.Contains(err.Error(), "is empty") {
+		t.Errorf("expected an empty kernel error, got %v", err)
+	}
+
+	missing := filepath.Join(dir, "helloworld_xen-x86_64")
+	if err := checkKernelBuilt("helloworld-xen-x86_64", missing); err == nil || !strings.Contains(err.Error(), "no kernel was produced") {
+		t.Errorf("expected a missing kernel error, got %v", err)
+	}
+}
@@ -0,0 +1,186 @@
+package unikraft
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Conditions under which FilterTargets selects a target.
+const (
+	TargetConditionNoFilter                = "no-filter"
+	TargetConditionName                    = "name"
+	TargetConditionArchitecture            = "architecture"
+	TargetConditionPlatform                = "platform"
+	TargetConditionArchitectureAndPlatform = "architecture-and-platform"
+)
+
+// TargetSpec is the plain description of a target considered for selection.
+type TargetSpec struct {
+	Name         string
+	Architecture string
+	Platform     string
+	Format       string
+}
+
+// TargetFilter selects targets by name, architecture or platform.  The name
+// takes precedence; otherwise the architecture and platform are matched
+// when given.  The architecture may be a comma-separated list, in which case
+// targets of any of the listed architectures are selected.
+type TargetFilter struct {
+	Architecture string
+	Platform     string
+	Target       string
+
+	// PlatformFallback lists the platforms tried in order, after Platform,
+	// until one has a matching target.
+	PlatformFallback []string
+
+	// Format, when set, additionally requires the native package format of
+	// the target to match.
+	Format string
+}
+
+// TargetDecision records whether a target was selected by a TargetFilter and
+// which condition selected it, or why it was rejected.
+type TargetDecision struct {
+	Target    TargetSpec
+	Selected  bool
+	Condition string
+	Reason    string
+}
+
+func (d TargetDecision) String() string {
+	if d.Selected {
+		return fmt.Sprintf("%s: selected (%s)", d.Target.Name, d.Condition)
+	}
+
+	return fmt.Sprintf("%s: rejected (%s)", d.Target.Name, d.Reason)
+}
+
+// decide evaluates the filter against a single target.
+func (f TargetFilter) decide(t TargetSpec) TargetDecision {
+	d := TargetDecision{Target: t}
+
+	switch {
+	case len(f.Target) == 0 && len(f.Architecture) == 0 && len(f.Platform) == 0:
+		d.Selected, d.Condition = true, TargetConditionNoFilter
+
+	case len(f.Target) > 0:
+		if t.Name == f.Target {
+			d.Selected, d.Condition = true, TargetConditionName
+		} else {
+			d.Reason = fmt.Sprintf("name %s does not match %s", t.Name, f.Target)
+		}
+
+	case len(f.Architecture) > 0 && len(f.Platform) == 0:
+		if f.matchesArchitecture(t.Architecture) {
+			d.Selected, d.Condition = true, TargetConditionArchitecture
+		} else {
+			d.Reason = fmt.Sprintf("architecture %s does not match %s", t.Architecture, f.Architecture)
+		}
+
+	case len(f.Platform) > 0 && len(f.Architecture) == 0:
+		if t.Platform == f.Platform {
+			d.Selected, d.Condition = true, TargetConditionPlatform
+		} else {
+			d.Reason = fmt.Sprintf("platform %s does not match %s", t.Platform, f.Platform)
+		}
+
+	default:
+		switch {
+		case !f.matchesArchitecture(t.Architecture):
+			d.Reason = fmt.Sprintf("architecture %s does not match %s", t.Architecture, f.Architecture)
+		case t.Platform != f.Platform:
+			d.Reason = fmt.Sprintf("platform %s does not match %s", t.Platform, f.Platform)
+		default:
+			d.Selected, d.Condition = true, TargetConditionArchitectureAndPlatform
+		}
+	}
+
+	if d.Selected && len(f.Format) > 0 && t.Format != f.Format {
+		d.Selected, d.Condition = false, ""
+		if len(t.Format) == 0 {
+			d.Reason = fmt.Sprintf("no format, expected %s", f.Format)
+		} else {
+			d.Reason = fmt.Sprintf("format %s does not match %s", t.Format, f.Format)
+		}
+	}
+
+	return d
+}
+
+// Architectures returns the architectures listed by the filter.
+func (f TargetFilter) Architectures() []string {
+	return splitList(f.Architecture)
+}
+
+func (f TargetFilter) matchesArchitecture(architecture string) bool {
+	for _, a := range f.Architectures() {
+		if a == architecture {
+			return true
+		}
+	}
+
+	return false
+}
+
+// splitList splits a comma-separated list, dropping empty and duplicate
+// entries.
+func splitList(list string) []string {
+	var items []string
+	seen := map[string]bool{}
+
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" || seen[item] {
+			continue
+		}
+
+		seen[item] = true
+		items = append(items, item)
+	}
+
+	return items
+}
+
+// withFallback returns the filter with Platform set to the first of Platform
+// and PlatformFallback which has a matching target among specs.  The filter
+// is returned unchanged when no platform matches or no fallback is set.
+func (f TargetFilter) withFallback(specs []TargetSpec) TargetFilter {
+	if len(f.PlatformFallback) == 0 || len(f.Target) > 0 {
+		return f
+	}
+
+	var platforms []string
+	if len(f.Platform) > 0 {
+		platforms = append(platforms, f.Platform)
+	}
+	platforms = append(platforms, f.PlatformFallback...)
+
+	for _, platform := range platforms {
+		candidate := f
+		candidate.Platform = platform
+		candidate.PlatformFallback = nil
+
+		for _, t := range specs {
+			if candidate.decide(t).Selected {
+				return candidate
+			}
+		}
+	}
+
+	return f
+}
+
+// filterTargetSpecs returns the decision for each of the given targets, in
+// order.
+func filterTargetSpecs(specs []TargetSpec, f TargetFilter) []TargetDecision {
+	f = f.withFallback(specs)
+
+	decisions := make([]TargetDecision, 0, len(specs))
+	for _, t := range specs {
+		decisions = append(decisions, f.decide(t))
+	}
+
+	return decisions
+}
@@ -0,0 +1,153 @@
+package unikraft
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFilterTargetSpecs(t *testing.T) {
+	specs := []TargetSpec{
+		{Name: "nginx-qemu-x86_64", Architecture: "x86_64", Platform: "qemu"},
+		{Name: "nginx-qemu-arm64", Architecture: "arm64", Platform: "qemu"},
+		{Name: "nginx-fc-x86_64", Architecture: "x86_64", Platform: "fc"},
+	}
+
+	tests := []struct {
+		name      string
+		filter    TargetFilter
+		selected  []bool
+		condition string
+	}{
+		{"no filter", TargetFilter{}, []bool{true, true, true}, TargetConditionNoFilter},
+		{"name", TargetFilter{Target: "nginx-qemu-arm64"}, []bool{false, true, false}, TargetConditionName},
+		{"name supersedes arch", TargetFilter{Target: "nginx-fc-x86_64", Architecture: "arm64"}, []bool{false, false, true}, TargetConditionName},
+		{"architecture", TargetFilter{Architecture: "x86_64"}, []bool{true, false, true}, TargetConditionArchitecture},
+		{"platform", TargetFilter{Platform: "qemu"}, []bool{true, true, false}, TargetConditionPlatform},
+		{"architecture and platform", TargetFilter{Architecture: "x86_64", Platform: "fc"}, []bool{false, false, true}, TargetConditionArchitectureAndPlatform},
+		{"architectures", TargetFilter{Architecture: "x86_64, arm64"}, []bool{true, true, true}, TargetConditionArchitecture},
+		{"architectures and platform", TargetFilter{Architecture: "arm64,x86_64", Platform: "qemu"}, []bool{true, true, false}, TargetConditionArchitectureAndPlatform},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decisions := filterTargetSpecs(specs, tt.filter)
+			if len(decisions) != len(specs) {
+				t.Fatalf("expected a decision per target, got %d", len(decisions))
+			}
+
+			for i, d := range decisions {
+				if d.Selected != tt.selected[i] {
+					t.Errorf("%s: selected=%v, want %v", d.Target.Name, d.Selected, tt.selected[i])
+				}
+				if d.Selected && d.Condition != tt.condition {
+					t.Errorf("%s: condition=%s, want %s", d.Target.Name, d.Condition, tt.condition)
+				}
+				if !d.Selected && d.Reason == "" {
+					t.Errorf("%s: expected a reason for rejection", d.Target.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	got := splitList(" x86_64, arm64,,x86_64 ")
+	if len(got) != 2 || got[0] != "x86_64" || got[1] != "arm64" {
+		t.Errorf("splitList: got %v", got)
+	}
+}
+
+func TestFilterTargetsPlatformFallback(t *testing.T) {
+	specs := []TargetSpec{
+		{Name: "nginx-qemu-x86_64", Architecture: "x86_64", Platform: "qemu"},
+		{Name: "nginx-fc-arm64", Architecture: "arm64", Platform: "fc"},
+		{Name: "nginx-qemu-arm64", Architecture: "arm64", Platform: "qemu"},
+	}
+
+	tests := []struct {
+		name   string
+		filter TargetFilter
+		want   []string
+	}{
+		{
+			name:   "preferred platform available",
+			filter: TargetFilter{Architecture: "arm64", Platform: "fc", PlatformFallback: []string{"qemu"}},
+			want:   []string{"nginx-fc-arm64"},
+		},
+		{
+			name:   "falls back to the second platform",
+			filter: TargetFilter{Architecture: "x86_64", Platform: "fc", PlatformFallback: []string{"xen", "qemu"}},
+			want:   []string{"nginx-qemu-x86_64"},
+		},
+		{
+			name:   "fallback list only",
+			filter: TargetFilter{Architecture: "x86_64", PlatformFallback: []string{"fc", "qemu"}},
+			want:   []string{"nginx-qemu-x86_64"},
+		},
+		{
+			name:   "no platform matches",
+			filter: TargetFilter{Architecture: "x86_64", Platform: "fc", PlatformFallback: []string{"xen"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, d := range filterTargetSpecs(specs, tt.filter) {
+				if d.Selected {
+					got = append(got, d.Target.Name)
+				}
+			}
+
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("selected %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterTargetsFormat(t *testing.T) {
+	specs := []TargetSpec{
+		{Name: "nginx-qemu-x86_64", Architecture: "x86_64", Platform: "qemu", Format: "oci"},
+		{Name: "nginx-qemu-arm64", Architecture: "arm64", Platform: "qemu", Format: "oci"},
+		{Name: "nginx-fc-x86_64", Architecture: "x86_64", Platform: "fc"},
+	}
+
+	tests := []struct {
+		name   string
+		filter TargetFilter
+		want   []string
+	}{
+		{
+			name:   "format only",
+			filter: TargetFilter{Format: "oci"},
+			want:   []string{"nginx-qemu-x86_64", "nginx-qemu-arm64"},
+		},
+		{
+			name:   "format and architecture",
+			filter: TargetFilter{Architecture: "x86_64", Format: "oci"},
+			want:   []string{"nginx-qemu-x86_64"},
+		},
+		{
+			name:   "no target of the format",
+			filter: TargetFilter{Platform: "fc", Format: "oci"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, d := range filterTargetSpecs(specs, tt.filter) {
+				if d.Selected {
+					got = append(got, d.Target.Name)
+				} else if d.Target.Name == "nginx-fc-x86_64" && tt.filter.Platform == "fc" && d.Reason != "no format, expected oci" {
+					t.Errorf("unexpected rejection reason: %s", d.Reason)
+				}
+			}
+
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("selected %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
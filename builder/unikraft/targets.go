@@ -0,0 +1,138 @@
+package unikraft
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"kraftkit.sh/kconfig"
+	"kraftkit.sh/unikraft/arch"
+	"kraftkit.sh/unikraft/plat"
+	"kraftkit.sh/unikraft/target"
+)
+
+// targetSpec returns the plain description of a project target.
+func targetSpec(t target.Target) TargetSpec {
+	return TargetSpec{
+		Name:         t.Name(),
+		Architecture: t.Architecture().Name(),
+		Platform:     t.Platform().Name(),
+		Format:       string(t.Format()),
+	}
+}
+
+// FilterTargetsDetailed returns, for each target, whether it is selected by
+// the filter and which condition selected or rejected it.
+func FilterTargetsDetailed(targets []target.Target, filter TargetFilter) []TargetDecision {
+	specs := make([]TargetSpec, 0, len(targets))
+	for _, t := range targets {
+		specs = append(specs, targetSpec(t))
+	}
+
+	return filterTargetSpecs(specs, filter)
+}
+
+// FilterTargets returns the targets selected by the filter.  Targets with the
+// same name are only returned once.
+func FilterTargets(targets []target.Target, filter TargetFilter) []target.Target {
+	var selected []target.Target
+	seen := map[string]bool{}
+
+	for i, d := range FilterTargetsDetailed(targets, filter) {
+		if !d.Selected || seen[d.Target.Name] {
+			continue
+		}
+
+		seen[d.Target.Name] = true
+		selected = append(selected, targets[i])
+	}
+
+	return selected
+}
+
+// LoadTargetDefinitions reads external target definitions from a JSON or
+// YAML file, rejecting unknown fields.
+func LoadTargetDefinitions(path string) ([]TargetDefinition, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read target definitions: %w", err)
+	}
+
+	var doc TargetDefinitions
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&doc)
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(raw))
+		dec.KnownFields(true)
+		err = dec.Decode(&doc)
+	default:
+		return nil, fmt.Errorf("unsupported target definitions file %s: expected .json, .yaml or .yml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not parse target definitions %s: %w", path, err)
+	}
+
+	return doc.Targets, nil
+}
+
+// mergeTargetDefinitions validates the definitions against the existing
+// targets and returns both merged.
+func mergeTargetDefinitions(targets []target.Target, defs []TargetDefinition) ([]target.Target, error) {
+	specs := make([]TargetSpec, 0, len(targets))
+	for _, t := range targets {
+		specs = append(specs, targetSpec(t))
+	}
+
+	if err := validateTargetDefinitions(defs, specs); err != nil {
+		return nil, err
+	}
+
+	merged := append([]target.Target{}, targets...)
+	for _, d := range defs {
+		t, err := newTargetFromDefinition(d)
+		if err != nil {
+			return nil, err
+		}
+
+		merged = append(merged, t)
+	}
+
+	return merged, nil
+}
+
+// newTargetFromDefinition returns the project target described by d.
+func newTargetFromDefinition(d TargetDefinition) (target.Target, error) {
+	a, err := arch.NewArchitectureFromOptions(arch.WithName(d.Architecture))
+	if err != nil {
+		return nil, fmt.Errorf("target %s: %w", d.Name, err)
+	}
+
+	p, err := plat.NewPlatformFromOptions(plat.WithName(d.Platform))
+	if err != nil {
+		return nil, fmt.Errorf("target %s: %w", d.Name, err)
+	}
+
+	values := kconfig.KeyValueMap{}
+	for k, v := range d.Config {
+		values.Set(k, v)
+	}
+
+	t, err := target.NewTargetFromOptions(
+		target.WithName(d.Name),
+		target.WithArchitecture(a),
+		target.WithPlatform(p),
+		target.WithKConfig(values),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("target %s: %w", d.Name, err)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,51 @@
+package unikraft
+
+import (
+	"os/exec"
+	"runtime"
+)
+
+// crossCompilePrefixes lists the GNU toolchain prefixes which can be used to
+// build a Unikraft architecture from a foreign host.
+var crossCompilePrefixes = map[string][]string{
+	"x86_64": {"x86_64-linux-gnu-", "x86_64-elf-"},
+	"arm64":  {"aarch64-linux-gnu-", "aarch64-none-elf-", "aarch64-elf-"},
+	"arm":    {"arm-linux-gnueabihf-", "arm-none-eabi-"},
+}
+
+// hostArchitecture returns the Unikraft name of the architecture of the host.
+func hostArchitecture() string {
+	switch runtime.GOARCH {
+	case "amd64":
+		return "x86_64"
+	case "arm64":
+		return "arm64"
+	case "arm":
+		return "arm"
+	default:
+		return runtime.GOARCH
+	}
+}
+
+// canBuildArchitecture reports whether a target architecture can be built on
+// a host architecture, either natively or with a cross toolchain found by
+// lookPath.
+func canBuildArchitecture(host, target string, lookPath func(string) (string, error)) bool {
+	if host == target {
+		return true
+	}
+
+	for _, prefix := range crossCompilePrefixes[target] {
+		if _, err := lookPath(prefix + "gcc"); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// buildableOnHost reports whether the target architecture can be built on the
+// current host.
+func buildableOnHost(target string) bool {
+	return canBuildArchitecture(hostArchitecture(), target, exec.LookPath)
+}
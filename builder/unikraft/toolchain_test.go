@@ -0,0 +1,26 @@
+package unikraft
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCanBuildArchitecture(t *testing.T) {
+	missing := func(string) (string, error) { return "", errors.New("not found") }
+	found := func(file string) (string, error) {
+		if file == "aarch64-linux-gnu-gcc" {
+			return "/usr/bin/" + file, nil
+		}
+		return "", errors.New("not found")
+	}
+
+	if !canBuildArchitecture("x86_64", "x86_64", missing) {
+		t.Errorf("expected native target to be buildable")
+	}
+	if canBuildArchitecture("x86_64", "arm64", missing) {
+		t.Errorf("expected arm64 target to be skipped on x86_64 host without cross toolchain")
+	}
+	if !canBuildArchitecture("x86_64", "arm64", found) {
+		t.Errorf("expected arm64 target to be buildable with cross toolchain")
+	}
+}
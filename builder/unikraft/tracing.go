@@ -0,0 +1,143 @@
+package unikraft
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// tracerName is the instrumentation name of the spans of a build.
+const tracerName = "packer-plugin-unikraft"
+
+// Span attributes.
+const (
+	attrTarget       = attribute.Key("unikraft.target")
+	attrArchitecture = attribute.Key("unikraft.architecture")
+	attrPlatform     = attribute.Key("unikraft.platform")
+	attrPhase        = attribute.Key("unikraft.phase")
+	attrComponent    = attribute.Key("unikraft.component")
+	attrVersion      = attribute.Key("unikraft.version")
+	attrBytes        = attribute.Key("unikraft.bytes")
+)
+
+// newTracerProvider returns a provider exporting the spans to an OTLP/HTTP
+// endpoint, e.g. `http://localhost:4318`.
+func newTracerProvider(ctx context.Context, endpoint string) (*sdktrace.TracerProvider, error) {
+	exporter, err := otlptrace.New(ctx, &otlpHTTPClient{
+		url:    strings.TrimSuffix(endpoint, "/") + "/v1/traces",
+		client: http.DefaultClient,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not create trace exporter: %w", err)
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", tracerName),
+		)),
+	), nil
+}
+
+// startTargetSpan starts the span of building a target.
+func startTargetSpan(ctx context.Context, tracer trace.Tracer, name, architecture, platform string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "target "+name, trace.WithAttributes(
+		attrTarget.String(name),
+		attrArchitecture.String(architecture),
+		attrPlatform.String(platform),
+	))
+}
+
+// startPhaseSpan starts the span of a phase of building a target.
+func startPhaseSpan(ctx context.Context, tracer trace.Tracer, phase, target string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, phase+" "+target, trace.WithAttributes(
+		attrPhase.String(phase),
+		attrTarget.String(target),
+	))
+}
+
+// startPullSpan starts the span of pulling a component.
+func startPullSpan(ctx context.Context, tracer trace.Tracer, component, version string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "pull "+component, trace.WithAttributes(
+		attrPhase.String("pull"),
+		attrComponent.String(component),
+		attrVersion.String(version),
+	))
+}
+
+// endSpan records the outcome of the operation of a span and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// otlpHTTPClient uploads spans to an OTLP/HTTP endpoint as protobuf.
+type otlpHTTPClient struct {
+	url    string
+	client *http.Client
+}
+
+func (c *otlpHTTPClient) Start(context.Context) error { return nil }
+
+func (c *otlpHTTPClient) Stop(context.Context) error { return nil }
+
+func (c *otlpHTTPClient) UploadTraces(ctx context.Context, spans []*tracepb.ResourceSpans) error {
+	raw, err := encodeExportTraceRequest(spans)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("could not export spans: %s", resp.Status)
+	}
+
+	return nil
+}
+
+// encodeExportTraceRequest encodes an ExportTraceServiceRequest, which only
+// holds the resource spans as its first field.  It is encoded by hand so as
+// not to depend on the gRPC definitions of the collector service.
+func encodeExportTraceRequest(spans []*tracepb.ResourceSpans) ([]byte, error) {
+	var raw []byte
+	for _, rs := range spans {
+		b, err := proto.Marshal(rs)
+		if err != nil {
+			return nil, err
+		}
+
+		raw = protowire.AppendTag(raw, 1, protowire.BytesType)
+		raw = protowire.AppendBytes(raw, b)
+	}
+
+	return raw, nil
+}
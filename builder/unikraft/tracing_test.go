@@ -0,0 +1,121 @@
+package unikraft
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+func spanAttributes(s tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range s.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestBuildSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	tracer := provider.Tracer(tracerName)
+
+	ctx, pull := startPullSpan(context.Background(), tracer, "musl", "stable")
+	pull.SetAttributes(attrBytes.Int64(4096))
+	endSpan(pull, nil)
+
+	ctx, targ := startTargetSpan(ctx, tracer, "nginx-qemu-x86_64", "x86_64", "qemu")
+	_, build := startPhaseSpan(ctx, tracer, "build", "nginx-qemu-x86_64")
+	endSpan(build, errors.New("make failed"))
+	endSpan(targ, nil)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(spans))
+	}
+
+	attrs := spanAttributes(spans[0])
+	if attrs[attrComponent].AsString() != "musl" || attrs[attrBytes].AsInt64() != 4096 {
+		t.Errorf("unexpected pull span attributes: %v", attrs)
+	}
+
+	phase, target := spans[1], spans[2]
+	attrs = spanAttributes(phase)
+	if attrs[attrPhase].AsString() != "build" || attrs[attrTarget].AsString() != "nginx-qemu-x86_64" {
+		t.Errorf("unexpected phase span attributes: %v", attrs)
+	}
+	if phase.Status.Code != codes.Error {
+		t.Errorf("expected the failed phase to have an error status, got %v", phase.Status)
+	}
+	if phase.Parent.SpanID() != target.SpanContext.SpanID() {
+		t.Error("expected the phase span to be a child of the target span")
+	}
+
+	attrs = spanAttributes(target)
+	if attrs[attrArchitecture].AsString() != "x86_64" || attrs[attrPlatform].AsString() != "qemu" {
+		t.Errorf("unexpected target span attributes: %v", attrs)
+	}
+}
+
+func TestTracerProviderExportsOTLP(t *testing.T) {
+	received := make(chan []*tracepb.ResourceSpans, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" {
+			http.NotFound(w, r)
+			return
+		}
+		raw, _ := io.ReadAll(r.Body)
+
+		var spans []*tracepb.ResourceSpans
+		for len(raw) > 0 {
+			num, typ, n := protowire.ConsumeTag(raw)
+			if n < 0 || num != 1 || typ != protowire.BytesType {
+				http.Error(w, "malformed request", http.StatusBadRequest)
+				return
+			}
+			raw = raw[n:]
+
+			b, n := protowire.ConsumeBytes(raw)
+			rs := &tracepb.ResourceSpans{}
+			if err := proto.Unmarshal(b, rs); n < 0 || err != nil {
+				http.Error(w, "malformed resource spans", http.StatusBadRequest)
+				return
+			}
+			raw = raw[n:]
+			spans = append(spans, rs)
+		}
+		received <- spans
+	}))
+	defer srv.Close()
+
+	provider, err := newTracerProvider(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, span := startTargetSpan(context.Background(), provider.Tracer(tracerName), "nginx-qemu-x86_64", "x86_64", "qemu")
+	endSpan(span, nil)
+
+	if err := provider.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	resources := <-received
+	if n := len(resources); n != 1 {
+		t.Fatalf("expected 1 resource, got %d", n)
+	}
+	spans := resources[0].ScopeSpans[0].Spans
+	if len(spans) != 1 || spans[0].Name != "target nginx-qemu-x86_64" {
+		t.Errorf("unexpected exported spans: %v", spans)
+	}
+}
@@ -0,0 +1,42 @@
+package unikraft
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// runValidator runs the validator command with the JSON encoding of artifact
+// on its standard input and returns its combined output.  A non-zero exit
+// status fails the validation.
+func runValidator(ctx context.Context, argv []string, artifact interface{}) (string, error) {
+	if len(argv) == 0 {
+		return "", fmt.Errorf("no validator command")
+	}
+
+	payload, err := json.Marshal(artifact)
+	if err != nil {
+		return "", err
+	}
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	inProcessGroup(cmd, killGracePeriod)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	err = cmd.Run()
+	output := strings.TrimSpace(out.String())
+	if err != nil {
+		if output != "" {
+			return output, fmt.Errorf("validator %s failed: %w: %s", argv[0], err, output)
+		}
+		return output, fmt.Errorf("validator %s failed: %w", argv[0], err)
+	}
+
+	return output, nil
+}
@@ -0,0 +1,63 @@
+package unikraft
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunValidator(t *testing.T) {
+	dir := t.TempDir()
+	received := filepath.Join(dir, "received.json")
+
+	// The validator saves its input and fails for kernels of the fc platform.
+	script := filepath.Join(dir, "validate.sh")
+	err := os.WriteFile(script, []byte(`#!/bin/sh
+cat > "$1"
+if grep -q '"platform":"fc"' "$1"; then
+	echo "fc kernels are not allowed"
+	exit 3
+fi
+echo ok
+`), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	artifact := TargetResult{
+		Target:       "nginx-qemu-x86_64",
+		Architecture: "x86_64",
+		Platform:     "qemu",
+		Status:       TargetStatusSuccess,
+		Kernel:       "/app/.unikraft/build/nginx_qemu-x86_64",
+	}
+
+	out, err := runValidator(context.Background(), []string{script, received}, artifact)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "ok" {
+		t.Errorf("unexpected output: %q", out)
+	}
+
+	raw, err := os.ReadFile(received)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got TargetResult
+	if err := json.Unmarshal(raw, &got); err != nil {
+		t.Fatalf("validator received invalid JSON %q: %v", raw, err)
+	}
+	if got.Target != artifact.Target || got.Kernel != artifact.Kernel {
+		t.Errorf("validator received %+v", got)
+	}
+
+	artifact.Platform = "fc"
+	_, err = runValidator(context.Background(), []string{script, received}, artifact)
+	if err == nil || !strings.Contains(err.Error(), "exit status 3") || !strings.Contains(err.Error(), "fc kernels are not allowed") {
+		t.Errorf("expected the exit status to fail validation, got %v", err)
+	}
+}
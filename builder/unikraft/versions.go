@@ -0,0 +1,110 @@
+package unikraft
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// unikraftVersionVars are the variables of the Makefile of the Unikraft core
+// UK_FULLVERSION is made of, in order.
+var unikraftVersionVars = []string{"UK_VERSION", "UK_SUBVERSION", "UK_EXTRAVERSION"}
+
+// KraftKitVersion returns the version of the KraftKit library the plugin is
+// built with, empty when it cannot be determined.
+func KraftKitVersion() string {
+	return hostProbe.kraftkit()
+}
+
+// KraftCLIVersion returns the version of the kraft executable binary,
+// DefaultKraftBinary when empty.
+func KraftCLIVersion(binary string) (string, error) {
+	if binary == "" {
+		binary = DefaultKraftBinary
+	}
+
+	out, err := exec.Command(binary, "version").Output()
+	if err != nil {
+		return "", fmt.Errorf("could not run %s version: %w", binary, err)
+	}
+
+	version := parseKraftVersion(string(out))
+	if version == "" {
+		return "", fmt.Errorf("could not find the version in the output of %s version", binary)
+	}
+
+	return version, nil
+}
+
+// parseKraftVersion returns the version printed by `kraft version`, such as
+// `kraft 0.7.3 (6d0c1f3) go1.21.4 2023-11-30T10:22:41Z`.
+func parseKraftVersion(out string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(out), "\n")
+	fields := strings.Fields(line)
+	if len(fields) > 1 && fields[0] == "kraft" {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return ""
+	}
+
+	return strings.TrimPrefix(fields[0], "v")
+}
+
+// UnikraftCoreVersion returns the UK_FULLVERSION of the Unikraft core in dir,
+// as set by its Makefile.
+func UnikraftCoreVersion(dir string) (string, error) {
+	path := filepath.Join(dir, "Makefile")
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	vars := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		k, v, ok := makefileAssignment(scanner.Text())
+		if ok {
+			vars[k] = v
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	var parts []string
+	for _, k := range unikraftVersionVars {
+		v, ok := vars[k]
+		switch {
+		case !ok && k != "UK_EXTRAVERSION":
+			return "", fmt.Errorf("%s does not set %s", path, k)
+		case v != "":
+			parts = append(parts, v)
+		}
+	}
+
+	return strings.Join(parts, "."), nil
+}
+
+// makefileAssignment parses a `VAR = value` line of a Makefile, with any of
+// the `=`, `:=` and `?=` operators.
+func makefileAssignment(line string) (string, string, bool) {
+	if strings.HasPrefix(line, "\t") || strings.HasPrefix(strings.TrimSpace(line), "#") {
+		return "", "", false
+	}
+
+	k, v, ok := strings.Cut(line, "=")
+	if !ok {
+		return "", "", false
+	}
+	k = strings.TrimSpace(strings.TrimRight(k, ":?"))
+	if k == "" || strings.ContainsAny(k, " \t$") {
+		return "", "", false
+	}
+
+	return k, strings.TrimSpace(v), true
+}
@@ -0,0 +1,91 @@
+package unikraft
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseKraftVersion(t *testing.T) {
+	tests := map[string]string{
+		"kraft 0.7.3 (6d0c1f3) go1.21.4 2023-11-30T10:22:41Z\n": "0.7.3",
+		"kraft v0.8.0-rc1\n":  "0.8.0-rc1",
+		"0.7.14\nmore output": "0.7.14",
+		"\n":                  "",
+	}
+
+	for out, want := range tests {
+		if got := parseKraftVersion(out); got != want {
+			t.Errorf("parseKraftVersion(%q) = %q, want %q", out, got, want)
+		}
+	}
+}
+
+func TestUnikraftCoreVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		makefile string
+		want     string
+		err      string
+	}{
+		{
+			name:     "release",
+			makefile: "UK_VERSION = 0\nUK_SUBVERSION = 16\nUK_EXTRAVERSION = 1\nUK_FULLVERSION := $(UK_VERSION).$(UK_SUBVERSION).$(UK_EXTRAVERSION)\n",
+			want:     "0.16.1",
+		},
+		{
+			name:     "no extra version",
+			makefile: "# Unikraft\nUK_VERSION := 0\nUK_SUBVERSION ?= 17\nUK_EXTRAVERSION =\n\nall:\n\tUK_VERSION=1 make\n",
+			want:     "0.17",
+		},
+		{
+			name:     "not a core",
+			makefile: "all:\n\techo\n",
+			err:      "does not set UK_VERSION",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "Makefile"), []byte(tt.makefile), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := UnikraftCoreVersion(dir)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("UnikraftCoreVersion() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKraftCLIVersion(t *testing.T) {
+	dir := t.TempDir()
+	kraft := filepath.Join(dir, "kraft")
+	if err := os.WriteFile(kraft, []byte("#!/bin/sh\necho 'kraft 0.7.3 (6d0c1f3) go1.21.4'\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := KraftCLIVersion(kraft)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "0.7.3" {
+		t.Errorf("KraftCLIVersion() = %s, want 0.7.3", got)
+	}
+
+	if _, err := KraftCLIVersion(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected a missing kraft to fail")
+	}
+}
@@ -0,0 +1,163 @@
+package unikraft
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Volume drivers a target may mount a volume with.
+const (
+	VolumeDriver9pfs   = "9pfs"
+	VolumeDriverInitrd = "initrd"
+	VolumeDriverRaw    = "raw"
+	// VolumeDriverVirtiofs shares a host directory through virtio-fs, which
+	// is only available with the virtual machines of qemu.
+	VolumeDriverVirtiofs = "virtiofs"
+)
+
+// VolumesAnnotation is the OCI annotation of a package declaring the volumes
+// its targets mount, as a JSON array of `source:destination:driver` entries,
+// so that runtimes can provide them when deploying the package.
+const VolumesAnnotation = "org.unikraft.volumes"
+
+// platformVolumeDrivers lists, per platform, the volume drivers it supports.
+var platformVolumeDrivers = map[string][]string{
+	"qemu": {VolumeDriver9pfs, VolumeDriverInitrd, VolumeDriverRaw, VolumeDriverVirtiofs},
+	"kvm":  {VolumeDriver9pfs, VolumeDriverInitrd, VolumeDriverRaw, VolumeDriverVirtiofs},
+	"fc":   {VolumeDriverInitrd, VolumeDriverRaw},
+	"xen":  {VolumeDriver9pfs, VolumeDriverInitrd},
+}
+
+// Volume is a volume mounted by a target.
+type Volume struct {
+	Source      string
+	Destination string
+	Driver      string
+}
+
+// ParseVolume parses a `source:destination[:driver]` volume spec.  The driver
+// defaults to 9pfs.
+func ParseVolume(spec string) (Volume, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return Volume{}, fmt.Errorf("invalid volume %q: expected source:destination[:driver]", spec)
+	}
+
+	v := Volume{
+		Source:      parts[0],
+		Destination: parts[1],
+		Driver:      VolumeDriver9pfs,
+	}
+	if len(parts) == 3 {
+		v.Driver = parts[2]
+	}
+
+	return v, nil
+}
+
+// String returns the `source:destination:driver` spec of the volume.
+func (v Volume) String() string {
+	return v.Source + ":" + v.Destination + ":" + v.Driver
+}
+
+// ParseVolumes parses volume specs and, when platform is set, checks that it
+// supports the driver of every volume.
+func ParseVolumes(platform string, specs []string) ([]Volume, error) {
+	var volumes []Volume
+	for _, spec := range specs {
+		v, err := ParseVolume(spec)
+		if err != nil {
+			return nil, err
+		}
+		volumes = append(volumes, v)
+	}
+
+	if platform != "" {
+		if err := validateVolumes(platform, volumes); err != nil {
+			return nil, err
+		}
+	}
+
+	return volumes, nil
+}
+
+// VolumeAnnotations returns the OCI annotations declaring volumes, or nil
+// when there are none.
+func VolumeAnnotations(volumes []Volume) (map[string]string, error) {
+	if len(volumes) == 0 {
+		return nil, nil
+	}
+
+	specs := make([]string, 0, len(volumes))
+	for _, v := range volumes {
+		specs = append(specs, v.String())
+	}
+
+	raw, err := json.Marshal(specs)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{VolumesAnnotation: string(raw)}, nil
+}
+
+// validateVolumes checks that the platform supports the driver of every
+// volume and suggests the supported drivers otherwise.
+func validateVolumes(platform string, volumes []Volume) error {
+	drivers, ok := platformVolumeDrivers[platform]
+	if !ok {
+		return nil
+	}
+
+	for _, v := range volumes {
+		supported := false
+		for _, d := range drivers {
+			if d == v.Driver {
+				supported = true
+				break
+			}
+		}
+
+		if !supported {
+			return fmt.Errorf("volume %s: platform %s does not support the %s driver, use one of: %s",
+				v.Destination,
+				platform,
+				v.Driver,
+				strings.Join(drivers, ", "),
+			)
+		}
+	}
+
+	return nil
+}
+
+// checkVolumeSources verifies that the host source of every volume can be
+// read, so that packaging fails early with the offending path.
+func checkVolumeSources(volumes []Volume) error {
+	for _, v := range volumes {
+		f, err := os.Open(v.Source)
+		if err != nil {
+			return fmt.Errorf("volume source %s is not readable: %w", v.Source, err)
+		}
+
+		fi, err := f.Stat()
+		if err == nil && fi.IsDir() {
+			// Opening a directory succeeds without read permission, listing
+			// it does not.
+			_, err = f.Readdirnames(1)
+			if err == io.EOF {
+				err = nil
+			}
+		}
+		f.Close()
+
+		if err != nil {
+			return fmt.Errorf("volume source %s is not readable: %w", v.Source, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package unikraft
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateVolumes(t *testing.T) {
+	tests := []struct {
+		platform string
+		spec     string
+		err      string
+	}{
+		{platform: "qemu", spec: "./html:/nginx/html"},
+		{platform: "fc", spec: "./rootfs.cpio:/:initrd"},
+		{platform: "fc", spec: "./html:/nginx/html", err: "platform fc does not support the 9pfs driver, use one of: initrd, raw"},
+		{platform: "xen", spec: "./disk.img:/data:raw", err: "use one of: 9pfs, initrd"},
+	}
+
+	for _, tt := range tests {
+		v, err := ParseVolume(tt.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = validateVolumes(tt.platform, []Volume{v})
+		if tt.err == "" && err != nil {
+			t.Errorf("%s on %s: unexpected error: %v", tt.spec, tt.platform, err)
+		} else if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("%s on %s: expected error containing %q, got %v", tt.spec, tt.platform, tt.err, err)
+		}
+	}
+}
+
+func TestParseVolumes(t *testing.T) {
+	volumes, err := ParseVolumes("qemu", []string{"./html:/nginx/html", "./cache:/cache:virtiofs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	annotations, err := VolumeAnnotations(volumes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `["./html:/nginx/html:9pfs","./cache:/cache:virtiofs"]`
+	if got := annotations[VolumesAnnotation]; got != want {
+		t.Errorf("%s = %s, want %s", VolumesAnnotation, got, want)
+	}
+
+	if _, err := ParseVolumes("fc", []string{"./cache:/cache:virtiofs"}); err == nil || !strings.Contains(err.Error(), "does not support the virtiofs driver") {
+		t.Errorf("expected virtiofs to be rejected on fc, got %v", err)
+	}
+	if _, err := ParseVolumes("", []string{"./cache:/cache:virtiofs"}); err != nil {
+		t.Errorf("unexpected error without a platform: %v", err)
+	}
+
+	if annotations, err := VolumeAnnotations(nil); err != nil || annotations != nil {
+		t.Errorf("expected no annotations without volumes, got %v, %v", annotations, err)
+	}
+}
+
+func TestParseVolumeInvalid(t *testing.T) {
+	for _, spec := range []string{"", "/data", ":/data", "a:b:c:d"} {
+		if _, err := ParseVolume(spec); err == nil {
+			t.Errorf("expected %q to be rejected", spec)
+		}
+	}
+}
+
+func TestCheckVolumeSources(t *testing.T) {
+	dir := t.TempDir()
+
+	readable := filepath.Join(dir, "html")
+	if err := os.Mkdir(readable, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkVolumeSources([]Volume{{Source: readable, Destination: "/nginx/html"}}); err != nil {
+		t.Errorf("unexpected error for readable source: %v", err)
+	}
+
+	missing := filepath.Join(dir, "missing")
+	err := checkVolumeSources([]Volume{{Source: missing, Destination: "/data"}})
+	if err == nil || !strings.Contains(err.Error(), missing) || !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected missing source to be reported, got %v", err)
+	}
+
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for root")
+	}
+
+	for _, path := range []string{filepath.Join(dir, "secret.key"), filepath.Join(dir, "private")} {
+		if filepath.Ext(path) != "" {
+			if err := os.WriteFile(path, []byte("secret"), 0o000); err != nil {
+				t.Fatal(err)
+			}
+		} else if err := os.Mkdir(path, 0o000); err != nil {
+			t.Fatal(err)
+		}
+		defer os.Chmod(path, 0o755)
+
+		err := checkVolumeSources([]Volume{{Source: path, Destination: "/data"}})
+		if err == nil || !strings.Contains(err.Error(), path) || !errors.Is(err, fs.ErrPermission) {
+			t.Errorf("expected permission denied for %s, got %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package unikraft
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultXenMemory is the memory of a Xen domain, in MiB.
+	DefaultXenMemory = 64
+	// DefaultXenVCPUs is the number of vCPUs of a Xen domain.
+	DefaultXenVCPUs = 1
+)
+
+// XenDomain describes the domain a kernel built for the xen platform is
+// booted in with `xl create`.
+type XenDomain struct {
+	Name     string
+	Kernel   string
+	BootArgs string
+	// Memory is the memory of the domain in MiB.  Defaults to
+	// DefaultXenMemory.
+	Memory int
+	// VCPUs is the number of vCPUs of the domain.  Defaults to
+	// DefaultXenVCPUs.
+	VCPUs int
+	// Vifs are the network interfaces of the domain, each a vif
+	// specification such as `bridge=xenbr0`.
+	Vifs []string
+}
+
+// Config returns the xl domain configuration file of the domain.
+func (d XenDomain) Config() ([]byte, error) {
+	if len(d.Kernel) == 0 {
+		return nil, fmt.Errorf("xen domain has no kernel")
+	}
+	if len(d.Name) == 0 {
+		return nil, fmt.Errorf("xen domain has no name")
+	}
+
+	memory := d.Memory
+	if memory <= 0 {
+		memory = DefaultXenMemory
+	}
+
+	vcpus := d.VCPUs
+	if vcpus <= 0 {
+		vcpus = DefaultXenVCPUs
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "name = %s\n", strconv.Quote(d.Name))
+	fmt.Fprintf(&b, "kernel = %s\n", strconv.Quote(d.Kernel))
+	if len(d.BootArgs) > 0 {
+		fmt.Fprintf(&b, "cmdline = %s\n", strconv.Quote(d.BootArgs))
+	}
+	fmt.Fprintf(&b, "memory = %d\n", memory)
+	fmt.Fprintf(&b, "vcpus = %d\n", vcpus)
+	if len(d.Vifs) > 0 {
+		vifs := make([]string, 0, len(d.Vifs))
+		for _, vif := range d.Vifs {
+			vifs = append(vifs, strconv.Quote(vif))
+		}
+		fmt.Fprintf(&b, "vif = [ %s ]\n", strings.Join(vifs, ", "))
+	}
+	b.WriteString("on_crash = \"destroy\"\n")
+
+	return []byte(b.String()), nil
+}
+
+// WriteConfig writes the xl domain configuration file of the domain to path.
+func (d XenDomain) WriteConfig(path string) error {
+	raw, err := d.Config()
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, raw, 0o644)
+}
@@ -0,0 +1,61 @@
+package unikraft
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestXenDomainConfig(t *testing.T) {
+	raw, err := XenDomain{
+		Name:     "helloworld_xen-x86_64",
+		Kernel:   "/out/helloworld_xen-x86_64",
+		BootArgs: "netdev.ip=10.0.0.2/24",
+		Memory:   32,
+		Vifs:     []string{"bridge=xenbr0", "mac=00:16:3e:00:00:01,bridge=xenbr1"},
+	}.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `name = "helloworld_xen-x86_64"
+kernel = "/out/helloworld_xen-x86_64"
+cmdline = "netdev.ip=10.0.0.2/24"
+memory = 32
+vcpus = 1
+vif = [ "bridge=xenbr0", "mac=00:16:3e:00:00:01,bridge=xenbr1" ]
+on_crash = "destroy"
+`
+	if string(raw) != want {
+		t.Errorf("Config() = %s, want %s", raw, want)
+	}
+}
+
+func TestXenDomainConfigDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.cfg")
+	err := XenDomain{Name: "app", Kernel: "/out/app"}.WriteConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `name = "app"
+kernel = "/out/app"
+memory = 64
+vcpus = 1
+on_crash = "destroy"
+`
+	if string(raw) != want {
+		t.Errorf("config = %s, want %s", raw, want)
+	}
+}
+
+func TestXenDomainConfigWithoutKernel(t *testing.T) {
+	if _, err := (XenDomain{Name: "app"}).Config(); err == nil {
+		t.Error("expected an error without a kernel")
+	}
+}
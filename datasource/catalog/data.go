@@ -0,0 +1,161 @@
+//go:generate packer-sdc mapstructure-to-hcl2 -type Config,DatasourceOutput,Package
+
+package catalogdatasource
+
+import (
+	"context"
+	"fmt"
+	"os"
+	unikraftBuilder "packer-plugin-unikraft/builder/unikraft"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer-plugin-sdk/hcl2helper"
+	"github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/hashicorp/packer-plugin-sdk/template/config"
+	"github.com/zclconf/go-cty/cty"
+	"kraftkit.sh/unikraft"
+)
+
+const DatasourceId = "packer.datasource.unikraft-catalog"
+
+type Config struct {
+	// The name of the component to look up.
+	Name string `mapstructure:"name"`
+	// The version of the component to look up.
+	Version string `mapstructure:"version"`
+	// The component types to look up: `core`, `arch`, `plat`, `lib` or `app`.
+	Types []string `mapstructure:"types"`
+	// Update the catalog before querying it.
+	Update bool `mapstructure:"update"`
+	// Do not fail when no package matches.
+	AllowEmpty bool `mapstructure:"allow_empty"`
+	// Log level to use.
+	LogLevel string `mapstructure:"log_level"`
+}
+
+// Package is a package found in the catalog.
+type Package struct {
+	Name    string `mapstructure:"name"`
+	Version string `mapstructure:"version"`
+	Type    string `mapstructure:"type"`
+	Format  string `mapstructure:"format"`
+	Source  string `mapstructure:"source"`
+}
+
+type DatasourceOutput struct {
+	// The packages matching the query, sorted by type and name.
+	Packages []Package `mapstructure:"packages"`
+	// The version of the first matching package.
+	Version string `mapstructure:"version"`
+}
+
+// Datasource queries the Unikraft package catalog.
+type Datasource struct {
+	config Config
+
+	// query overrides querying the catalog with KraftKit.
+	query func(context.Context, *unikraftBuilder.Catalog) ([]unikraftBuilder.CatalogEntry, error)
+}
+
+func (d *Datasource) ConfigSpec() hcldec.ObjectSpec {
+	return d.config.FlatMapstructure().HCL2Spec()
+}
+
+func (d *Datasource) Configure(raws ...interface{}) error {
+	err := config.Decode(&d.config, &config.DecodeOpts{
+		PluginType: DatasourceId,
+	}, raws...)
+	if err != nil {
+		return err
+	}
+
+	// Accumulate any errors
+	var errs *packer.MultiError
+	if d.config.Name == "" && len(d.config.Types) == 0 {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("name or types must be specified"))
+	}
+
+	if err := unikraftBuilder.ValidateCatalogTypes(d.config.Types); err != nil {
+		errs = packer.MultiErrorAppend(errs, err)
+	}
+
+	if errs != nil && len(errs.Errors) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func (d *Datasource) OutputSpec() hcldec.ObjectSpec {
+	return (&DatasourceOutput{}).FlatMapstructure().HCL2Spec()
+}
+
+func (d *Datasource) Execute() (cty.Value, error) {
+	catalog := &unikraftBuilder.Catalog{
+		Name:    d.config.Name,
+		Version: d.config.Version,
+		Update:  d.config.Update,
+	}
+	for _, t := range d.config.Types {
+		catalog.Types = append(catalog.Types, unikraft.ComponentType(t))
+	}
+
+	query := d.query
+	if query == nil {
+		query = queryCatalog(d.config.LogLevel)
+	}
+
+	entries, err := query(context.Background(), catalog)
+	if err != nil {
+		return cty.NullVal(cty.EmptyObject), fmt.Errorf("could not query the catalog: %w", err)
+	}
+
+	if len(entries) == 0 && !d.config.AllowEmpty {
+		return cty.NullVal(cty.EmptyObject), fmt.Errorf("no package matches %s", describeQuery(d.config))
+	}
+
+	output := DatasourceOutput{Packages: []Package{}}
+	for _, e := range entries {
+		output.Packages = append(output.Packages, Package(e))
+	}
+	if len(output.Packages) > 0 {
+		output.Version = output.Packages[0].Version
+	}
+
+	return hcl2helper.HCL2ValueFromConfig(output, d.OutputSpec()), nil
+}
+
+// queryCatalog returns a query of the catalog with KraftKit.
+func queryCatalog(logLevel string) func(context.Context, *unikraftBuilder.Catalog) ([]unikraftBuilder.CatalogEntry, error) {
+	return func(_ context.Context, catalog *unikraftBuilder.Catalog) ([]unikraftBuilder.CatalogEntry, error) {
+		ui := &packer.BasicUi{Writer: os.Stderr, ErrorWriter: os.Stderr}
+
+		packages, err := catalog.CatalogCmd(unikraftBuilder.KraftCommandContext(ui, logLevel, false))
+		if err != nil {
+			return nil, err
+		}
+
+		entries := make([]unikraftBuilder.CatalogEntry, 0, len(packages))
+		for _, p := range packages {
+			entries = append(entries, unikraftBuilder.NewCatalogEntry(p))
+		}
+
+		return entries, nil
+	}
+}
+
+// describeQuery describes the query of the catalog for error messages.
+func describeQuery(c Config) string {
+	desc := c.Name
+	if desc == "" {
+		desc = "any component"
+	}
+	if c.Version != "" {
+		desc += "@" + c.Version
+	}
+	if len(c.Types) > 0 {
+		desc += fmt.Sprintf(" of type %v", c.Types)
+	}
+
+	return desc
+}
@@ -0,0 +1,77 @@
+package catalogdatasource
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	unikraftBuilder "packer-plugin-unikraft/builder/unikraft"
+)
+
+func fakeQuery(entries ...unikraftBuilder.CatalogEntry) func(context.Context, *unikraftBuilder.Catalog) ([]unikraftBuilder.CatalogEntry, error) {
+	return func(_ context.Context, c *unikraftBuilder.Catalog) ([]unikraftBuilder.CatalogEntry, error) {
+		var matched []unikraftBuilder.CatalogEntry
+		for _, e := range entries {
+			if c.Name == "" || c.Name == e.Name {
+				matched = append(matched, e)
+			}
+		}
+		return matched, nil
+	}
+}
+
+func TestExecuteExposesPackages(t *testing.T) {
+	d := &Datasource{query: fakeQuery(unikraftBuilder.CatalogEntry{
+		Name:    "musl",
+		Version: "stable",
+		Type:    "lib",
+		Format:  "manifest",
+		Source:  "https://github.com/unikraft/lib-musl.git",
+	})}
+	if err := d.Configure(map[string]interface{}{"name": "musl", "types": []string{"lib"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := d.Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := value.GetAttr("version").AsString(); got != "stable" {
+		t.Errorf("version = %s, want stable", got)
+	}
+
+	packages := value.GetAttr("packages").AsValueSlice()
+	if len(packages) != 1 {
+		t.Fatalf("expected 1 package, got %d", len(packages))
+	}
+	if got := packages[0].GetAttr("source").AsString(); got != "https://github.com/unikraft/lib-musl.git" {
+		t.Errorf("source = %s", got)
+	}
+}
+
+func TestExecuteFailsWhenMissing(t *testing.T) {
+	d := &Datasource{query: fakeQuery()}
+	if err := d.Configure(map[string]interface{}{"name": "lwip", "version": "0.15.0"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := d.Execute(); err == nil || !strings.Contains(err.Error(), "lwip@0.15.0") {
+		t.Errorf("expected missing package to fail, got %v", err)
+	}
+
+	d.config.AllowEmpty = true
+	if _, err := d.Execute(); err != nil {
+		t.Errorf("unexpected error with allow_empty: %v", err)
+	}
+}
+
+func TestConfigureValidatesQuery(t *testing.T) {
+	if err := (&Datasource{}).Configure(map[string]interface{}{}); err == nil {
+		t.Error("expected a query without name or types to be rejected")
+	}
+
+	if err := (&Datasource{}).Configure(map[string]interface{}{"types": []string{"library"}}); err == nil {
+		t.Error("expected an unknown type to be rejected")
+	}
+}
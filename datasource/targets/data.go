@@ -0,0 +1,159 @@
+//go:generate packer-sdc mapstructure-to-hcl2 -type Config,DatasourceOutput,Target
+
+package targetsdatasource
+
+import (
+	"fmt"
+	unikraftBuilder "packer-plugin-unikraft/builder/unikraft"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer-plugin-sdk/template/config"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const DatasourceId = "packer.datasource.unikraft-targets"
+
+type Config struct {
+	// The directory of the project. Defaults to the current directory.
+	Workdir string `mapstructure:"workdir"`
+	// The Kraftfile of the project, relative to workdir. Defaults to the
+	// ones kraft looks up.
+	Kraftfile string `mapstructure:"kraftfile"`
+	// Only list the targets of these comma-separated architectures.
+	Architecture string `mapstructure:"architecture"`
+	// Only list the targets of this platform.
+	Platform string `mapstructure:"platform"`
+	// Do not fail when no target is listed.
+	AllowEmpty bool `mapstructure:"allow_empty"`
+}
+
+// Target is a target declared in the Kraftfile.
+type Target struct {
+	Name         string            `mapstructure:"name"`
+	Architecture string            `mapstructure:"architecture"`
+	Platform     string            `mapstructure:"platform"`
+	KConfig      map[string]string `mapstructure:"kconfig"`
+}
+
+type DatasourceOutput struct {
+	// The path of the Kraftfile.
+	Kraftfile string `mapstructure:"kraftfile"`
+	// The targets of the Kraftfile, in order.
+	Targets []Target `mapstructure:"targets"`
+	// The names of the targets, in order.
+	Names []string `mapstructure:"names"`
+}
+
+// Datasource lists the targets declared in the Kraftfile of a project.
+type Datasource struct {
+	config Config
+}
+
+func (d *Datasource) ConfigSpec() hcldec.ObjectSpec {
+	return d.config.FlatMapstructure().HCL2Spec()
+}
+
+func (d *Datasource) Configure(raws ...interface{}) error {
+	err := config.Decode(&d.config, &config.DecodeOpts{
+		PluginType: DatasourceId,
+	}, raws...)
+	if err != nil {
+		return err
+	}
+
+	if d.config.Workdir == "" {
+		d.config.Workdir = "."
+	}
+
+	return nil
+}
+
+func (d *Datasource) OutputSpec() hcldec.ObjectSpec {
+	return (&DatasourceOutput{}).FlatMapstructure().HCL2Spec()
+}
+
+func (d *Datasource) Execute() (cty.Value, error) {
+	path, targets, err := unikraftBuilder.ReadKraftfileTargets(d.config.Workdir, d.config.Kraftfile)
+	if err != nil {
+		return cty.NullVal(cty.EmptyObject), err
+	}
+
+	targets = unikraftBuilder.FilterKraftfileTargets(targets, unikraftBuilder.TargetFilter{
+		Architecture: d.config.Architecture,
+		Platform:     d.config.Platform,
+	})
+	if len(targets) == 0 && !d.config.AllowEmpty {
+		return cty.NullVal(cty.EmptyObject), fmt.Errorf("%s declares no target%s", path, describeFilter(d.config))
+	}
+
+	output := DatasourceOutput{Kraftfile: path, Targets: []Target{}, Names: []string{}}
+	for _, t := range targets {
+		output.Targets = append(output.Targets, Target(t))
+		output.Names = append(output.Names, t.Name)
+	}
+
+	return output.value(), nil
+}
+
+// value returns the output as the value of the data source.  The value is
+// built rather than converted from the output, as the conversion does not
+// support lists of strings and maps.
+func (o DatasourceOutput) value() cty.Value {
+	targetType := hcldec.ImpliedType(hcldec.ObjectSpec((*FlatTarget)(nil).HCL2Spec()))
+
+	targets := cty.ListValEmpty(targetType)
+	if len(o.Targets) > 0 {
+		values := make([]cty.Value, 0, len(o.Targets))
+		for _, t := range o.Targets {
+			values = append(values, t.value())
+		}
+		targets = cty.ListVal(values)
+	}
+
+	names := cty.ListValEmpty(cty.String)
+	if len(o.Names) > 0 {
+		values := make([]cty.Value, 0, len(o.Names))
+		for _, n := range o.Names {
+			values = append(values, cty.StringVal(n))
+		}
+		names = cty.ListVal(values)
+	}
+
+	return cty.ObjectVal(map[string]cty.Value{
+		"kraftfile": cty.StringVal(o.Kraftfile),
+		"targets":   targets,
+		"names":     names,
+	})
+}
+
+func (t Target) value() cty.Value {
+	kconfig := cty.MapValEmpty(cty.String)
+	if len(t.KConfig) > 0 {
+		values := make(map[string]cty.Value, len(t.KConfig))
+		for k, v := range t.KConfig {
+			values[k] = cty.StringVal(v)
+		}
+		kconfig = cty.MapVal(values)
+	}
+
+	return cty.ObjectVal(map[string]cty.Value{
+		"name":         cty.StringVal(t.Name),
+		"architecture": cty.StringVal(t.Architecture),
+		"platform":     cty.StringVal(t.Platform),
+		"kconfig":      kconfig,
+	})
+}
+
+// describeFilter describes the selection of the targets for error messages.
+func describeFilter(c Config) string {
+	switch {
+	case c.Architecture != "" && c.Platform != "":
+		return fmt.Sprintf(" for %s/%s", c.Platform, c.Architecture)
+	case c.Architecture != "":
+		return " for " + c.Architecture
+	case c.Platform != "":
+		return " for " + c.Platform
+	}
+
+	return ""
+}
@@ -0,0 +1,116 @@
+package targetsdatasource
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const kraftfile = `spec: v0.6
+name: helloworld
+unikraft: stable
+targets:
+- qemu/x86_64
+- qemu/arm64
+- name: fc-net
+  arch: x86_64
+  plat: fc
+  kconfig:
+    CONFIG_LIBUKNETDEV: y
+`
+
+func project(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "Kraftfile"), []byte(kraftfile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return dir
+}
+
+func TestExecuteListsTargets(t *testing.T) {
+	d := &Datasource{}
+	if err := d.Configure(map[string]interface{}{"workdir": project(t)}); err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := d.Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := hcldec.ImpliedType(d.OutputSpec()); !value.Type().Equals(want) {
+		t.Fatalf("output type = %s, want %s", value.Type().FriendlyName(), want.FriendlyName())
+	}
+
+	var names []string
+	for _, n := range value.GetAttr("names").AsValueSlice() {
+		names = append(names, n.AsString())
+	}
+	if got := strings.Join(names, ","); got != "qemu-x86_64,qemu-arm64,fc-net" {
+		t.Errorf("names = %s", got)
+	}
+
+	targets := value.GetAttr("targets").AsValueSlice()
+	if len(targets) != 3 {
+		t.Fatalf("expected 3 targets, got %d", len(targets))
+	}
+	if got := targets[2].GetAttr("platform").AsString(); got != "fc" {
+		t.Errorf("platform = %s, want fc", got)
+	}
+	if got := targets[2].GetAttr("kconfig").Index(cty.StringVal("CONFIG_LIBUKNETDEV")).AsString(); got != "y" {
+		t.Errorf("CONFIG_LIBUKNETDEV = %s, want y", got)
+	}
+	if got := targets[0].GetAttr("kconfig").LengthInt(); got != 0 {
+		t.Errorf("expected no kconfig, got %d symbols", got)
+	}
+}
+
+func TestExecuteFiltersTargets(t *testing.T) {
+	d := &Datasource{}
+	if err := d.Configure(map[string]interface{}{
+		"workdir":      project(t),
+		"architecture": "arm64",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := d.Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := value.GetAttr("names").AsValueSlice(); len(got) != 1 || got[0].AsString() != "qemu-arm64" {
+		t.Errorf("names = %v", got)
+	}
+
+	d.config.Platform = "xen"
+	if _, err := d.Execute(); err == nil || !strings.Contains(err.Error(), "no target for xen/arm64") {
+		t.Errorf("expected no target to fail, got %v", err)
+	}
+
+	d.config.AllowEmpty = true
+	value, err = d.Execute()
+	if err != nil {
+		t.Fatalf("unexpected error with allow_empty: %v", err)
+	}
+	if want := hcldec.ImpliedType(d.OutputSpec()); !value.Type().Equals(want) {
+		t.Errorf("empty output type = %s, want %s", value.Type().FriendlyName(), want.FriendlyName())
+	}
+}
+
+func TestExecuteFailsWithoutKraftfile(t *testing.T) {
+	d := &Datasource{}
+	if err := d.Configure(map[string]interface{}{"workdir": t.TempDir()}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := d.Execute(); err == nil || !strings.Contains(err.Error(), "no Kraftfile") {
+		t.Errorf("expected a missing Kraftfile to fail, got %v", err)
+	}
+}
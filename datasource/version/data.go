@@ -0,0 +1,108 @@
+//go:generate packer-sdc mapstructure-to-hcl2 -type Config,DatasourceOutput
+
+package versiondatasource
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	unikraftBuilder "packer-plugin-unikraft/builder/unikraft"
+	"path/filepath"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer-plugin-sdk/hcl2helper"
+	"github.com/hashicorp/packer-plugin-sdk/template/config"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const DatasourceId = "packer.datasource.unikraft-version"
+
+type Config struct {
+	// The directory of the project. Defaults to the current directory.
+	Workdir string `mapstructure:"workdir"`
+	// The directory of the pulled Unikraft core. Defaults to
+	// `.unikraft/unikraft` in workdir.
+	UnikraftDir string `mapstructure:"unikraft_dir"`
+	// The kraft executable whose version is reported. When set, the data
+	// source fails if it cannot be run.
+	KraftBinary string `mapstructure:"kraft_binary"`
+}
+
+type DatasourceOutput struct {
+	// The version of the KraftKit library the plugin is built with.
+	KraftKit string `mapstructure:"kraftkit"`
+	// The version of the kraft executable, empty when it is not installed.
+	Kraft string `mapstructure:"kraft"`
+	// The UK_FULLVERSION of the Unikraft core, empty when it is not pulled.
+	Unikraft string `mapstructure:"unikraft"`
+	// The directory of the Unikraft core.
+	UnikraftDir string `mapstructure:"unikraft_dir"`
+}
+
+// Datasource reports the versions of KraftKit and of the Unikraft core.
+type Datasource struct {
+	config Config
+}
+
+func (d *Datasource) ConfigSpec() hcldec.ObjectSpec {
+	return d.config.FlatMapstructure().HCL2Spec()
+}
+
+func (d *Datasource) Configure(raws ...interface{}) error {
+	err := config.Decode(&d.config, &config.DecodeOpts{
+		PluginType: DatasourceId,
+	}, raws...)
+	if err != nil {
+		return err
+	}
+
+	if d.config.Workdir == "" {
+		d.config.Workdir = "."
+	}
+
+	if d.config.UnikraftDir == "" {
+		d.config.UnikraftDir = filepath.Join(d.config.Workdir, ".unikraft", "unikraft")
+	}
+
+	return nil
+}
+
+func (d *Datasource) OutputSpec() hcldec.ObjectSpec {
+	return (&DatasourceOutput{}).FlatMapstructure().HCL2Spec()
+}
+
+func (d *Datasource) Execute() (cty.Value, error) {
+	output := DatasourceOutput{
+		KraftKit:    unikraftBuilder.KraftKitVersion(),
+		UnikraftDir: d.config.UnikraftDir,
+	}
+
+	kraft, err := kraftVersion(d.config.KraftBinary)
+	if err != nil {
+		return cty.NullVal(cty.EmptyObject), err
+	}
+	output.Kraft = kraft
+
+	unikraft, err := unikraftBuilder.UnikraftCoreVersion(d.config.UnikraftDir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return cty.NullVal(cty.EmptyObject), fmt.Errorf("could not read the version of the Unikraft core: %w", err)
+	}
+	output.Unikraft = unikraft
+
+	return hcl2helper.HCL2ValueFromConfig(output, d.OutputSpec()), nil
+}
+
+// kraftVersion returns the version of binary.  Without binary, the version of
+// the kraft found in PATH is returned, empty when there is none.
+func kraftVersion(binary string) (string, error) {
+	if binary == "" {
+		path, err := exec.LookPath(unikraftBuilder.DefaultKraftBinary)
+		if err != nil {
+			return "", nil
+		}
+		binary = path
+	}
+
+	return unikraftBuilder.KraftCLIVersion(binary)
+}
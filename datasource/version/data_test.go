@@ -0,0 +1,90 @@
+package versiondatasource
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const makefile = `UK_VERSION = 0
+UK_SUBVERSION = 16
+UK_EXTRAVERSION = 1
+`
+
+func kraft(t *testing.T, dir string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, "kraft")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\necho 'kraft 0.7.3 (6d0c1f3) go1.21.4'\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestExecuteReportsVersions(t *testing.T) {
+	dir := t.TempDir()
+	core := filepath.Join(dir, ".unikraft", "unikraft")
+	if err := os.MkdirAll(core, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(core, "Makefile"), []byte(makefile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", filepath.Dir(kraft(t, t.TempDir())))
+
+	d := &Datasource{}
+	if err := d.Configure(map[string]interface{}{"workdir": dir}); err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := d.Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := value.GetAttr("kraft").AsString(); got != "0.7.3" {
+		t.Errorf("kraft = %s, want 0.7.3", got)
+	}
+	if got := value.GetAttr("unikraft").AsString(); got != "0.16.1" {
+		t.Errorf("unikraft = %s, want 0.16.1", got)
+	}
+	if got := value.GetAttr("unikraft_dir").AsString(); got != core {
+		t.Errorf("unikraft_dir = %s, want %s", got, core)
+	}
+}
+
+func TestExecuteWithoutKraftAndCore(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	d := &Datasource{}
+	if err := d.Configure(map[string]interface{}{"workdir": t.TempDir()}); err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := d.Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := value.GetAttr("kraft").AsString(); got != "" {
+		t.Errorf("expected no kraft version, got %s", got)
+	}
+	if got := value.GetAttr("unikraft").AsString(); got != "" {
+		t.Errorf("expected no Unikraft version, got %s", got)
+	}
+}
+
+func TestExecuteFailsWithMissingKraftBinary(t *testing.T) {
+	d := &Datasource{}
+	if err := d.Configure(map[string]interface{}{
+		"workdir":      t.TempDir(),
+		"kraft_binary": filepath.Join(t.TempDir(), "kraft"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := d.Execute(); err == nil || !strings.Contains(err.Error(), "could not run") {
+		t.Errorf("expected a missing kraft_binary to fail, got %v", err)
+	}
+}
@@ -3,8 +3,19 @@ package main
 import (
 	"fmt"
 	"os"
+	importBuilder "packer-plugin-unikraft/builder/import"
 	unikraftBuilder "packer-plugin-unikraft/builder/unikraft"
+	catalogDS "packer-plugin-unikraft/datasource/catalog"
+	targetsDS "packer-plugin-unikraft/datasource/targets"
+	versionDS "packer-plugin-unikraft/datasource/version"
+	amiPP "packer-plugin-unikraft/post-processor/ami"
+	deployPP "packer-plugin-unikraft/post-processor/deploy"
+	kubernetesPP "packer-plugin-unikraft/post-processor/kubernetes"
+	pushPP "packer-plugin-unikraft/post-processor/push"
+	signPP "packer-plugin-unikraft/post-processor/sign"
+	storagePP "packer-plugin-unikraft/post-processor/storage"
 	unikraftPP "packer-plugin-unikraft/post-processor/unikraft"
+	runProvisioner "packer-plugin-unikraft/provisioner/run"
 	unikraftVersion "packer-plugin-unikraft/version"
 
 	"github.com/hashicorp/packer-plugin-sdk/plugin"
@@ -13,7 +24,18 @@ import (
 func main() {
 	pps := plugin.NewSet()
 	pps.RegisterBuilder("builder", new(unikraftBuilder.Builder))
+	pps.RegisterBuilder("import", new(importBuilder.Builder))
 	pps.RegisterPostProcessor("post-processor", new(unikraftPP.PostProcessor))
+	pps.RegisterPostProcessor("storage", new(storagePP.PostProcessor))
+	pps.RegisterPostProcessor("push", new(pushPP.PostProcessor))
+	pps.RegisterPostProcessor("sign", new(signPP.PostProcessor))
+	pps.RegisterPostProcessor("deploy", new(deployPP.PostProcessor))
+	pps.RegisterPostProcessor("ami", new(amiPP.PostProcessor))
+	pps.RegisterPostProcessor("kubernetes", new(kubernetesPP.PostProcessor))
+	pps.RegisterProvisioner("run", new(runProvisioner.Provisioner))
+	pps.RegisterDatasource("catalog", new(catalogDS.Datasource))
+	pps.RegisterDatasource("targets", new(targetsDS.Datasource))
+	pps.RegisterDatasource("version", new(versionDS.Datasource))
 	pps.SetVersion(unikraftVersion.PluginVersion)
 	err := pps.Run()
 	if err != nil {
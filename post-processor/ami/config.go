@@ -0,0 +1,118 @@
+//go:generate packer-sdc mapstructure-to-hcl2 -type Config
+
+package amipprocessor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/packer-plugin-sdk/common"
+	"github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/hashicorp/packer-plugin-sdk/template/config"
+	"github.com/hashicorp/packer-plugin-sdk/template/interpolate"
+	"github.com/mitchellh/mapstructure"
+)
+
+const BuilderId = "packer.post-processor.unikraft-ami"
+
+const (
+	// defaultRoleName is the service role EC2 imports snapshots with when
+	// none is configured.
+	defaultRoleName = "vmimport"
+	// defaultImportTimeout is how long to wait for a snapshot import when no
+	// timeout is configured.
+	defaultImportTimeout = time.Hour
+)
+
+// bootModes are the boot modes an AMI may be registered with.
+var bootModes = []string{"legacy-bios", "uefi", "uefi-preferred"}
+
+type Config struct {
+	common.PackerConfig `mapstructure:",squash"`
+
+	// The bucket the disk image is uploaded to before being imported. This
+	// is required.
+	Bucket string `mapstructure:"bucket" required:"true"`
+	// The prefix of the uploaded key.
+	Prefix string `mapstructure:"prefix"`
+	// The region the AMI is registered in.
+	Region string `mapstructure:"region"`
+	// The access key used to import. Defaults to the AWS credential chain.
+	AccessKey string `mapstructure:"access_key"`
+	// The secret key used to import.
+	SecretKey string `mapstructure:"secret_key"`
+	// The session token used to import.
+	SessionToken string `mapstructure:"session_token"`
+	// The name of the AMI. This is required.
+	AMIName string `mapstructure:"ami_name" required:"true"`
+	// The description of the AMI.
+	AMIDescription string `mapstructure:"ami_description"`
+	// Tags applied to the AMI and its snapshot.
+	Tags map[string]string `mapstructure:"tags"`
+	// The boot mode of the AMI: `legacy-bios`, `uefi` or `uefi-preferred`.
+	// Defaults to the one of the instance type.
+	BootMode string `mapstructure:"boot_mode"`
+	// The service role EC2 reads the uploaded image with. Defaults to
+	// `vmimport`.
+	RoleName string `mapstructure:"role_name"`
+	// How long to wait for the snapshot import. Defaults to 1h.
+	ImportTimeout time.Duration `mapstructure:"import_timeout"`
+	// Keep the uploaded image once imported.
+	SkipClean bool `mapstructure:"skip_clean"`
+
+	ctx interpolate.Context
+}
+
+func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
+	var md mapstructure.Metadata
+	err := config.Decode(c, &config.DecodeOpts{
+		Metadata:           &md,
+		PluginType:         BuilderId,
+		Interpolate:        true,
+		InterpolateContext: &c.ctx,
+	}, raws...)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.RoleName == "" {
+		c.RoleName = defaultRoleName
+	}
+	if c.ImportTimeout == 0 {
+		c.ImportTimeout = defaultImportTimeout
+	}
+
+	// Accumulate any errors
+	var errs *packer.MultiError
+	if c.Bucket == "" {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("bucket must be specified"))
+	}
+
+	if c.AMIName == "" {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("ami_name must be specified"))
+	}
+
+	if c.AccessKey != "" && c.SecretKey == "" {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("secret key must be specified with an access key"))
+	}
+
+	if c.BootMode != "" {
+		valid := false
+		for _, m := range bootModes {
+			valid = valid || c.BootMode == m
+		}
+		if !valid {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("unknown boot_mode %q, expected legacy-bios, uefi or uefi-preferred", c.BootMode))
+		}
+	}
+
+	if c.ImportTimeout < 0 {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("import_timeout must not be negative"))
+	}
+
+	if errs != nil && len(errs.Errors) > 0 {
+		return nil, errs
+	}
+
+	return nil, nil
+}
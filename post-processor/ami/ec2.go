@@ -0,0 +1,160 @@
+package amipprocessor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// EC2Importer imports disk images into EC2 through an S3 bucket.
+type EC2Importer struct {
+	Bucket string
+	// Region is the region the AMI is registered in.
+	Region string
+
+	s3       *s3.S3
+	uploader *s3manager.Uploader
+	ec2      *ec2.EC2
+}
+
+// NewEC2Importer returns an importer for the region and bucket of the
+// configuration.  The default AWS credential chain is used unless an access
+// key is configured.
+func NewEC2Importer(c *Config) (*EC2Importer, error) {
+	cfg := aws.NewConfig()
+	if c.Region != "" {
+		cfg = cfg.WithRegion(c.Region)
+	}
+	if c.AccessKey != "" {
+		cfg = cfg.WithCredentials(credentials.NewStaticCredentials(c.AccessKey, c.SecretKey, c.SessionToken))
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *cfg,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not create AWS session: %w", err)
+	}
+
+	return &EC2Importer{
+		Bucket:   c.Bucket,
+		Region:   aws.StringValue(sess.Config.Region),
+		s3:       s3.New(sess),
+		uploader: s3manager.NewUploader(sess),
+		ec2:      ec2.New(sess),
+	}, nil
+}
+
+func (i *EC2Importer) Upload(ctx context.Context, key string, r io.Reader) error {
+	_, err := i.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket: aws.String(i.Bucket),
+		Key:    aws.String(key),
+		Body:   r,
+	})
+	return err
+}
+
+func (i *EC2Importer) Delete(ctx context.Context, key string) error {
+	_, err := i.s3.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(i.Bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+func (i *EC2Importer) ImportSnapshot(ctx context.Context, req ImportSnapshotRequest) (string, error) {
+	out, err := i.ec2.ImportSnapshotWithContext(ctx, &ec2.ImportSnapshotInput{
+		Description: aws.String(req.Description),
+		RoleName:    aws.String(req.RoleName),
+		DiskContainer: &ec2.SnapshotDiskContainer{
+			Description: aws.String(req.Description),
+			Format:      aws.String(req.Format),
+			UserBucket: &ec2.UserBucket{
+				S3Bucket: aws.String(i.Bucket),
+				S3Key:    aws.String(req.Key),
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return aws.StringValue(out.ImportTaskId), nil
+}
+
+func (i *EC2Importer) SnapshotTask(ctx context.Context, taskID string) (*SnapshotTask, error) {
+	out, err := i.ec2.DescribeImportSnapshotTasksWithContext(ctx, &ec2.DescribeImportSnapshotTasksInput{
+		ImportTaskIds: []*string{aws.String(taskID)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(out.ImportSnapshotTasks) == 0 || out.ImportSnapshotTasks[0].SnapshotTaskDetail == nil {
+		return nil, fmt.Errorf("no import snapshot task %s", taskID)
+	}
+
+	detail := out.ImportSnapshotTasks[0].SnapshotTaskDetail
+	return &SnapshotTask{
+		Status:     aws.StringValue(detail.Status),
+		Message:    aws.StringValue(detail.StatusMessage),
+		Progress:   aws.StringValue(detail.Progress),
+		SnapshotID: aws.StringValue(detail.SnapshotId),
+	}, nil
+}
+
+func (i *EC2Importer) RegisterImage(ctx context.Context, req RegisterImageRequest) (string, error) {
+	in := &ec2.RegisterImageInput{
+		Name:               aws.String(req.Name),
+		Architecture:       aws.String(req.Architecture),
+		VirtualizationType: aws.String("hvm"),
+		RootDeviceName:     aws.String(rootDeviceName),
+		BlockDeviceMappings: []*ec2.BlockDeviceMapping{{
+			DeviceName: aws.String(rootDeviceName),
+			Ebs: &ec2.EbsBlockDevice{
+				SnapshotId:          aws.String(req.SnapshotID),
+				DeleteOnTermination: aws.Bool(true),
+			},
+		}},
+	}
+	if req.Description != "" {
+		in.Description = aws.String(req.Description)
+	}
+	if req.BootMode != "" {
+		in.BootMode = aws.String(req.BootMode)
+	}
+
+	out, err := i.ec2.RegisterImageWithContext(ctx, in)
+	if err != nil {
+		return "", err
+	}
+
+	return aws.StringValue(out.ImageId), nil
+}
+
+func (i *EC2Importer) CreateTags(ctx context.Context, resources []string, tags map[string]string) error {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	in := &ec2.CreateTagsInput{
+		Resources: aws.StringSlice(resources),
+	}
+	for _, k := range keys {
+		in.Tags = append(in.Tags, &ec2.Tag{Key: aws.String(k), Value: aws.String(tags[k])})
+	}
+
+	_, err := i.ec2.CreateTagsWithContext(ctx, in)
+	return err
+}
@@ -0,0 +1,291 @@
+package amipprocessor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	unikraft "packer-plugin-unikraft/builder/unikraft"
+	unikraftpprocessor "packer-plugin-unikraft/post-processor/unikraft"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/mitchellh/mapstructure"
+)
+
+const (
+	// rootDeviceName is the device the imported snapshot is attached as.
+	rootDeviceName = "/dev/xvda"
+	// defaultPollInterval is how often the snapshot import is polled.
+	defaultPollInterval = 15 * time.Second
+)
+
+// importFormats are the EC2 formats of the disk formats EC2 can import.
+var importFormats = map[string]string{
+	"raw":  "RAW",
+	"vmdk": "VMDK",
+}
+
+// amiArchitectures are the EC2 architectures of the Unikraft ones.
+var amiArchitectures = map[string]string{
+	"x86_64": "x86_64",
+	"arm64":  "arm64",
+}
+
+// ImportSnapshotRequest describes an uploaded disk image to import as an EBS
+// snapshot.
+type ImportSnapshotRequest struct {
+	Key         string
+	Format      string
+	RoleName    string
+	Description string
+}
+
+// SnapshotTask is the state of a snapshot import.
+type SnapshotTask struct {
+	Status     string
+	Message    string
+	Progress   string
+	SnapshotID string
+}
+
+// RegisterImageRequest describes an AMI booting from an imported snapshot.
+type RegisterImageRequest struct {
+	Name         string
+	Description  string
+	Architecture string
+	BootMode     string
+	SnapshotID   string
+}
+
+// Importer uploads disk images and registers them as AMIs.
+type Importer interface {
+	Upload(ctx context.Context, key string, r io.Reader) error
+	Delete(ctx context.Context, key string) error
+	ImportSnapshot(ctx context.Context, req ImportSnapshotRequest) (string, error)
+	SnapshotTask(ctx context.Context, taskID string) (*SnapshotTask, error)
+	RegisterImage(ctx context.Context, req RegisterImageRequest) (string, error)
+	CreateTags(ctx context.Context, resources []string, tags map[string]string) error
+}
+
+// PostProcessor imports the disk image of an artifact into EC2 as an AMI.
+type PostProcessor struct {
+	config Config
+
+	// importer overrides the EC2 importer.
+	importer Importer
+	// pollInterval overrides how often the snapshot import is polled.
+	pollInterval time.Duration
+}
+
+func (p *PostProcessor) ConfigSpec() hcldec.ObjectSpec { return p.config.FlatMapstructure().HCL2Spec() }
+
+func (p *PostProcessor) Configure(raws ...interface{}) error {
+	_, err := p.config.Prepare(raws...)
+	return err
+}
+
+func (p *PostProcessor) PostProcess(ctx context.Context, ui packersdk.Ui, source packersdk.Artifact) (packersdk.Artifact, bool, bool, error) {
+	disk, err := artifactDisk(source)
+	if err != nil {
+		ui.Error(err.Error())
+		return source, false, false, err
+	}
+
+	importer := p.importer
+	region := p.config.Region
+	if importer == nil {
+		ec2Importer, err := NewEC2Importer(&p.config)
+		if err != nil {
+			ui.Error(err.Error())
+			return source, false, false, err
+		}
+		importer = ec2Importer
+		region = ec2Importer.Region
+	}
+
+	f, err := os.Open(disk.Path)
+	if err != nil {
+		return nil, false, false, fmt.Errorf("import error: %s", err)
+	}
+	defer f.Close()
+
+	key := path.Join(p.config.Prefix, filepath.Base(disk.Path))
+	ui.Say(fmt.Sprintf("Uploading %s to %s/%s", disk.Path, p.config.Bucket, key))
+	if err := importer.Upload(ctx, key, f); err != nil {
+		return nil, false, false, fmt.Errorf("import error: %s", err)
+	}
+
+	if !p.config.SkipClean {
+		defer func() {
+			if err := importer.Delete(ctx, key); err != nil {
+				ui.Error(fmt.Sprintf("could not delete %s/%s: %s", p.config.Bucket, key, err))
+			}
+		}()
+	}
+
+	ui.Say(fmt.Sprintf("Importing %s as a snapshot", key))
+	taskID, err := importer.ImportSnapshot(ctx, ImportSnapshotRequest{
+		Key:         key,
+		Format:      disk.Format,
+		RoleName:    p.config.RoleName,
+		Description: p.config.AMIName,
+	})
+	if err != nil {
+		return nil, false, false, fmt.Errorf("import error: %s", err)
+	}
+
+	snapshotID, err := p.waitForSnapshot(ctx, ui, importer, taskID)
+	if err != nil {
+		return nil, false, false, fmt.Errorf("import error: %s", err)
+	}
+
+	ui.Say(fmt.Sprintf("Registering AMI %s from %s", p.config.AMIName, snapshotID))
+	amiID, err := importer.RegisterImage(ctx, RegisterImageRequest{
+		Name:         p.config.AMIName,
+		Description:  p.config.AMIDescription,
+		Architecture: disk.Architecture,
+		BootMode:     p.config.BootMode,
+		SnapshotID:   snapshotID,
+	})
+	if err != nil {
+		return nil, false, false, fmt.Errorf("import error: %s", err)
+	}
+
+	if err := importer.CreateTags(ctx, []string{amiID, snapshotID}, p.tags(disk)); err != nil {
+		return nil, false, false, fmt.Errorf("import error: %s", err)
+	}
+
+	ui.Say(fmt.Sprintf("Registered AMI %s", amiID))
+
+	artifact := &unikraft.Artifact{
+		StateData: map[string]interface{}{
+			"ami":         amiID,
+			"snapshot_id": snapshotID,
+			"region":      region,
+			"build_id":    source.Id(),
+		},
+	}
+	return artifact, true, true, nil
+}
+
+// waitForSnapshot polls the snapshot import task until it completes and
+// returns the ID of the imported snapshot.
+func (p *PostProcessor) waitForSnapshot(ctx context.Context, ui packersdk.Ui, importer Importer, taskID string) (string, error) {
+	interval := p.pollInterval
+	if interval == 0 {
+		interval = defaultPollInterval
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.config.ImportTimeout)
+	defer cancel()
+
+	progress := ""
+	for {
+		task, err := importer.SnapshotTask(ctx, taskID)
+		if err != nil {
+			return "", err
+		}
+
+		switch task.Status {
+		case "completed":
+			return task.SnapshotID, nil
+		case "deleting", "deleted":
+			return "", fmt.Errorf("snapshot import %s failed: %s", taskID, task.Message)
+		}
+
+		if task.Progress != "" && task.Progress != progress {
+			progress = task.Progress
+			ui.Message(fmt.Sprintf("Snapshot import %s: %s%%", taskID, progress))
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("snapshot import %s did not complete: %w", taskID, ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
+
+// tags returns the tags of the AMI and its snapshot: the configured ones
+// along with the target of the disk image.
+func (p *PostProcessor) tags(disk artifactDiskImage) map[string]string {
+	tags := map[string]string{
+		"unikraft:architecture": disk.TargetArchitecture,
+		"unikraft:platform":     disk.Platform,
+	}
+	for k, v := range p.config.Tags {
+		tags[k] = v
+	}
+
+	return tags
+}
+
+// artifactDiskImage is the disk image of an artifact to import.
+type artifactDiskImage struct {
+	Path string
+	// Format is the EC2 format of the image.
+	Format string
+	// Architecture is the EC2 architecture of the image.
+	Architecture string
+	// TargetArchitecture and Platform are the target of the kernel.
+	TargetArchitecture string
+	Platform           string
+}
+
+// artifactDisk returns the disk image of an artifact produced by the
+// unikraft post-processor with the disk format.
+func artifactDisk(source packersdk.Artifact) (artifactDiskImage, error) {
+	switch source.BuilderId() {
+	case unikraft.BuilderId, unikraftpprocessor.BuilderId:
+		break
+	default:
+		return artifactDiskImage{}, fmt.Errorf("unknown artifact %s", source.BuilderId())
+	}
+
+	var disks []string
+	if err := mapstructure.Decode(source.State("disks"), &disks); err != nil {
+		return artifactDiskImage{}, fmt.Errorf("failed to decode disks")
+	}
+
+	if len(disks) != 1 {
+		return artifactDiskImage{}, fmt.Errorf("artifact has %d disk images, expected one: package it with the disk format", len(disks))
+	}
+
+	diskFormat, _ := source.State("disk_format").(string)
+	if diskFormat == "" {
+		diskFormat = "raw"
+	}
+	format, ok := importFormats[diskFormat]
+	if !ok {
+		return artifactDiskImage{}, fmt.Errorf("EC2 cannot import %s disk images, expected raw or vmdk", diskFormat)
+	}
+
+	targets, err := unikraft.ArtifactTargets(source)
+	if err != nil {
+		return artifactDiskImage{}, err
+	}
+
+	// Disk images only boot qemu/x86_64 kernels.
+	target := unikraft.TargetArtifact{Architecture: "x86_64", Platform: "qemu"}
+	if len(targets) == 1 {
+		target = targets[0]
+	}
+
+	architecture, ok := amiArchitectures[target.Architecture]
+	if !ok {
+		return artifactDiskImage{}, fmt.Errorf("EC2 cannot boot %s disk images", target.Architecture)
+	}
+
+	return artifactDiskImage{
+		Path:               disks[0],
+		Format:             format,
+		Architecture:       architecture,
+		TargetArchitecture: target.Architecture,
+		Platform:           target.Platform,
+	}, nil
+}
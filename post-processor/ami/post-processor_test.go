@@ -0,0 +1,226 @@
+package amipprocessor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	unikraft "packer-plugin-unikraft/builder/unikraft"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+type fakeImporter struct {
+	objects    map[string][]byte
+	deleted    []string
+	imports    []ImportSnapshotRequest
+	registered []RegisterImageRequest
+	tagged     map[string]map[string]string
+
+	// tasks are the states the snapshot import goes through.
+	tasks []SnapshotTask
+}
+
+func (f *fakeImporter) Upload(_ context.Context, key string, r io.Reader) error {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	f.objects[key] = raw
+	return nil
+}
+
+func (f *fakeImporter) Delete(_ context.Context, key string) error {
+	f.deleted = append(f.deleted, key)
+	return nil
+}
+
+func (f *fakeImporter) ImportSnapshot(_ context.Context, req ImportSnapshotRequest) (string, error) {
+	f.imports = append(f.imports, req)
+	return "import-snap-1", nil
+}
+
+func (f *fakeImporter) SnapshotTask(_ context.Context, taskID string) (*SnapshotTask, error) {
+	if len(f.tasks) == 0 {
+		return nil, fmt.Errorf("no import snapshot task %s", taskID)
+	}
+	task := f.tasks[0]
+	if len(f.tasks) > 1 {
+		f.tasks = f.tasks[1:]
+	}
+	return &task, nil
+}
+
+func (f *fakeImporter) RegisterImage(_ context.Context, req RegisterImageRequest) (string, error) {
+	f.registered = append(f.registered, req)
+	return "ami-0123456789", nil
+}
+
+func (f *fakeImporter) CreateTags(_ context.Context, resources []string, tags map[string]string) error {
+	for _, r := range resources {
+		f.tagged[r] = tags
+	}
+	return nil
+}
+
+func testUi() *packersdk.BasicUi {
+	return &packersdk.BasicUi{
+		Reader: new(bytes.Buffer),
+		Writer: new(bytes.Buffer),
+	}
+}
+
+func diskArtifact(t *testing.T, diskFormat string) *unikraft.Artifact {
+	t.Helper()
+
+	disk := filepath.Join(t.TempDir(), "nginx.img")
+	if err := os.WriteFile(disk, []byte("disk"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return &unikraft.Artifact{
+		StateData: map[string]interface{}{
+			"packages":    []string{disk},
+			"format":      unikraft.FormatDisk,
+			"disks":       []string{disk},
+			"disk_format": diskFormat,
+			"targets": []map[string]string{
+				{"architecture": "x86_64", "platform": "qemu"},
+			},
+			"build_id": "3f2a",
+		},
+	}
+}
+
+func TestPostProcessImportsDisk(t *testing.T) {
+	importer := &fakeImporter{
+		objects: map[string][]byte{},
+		tagged:  map[string]map[string]string{},
+		tasks: []SnapshotTask{
+			{Status: "active", Progress: "20"},
+			{Status: "active", Progress: "80"},
+			{Status: "completed", SnapshotID: "snap-42"},
+		},
+	}
+	p := &PostProcessor{importer: importer, pollInterval: time.Millisecond}
+	err := p.Configure(map[string]interface{}{
+		"bucket":    "imports",
+		"prefix":    "nginx",
+		"region":    "eu-central-1",
+		"ami_name":  "nginx",
+		"boot_mode": "legacy-bios",
+		"tags":      map[string]string{"team": "web"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	artifact, keep, forceOverride, err := p.PostProcess(context.Background(), testUi(), diskArtifact(t, "vmdk"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !keep || !forceOverride {
+		t.Errorf("expected the artifact to be kept")
+	}
+
+	if string(importer.objects["nginx/nginx.img"]) != "disk" {
+		t.Errorf("disk image was not uploaded: %v", importer.objects)
+	}
+	if !reflect.DeepEqual(importer.deleted, []string{"nginx/nginx.img"}) {
+		t.Errorf("expected the upload to be deleted, got %v", importer.deleted)
+	}
+
+	wantImport := ImportSnapshotRequest{Key: "nginx/nginx.img", Format: "VMDK", RoleName: "vmimport", Description: "nginx"}
+	if !reflect.DeepEqual(importer.imports, []ImportSnapshotRequest{wantImport}) {
+		t.Errorf("imports = %+v, want %+v", importer.imports, wantImport)
+	}
+
+	wantRegister := RegisterImageRequest{Name: "nginx", Architecture: "x86_64", BootMode: "legacy-bios", SnapshotID: "snap-42"}
+	if !reflect.DeepEqual(importer.registered, []RegisterImageRequest{wantRegister}) {
+		t.Errorf("registered = %+v, want %+v", importer.registered, wantRegister)
+	}
+
+	wantTags := map[string]string{"unikraft:architecture": "x86_64", "unikraft:platform": "qemu", "team": "web"}
+	for _, id := range []string{"ami-0123456789", "snap-42"} {
+		if !reflect.DeepEqual(importer.tagged[id], wantTags) {
+			t.Errorf("tags of %s = %v, want %v", id, importer.tagged[id], wantTags)
+		}
+	}
+
+	if artifact.State("ami") != "ami-0123456789" || artifact.State("snapshot_id") != "snap-42" || artifact.State("region") != "eu-central-1" {
+		t.Errorf("unexpected artifact %s", artifact)
+	}
+}
+
+func TestPostProcessImportErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source packersdk.Artifact
+		tasks  []SnapshotTask
+		want   string
+	}{
+		{
+			name:   "oci package",
+			source: &unikraft.Artifact{StateData: map[string]interface{}{"format": "oci", "packages": []string{"nginx:latest"}}},
+			want:   "artifact has 0 disk images",
+		},
+		{
+			name:   "qcow2 disk",
+			source: diskArtifact(t, "qcow2"),
+			want:   "EC2 cannot import qcow2 disk images",
+		},
+		{
+			name:   "failed import",
+			source: diskArtifact(t, "raw"),
+			tasks:  []SnapshotTask{{Status: "deleted", Message: "ClientError: Unsupported kernel version"}},
+			want:   "Unsupported kernel version",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			importer := &fakeImporter{objects: map[string][]byte{}, tagged: map[string]map[string]string{}, tasks: tt.tasks}
+			p := &PostProcessor{importer: importer, pollInterval: time.Millisecond}
+			if err := p.Configure(map[string]interface{}{"bucket": "imports", "ami_name": "nginx"}); err != nil {
+				t.Fatal(err)
+			}
+
+			_, _, _, err := p.PostProcess(context.Background(), testUi(), tt.source)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+			if len(importer.registered) != 0 {
+				t.Errorf("expected no AMI to be registered, got %+v", importer.registered)
+			}
+		})
+	}
+}
+
+func TestConfigureValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  map[string]interface{}
+		want string
+	}{
+		{name: "missing bucket", raw: map[string]interface{}{"ami_name": "nginx"}, want: "bucket must be specified"},
+		{name: "missing name", raw: map[string]interface{}{"bucket": "imports"}, want: "ami_name must be specified"},
+		{name: "unknown boot mode", raw: map[string]interface{}{"bucket": "imports", "ami_name": "nginx", "boot_mode": "bios"}, want: `unknown boot_mode "bios"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p PostProcessor
+			err := p.Configure(tt.raw)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package deploypprocessor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Service exposes an internal port of an instance on its FQDN.
+type Service struct {
+	Port         int      `json:"port"`
+	InternalPort int      `json:"internal_port,omitempty"`
+	Handlers     []string `json:"handlers,omitempty"`
+}
+
+// ServiceGroup is the set of services of an instance.
+type ServiceGroup struct {
+	Services []Service `json:"services"`
+}
+
+// CreateInstanceRequest describes an instance to create.
+type CreateInstanceRequest struct {
+	Name         string            `json:"name,omitempty"`
+	Image        string            `json:"image"`
+	Args         []string          `json:"args,omitempty"`
+	Env          map[string]string `json:"env,omitempty"`
+	MemoryMB     int               `json:"memory_mb,omitempty"`
+	ServiceGroup *ServiceGroup     `json:"service_group,omitempty"`
+	Autostart    bool              `json:"autostart"`
+}
+
+// Instance is an instance of Unikraft Cloud.
+type Instance struct {
+	UUID      string `json:"uuid"`
+	Name      string `json:"name"`
+	FQDN      string `json:"fqdn"`
+	PrivateIP string `json:"private_ip"`
+	State     string `json:"state"`
+}
+
+// apiResponse is the envelope of every Unikraft Cloud API response.
+type apiResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Data    struct {
+		Instances []struct {
+			Instance
+			Status  string `json:"status"`
+			Message string `json:"message"`
+		} `json:"instances"`
+	} `json:"data"`
+}
+
+// Client talks to the instances API of Unikraft Cloud.
+type Client struct {
+	// BaseURL is the API endpoint of a metro, e.g.
+	// `https://api.fra0.kraft.cloud/v1`.
+	BaseURL string
+	// Token authenticates the requests.
+	Token string
+	// HTTPClient sends the requests.  Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// metroURL returns the API endpoint of a metro.
+func metroURL(metro string) string {
+	return fmt.Sprintf("https://api.%s.kraft.cloud/v1", metro)
+}
+
+// GetInstance returns the instance with the given name or UUID, or nil if
+// there is none.
+func (c *Client) GetInstance(ctx context.Context, nameOrUUID string) (*Instance, error) {
+	instances, status, err := c.do(ctx, http.MethodGet, "/instances/"+url.PathEscape(nameOrUUID), nil)
+	if status == http.StatusNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if len(instances) == 0 {
+		return nil, nil
+	}
+
+	return &instances[0], nil
+}
+
+// DeleteInstance stops and removes the instance with the given UUID.
+func (c *Client) DeleteInstance(ctx context.Context, uuid string) error {
+	_, _, err := c.do(ctx, http.MethodDelete, "/instances/"+url.PathEscape(uuid), nil)
+	return err
+}
+
+// CreateInstance creates an instance.
+func (c *Client) CreateInstance(ctx context.Context, req CreateInstanceRequest) (*Instance, error) {
+	instances, _, err := c.do(ctx, http.MethodPost, "/instances", req)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(instances) == 0 {
+		return nil, fmt.Errorf("no instance was created")
+	}
+
+	return &instances[0], nil
+}
+
+// do sends a request to the API and returns the instances of its response,
+// along with the HTTP status code.
+func (c *Client) do(ctx context.Context, method, path string, body interface{}) ([]Instance, int, error) {
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return nil, 0, err
+		}
+		reader = bytes.NewReader(raw)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.BaseURL, "/")+path, reader)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	var decoded apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil && err != io.EOF {
+		return nil, resp.StatusCode, fmt.Errorf("%s %s: invalid response (%s): %w", method, path, resp.Status, err)
+	}
+
+	if resp.StatusCode >= 300 || decoded.Status == "error" {
+		msg := decoded.Message
+		if msg == "" {
+			msg = resp.Status
+		}
+		return nil, resp.StatusCode, fmt.Errorf("%s %s: %s", method, path, msg)
+	}
+
+	var instances []Instance
+	for _, i := range decoded.Data.Instances {
+		if i.Status != "" && i.Status != "success" {
+			return nil, resp.StatusCode, fmt.Errorf("%s %s: %s", method, path, i.Message)
+		}
+
+		instances = append(instances, i.Instance)
+	}
+
+	return instances, resp.StatusCode, nil
+}
@@ -0,0 +1,73 @@
+package deploypprocessor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClientCreateInstance(t *testing.T) {
+	var got CreateInstanceRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/instances" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer secret" {
+			t.Errorf("Authorization = %q", auth)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+
+		w.Write([]byte(`{"status":"success","data":{"instances":[{"status":"success","uuid":"6c9ec9d3","name":"nginx","fqdn":"nginx.fra0.kraft.host"}]}}`))
+	}))
+	defer server.Close()
+
+	c := &Client{BaseURL: server.URL + "/v1", Token: "secret"}
+	instance, err := c.CreateInstance(context.Background(), CreateInstanceRequest{
+		Name:      "nginx",
+		Image:     "nginx:latest",
+		MemoryMB:  64,
+		Autostart: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if instance.UUID != "6c9ec9d3" || instance.FQDN != "nginx.fra0.kraft.host" {
+		t.Errorf("unexpected instance %+v", instance)
+	}
+	if got.Image != "nginx:latest" || got.MemoryMB != 64 || !got.Autostart {
+		t.Errorf("unexpected request %+v", got)
+	}
+}
+
+func TestClientGetMissingInstance(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"status":"error","message":"instance not found"}`))
+	}))
+	defer server.Close()
+
+	c := &Client{BaseURL: server.URL, Token: "secret"}
+	instance, err := c.GetInstance(context.Background(), "nginx")
+	if err != nil || instance != nil {
+		t.Errorf("expected no instance, got %+v, %v", instance, err)
+	}
+}
+
+func TestClientReportsInstanceErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"partial_success","data":{"instances":[{"status":"error","message":"image not found"}]}}`))
+	}))
+	defer server.Close()
+
+	c := &Client{BaseURL: server.URL, Token: "secret"}
+	_, err := c.CreateInstance(context.Background(), CreateInstanceRequest{Image: "missing:latest"})
+	if err == nil || !strings.Contains(err.Error(), "image not found") {
+		t.Errorf("expected the API error, got %v", err)
+	}
+}
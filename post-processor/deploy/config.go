@@ -0,0 +1,155 @@
+//go:generate packer-sdc mapstructure-to-hcl2 -type Config,PortConfig
+
+package deploypprocessor
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/packer-plugin-sdk/common"
+	"github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/hashicorp/packer-plugin-sdk/template/config"
+	"github.com/hashicorp/packer-plugin-sdk/template/interpolate"
+	"github.com/mitchellh/mapstructure"
+)
+
+const BuilderId = "packer.post-processor.unikraft-deploy"
+
+const (
+	// envToken is the environment variable the API token is read from when
+	// none is configured.
+	envToken = "UKC_TOKEN"
+	// envMetro is the environment variable the metro is read from when none
+	// is configured.
+	envMetro = "UKC_METRO"
+	// defaultMetro is the metro instances are deployed to by default.
+	defaultMetro = "fra0"
+)
+
+// portHandlers are the handlers a port may be served with.
+var portHandlers = []string{"tls", "http", "redirect"}
+
+type Config struct {
+	common.PackerConfig `mapstructure:",squash"`
+
+	// The token authenticating against Unikraft Cloud. Defaults to the
+	// `UKC_TOKEN` environment variable.
+	Token string `mapstructure:"token"`
+	// The metro to deploy to. Defaults to the `UKC_METRO` environment
+	// variable, then `fra0`.
+	Metro string `mapstructure:"metro"`
+	// The API endpoint to deploy to, instead of the one of the metro.
+	Endpoint string `mapstructure:"endpoint"`
+	// The name of the instance. An existing instance with this name is
+	// replaced.
+	Name string `mapstructure:"name"`
+	// The memory of the instance in MiB.
+	Memory int `mapstructure:"memory"`
+	// The arguments of the unikernel.
+	Args []string `mapstructure:"args"`
+	// The environment variables of the unikernel.
+	Env map[string]string `mapstructure:"env"`
+	// The ports the instance is reachable on.
+	Ports []PortConfig `mapstructure:"port"`
+	// Do not start the instance once created.
+	NoStart bool `mapstructure:"no_start"`
+
+	ctx interpolate.Context
+}
+
+// PortConfig exposes an internal port of the instance.
+type PortConfig struct {
+	// The public port. This is required.
+	Port int `mapstructure:"port" required:"true"`
+	// The port the unikernel listens on. Defaults to port.
+	InternalPort int `mapstructure:"internal_port"`
+	// The handlers of the port: `tls`, `http` or `redirect`.
+	Handlers []string `mapstructure:"handlers"`
+}
+
+func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
+	var md mapstructure.Metadata
+	err := config.Decode(c, &config.DecodeOpts{
+		Metadata:           &md,
+		PluginType:         BuilderId,
+		Interpolate:        true,
+		InterpolateContext: &c.ctx,
+	}, raws...)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Token == "" {
+		c.Token = os.Getenv(envToken)
+	}
+
+	if c.Metro == "" {
+		c.Metro = os.Getenv(envMetro)
+	}
+	if c.Metro == "" {
+		c.Metro = defaultMetro
+	}
+
+	if c.Endpoint == "" {
+		c.Endpoint = metroURL(c.Metro)
+	}
+
+	// Accumulate any errors
+	var errs *packer.MultiError
+	if c.Token == "" {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("token must be specified, or set in %s", envToken))
+	}
+
+	if c.Memory < 0 {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("memory must not be negative"))
+	}
+
+	for i, p := range c.Ports {
+		if p.Port < 1 || p.Port > 65535 {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("port[%d]: invalid port %d", i, p.Port))
+		}
+
+		if p.InternalPort < 0 || p.InternalPort > 65535 {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("port[%d]: invalid internal_port %d", i, p.InternalPort))
+		}
+
+		for _, h := range p.Handlers {
+			valid := false
+			for _, known := range portHandlers {
+				valid = valid || h == known
+			}
+			if !valid {
+				errs = packer.MultiErrorAppend(errs, fmt.Errorf("port[%d]: unknown handler %q, expected tls, http or redirect", i, h))
+			}
+		}
+	}
+
+	if errs != nil && len(errs.Errors) > 0 {
+		return nil, errs
+	}
+
+	return nil, nil
+}
+
+// serviceGroup returns the services of the instance, if it exposes any port.
+func (c *Config) serviceGroup() *ServiceGroup {
+	if len(c.Ports) == 0 {
+		return nil
+	}
+
+	group := &ServiceGroup{}
+	for _, p := range c.Ports {
+		internal := p.InternalPort
+		if internal == 0 {
+			internal = p.Port
+		}
+
+		group.Services = append(group.Services, Service{
+			Port:         p.Port,
+			InternalPort: internal,
+			Handlers:     p.Handlers,
+		})
+	}
+
+	return group
+}
@@ -0,0 +1,113 @@
+package deploypprocessor
+
+import (
+	"context"
+	"fmt"
+	unikraft "packer-plugin-unikraft/builder/unikraft"
+	unikraftpprocessor "packer-plugin-unikraft/post-processor/unikraft"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+// Deployer manages the instances of Unikraft Cloud.
+type Deployer interface {
+	GetInstance(ctx context.Context, nameOrUUID string) (*Instance, error)
+	DeleteInstance(ctx context.Context, uuid string) error
+	CreateInstance(ctx context.Context, req CreateInstanceRequest) (*Instance, error)
+}
+
+// PostProcessor deploys the package of an artifact to Unikraft Cloud.
+type PostProcessor struct {
+	config Config
+
+	// deployer overrides the Unikraft Cloud API client.
+	deployer Deployer
+}
+
+func (p *PostProcessor) ConfigSpec() hcldec.ObjectSpec { return p.config.FlatMapstructure().HCL2Spec() }
+
+func (p *PostProcessor) Configure(raws ...interface{}) error {
+	_, err := p.config.Prepare(raws...)
+	return err
+}
+
+func (p *PostProcessor) PostProcess(ctx context.Context, ui packersdk.Ui, source packersdk.Artifact) (packersdk.Artifact, bool, bool, error) {
+	image, err := artifactImage(source)
+	if err != nil {
+		ui.Error(err.Error())
+		return source, false, false, err
+	}
+
+	deployer := p.deployer
+	if deployer == nil {
+		deployer = &Client{
+			BaseURL: p.config.Endpoint,
+			Token:   p.config.Token,
+		}
+	}
+
+	// Instances cannot change their image, so an existing instance of the
+	// same name is replaced.
+	if p.config.Name != "" {
+		existing, err := deployer.GetInstance(ctx, p.config.Name)
+		if err != nil {
+			return nil, false, false, fmt.Errorf("deploy error: %s", err)
+		}
+
+		if existing != nil {
+			ui.Say(fmt.Sprintf("Replacing instance %s (%s)", existing.Name, existing.UUID))
+			if err := deployer.DeleteInstance(ctx, existing.UUID); err != nil {
+				return nil, false, false, fmt.Errorf("deploy error: %s", err)
+			}
+		}
+	}
+
+	ui.Say(fmt.Sprintf("Deploying %s to %s", image, p.config.Metro))
+	instance, err := deployer.CreateInstance(ctx, CreateInstanceRequest{
+		Name:         p.config.Name,
+		Image:        image,
+		Args:         p.config.Args,
+		Env:          p.config.Env,
+		MemoryMB:     p.config.Memory,
+		ServiceGroup: p.config.serviceGroup(),
+		Autostart:    !p.config.NoStart,
+	})
+	if err != nil {
+		return nil, false, false, fmt.Errorf("deploy error: %s", err)
+	}
+
+	if instance.FQDN != "" {
+		ui.Say(fmt.Sprintf("Deployed instance %s (%s) at %s", instance.Name, instance.UUID, instance.FQDN))
+	} else {
+		ui.Say(fmt.Sprintf("Deployed instance %s (%s)", instance.Name, instance.UUID))
+	}
+
+	artifact := &unikraft.Artifact{
+		StateData: map[string]interface{}{
+			"packages":      []string{image},
+			"build_id":      source.Id(),
+			"instance_uuid": instance.UUID,
+			"instance_name": instance.Name,
+			"instance_fqdn": instance.FQDN,
+			"metro":         p.config.Metro,
+		},
+	}
+	return artifact, true, true, nil
+}
+
+// artifactImage returns the first package of an artifact produced by the
+// unikraft or push post-processors.
+func artifactImage(source packersdk.Artifact) (string, error) {
+	packages, err := unikraftpprocessor.ArtifactPackages(source, "deployed")
+	if err != nil {
+		return "", err
+	}
+
+	// The push post-processor lists the pushed package before its tags.
+	if len(packages) == 0 {
+		return "", fmt.Errorf("artifact has no package to deploy")
+	}
+
+	return packages[0], nil
+}
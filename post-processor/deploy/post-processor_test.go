@@ -0,0 +1,171 @@
+package deploypprocessor
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	unikraft "packer-plugin-unikraft/builder/unikraft"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+type fakeDeployer struct {
+	instances map[string]Instance
+	deleted   []string
+	created   []CreateInstanceRequest
+}
+
+func (f *fakeDeployer) GetInstance(_ context.Context, name string) (*Instance, error) {
+	if i, ok := f.instances[name]; ok {
+		return &i, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeDeployer) DeleteInstance(_ context.Context, uuid string) error {
+	f.deleted = append(f.deleted, uuid)
+	return nil
+}
+
+func (f *fakeDeployer) CreateInstance(_ context.Context, req CreateInstanceRequest) (*Instance, error) {
+	f.created = append(f.created, req)
+	return &Instance{UUID: "6c9ec9d3", Name: req.Name, FQDN: req.Name + ".fra0.kraft.host"}, nil
+}
+
+func testUi() *packersdk.BasicUi {
+	return &packersdk.BasicUi{
+		Reader: new(bytes.Buffer),
+		Writer: new(bytes.Buffer),
+	}
+}
+
+func TestPostProcessDeploysPackage(t *testing.T) {
+	deployer := &fakeDeployer{}
+	p := &PostProcessor{deployer: deployer}
+	err := p.Configure(map[string]interface{}{
+		"token":  "secret",
+		"name":   "nginx",
+		"memory": 64,
+		"port": []map[string]interface{}{
+			{"port": 443, "internal_port": 8080, "handlers": []string{"tls", "http"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source := &unikraft.Artifact{
+		StateData: map[string]interface{}{
+			"packages": []string{"index.unikraft.io/user/nginx:latest", "index.unikraft.io/user/nginx:1.25"},
+		},
+	}
+
+	artifact, keep, _, err := p.PostProcess(context.Background(), testUi(), source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !keep {
+		t.Error("expected the artifact to be kept")
+	}
+
+	if len(deployer.created) != 1 {
+		t.Fatalf("expected one instance, got %d", len(deployer.created))
+	}
+	req := deployer.created[0]
+	if req.Image != "index.unikraft.io/user/nginx:latest" || req.MemoryMB != 64 || !req.Autostart {
+		t.Errorf("unexpected request %+v", req)
+	}
+	wantServices := &ServiceGroup{Services: []Service{{Port: 443, InternalPort: 8080, Handlers: []string{"tls", "http"}}}}
+	if !reflect.DeepEqual(req.ServiceGroup, wantServices) {
+		t.Errorf("service group %+v, want %+v", req.ServiceGroup, wantServices)
+	}
+
+	if got := artifact.State("instance_uuid"); got != "6c9ec9d3" {
+		t.Errorf("instance_uuid = %v", got)
+	}
+	if got := artifact.State("instance_fqdn"); got != "nginx.fra0.kraft.host" {
+		t.Errorf("instance_fqdn = %v", got)
+	}
+}
+
+func TestPostProcessReplacesInstance(t *testing.T) {
+	deployer := &fakeDeployer{instances: map[string]Instance{
+		"nginx": {UUID: "1f0c2a7e", Name: "nginx"},
+	}}
+	p := &PostProcessor{deployer: deployer}
+	if err := p.Configure(map[string]interface{}{"token": "secret", "name": "nginx"}); err != nil {
+		t.Fatal(err)
+	}
+
+	source := &unikraft.Artifact{
+		StateData: map[string]interface{}{"oci": "index.unikraft.io/user/nginx:latest"},
+	}
+	if _, _, _, err := p.PostProcess(context.Background(), testUi(), source); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(deployer.deleted, []string{"1f0c2a7e"}) {
+		t.Errorf("deleted %v, want the existing instance", deployer.deleted)
+	}
+}
+
+func TestPostProcessWithoutPackage(t *testing.T) {
+	p := &PostProcessor{deployer: &fakeDeployer{}}
+	if err := p.Configure(map[string]interface{}{"token": "secret"}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, _, err := p.PostProcess(context.Background(), testUi(), &unikraft.Artifact{StateData: map[string]interface{}{}})
+	if err == nil || !strings.Contains(err.Error(), "no package to deploy") {
+		t.Errorf("expected a missing package error, got %v", err)
+	}
+}
+
+func TestConfigPrepare(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  map[string]interface{}
+		want string
+	}{
+		{name: "token", raw: map[string]interface{}{"token": "secret"}},
+		{name: "no token", raw: map[string]interface{}{}, want: "token must be specified"},
+		{name: "invalid port", raw: map[string]interface{}{
+			"token": "secret",
+			"port":  []map[string]interface{}{{"port": 70000}},
+		}, want: "invalid port 70000"},
+		{name: "unknown handler", raw: map[string]interface{}{
+			"token": "secret",
+			"port":  []map[string]interface{}{{"port": 443, "handlers": []string{"grpc"}}},
+		}, want: `unknown handler "grpc"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(envToken, "")
+
+			var c Config
+			_, err := c.Prepare(tt.raw)
+			if tt.want == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestConfigPrepareMetro(t *testing.T) {
+	t.Setenv(envMetro, "")
+
+	var c Config
+	if _, err := c.Prepare(map[string]interface{}{"token": "secret"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Endpoint != "https://api.fra0.kraft.cloud/v1" {
+		t.Errorf("Endpoint = %q", c.Endpoint)
+	}
+}
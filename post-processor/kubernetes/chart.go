@@ -0,0 +1,128 @@
+package kubernetespprocessor
+
+import "path/filepath"
+
+// chartDeployment is the template of the Deployment of the chart.
+const chartDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Release.Name }}
+  labels:
+    app.kubernetes.io/instance: {{ .Release.Name }}
+    {{- toYaml .Values.labels | nindent 4 }}
+spec:
+  replicas: {{ .Values.replicaCount }}
+  selector:
+    matchLabels:
+      app.kubernetes.io/instance: {{ .Release.Name }}
+      {{- toYaml .Values.labels | nindent 6 }}
+  template:
+    metadata:
+      labels:
+        app.kubernetes.io/instance: {{ .Release.Name }}
+        {{- toYaml .Values.labels | nindent 8 }}
+    spec:
+      runtimeClassName: {{ .Values.runtimeClassName }}
+      {{- with .Values.nodeSelector }}
+      nodeSelector:
+        {{- toYaml . | nindent 8 }}
+      {{- end }}
+      containers:
+        - name: {{ .Chart.Name }}
+          image: {{ .Values.image }}
+          resources:
+            {{- toYaml .Values.resources | nindent 12 }}
+          {{- with .Values.ports }}
+          ports:
+            {{- range . }}
+            - containerPort: {{ . }}
+            {{- end }}
+          {{- end }}
+`
+
+// chartService is the template of the Service of the chart, rendered when the
+// unikernel listens on ports.
+const chartService = `{{- if .Values.ports }}
+apiVersion: v1
+kind: Service
+metadata:
+  name: {{ .Release.Name }}
+  labels:
+    app.kubernetes.io/instance: {{ .Release.Name }}
+    {{- toYaml .Values.labels | nindent 4 }}
+spec:
+  selector:
+    app.kubernetes.io/instance: {{ .Release.Name }}
+    {{- toYaml .Values.labels | nindent 4 }}
+  ports:
+    {{- range .Values.ports }}
+    - name: port-{{ . }}
+      port: {{ . }}
+      targetPort: {{ . }}
+    {{- end }}
+{{- end }}
+`
+
+type chartMetadata struct {
+	APIVersion  string `yaml:"apiVersion"`
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Type        string `yaml:"type"`
+	Version     string `yaml:"version"`
+	AppVersion  string `yaml:"appVersion,omitempty"`
+}
+
+type chartValues struct {
+	ReplicaCount     int               `yaml:"replicaCount"`
+	Image            string            `yaml:"image"`
+	RuntimeClassName string            `yaml:"runtimeClassName"`
+	Labels           map[string]string `yaml:"labels"`
+	NodeSelector     map[string]string `yaml:"nodeSelector"`
+	Resources        resources         `yaml:"resources"`
+	Ports            []int             `yaml:"ports"`
+}
+
+// Chart returns the files of the chart of w, keyed by their path in the
+// chart.  The defaults of the values are those of w.
+func (w *workload) Chart(version, appVersion string) (map[string][]byte, error) {
+	metadata, err := encodeYAML(chartMetadata{
+		APIVersion:  "v2",
+		Name:        w.Name,
+		Description: "The " + w.Name + " unikernel.",
+		Type:        "application",
+		Version:     version,
+		AppVersion:  appVersion,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	nodeSelector := w.NodeSelector
+	if nodeSelector == nil {
+		nodeSelector = map[string]string{}
+	}
+	ports := w.Ports
+	if ports == nil {
+		ports = []int{}
+	}
+
+	values, err := encodeYAML(chartValues{
+		ReplicaCount:     w.Replicas,
+		Image:            w.Image,
+		RuntimeClassName: w.RuntimeClass,
+		Labels:           w.labels(),
+		NodeSelector:     nodeSelector,
+		Resources:        w.resources(),
+		Ports:            ports,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string][]byte{
+		"Chart.yaml":  metadata,
+		"values.yaml": values,
+		filepath.Join("templates", "deployment.yaml"): []byte(chartDeployment),
+		filepath.Join("templates", "service.yaml"):    []byte(chartService),
+	}, nil
+}
@@ -0,0 +1,166 @@
+//go:generate packer-sdc mapstructure-to-hcl2 -type Config
+
+package kubernetespprocessor
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/packer-plugin-sdk/common"
+	"github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/hashicorp/packer-plugin-sdk/template/config"
+	"github.com/hashicorp/packer-plugin-sdk/template/interpolate"
+	"github.com/mitchellh/mapstructure"
+)
+
+const BuilderId = "packer.post-processor.unikraft-kubernetes"
+
+const (
+	// FormatManifest writes a manifest ready to be applied with kubectl.
+	FormatManifest = "manifest"
+	// FormatHelm writes a Helm chart.
+	FormatHelm = "helm"
+)
+
+const (
+	// DefaultOutputDir is the directory the manifest or chart is written to
+	// when output_dir is not set.
+	DefaultOutputDir = "kubernetes"
+	// DefaultRuntimeClass is the RuntimeClass running the pods when
+	// runtime_class is not set, the one of the runu runtime of KraftKit.
+	DefaultRuntimeClass = "runu"
+	// DefaultMemory is the memory of the unikernels when memory is not set,
+	// the default of kraft run.
+	DefaultMemory = "64Mi"
+	// DefaultChartVersion is the version of the chart when chart_version is
+	// not set.
+	DefaultChartVersion = "0.1.0"
+)
+
+var (
+	// namePattern matches the names of Kubernetes objects, DNS labels.
+	namePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$`)
+	// quantityPattern matches the resource quantities of Kubernetes.
+	quantityPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?(m|k|M|G|T|Ki|Mi|Gi|Ti)?$`)
+)
+
+type Config struct {
+	common.PackerConfig `mapstructure:",squash"`
+
+	// What to write: `manifest`, a Deployment and a Service ready to be
+	// applied, or `helm`, a chart. Defaults to `manifest`.
+	Format string `mapstructure:"format"`
+	// The directory the manifest or chart is written to. Defaults to
+	// `kubernetes`.
+	OutputDir string `mapstructure:"output_dir"`
+	// The name of the objects and of the chart. Defaults to the name of the
+	// repository of the image.
+	Name string `mapstructure:"name"`
+	// The namespace of the objects of the manifest.
+	Namespace string `mapstructure:"namespace"`
+	// The image the pods run. Defaults to the first package of the artifact.
+	Image string `mapstructure:"image"`
+	// The RuntimeClass running the pods as unikernels, e.g. `urunc`.
+	// Defaults to `runu`.
+	RuntimeClass string `mapstructure:"runtime_class"`
+	// The number of pods. Defaults to 1.
+	Replicas int `mapstructure:"replicas"`
+	// The ports the unikernel listens on, exposed by a Service.
+	Ports []int `mapstructure:"ports"`
+	// The CPU of the pods. Defaults to the CPUs brought up by the kernel, as
+	// configured by its KConfig.
+	CPU string `mapstructure:"cpu"`
+	// The memory of the pods. Defaults to `64Mi`.
+	Memory string `mapstructure:"memory"`
+	// Labels added to the objects and selecting the pods.
+	Labels map[string]string `mapstructure:"labels"`
+	// The version of the chart. Defaults to `0.1.0`.
+	ChartVersion string `mapstructure:"chart_version"`
+
+	ctx interpolate.Context
+}
+
+func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
+	var md mapstructure.Metadata
+	err := config.Decode(c, &config.DecodeOpts{
+		Metadata:           &md,
+		PluginType:         BuilderId,
+		Interpolate:        true,
+		InterpolateContext: &c.ctx,
+	}, raws...)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Format == "" {
+		c.Format = FormatManifest
+	}
+
+	if c.OutputDir == "" {
+		c.OutputDir = DefaultOutputDir
+	}
+
+	if c.RuntimeClass == "" {
+		c.RuntimeClass = DefaultRuntimeClass
+	}
+
+	if c.Memory == "" {
+		c.Memory = DefaultMemory
+	}
+
+	// Accumulate any errors
+	var errs *packer.MultiError
+	if c.Format != FormatManifest && c.Format != FormatHelm {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("unknown format %q, expected %s or %s", c.Format, FormatManifest, FormatHelm))
+	}
+
+	if c.Name != "" && !namePattern.MatchString(c.Name) {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("invalid name %q, expected a DNS label", c.Name))
+	}
+
+	if c.Format == FormatHelm && c.Namespace != "" {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("namespace cannot be combined with the helm format, the namespace of the release applies"))
+	}
+
+	if c.Format != FormatHelm && c.ChartVersion != "" {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("chart_version requires the helm format"))
+	}
+
+	if c.Replicas < 0 {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("replicas must not be negative"))
+	}
+
+	for _, port := range c.Ports {
+		if port < 1 || port > 65535 {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("invalid port %d", port))
+		}
+	}
+
+	if c.CPU != "" && !quantityPattern.MatchString(c.CPU) {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("invalid cpu %q", c.CPU))
+	}
+
+	if !quantityPattern.MatchString(c.Memory) {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("invalid memory %q", c.Memory))
+	}
+
+	for k := range c.Labels {
+		if k == "" {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("invalid label %q", k))
+		}
+	}
+
+	if errs != nil && len(errs.Errors) > 0 {
+		return nil, errs
+	}
+
+	if c.Replicas == 0 {
+		c.Replicas = 1
+	}
+
+	if c.ChartVersion == "" {
+		c.ChartVersion = DefaultChartVersion
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,171 @@
+package kubernetespprocessor
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// nameLabel is the label naming the unikernel, selecting its pods.
+const nameLabel = "app.kubernetes.io/name"
+
+// workload describes the unikernel to deploy, which both the manifest and the
+// values of the chart are made of.
+type workload struct {
+	Name         string
+	Namespace    string
+	Image        string
+	RuntimeClass string
+	Replicas     int
+	Ports        []int
+	Labels       map[string]string
+	NodeSelector map[string]string
+	CPU          string
+	Memory       string
+}
+
+type object struct {
+	APIVersion string      `yaml:"apiVersion"`
+	Kind       string      `yaml:"kind"`
+	Metadata   objectMeta  `yaml:"metadata"`
+	Spec       interface{} `yaml:"spec"`
+}
+
+type objectMeta struct {
+	Name      string            `yaml:"name,omitempty"`
+	Namespace string            `yaml:"namespace,omitempty"`
+	Labels    map[string]string `yaml:"labels,omitempty"`
+}
+
+type deploymentSpec struct {
+	Replicas int           `yaml:"replicas"`
+	Selector labelSelector `yaml:"selector"`
+	Template podTemplate   `yaml:"template"`
+}
+
+type labelSelector struct {
+	MatchLabels map[string]string `yaml:"matchLabels"`
+}
+
+type podTemplate struct {
+	Metadata objectMeta `yaml:"metadata"`
+	Spec     podSpec    `yaml:"spec"`
+}
+
+type podSpec struct {
+	RuntimeClassName string            `yaml:"runtimeClassName"`
+	NodeSelector     map[string]string `yaml:"nodeSelector,omitempty"`
+	Containers       []container       `yaml:"containers"`
+}
+
+type container struct {
+	Name      string          `yaml:"name"`
+	Image     string          `yaml:"image"`
+	Resources resources       `yaml:"resources"`
+	Ports     []containerPort `yaml:"ports,omitempty"`
+}
+
+// resources are the requests and limits of a container.  Both are the same,
+// as the memory and CPUs of a unikernel are fixed when it boots.
+type resources struct {
+	Requests map[string]string `yaml:"requests"`
+	Limits   map[string]string `yaml:"limits"`
+}
+
+type containerPort struct {
+	ContainerPort int `yaml:"containerPort"`
+}
+
+type serviceSpec struct {
+	Selector map[string]string `yaml:"selector"`
+	Ports    []servicePort     `yaml:"ports"`
+}
+
+type servicePort struct {
+	Name       string `yaml:"name"`
+	Port       int    `yaml:"port"`
+	TargetPort int    `yaml:"targetPort"`
+}
+
+// labels returns the labels of the objects of w.
+func (w *workload) labels() map[string]string {
+	labels := map[string]string{nameLabel: w.Name}
+	for k, v := range w.Labels {
+		labels[k] = v
+	}
+
+	return labels
+}
+
+func (w *workload) resources() resources {
+	quantities := map[string]string{"cpu": w.CPU, "memory": w.Memory}
+	return resources{Requests: quantities, Limits: quantities}
+}
+
+// Manifest returns the Deployment of w, followed by its Service when it
+// listens on ports.
+func (w *workload) Manifest() ([]byte, error) {
+	labels := w.labels()
+
+	var ports []containerPort
+	var servicePorts []servicePort
+	for _, port := range w.Ports {
+		ports = append(ports, containerPort{ContainerPort: port})
+		servicePorts = append(servicePorts, servicePort{
+			Name:       fmt.Sprintf("port-%d", port),
+			Port:       port,
+			TargetPort: port,
+		})
+	}
+
+	objects := []object{{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Metadata:   objectMeta{Name: w.Name, Namespace: w.Namespace, Labels: labels},
+		Spec: deploymentSpec{
+			Replicas: w.Replicas,
+			Selector: labelSelector{MatchLabels: labels},
+			Template: podTemplate{
+				Metadata: objectMeta{Labels: labels},
+				Spec: podSpec{
+					RuntimeClassName: w.RuntimeClass,
+					NodeSelector:     w.NodeSelector,
+					Containers: []container{{
+						Name:      w.Name,
+						Image:     w.Image,
+						Resources: w.resources(),
+						Ports:     ports,
+					}},
+				},
+			},
+		},
+	}}
+	if len(servicePorts) > 0 {
+		objects = append(objects, object{
+			APIVersion: "v1",
+			Kind:       "Service",
+			Metadata:   objectMeta{Name: w.Name, Namespace: w.Namespace, Labels: labels},
+			Spec:       serviceSpec{Selector: labels, Ports: servicePorts},
+		})
+	}
+
+	return encodeYAML(objects...)
+}
+
+// encodeYAML encodes docs as a stream of YAML documents.
+func encodeYAML[T any](docs ...T) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	for _, doc := range docs {
+		if err := enc.Encode(doc); err != nil {
+			return nil, err
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,205 @@
+package kubernetespprocessor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	unikraft "packer-plugin-unikraft/builder/unikraft"
+	unikraftpprocessor "packer-plugin-unikraft/post-processor/unikraft"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+// nodeArchitectures maps the architectures of Unikraft to the
+// `kubernetes.io/arch` label of the nodes running them.
+var nodeArchitectures = map[string]string{
+	"x86_64": "amd64",
+	"arm64":  "arm64",
+	"arm":    "arm",
+}
+
+// invalidNameChars matches the characters not allowed in the names of
+// Kubernetes objects.
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// PostProcessor writes a Kubernetes manifest or Helm chart running the package
+// of an artifact as a unikernel.
+type PostProcessor struct {
+	config Config
+}
+
+func (p *PostProcessor) ConfigSpec() hcldec.ObjectSpec { return p.config.FlatMapstructure().HCL2Spec() }
+
+func (p *PostProcessor) Configure(raws ...interface{}) error {
+	_, err := p.config.Prepare(raws...)
+	return err
+}
+
+func (p *PostProcessor) PostProcess(ctx context.Context, ui packersdk.Ui, source packersdk.Artifact) (packersdk.Artifact, bool, bool, error) {
+	packages, err := unikraftpprocessor.ArtifactPackages(source, "run by Kubernetes")
+	if err != nil {
+		ui.Error(err.Error())
+		return source, false, false, err
+	}
+
+	image := p.config.Image
+	if image == "" {
+		if len(packages) == 0 {
+			return nil, false, false, fmt.Errorf("artifact has no packages to deploy, set image")
+		}
+		image = packages[0]
+	}
+
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return nil, false, false, fmt.Errorf("invalid image %s: %s", image, err)
+	}
+
+	targets, err := unikraft.ArtifactTargets(source)
+	if err != nil {
+		ui.Error(err.Error())
+		return source, false, false, err
+	}
+
+	hints, known, err := resourceHints(targets)
+	if err != nil {
+		return nil, false, false, fmt.Errorf("could not read the KConfig of the kernel: %s", err)
+	}
+	if known && !hints.Network && len(p.config.Ports) > 0 {
+		ui.Say("The kernel has no network drivers, its ports will not be reachable")
+	}
+
+	w := &workload{
+		Name:         p.config.Name,
+		Namespace:    p.config.Namespace,
+		Image:        image,
+		RuntimeClass: p.config.RuntimeClass,
+		Replicas:     p.config.Replicas,
+		Ports:        p.config.Ports,
+		Labels:       p.config.Labels,
+		NodeSelector: nodeSelector(targets),
+		CPU:          p.config.CPU,
+		Memory:       p.config.Memory,
+	}
+	if w.Name == "" {
+		w.Name = imageName(ref)
+		if w.Name == "" {
+			return nil, false, false, fmt.Errorf("could not name the objects after %s, set name", image)
+		}
+	}
+	if w.CPU == "" {
+		w.CPU = strconv.Itoa(hints.CPUs)
+	}
+
+	files := map[string][]byte{}
+	switch p.config.Format {
+	case FormatHelm:
+		chart, err := w.Chart(p.config.ChartVersion, ref.Identifier())
+		if err != nil {
+			return nil, false, false, fmt.Errorf("could not render the chart: %s", err)
+		}
+		for file, contents := range chart {
+			files[filepath.Join(p.config.OutputDir, w.Name, file)] = contents
+		}
+	default:
+		manifest, err := w.Manifest()
+		if err != nil {
+			return nil, false, false, fmt.Errorf("could not render the manifest: %s", err)
+		}
+		files[filepath.Join(p.config.OutputDir, w.Name+".yaml")] = manifest
+	}
+
+	var written []string
+	for file := range files {
+		written = append(written, file)
+	}
+	sort.Strings(written)
+
+	for _, file := range written {
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			return nil, false, false, fmt.Errorf("could not write %s: %s", file, err)
+		}
+		if err := os.WriteFile(file, files[file], 0o644); err != nil {
+			return nil, false, false, fmt.Errorf("could not write %s: %s", file, err)
+		}
+		ui.Say(fmt.Sprintf("Wrote %s", file))
+	}
+
+	artifact := &unikraft.Artifact{
+		StateData: map[string]interface{}{
+			"packages":  []string{image},
+			"build_id":  source.Id(),
+			"manifests": written,
+			"targets":   source.State("targets"),
+		},
+	}
+	return artifact, true, true, nil
+}
+
+// resourceHints returns the resources of the kernels of targets, as told by
+// their KConfig: the most CPUs of any kernel, and whether any has network
+// drivers.  Whether any KConfig is known is returned along with them.
+func resourceHints(targets []unikraft.TargetArtifact) (unikraft.ResourceHints, bool, error) {
+	hints := unikraft.ResourceHints{CPUs: 1}
+	known := false
+
+	for _, t := range targets {
+		if t.KConfig == "" {
+			continue
+		}
+
+		h, err := unikraft.KConfigResourceHints(t.KConfig)
+		if err != nil {
+			return hints, false, err
+		}
+
+		known = true
+		if h.CPUs > hints.CPUs {
+			hints.CPUs = h.CPUs
+		}
+		hints.Network = hints.Network || h.Network
+	}
+
+	return hints, known, nil
+}
+
+// nodeSelector returns the labels of the nodes able to run the kernels of
+// targets, which all have to be of the same architecture.  Nodes are not
+// selected when the targets are of several architectures, as the package is
+// then resolved by architecture.
+func nodeSelector(targets []unikraft.TargetArtifact) map[string]string {
+	architectures := map[string]bool{}
+	for _, t := range targets {
+		architectures[t.Architecture] = true
+	}
+	if len(architectures) != 1 {
+		return nil
+	}
+
+	arch, ok := nodeArchitectures[targets[0].Architecture]
+	if !ok {
+		return nil
+	}
+
+	return map[string]string{"kubernetes.io/arch": arch}
+}
+
+// imageName returns the name of the objects running ref, after the last
+// element of its repository.
+func imageName(ref name.Reference) string {
+	n := strings.ToLower(path.Base(ref.Context().RepositoryStr()))
+	n = strings.Trim(invalidNameChars.ReplaceAllString(n, "-"), "-")
+	if len(n) > 63 {
+		n = strings.TrimRight(n[:63], "-")
+	}
+
+	return n
+}
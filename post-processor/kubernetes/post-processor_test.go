@@ -0,0 +1,208 @@
+package kubernetespprocessor
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	unikraft "packer-plugin-unikraft/builder/unikraft"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"gopkg.in/yaml.v3"
+)
+
+func TestConfigure(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  map[string]interface{}
+		want string
+	}{
+		{name: "defaults", raw: map[string]interface{}{}},
+		{name: "helm", raw: map[string]interface{}{"format": "helm", "chart_version": "1.2.3"}},
+		{name: "unknown format", raw: map[string]interface{}{"format": "cnab"}, want: `unknown format "cnab"`},
+		{name: "invalid name", raw: map[string]interface{}{"name": "My_App"}, want: "expected a DNS label"},
+		{name: "helm namespace", raw: map[string]interface{}{"format": "helm", "namespace": "apps"}, want: "namespace cannot be combined with the helm format"},
+		{name: "chart version without helm", raw: map[string]interface{}{"chart_version": "1.2.3"}, want: "chart_version requires the helm format"},
+		{name: "negative replicas", raw: map[string]interface{}{"replicas": -1}, want: "replicas must not be negative"},
+		{name: "invalid port", raw: map[string]interface{}{"ports": []int{8080, 70000}}, want: "invalid port 70000"},
+		{name: "invalid memory", raw: map[string]interface{}{"memory": "64 MB"}, want: `invalid memory "64 MB"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &PostProcessor{}
+			err := p.Configure(tt.raw)
+			if tt.want == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+// source returns the artifact of a package of an x86_64 kernel built with
+// dotconfig.
+func source(t *testing.T, dotconfig string) packersdk.Artifact {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), ".config")
+	if err := os.WriteFile(path, []byte(dotconfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return &unikraft.Artifact{
+		StateData: map[string]interface{}{
+			"packages": []string{"registry.io/unikraft/nginx:1.25"},
+			"targets": []map[string]string{
+				{"platform": "qemu", "architecture": "x86_64", "kconfig": path},
+			},
+		},
+	}
+}
+
+func TestPostProcessWritesManifest(t *testing.T) {
+	dir := t.TempDir()
+	p := &PostProcessor{}
+	if err := p.Configure(map[string]interface{}{
+		"output_dir": dir,
+		"namespace":  "apps",
+		"ports":      []int{8080},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	ui := &packersdk.BasicUi{Reader: new(bytes.Buffer), Writer: new(bytes.Buffer)}
+	artifact, keep, _, err := p.PostProcess(context.Background(), ui, source(t, "CONFIG_HAVE_SMP=y\nCONFIG_UKPLAT_LCPU_MAXCOUNT=2\nCONFIG_LIBUKNETDEV=y\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !keep {
+		t.Error("expected the artifact to be kept")
+	}
+
+	manifest := filepath.Join(dir, "nginx.yaml")
+	if got := artifact.Files(); !reflect.DeepEqual(got, []string{manifest}) {
+		t.Errorf("Files() = %v, want %s", got, manifest)
+	}
+
+	raw, err := os.ReadFile(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var objects []map[string]interface{}
+	dec := yaml.NewDecoder(bytes.NewReader(raw))
+	for {
+		var o map[string]interface{}
+		if err := dec.Decode(&o); err != nil {
+			break
+		}
+		objects = append(objects, o)
+	}
+	if len(objects) != 2 || objects[0]["kind"] != "Deployment" || objects[1]["kind"] != "Service" {
+		t.Fatalf("expected a Deployment and a Service, got:\n%s", raw)
+	}
+
+	for _, want := range []string{
+		"namespace: apps",
+		"runtimeClassName: runu",
+		"kubernetes.io/arch: amd64",
+		"image: registry.io/unikraft/nginx:1.25",
+		"cpu: \"2\"",
+		"memory: 64Mi",
+		"containerPort: 8080",
+	} {
+		if !strings.Contains(string(raw), want) {
+			t.Errorf("expected the manifest to contain %q, got:\n%s", want, raw)
+		}
+	}
+}
+
+func TestPostProcessWritesChart(t *testing.T) {
+	dir := t.TempDir()
+	p := &PostProcessor{}
+	if err := p.Configure(map[string]interface{}{
+		"output_dir":    dir,
+		"format":        "helm",
+		"name":          "web",
+		"runtime_class": "urunc",
+		"cpu":           "500m",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	ui := &packersdk.BasicUi{Reader: new(bytes.Buffer), Writer: new(bytes.Buffer)}
+	artifact, _, _, err := p.PostProcess(context.Background(), ui, source(t, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(artifact.Files()); got != 4 {
+		t.Errorf("expected 4 files in the chart, got %d", got)
+	}
+
+	raw, err := os.ReadFile(filepath.Join(dir, "web", "Chart.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(raw), "version: 0.1.0") || !strings.Contains(string(raw), `appVersion: "1.25"`) {
+		t.Errorf("unexpected Chart.yaml:\n%s", raw)
+	}
+
+	var values chartValues
+	raw, err = os.ReadFile(filepath.Join(dir, "web", "values.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := yaml.Unmarshal(raw, &values); err != nil {
+		t.Fatal(err)
+	}
+	if values.RuntimeClassName != "urunc" || values.Resources.Limits["cpu"] != "500m" || values.Labels[nameLabel] != "web" {
+		t.Errorf("unexpected values.yaml:\n%s", raw)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "web", "templates", "deployment.yaml")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPostProcessRejectsDisks(t *testing.T) {
+	p := &PostProcessor{}
+	if err := p.Configure(map[string]interface{}{"output_dir": t.TempDir()}); err != nil {
+		t.Fatal(err)
+	}
+
+	source := &unikraft.Artifact{
+		StateData: map[string]interface{}{
+			"packages": []string{"app.raw"},
+			"format":   unikraft.FormatDisk,
+		},
+	}
+	ui := &packersdk.BasicUi{Reader: new(bytes.Buffer), Writer: new(bytes.Buffer)}
+	if _, _, _, err := p.PostProcess(context.Background(), ui, source); err == nil || !strings.Contains(err.Error(), "disk images") {
+		t.Errorf("expected disk images to be rejected, got %v", err)
+	}
+}
+
+func TestImageName(t *testing.T) {
+	tests := map[string]string{
+		"registry.io/unikraft/nginx:1.25":                      "nginx",
+		"unikraft.org/my_app.web:latest":                       "my-app-web",
+		"localhost:5000/app@sha256:" + strings.Repeat("a", 64): "app",
+	}
+
+	for image, want := range tests {
+		ref, err := name.ParseReference(image)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := imageName(ref); got != want {
+			t.Errorf("imageName(%s) = %s, want %s", image, got, want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+//go:generate packer-sdc mapstructure-to-hcl2 -type Config
+
+package pushpprocessor
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/packer-plugin-sdk/common"
+	"github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/hashicorp/packer-plugin-sdk/template/config"
+	"github.com/hashicorp/packer-plugin-sdk/template/interpolate"
+	"github.com/mitchellh/mapstructure"
+)
+
+const BuilderId = "packer.post-processor.unikraft-push"
+
+// tagPattern matches a valid tag of an OCI reference.
+var tagPattern = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+
+type Config struct {
+	common.PackerConfig `mapstructure:",squash"`
+
+	// The additional tags of the pushed packages.
+	Tags []string `mapstructure:"tags"`
+	// The username used to authenticate against the registry. Defaults to
+	// the `UNIKRAFT_REGISTRY_USERNAME` environment variable, then the Docker
+	// configuration.
+	Username string `mapstructure:"username"`
+	// The password used to authenticate against the registry. Defaults to
+	// the `UNIKRAFT_REGISTRY_PASSWORD` environment variable.
+	Password string `mapstructure:"password"`
+	// Log level to use.
+	LogLevel string `mapstructure:"log_level"`
+	// Force fancy output even when not writing to a terminal.
+	FancyOutput bool `mapstructure:"fancy_output"`
+
+	ctx interpolate.Context
+}
+
+func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
+	var md mapstructure.Metadata
+	err := config.Decode(c, &config.DecodeOpts{
+		Metadata:           &md,
+		PluginType:         BuilderId,
+		Interpolate:        true,
+		InterpolateContext: &c.ctx,
+	}, raws...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Accumulate any errors
+	var errs *packer.MultiError
+	for _, tag := range c.Tags {
+		if !tagPattern.MatchString(tag) {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("invalid tag %q", tag))
+		}
+	}
+
+	if c.Username != "" && c.Password == "" {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("password must be specified with a username"))
+	}
+
+	if errs != nil && len(errs.Errors) > 0 {
+		return nil, errs
+	}
+
+	return nil, nil
+}
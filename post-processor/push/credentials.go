@@ -0,0 +1,59 @@
+package pushpprocessor
+
+import (
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+const (
+	envUsername = "UNIKRAFT_REGISTRY_USERNAME"
+	envPassword = "UNIKRAFT_REGISTRY_PASSWORD"
+)
+
+// Credentials authenticate against a registry.  Empty credentials leave the
+// authentication to the KraftKit configuration.
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// resolveCredentials returns the credentials of a registry, and where they
+// come from.  Credentials given in the configuration take precedence over the
+// environment, which takes precedence over the Docker configuration read by
+// keychain.
+func resolveCredentials(registry string, explicit Credentials, getenv func(string) string, keychain authn.Keychain) (Credentials, string, error) {
+	if explicit.Username != "" {
+		return explicit, "configuration", nil
+	}
+
+	if username := getenv(envUsername); username != "" {
+		return Credentials{Username: username, Password: getenv(envPassword)}, "environment", nil
+	}
+
+	reg, err := name.NewRegistry(registry)
+	if err != nil {
+		return Credentials{}, "", fmt.Errorf("invalid registry %s: %w", registry, err)
+	}
+
+	auth, err := keychain.Resolve(reg)
+	if err != nil {
+		return Credentials{}, "", fmt.Errorf("could not read credentials of %s: %w", registry, err)
+	}
+
+	if auth == authn.Anonymous {
+		return Credentials{}, "", nil
+	}
+
+	cfg, err := auth.Authorization()
+	if err != nil {
+		return Credentials{}, "", fmt.Errorf("could not read credentials of %s: %w", registry, err)
+	}
+
+	if cfg.Username == "" {
+		return Credentials{}, "", nil
+	}
+
+	return Credentials{Username: cfg.Username, Password: cfg.Password}, "docker configuration", nil
+}
@@ -0,0 +1,115 @@
+package pushpprocessor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	unikraft "packer-plugin-unikraft/builder/unikraft"
+	unikraftpprocessor "packer-plugin-unikraft/post-processor/unikraft"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/mitchellh/mapstructure"
+)
+
+// Pusher pushes a package to its registry with additional tags, returning the
+// references it was pushed as.
+type Pusher interface {
+	Push(pkgName string, tags []string, username, password string) ([]string, error)
+}
+
+// PostProcessor pushes the packages of an artifact to their OCI registries.
+type PostProcessor struct {
+	config Config
+
+	// pusher overrides the KraftKit driver.
+	pusher Pusher
+	// keychain overrides the Docker configuration.
+	keychain authn.Keychain
+}
+
+func (p *PostProcessor) ConfigSpec() hcldec.ObjectSpec { return p.config.FlatMapstructure().HCL2Spec() }
+
+func (p *PostProcessor) Configure(raws ...interface{}) error {
+	_, err := p.config.Prepare(raws...)
+	return err
+}
+
+func (p *PostProcessor) PostProcess(ctx context.Context, ui packersdk.Ui, source packersdk.Artifact) (packersdk.Artifact, bool, bool, error) {
+	packages, err := unikraftpprocessor.ArtifactPackages(source, "pushed")
+	if err != nil {
+		ui.Error(err.Error())
+		return source, false, false, err
+	}
+	if len(packages) == 0 {
+		return nil, false, false, fmt.Errorf("artifact has no packages to push")
+	}
+
+	// The volumes declared when packaging are recorded in the annotations of
+	// the pushed packages.
+	var volumes []string
+	if err := mapstructure.Decode(source.State("volumes"), &volumes); err != nil {
+		err := fmt.Errorf("failed to decode volumes")
+		ui.Error(err.Error())
+		return source, false, false, err
+	}
+
+	pusher := p.pusher
+	if pusher == nil {
+		pusher = &unikraft.KraftDriver{
+			Ui:             ui,
+			CommandContext: unikraft.KraftCommandContext(ui, p.config.LogLevel, p.config.FancyOutput),
+			Volumes:        volumes,
+		}
+	}
+
+	keychain := p.keychain
+	if keychain == nil {
+		keychain = authn.DefaultKeychain
+	}
+
+	var pushed []string
+	for _, pkg := range packages {
+		ref, err := name.ParseReference(pkg)
+		if err != nil {
+			return nil, false, false, fmt.Errorf("invalid package reference %s: %s", pkg, err)
+		}
+
+		creds, from, err := resolveCredentials(
+			ref.Context().RegistryStr(),
+			Credentials{Username: p.config.Username, Password: p.config.Password},
+			os.Getenv,
+			keychain,
+		)
+		if err != nil {
+			ui.Error(err.Error())
+			return source, false, false, err
+		}
+		if from != "" {
+			ui.Say(fmt.Sprintf("Using credentials of %s from the %s", ref.Context().RegistryStr(), from))
+		}
+
+		ui.Say(fmt.Sprintf("Pushing %s", pkg))
+		refs, err := pusher.Push(pkg, p.config.Tags, creds.Username, creds.Password)
+		if err != nil {
+			return nil, false, false, fmt.Errorf("push error: %s", err)
+		}
+
+		pushed = append(pushed, refs...)
+	}
+
+	state := map[string]interface{}{
+		"packages": pushed,
+		"targets":  source.State("targets"),
+	}
+	if len(volumes) > 0 {
+		state["volumes"] = volumes
+	}
+
+	artifact := &unikraft.Artifact{
+		StateData: state,
+	}
+	return artifact, true, true, nil
+}
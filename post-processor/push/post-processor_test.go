@@ -0,0 +1,114 @@
+package pushpprocessor
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	unikraft "packer-plugin-unikraft/builder/unikraft"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+type fakePusher struct {
+	pushed   []string
+	username string
+}
+
+func (f *fakePusher) Push(pkgName string, tags []string, username, password string) ([]string, error) {
+	f.username = username
+
+	refs := []string{pkgName}
+	repository := pkgName[:strings.LastIndex(pkgName, ":")]
+	for _, tag := range tags {
+		refs = append(refs, repository+":"+tag)
+	}
+
+	f.pushed = append(f.pushed, refs...)
+	return refs, nil
+}
+
+type fakeKeychain map[string]authn.AuthConfig
+
+func (k fakeKeychain) Resolve(r authn.Resource) (authn.Authenticator, error) {
+	cfg, ok := k[r.RegistryStr()]
+	if !ok {
+		return authn.Anonymous, nil
+	}
+	return authn.FromConfig(cfg), nil
+}
+
+func TestPostProcessPushesPackagesWithTags(t *testing.T) {
+	t.Setenv(envUsername, "")
+
+	pusher := &fakePusher{}
+	p := &PostProcessor{
+		pusher:   pusher,
+		keychain: fakeKeychain{"registry.io": {Username: "docker", Password: "secret"}},
+	}
+	if err := p.Configure(map[string]interface{}{"tags": []string{"1.0", "stable"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	source := &unikraft.Artifact{
+		StateData: map[string]interface{}{
+			"packages": []string{"registry.io/nginx:latest"},
+		},
+	}
+
+	ui := &packersdk.BasicUi{Reader: new(bytes.Buffer), Writer: new(bytes.Buffer)}
+	artifact, _, _, err := p.PostProcess(context.Background(), ui, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"registry.io/nginx:latest", "registry.io/nginx:1.0", "registry.io/nginx:stable"}
+	if got := artifact.State("packages"); !reflect.DeepEqual(got, want) {
+		t.Errorf("pushed %v, want %v", got, want)
+	}
+	if pusher.username != "docker" {
+		t.Errorf("expected credentials from the docker configuration, got username %q", pusher.username)
+	}
+}
+
+func TestResolveCredentials(t *testing.T) {
+	keychain := fakeKeychain{"registry.io": {Username: "docker", Password: "docker-secret"}}
+	env := map[string]string{}
+	getenv := func(key string) string { return env[key] }
+
+	creds, from, err := resolveCredentials("registry.io", Credentials{Username: "hcl", Password: "hcl-secret"}, getenv, keychain)
+	if err != nil || creds.Username != "hcl" || from != "configuration" {
+		t.Errorf("expected explicit credentials, got %+v from %q (%v)", creds, from, err)
+	}
+
+	env[envUsername] = "env"
+	env[envPassword] = "env-secret"
+	creds, from, err = resolveCredentials("registry.io", Credentials{}, getenv, keychain)
+	if err != nil || creds != (Credentials{Username: "env", Password: "env-secret"}) || from != "environment" {
+		t.Errorf("expected environment credentials, got %+v from %q (%v)", creds, from, err)
+	}
+
+	delete(env, envUsername)
+	creds, from, err = resolveCredentials("registry.io", Credentials{}, getenv, keychain)
+	if err != nil || creds != (Credentials{Username: "docker", Password: "docker-secret"}) || from != "docker configuration" {
+		t.Errorf("expected docker credentials, got %+v from %q (%v)", creds, from, err)
+	}
+
+	creds, from, err = resolveCredentials("other.io", Credentials{}, getenv, keychain)
+	if err != nil || creds != (Credentials{}) || from != "" {
+		t.Errorf("expected no credentials, got %+v from %q (%v)", creds, from, err)
+	}
+}
+
+func TestConfigureRejectsInvalidTags(t *testing.T) {
+	p := &PostProcessor{}
+	if err := p.Configure(map[string]interface{}{"tags": []string{"not a tag"}}); err == nil {
+		t.Error("expected an invalid tag to be rejected")
+	}
+	if err := p.Configure(map[string]interface{}{"username": "user"}); err == nil {
+		t.Error("expected a username without password to be rejected")
+	}
+}
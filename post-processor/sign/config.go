@@ -0,0 +1,100 @@
+//go:generate packer-sdc mapstructure-to-hcl2 -type Config
+
+package signpprocessor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/packer-plugin-sdk/common"
+	"github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/hashicorp/packer-plugin-sdk/template/config"
+	"github.com/hashicorp/packer-plugin-sdk/template/interpolate"
+	"github.com/mitchellh/mapstructure"
+)
+
+const BuilderId = "packer.post-processor.unikraft-sign"
+
+// DefaultCosignBinary is the cosign executable run when cosign_binary is not
+// set.
+const DefaultCosignBinary = "cosign"
+
+type Config struct {
+	common.PackerConfig `mapstructure:",squash"`
+
+	// The private key signing the packages: a path, or a KMS URI such as
+	// `awskms:///alias/unikraft`. Required unless keyless is set.
+	Key string `mapstructure:"key"`
+	// The password of key. Defaults to the `COSIGN_PASSWORD` environment
+	// variable.
+	KeyPassword string `mapstructure:"key_password"`
+	// Sign with a short-lived certificate bound to an OIDC identity instead
+	// of a key.
+	Keyless bool `mapstructure:"keyless"`
+	// The OIDC token identifying the signer of keyless signatures, e.g. the
+	// one of the CI job. Defaults to the ones cosign finds on its own.
+	IdentityToken string `mapstructure:"identity_token"`
+	// The Fulcio instance issuing the certificates of keyless signatures.
+	// Defaults to the public instance of Sigstore.
+	FulcioURL string `mapstructure:"fulcio_url"`
+	// The Rekor transparency log the signatures are recorded in. Defaults to
+	// the public instance of Sigstore.
+	RekorURL string `mapstructure:"rekor_url"`
+	// Do not upload the signatures to the transparency log.
+	NoTlogUpload bool `mapstructure:"no_tlog_upload"`
+	// Annotations added to the signatures.
+	Annotations map[string]string `mapstructure:"annotations"`
+	// Sign every manifest of multi-platform packages as well as their index.
+	Recursive bool `mapstructure:"recursive"`
+	// The cosign executable. Defaults to cosign, looked up in `PATH`.
+	CosignBinary string `mapstructure:"cosign_binary"`
+
+	ctx interpolate.Context
+}
+
+func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
+	var md mapstructure.Metadata
+	err := config.Decode(c, &config.DecodeOpts{
+		Metadata:           &md,
+		PluginType:         BuilderId,
+		Interpolate:        true,
+		InterpolateContext: &c.ctx,
+	}, raws...)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.CosignBinary == "" {
+		c.CosignBinary = DefaultCosignBinary
+	}
+
+	// Accumulate any errors
+	var errs *packer.MultiError
+	if c.Key == "" && !c.Keyless {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("key or keyless must be specified"))
+	}
+
+	if c.Key != "" && c.Keyless {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("key cannot be combined with keyless"))
+	}
+
+	if !c.Keyless && (c.IdentityToken != "" || c.FulcioURL != "") {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("identity_token and fulcio_url require keyless"))
+	}
+
+	if c.Keyless && c.NoTlogUpload {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("keyless signatures must be uploaded to the transparency log"))
+	}
+
+	for k := range c.Annotations {
+		if k == "" || strings.Contains(k, "=") {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("invalid annotation %q", k))
+		}
+	}
+
+	if errs != nil && len(errs.Errors) > 0 {
+		return nil, errs
+	}
+
+	return nil, nil
+}
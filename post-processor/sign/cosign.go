@@ -0,0 +1,137 @@
+package signpprocessor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// CosignSigner signs images by running cosign.
+type CosignSigner struct {
+	// Binary is the cosign executable.
+	Binary string
+
+	Key           string
+	KeyPassword   string
+	IdentityToken string
+	FulcioURL     string
+	RekorURL      string
+	NoTlogUpload  bool
+	Annotations   map[string]string
+	Recursive     bool
+}
+
+// NewCosignSigner returns the signer configured by c.
+func NewCosignSigner(c *Config) *CosignSigner {
+	return &CosignSigner{
+		Binary:        c.CosignBinary,
+		Key:           c.Key,
+		KeyPassword:   c.KeyPassword,
+		IdentityToken: c.IdentityToken,
+		FulcioURL:     c.FulcioURL,
+		RekorURL:      c.RekorURL,
+		NoTlogUpload:  c.NoTlogUpload,
+		Annotations:   c.Annotations,
+		Recursive:     c.Recursive,
+	}
+}
+
+// Args returns the arguments of cosign signing ref.  Signatures are keyless
+// when no key is set.
+func (s *CosignSigner) Args(ref string) []string {
+	args := []string{"sign", "--yes"}
+	if s.Key != "" {
+		args = append(args, "--key", s.Key)
+	}
+	if s.IdentityToken != "" {
+		args = append(args, "--identity-token", s.IdentityToken)
+	}
+	if s.FulcioURL != "" {
+		args = append(args, "--fulcio-url", s.FulcioURL)
+	}
+	if s.RekorURL != "" {
+		args = append(args, "--rekor-url", s.RekorURL)
+	}
+	if s.NoTlogUpload {
+		args = append(args, "--tlog-upload=false")
+	}
+	if s.Recursive {
+		args = append(args, "--recursive")
+	}
+
+	keys := make([]string, 0, len(s.Annotations))
+	for k := range s.Annotations {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "-a", k+"="+s.Annotations[k])
+	}
+
+	return append(args, ref)
+}
+
+// Sign signs ref, and attaches the signature to its registry.  The output of
+// cosign is included in the returned error when it fails.
+func (s *CosignSigner) Sign(ctx context.Context, ref string) error {
+	args := s.Args(ref)
+	cmd := exec.CommandContext(ctx, s.Binary, args...)
+
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if s.KeyPassword != "" {
+		cmd.Env = append(os.Environ(), "COSIGN_PASSWORD="+s.KeyPassword)
+	}
+
+	if err := cmd.Run(); err != nil {
+		if output := strings.TrimSpace(out.String()); output != "" {
+			return fmt.Errorf("%s sign failed: %w: %s", s.Binary, err, output)
+		}
+		return fmt.Errorf("%s sign failed: %w", s.Binary, err)
+	}
+
+	return nil
+}
+
+// RemoteRegistry resolves digests in the registries of the references,
+// authenticating with Keychain.
+type RemoteRegistry struct {
+	Keychain authn.Keychain
+}
+
+func (r *RemoteRegistry) Digest(ctx context.Context, ref string) (string, error) {
+	parsed, err := name.ParseReference(ref)
+	if err != nil {
+		return "", err
+	}
+
+	keychain := r.Keychain
+	if keychain == nil {
+		keychain = authn.DefaultKeychain
+	}
+
+	desc, err := remote.Head(parsed,
+		remote.WithContext(ctx),
+		remote.WithAuthFromKeychain(keychain),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return desc.Digest.String(), nil
+}
+
+// signatureRef returns the reference cosign attaches the signatures of the
+// image of digest in repository to: the `sha256-<hex>.sig` tag.
+func signatureRef(repository, digest string) string {
+	return repository + ":" + strings.Replace(digest, ":", "-", 1) + ".sig"
+}
@@ -0,0 +1,81 @@
+package signpprocessor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCosignSignerArgs(t *testing.T) {
+	keyed := &CosignSigner{
+		Key:          "cosign.key",
+		RekorURL:     "https://rekor.example.com",
+		NoTlogUpload: true,
+		Annotations:  map[string]string{"org": "unikraft", "build": "42"},
+		Recursive:    true,
+	}
+	want := []string{
+		"sign", "--yes",
+		"--key", "cosign.key",
+		"--rekor-url", "https://rekor.example.com",
+		"--tlog-upload=false",
+		"--recursive",
+		"-a", "build=42",
+		"-a", "org=unikraft",
+		"registry.io/nginx@sha256:aaaa",
+	}
+	if got := keyed.Args("registry.io/nginx@sha256:aaaa"); !reflect.DeepEqual(got, want) {
+		t.Errorf("args = %q, want %q", got, want)
+	}
+
+	keyless := &CosignSigner{IdentityToken: "token", FulcioURL: "https://fulcio.example.com"}
+	want = []string{
+		"sign", "--yes",
+		"--identity-token", "token",
+		"--fulcio-url", "https://fulcio.example.com",
+		"registry.io/nginx@sha256:aaaa",
+	}
+	if got := keyless.Args("registry.io/nginx@sha256:aaaa"); !reflect.DeepEqual(got, want) {
+		t.Errorf("args = %q, want %q", got, want)
+	}
+}
+
+func TestCosignSignerSign(t *testing.T) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "cosign")
+	record := filepath.Join(dir, "record")
+	script := "#!/bin/sh\n" +
+		"echo \"$COSIGN_PASSWORD $*\" > " + record + "\n" +
+		"[ \"$3\" = good.key ] || { echo 'error: reading key'; exit 1; }\n"
+	if err := os.WriteFile(binary, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &CosignSigner{Binary: binary, Key: "good.key", KeyPassword: "secret"}
+	if err := s.Sign(context.Background(), "registry.io/nginx@sha256:aaaa"); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(raw)); got != "secret sign --yes --key good.key registry.io/nginx@sha256:aaaa" {
+		t.Errorf("cosign ran as %q", got)
+	}
+
+	s.Key = "bad.key"
+	err = s.Sign(context.Background(), "registry.io/nginx@sha256:aaaa")
+	if err == nil || !strings.Contains(err.Error(), "error: reading key") {
+		t.Errorf("expected the output of cosign in the error, got %v", err)
+	}
+}
+
+func TestSignatureRef(t *testing.T) {
+	if got := signatureRef("registry.io/nginx", "sha256:aaaa"); got != "registry.io/nginx:sha256-aaaa.sig" {
+		t.Errorf("signatureRef() = %s", got)
+	}
+}
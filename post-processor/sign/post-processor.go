@@ -0,0 +1,112 @@
+package signpprocessor
+
+import (
+	"context"
+	"fmt"
+	unikraft "packer-plugin-unikraft/builder/unikraft"
+	unikraftpprocessor "packer-plugin-unikraft/post-processor/unikraft"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+// Signer signs an image, given by digest, and attaches the signature to its
+// registry.
+type Signer interface {
+	Sign(ctx context.Context, ref string) error
+}
+
+// Registry resolves the digest of the manifest of a reference.
+type Registry interface {
+	Digest(ctx context.Context, ref string) (string, error)
+}
+
+// PostProcessor signs the pushed packages of an artifact with cosign.
+type PostProcessor struct {
+	config Config
+
+	// signer overrides cosign.
+	signer Signer
+	// registry overrides the registries of the packages.
+	registry Registry
+}
+
+func (p *PostProcessor) ConfigSpec() hcldec.ObjectSpec { return p.config.FlatMapstructure().HCL2Spec() }
+
+func (p *PostProcessor) Configure(raws ...interface{}) error {
+	_, err := p.config.Prepare(raws...)
+	return err
+}
+
+func (p *PostProcessor) PostProcess(ctx context.Context, ui packersdk.Ui, source packersdk.Artifact) (packersdk.Artifact, bool, bool, error) {
+	packages, err := unikraftpprocessor.ArtifactPackages(source, "signed")
+	if err != nil {
+		ui.Error(err.Error())
+		return source, false, false, err
+	}
+	if len(packages) == 0 {
+		return nil, false, false, fmt.Errorf("artifact has no packages to sign")
+	}
+
+	signer := p.signer
+	if signer == nil {
+		signer = NewCosignSigner(&p.config)
+	}
+
+	registry := p.registry
+	if registry == nil {
+		registry = &RemoteRegistry{}
+	}
+
+	// Tags of the same image share its signature, so every image is only
+	// signed once.
+	var signatures []map[string]string
+	signed := map[string]bool{}
+	for _, pkg := range packages {
+		ref, err := name.ParseReference(pkg)
+		if err != nil {
+			return nil, false, false, fmt.Errorf("invalid package reference %s: %s", pkg, err)
+		}
+
+		digest, err := registry.Digest(ctx, pkg)
+		if err != nil {
+			return nil, false, false, fmt.Errorf("could not resolve %s, was it pushed? %s", pkg, err)
+		}
+
+		image := ref.Context().Name() + "@" + digest
+		if signed[image] {
+			continue
+		}
+		signed[image] = true
+
+		ui.Say(fmt.Sprintf("Signing %s", image))
+		if err := signer.Sign(ctx, image); err != nil {
+			return nil, false, false, fmt.Errorf("signing error: %s", err)
+		}
+
+		signature := signatureRef(ref.Context().Name(), digest)
+		signatureDigest, err := registry.Digest(ctx, signature)
+		if err != nil {
+			return nil, false, false, fmt.Errorf("could not resolve the signature of %s: %s", pkg, err)
+		}
+		ui.Say(fmt.Sprintf("Attached signature %s@%s", signature, signatureDigest))
+
+		signatures = append(signatures, map[string]string{
+			"package":          pkg,
+			"digest":           digest,
+			"signature":        signature,
+			"signature_digest": signatureDigest,
+		})
+	}
+
+	artifact := &unikraft.Artifact{
+		StateData: map[string]interface{}{
+			"packages":   packages,
+			"build_id":   source.Id(),
+			"signatures": signatures,
+			"targets":    source.State("targets"),
+		},
+	}
+	return artifact, true, true, nil
+}
@@ -0,0 +1,133 @@
+package signpprocessor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	unikraft "packer-plugin-unikraft/builder/unikraft"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+type fakeSigner struct {
+	signed []string
+}
+
+func (f *fakeSigner) Sign(_ context.Context, ref string) error {
+	f.signed = append(f.signed, ref)
+	return nil
+}
+
+// fakeRegistry maps references to their digests.
+type fakeRegistry map[string]string
+
+func (f fakeRegistry) Digest(_ context.Context, ref string) (string, error) {
+	digest, ok := f[ref]
+	if !ok {
+		return "", fmt.Errorf("MANIFEST_UNKNOWN: %s", ref)
+	}
+	return digest, nil
+}
+
+func TestPostProcessSignsEveryImageOnce(t *testing.T) {
+	signer := &fakeSigner{}
+	p := &PostProcessor{
+		signer: signer,
+		registry: fakeRegistry{
+			"registry.io/nginx:latest":          "sha256:aaaa",
+			"registry.io/nginx:1.0":             "sha256:aaaa",
+			"registry.io/nginx:sha256-aaaa.sig": "sha256:5151",
+			"registry.io/redis:latest":          "sha256:bbbb",
+			"registry.io/redis:sha256-bbbb.sig": "sha256:5252",
+		},
+	}
+	if err := p.Configure(map[string]interface{}{"key": "cosign.key"}); err != nil {
+		t.Fatal(err)
+	}
+
+	source := &unikraft.Artifact{
+		StateData: map[string]interface{}{
+			"packages": []string{"registry.io/nginx:latest", "registry.io/nginx:1.0", "registry.io/redis:latest"},
+		},
+	}
+
+	ui := &packersdk.BasicUi{Reader: new(bytes.Buffer), Writer: new(bytes.Buffer)}
+	artifact, keep, _, err := p.PostProcess(context.Background(), ui, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !keep {
+		t.Error("expected the artifact to be kept")
+	}
+
+	if want := []string{"registry.io/nginx@sha256:aaaa", "registry.io/redis@sha256:bbbb"}; !reflect.DeepEqual(signer.signed, want) {
+		t.Errorf("signed %v, want %v", signer.signed, want)
+	}
+
+	want := []map[string]string{
+		{"package": "registry.io/nginx:latest", "digest": "sha256:aaaa", "signature": "registry.io/nginx:sha256-aaaa.sig", "signature_digest": "sha256:5151"},
+		{"package": "registry.io/redis:latest", "digest": "sha256:bbbb", "signature": "registry.io/redis:sha256-bbbb.sig", "signature_digest": "sha256:5252"},
+	}
+	if got := artifact.State("signatures"); !reflect.DeepEqual(got, want) {
+		t.Errorf("signatures = %v, want %v", got, want)
+	}
+}
+
+func TestPostProcessUnpushedPackage(t *testing.T) {
+	p := &PostProcessor{signer: &fakeSigner{}, registry: fakeRegistry{}}
+	if err := p.Configure(map[string]interface{}{"keyless": true}); err != nil {
+		t.Fatal(err)
+	}
+
+	source := &unikraft.Artifact{StateData: map[string]interface{}{"oci": "registry.io/nginx:latest"}}
+	ui := &packersdk.BasicUi{Reader: new(bytes.Buffer), Writer: new(bytes.Buffer)}
+	_, _, _, err := p.PostProcess(context.Background(), ui, source)
+	if err == nil || !strings.Contains(err.Error(), "was it pushed?") {
+		t.Errorf("expected an unresolved package error, got %v", err)
+	}
+}
+
+func TestPostProcessRejectsDisks(t *testing.T) {
+	p := &PostProcessor{signer: &fakeSigner{}, registry: fakeRegistry{}}
+	source := &unikraft.Artifact{StateData: map[string]interface{}{
+		"format":   unikraft.FormatDisk,
+		"packages": []string{"/tmp/nginx.img"},
+	}}
+
+	ui := &packersdk.BasicUi{Reader: new(bytes.Buffer), Writer: new(bytes.Buffer)}
+	if _, _, _, err := p.PostProcess(context.Background(), ui, source); err == nil {
+		t.Error("expected disk images to be rejected")
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  map[string]interface{}
+		want string
+	}{
+		{name: "key", raw: map[string]interface{}{"key": "awskms:///alias/unikraft"}},
+		{name: "keyless", raw: map[string]interface{}{"keyless": true, "identity_token": "token"}},
+		{name: "no key", raw: map[string]interface{}{}, want: "key or keyless must be specified"},
+		{name: "key and keyless", raw: map[string]interface{}{"key": "cosign.key", "keyless": true}, want: "key cannot be combined with keyless"},
+		{name: "keyed identity", raw: map[string]interface{}{"key": "cosign.key", "fulcio_url": "https://fulcio.example.com"}, want: "require keyless"},
+		{name: "keyless without tlog", raw: map[string]interface{}{"keyless": true, "no_tlog_upload": true}, want: "must be uploaded to the transparency log"},
+		{name: "invalid annotation", raw: map[string]interface{}{"key": "cosign.key", "annotations": map[string]string{"a=b": "c"}}, want: `invalid annotation "a=b"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p PostProcessor
+			err := p.Configure(tt.raw)
+			if tt.want == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
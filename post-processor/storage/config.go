@@ -0,0 +1,78 @@
+//go:generate packer-sdc mapstructure-to-hcl2 -type Config
+
+package storagepprocessor
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/packer-plugin-sdk/common"
+	"github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/hashicorp/packer-plugin-sdk/template/config"
+	"github.com/hashicorp/packer-plugin-sdk/template/interpolate"
+	"github.com/mitchellh/mapstructure"
+)
+
+const BuilderId = "packer.post-processor.unikraft-storage"
+
+// BackendS3 stores artifacts in an S3 bucket.
+const BackendS3 = "s3"
+
+type Config struct {
+	common.PackerConfig `mapstructure:",squash"`
+
+	// The storage backend to upload to. Defaults to `s3`.
+	Backend string `mapstructure:"backend"`
+	// The bucket to upload to. This is required.
+	Bucket string `mapstructure:"bucket" required:"true"`
+	// The prefix of the uploaded keys.
+	Prefix string `mapstructure:"prefix"`
+	// The region of the bucket.
+	Region string `mapstructure:"region"`
+	// The endpoint of an S3 compatible storage.
+	Endpoint string `mapstructure:"endpoint"`
+	// The access key used to upload. Defaults to the AWS credential chain.
+	AccessKey string `mapstructure:"access_key"`
+	// The secret key used to upload.
+	SecretKey string `mapstructure:"secret_key"`
+	// The session token used to upload.
+	SessionToken string `mapstructure:"session_token"`
+
+	ctx interpolate.Context
+}
+
+func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
+	var md mapstructure.Metadata
+	err := config.Decode(c, &config.DecodeOpts{
+		Metadata:           &md,
+		PluginType:         BuilderId,
+		Interpolate:        true,
+		InterpolateContext: &c.ctx,
+	}, raws...)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Backend == "" {
+		c.Backend = BackendS3
+	}
+
+	// Accumulate any errors
+	var errs *packer.MultiError
+	if c.Backend != BackendS3 {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("unsupported storage backend %q", c.Backend))
+	}
+
+	if c.Bucket == "" {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("bucket must be specified"))
+	}
+
+	if c.AccessKey != "" && c.SecretKey == "" {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("secret key must be specified with an access key"))
+	}
+
+	if errs != nil && len(errs.Errors) > 0 {
+		return nil, errs
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,93 @@
+package storagepprocessor
+
+import (
+	"context"
+	"fmt"
+	unikraft "packer-plugin-unikraft/builder/unikraft"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+// PostProcessor uploads the files of an artifact to a storage backend.
+type PostProcessor struct {
+	config Config
+
+	// backend overrides the configured backend.
+	backend Backend
+}
+
+func (p *PostProcessor) ConfigSpec() hcldec.ObjectSpec { return p.config.FlatMapstructure().HCL2Spec() }
+
+func (p *PostProcessor) Configure(raws ...interface{}) error {
+	_, err := p.config.Prepare(raws...)
+	return err
+}
+
+func (p *PostProcessor) PostProcess(ctx context.Context, ui packersdk.Ui, source packersdk.Artifact) (packersdk.Artifact, bool, bool, error) {
+	switch source.BuilderId() {
+	case unikraft.BuilderId:
+		break
+	default:
+		return nil, false, false, fmt.Errorf("unknown artifact %s", source.BuilderId())
+	}
+
+	backend := p.backend
+	if backend == nil {
+		var err error
+		backend, err = NewS3Backend(&p.config)
+		if err != nil {
+			ui.Error(err.Error())
+			return source, false, false, err
+		}
+	}
+
+	files := artifactFiles(source)
+	if len(files) == 0 {
+		return nil, false, false, fmt.Errorf("artifact has no files to upload")
+	}
+
+	ui.Say(fmt.Sprintf("Uploading %d files to %s/%s", len(files), p.config.Bucket, p.config.Prefix))
+
+	urls, metadata, err := Upload(ctx, backend, p.config.Prefix, files, artifactMetadata(source))
+	if err != nil {
+		ui.Error(err.Error())
+		return source, false, false, err
+	}
+
+	state := map[string]interface{}{
+		"urls":     urls,
+		"metadata": metadata,
+	}
+	if kernel, ok := source.State("kernel").(string); ok {
+		state["kernel"] = urls[kernel]
+	}
+
+	artifact := &unikraft.Artifact{
+		StateData: state,
+	}
+	return artifact, true, true, nil
+}
+
+// artifactFiles returns the kernels, initramfs and debug kernel of an
+// artifact.
+func artifactFiles(source packersdk.Artifact) []string {
+	files := source.Files()
+	if dbg, ok := source.State("kernel_dbg").(string); ok && dbg != "" {
+		files = append(files, dbg)
+	}
+
+	return files
+}
+
+// artifactMetadata returns the state of an artifact describing its build.
+func artifactMetadata(source packersdk.Artifact) map[string]interface{} {
+	metadata := map[string]interface{}{}
+	for _, name := range []string{"targets", "environment"} {
+		if v := source.State(name); v != nil {
+			metadata[name] = v
+		}
+	}
+
+	return metadata
+}
@@ -0,0 +1,137 @@
+package storagepprocessor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	unikraft "packer-plugin-unikraft/builder/unikraft"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+type fakeBackend struct {
+	objects map[string][]byte
+}
+
+func (b *fakeBackend) Put(_ context.Context, key string, r io.Reader) (string, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	b.objects[key] = raw
+	return "fake://bucket/" + key, nil
+}
+
+func (b *fakeBackend) List(_ context.Context, prefix string) ([]string, error) {
+	var keys []string
+	for key := range b.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (b *fakeBackend) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	raw, ok := b.objects[key]
+	if !ok {
+		return nil, fmt.Errorf("no such key: %s", key)
+	}
+	return io.NopCloser(bytes.NewReader(raw)), nil
+}
+
+func TestPostProcessUploadsArtifact(t *testing.T) {
+	dir := t.TempDir()
+	kernel := filepath.Join(dir, "app_qemu-x86_64")
+	dbg := filepath.Join(dir, "dbg", "app_qemu-x86_64.dbg")
+	if err := os.MkdirAll(filepath.Dir(dbg), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{kernel, dbg} {
+		if err := os.WriteFile(f, []byte(filepath.Base(f)), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	source := &unikraft.Artifact{
+		StateData: map[string]interface{}{
+			"binaries":   []string{kernel},
+			"kernel":     kernel,
+			"kernel_dbg": dbg,
+			"targets": []map[string]string{
+				{"platform": "qemu", "architecture": "x86_64", "kernel": kernel},
+			},
+		},
+	}
+
+	ui := &packersdk.BasicUi{Reader: new(bytes.Buffer), Writer: io.Discard, ErrorWriter: io.Discard}
+	backend := &fakeBackend{objects: map[string][]byte{}}
+	p := &PostProcessor{backend: backend}
+	p.config.Bucket = "unikernels"
+	p.config.Prefix = "app/v1"
+
+	artifact, _, _, err := p.PostProcess(context.Background(), ui, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys, _ := backend.List(context.Background(), "app/v1/")
+	want := []string{"app/v1/app_qemu-x86_64", "app/v1/app_qemu-x86_64.dbg", "app/v1/metadata.json"}
+	if strings.Join(keys, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected keys %v, got %v", want, keys)
+	}
+
+	if got := artifact.State("kernel"); got != "fake://bucket/app/v1/app_qemu-x86_64" {
+		t.Errorf("unexpected kernel URL: %v", got)
+	}
+	if got := artifact.State("metadata"); got != "fake://bucket/app/v1/metadata.json" {
+		t.Errorf("unexpected metadata URL: %v", got)
+	}
+
+	r, err := backend.Get(context.Background(), "app/v1/metadata.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	var metadata struct {
+		Files []map[string]string `json:"files"`
+		State struct {
+			Targets []map[string]string `json:"targets"`
+		} `json:"state"`
+	}
+	if err := json.NewDecoder(r).Decode(&metadata); err != nil {
+		t.Fatalf("metadata is not valid JSON: %v", err)
+	}
+	if len(metadata.Files) != 2 || len(metadata.State.Targets) != 1 {
+		t.Errorf("unexpected metadata: %+v", metadata)
+	}
+}
+
+func TestUploadRejectsConflictingKeys(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a", "kernel")
+	b := filepath.Join(dir, "b", "kernel")
+	for _, f := range []string{a, b} {
+		if err := os.MkdirAll(filepath.Dir(f), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(f, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backend := &fakeBackend{objects: map[string][]byte{}}
+	if _, _, err := Upload(context.Background(), backend, "", []string{a, b}, nil); err == nil {
+		t.Fatal("expected an error for files uploaded to the same key")
+	}
+}
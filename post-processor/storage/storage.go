@@ -0,0 +1,96 @@
+package storagepprocessor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+// Backend stores the files of an artifact remotely.
+type Backend interface {
+	// Put stores the content of r under key and returns its remote URL.
+	Put(ctx context.Context, key string, r io.Reader) (string, error)
+	// List returns the keys stored under prefix.
+	List(ctx context.Context, prefix string) ([]string, error)
+	// Get returns the content stored under key.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// metadataName is the name of the object describing an uploaded artifact.
+const metadataName = "metadata.json"
+
+// Upload stores every file under prefix, followed by the metadata of the
+// artifact, and returns the remote URL of each file keyed by its local path
+// and the URL of the metadata.
+func Upload(ctx context.Context, backend Backend, prefix string, files []string, metadata map[string]interface{}) (map[string]string, string, error) {
+	urls := map[string]string{}
+	keys := map[string]string{}
+
+	for _, file := range files {
+		if _, ok := urls[file]; ok {
+			continue
+		}
+
+		key := path.Join(prefix, filepath.Base(file))
+		if other, ok := keys[key]; ok {
+			return nil, "", fmt.Errorf("%s and %s would both be uploaded to %s", other, file, key)
+		}
+		keys[key] = file
+
+		url, err := putFile(ctx, backend, key, file)
+		if err != nil {
+			return nil, "", err
+		}
+		urls[file] = url
+	}
+
+	local := make([]string, 0, len(urls))
+	for file := range urls {
+		local = append(local, file)
+	}
+	sort.Strings(local)
+
+	objects := make([]map[string]string, 0, len(local))
+	for _, file := range local {
+		objects = append(objects, map[string]string{
+			"file": filepath.Base(file),
+			"url":  urls[file],
+		})
+	}
+
+	raw, err := json.MarshalIndent(map[string]interface{}{
+		"files": objects,
+		"state": metadata,
+	}, "", "  ")
+	if err != nil {
+		return nil, "", fmt.Errorf("could not encode metadata: %w", err)
+	}
+
+	metadataURL, err := backend.Put(ctx, path.Join(prefix, metadataName), bytes.NewReader(raw))
+	if err != nil {
+		return nil, "", fmt.Errorf("could not upload metadata: %w", err)
+	}
+
+	return urls, metadataURL, nil
+}
+
+func putFile(ctx context.Context, backend Backend, key, file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	url, err := backend.Put(ctx, key, f)
+	if err != nil {
+		return "", fmt.Errorf("could not upload %s: %w", file, err)
+	}
+
+	return url, nil
+}
@@ -0,0 +1,43 @@
+package unikraftpprocessor
+
+import (
+	"fmt"
+	unikraft "packer-plugin-unikraft/builder/unikraft"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/mitchellh/mapstructure"
+)
+
+// ArtifactPackages returns the references of the packages of an artifact
+// produced by the unikraft, push or sign post-processors, for the
+// post-processors handling them.  verb tells what they do with the packages,
+// e.g. `pushed`, in the error rejecting the packages they cannot handle.
+func ArtifactPackages(source packersdk.Artifact, verb string) ([]string, error) {
+	switch source.BuilderId() {
+	case unikraft.BuilderId, BuilderId:
+		break
+	default:
+		return nil, fmt.Errorf("unknown artifact %s", source.BuilderId())
+	}
+
+	format, _ := source.State("format").(string)
+	if format == unikraft.FormatDisk {
+		return nil, fmt.Errorf("disk images cannot be %s", verb)
+	}
+	if unikraft.IsArchiveFormat(format) {
+		return nil, fmt.Errorf("%s archives cannot be %s, only oci packages", format, verb)
+	}
+
+	var packages []string
+	if err := mapstructure.Decode(source.State("packages"), &packages); err != nil {
+		return nil, fmt.Errorf("failed to decode packages")
+	}
+
+	if len(packages) == 0 {
+		if oci, ok := source.State("oci").(string); ok && oci != "" {
+			packages = append(packages, oci)
+		}
+	}
+
+	return packages, nil
+}
@@ -0,0 +1,67 @@
+package unikraftpprocessor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	unikraft "packer-plugin-unikraft/builder/unikraft"
+)
+
+func TestArtifactPackages(t *testing.T) {
+	tests := []struct {
+		name  string
+		state map[string]interface{}
+		want  []string
+		err   string
+	}{
+		{
+			name:  "packages",
+			state: map[string]interface{}{"packages": []string{"registry.io/app:latest"}, "format": "oci"},
+			want:  []string{"registry.io/app:latest"},
+		},
+		{
+			name:  "oci package of several formats",
+			state: map[string]interface{}{"oci": "registry.io/app:latest"},
+			want:  []string{"registry.io/app:latest"},
+		},
+		{
+			name:  "disk images",
+			state: map[string]interface{}{"packages": []string{"app.raw"}, "format": unikraft.FormatDisk},
+			err:   "disk images cannot be pushed",
+		},
+		{
+			name:  "archives",
+			state: map[string]interface{}{"packages": []string{"app.tar"}, "format": "tar"},
+			err:   "tar archives cannot be pushed, only oci packages",
+		},
+		{
+			name:  "archives of several formats",
+			state: map[string]interface{}{"format": "cpio", "archives": []string{"app.cpio"}},
+			err:   "cpio archives cannot be pushed",
+		},
+		{
+			name:  "undecodable packages",
+			state: map[string]interface{}{"packages": 42},
+			err:   "failed to decode packages",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ArtifactPackages(&unikraft.Artifact{StateData: tt.state}, "pushed")
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("packages = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,6 +4,8 @@ package unikraftpprocessor
 
 import (
 	"fmt"
+	unikraft "packer-plugin-unikraft/builder/unikraft"
+	"strings"
 
 	"github.com/hashicorp/packer-plugin-sdk/common"
 	"github.com/hashicorp/packer-plugin-sdk/packer"
@@ -33,6 +35,33 @@ type Config struct {
 	Rootfs string `mapstructure:"rootfs"`
 	// Log level to use.
 	LogLevel string `mapstructure:"log_level"`
+	// Force fancy output even when not writing to a terminal.
+	FancyOutput bool `mapstructure:"fancy_output"`
+	// Whether to package every target of the artifact individually.
+	PerTarget bool `mapstructure:"per_target"`
+	// The format to package in: `oci`, or `disk` for a bootable disk image
+	// written to destination. Defaults to `oci`.
+	Format string `mapstructure:"format"`
+	// The formats to package in at once, instead of the single format: any
+	// of `oci`, `disk`, `raw` for a copy of the kernel, and `cpio` or `tar`
+	// for an archive of the kernel and its initramfs.
+	Formats []string `mapstructure:"formats"`
+	// The destination of the formats not written to destination, keyed by
+	// format.
+	FormatDestinations map[string]string `mapstructure:"format_destinations"`
+	// The format of the disk image: `raw`, `qcow2` or `vmdk`. Defaults to
+	// `raw`.
+	DiskFormat string `mapstructure:"disk_format"`
+	// The size of the disk image in MiB. Defaults to the smallest size the
+	// kernel fits in.
+	DiskSize int `mapstructure:"disk_size"`
+	// Write a software bill of materials of every package, listing the
+	// components it was built from, in `spdx` or `cyclonedx` format.
+	SBOM string `mapstructure:"sbom"`
+	// The `source:destination[:driver]` volumes the packaged targets mount,
+	// with the `9pfs` driver by default. They are declared in the
+	// `org.unikraft.volumes` annotation of the OCI package once pushed.
+	Volumes []string `mapstructure:"volumes"`
 
 	ctx interpolate.Context
 }
@@ -64,9 +93,135 @@ func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
 		errs = packer.MultiErrorAppend(errs, fmt.Errorf("file destination must be specified"))
 	}
 
+	if err := c.checkFormat(); err != nil {
+		errs = packer.MultiErrorAppend(errs, err)
+	}
+
 	if errs != nil && len(errs.Errors) > 0 {
 		return nil, errs
 	}
 
 	return nil, nil
 }
+
+// checkFormat checks the format options.
+func (c *Config) checkFormat() error {
+	var errs *packer.MultiError
+	if c.Format != "" && len(c.Formats) > 0 {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("format and formats cannot both be set"))
+	}
+
+	formats := c.formats()
+	destinations := map[string]string{}
+	for _, format := range formats {
+		if format != "oci" && format != unikraft.FormatDisk && !unikraft.IsArchiveFormat(format) {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("unknown format %q, expected oci, disk or %s", format, strings.Join(unikraft.ArchiveFormats, ", ")))
+			continue
+		}
+
+		destination := c.destination(format)
+		if other, ok := destinations[destination]; ok {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("formats %s and %s are both written to %s, set format_destinations", other, format, destination))
+		}
+		destinations[destination] = format
+	}
+	for format := range c.FormatDestinations {
+		if !hasFormat(formats, format) {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("format_destinations sets the destination of %s, which is not packaged", format))
+		}
+	}
+
+	if !hasFormat(formats, unikraft.FormatDisk) {
+		if c.DiskFormat != "" || c.DiskSize != 0 {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("disk_format and disk_size require the disk format"))
+		}
+	} else {
+		valid := c.DiskFormat == ""
+		for _, f := range unikraft.DiskFormats {
+			valid = valid || c.DiskFormat == f
+		}
+		if !valid {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("unknown disk_format %q, expected %s", c.DiskFormat, strings.Join(unikraft.DiskFormats, ", ")))
+		}
+
+		if c.DiskSize < 0 {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("disk_size must not be negative"))
+		}
+	}
+
+	// Only the OCI package is pushed along with the other formats.
+	if c.Push && !hasFormat(formats, "oci") {
+		if hasFormat(formats, unikraft.FormatDisk) {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("disk images cannot be pushed"))
+		} else {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("push requires the oci format"))
+		}
+	}
+
+	// Volumes are only declared by OCI packages.
+	if len(c.Volumes) > 0 {
+		if !hasFormat(formats, "oci") {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf("volumes require the oci format"))
+		}
+		if _, err := unikraft.ParseVolumes(c.Platform, c.Volumes); err != nil {
+			errs = packer.MultiErrorAppend(errs, err)
+		}
+	}
+
+	if c.SBOM != "" {
+		if err := unikraft.CheckSBOMFormat(c.SBOM); err != nil {
+			errs = packer.MultiErrorAppend(errs, err)
+		}
+	}
+
+	if errs != nil && len(errs.Errors) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// formats returns the formats to package in.
+func (c *Config) formats() []string {
+	if len(c.Formats) > 0 {
+		return c.Formats
+	}
+	if c.Format == "" {
+		return []string{"oci"}
+	}
+
+	return []string{c.Format}
+}
+
+// destination returns the destination of the package in format, before it is
+// rendered for the packaged target.
+func (c *Config) destination(format string) string {
+	if destination, ok := c.FormatDestinations[format]; ok {
+		return destination
+	}
+
+	return c.FileDestination
+}
+
+// primaryFormat returns the format of the packages the following
+// post-processors handle: oci when packaged, otherwise disk when packaged,
+// otherwise the first format.
+func primaryFormat(formats []string) string {
+	for _, format := range []string{"oci", unikraft.FormatDisk} {
+		if hasFormat(formats, format) {
+			return format
+		}
+	}
+
+	return formats[0]
+}
+
+func hasFormat(formats []string, format string) bool {
+	for _, f := range formats {
+		if f == format {
+			return true
+		}
+	}
+
+	return false
+}
@@ -26,6 +26,15 @@ type FlatConfig struct {
 	Push                *bool             `mapstructure:"push" cty:"push" hcl:"push"`
 	Rootfs              *string           `mapstructure:"rootfs" cty:"rootfs" hcl:"rootfs"`
 	LogLevel            *string           `mapstructure:"log_level" cty:"log_level" hcl:"log_level"`
+	FancyOutput         *bool             `mapstructure:"fancy_output" cty:"fancy_output" hcl:"fancy_output"`
+	PerTarget           *bool             `mapstructure:"per_target" cty:"per_target" hcl:"per_target"`
+	Format              *string           `mapstructure:"format" cty:"format" hcl:"format"`
+	Formats             []string          `mapstructure:"formats" cty:"formats" hcl:"formats"`
+	FormatDestinations  map[string]string `mapstructure:"format_destinations" cty:"format_destinations" hcl:"format_destinations"`
+	DiskFormat          *string           `mapstructure:"disk_format" cty:"disk_format" hcl:"disk_format"`
+	DiskSize            *int              `mapstructure:"disk_size" cty:"disk_size" hcl:"disk_size"`
+	SBOM                *string           `mapstructure:"sbom" cty:"sbom" hcl:"sbom"`
+	Volumes             []string          `mapstructure:"volumes" cty:"volumes" hcl:"volumes"`
 }
 
 // FlatMapstructure returns a new FlatConfig.
@@ -56,6 +65,15 @@ func (*FlatConfig) HCL2Spec() map[string]hcldec.Spec {
 		"push":                       &hcldec.AttrSpec{Name: "push", Type: cty.Bool, Required: false},
 		"rootfs":                     &hcldec.AttrSpec{Name: "rootfs", Type: cty.String, Required: false},
 		"log_level":                  &hcldec.AttrSpec{Name: "log_level", Type: cty.String, Required: false},
+		"fancy_output":               &hcldec.AttrSpec{Name: "fancy_output", Type: cty.Bool, Required: false},
+		"per_target":                 &hcldec.AttrSpec{Name: "per_target", Type: cty.Bool, Required: false},
+		"format":                     &hcldec.AttrSpec{Name: "format", Type: cty.String, Required: false},
+		"formats":                    &hcldec.AttrSpec{Name: "formats", Type: cty.List(cty.String), Required: false},
+		"format_destinations":        &hcldec.AttrSpec{Name: "format_destinations", Type: cty.Map(cty.String), Required: false},
+		"disk_format":                &hcldec.AttrSpec{Name: "disk_format", Type: cty.String, Required: false},
+		"disk_size":                  &hcldec.AttrSpec{Name: "disk_size", Type: cty.Number, Required: false},
+		"sbom":                       &hcldec.AttrSpec{Name: "sbom", Type: cty.String, Required: false},
+		"volumes":                    &hcldec.AttrSpec{Name: "volumes", Type: cty.List(cty.String), Required: false},
 	}
 	return s
 }
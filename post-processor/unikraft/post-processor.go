@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	unikraft "packer-plugin-unikraft/builder/unikraft"
+	"path/filepath"
+	"time"
 
 	"github.com/hashicorp/hcl/v2/hcldec"
 	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
@@ -24,13 +26,14 @@ func (p *PostProcessor) Configure(raws ...interface{}) error {
 		Interpolate:        true,
 		InterpolateContext: &p.config.ctx,
 		InterpolateFilter: &interpolate.RenderFilter{
-			Exclude: []string{},
+			// The destinations are rendered for each packaged target.
+			Exclude: []string{"destination", "format_destinations"},
 		},
 	}, raws...)
 	if err != nil {
 		return err
 	}
-	return nil
+	return p.config.checkFormat()
 }
 
 func (p *PostProcessor) PostProcess(ctx context.Context, ui packersdk.Ui, source packersdk.Artifact) (packersdk.Artifact, bool, bool, error) {
@@ -48,34 +51,271 @@ func (p *PostProcessor) PostProcess(ctx context.Context, ui packersdk.Ui, source
 		return source, false, false, err
 	}
 
+	// The initramfs constructed by the builder is packaged unless a rootfs
+	// is configured.
+	rootfs := p.config.Rootfs
+	if rootfs == "" {
+		var initramfs []string
+		if err := mapstructure.Decode(source.State("initramfs"), &initramfs); err != nil {
+			err := fmt.Errorf("failed to decode initramfs")
+			ui.Error(err.Error())
+			return source, false, false, err
+		}
+		if len(initramfs) > 0 {
+			rootfs = initramfs[0]
+		}
+	}
+
 	driver := &unikraft.KraftDriver{
 		Ctx:            &p.config.ctx,
 		Ui:             ui,
-		CommandContext: unikraft.KraftCommandContext(ui, p.config.LogLevel),
+		CommandContext: unikraft.KraftCommandContext(ui, p.config.LogLevel, p.config.FancyOutput),
+		PkgFormat:      p.config.Format,
+		DiskFormat:     p.config.DiskFormat,
+		DiskSize:       p.config.DiskSize,
+		SBOMFormat:     p.config.SBOM,
+		Volumes:        p.config.Volumes,
 	}
 
-	if p.config.Target != "" {
-		p.config.Architecture = ""
-		p.config.Platform = ""
+	// The project is packaged with the Kraftfile and command line it was
+	// built with.
+	if kraftfile, ok := source.State("kraftfile").(string); ok {
+		driver.Kraftfile = kraftfile
+	}
+	if cmdline, ok := source.State("cmdline").(string); ok {
+		driver.Cmdline = cmdline
 	}
 
-	err := driver.Pkg(
-		p.config.Architecture,
-		p.config.Platform,
-		p.config.Target,
-		p.config.FileDestination,
-		p.config.FileSource,
-		p.config.Rootfs,
-		p.config.Push,
-	)
+	built, err := unikraft.ArtifactTargets(source)
 	if err != nil {
-		return nil, false, false, fmt.Errorf("packaging error: %s", err)
+		ui.Error(err.Error())
+		return source, false, false, err
+	}
+
+	targets := []unikraft.TargetArtifact{{
+		Architecture: p.config.Architecture,
+		Platform:     p.config.Platform,
+		Target:       p.config.Target,
+	}}
+	if p.config.PerTarget {
+		targets = built
+		if len(targets) == 0 {
+			return nil, false, false, fmt.Errorf("artifact has no targets to package")
+		}
+	}
+
+	formats := p.config.formats()
+	primary := primaryFormat(formats)
+
+	// packages are the packages in each format, and files the metadata of
+	// every package in every format.
+	packages := map[string][]string{}
+	var sboms []string
+	var files []map[string]string
+	var packaged []map[string]string
+	durations := map[unikraft.TargetArtifact]time.Duration{}
+	for _, t := range targets {
+		t.Kernel = targetKernel(built, t)
+		t.KConfig = targetKConfig(built, t)
+
+		architecture := t.Architecture
+		platform := t.Platform
+		target := p.config.Target
+		if p.config.PerTarget {
+			target = ""
+		} else if target != "" {
+			architecture = ""
+			platform = ""
+		}
+
+		for _, format := range formats {
+			destination, err := renderDestination(p.config.destination(format), p.config.ctx, t)
+			if err != nil {
+				return nil, false, false, fmt.Errorf("invalid destination: %s", err)
+			}
+
+			if len(targets) > 1 || len(formats) > 1 {
+				ui.Say(fmt.Sprintf("Packaging %s/%s as %s", platform, architecture, destination))
+			}
+
+			file := map[string]string{
+				"format":       format,
+				"destination":  destination,
+				"architecture": t.Architecture,
+				"platform":     t.Platform,
+			}
+
+			start := time.Now()
+			if unikraft.IsArchiveFormat(format) {
+				kernel := t.Kernel
+				if kernel == "" {
+					return nil, false, false, fmt.Errorf("packaging error: no kernel built for %s/%s to package as %s", t.Platform, t.Architecture, format)
+				}
+
+				digest, err := unikraft.KernelArchive{Kernel: kernel, Initrd: rootfs}.Write(format, destination)
+				if err != nil {
+					return nil, false, false, fmt.Errorf("packaging error: %s", err)
+				}
+				file["sha256"] = digest
+			} else {
+				driver.PkgFormat = format
+				err = driver.Pkg(
+					architecture,
+					platform,
+					target,
+					destination,
+					p.config.FileSource,
+					rootfs,
+					p.config.Push && format == "oci",
+				)
+				if err != nil {
+					return nil, false, false, fmt.Errorf("packaging error: %s", err)
+				}
+				if sbom := driver.SBOM(); sbom != "" {
+					sboms = append(sboms, sbom)
+				}
+			}
+			durations[unikraft.TargetArtifact{Target: target, Platform: platform, Architecture: architecture}] += time.Since(start)
+
+			packages[format] = append(packages[format], destination)
+			files = append(files, file)
+		}
+
+		entry := map[string]string{
+			"architecture": t.Architecture,
+			"platform":     t.Platform,
+		}
+		if t.KConfig != "" {
+			entry["kconfig"] = t.KConfig
+		}
+		packaged = append(packaged, entry)
+	}
+
+	// The following post-processors handle the packages of the primary
+	// format, the others are listed along with it.
+	state := map[string]interface{}{
+		"format":  primary,
+		"targets": packaged,
+	}
+	// Archives are files the following post-processors can neither push nor
+	// run, and are only listed under archives.
+	if !unikraft.IsArchiveFormat(primary) {
+		state["packages"] = packages[primary]
+	}
+	if len(formats) > 1 {
+		state["formats"] = formats
+		state["package_files"] = files
+	}
+	var archives []string
+	for _, format := range unikraft.ArchiveFormats {
+		archives = append(archives, packages[format]...)
+	}
+	if len(archives) > 0 {
+		state["archives"] = archives
+	}
+	if rootfs != "" {
+		state["initrd"] = rootfs
+	}
+	if driver.Cmdline != "" {
+		state["cmdline"] = driver.Cmdline
+	}
+	if report, ok := source.State("build_report").(string); ok && report != "" {
+		if err := recordPackaging(report, durations); err != nil {
+			ui.Error(fmt.Sprintf("could not record packaging in build report: %s", err))
+		}
+		state["build_report"] = report
+	}
+	if len(sboms) > 0 {
+		state["sbom"] = sboms
+		state["sbom_format"] = p.config.SBOM
+	}
+	if disks := packages[unikraft.FormatDisk]; len(disks) > 0 {
+		diskFormat := p.config.DiskFormat
+		if diskFormat == "" {
+			diskFormat = "raw"
+		}
+
+		state["disks"] = disks
+		state["disk_format"] = diskFormat
+	}
+	if len(p.config.Volumes) > 0 {
+		state["volumes"] = p.config.Volumes
+	}
+	if oci := packages["oci"]; len(oci) == 1 {
+		state["oci"] = oci[0]
 	}
 
 	artifact := &unikraft.Artifact{
-		StateData: map[string]interface{}{
-			"oci": p.config.FileDestination,
-		},
+		StateData: state,
 	}
 	return artifact, true, true, nil
 }
+
+// recordPackaging adds the time taken to package each target to the build
+// report at path.
+func recordPackaging(path string, durations map[unikraft.TargetArtifact]time.Duration) error {
+	report, err := unikraft.ReadBuildReport(path)
+	if err != nil {
+		return err
+	}
+
+	for t, d := range durations {
+		report.AddPhase(t.Target, t.Platform, t.Architecture, "package", d)
+	}
+
+	return report.WriteFile(path)
+}
+
+// renderDestination renders the destination of the package of a target, which
+// may refer to its `{{ .Target }}`, `{{ .Architecture }}`, `{{ .Platform }}`
+// and `{{ .Version }}` like the outputs of the builder.
+func renderDestination(destination string, ctx interpolate.Context, t unikraft.TargetArtifact) (string, error) {
+	name := ""
+	if t.Kernel != "" {
+		name = filepath.Base(t.Kernel)
+	}
+
+	data := unikraft.NewOutputTemplateData(t, name)
+	ctx.Data = &data
+	return interpolate.Render(destination, &ctx)
+}
+
+// targetKernel returns the kernel of the packaged target t, looked up in the
+// targets built when t does not record it.
+func targetKernel(built []unikraft.TargetArtifact, t unikraft.TargetArtifact) string {
+	if t.Kernel != "" {
+		return t.Kernel
+	}
+
+	if t.Architecture == "" && t.Platform == "" && len(built) == 1 {
+		return built[0].Kernel
+	}
+
+	for _, b := range built {
+		if b.Architecture == t.Architecture && b.Platform == t.Platform {
+			return b.Kernel
+		}
+	}
+
+	return ""
+}
+
+// targetKConfig returns the .config the kernel of the packaged target t was
+// built with, looked up in the targets built when t does not record it.
+func targetKConfig(built []unikraft.TargetArtifact, t unikraft.TargetArtifact) string {
+	if t.KConfig != "" {
+		return t.KConfig
+	}
+
+	if t.Architecture == "" && t.Platform == "" && len(built) == 1 {
+		return built[0].KConfig
+	}
+
+	for _, b := range built {
+		if b.Architecture == t.Architecture && b.Platform == t.Platform {
+			return b.KConfig
+		}
+	}
+
+	return ""
+}
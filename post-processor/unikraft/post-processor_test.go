@@ -0,0 +1,170 @@
+package unikraftpprocessor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	unikraft "packer-plugin-unikraft/builder/unikraft"
+
+	"github.com/hashicorp/packer-plugin-sdk/template/interpolate"
+)
+
+func TestPerTargetDestinations(t *testing.T) {
+	source := &unikraft.Artifact{
+		StateData: map[string]interface{}{
+			"targets": []map[string]string{
+				{"platform": "qemu", "architecture": "x86_64", "kernel": "/app/.unikraft/build/app_qemu-x86_64"},
+				{"platform": "qemu", "architecture": "arm64", "kernel": "/app/.unikraft/build/app_qemu-arm64"},
+			},
+		},
+	}
+
+	targets, err := unikraft.ArtifactTargets(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) != 2 {
+		t.Fatalf("expected 2 targets, got %d", len(targets))
+	}
+
+	want := []string{
+		"registry.io/app-x86_64:latest",
+		"registry.io/app-arm64:latest",
+	}
+	for i, target := range targets {
+		got, err := renderDestination("registry.io/app-{{ .Architecture }}:latest", interpolate.Context{}, target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want[i] {
+			t.Errorf("destination of target %d = %s, want %s", i, got, want[i])
+		}
+	}
+}
+
+func TestDestinationTemplate(t *testing.T) {
+	dotconfig := filepath.Join(t.TempDir(), ".config")
+	if err := os.WriteFile(dotconfig, []byte("CONFIG_UK_FULLVERSION=\"0.16.1\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	target := unikraft.TargetArtifact{
+		Platform:     "fc",
+		Architecture: "x86_64",
+		Kernel:       "/app/.unikraft/build/app_fc-x86_64",
+		KConfig:      dotconfig,
+	}
+	got, err := renderDestination("registry.io/app:{{ .Version }}-{{ .Target }}", interpolate.Context{}, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "registry.io/app:0.16.1-fc-x86_64"; got != want {
+		t.Errorf("destination = %s, want %s", got, want)
+	}
+}
+
+func TestConfigureDiskFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  map[string]interface{}
+		want string
+	}{
+		{name: "disk", raw: map[string]interface{}{"format": "disk", "disk_format": "vmdk", "disk_size": 64}},
+		{name: "disk options without disk", raw: map[string]interface{}{"disk_format": "qcow2"}, want: "require the disk format"},
+		{name: "unknown disk format", raw: map[string]interface{}{"format": "disk", "disk_format": "vhd"}, want: `unknown disk_format "vhd"`},
+		{name: "pushed disk", raw: map[string]interface{}{"format": "disk", "push": true}, want: "disk images cannot be pushed"},
+		{name: "unknown format", raw: map[string]interface{}{"format": "zip"}, want: `unknown format "zip"`},
+		{name: "formats", raw: map[string]interface{}{"formats": []string{"oci", "cpio", "raw"}, "format_destinations": map[string]string{"cpio": "/tmp/nginx.cpio", "raw": "/tmp/nginx"}, "push": true}},
+		{name: "format and formats", raw: map[string]interface{}{"format": "oci", "formats": []string{"oci", "raw"}}, want: "format and formats cannot both be set"},
+		{name: "shared destination", raw: map[string]interface{}{"formats": []string{"oci", "raw"}}, want: "formats oci and raw are both written to /tmp/nginx.img"},
+		{name: "destination of unpackaged format", raw: map[string]interface{}{"format_destinations": map[string]string{"tar": "/tmp/nginx.tar"}}, want: "destination of tar, which is not packaged"},
+		{name: "pushed archive", raw: map[string]interface{}{"formats": []string{"tar"}, "push": true}, want: "push requires the oci format"},
+		{name: "sbom", raw: map[string]interface{}{"format": "disk", "sbom": "cyclonedx"}},
+		{name: "unknown sbom format", raw: map[string]interface{}{"sbom": "swid"}, want: `unknown sbom format "swid"`},
+		{name: "volumes", raw: map[string]interface{}{"platform": "qemu", "volumes": []string{"./html:/nginx/html", "./cache:/cache:virtiofs"}}},
+		{name: "volumes of disk", raw: map[string]interface{}{"format": "disk", "volumes": []string{"./html:/nginx/html"}}, want: "volumes require the oci format"},
+		{name: "unsupported volume driver", raw: map[string]interface{}{"platform": "fc", "volumes": []string{"./html:/nginx/html"}}, want: "platform fc does not support the 9pfs driver"},
+		{name: "invalid volume", raw: map[string]interface{}{"volumes": []string{"/nginx/html"}}, want: "expected source:destination[:driver]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.raw["source"] = "/tmp/nginx"
+			tt.raw["destination"] = "/tmp/nginx.img"
+
+			var p PostProcessor
+			err := p.Configure(tt.raw)
+			if tt.want == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestPrimaryFormat(t *testing.T) {
+	tests := []struct {
+		formats []string
+		want    string
+	}{
+		{formats: []string{"raw", "oci", "disk"}, want: "oci"},
+		{formats: []string{"cpio", "disk"}, want: "disk"},
+		{formats: []string{"tar", "raw"}, want: "tar"},
+	}
+
+	for _, tt := range tests {
+		if got := primaryFormat(tt.formats); got != tt.want {
+			t.Errorf("primaryFormat(%v) = %s, want %s", tt.formats, got, tt.want)
+		}
+	}
+}
+
+func TestTargetKConfig(t *testing.T) {
+	built := []unikraft.TargetArtifact{
+		{Platform: "qemu", Architecture: "x86_64", KConfig: "/app/.config.app_qemu-x86_64"},
+		{Platform: "qemu", Architecture: "arm64", KConfig: "/app/.config.app_qemu-arm64"},
+	}
+
+	if got := targetKConfig(built, unikraft.TargetArtifact{Platform: "qemu", Architecture: "arm64"}); got != "/app/.config.app_qemu-arm64" {
+		t.Errorf("targetKConfig() = %s, want the .config of qemu/arm64", got)
+	}
+	if got := targetKConfig(built, unikraft.TargetArtifact{}); got != "" {
+		t.Errorf("expected no .config for an ambiguous target, got %s", got)
+	}
+	if got := targetKConfig(built[:1], unikraft.TargetArtifact{}); got != "/app/.config.app_qemu-x86_64" {
+		t.Errorf("targetKConfig() = %s, want the .config of the only target", got)
+	}
+}
+
+func TestRecordPackaging(t *testing.T) {
+	path := filepath.Join(t.TempDir(), unikraft.BuildReportFile)
+	report := &unikraft.BuildReport{Targets: []unikraft.TargetResult{
+		{Target: "app-qemu-x86_64", Platform: "qemu", Architecture: "x86_64"},
+		{Target: "app-fc-x86_64", Platform: "fc", Architecture: "x86_64"},
+	}}
+	if err := report.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	err := recordPackaging(path, map[unikraft.TargetArtifact]time.Duration{
+		{Platform: "qemu", Architecture: "x86_64"}: 3 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := unikraft.ReadBuildReport(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := got.Targets[0].Phases["package"]; d != 3*time.Second {
+		t.Errorf("package duration of qemu/x86_64 = %s, want 3s", d)
+	}
+	if len(got.Targets[1].Phases) != 0 {
+		t.Errorf("expected fc/x86_64 to not be packaged, got %v", got.Targets[1].Phases)
+	}
+}
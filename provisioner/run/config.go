@@ -0,0 +1,136 @@
+//go:generate packer-sdc mapstructure-to-hcl2 -type Config
+
+package runprovisioner
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/packer-plugin-sdk/common"
+	"github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/hashicorp/packer-plugin-sdk/template/config"
+	"github.com/hashicorp/packer-plugin-sdk/template/interpolate"
+	"github.com/mitchellh/mapstructure"
+)
+
+const ProvisionerId = "packer.provisioner.unikraft-run"
+
+const (
+	// defaultArchitecture is the architecture of the booted kernel when none
+	// is configured.
+	defaultArchitecture = "x86_64"
+	// defaultMemory is the memory of the VM in MiB when none is configured.
+	defaultMemory = 64
+	// defaultTimeout is how long to wait for the prompt after booting and
+	// after every command when no timeout is configured.
+	defaultTimeout = time.Minute
+	// defaultMountTag is the 9pfs tag of the shared directory when none is
+	// configured.
+	defaultMountTag = "fs0"
+)
+
+type Config struct {
+	common.PackerConfig `mapstructure:",squash"`
+
+	// The commands to run in the unikernel, in order. This is required.
+	Commands []string `mapstructure:"commands" required:"true"`
+	// The prompt the unikernel prints when it is ready for a command. This
+	// is required.
+	Prompt string `mapstructure:"prompt" required:"true"`
+	// The kernel to boot. Defaults to the kernel built by the builder.
+	Kernel string `mapstructure:"kernel"`
+	// The initramfs to boot with. Defaults to the initramfs constructed by
+	// the builder, if any.
+	Initrd string `mapstructure:"initrd"`
+	// The architecture of the kernel: `x86_64` or `arm64`. Defaults to
+	// `x86_64`.
+	Architecture string `mapstructure:"architecture"`
+	// The command line of the kernel.
+	KernelArgs string `mapstructure:"kernel_args"`
+	// The memory of the VM in MiB. Defaults to 64.
+	Memory int `mapstructure:"memory"`
+	// The device the console of the unikernel is attached to: `serial` or
+	// `virtio`. Defaults to `serial`.
+	Console string `mapstructure:"console"`
+	// A host directory exported to the unikernel over 9pfs.
+	SharedDir string `mapstructure:"shared_dir"`
+	// The 9pfs tag of shared_dir. Defaults to `fs0`.
+	MountTag string `mapstructure:"mount_tag"`
+	// The QEMU executable. Defaults to the one of the architecture.
+	QemuBinary string `mapstructure:"qemu_binary"`
+	// How long to wait for the prompt after booting. Defaults to 1m.
+	BootTimeout time.Duration `mapstructure:"boot_timeout"`
+	// How long to wait for the prompt after every command. Defaults to 1m.
+	CommandTimeout time.Duration `mapstructure:"command_timeout"`
+	// A file the commands and their output are written to.
+	OutputFile string `mapstructure:"output_file"`
+
+	ctx interpolate.Context
+}
+
+func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
+	var md mapstructure.Metadata
+	err := config.Decode(c, &config.DecodeOpts{
+		Metadata:           &md,
+		PluginType:         ProvisionerId,
+		Interpolate:        true,
+		InterpolateContext: &c.ctx,
+	}, raws...)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Architecture == "" {
+		c.Architecture = defaultArchitecture
+	}
+	if c.Memory == 0 {
+		c.Memory = defaultMemory
+	}
+	if c.Console == "" {
+		c.Console = consoleSerial
+	}
+	if c.MountTag == "" {
+		c.MountTag = defaultMountTag
+	}
+	if c.QemuBinary == "" {
+		c.QemuBinary = qemuBinaries[c.Architecture]
+	}
+	if c.BootTimeout == 0 {
+		c.BootTimeout = defaultTimeout
+	}
+	if c.CommandTimeout == 0 {
+		c.CommandTimeout = defaultTimeout
+	}
+
+	// Accumulate any errors
+	var errs *packer.MultiError
+	if len(c.Commands) == 0 {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("commands must be specified"))
+	}
+
+	if c.Prompt == "" {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("prompt must be specified"))
+	}
+
+	if _, ok := qemuBinaries[c.Architecture]; !ok {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("unsupported architecture %q, expected x86_64 or arm64", c.Architecture))
+	}
+
+	if c.Console != consoleSerial && c.Console != consoleVirtio {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("unsupported console %q, expected serial or virtio", c.Console))
+	}
+
+	if c.Memory < 0 {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("memory must not be negative"))
+	}
+
+	if c.BootTimeout < 0 || c.CommandTimeout < 0 {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("boot_timeout and command_timeout must not be negative"))
+	}
+
+	if errs != nil && len(errs.Errors) > 0 {
+		return nil, errs
+	}
+
+	return nil, nil
+}
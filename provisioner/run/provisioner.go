@@ -0,0 +1,123 @@
+package runprovisioner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	unikraft "packer-plugin-unikraft/builder/unikraft"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/mitchellh/mapstructure"
+)
+
+// Provisioner boots the built unikernel and runs commands on its console.
+type Provisioner struct {
+	config Config
+
+	// boot overrides how the VM is booted.
+	boot func(ctx context.Context, binary string, args []string) (vmConsole, error)
+}
+
+func (p *Provisioner) ConfigSpec() hcldec.ObjectSpec { return p.config.FlatMapstructure().HCL2Spec() }
+
+func (p *Provisioner) Prepare(raws ...interface{}) error {
+	_, err := p.config.Prepare(raws...)
+	return err
+}
+
+func (p *Provisioner) Provision(ctx context.Context, ui packersdk.Ui, _ packersdk.Communicator, generatedData map[string]interface{}) error {
+	vm, err := p.vm(generatedData)
+	if err != nil {
+		return err
+	}
+
+	args, err := vm.Args()
+	if err != nil {
+		return err
+	}
+
+	boot := p.boot
+	if boot == nil {
+		boot = startQemu
+	}
+
+	ui.Say(fmt.Sprintf("Booting %s", vm.Kernel))
+	console, err := boot(ctx, p.config.QemuBinary, args)
+	if err != nil {
+		return fmt.Errorf("run error: %s", err)
+	}
+
+	results, err := runSession(ctx, console, console, p.config.Prompt, p.config.Commands, p.config.BootTimeout, p.config.CommandTimeout)
+	for _, r := range results {
+		ui.Say(fmt.Sprintf("Ran %s", r.Command))
+		if r.Output != "" {
+			ui.Message(r.Output)
+		}
+	}
+
+	if closeErr := console.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("run error: %s", err)
+	}
+
+	if p.config.OutputFile != "" {
+		if err := writeTranscript(p.config.OutputFile, results); err != nil {
+			return fmt.Errorf("run error: %s", err)
+		}
+	}
+
+	return nil
+}
+
+// vm returns the VM booting the configured kernel, or the kernel and
+// initramfs built by the builder.
+func (p *Provisioner) vm(generatedData map[string]interface{}) (VM, error) {
+	kernel := p.config.Kernel
+	if kernel == "" {
+		kernel, _ = generatedData["kernel"].(string)
+	}
+	if kernel == "" {
+		return VM{}, fmt.Errorf("no kernel to boot: set kernel, or build one with the unikraft builder")
+	}
+
+	initrd := p.config.Initrd
+	if initrd == "" {
+		var initramfs []string
+		if err := mapstructure.Decode(generatedData["initramfs"], &initramfs); err != nil {
+			return VM{}, fmt.Errorf("failed to decode initramfs: %s", err)
+		}
+		if len(initramfs) > 0 {
+			initrd = unikraft.StagedPath(initramfs[0])
+		}
+	}
+
+	return VM{
+		Architecture: p.config.Architecture,
+		Kernel:       unikraft.StagedPath(kernel),
+		Initrd:       initrd,
+		KernelArgs:   p.config.KernelArgs,
+		Memory:       p.config.Memory,
+		Console:      p.config.Console,
+		SharedDir:    p.config.SharedDir,
+		MountTag:     p.config.MountTag,
+	}, nil
+}
+
+// writeTranscript writes every command run, prefixed by `$`, followed by its
+// output to path.
+func writeTranscript(path string, results []Result) error {
+	var b strings.Builder
+	for _, r := range results {
+		fmt.Fprintf(&b, "$ %s\n", r.Command)
+		if r.Output != "" {
+			b.WriteString(r.Output + "\n")
+		}
+	}
+
+	return os.WriteFile(path, []byte(b.String()), 0o644)
+}
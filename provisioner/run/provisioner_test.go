@@ -0,0 +1,127 @@
+package runprovisioner
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+type fakeConsole struct {
+	io.Reader
+	io.Writer
+	closed bool
+}
+
+func (c *fakeConsole) Close() error {
+	c.closed = true
+	return nil
+}
+
+func testUi() *packersdk.BasicUi {
+	return &packersdk.BasicUi{
+		Reader: new(bytes.Buffer),
+		Writer: new(bytes.Buffer),
+	}
+}
+
+func TestProvisionRunsCommands(t *testing.T) {
+	transcript := filepath.Join(t.TempDir(), "transcript")
+
+	output, input := fakeShell("", "# ", map[string]string{"cat /etc/motd": "hello\r\n"})
+	console := &fakeConsole{Reader: output, Writer: input}
+
+	var bootArgs []string
+	p := &Provisioner{boot: func(_ context.Context, binary string, args []string) (vmConsole, error) {
+		bootArgs = append([]string{binary}, args...)
+		return console, nil
+	}}
+	err := p.Prepare(map[string]interface{}{
+		"prompt":      "# ",
+		"commands":    []string{"cat /etc/motd"},
+		"output_file": transcript,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = p.Provision(context.Background(), testUi(), nil, map[string]interface{}{
+		"kernel":    "/out/helloworld_qemu-x86_64",
+		"initramfs": []string{"/out/initramfs.cpio"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !console.closed {
+		t.Error("expected the VM to be stopped")
+	}
+	if got := strings.Join(bootArgs, " "); !strings.HasPrefix(got, "qemu-system-x86_64 -cpu max -m 64 -kernel /out/helloworld_qemu-x86_64") || !strings.Contains(got, "-initrd /out/initramfs.cpio") {
+		t.Errorf("unexpected boot command %q", got)
+	}
+
+	raw, err := os.ReadFile(transcript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(raw), "$ cat /etc/motd\nhello\n"; got != want {
+		t.Errorf("transcript = %q, want %q", got, want)
+	}
+}
+
+func TestProvisionWithoutKernel(t *testing.T) {
+	p := &Provisioner{}
+	if err := p.Prepare(map[string]interface{}{"prompt": "# ", "commands": []string{"ls"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := p.Provision(context.Background(), testUi(), nil, map[string]interface{}{})
+	if err == nil || !strings.Contains(err.Error(), "no kernel to boot") {
+		t.Errorf("expected a missing kernel error, got %v", err)
+	}
+}
+
+func TestPrepareValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  map[string]interface{}
+		want []string
+	}{
+		{name: "missing", raw: map[string]interface{}{}, want: []string{"commands must be specified", "prompt must be specified"}},
+		{name: "unsupported", raw: map[string]interface{}{
+			"prompt":       "# ",
+			"commands":     []string{"ls"},
+			"architecture": "riscv64",
+			"console":      "vsock",
+		}, want: []string{"unsupported architecture", "unsupported console"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c Config
+			_, err := c.Prepare(tt.raw)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("expected error containing %q, got %v", want, err)
+				}
+			}
+		})
+	}
+
+	var c Config
+	if _, err := c.Prepare(map[string]interface{}{"prompt": "# ", "commands": []string{"ls"}, "architecture": "arm64"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := []interface{}{c.QemuBinary, c.Memory, c.Console, c.MountTag}; !reflect.DeepEqual(got, []interface{}{"qemu-system-aarch64", 64, "serial", "fs0"}) {
+		t.Errorf("defaults = %v", got)
+	}
+}
@@ -0,0 +1,148 @@
+package runprovisioner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// qemuBinaries are the QEMU executables booting kernels of each supported
+// architecture.
+var qemuBinaries = map[string]string{
+	"x86_64": "qemu-system-x86_64",
+	"arm64":  "qemu-system-aarch64",
+}
+
+// Consoles the unikernel may be reached over.
+const (
+	consoleSerial = "serial"
+	consoleVirtio = "virtio"
+)
+
+// VM describes how a kernel is booted with QEMU.
+type VM struct {
+	Architecture string
+	Kernel       string
+	Initrd       string
+	KernelArgs   string
+	// Memory is the memory of the VM in MiB.
+	Memory int
+	// Console is the device the console of the unikernel is attached to:
+	// `serial` or `virtio`.
+	Console string
+	// SharedDir, when set, is exported to the unikernel over 9pfs with the
+	// MountTag tag.
+	SharedDir string
+	MountTag  string
+}
+
+// Args returns the arguments of QEMU booting the VM, with the console of the
+// unikernel attached to the standard input and output of QEMU.
+func (vm VM) Args() ([]string, error) {
+	var args []string
+	switch vm.Architecture {
+	case "x86_64":
+		args = append(args, "-cpu", "max")
+	case "arm64":
+		args = append(args, "-machine", "virt", "-cpu", "max")
+	default:
+		return nil, fmt.Errorf("unsupported architecture %q, expected x86_64 or arm64", vm.Architecture)
+	}
+
+	args = append(args,
+		"-m", fmt.Sprint(vm.Memory),
+		"-kernel", vm.Kernel,
+		"-display", "none",
+		"-monitor", "none",
+		"-no-reboot",
+	)
+	if vm.Initrd != "" {
+		args = append(args, "-initrd", vm.Initrd)
+	}
+	if vm.KernelArgs != "" {
+		args = append(args, "-append", vm.KernelArgs)
+	}
+
+	switch vm.Console {
+	case consoleSerial, "":
+		args = append(args, "-serial", "stdio")
+	case consoleVirtio:
+		args = append(args,
+			"-serial", "none",
+			"-device", "virtio-serial",
+			"-chardev", "stdio,id=console",
+			"-device", "virtconsole,chardev=console",
+		)
+	default:
+		return nil, fmt.Errorf("unsupported console %q, expected serial or virtio", vm.Console)
+	}
+
+	if vm.SharedDir != "" {
+		args = append(args,
+			"-fsdev", fmt.Sprintf("local,id=shared,path=%s,security_model=none", vm.SharedDir),
+			"-device", fmt.Sprintf("virtio-9p-pci,fsdev=shared,mount_tag=%s", vm.MountTag),
+		)
+	}
+
+	return args, nil
+}
+
+// vmConsole is the console of a booted VM.  Closing it stops the VM.
+type vmConsole interface {
+	io.Reader
+	io.Writer
+	io.Closer
+}
+
+// qemuProcess is a QEMU process whose standard input and output are the
+// console of the unikernel.
+type qemuProcess struct {
+	io.Reader
+	io.Writer
+
+	cmd    *exec.Cmd
+	stderr bytes.Buffer
+}
+
+// startQemu boots a VM by running binary with args.
+func startQemu(ctx context.Context, binary string, args []string) (vmConsole, error) {
+	p := &qemuProcess{cmd: exec.CommandContext(ctx, binary, args...)}
+	p.cmd.Stderr = &p.stderr
+
+	stdin, err := p.cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := p.cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	p.Reader = stdout
+	p.Writer = stdin
+
+	if err := p.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("could not start %s: %w", binary, err)
+	}
+
+	return p, nil
+}
+
+// Close stops QEMU.  Its standard error is reported when it exited on its own
+// with an error.
+func (p *qemuProcess) Close() error {
+	// The unikernel keeps running once the commands are done.
+	p.cmd.Process.Kill()
+
+	err := p.cmd.Wait()
+	if exitErr, ok := err.(*exec.ExitError); ok && !exitErr.Exited() {
+		return nil
+	}
+	if err != nil && strings.TrimSpace(p.stderr.String()) != "" {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(p.stderr.String()))
+	}
+
+	return err
+}
@@ -0,0 +1,64 @@
+package runprovisioner
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestVMArgs(t *testing.T) {
+	args, err := VM{
+		Architecture: "arm64",
+		Kernel:       "/out/helloworld_qemu-arm64",
+		Initrd:       "/out/initramfs.cpio",
+		KernelArgs:   "vfs.fstab=[ \"fs0:/data:9pfs\" ]",
+		Memory:       64,
+		Console:      consoleVirtio,
+		SharedDir:    "/srv/data",
+		MountTag:     "fs0",
+	}.Args()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"-machine", "virt", "-cpu", "max",
+		"-m", "64",
+		"-kernel", "/out/helloworld_qemu-arm64",
+		"-display", "none",
+		"-monitor", "none",
+		"-no-reboot",
+		"-initrd", "/out/initramfs.cpio",
+		"-append", "vfs.fstab=[ \"fs0:/data:9pfs\" ]",
+		"-serial", "none",
+		"-device", "virtio-serial",
+		"-chardev", "stdio,id=console",
+		"-device", "virtconsole,chardev=console",
+		"-fsdev", "local,id=shared,path=/srv/data,security_model=none",
+		"-device", "virtio-9p-pci,fsdev=shared,mount_tag=fs0",
+	}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("Args() = %q, want %q", args, want)
+	}
+}
+
+func TestVMArgsSerial(t *testing.T) {
+	args, err := VM{Architecture: "x86_64", Kernel: "/out/helloworld_qemu-x86_64", Memory: 32}.Args()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(args, " "); !strings.HasSuffix(got, "-no-reboot -serial stdio") {
+		t.Errorf("expected the serial console on stdio, got %q", got)
+	}
+}
+
+func TestVMArgsUnsupported(t *testing.T) {
+	if _, err := (VM{Architecture: "riscv64"}).Args(); err == nil || !strings.Contains(err.Error(), "unsupported architecture") {
+		t.Errorf("expected an unsupported architecture error, got %v", err)
+	}
+
+	if _, err := (VM{Architecture: "x86_64", Console: "vsock"}).Args(); err == nil || !strings.Contains(err.Error(), "unsupported console") {
+		t.Errorf("expected an unsupported console error, got %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package runprovisioner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Result is the output of a command run inside the unikernel.
+type Result struct {
+	Command string
+	Output  string
+}
+
+// console accumulates the output of a unikernel console read in the
+// background.
+type console struct {
+	chunks <-chan string
+	done   chan struct{}
+	err    error
+	buf    string
+}
+
+func newConsole(r io.Reader) *console {
+	chunks := make(chan string)
+	c := &console{chunks: chunks, done: make(chan struct{})}
+
+	go func() {
+		defer close(chunks)
+
+		p := make([]byte, 4096)
+		for {
+			n, err := r.Read(p)
+			if n > 0 {
+				select {
+				case chunks <- string(p[:n]):
+				case <-c.done:
+					return
+				}
+			}
+			if err != nil {
+				if err != io.EOF {
+					c.err = err
+				}
+				return
+			}
+		}
+	}()
+
+	return c
+}
+
+// close stops reading the console.
+func (c *console) close() {
+	close(c.done)
+}
+
+// waitFor returns the console output up to prompt, and consumes it along with
+// the prompt.  It fails when the console closes or the timeout elapses first.
+func (c *console) waitFor(ctx context.Context, prompt string, timeout time.Duration) (string, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		if i := strings.Index(c.buf, prompt); i >= 0 {
+			out := c.buf[:i]
+			c.buf = c.buf[i+len(prompt):]
+			return out, nil
+		}
+
+		select {
+		case chunk, ok := <-c.chunks:
+			if !ok {
+				if c.err != nil {
+					return c.buf, fmt.Errorf("console closed: %w", c.err)
+				}
+				return c.buf, fmt.Errorf("unikernel exited")
+			}
+			c.buf += chunk
+		case <-timer.C:
+			return c.buf, fmt.Errorf("timed out after %s waiting for prompt %q", timeout, prompt)
+		case <-ctx.Done():
+			return c.buf, ctx.Err()
+		}
+	}
+}
+
+// runSession waits for the unikernel to print prompt on its console, then
+// runs every command by writing it to input, collecting its output up to the
+// next prompt.  The echo of the command is not part of its output.
+func runSession(ctx context.Context, output io.Reader, input io.Writer, prompt string, commands []string, bootTimeout, commandTimeout time.Duration) ([]Result, error) {
+	c := newConsole(output)
+	defer c.close()
+
+	if boot, err := c.waitFor(ctx, prompt, bootTimeout); err != nil {
+		return nil, fmt.Errorf("boot failed: %w%s", err, consoleTail(boot))
+	}
+
+	var results []Result
+	for _, command := range commands {
+		if _, err := io.WriteString(input, command+"\n"); err != nil {
+			return results, fmt.Errorf("could not run %q: %w", command, err)
+		}
+
+		out, err := c.waitFor(ctx, prompt, commandTimeout)
+		out = commandOutput(command, out)
+		results = append(results, Result{Command: command, Output: out})
+		if err != nil {
+			return results, fmt.Errorf("command %q failed: %w%s", command, err, consoleTail(out))
+		}
+	}
+
+	return results, nil
+}
+
+// commandOutput normalizes the line endings of the console output of command
+// and strips its echo.
+func commandOutput(command, out string) string {
+	out = strings.ReplaceAll(out, "\r\n", "\n")
+	if first, rest, ok := strings.Cut(out, "\n"); ok && strings.TrimSpace(first) == strings.TrimSpace(command) {
+		out = rest
+	} else if strings.TrimSpace(out) == strings.TrimSpace(command) {
+		out = ""
+	}
+
+	return strings.TrimRight(out, "\n")
+}
+
+// consoleTailLines is the number of console lines reported on failure.
+const consoleTailLines = 20
+
+func consoleTail(out string) string {
+	lines := strings.Split(strings.TrimRight(strings.ReplaceAll(out, "\r\n", "\n"), "\n"), "\n")
+	if len(lines) > consoleTailLines {
+		lines = lines[len(lines)-consoleTailLines:]
+	}
+
+	tail := strings.Join(lines, "\n")
+	if strings.TrimSpace(tail) == "" {
+		return ""
+	}
+
+	return ":\n" + tail
+}
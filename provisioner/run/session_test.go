@@ -0,0 +1,97 @@
+package runprovisioner
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeShell emulates the console of a unikernel shell: it prints banner and
+// prompt, then echoes every command followed by its reply and the prompt.
+// Commands without a reply hang.
+func fakeShell(banner, prompt string, replies map[string]string) (output io.Reader, input io.Writer) {
+	outR, outW := io.Pipe()
+	inR, inW := io.Pipe()
+
+	go func() {
+		defer outW.Close()
+
+		io.WriteString(outW, banner+prompt)
+		scanner := bufio.NewScanner(inR)
+		for scanner.Scan() {
+			command := scanner.Text()
+			reply, ok := replies[command]
+			if !ok {
+				continue
+			}
+			io.WriteString(outW, command+"\r\n"+reply+prompt)
+		}
+	}()
+
+	return outR, inW
+}
+
+func TestRunSession(t *testing.T) {
+	output, input := fakeShell("Powered by Unikraft\r\n", "uk> ", map[string]string{
+		"uname":  "Unikraft 0.16.1\r\n",
+		"ls /":   "etc\r\nusr\r\n",
+		"true":   "",
+		"unused": "",
+	})
+
+	results, err := runSession(context.Background(), output, input, "uk> ", []string{"uname", "ls /", "true"}, time.Second, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Result{
+		{Command: "uname", Output: "Unikraft 0.16.1"},
+		{Command: "ls /", Output: "etc\nusr"},
+		{Command: "true", Output: ""},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("results = %q, want %q", results, want)
+	}
+}
+
+func TestRunSessionCommandTimeout(t *testing.T) {
+	output, input := fakeShell("", "uk> ", map[string]string{"uname": "Unikraft\r\n"})
+
+	results, err := runSession(context.Background(), output, input, "uk> ", []string{"uname", "sleep"}, time.Second, 50*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), `command "sleep" failed: timed out`) {
+		t.Fatalf("expected a command timeout, got %v", err)
+	}
+	if len(results) != 2 || results[0].Output != "Unikraft" {
+		t.Errorf("results = %q", results)
+	}
+}
+
+func TestRunSessionBootFailure(t *testing.T) {
+	output := strings.NewReader("Powered by Unikraft\r\n[    0.1] CRIT: Crash dump\r\n")
+
+	_, err := runSession(context.Background(), output, io.Discard, "uk> ", []string{"uname"}, time.Second, time.Second)
+	if err == nil || !strings.Contains(err.Error(), "boot failed: unikernel exited") || !strings.Contains(err.Error(), "Crash dump") {
+		t.Errorf("expected a boot failure with the console, got %v", err)
+	}
+}
+
+func TestCommandOutput(t *testing.T) {
+	tests := []struct {
+		command, out, want string
+	}{
+		{"uname", "uname\r\nUnikraft\r\n", "Unikraft"},
+		{"uname", "Unikraft\r\n", "Unikraft"},
+		{"true", "true\r\n", ""},
+		{"true", "true", ""},
+	}
+
+	for _, tt := range tests {
+		if got := commandOutput(tt.command, tt.out); got != tt.want {
+			t.Errorf("commandOutput(%q, %q) = %q, want %q", tt.command, tt.out, got, tt.want)
+		}
+	}
+}